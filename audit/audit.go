@@ -0,0 +1,156 @@
+// Package audit keeps the tamper-evident log of changes made to merchants,
+// their settings and transactions. The request making a change is carried
+// in its context, so the usecases can say who made it without every caller
+// passing it along.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// System is the actor of changes not made through the API, such as
+// scheduled billing.
+const System = "system"
+
+// Anonymous is the actor of API calls. The gateway does not authenticate
+// its callers, so it cannot say who made one.
+const Anonymous = "anonymous"
+
+// Request is who made an API call and from where. ClaimedActor is who the
+// caller says it is, which nothing has verified.
+type Request struct {
+	Actor        string
+	ClaimedActor string
+	RequestID    string
+	SourceIP     string
+}
+
+type requestKey struct{}
+
+// WithRequest returns a context carrying the request.
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// RequestFrom gives the request the context carries. Without one the
+// change was made by the gateway itself.
+func RequestFrom(ctx context.Context) Request {
+	r, ok := ctx.Value(requestKey{}).(Request)
+	if !ok {
+		return Request{Actor: System}
+	}
+
+	return r
+}
+
+type changeKey struct{}
+
+// WithinChange returns a context for the writes making up a change that an
+// auditor records as a whole, so that they are not recorded again one by
+// one.
+func WithinChange(ctx context.Context) context.Context {
+	return context.WithValue(ctx, changeKey{}, true)
+}
+
+// InChange reports whether the context is for part of a change that is
+// already being recorded.
+func InChange(ctx context.Context) bool {
+	in, _ := ctx.Value(changeKey{}).(bool)
+
+	return in
+}
+
+// Hash gives an entry's link in the chain: the SHA-256 of its previous
+// hash and everything it records except its ID, which the storage assigns.
+// ClaimedActor is only hashed when there is one, so that entries recorded
+// before it was kept still verify.
+func Hash(e domain.AuditEntry) string {
+	fields := []interface{}{
+		e.PrevHash,
+		e.Actor,
+		e.MerchantID,
+		e.Action,
+		e.Entity,
+		e.EntityID,
+		string(e.Before),
+		string(e.After),
+		e.RequestID,
+		e.SourceIP,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	if e.ClaimedActor != "" {
+		fields = append(fields, e.ClaimedActor)
+	}
+	b, _ := json.Marshal(fields)
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff gives the JSON values of the fields that differ between two
+// versions of an entity. A nil before is a created entity, whose fields
+// are all given. Equal versions give nil.
+func Diff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	a, err := json.Marshal(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if before == nil {
+		return json.RawMessage("null"), a, nil
+	}
+	b, err := json.Marshal(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var bFields, aFields map[string]json.RawMessage
+	err = json.Unmarshal(b, &bFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = json.Unmarshal(a, &aFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	bChanged, aChanged := map[string]json.RawMessage{}, map[string]json.RawMessage{}
+	for k, v := range aFields {
+		if !bytes.Equal(bFields[k], v) {
+			bChanged[k], aChanged[k] = orNull(bFields[k]), v
+		}
+	}
+	for k, v := range bFields {
+		if _, ok := aFields[k]; !ok {
+			bChanged[k], aChanged[k] = v, orNull(nil)
+		}
+	}
+	if len(aChanged) == 0 {
+		return nil, nil, nil
+	}
+
+	b, err = json.Marshal(bChanged)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err = json.Marshal(aChanged)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b, a, nil
+}
+
+// orNull stands null in for a field one version leaves out.
+func orNull(v json.RawMessage) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+
+	return v
+}
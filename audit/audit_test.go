@@ -0,0 +1,86 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+func TestRequest(t *testing.T) {
+	assert.Equal(t, audit.Request{Actor: audit.System}, audit.RequestFrom(context.TODO()))
+
+	r := audit.Request{Actor: audit.Anonymous, ClaimedActor: "ops@lorem.id", RequestID: "req-1", SourceIP: "203.0.113.7"}
+	assert.Equal(t, r, audit.RequestFrom(audit.WithRequest(context.TODO(), r)))
+}
+
+func TestWithinChange(t *testing.T) {
+	assert.False(t, audit.InChange(context.TODO()))
+	assert.True(t, audit.InChange(audit.WithinChange(context.TODO())))
+}
+
+func TestHash(t *testing.T) {
+	e := domain.AuditEntry{
+		Actor:      "ops@lorem.id",
+		MerchantID: 1,
+		Action:     "transaction.capture",
+		Entity:     domain.AuditTransaction,
+		EntityID:   4,
+		Before:     json.RawMessage(`{"state":"authorized"}`),
+		After:      json.RawMessage(`{"state":"captured"}`),
+		RequestID:  "req-1",
+		SourceIP:   "203.0.113.7",
+		CreatedAt:  time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+	}
+	hash := audit.Hash(e)
+	assert.Len(t, hash, 64)
+
+	e.ID, e.Hash = 9, "lorem"
+	assert.Equal(t, hash, audit.Hash(e), "the ID and hash are not hashed")
+	e.CreatedAt = e.CreatedAt.In(time.FixedZone("WIB", 7*60*60))
+	assert.Equal(t, hash, audit.Hash(e), "times are hashed in UTC")
+
+	for _, change := range []func(e *domain.AuditEntry){
+		func(e *domain.AuditEntry) { e.PrevHash = "ipsum" },
+		func(e *domain.AuditEntry) { e.Actor = "someone" },
+		func(e *domain.AuditEntry) { e.ClaimedActor = "someone" },
+		func(e *domain.AuditEntry) { e.After = json.RawMessage(`{"state":"refunded"}`) },
+		func(e *domain.AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Second) },
+	} {
+		changed := e
+		change(&changed)
+		assert.NotEqual(t, hash, audit.Hash(changed))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := domain.Transaction{ID: 4, State: domain.TransactionAuthorized, Metadata: map[string]string{"order": "A1"}, Version: 2}
+	after := before
+	after.State = domain.TransactionCaptured
+	after.Metadata = nil
+	after.Version = 3
+
+	b, a, err := audit.Diff(before, after)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"state":"authorized","metadata":{"order":"A1"},"version":2}`, string(b))
+	assert.JSONEq(t, `{"state":"captured","metadata":null,"version":3}`, string(a))
+
+	b, a, err = audit.Diff(before, before)
+	assert.NoError(t, err)
+	assert.Nil(t, b)
+	assert.Nil(t, a)
+}
+
+func TestDiffCreated(t *testing.T) {
+	s := domain.Setting{ID: 2, MerchantID: 1, PaymentType: "QRIS", Version: 1}
+
+	b, a, err := audit.Diff(nil, s)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+	assert.JSONEq(t, `{"id":2,"merchantId":1,"color":"","paymentType":"QRIS","paymentName":"","version":1}`, string(a))
+}
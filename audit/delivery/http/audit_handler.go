@@ -0,0 +1,114 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type AuditHandler struct {
+	Usecase domain.AuditUsecase
+}
+
+// NewAuditHandler serves the audit log. Requests are put down to their
+// actor by Recorder.
+func NewAuditHandler(e *echo.Echo, u domain.AuditUsecase) *AuditHandler {
+	handler := &AuditHandler{
+		Usecase: u,
+	}
+
+	e.GET("/audit", handler.Fetch)
+	e.GET("/audit/verify", handler.Verify)
+
+	return handler
+}
+
+// Recorder, used on every route, puts who made a request and from where
+// into its context for the audit log. Nothing authenticates callers, so the
+// actor is always anonymous; the X-Actor header is only kept as the actor
+// the caller claims to be. The request ID is the caller's X-Request-ID, or
+// a new one, and is sent back either way.
+func Recorder(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		r := audit.Request{
+			Actor:        audit.Anonymous,
+			ClaimedActor: req.Header.Get("X-Actor"),
+			RequestID:    req.Header.Get(echo.HeaderXRequestID),
+			SourceIP:     c.RealIP(),
+		}
+		if r.RequestID == "" {
+			r.RequestID = requestID()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, r.RequestID)
+		c.SetRequest(req.WithContext(audit.WithRequest(req.Context(), r)))
+
+		return next(c)
+	}
+}
+
+// Fetch lists the audit log, oldest first, optionally only for one kind of
+// entity or one entity and within a time range given in RFC 3339.
+func (h *AuditHandler) Fetch(c echo.Context) error {
+	f := domain.AuditFilter{Entity: c.QueryParam("entity")}
+	var err error
+	if v := c.QueryParam("entityId"); v != "" {
+		f.EntityID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || f.Entity == "" {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+		}
+	}
+	if v := c.QueryParam("from"); v != "" {
+		f.From, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		f.To, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+		}
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, f)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Verify checks the audit log's hash chain from the first entry to the
+// last.
+func (h *AuditHandler) Verify(c echo.Context) error {
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Verify(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func requestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	auditHttp "github.com/hezbymuhammad/payment-gateway/audit/delivery/http"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+func TestFetch(t *testing.T) {
+	from := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	f := domain.AuditFilter{Entity: domain.AuditTransaction, EntityID: 4, From: from, To: from.Add(time.Hour)}
+	entries := []domain.AuditEntry{{ID: 1, Action: "transaction.capture", Entity: domain.AuditTransaction, EntityID: 4}}
+	mockUsecase := new(mocks.AuditUsecase)
+	mockUsecase.On("Fetch", mock.Anything, f).Return(entries, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/audit?entity=transaction&entityId=4&from=2026-10-19T08:00:00Z&to=2026-10-19T09:00:00Z", nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/audit")
+
+	handler := auditHttp.NewAuditHandler(echo.New(), mockUsecase)
+	err = handler.Fetch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var res []domain.AuditEntry
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Len(t, res, 1)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestFetchBadParam(t *testing.T) {
+	for _, q := range []string{
+		"entityId=4",
+		"entity=transaction&entityId=lorem",
+		"from=yesterday",
+		"to=2026-10-19",
+	} {
+		mockUsecase := new(mocks.AuditUsecase)
+
+		e := echo.New()
+		req, err := http.NewRequest(echo.GET, "/audit?"+q, nil)
+		assert.NoError(t, err)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetPath("/audit")
+
+		handler := auditHttp.NewAuditHandler(echo.New(), mockUsecase)
+		err = handler.Fetch(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, q)
+		mockUsecase.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	}
+}
+
+func TestFailedFetch(t *testing.T) {
+	mockUsecase := new(mocks.AuditUsecase)
+	mockUsecase.On("Fetch", mock.Anything, mock.Anything).Return(nil, errors.New("dummy err")).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/audit", nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/audit")
+
+	handler := auditHttp.NewAuditHandler(echo.New(), mockUsecase)
+	err = handler.Fetch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestVerify(t *testing.T) {
+	mockUsecase := new(mocks.AuditUsecase)
+	mockUsecase.On("Verify", mock.Anything).Return(domain.AuditVerification{Valid: false, Entries: 3, BrokenAt: 2}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/audit/verify", nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/audit/verify")
+
+	handler := auditHttp.NewAuditHandler(echo.New(), mockUsecase)
+	err = handler.Verify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"valid":false,"entries":3,"brokenAt":2}`, rec.Body.String())
+}
+
+func TestRecorder(t *testing.T) {
+	var got audit.Request
+	next := func(c echo.Context) error {
+		got = audit.RequestFrom(c.Request().Context())
+		return nil
+	}
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PATCH, "/transactions/4", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Actor", "ops@lorem.id")
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	req.RemoteAddr = "203.0.113.7:5000"
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	assert.NoError(t, auditHttp.Recorder(next)(ctx))
+	assert.Equal(t, audit.Request{Actor: audit.Anonymous, ClaimedActor: "ops@lorem.id", RequestID: "req-1", SourceIP: "203.0.113.7"}, got)
+	assert.Equal(t, "req-1", rec.Header().Get(echo.HeaderXRequestID))
+
+	req, err = http.NewRequest(echo.PATCH, "/transactions/4", nil)
+	assert.NoError(t, err)
+	rec = httptest.NewRecorder()
+	ctx = e.NewContext(req, rec)
+
+	assert.NoError(t, auditHttp.Recorder(next)(ctx))
+	assert.Equal(t, audit.Anonymous, got.Actor)
+	assert.Empty(t, got.ClaimedActor)
+	assert.Len(t, got.RequestID, 32)
+	assert.Equal(t, got.RequestID, rec.Header().Get(echo.HeaderXRequestID))
+}
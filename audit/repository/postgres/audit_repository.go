@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strconv"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const auditColumns = "id, actor, claimed_actor, merchant_id, action, entity, entity_id, before, after, request_id, source_ip, created_at, prev_hash, hash"
+
+type postgresAuditRepo struct {
+	DB *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) domain.AuditRepository {
+	return &postgresAuditRepo{
+		DB: db,
+	}
+}
+
+// Append adds the entry only if it follows the last one. Two entries
+// appended at once after the same one would both pass that check, so the
+// unique prev_hash turns the second away.
+func (ar *postgresAuditRepo) Append(ctx context.Context, e *domain.AuditEntry) error {
+	query := "INSERT INTO audit_log (actor, claimed_actor, merchant_id, action, entity, entity_id, before, after, request_id, source_ip, created_at, prev_hash, hash) SELECT $1, $2, $3::bigint, $4, $5, $6::bigint, $7, $8, $9, $10, $11::timestamptz, $12::text, $13 WHERE COALESCE((SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1), '')=$12::text RETURNING id"
+
+	err := ar.DB.QueryRowContext(ctx, query, e.Actor, e.ClaimedActor, e.MerchantID, e.Action, e.Entity, e.EntityID, string(e.Before), string(e.After), e.RequestID, e.SourceIP, e.CreatedAt, e.PrevHash, e.Hash).Scan(&e.ID)
+	if err == sql.ErrNoRows {
+		return domain.ErrAuditChain
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (ar *postgresAuditRepo) Last(ctx context.Context) (domain.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log ORDER BY id DESC LIMIT 1"
+
+	e, err := scanEntry(ar.DB.QueryRowContext(ctx, query))
+	if err == sql.ErrNoRows {
+		return domain.AuditEntry{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.AuditEntry{}, err
+	}
+
+	return e, nil
+}
+
+// Fetch lists the entries matching the filter, oldest first.
+func (ar *postgresAuditRepo) Fetch(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE TRUE"
+	args := []interface{}{}
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		query += " AND " + cond + "$" + strconv.Itoa(len(args))
+	}
+	if f.Entity != "" {
+		where("entity=", f.Entity)
+	}
+	if f.EntityID != 0 {
+		where("entity_id=", f.EntityID)
+	}
+	if !f.From.IsZero() {
+		where("created_at >= ", f.From)
+	}
+	if !f.To.IsZero() {
+		where("created_at < ", f.To)
+	}
+	query += " ORDER BY id"
+
+	rows, err := ar.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.AuditEntry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEntry reads an entry. Times come back in UTC, as they were hashed.
+func scanEntry(row scanner) (domain.AuditEntry, error) {
+	var before, after string
+	e := domain.AuditEntry{}
+
+	err := row.Scan(&e.ID, &e.Actor, &e.ClaimedActor, &e.MerchantID, &e.Action, &e.Entity, &e.EntityID, &before, &after, &e.RequestID, &e.SourceIP, &e.CreatedAt, &e.PrevHash, &e.Hash)
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	e.Before = []byte(before)
+	e.After = []byte(after)
+	e.CreatedAt = e.CreatedAt.UTC()
+
+	return e, nil
+}
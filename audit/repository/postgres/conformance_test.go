@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	auditRepo "github.com/hezbymuhammad/payment-gateway/audit/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.AuditRepository(t, func(t *testing.T) domain.AuditRepository {
+		return auditRepo.NewAuditRepository(migrationtest.NewPostgresDB(t))
+	})
+}
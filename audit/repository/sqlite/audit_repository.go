@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const auditColumns = "id, actor, claimed_actor, merchant_id, action, entity, entity_id, before, after, request_id, source_ip, created_at, prev_hash, hash"
+
+type sqliteAuditRepo struct {
+	DB *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) domain.AuditRepository {
+	return &sqliteAuditRepo{
+		DB: db,
+	}
+}
+
+// Append adds the entry only if it follows the last one, checked in the
+// same statement so that no other entry can come in between.
+func (ar *sqliteAuditRepo) Append(ctx context.Context, e *domain.AuditEntry) error {
+	query := "INSERT INTO audit_log (actor, claimed_actor, merchant_id, action, entity, entity_id, before, after, request_id, source_ip, created_at, prev_hash, hash) SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ? WHERE COALESCE((SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1), '')=?"
+
+	res, err := ar.DB.ExecContext(ctx, query, e.Actor, e.ClaimedActor, e.MerchantID, e.Action, e.Entity, e.EntityID, string(e.Before), string(e.After), e.RequestID, e.SourceIP, e.CreatedAt, e.PrevHash, e.Hash, e.PrevHash)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrAuditChain
+	}
+
+	e.ID, err = res.LastInsertId()
+	return err
+}
+
+func (ar *sqliteAuditRepo) Last(ctx context.Context) (domain.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log ORDER BY id DESC LIMIT 1"
+
+	e, err := scanEntry(ar.DB.QueryRowContext(ctx, query))
+	if err == sql.ErrNoRows {
+		return domain.AuditEntry{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.AuditEntry{}, err
+	}
+
+	return e, nil
+}
+
+// Fetch lists the entries matching the filter, oldest first.
+func (ar *sqliteAuditRepo) Fetch(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE 1=1"
+	args := []interface{}{}
+	if f.Entity != "" {
+		query += " AND entity=?"
+		args = append(args, f.Entity)
+	}
+	if f.EntityID != 0 {
+		query += " AND entity_id=?"
+		args = append(args, f.EntityID)
+	}
+	if !f.From.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		query += " AND created_at < ?"
+		args = append(args, f.To.UTC())
+	}
+	query += " ORDER BY id"
+
+	rows, err := ar.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.AuditEntry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, e)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row scanner) (domain.AuditEntry, error) {
+	var before, after string
+	e := domain.AuditEntry{}
+
+	err := row.Scan(&e.ID, &e.Actor, &e.ClaimedActor, &e.MerchantID, &e.Action, &e.Entity, &e.EntityID, &before, &after, &e.RequestID, &e.SourceIP, &e.CreatedAt, &e.PrevHash, &e.Hash)
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	e.Before = []byte(before)
+	e.After = []byte(after)
+	e.CreatedAt = e.CreatedAt.UTC()
+
+	return e, nil
+}
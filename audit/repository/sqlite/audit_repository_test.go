@@ -0,0 +1,40 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	auditRepo "github.com/hezbymuhammad/payment-gateway/audit/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestAppendOnly(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	ar := auditRepo.NewAuditRepository(db)
+	e := domain.AuditEntry{
+		Actor:     "ops@lorem.id",
+		Action:    "merchant.approve",
+		Entity:    domain.AuditMerchant,
+		EntityID:  1,
+		Before:    json.RawMessage(`{"status":"submitted"}`),
+		After:     json.RawMessage(`{"status":"approved"}`),
+		CreatedAt: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+	}
+	e.Hash = audit.Hash(e)
+	assert.NoError(t, ar.Append(context.TODO(), &e))
+
+	_, err := db.Exec("UPDATE audit_log SET actor='someone else' WHERE id=?", e.ID)
+	assert.Error(t, err)
+	_, err = db.Exec("DELETE FROM audit_log WHERE id=?", e.ID)
+	assert.Error(t, err)
+
+	res, err := ar.Last(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, e, res)
+}
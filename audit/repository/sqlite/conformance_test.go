@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	auditRepo "github.com/hezbymuhammad/payment-gateway/audit/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.AuditRepository(t, func(t *testing.T) domain.AuditRepository {
+		return auditRepo.NewAuditRepository(migrationtest.NewDB(t))
+	})
+}
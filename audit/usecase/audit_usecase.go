@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type auditUsecase struct {
+	auditRepo domain.AuditRepository
+
+	// mu keeps this process's entries in line, each reading the last
+	// hash before appending after it.
+	mu sync.Mutex
+}
+
+func NewAuditUsecase(ar domain.AuditRepository) domain.AuditUsecase {
+	return &auditUsecase{
+		auditRepo: ar,
+	}
+}
+
+// Record appends the entry to the log, filling in the request it was made
+// in and chaining it to the last entry.
+func (au *auditUsecase) Record(ctx context.Context, e *domain.AuditEntry) error {
+	r := audit.RequestFrom(ctx)
+	e.Actor = r.Actor
+	e.ClaimedActor = r.ClaimedActor
+	e.RequestID = r.RequestID
+	e.SourceIP = r.SourceIP
+	e.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	au.mu.Lock()
+	defer au.mu.Unlock()
+
+	last, err := au.auditRepo.Last(ctx)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+	e.PrevHash = last.Hash
+	e.Hash = audit.Hash(*e)
+
+	return au.auditRepo.Append(ctx, e)
+}
+
+func (au *auditUsecase) Fetch(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	return au.auditRepo.Fetch(ctx, f)
+}
+
+// Verify walks the whole log, checking each entry against its hash and the
+// entry before it.
+func (au *auditUsecase) Verify(ctx context.Context) (domain.AuditVerification, error) {
+	entries, err := au.auditRepo.Fetch(ctx, domain.AuditFilter{})
+	if err != nil {
+		return domain.AuditVerification{}, err
+	}
+
+	prev := ""
+	for _, e := range entries {
+		if e.PrevHash != prev || audit.Hash(e) != e.Hash {
+			return domain.AuditVerification{Entries: len(entries), BrokenAt: e.ID}, nil
+		}
+		prev = e.Hash
+	}
+
+	return domain.AuditVerification{Valid: true, Entries: len(entries)}, nil
+}
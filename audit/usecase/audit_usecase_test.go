@@ -0,0 +1,129 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	auditUsecase "github.com/hezbymuhammad/payment-gateway/audit/usecase"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+var request = audit.Request{Actor: audit.Anonymous, ClaimedActor: "ops@lorem.id", RequestID: "req-1", SourceIP: "203.0.113.7"}
+
+func TestRecord(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+	last := domain.AuditEntry{ID: 6, Hash: "lorem"}
+	mockAuditRepo.On("Last", mock.Anything).Return(last, nil).Once()
+	mockAuditRepo.On("Append", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.PrevHash == "lorem" && e.Hash == audit.Hash(*e) && e.Actor == audit.Anonymous && e.ClaimedActor == "ops@lorem.id" && e.RequestID == "req-1" && e.SourceIP == "203.0.113.7" && !e.CreatedAt.IsZero()
+	})).Return(nil).Once()
+	u := auditUsecase.NewAuditUsecase(mockAuditRepo)
+
+	e := domain.AuditEntry{Action: "merchant.approve", Entity: domain.AuditMerchant, EntityID: 1, Before: json.RawMessage(`{"status":"submitted"}`), After: json.RawMessage(`{"status":"approved"}`)}
+	err := u.Record(audit.WithRequest(context.TODO(), request), &e)
+
+	assert.NoError(t, err)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestRecordFirst(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Last", mock.Anything).Return(domain.AuditEntry{}, domain.ErrNotFound).Once()
+	mockAuditRepo.On("Append", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.PrevHash == "" && e.Actor == audit.System
+	})).Return(nil).Once()
+	u := auditUsecase.NewAuditUsecase(mockAuditRepo)
+
+	err := u.Record(context.TODO(), &domain.AuditEntry{Action: "transaction.store", Entity: domain.AuditTransaction, EntityID: 4})
+
+	assert.NoError(t, err)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestRecordError(t *testing.T) {
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Last", mock.Anything).Return(domain.AuditEntry{}, fmt.Errorf("some error")).Once()
+	u := auditUsecase.NewAuditUsecase(mockAuditRepo)
+
+	err := u.Record(context.TODO(), &domain.AuditEntry{})
+
+	assert.Error(t, err)
+	mockAuditRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestVerify(t *testing.T) {
+	entries := chain(3)
+	mockAuditRepo := new(mocks.AuditRepository)
+	mockAuditRepo.On("Fetch", mock.Anything, domain.AuditFilter{}).Return(entries, nil).Once()
+	u := auditUsecase.NewAuditUsecase(mockAuditRepo)
+
+	res, err := u.Verify(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AuditVerification{Valid: true, Entries: 3}, res)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []domain.AuditEntry) []domain.AuditEntry
+		broken int64
+	}{
+		{name: "edited", broken: 2, tamper: func(entries []domain.AuditEntry) []domain.AuditEntry {
+			entries[1].After = json.RawMessage(`{"state":"refunded"}`)
+			return entries
+		}},
+		{name: "rehashed", broken: 3, tamper: func(entries []domain.AuditEntry) []domain.AuditEntry {
+			entries[1].Actor = "someone else"
+			entries[1].Hash = audit.Hash(entries[1])
+			return entries
+		}},
+		{name: "removed", broken: 3, tamper: func(entries []domain.AuditEntry) []domain.AuditEntry {
+			return append(entries[:1], entries[2:]...)
+		}},
+	}
+
+	for _, tt := range tests {
+		mockAuditRepo := new(mocks.AuditRepository)
+		mockAuditRepo.On("Fetch", mock.Anything, domain.AuditFilter{}).Return(tt.tamper(chain(3)), nil).Once()
+		u := auditUsecase.NewAuditUsecase(mockAuditRepo)
+
+		res, err := u.Verify(context.TODO())
+
+		assert.NoError(t, err)
+		assert.False(t, res.Valid, tt.name)
+		assert.Equal(t, tt.broken, res.BrokenAt, tt.name)
+	}
+}
+
+// chain gives n entries hashed one after the other.
+func chain(n int) []domain.AuditEntry {
+	entries := []domain.AuditEntry{}
+	prev := ""
+	for i := 1; i <= n; i++ {
+		e := domain.AuditEntry{
+			ID:        int64(i),
+			Actor:     "ops@lorem.id",
+			Action:    "transaction.capture",
+			Entity:    domain.AuditTransaction,
+			EntityID:  int64(i),
+			Before:    json.RawMessage(`{"state":"authorized"}`),
+			After:     json.RawMessage(`{"state":"captured"}`),
+			CreatedAt: time.Date(2026, 10, 19, 8, i, 0, 0, time.UTC),
+			PrevHash:  prev,
+		}
+		e.Hash = audit.Hash(e)
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+
+	return entries
+}
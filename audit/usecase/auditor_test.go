@@ -0,0 +1,232 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	auditUsecase "github.com/hezbymuhammad/payment-gateway/audit/usecase"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+func TestMerchantAuditorApprove(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	submitted := domain.Merchant{ID: 1, Name: "lorem", Status: domain.MerchantSubmitted, Version: 3}
+	approved := submitted
+	approved.Status = domain.MerchantApproved
+	approved.ReviewNotes = "ok"
+	approved.Version = 4
+	mockMerchants.On("GetByID", mock.Anything, int64(1)).Return(submitted, nil).Once()
+	mockMerchants.On("Approve", mock.Anything, int64(1), "ok").Return(approved, nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "merchant.approve" && e.Entity == domain.AuditMerchant && e.EntityID == 1 && e.MerchantID == 1 &&
+			string(e.Before) == `{"reviewNotes":"","status":"submitted","version":3}` &&
+			string(e.After) == `{"reviewNotes":"ok","status":"approved","version":4}`
+	})).Return(nil).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	res, err := u.Approve(context.TODO(), 1, "ok")
+
+	assert.NoError(t, err)
+	assert.Equal(t, approved, res)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestMerchantAuditorUpdateStale(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	stored := domain.Merchant{ID: 1, Name: "lorem", Version: 3}
+	mockMerchants.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Twice()
+	mockMerchants.On("Update", mock.Anything, mock.Anything).Return(domain.ErrStaleVersion).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	err := u.Update(context.TODO(), &domain.Merchant{ID: 1, Name: "ipsum", Version: 2})
+
+	assert.Equal(t, domain.ErrStaleVersion, err)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestMerchantAuditorStoreSetting(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	mockMerchants.On("StoreSetting", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		s := args.Get(1).(*domain.Setting)
+		s.ID = 2
+		s.Version = 1
+	}).Return(nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "setting.store" && e.Entity == domain.AuditSetting && e.EntityID == 2 && e.MerchantID == 1 && string(e.Before) == "null"
+	})).Return(nil).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	err := u.StoreSetting(context.TODO(), &domain.Setting{MerchantID: 1, PaymentType: "QRIS"})
+
+	assert.NoError(t, err)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestMerchantAuditorUpdateSetting(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	mockMerchants.On("GetSetting", mock.Anything, int64(2)).Return(domain.Setting{ID: 2, MerchantID: 1, Color: "RED", Version: 1}, nil).Once()
+	mockMerchants.On("UpdateSetting", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Setting).Version++
+	}).Return(nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "setting.update" && e.MerchantID == 1 && string(e.Before) == `{"color":"RED","version":1}` && string(e.After) == `{"color":"BLUE","version":2}`
+	})).Return(nil).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	err := u.UpdateSetting(context.TODO(), &domain.Setting{ID: 2, MerchantID: 1, Color: "BLUE", Version: 1})
+
+	assert.NoError(t, err)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestMerchantAuditorReads(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	mockMerchants.On("GetByID", mock.Anything, int64(1)).Return(domain.Merchant{ID: 1}, nil).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	_, err := u.GetByID(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestTransactionAuditorStore(t *testing.T) {
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		tr := args.Get(1).(*domain.Transaction)
+		tr.ID = 4
+		tr.State = domain.TransactionAuthorized
+	}).Return(domain.ErrProcessorTimeout).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "transaction.store" && e.Entity == domain.AuditTransaction && e.EntityID == 4 && e.MerchantID == 6 && string(e.Before) == "null"
+	})).Return(nil).Once()
+	u := auditUsecase.NewTransactionAuditor(mockTransactions, mockAudit)
+
+	err := u.Store(context.TODO(), &domain.Transaction{MerchantID: 6, ParentMerchantID: 6, SettingID: 1, Amount: 10000})
+
+	assert.Equal(t, domain.ErrProcessorTimeout, err)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestTransactionAuditorStoreRefused(t *testing.T) {
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Return(domain.ErrMerchantInactive).Once()
+	u := auditUsecase.NewTransactionAuditor(mockTransactions, mockAudit)
+
+	err := u.Store(context.TODO(), &domain.Transaction{MerchantID: 6})
+
+	assert.Equal(t, domain.ErrMerchantInactive, err)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestTransactionAuditorCaptureTimeout(t *testing.T) {
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	authorized := domain.Transaction{ID: 4, MerchantID: 6, State: domain.TransactionAuthorized, Version: 2}
+	failed := authorized
+	failed.ResponseCode = domain.ResponseTimeout
+	failed.Version = 3
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(authorized, nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{}, domain.ErrProcessorTimeout).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(failed, nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "transaction.capture" && e.EntityID == 4 && e.MerchantID == 6 && string(e.After) == `{"responseCode":"`+domain.ResponseTimeout+`","version":3}`
+	})).Return(nil).Once()
+	u := auditUsecase.NewTransactionAuditor(mockTransactions, mockAudit)
+
+	_, err := u.Capture(context.TODO(), 4)
+
+	assert.Equal(t, domain.ErrProcessorTimeout, err)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestTransactionAuditorUnchanged(t *testing.T) {
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	pending := domain.Transaction{ID: 4, MerchantID: 6, State: domain.TransactionPending, Version: 1}
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(pending, nil).Twice()
+	mockTransactions.On("Refund", mock.Anything, int64(4)).Return(domain.Transaction{}, domain.ErrInvalidState).Once()
+	u := auditUsecase.NewTransactionAuditor(mockTransactions, mockAudit)
+
+	_, err := u.Refund(context.TODO(), 4)
+
+	assert.Equal(t, domain.ErrInvalidState, err)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestTransactionAuditorRecordFails(t *testing.T) {
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockAudit := new(mocks.AuditUsecase)
+	authorized := domain.Transaction{ID: 4, MerchantID: 6, State: domain.TransactionAuthorized, Version: 2}
+	voided := authorized
+	voided.State = domain.TransactionVoided
+	voided.Version = 3
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(authorized, nil).Once()
+	mockTransactions.On("Void", mock.Anything, int64(4)).Return(voided, nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.Anything).Return(domain.ErrAuditChain).Once()
+	u := auditUsecase.NewTransactionAuditor(mockTransactions, mockAudit)
+
+	res, err := u.Void(context.TODO(), 4)
+
+	assert.NoError(t, err)
+	assert.Equal(t, voided, res)
+}
+
+func TestMerchantRepositoryAuditorUpdate(t *testing.T) {
+	mockMerchants := new(mocks.MerchantRepository)
+	mockAudit := new(mocks.AuditUsecase)
+	approved := domain.Merchant{ID: 1, Name: "lorem", Status: domain.MerchantApproved, Version: 3}
+	held := approved
+	held.Status = domain.MerchantPendingReview
+	mockMerchants.On("GetByID", mock.Anything, int64(1)).Return(approved, nil).Once()
+	mockMerchants.On("Update", mock.Anything, &held).Return(nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "merchant.update" && e.Entity == domain.AuditMerchant && e.EntityID == 1 && e.MerchantID == 1 &&
+			string(e.Before) == `{"status":"approved"}` && string(e.After) == `{"status":"pending_review"}`
+	})).Return(nil).Once()
+	r := auditUsecase.NewMerchantRepositoryAuditor(mockMerchants, mockAudit)
+
+	err := r.Update(context.TODO(), &held)
+
+	assert.NoError(t, err)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestMerchantRepositoryAuditorWithinChange(t *testing.T) {
+	mockMerchants := new(mocks.MerchantUsecase)
+	mockRepo := new(mocks.MerchantRepository)
+	mockAudit := new(mocks.AuditUsecase)
+	draft := domain.Merchant{ID: 1, Name: "lorem", Status: domain.MerchantDraft, Version: 3}
+	submitted := draft
+	submitted.Status = domain.MerchantSubmitted
+	submitted.Version = 4
+	r := auditUsecase.NewMerchantRepositoryAuditor(mockRepo, mockAudit)
+	mockMerchants.On("GetByID", mock.Anything, int64(1)).Return(draft, nil).Once()
+	mockMerchants.On("Submit", mock.Anything, int64(1)).Run(func(args mock.Arguments) {
+		m := draft
+		m.Status = domain.MerchantSubmitted
+		assert.NoError(t, r.Update(args.Get(0).(context.Context), &m))
+	}).Return(submitted, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	mockAudit.On("Record", mock.Anything, mock.MatchedBy(func(e *domain.AuditEntry) bool {
+		return e.Action == "merchant.submit"
+	})).Return(nil).Once()
+	u := auditUsecase.NewMerchantAuditor(mockMerchants, mockAudit)
+
+	_, err := u.Submit(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	mockAudit.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type merchantAuditor struct {
+	domain.MerchantUsecase
+	audit domain.AuditUsecase
+}
+
+// NewMerchantAuditor wraps u so that every change it makes to a merchant,
+// its documents, group or settings is recorded in the audit log. Reads go
+// straight through.
+func NewMerchantAuditor(u domain.MerchantUsecase, au domain.AuditUsecase) domain.MerchantUsecase {
+	return &merchantAuditor{
+		MerchantUsecase: u,
+		audit:           au,
+	}
+}
+
+func (ma *merchantAuditor) Store(ctx context.Context, m *domain.Merchant) error {
+	err := ma.MerchantUsecase.Store(ctx, m)
+	if m.ID != 0 {
+		record(ctx, ma.audit, domain.AuditEntry{MerchantID: m.ID, Action: "merchant.store", Entity: domain.AuditMerchant, EntityID: m.ID}, nil, *m)
+	}
+
+	return err
+}
+
+func (ma *merchantAuditor) Update(ctx context.Context, m *domain.Merchant) error {
+	_, err := ma.change(ctx, "merchant.update", m.ID, func(ctx context.Context) (domain.Merchant, error) {
+		err := ma.MerchantUsecase.Update(ctx, m)
+		return *m, err
+	})
+
+	return err
+}
+
+func (ma *merchantAuditor) AddDocument(ctx context.Context, d *domain.MerchantDocument, r io.Reader) error {
+	err := ma.MerchantUsecase.AddDocument(ctx, d, r)
+	if d.ID != 0 {
+		record(ctx, ma.audit, domain.AuditEntry{MerchantID: d.MerchantID, Action: "merchant.add_document", Entity: domain.AuditMerchantDocument, EntityID: d.ID}, nil, *d)
+	}
+
+	return err
+}
+
+func (ma *merchantAuditor) Submit(ctx context.Context, id int64) (domain.Merchant, error) {
+	return ma.change(ctx, "merchant.submit", id, func(ctx context.Context) (domain.Merchant, error) {
+		return ma.MerchantUsecase.Submit(ctx, id)
+	})
+}
+
+func (ma *merchantAuditor) Approve(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+	return ma.change(ctx, "merchant.approve", id, func(ctx context.Context) (domain.Merchant, error) {
+		return ma.MerchantUsecase.Approve(ctx, id, notes)
+	})
+}
+
+func (ma *merchantAuditor) Reject(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+	return ma.change(ctx, "merchant.reject", id, func(ctx context.Context) (domain.Merchant, error) {
+		return ma.MerchantUsecase.Reject(ctx, id, notes)
+	})
+}
+
+func (ma *merchantAuditor) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
+	err := ma.MerchantUsecase.SetChild(ctx, mg)
+	if err == nil {
+		record(ctx, ma.audit, domain.AuditEntry{MerchantID: mg.ParentMerchantID, Action: "merchant.set_child", Entity: domain.AuditMerchantGroup, EntityID: mg.ChildMerchantID}, nil, *mg)
+	}
+
+	return err
+}
+
+func (ma *merchantAuditor) StoreSetting(ctx context.Context, s *domain.Setting) error {
+	err := ma.MerchantUsecase.StoreSetting(ctx, s)
+	if s.ID != 0 {
+		record(ctx, ma.audit, domain.AuditEntry{MerchantID: s.MerchantID, Action: "setting.store", Entity: domain.AuditSetting, EntityID: s.ID}, nil, *s)
+	}
+
+	return err
+}
+
+func (ma *merchantAuditor) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+	before, err := ma.MerchantUsecase.GetSetting(ctx, s.ID)
+	if err != nil {
+		return ma.MerchantUsecase.UpdateSetting(ctx, s)
+	}
+
+	err = ma.MerchantUsecase.UpdateSetting(ctx, s)
+	after := *s
+	if err != nil {
+		var gerr error
+		after, gerr = ma.MerchantUsecase.GetSetting(ctx, s.ID)
+		if gerr != nil {
+			return err
+		}
+	}
+	record(ctx, ma.audit, domain.AuditEntry{MerchantID: before.MerchantID, Action: "setting.update", Entity: domain.AuditSetting, EntityID: s.ID}, before, after)
+
+	return err
+}
+
+// change makes a change to an existing merchant and records what it did.
+// A call that fails may still have changed the merchant, so then it is
+// read back to see.
+func (ma *merchantAuditor) change(ctx context.Context, action string, id int64, call func(ctx context.Context) (domain.Merchant, error)) (domain.Merchant, error) {
+	before, err := ma.MerchantUsecase.GetByID(ctx, id)
+	if err != nil {
+		return call(ctx)
+	}
+
+	m, err := call(audit.WithinChange(ctx))
+	after := m
+	if err != nil {
+		var gerr error
+		after, gerr = ma.MerchantUsecase.GetByID(ctx, id)
+		if gerr != nil {
+			return m, err
+		}
+	}
+	record(ctx, ma.audit, domain.AuditEntry{MerchantID: id, Action: action, Entity: domain.AuditMerchant, EntityID: id}, before, after)
+
+	return m, err
+}
+
+// record appends the fields a change made to the entry, unless it changed
+// nothing. By now the change is made, so an entry that cannot be recorded
+// is logged rather than failing the call.
+func record(ctx context.Context, au domain.AuditUsecase, e domain.AuditEntry, before, after interface{}) {
+	b, a, err := audit.Diff(before, after)
+	if err == nil && a == nil {
+		return
+	}
+	if err == nil {
+		e.Before, e.After = b, a
+		err = au.Record(ctx, &e)
+	}
+	if err != nil {
+		log.Printf("audit: %s of %s %d not recorded: %v", e.Action, e.Entity, e.EntityID, err)
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type merchantRepositoryAuditor struct {
+	domain.MerchantRepository
+	audit domain.AuditUsecase
+}
+
+// NewMerchantRepositoryAuditor wraps r for code that updates merchants
+// without going through the merchant usecase, such as the scheduled
+// screening refresh, so that those updates are recorded in the audit log
+// too. An update made within a merchant usecase call that is already being
+// recorded is not recorded again. Everything else goes straight through.
+func NewMerchantRepositoryAuditor(r domain.MerchantRepository, au domain.AuditUsecase) domain.MerchantRepository {
+	return &merchantRepositoryAuditor{
+		MerchantRepository: r,
+		audit:              au,
+	}
+}
+
+// Update records the merchant update the same way as the merchant
+// usecase's auditor. A failed update may still have changed the merchant,
+// so then it is read back to see.
+func (ra *merchantRepositoryAuditor) Update(ctx context.Context, m *domain.Merchant) error {
+	if audit.InChange(ctx) {
+		return ra.MerchantRepository.Update(ctx, m)
+	}
+	before, err := ra.MerchantRepository.GetByID(ctx, m.ID)
+	if err != nil {
+		return ra.MerchantRepository.Update(ctx, m)
+	}
+
+	err = ra.MerchantRepository.Update(ctx, m)
+	after := *m
+	if err != nil {
+		var gerr error
+		after, gerr = ra.MerchantRepository.GetByID(ctx, m.ID)
+		if gerr != nil {
+			return err
+		}
+	}
+	record(ctx, ra.audit, domain.AuditEntry{MerchantID: m.ID, Action: "merchant.update", Entity: domain.AuditMerchant, EntityID: m.ID}, before, after)
+
+	return err
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type transactionAuditor struct {
+	domain.TransactionUsecase
+	audit domain.AuditUsecase
+}
+
+// NewTransactionAuditor wraps u so that every change it makes to a
+// transaction is recorded in the audit log, whether it comes through the
+// API or from within the gateway, such as a subscription being billed.
+// Reads go straight through.
+func NewTransactionAuditor(u domain.TransactionUsecase, au domain.AuditUsecase) domain.TransactionUsecase {
+	return &transactionAuditor{
+		TransactionUsecase: u,
+		audit:              au,
+	}
+}
+
+// Store records the transaction as taken, whatever came of it. A payment
+// that was declined or failed to authorize is stored all the same.
+func (ta *transactionAuditor) Store(ctx context.Context, t *domain.Transaction) error {
+	err := ta.TransactionUsecase.Store(ctx, t)
+	if t.ID != 0 {
+		record(ctx, ta.audit, domain.AuditEntry{MerchantID: t.MerchantID, Action: "transaction.store", Entity: domain.AuditTransaction, EntityID: t.ID}, nil, *t)
+	}
+
+	return err
+}
+
+func (ta *transactionAuditor) Patch(ctx context.Context, id int64, p *domain.TransactionPatch) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.patch", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Patch(ctx, id, p)
+	})
+}
+
+func (ta *transactionAuditor) Capture(ctx context.Context, id int64) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.capture", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Capture(ctx, id)
+	})
+}
+
+func (ta *transactionAuditor) Refund(ctx context.Context, id int64) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.refund", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Refund(ctx, id)
+	})
+}
+
+func (ta *transactionAuditor) Void(ctx context.Context, id int64) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.void", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Void(ctx, id)
+	})
+}
+
+func (ta *transactionAuditor) Complete(ctx context.Context, id int64, amount int64, reference string) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.complete", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Complete(ctx, id, amount, reference)
+	})
+}
+
+func (ta *transactionAuditor) Fail(ctx context.Context, id int64, message string) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.fail", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Fail(ctx, id, message)
+	})
+}
+
+func (ta *transactionAuditor) Approve(ctx context.Context, id int64) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.approve", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Approve(ctx, id)
+	})
+}
+
+func (ta *transactionAuditor) Reject(ctx context.Context, id int64) (domain.Transaction, error) {
+	return ta.change(ctx, "transaction.reject", id, func() (domain.Transaction, error) {
+		return ta.TransactionUsecase.Reject(ctx, id)
+	})
+}
+
+// change makes a change to an existing transaction and records what it
+// did. A call that fails may still have changed the transaction, as a
+// processor timeout can, so then it is read back to see.
+func (ta *transactionAuditor) change(ctx context.Context, action string, id int64, call func() (domain.Transaction, error)) (domain.Transaction, error) {
+	before, err := ta.TransactionUsecase.GetByID(ctx, id)
+	if err != nil {
+		return call()
+	}
+
+	t, err := call()
+	after := t
+	if err != nil {
+		var gerr error
+		after, gerr = ta.TransactionUsecase.GetByID(ctx, id)
+		if gerr != nil {
+			return t, err
+		}
+	}
+	record(ctx, ta.audit, domain.AuditEntry{MerchantID: before.MerchantID, Action: action, Entity: domain.AuditTransaction, EntityID: id}, before, after)
+
+	return t, err
+}
@@ -0,0 +1,76 @@
+// Package blob keeps files for the gateway, such as dispute evidence.
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type localStore struct {
+	root string
+}
+
+// NewLocalStore keeps blobs as files under root.
+func NewLocalStore(root string) domain.BlobStore {
+	return &localStore{root: root}
+}
+
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return 0, err
+	}
+
+	// Write under a temporary name so a failed upload never leaves a partial
+	// file behind the key.
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	err = f.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	return n, os.Rename(f.Name(), path)
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, domain.ErrNotFound
+	}
+
+	return f, err
+}
+
+// path maps a key to a file under root, refusing keys that would escape it.
+func (s *localStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || clean == "/" || strings.Contains(key, "..") {
+		return "", domain.ErrNotFound
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
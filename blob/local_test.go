@@ -0,0 +1,44 @@
+package blob_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/blob"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+func TestLocalStore(t *testing.T) {
+	s := blob.NewLocalStore(t.TempDir())
+
+	n, err := s.Put(context.TODO(), "disputes/1/abc", strings.NewReader("receipt"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+
+	r, err := s.Get(context.TODO(), "disputes/1/abc")
+	assert.NoError(t, err)
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "receipt", string(b))
+}
+
+func TestLocalStoreMissing(t *testing.T) {
+	s := blob.NewLocalStore(t.TempDir())
+
+	_, err := s.Get(context.TODO(), "disputes/1/abc")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	s := blob.NewLocalStore(t.TempDir())
+
+	_, err := s.Put(context.TODO(), "../outside", strings.NewReader("x"))
+	assert.Equal(t, domain.ErrNotFound, err)
+	_, err = s.Get(context.TODO(), "disputes/../../etc/passwd")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
@@ -0,0 +1,41 @@
+// Package branding holds the template helpers shared by the customer-facing
+// pages that are rendered in a merchant's colours.
+package branding
+
+import (
+	"html/template"
+	"regexp"
+	"strconv"
+)
+
+var cssColor = regexp.MustCompile(`^(#[0-9A-Fa-f]{3,8}|[A-Za-z]+)$`)
+
+// Funcs is the FuncMap every branded page template is parsed with.
+var Funcs = template.FuncMap{
+	"money": Money,
+	"color": Color,
+}
+
+// Money groups the digits of an amount in thousands.
+func Money(amount int64) string {
+	s := strconv.FormatInt(amount, 10)
+	sign := ""
+	if amount < 0 {
+		sign, s = "-", s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return sign + s
+}
+
+// Color passes through setting colours that are safe inside a style block.
+// Anything else falls back to a neutral grey.
+func Color(c string) template.CSS {
+	if !cssColor.MatchString(c) {
+		return template.CSS("#999")
+	}
+
+	return template.CSS(c)
+}
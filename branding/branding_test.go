@@ -0,0 +1,25 @@
+package branding_test
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/branding"
+)
+
+func TestMoney(t *testing.T) {
+	assert.Equal(t, "0", branding.Money(0))
+	assert.Equal(t, "999", branding.Money(999))
+	assert.Equal(t, "1,000", branding.Money(1000))
+	assert.Equal(t, "111,000", branding.Money(111000))
+	assert.Equal(t, "-1,234,567", branding.Money(-1234567))
+}
+
+func TestColor(t *testing.T) {
+	assert.Equal(t, template.CSS("RED"), branding.Color("RED"))
+	assert.Equal(t, template.CSS("#ff0000"), branding.Color("#ff0000"))
+	assert.Equal(t, template.CSS("#999"), branding.Color("red;}</style>"))
+	assert.Equal(t, template.CSS("#999"), branding.Color(""))
+}
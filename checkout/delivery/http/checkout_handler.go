@@ -0,0 +1,201 @@
+package http
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/branding"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+//go:embed templates/checkout.html
+var templates embed.FS
+
+var page = template.Must(template.New("checkout.html").Funcs(branding.Funcs).ParseFS(templates, "templates/checkout.html"))
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type CheckoutHandler struct {
+	Usecase domain.CheckoutUsecase
+}
+
+func NewCheckoutHandler(e *echo.Echo, u domain.CheckoutUsecase) *CheckoutHandler {
+	handler := &CheckoutHandler{
+		Usecase: u,
+	}
+
+	e.POST("/checkout/sessions", handler.Create)
+	e.GET("/checkout/:id", handler.Render)
+	e.POST("/checkout/:id", handler.Complete)
+	e.POST("/checkout/:id/cancel", handler.Cancel)
+
+	return handler
+}
+
+func (h *CheckoutHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.CheckoutSession
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.Amount <= 0 || !validURL(data.SuccessURL) || !validURL(data.CancelURL) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Create(ctx, &data)
+	if err == domain.ErrPaymentMethod {
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+// Render shows the hosted page. Sessions that are already finished send the
+// customer straight back to the merchant.
+func (h *CheckoutHandler) Render(c echo.Context) error {
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetPage(ctx, c.Param("id"))
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	switch res.Session.Status {
+	case domain.CheckoutComplete:
+		return c.Redirect(http.StatusSeeOther, redirectURL(res.Session.SuccessURL, res.Session.ID))
+	case domain.CheckoutCanceled:
+		return c.Redirect(http.StatusSeeOther, redirectURL(res.Session.CancelURL, res.Session.ID))
+	case domain.CheckoutExpired:
+		return render(c, http.StatusGone, res, "")
+	default:
+		return render(c, http.StatusOK, res, "")
+	}
+}
+
+// Complete takes the submitted form. A captured payment redirects to the
+// success URL and one still going shows that it is being processed;
+// anything else shows the page again with the reason.
+func (h *CheckoutHandler) Complete(c echo.Context) error {
+	ctx := c.Request().Context()
+	id := c.Param("id")
+	settingID, _ := strconv.ParseInt(c.FormValue("settingId"), 10, 64)
+	month, _ := strconv.Atoi(c.FormValue("expiryMonth"))
+	year, _ := strconv.Atoi(c.FormValue("expiryYear"))
+	payment := domain.CheckoutPayment{
+		SettingID: settingID,
+		Card: domain.Card{
+			Number:      c.FormValue("number"),
+			ExpiryMonth: month,
+			ExpiryYear:  year,
+		},
+	}
+
+	s, t, err := h.Usecase.Complete(ctx, id, &payment)
+	if err == nil && s.Status == domain.CheckoutComplete {
+		return c.Redirect(http.StatusSeeOther, redirectURL(s.SuccessURL, s.ID))
+	}
+	if s.Status == domain.CheckoutProcessing && s.TransactionID != 0 {
+		res, err := h.Usecase.GetPage(ctx, id)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+		}
+		return render(c, http.StatusAccepted, res, "")
+	}
+
+	status := http.StatusInternalServerError
+	message := "Payment could not be processed, please try again"
+	switch err {
+	case nil:
+		status = http.StatusPaymentRequired
+		message = "Payment declined"
+		if t.ResponseMessage != "" {
+			message += ": " + t.ResponseMessage
+		}
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrSessionClosed:
+		if s.Status == domain.CheckoutComplete {
+			return c.Redirect(http.StatusSeeOther, redirectURL(s.SuccessURL, s.ID))
+		}
+		status = http.StatusConflict
+		message = err.Error()
+	case domain.ErrSessionExpired:
+		status = http.StatusGone
+		message = err.Error()
+	case domain.ErrInvalidCard, domain.ErrCardExpired, domain.ErrUnknownBrand, domain.ErrPaymentMethod:
+		status = http.StatusUnprocessableEntity
+		message = err.Error()
+	}
+
+	res, err := h.Usecase.GetPage(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return render(c, status, res, message)
+}
+
+func (h *CheckoutHandler) Cancel(c echo.Context) error {
+	ctx := c.Request().Context()
+	s, err := h.Usecase.Cancel(ctx, c.Param("id"))
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err == domain.ErrSessionClosed {
+		return c.Redirect(http.StatusSeeOther, "/checkout/"+s.ID)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.Redirect(http.StatusSeeOther, redirectURL(s.CancelURL, s.ID))
+}
+
+type view struct {
+	domain.CheckoutPage
+	Error string
+}
+
+func render(c echo.Context, status int, p domain.CheckoutPage, message string) error {
+	var buf bytes.Buffer
+	err := page.Execute(&buf, view{CheckoutPage: p, Error: message})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.HTML(status, buf.String())
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// redirectURL adds the session id to a merchant URL so the merchant can look
+// the session up when the customer lands back on their site.
+func redirectURL(s string, id string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	q := u.Query()
+	q.Set("session_id", id)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,188 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	checkoutHttp "github.com/hezbymuhammad/payment-gateway/checkout/delivery/http"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+func checkoutPage(status string) domain.CheckoutPage {
+	card := domain.Setting{ID: 1, MerchantID: 6, Color: "RED", PaymentType: "CARD", PaymentName: "VISA"}
+	return domain.CheckoutPage{
+		Session: domain.CheckoutSession{
+			ID:         "cs_1",
+			MerchantID: 6,
+			Amount:     50000,
+			Currency:   "IDR",
+			SuccessURL: "https://shop.example.com/ok?order=9",
+			CancelURL:  "https://shop.example.com/cancel",
+			Status:     status,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		},
+		Merchant: domain.Merchant{ID: 6, Name: "CAFE"},
+		Setting:  card,
+		Methods:  []domain.Setting{card},
+	}
+}
+
+func TestCreateInvalidRedirect(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+
+	e := echo.New()
+	body := `{"merchantId":6,"amount":50000,"successUrl":"javascript:alert(1)","cancelUrl":"https://shop.example.com/cancel"}`
+	req, err := http.NewRequest(echo.POST, "/checkout/sessions", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/sessions")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Create(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRender(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+	mockUsecase.On("GetPage", mock.Anything, "cs_1").Return(checkoutPage(domain.CheckoutOpen), nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/checkout/cs_1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("cs_1")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Render(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "solid RED")
+	assert.Contains(t, body, "IDR 50,000")
+	assert.Contains(t, body, `value="1" checked> VISA`)
+	assert.Contains(t, body, `action="/checkout/cs_1"`)
+}
+
+func TestRenderCompletedRedirects(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+	mockUsecase.On("GetPage", mock.Anything, "cs_1").Return(checkoutPage(domain.CheckoutComplete), nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/checkout/cs_1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("cs_1")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Render(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "https://shop.example.com/ok?order=9&session_id=cs_1", rec.Header().Get("Location"))
+}
+
+func TestComplete(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+	s := checkoutPage(domain.CheckoutComplete).Session
+	mockUsecase.On("Complete", mock.Anything, "cs_1", &domain.CheckoutPayment{
+		SettingID: 1,
+		Card:      domain.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2030},
+	}).Return(s, domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+
+	form := url.Values{"settingId": {"1"}, "number": {"4111111111111111"}, "expiryMonth": {"12"}, "expiryYear": {"2030"}}
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/checkout/cs_1", strings.NewReader(form.Encode()))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("cs_1")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Complete(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "https://shop.example.com/ok?order=9&session_id=cs_1", rec.Header().Get("Location"))
+}
+
+func TestCompleteDeclined(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+	mockUsecase.On("Complete", mock.Anything, "cs_1", mock.Anything).
+		Return(checkoutPage(domain.CheckoutOpen).Session, domain.Transaction{ID: 4, State: domain.TransactionDeclined, ResponseMessage: "Do not honor"}, nil).Once()
+	mockUsecase.On("GetPage", mock.Anything, "cs_1").Return(checkoutPage(domain.CheckoutOpen), nil).Once()
+
+	form := url.Values{"settingId": {"1"}, "number": {"4000000000000002"}, "expiryMonth": {"12"}, "expiryYear": {"2030"}}
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/checkout/cs_1", strings.NewReader(form.Encode()))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("cs_1")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Complete(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Payment declined: Do not honor")
+}
+
+func TestCompleteHeldForReview(t *testing.T) {
+	mockUsecase := new(mocks.CheckoutUsecase)
+	s := checkoutPage(domain.CheckoutProcessing).Session
+	s.TransactionID = 4
+	mockUsecase.On("Complete", mock.Anything, "cs_1", mock.Anything).
+		Return(s, domain.Transaction{ID: 4, State: domain.TransactionReview}, nil).Once()
+	mockUsecase.On("GetPage", mock.Anything, "cs_1").Return(checkoutPage(domain.CheckoutProcessing), nil).Once()
+
+	form := url.Values{"settingId": {"1"}, "number": {"4111111111111111"}, "expiryMonth": {"12"}, "expiryYear": {"2030"}}
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/checkout/cs_1", strings.NewReader(form.Encode()))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/checkout/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("cs_1")
+
+	handler := checkoutHttp.NewCheckoutHandler(echo.New(), mockUsecase)
+	err = handler.Complete(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Your payment is being processed.")
+	assert.NotContains(t, rec.Body.String(), "declined")
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const sessionColumns = "id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id"
+
+type postgresCheckoutRepo struct {
+	DB *sql.DB
+}
+
+func NewCheckoutRepository(db *sql.DB) domain.CheckoutRepository {
+	return &postgresCheckoutRepo{
+		DB: db,
+	}
+}
+
+func (cr *postgresCheckoutRepo) Store(ctx context.Context, s *domain.CheckoutSession) error {
+	query := "INSERT INTO checkout_sessions (" + sessionColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+
+	_, err := cr.exec(
+		ctx,
+		query,
+		s.ID,
+		s.MerchantID,
+		s.Amount,
+		s.Currency,
+		s.SuccessURL,
+		s.CancelURL,
+		s.Status,
+		s.ExpiresAt,
+		s.TransactionID,
+	)
+	return err
+}
+
+func (cr *postgresCheckoutRepo) GetByID(ctx context.Context, id string) (domain.CheckoutSession, error) {
+	query := "SELECT " + sessionColumns + " FROM checkout_sessions WHERE id=$1"
+
+	data, err := scanSession(cr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.CheckoutSession{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.CheckoutSession{}, err
+	}
+
+	return data, nil
+}
+
+// FetchProcessing lists the sessions waiting on a payment that was neither
+// captured nor failed when it was made.
+func (cr *postgresCheckoutRepo) FetchProcessing(ctx context.Context) ([]domain.CheckoutSession, error) {
+	query := "SELECT " + sessionColumns + " FROM checkout_sessions WHERE status='processing' AND transaction_id <> 0 ORDER BY expires_at"
+
+	rows, err := cr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.CheckoutSession{}
+	for rows.Next() {
+		data, err := scanSession(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// Claim moves an open, unexpired session to processing. Only one caller can
+// win, which is what stops a session from being paid twice.
+func (cr *postgresCheckoutRepo) Claim(ctx context.Context, id string, now time.Time) (bool, error) {
+	query := "UPDATE checkout_sessions SET status='processing' WHERE id=$1 AND status='open' AND expires_at > $2"
+
+	res, err := cr.exec(ctx, query, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Update saves the session if it is still in the from status, and returns
+// domain.ErrSessionClosed if something else moved it first.
+func (cr *postgresCheckoutRepo) Update(ctx context.Context, s *domain.CheckoutSession, from string) error {
+	query := "UPDATE checkout_sessions SET status=$1, transaction_id=$2 WHERE id=$3 AND status=$4"
+
+	res, err := cr.exec(ctx, query, s.Status, s.TransactionID, s.ID, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrSessionClosed
+	}
+
+	return nil
+}
+
+func (cr *postgresCheckoutRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := cr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row scanner) (domain.CheckoutSession, error) {
+	data := domain.CheckoutSession{}
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Amount,
+		&data.Currency,
+		&data.SuccessURL,
+		&data.CancelURL,
+		&data.Status,
+		&data.ExpiresAt,
+		&data.TransactionID,
+	)
+	if err != nil {
+		return domain.CheckoutSession{}, err
+	}
+
+	data.ExpiresAt = data.ExpiresAt.UTC()
+	return data, nil
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const sessionColumns = "id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id"
+
+type sqliteCheckoutRepo struct {
+	DB *sql.DB
+}
+
+func NewCheckoutRepository(db *sql.DB) domain.CheckoutRepository {
+	return &sqliteCheckoutRepo{
+		DB: db,
+	}
+}
+
+func (cr *sqliteCheckoutRepo) Store(ctx context.Context, s *domain.CheckoutSession) error {
+	query := "INSERT INTO checkout_sessions (id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	_, err := cr.exec(
+		ctx,
+		query,
+		s.ID,
+		s.MerchantID,
+		s.Amount,
+		s.Currency,
+		s.SuccessURL,
+		s.CancelURL,
+		s.Status,
+		s.ExpiresAt,
+		s.TransactionID,
+	)
+	return err
+}
+
+func (cr *sqliteCheckoutRepo) GetByID(ctx context.Context, id string) (domain.CheckoutSession, error) {
+	query := "SELECT " + sessionColumns + " FROM checkout_sessions WHERE id=? LIMIT 1"
+
+	data, err := scanSession(cr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.CheckoutSession{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.CheckoutSession{}, err
+	}
+
+	return data, nil
+}
+
+// FetchProcessing lists the sessions waiting on a payment that was neither
+// captured nor failed when it was made.
+func (cr *sqliteCheckoutRepo) FetchProcessing(ctx context.Context) ([]domain.CheckoutSession, error) {
+	query := "SELECT " + sessionColumns + " FROM checkout_sessions WHERE status='processing' AND transaction_id != 0 ORDER BY expires_at"
+
+	rows, err := cr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.CheckoutSession{}
+	for rows.Next() {
+		data, err := scanSession(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// Claim moves an open, unexpired session to processing. Only one caller can
+// win, which is what stops a session from being paid twice.
+func (cr *sqliteCheckoutRepo) Claim(ctx context.Context, id string, now time.Time) (bool, error) {
+	query := "UPDATE checkout_sessions SET status='processing' WHERE id=? AND status='open' AND expires_at > ?"
+
+	res, err := cr.exec(ctx, query, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Update saves the session if it is still in the from status, and returns
+// domain.ErrSessionClosed if something else moved it first.
+func (cr *sqliteCheckoutRepo) Update(ctx context.Context, s *domain.CheckoutSession, from string) error {
+	query := "UPDATE checkout_sessions SET status=?, transaction_id=? WHERE id=? AND status=?"
+
+	res, err := cr.exec(ctx, query, s.Status, s.TransactionID, s.ID, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrSessionClosed
+	}
+
+	return nil
+}
+
+func (cr *sqliteCheckoutRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row scanner) (domain.CheckoutSession, error) {
+	data := domain.CheckoutSession{}
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Amount,
+		&data.Currency,
+		&data.SuccessURL,
+		&data.CancelURL,
+		&data.Status,
+		&data.ExpiresAt,
+		&data.TransactionID,
+	)
+
+	return data, err
+}
@@ -0,0 +1,111 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	checkoutRepo "github.com/hezbymuhammad/payment-gateway/checkout/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+var now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.CheckoutSession{ID: "cs_1", MerchantID: 6, Amount: 50000, Currency: "IDR", SuccessURL: "https://shop.example.com/ok", CancelURL: "https://shop.example.com/cancel", Status: domain.CheckoutOpen, ExpiresAt: now}
+	query := regexp.QuoteMeta("INSERT INTO checkout_sessions (id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs("cs_1", 6, 50000, "IDR", data.SuccessURL, data.CancelURL, domain.CheckoutOpen, now, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	err = cr.Store(context.TODO(), data)
+	assert.NoError(t, err)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "amount", "currency", "success_url", "cancel_url", "status", "expires_at", "transaction_id"})
+	query := regexp.QuoteMeta("SELECT id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id FROM checkout_sessions WHERE id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs("cs_1").WillReturnRows(rows)
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	_, err = cr.GetByID(context.TODO(), "cs_1")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE checkout_sessions SET status='processing' WHERE id=? AND status='open' AND expires_at > ?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs("cs_1", now).WillReturnResult(sqlmock.NewResult(0, 1))
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	claimed, err := cr.Claim(context.TODO(), "cs_1", now)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestClaimLost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	prep := mock.ExpectPrepare("UPDATE checkout_sessions")
+	prep.ExpectExec().WithArgs("cs_1", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	claimed, err := cr.Claim(context.TODO(), "cs_1", now)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestUpdateFromStatusLost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE checkout_sessions SET status=?, transaction_id=? WHERE id=? AND status=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(domain.CheckoutCanceled, 0, "cs_1", domain.CheckoutOpen).WillReturnResult(sqlmock.NewResult(0, 0))
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	err = cr.Update(context.TODO(), &domain.CheckoutSession{ID: "cs_1", Status: domain.CheckoutCanceled}, domain.CheckoutOpen)
+	assert.Equal(t, domain.ErrSessionClosed, err)
+}
+
+func TestFetchProcessing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "amount", "currency", "success_url", "cancel_url", "status", "expires_at", "transaction_id"}).
+		AddRow("cs_1", 6, 50000, "IDR", "https://shop.example.com/ok", "https://shop.example.com/cancel", domain.CheckoutProcessing, now, 4)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, amount, currency, success_url, cancel_url, status, expires_at, transaction_id FROM checkout_sessions WHERE status='processing' AND transaction_id != 0 ORDER BY expires_at")
+	mock.ExpectQuery(query).WillReturnRows(rows)
+	cr := checkoutRepo.NewCheckoutRepository(db)
+
+	res, err := cr.FetchProcessing(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, int64(4), res[0].TransactionID)
+}
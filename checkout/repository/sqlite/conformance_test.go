@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	checkoutRepo "github.com/hezbymuhammad/payment-gateway/checkout/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.CheckoutRepository(t, func(t *testing.T) domain.CheckoutRepository {
+		return checkoutRepo.NewCheckoutRepository(migrationtest.NewDB(t))
+	})
+}
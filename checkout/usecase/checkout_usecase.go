@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// supported lists the payment types the hosted page can collect details for.
+// Settings of any other type are not offered to the customer.
+var supported = map[string]bool{
+	"CARD": true,
+}
+
+// Config holds the checkout settings that come from config.json. BaseURL is
+// where session URLs point and TTL is how long a session stays payable.
+type Config struct {
+	BaseURL string
+	TTL     time.Duration
+}
+
+type checkoutUsecase struct {
+	checkoutRepo domain.CheckoutRepository
+	merchantRepo domain.MerchantRepository
+	cardVault    domain.CardVaultUsecase
+	transactions domain.TransactionUsecase
+	cfg          Config
+}
+
+func NewCheckoutUsecase(cr domain.CheckoutRepository, mr domain.MerchantRepository, cv domain.CardVaultUsecase, tu domain.TransactionUsecase, cfg Config) domain.CheckoutUsecase {
+	return &checkoutUsecase{
+		checkoutRepo: cr,
+		merchantRepo: mr,
+		cardVault:    cv,
+		transactions: tu,
+		cfg:          cfg,
+	}
+}
+
+func (cu *checkoutUsecase) Create(ctx context.Context, s *domain.CheckoutSession) error {
+	methods, err := cu.methods(ctx, s.MerchantID)
+	if err != nil {
+		return err
+	}
+	if len(methods) == 0 {
+		return domain.ErrPaymentMethod
+	}
+
+	s.ID, err = newSessionID()
+	if err != nil {
+		return err
+	}
+	if s.Currency == "" {
+		s.Currency = "IDR"
+	}
+	s.Status = domain.CheckoutOpen
+	s.ExpiresAt = time.Now().UTC().Add(cu.cfg.TTL).Truncate(time.Second)
+	s.TransactionID = 0
+
+	err = cu.checkoutRepo.Store(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	s.URL = cu.url(s.ID)
+	return nil
+}
+
+// GetPage loads a session with the merchant branding. An open session past
+// its expiry is marked expired on the way, and one waiting on its payment is
+// settled if the payment has since ended.
+func (cu *checkoutUsecase) GetPage(ctx context.Context, id string) (domain.CheckoutPage, error) {
+	s, err := cu.checkoutRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.CheckoutPage{}, err
+	}
+	now := time.Now()
+	if s.Status == domain.CheckoutOpen && !now.Before(s.ExpiresAt) {
+		s.Status = domain.CheckoutExpired
+		err = cu.checkoutRepo.Update(ctx, &s, domain.CheckoutOpen)
+		if err == domain.ErrSessionClosed {
+			// Paid for or canceled just now; show it as it is.
+			s, err = cu.checkoutRepo.GetByID(ctx, id)
+		}
+		if err != nil {
+			return domain.CheckoutPage{}, err
+		}
+	}
+	if s.Status == domain.CheckoutProcessing && s.TransactionID != 0 {
+		err = cu.followUp(ctx, &s, now)
+		if err != nil {
+			log.Printf("checkout session %s: %v", s.ID, err)
+		}
+	}
+
+	m, err := cu.merchantRepo.GetByID(ctx, s.MerchantID)
+	if err != nil {
+		return domain.CheckoutPage{}, err
+	}
+	methods, err := cu.methods(ctx, s.MerchantID)
+	if err != nil {
+		return domain.CheckoutPage{}, err
+	}
+
+	page := domain.CheckoutPage{Session: s, Merchant: m, Methods: methods}
+	if len(methods) > 0 {
+		page.Setting = methods[0]
+	}
+	page.Session.URL = cu.url(s.ID)
+	return page, nil
+}
+
+// Complete pays a session. The session is claimed first so that a double
+// submit cannot charge twice. A captured payment completes it and one that
+// failed reopens it so the customer can try again. A payment that is still
+// going, such as one held for review or an authorization whose capture did
+// not go through, keeps the session claimed until GetPage or RunSchedule
+// sees how it ended.
+func (cu *checkoutUsecase) Complete(ctx context.Context, id string, p *domain.CheckoutPayment) (domain.CheckoutSession, domain.Transaction, error) {
+	s, err := cu.checkoutRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.CheckoutSession{}, domain.Transaction{}, err
+	}
+
+	now := time.Now().UTC()
+	claimed, err := cu.checkoutRepo.Claim(ctx, id, now)
+	if err != nil {
+		return domain.CheckoutSession{}, domain.Transaction{}, err
+	}
+	if !claimed {
+		if s.Status == domain.CheckoutOpen && !now.Before(s.ExpiresAt) {
+			return s, domain.Transaction{}, domain.ErrSessionExpired
+		}
+		return s, domain.Transaction{}, domain.ErrSessionClosed
+	}
+	s.Status = domain.CheckoutProcessing
+
+	t, err := cu.charge(ctx, s, p)
+	if t.ID == 0 {
+		cu.release(ctx, &s)
+		return s, domain.Transaction{}, err
+	}
+
+	s.TransactionID = t.ID
+	settle(&s, t, now)
+	uerr := cu.checkoutRepo.Update(ctx, &s, domain.CheckoutProcessing)
+	if uerr == domain.ErrSessionClosed {
+		// A follow-up settled the session from the same payment first.
+		s, uerr = cu.checkoutRepo.GetByID(ctx, id)
+	}
+	if err != nil {
+		return s, t, err
+	}
+	if uerr != nil {
+		return domain.CheckoutSession{}, domain.Transaction{}, uerr
+	}
+
+	return s, t, nil
+}
+
+// RunSchedule settles the sessions whose payment has ended since they were
+// submitted.
+func (cu *checkoutUsecase) RunSchedule(ctx context.Context, now time.Time) error {
+	sessions, err := cu.checkoutRepo.FetchProcessing(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for n := range sessions {
+		err = cu.followUp(ctx, &sessions[n], now)
+		if err != nil {
+			log.Printf("checkout session %s: %v", sessions[n].ID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// followUp settles a claimed session once its payment has moved on, for
+// example out of review. An authorized payment is captured, as Complete
+// would have.
+func (cu *checkoutUsecase) followUp(ctx context.Context, s *domain.CheckoutSession, now time.Time) error {
+	t, err := cu.transactions.GetByID(ctx, s.TransactionID)
+	if err != nil {
+		return err
+	}
+	if t.State == domain.TransactionAuthorized {
+		t, err = cu.transactions.Capture(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if !settle(s, t, now) {
+		return nil
+	}
+
+	err = cu.checkoutRepo.Update(ctx, s, domain.CheckoutProcessing)
+	if err == domain.ErrSessionClosed {
+		// Settled from the same payment by someone else first.
+		*s, err = cu.checkoutRepo.GetByID(ctx, s.ID)
+	}
+	return err
+}
+
+// Cancel closes an open session. A session that has already been canceled
+// or has expired is returned as it is.
+func (cu *checkoutUsecase) Cancel(ctx context.Context, id string) (domain.CheckoutSession, error) {
+	s, err := cu.checkoutRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.CheckoutSession{}, err
+	}
+	if s.Status == domain.CheckoutCanceled || s.Status == domain.CheckoutExpired {
+		return s, nil
+	}
+	if s.Status != domain.CheckoutOpen {
+		return s, domain.ErrSessionClosed
+	}
+
+	s.Status = domain.CheckoutCanceled
+	err = cu.checkoutRepo.Update(ctx, &s, domain.CheckoutOpen)
+	if err == domain.ErrSessionClosed {
+		// The session was submitted or expired meanwhile; answer for
+		// where it is now.
+		return cu.Cancel(ctx, id)
+	}
+	if err != nil {
+		return domain.CheckoutSession{}, err
+	}
+
+	return s, nil
+}
+
+func (cu *checkoutUsecase) charge(ctx context.Context, s domain.CheckoutSession, p *domain.CheckoutPayment) (domain.Transaction, error) {
+	setting, err := cu.merchantRepo.GetSetting(ctx, p.SettingID)
+	if err == domain.ErrNotFound {
+		return domain.Transaction{}, domain.ErrPaymentMethod
+	}
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if setting.MerchantID != s.MerchantID || !supported[setting.PaymentType] {
+		return domain.Transaction{}, domain.ErrPaymentMethod
+	}
+
+	card := p.Card
+	card.MerchantID = s.MerchantID
+	err = cu.cardVault.Tokenize(ctx, &card)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	t := domain.Transaction{
+		MerchantID:       s.MerchantID,
+		ParentMerchantID: s.MerchantID,
+		SettingID:        setting.ID,
+		Amount:           s.Amount,
+		Currency:         s.Currency,
+		PaymentType:      setting.PaymentType,
+		CardToken:        card.Token,
+	}
+	err = cu.transactions.Store(ctx, &t)
+	if err != nil || t.State != domain.TransactionAuthorized {
+		return t, err
+	}
+
+	captured, err := cu.transactions.Capture(ctx, t.ID)
+	if err != nil {
+		return t, err
+	}
+
+	return captured, nil
+}
+
+// settle sets a claimed session's status from its payment and tells whether
+// the payment has ended. A captured payment completes the session; one that
+// failed reopens it, or expires it if it is past its expiry. Anything else
+// leaves it processing.
+func settle(s *domain.CheckoutSession, t domain.Transaction, now time.Time) bool {
+	switch t.State {
+	case domain.TransactionCaptured:
+		s.Status = domain.CheckoutComplete
+	case domain.TransactionDeclined, domain.TransactionFailed, domain.TransactionVoided:
+		s.Status = domain.CheckoutOpen
+		if !now.Before(s.ExpiresAt) {
+			s.Status = domain.CheckoutExpired
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// release hands a claimed session back so the customer can retry.
+func (cu *checkoutUsecase) release(ctx context.Context, s *domain.CheckoutSession) {
+	s.Status = domain.CheckoutOpen
+	err := cu.checkoutRepo.Update(ctx, s, domain.CheckoutProcessing)
+	if err != nil {
+		log.Printf("checkout session %s: %v", s.ID, err)
+	}
+}
+
+func (cu *checkoutUsecase) methods(ctx context.Context, merchantID int64) ([]domain.Setting, error) {
+	settings, err := cu.merchantRepo.FetchSettings(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []domain.Setting{}
+	for _, s := range settings {
+		if supported[s.PaymentType] {
+			methods = append(methods, s)
+		}
+	}
+
+	return methods, nil
+}
+
+func (cu *checkoutUsecase) url(id string) string {
+	return cu.cfg.BaseURL + "/checkout/" + id
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "cs_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,388 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	checkoutRepo "github.com/hezbymuhammad/payment-gateway/checkout/repository/sqlite"
+	checkoutUsecase "github.com/hezbymuhammad/payment-gateway/checkout/usecase"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+var (
+	cfg     = checkoutUsecase.Config{BaseURL: "https://pay.example.com", TTL: 30 * time.Minute}
+	card    = domain.Setting{ID: 1, MerchantID: 6, Color: "RED", PaymentType: "CARD", PaymentName: "VISA"}
+	qris    = domain.Setting{ID: 6, MerchantID: 6, Color: "RED", PaymentType: "QRIS", PaymentName: "QRIS"}
+	payment = &domain.CheckoutPayment{SettingID: 1, Card: domain.Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2030}}
+)
+
+func openSession() domain.CheckoutSession {
+	return domain.CheckoutSession{ID: "cs_1", MerchantID: 6, Amount: 50000, Currency: "IDR", Status: domain.CheckoutOpen, ExpiresAt: time.Now().Add(time.Minute)}
+}
+
+func TestCreate(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockMerchantRepo.On("FetchSettings", mock.Anything, int64(6)).Return([]domain.Setting{card, qris}, nil).Once()
+	mockCheckoutRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	data := &domain.CheckoutSession{MerchantID: 6, Amount: 50000}
+	err := u.Create(context.TODO(), data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutOpen, data.Status)
+	assert.Equal(t, "IDR", data.Currency)
+	assert.True(t, strings.HasPrefix(data.URL, "https://pay.example.com/checkout/cs_"))
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), data.ExpiresAt, 2*time.Second)
+}
+
+func TestCreateWithoutPaymentMethods(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockMerchantRepo.On("FetchSettings", mock.Anything, int64(6)).Return([]domain.Setting{qris}, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	err := u.Create(context.TODO(), &domain.CheckoutSession{MerchantID: 6, Amount: 50000})
+
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+}
+
+func TestGetPageExpires(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := openSession()
+	s.ExpiresAt = time.Now().Add(-time.Minute)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(s, nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutExpired
+	}), domain.CheckoutOpen).Return(nil).Once()
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6, Name: "CAFE"}, nil).Once()
+	mockMerchantRepo.On("FetchSettings", mock.Anything, int64(6)).Return([]domain.Setting{qris, card}, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	res, err := u.GetPage(context.TODO(), "cs_1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutExpired, res.Session.Status)
+	assert.Equal(t, []domain.Setting{card}, res.Methods)
+	assert.Equal(t, card, res.Setting)
+}
+
+func TestComplete(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.MatchedBy(func(c *domain.Card) bool {
+		return c.MerchantID == 6
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Card).Token = "tok_1"
+	}).Return(nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+		return t.CardToken == "tok_1" && t.Amount == 50000 && t.SettingID == 1
+	})).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutComplete && s.TransactionID == 4
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	s, tx, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutComplete, s.Status)
+	assert.Equal(t, domain.TransactionCaptured, tx.State)
+	mockCheckoutRepo.AssertExpectations(t)
+}
+
+func TestCompleteDeclinedReopens(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.Anything).Return(nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionDeclined
+	}).Return(nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutOpen && s.TransactionID == 4
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	s, tx, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutOpen, s.Status)
+	assert.Equal(t, domain.TransactionDeclined, tx.State)
+	mockTransactions.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+}
+
+func TestCompleteHeldForReview(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.Anything).Return(nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionReview
+	}).Return(nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutProcessing && s.TransactionID == 4
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	s, tx, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutProcessing, s.Status)
+	assert.Equal(t, domain.TransactionReview, tx.State)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockTransactions.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+}
+
+var errProcessor = errors.New("no processor answered")
+
+func TestCompletePending(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.Anything).Return(nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionPending
+	}).Return(errProcessor).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutProcessing && s.TransactionID == 4
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	s, _, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.Equal(t, errProcessor, err)
+	assert.Equal(t, domain.CheckoutProcessing, s.Status)
+	mockCheckoutRepo.AssertExpectations(t)
+}
+
+func TestCompleteCaptureFails(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.Anything).Return(nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{}, domain.ErrProcessorTimeout).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutProcessing && s.TransactionID == 4
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	s, _, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.Equal(t, domain.ErrProcessorTimeout, err)
+	assert.Equal(t, domain.CheckoutProcessing, s.Status)
+	mockCheckoutRepo.AssertExpectations(t)
+}
+
+func TestRunSchedule(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	now := time.Now()
+	approved, rejected, late, held := openSession(), openSession(), openSession(), openSession()
+	approved.Status, approved.TransactionID = domain.CheckoutProcessing, 4
+	rejected.ID, rejected.Status, rejected.TransactionID = "cs_2", domain.CheckoutProcessing, 5
+	late.ID, late.Status, late.TransactionID, late.ExpiresAt = "cs_3", domain.CheckoutProcessing, 6, now.Add(-time.Minute)
+	held.ID, held.Status, held.TransactionID = "cs_4", domain.CheckoutProcessing, 7
+	mockCheckoutRepo.On("FetchProcessing", mock.Anything).Return([]domain.CheckoutSession{approved, rejected, late, held}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionAuthorized}, nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(5)).Return(domain.Transaction{ID: 5, State: domain.TransactionDeclined}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(6)).Return(domain.Transaction{ID: 6, State: domain.TransactionDeclined}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(7)).Return(domain.Transaction{ID: 7, State: domain.TransactionReview}, nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.ID == "cs_1" && s.Status == domain.CheckoutComplete
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.ID == "cs_2" && s.Status == domain.CheckoutOpen
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.ID == "cs_3" && s.Status == domain.CheckoutExpired
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	err := u.RunSchedule(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockCheckoutRepo.AssertExpectations(t)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestGetPageSettlesApprovedPayment(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := openSession()
+	s.Status, s.TransactionID = domain.CheckoutProcessing, 4
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(s, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutComplete
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6, Name: "CAFE"}, nil).Once()
+	mockMerchantRepo.On("FetchSettings", mock.Anything, int64(6)).Return([]domain.Setting{card}, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	res, err := u.GetPage(context.TODO(), "cs_1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutComplete, res.Session.Status)
+}
+
+func TestCompleteInvalidCardReleases(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockCardVault.On("Tokenize", mock.Anything, mock.Anything).Return(domain.ErrInvalidCard).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.CheckoutSession) bool {
+		return s.Status == domain.CheckoutOpen
+	}), domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	_, _, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.Equal(t, domain.ErrInvalidCard, err)
+	mockCheckoutRepo.AssertExpectations(t)
+}
+
+func TestCompleteTwice(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := openSession()
+	s.Status = domain.CheckoutComplete
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(s, nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(false, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	_, _, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.Equal(t, domain.ErrSessionClosed, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestCompleteExpired(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := openSession()
+	s.ExpiresAt = time.Now().Add(-time.Second)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(s, nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(false, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	_, _, err := u.Complete(context.TODO(), "cs_1", payment)
+
+	assert.Equal(t, domain.ErrSessionExpired, err)
+}
+
+func TestCompleteWithQRISSetting(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(openSession(), nil).Once()
+	mockCheckoutRepo.On("Claim", mock.Anything, "cs_1", mock.Anything).Return(true, nil).Once()
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(6)).Return(qris, nil).Once()
+	mockCheckoutRepo.On("Update", mock.Anything, mock.Anything, domain.CheckoutProcessing).Return(nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	_, _, err := u.Complete(context.TODO(), "cs_1", &domain.CheckoutPayment{SettingID: 6})
+
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+	mockCardVault.AssertNotCalled(t, "Tokenize", mock.Anything, mock.Anything)
+}
+
+func TestCancelCompletedSession(t *testing.T) {
+	mockCheckoutRepo := new(mocks.CheckoutRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := openSession()
+	s.Status = domain.CheckoutComplete
+	mockCheckoutRepo.On("GetByID", mock.Anything, "cs_1").Return(s, nil).Once()
+	u := checkoutUsecase.NewCheckoutUsecase(mockCheckoutRepo, mockMerchantRepo, mockCardVault, mockTransactions, cfg)
+
+	_, err := u.Cancel(context.TODO(), "cs_1")
+
+	assert.Equal(t, domain.ErrSessionClosed, err)
+}
+
+func TestCancelWhilePaying(t *testing.T) {
+	cr := checkoutRepo.NewCheckoutRepository(migrationtest.NewDB(t))
+	s := openSession()
+	s.ExpiresAt = s.ExpiresAt.UTC().Truncate(time.Second)
+	assert.NoError(t, cr.Store(context.TODO(), &s))
+	claimed, err := cr.Claim(context.TODO(), s.ID, time.Now().UTC())
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	u := checkoutUsecase.NewCheckoutUsecase(cr, new(mocks.MerchantRepository), new(mocks.CardVaultUsecase), new(mocks.TransactionUsecase), cfg)
+
+	_, err = u.Cancel(context.TODO(), s.ID)
+
+	assert.Equal(t, domain.ErrSessionClosed, err)
+	res, err := cr.GetByID(context.TODO(), s.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CheckoutProcessing, res.Status)
+}
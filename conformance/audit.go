@@ -0,0 +1,105 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/audit"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// AuditRepository runs the checks every domain.AuditRepository must pass.
+func AuditRepository(t *testing.T, newRepo func(t *testing.T) domain.AuditRepository) {
+	ctx := context.Background()
+
+	t.Run("AppendAndLast", func(t *testing.T) {
+		ar := newRepo(t)
+		_, err := ar.Last(ctx)
+		assert.Equal(t, domain.ErrNotFound, err)
+
+		first := auditEntry("", domain.AuditTransaction, 4, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+		assert.NoError(t, ar.Append(ctx, &first))
+		assert.NotZero(t, first.ID)
+		second := auditEntry(first.Hash, domain.AuditMerchant, 1, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+		assert.NoError(t, ar.Append(ctx, &second))
+
+		res, err := ar.Last(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, second, res)
+		assert.Equal(t, second.Hash, audit.Hash(res))
+	})
+
+	t.Run("AppendOutOfChain", func(t *testing.T) {
+		ar := newRepo(t)
+		first := auditEntry("", domain.AuditTransaction, 4, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC))
+		assert.NoError(t, ar.Append(ctx, &first))
+
+		again := auditEntry("", domain.AuditTransaction, 5, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+		assert.Equal(t, domain.ErrAuditChain, ar.Append(ctx, &again))
+		stray := auditEntry("lorem", domain.AuditTransaction, 5, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC))
+		assert.Equal(t, domain.ErrAuditChain, ar.Append(ctx, &stray))
+
+		res, err := ar.Fetch(ctx, domain.AuditFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{first}, res)
+	})
+
+	t.Run("Fetch", func(t *testing.T) {
+		ar := newRepo(t)
+		res, err := ar.Fetch(ctx, domain.AuditFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{}, res)
+
+		morning := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+		entries := []domain.AuditEntry{
+			auditEntry("", domain.AuditTransaction, 4, morning),
+			auditEntry("", domain.AuditMerchant, 4, morning.Add(time.Hour)),
+			auditEntry("", domain.AuditTransaction, 5, morning.Add(2*time.Hour)),
+			auditEntry("", domain.AuditTransaction, 4, morning.Add(3*time.Hour)),
+		}
+		for i := range entries {
+			if i > 0 {
+				entries[i].PrevHash = entries[i-1].Hash
+				entries[i].Hash = audit.Hash(entries[i])
+			}
+			assert.NoError(t, ar.Append(ctx, &entries[i]))
+		}
+
+		res, err = ar.Fetch(ctx, domain.AuditFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, entries, res)
+
+		res, err = ar.Fetch(ctx, domain.AuditFilter{Entity: domain.AuditTransaction, EntityID: 4})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{entries[0], entries[3]}, res)
+
+		res, err = ar.Fetch(ctx, domain.AuditFilter{Entity: domain.AuditTransaction, From: morning.Add(time.Hour), To: morning.Add(3 * time.Hour)})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.AuditEntry{entries[2]}, res)
+	})
+}
+
+// auditEntry gives an entry following prevHash, hashed.
+func auditEntry(prevHash string, entity string, id int64, at time.Time) domain.AuditEntry {
+	e := domain.AuditEntry{
+		Actor:        audit.Anonymous,
+		ClaimedActor: "ops@lorem.id",
+		MerchantID:   1,
+		Action:       entity + ".update",
+		Entity:       entity,
+		EntityID:     id,
+		Before:       json.RawMessage(`{"state":"authorized"}`),
+		After:        json.RawMessage(`{"state":"captured"}`),
+		RequestID:    "req-1",
+		SourceIP:     "203.0.113.7",
+		CreatedAt:    at,
+		PrevHash:     prevHash,
+	}
+	e.Hash = audit.Hash(e)
+
+	return e
+}
@@ -0,0 +1,87 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// CardVaultRepository runs the checks every domain.CardVaultRepository must
+// pass. Setting a fingerprint also fills in the transactions charged to the
+// card, so newRepos also returns a transaction repository on the same store.
+func CardVaultRepository(t *testing.T, newRepos func(t *testing.T) (domain.CardVaultRepository, domain.TransactionRepository)) {
+	ctx := context.Background()
+
+	t.Run("StoreAndGetByToken", func(t *testing.T) {
+		cr, _ := newRepos(t)
+		card := vaultedCard("tok_1")
+
+		assert.NoError(t, cr.Store(ctx, &card))
+
+		res, err := cr.GetByToken(ctx, "tok_1")
+		assert.NoError(t, err)
+		assert.Equal(t, card, res)
+
+		_, err = cr.GetByToken(ctx, "tok_404")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cr, _ := newRepos(t)
+		card := vaultedCard("tok_1")
+		assert.NoError(t, cr.Store(ctx, &card))
+
+		assert.NoError(t, cr.Delete(ctx, "tok_1"))
+		_, err := cr.GetByToken(ctx, "tok_1")
+		assert.Equal(t, domain.ErrNotFound, err)
+		assert.Equal(t, domain.ErrNotFound, cr.Delete(ctx, "tok_1"))
+	})
+
+	t.Run("SetFingerprint", func(t *testing.T) {
+		cr, tr := newRepos(t)
+		old, fingerprinted := vaultedCard("tok_1"), vaultedCard("tok_2")
+		old.Fingerprint = ""
+		fingerprinted.Fingerprint = "fp_2"
+		assert.NoError(t, cr.Store(ctx, &old))
+		assert.NoError(t, cr.Store(ctx, &fingerprinted))
+		data := payment(1)
+		data.CardFingerprint = ""
+		assert.NoError(t, tr.Store(ctx, &data))
+
+		res, err := cr.FetchWithoutFingerprint(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.VaultedCard{old}, res)
+
+		assert.NoError(t, cr.SetFingerprint(ctx, "tok_1", "fp_1"))
+		card, err := cr.GetByToken(ctx, "tok_1")
+		assert.NoError(t, err)
+		assert.Equal(t, "fp_1", card.Fingerprint)
+		charged, err := tr.GetByID(ctx, data.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "fp_1", charged.CardFingerprint)
+
+		res, err = cr.FetchWithoutFingerprint(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
+
+func vaultedCard(token string) domain.VaultedCard {
+	return domain.VaultedCard{
+		Card: domain.Card{
+			Token:       token,
+			MerchantID:  1,
+			ExpiryMonth: 12,
+			ExpiryYear:  2030,
+			BIN:         "411111",
+			Last4:       "1111",
+			Brand:       "VISA",
+			Fingerprint: "fp_1",
+		},
+		EncryptedKey: []byte{1, 2, 3},
+		Ciphertext:   []byte{4, 5, 6},
+	}
+}
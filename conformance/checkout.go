@@ -0,0 +1,81 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// CheckoutRepository runs the checks every domain.CheckoutRepository must
+// pass.
+func CheckoutRepository(t *testing.T, newRepo func(t *testing.T) domain.CheckoutRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndGet", func(t *testing.T) {
+		cr := newRepo(t)
+		s := session("cs_1", now)
+		assert.NoError(t, cr.Store(ctx, &s))
+
+		res, err := cr.GetByID(ctx, s.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, s, res)
+
+		_, err = cr.GetByID(ctx, "cs_404")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("ClaimOnce", func(t *testing.T) {
+		cr := newRepo(t)
+		s, expired := session("cs_1", now), session("cs_2", now)
+		expired.ExpiresAt = now.Add(-time.Minute)
+		assert.NoError(t, cr.Store(ctx, &s))
+		assert.NoError(t, cr.Store(ctx, &expired))
+
+		claimed, err := cr.Claim(ctx, s.ID, now)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = cr.Claim(ctx, s.ID, now)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		claimed, err = cr.Claim(ctx, expired.ID, now)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("UpdateFromStatus", func(t *testing.T) {
+		cr := newRepo(t)
+		s := session("cs_1", now)
+		assert.NoError(t, cr.Store(ctx, &s))
+
+		s.Status = domain.CheckoutProcessing
+		s.TransactionID = 7
+		assert.NoError(t, cr.Update(ctx, &s, domain.CheckoutOpen))
+
+		res, err := cr.FetchProcessing(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.CheckoutSession{s}, res)
+
+		s.Status = domain.CheckoutComplete
+		assert.Equal(t, domain.ErrSessionClosed, cr.Update(ctx, &s, domain.CheckoutOpen))
+	})
+}
+
+func session(id string, now time.Time) domain.CheckoutSession {
+	return domain.CheckoutSession{
+		ID:         id,
+		MerchantID: 1,
+		Amount:     150000,
+		Currency:   "IDR",
+		SuccessURL: "https://shop.example.com/success",
+		CancelURL:  "https://shop.example.com/cancel",
+		Status:     domain.CheckoutOpen,
+		ExpiresAt:  now.Add(30 * time.Minute),
+	}
+}
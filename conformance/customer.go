@@ -0,0 +1,92 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// CustomerRepository runs the checks every domain.CustomerRepository must
+// pass.
+func CustomerRepository(t *testing.T, newRepo func(t *testing.T) domain.CustomerRepository) {
+	ctx := context.Background()
+
+	t.Run("StoreAndFetch", func(t *testing.T) {
+		cr := newRepo(t)
+		first, second, elsewhere := customer(1), customer(1), customer(2)
+		second.Name = "Siti"
+		for _, c := range []*domain.Customer{&first, &second, &elsewhere} {
+			assert.NoError(t, cr.Store(ctx, c))
+			assert.NotZero(t, c.ID)
+		}
+
+		res, err := cr.FetchByMerchant(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Customer{first, second}, res)
+
+		c, err := cr.GetByID(ctx, elsewhere.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, elsewhere, c)
+
+		_, err = cr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		cr := newRepo(t)
+		c := customer(1)
+		assert.NoError(t, cr.Store(ctx, &c))
+
+		c.Email = "budi@example.org"
+		c.Phone = ""
+		assert.NoError(t, cr.Update(ctx, &c))
+
+		res, err := cr.GetByID(ctx, c.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, c, res)
+	})
+
+	t.Run("PaymentMethods", func(t *testing.T) {
+		cr := newRepo(t)
+		c := customer(1)
+		assert.NoError(t, cr.Store(ctx, &c))
+		first := domain.PaymentMethod{CustomerID: c.ID, MerchantID: 1, Type: "CARD", CardToken: "tok_1", Last4: "1111", Brand: "VISA"}
+		second := first
+		second.CardToken = "tok_2"
+		assert.NoError(t, cr.StorePaymentMethod(ctx, &first))
+		assert.NoError(t, cr.StorePaymentMethod(ctx, &second))
+
+		res, err := cr.FetchPaymentMethods(ctx, c.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.PaymentMethod{first, second}, res)
+
+		pm, err := cr.GetPaymentMethod(ctx, second.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, second, pm)
+
+		assert.NoError(t, cr.DeletePaymentMethod(ctx, first.ID))
+		_, err = cr.GetPaymentMethod(ctx, first.ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cr := newRepo(t)
+		c := customer(1)
+		assert.NoError(t, cr.Store(ctx, &c))
+		pm := domain.PaymentMethod{CustomerID: c.ID, MerchantID: 1, Type: "CARD", CardToken: "tok_1", Last4: "1111", Brand: "VISA"}
+		assert.NoError(t, cr.StorePaymentMethod(ctx, &pm))
+
+		assert.NoError(t, cr.Delete(ctx, c.ID))
+		_, err := cr.GetByID(ctx, c.ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+		_, err = cr.GetPaymentMethod(ctx, pm.ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+}
+
+func customer(merchantID int64) domain.Customer {
+	return domain.Customer{MerchantID: merchantID, Name: "Budi", Email: "budi@example.com", Phone: "+6281234567890"}
+}
@@ -0,0 +1,117 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// DisputeRepository runs the checks every domain.DisputeRepository must
+// pass.
+func DisputeRepository(t *testing.T, newRepo func(t *testing.T) domain.DisputeRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndGet", func(t *testing.T) {
+		dr := newRepo(t)
+		d := dispute(4, now)
+		assert.NoError(t, dr.Store(ctx, &d))
+		assert.NotZero(t, d.ID)
+
+		res, err := dr.GetByID(ctx, d.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, d, res)
+
+		_, err = dr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("OneOpenDisputePerTransaction", func(t *testing.T) {
+		dr := newRepo(t)
+		d := dispute(4, now)
+		assert.NoError(t, dr.Store(ctx, &d))
+
+		again := dispute(4, now)
+		assert.Equal(t, domain.ErrDisputed, dr.Store(ctx, &again))
+
+		ok, err := dr.Transition(ctx, d.ID, domain.DisputeNeedsResponse, domain.DisputeWon)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.NoError(t, dr.Store(ctx, &again))
+		assert.NotEqual(t, d.ID, again.ID)
+	})
+
+	t.Run("FetchOverdue", func(t *testing.T) {
+		dr := newRepo(t)
+		overdue, pending, contested := dispute(1, now), dispute(2, now), dispute(3, now)
+		overdue.RespondBy = now.Add(-time.Hour)
+		pending.RespondBy = now.Add(time.Hour)
+		contested.RespondBy = now.Add(-time.Hour)
+		contested.Status = domain.DisputeUnderReview
+		for _, d := range []*domain.Dispute{&overdue, &pending, &contested} {
+			assert.NoError(t, dr.Store(ctx, d))
+		}
+
+		res, err := dr.FetchOverdue(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Dispute{overdue}, res)
+	})
+
+	t.Run("LoseOnce", func(t *testing.T) {
+		dr := newRepo(t)
+		d := dispute(4, now)
+		assert.NoError(t, dr.Store(ctx, &d))
+		e := domain.LedgerEntry{MerchantID: 6, TransactionID: 4, Type: domain.LedgerChargeback, Reference: "dispute-1", Amount: -150000, Currency: "IDR", CreatedAt: now}
+
+		ok, err := dr.Lose(ctx, d.ID, domain.DisputeNeedsResponse, &e)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.NotZero(t, e.ID)
+
+		again := e
+		again.ID = 0
+		ok, err = dr.Lose(ctx, d.ID, domain.DisputeNeedsResponse, &again)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Zero(t, again.ID)
+
+		res, err := dr.GetByID(ctx, d.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DisputeLost, res.Status)
+	})
+
+	t.Run("Evidence", func(t *testing.T) {
+		dr := newRepo(t)
+		first := domain.DisputeEvidence{DisputeID: 1, FileName: "receipt.pdf", ContentType: "application/pdf", Size: 2048, StorageKey: "disputes/1/receipt.pdf", CreatedAt: now}
+		second := first
+		second.FileName = "delivery.png"
+		second.StorageKey = "disputes/1/delivery.png"
+		elsewhere := first
+		elsewhere.DisputeID = 2
+		for _, e := range []*domain.DisputeEvidence{&first, &second, &elsewhere} {
+			assert.NoError(t, dr.StoreEvidence(ctx, e))
+			assert.NotZero(t, e.ID)
+		}
+
+		res, err := dr.FetchEvidence(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DisputeEvidence{first, second}, res)
+	})
+}
+
+func dispute(transactionID int64, now time.Time) domain.Dispute {
+	return domain.Dispute{
+		TransactionID: transactionID,
+		MerchantID:    6,
+		ReasonCode:    "fraudulent",
+		Amount:        150000,
+		Currency:      "IDR",
+		Status:        domain.DisputeNeedsResponse,
+		RespondBy:     now.Add(7 * 24 * time.Hour),
+		CreatedAt:     now,
+	}
+}
@@ -0,0 +1,61 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// EWalletRepository runs the checks every domain.EWalletRepository must
+// pass.
+func EWalletRepository(t *testing.T, newRepo func(t *testing.T) domain.EWalletRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndGet", func(t *testing.T) {
+		er := newRepo(t)
+		p := walletPayment(7, now)
+		assert.NoError(t, er.Store(ctx, &p))
+		assert.NotZero(t, p.ID)
+
+		res, err := er.GetByTransaction(ctx, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, p, res)
+
+		_, err = er.GetByTransaction(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("FetchPending", func(t *testing.T) {
+		er := newRepo(t)
+		older := walletPayment(1, now.Add(-time.Hour))
+		old := walletPayment(2, now.Add(-time.Minute))
+		paid := walletPayment(3, now.Add(-time.Hour))
+		recent := walletPayment(4, now.Add(time.Minute))
+		for _, p := range []*domain.EWalletPayment{&old, &older, &paid, &recent} {
+			assert.NoError(t, er.Store(ctx, p))
+		}
+		paid.Status = domain.EWalletPaid
+		assert.NoError(t, er.Update(ctx, &paid))
+
+		res, err := er.FetchPending(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.EWalletPayment{older, old}, res)
+	})
+}
+
+func walletPayment(transactionID int64, createdAt time.Time) domain.EWalletPayment {
+	return domain.EWalletPayment{
+		TransactionID: transactionID,
+		Provider:      "DANA",
+		Reference:     "dana-ref",
+		CheckoutURL:   "https://m.dana.id/checkout",
+		Deeplink:      "dana://checkout",
+		Status:        domain.EWalletPending,
+		CreatedAt:     createdAt,
+	}
+}
@@ -0,0 +1,54 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// FXRepository runs the checks every domain.FXRepository must pass.
+func FXRepository(t *testing.T, newRepo func(t *testing.T) domain.FXRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("Rates", func(t *testing.T) {
+		fr := newRepo(t)
+		usd := domain.FXRate{Currency: "USD", Rate: "15500.25", UpdatedAt: now.Add(-time.Hour)}
+		sgd := domain.FXRate{Currency: "SGD", Rate: "11800", UpdatedAt: now.Add(-time.Hour)}
+		assert.NoError(t, fr.StoreRate(ctx, &usd))
+		assert.NoError(t, fr.StoreRate(ctx, &sgd))
+
+		usd.Rate = "15600.5"
+		usd.UpdatedAt = now
+		assert.NoError(t, fr.StoreRate(ctx, &usd))
+
+		r, err := fr.GetRate(ctx, "USD")
+		assert.NoError(t, err)
+		assert.Equal(t, usd, r)
+
+		res, err := fr.FetchRates(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.FXRate{sgd, usd}, res)
+
+		_, err = fr.GetRate(ctx, "EUR")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Quotes", func(t *testing.T) {
+		fr := newRepo(t)
+		q := domain.FXQuote{MerchantID: 1, Currency: "USD", Amount: 1000, SettlementCurrency: "IDR", Rate: "15500.25", Markup: 150, SettlementAmount: 152677, ExpiresAt: now.Add(15 * time.Minute)}
+		assert.NoError(t, fr.StoreQuote(ctx, &q))
+		assert.NotZero(t, q.ID)
+
+		res, err := fr.GetQuote(ctx, q.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, q, res)
+
+		_, err = fr.GetQuote(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+}
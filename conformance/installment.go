@@ -0,0 +1,48 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// InstallmentRepository runs the checks every domain.InstallmentRepository
+// must pass.
+func InstallmentRepository(t *testing.T, newRepo func(t *testing.T) domain.InstallmentRepository) {
+	ctx := context.Background()
+
+	t.Run("StoreAndFetch", func(t *testing.T) {
+		ir := newRepo(t)
+		twelve := domain.InstallmentPlan{MerchantID: 1, SettingID: 1, Tenor: 12, CustomerRate: 250, MerchantRate: 100, MinAmount: 1000000, BINs: []string{"411111", "522222"}}
+		three := domain.InstallmentPlan{MerchantID: 1, SettingID: 1, Tenor: 3, BINs: []string{}}
+		elsewhere := domain.InstallmentPlan{MerchantID: 1, SettingID: 2, Tenor: 6, BINs: []string{}}
+		for _, p := range []*domain.InstallmentPlan{&twelve, &three, &elsewhere} {
+			assert.NoError(t, ir.StorePlan(ctx, p))
+			assert.NotZero(t, p.ID)
+		}
+
+		res, err := ir.FetchPlans(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.InstallmentPlan{three, twelve}, res)
+
+		p, err := ir.GetPlan(ctx, twelve.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, twelve, p)
+
+		_, err = ir.GetPlan(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("NilBINs", func(t *testing.T) {
+		ir := newRepo(t)
+		p := domain.InstallmentPlan{MerchantID: 1, SettingID: 1, Tenor: 3}
+		assert.NoError(t, ir.StorePlan(ctx, &p))
+
+		res, err := ir.GetPlan(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, res.BINs)
+	})
+}
@@ -0,0 +1,145 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// InvoiceRepository runs the checks every domain.InvoiceRepository must
+// pass.
+func InvoiceRepository(t *testing.T, newRepo func(t *testing.T) domain.InvoiceRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndGet", func(t *testing.T) {
+		ir := newRepo(t)
+		i := invoice(now)
+		assert.NoError(t, ir.Store(ctx, &i))
+		assert.NotZero(t, i.ID)
+		for _, item := range i.LineItems {
+			assert.NotZero(t, item.ID)
+			assert.Equal(t, i.ID, item.InvoiceID)
+		}
+
+		res, err := ir.GetByID(ctx, i.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, i, res)
+
+		res, err = ir.GetByLinkToken(ctx, "inv_1")
+		assert.NoError(t, err)
+		assert.Equal(t, i, res)
+
+		list, err := ir.FetchByMerchant(ctx, 1)
+		assert.NoError(t, err)
+		i.LineItems = nil
+		assert.Equal(t, []domain.Invoice{i}, list)
+
+		_, err = ir.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+		_, err = ir.GetByLinkToken(ctx, "inv_404")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("DraftsHaveNoLink", func(t *testing.T) {
+		ir := newRepo(t)
+		first, second := invoice(now), invoice(now)
+		first.Status, first.LinkToken = domain.InvoiceDraft, ""
+		second.Status, second.LinkToken = domain.InvoiceDraft, ""
+		assert.NoError(t, ir.Store(ctx, &first))
+		assert.NoError(t, ir.Store(ctx, &second))
+
+		res, err := ir.GetByID(ctx, second.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "", res.LinkToken)
+	})
+
+	t.Run("ClaimAndUpdate", func(t *testing.T) {
+		ir := newRepo(t)
+		i := invoice(now)
+		assert.NoError(t, ir.Store(ctx, &i))
+
+		claimed, err := ir.Claim(ctx, i.ID)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = ir.Claim(ctx, i.ID)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		i.Status = domain.InvoiceProcessing
+		i.TransactionID = 4
+		assert.NoError(t, ir.Update(ctx, &i, domain.InvoiceProcessing))
+		res, err := ir.FetchProcessing(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, int64(4), res[0].TransactionID)
+
+		i.Status = domain.InvoicePaid
+		assert.NoError(t, ir.Update(ctx, &i, domain.InvoiceProcessing))
+		assert.Equal(t, domain.ErrInvoiceState, ir.Update(ctx, &i, domain.InvoiceProcessing))
+	})
+
+	t.Run("MarkOverdue", func(t *testing.T) {
+		ir := newRepo(t)
+		due, later := invoice(now), invoice(now)
+		due.DueDate = now.Add(-time.Hour)
+		later.LinkToken = "inv_2"
+		assert.NoError(t, ir.Store(ctx, &due))
+		assert.NoError(t, ir.Store(ctx, &later))
+
+		n, err := ir.MarkOverdue(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		res, err := ir.GetByID(ctx, due.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.InvoiceOverdue, res.Status)
+	})
+
+	t.Run("Reminders", func(t *testing.T) {
+		ir := newRepo(t)
+		reminders := []domain.InvoiceReminder{
+			{InvoiceID: 1, RemindAt: now.Add(-time.Hour)},
+			{InvoiceID: 1, RemindAt: now.Add(time.Hour)},
+			{InvoiceID: 2, RemindAt: now},
+		}
+		assert.NoError(t, ir.StoreReminders(ctx, reminders))
+		for _, r := range reminders {
+			assert.NotZero(t, r.ID)
+		}
+
+		res, err := ir.FetchDueReminders(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.InvoiceReminder{reminders[0], reminders[2]}, res)
+
+		reminders[0].SentAt = now
+		assert.NoError(t, ir.MarkReminderSent(ctx, &reminders[0]))
+		res, err = ir.FetchDueReminders(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.InvoiceReminder{reminders[2]}, res)
+	})
+}
+
+func invoice(now time.Time) domain.Invoice {
+	return domain.Invoice{
+		MerchantID: 1,
+		SettingID:  1,
+		CustomerID: 2,
+		Status:     domain.InvoiceOpen,
+		Currency:   "IDR",
+		LineItems: []domain.InvoiceLineItem{
+			{Description: "Coffee", Quantity: 2, UnitPrice: 25000, Amount: 50000},
+			{Description: "Cake", Quantity: 1, UnitPrice: 40000, Amount: 40000},
+		},
+		TaxRate:   1100,
+		Subtotal:  90000,
+		Tax:       9900,
+		Total:     99900,
+		DueDate:   now.Add(7 * 24 * time.Hour),
+		LinkToken: "inv_1",
+	}
+}
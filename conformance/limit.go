@@ -0,0 +1,78 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// LimitRepository runs the checks every domain.LimitRepository must pass.
+// Effective limits follow merchant_groups, so newRepos also returns a
+// merchant repository on the same store.
+func LimitRepository(t *testing.T, newRepos func(t *testing.T) (domain.LimitRepository, domain.MerchantRepository)) {
+	ctx := context.Background()
+	updatedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("UpsertAndFetch", func(t *testing.T) {
+		lr, _ := newRepos(t)
+		card := domain.Limit{MerchantID: 1, PaymentType: "CARD", MaxAmount: 5000000, UpdatedAt: updatedAt}
+		all := domain.Limit{MerchantID: 1, DailyVolume: 10000000, UpdatedAt: updatedAt}
+		assert.NoError(t, lr.Upsert(ctx, &card))
+		assert.NoError(t, lr.Upsert(ctx, &all))
+
+		card.MaxAmount = 2500000
+		assert.NoError(t, lr.Upsert(ctx, &card))
+
+		res, err := lr.Fetch(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Limit{all, card}, res)
+	})
+
+	t.Run("FetchEffective", func(t *testing.T) {
+		lr, mr := newRepos(t)
+		assert.NoError(t, mr.SetChild(ctx, &domain.MerchantGroup{ParentMerchantID: 1, ChildMerchantID: 2}))
+		assert.NoError(t, mr.SetChild(ctx, &domain.MerchantGroup{ParentMerchantID: 2, ChildMerchantID: 3}))
+		limits := []domain.Limit{
+			{MerchantID: 1, MonthlyVolume: 90000000, UpdatedAt: updatedAt},
+			{MerchantID: 3, PaymentType: "CARD", MaxAmount: 1000000, UpdatedAt: updatedAt},
+			{MerchantID: 4, MaxAmount: 1, UpdatedAt: updatedAt},
+		}
+		for i := range limits {
+			assert.NoError(t, lr.Upsert(ctx, &limits[i]))
+		}
+
+		res, err := lr.FetchEffective(ctx, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, limits[:2], res)
+	})
+
+	t.Run("ReserveAndRelease", func(t *testing.T) {
+		lr, _ := newRepos(t)
+
+		volume, err := lr.Usage(ctx, 1, "CARD", "2026-10-19")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), volume)
+
+		ok, err := lr.Reserve(ctx, 1, "CARD", "2026-10-19", 600, 1000)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = lr.Reserve(ctx, 1, "CARD", "2026-10-19", 600, 1000)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = lr.Reserve(ctx, 1, "CARD", "2026-10-19", 400, 1000)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = lr.Reserve(ctx, 1, "CARD", "2026-10-19", 5000, 0)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		assert.NoError(t, lr.Release(ctx, 1, "CARD", "2026-10-19", 9000))
+		volume, err = lr.Usage(ctx, 1, "CARD", "2026-10-19")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), volume)
+	})
+}
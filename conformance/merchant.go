@@ -0,0 +1,242 @@
+// Package conformance checks that repository backends behave alike. Each
+// backend's tests call the suite for the interface it implements, with a
+// function returning a fresh, empty repository.
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// MerchantRepository runs the checks every domain.MerchantRepository must
+// pass.
+func MerchantRepository(t *testing.T, newRepo func(t *testing.T) domain.MerchantRepository) {
+	ctx := context.Background()
+
+	t.Run("StoreAndGetByID", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+
+		err := mr.Store(ctx, &m)
+		assert.NoError(t, err)
+		assert.NotZero(t, m.ID)
+		assert.Equal(t, int64(1), m.Version)
+
+		res, err := mr.GetByID(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, m, res)
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		mr := newRepo(t)
+
+		_, err := mr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("UpdateReplacesOwners", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+		assert.NoError(t, mr.Store(ctx, &m))
+
+		m.Status = domain.MerchantApproved
+		m.ReviewNotes = "documents checked"
+		m.ScreeningResult = domain.ScreeningClear
+		m.Owners = []domain.Owner{{Name: "Siti Rahma", IDNumber: "3171010101850002", Share: 100}}
+		err := mr.Update(ctx, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), m.Version)
+
+		res, err := mr.GetByID(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, m, res)
+	})
+
+	t.Run("UpdateStale", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+		assert.NoError(t, mr.Store(ctx, &m))
+		stale := m
+		assert.NoError(t, mr.Update(ctx, &m))
+
+		stale.ReviewNotes = "lost update"
+		err := mr.Update(ctx, &stale)
+		assert.Equal(t, domain.ErrStaleVersion, err)
+
+		res, err := mr.GetByID(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, m, res)
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+		m.ID = 404
+
+		err := mr.Update(ctx, &m)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Fetch", func(t *testing.T) {
+		mr := newRepo(t)
+		res, err := mr.Fetch(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Merchant{}, res)
+
+		first, second := kopiJoss(), domain.Merchant{Name: "WARUNG", Owners: []domain.Owner{}, Status: domain.MerchantDraft}
+		assert.NoError(t, mr.Store(ctx, &first))
+		assert.NoError(t, mr.Store(ctx, &second))
+
+		res, err = mr.Fetch(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Merchant{first, second}, res)
+	})
+
+	t.Run("Documents", func(t *testing.T) {
+		mr := newRepo(t)
+		m, other := kopiJoss(), kopiJoss()
+		assert.NoError(t, mr.Store(ctx, &m))
+		assert.NoError(t, mr.Store(ctx, &other))
+
+		res, err := mr.FetchDocuments(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.MerchantDocument{}, res)
+
+		createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+		idCard := domain.MerchantDocument{MerchantID: m.ID, Type: "id_card", FileName: "ktp.jpg", ContentType: "image/jpeg", Size: 2048, StorageKey: "merchants/1/a", CreatedAt: createdAt}
+		taxCard := domain.MerchantDocument{MerchantID: m.ID, Type: "tax_card", FileName: "npwp.pdf", ContentType: "application/pdf", Size: 4096, StorageKey: "merchants/1/b", CreatedAt: createdAt}
+		elsewhere := domain.MerchantDocument{MerchantID: other.ID, Type: "id_card", FileName: "ktp.jpg", ContentType: "image/jpeg", Size: 1024, StorageKey: "merchants/2/a", CreatedAt: createdAt}
+		for _, d := range []*domain.MerchantDocument{&idCard, &elsewhere, &taxCard} {
+			assert.NoError(t, mr.StoreDocument(ctx, d))
+			assert.NotZero(t, d.ID)
+		}
+
+		res, err = mr.FetchDocuments(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.MerchantDocument{idCard, taxCard}, res)
+	})
+
+	t.Run("Settings", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+		assert.NoError(t, mr.Store(ctx, &m))
+		assert.NoError(t, mr.InitSetting(ctx, &m))
+
+		qr := domain.Setting{
+			MerchantID:  m.ID,
+			Color:       "RED",
+			PaymentType: "QR",
+			PaymentName: "QRIS",
+			QR:          &domain.QRAccount{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", City: "JAKARTA"},
+		}
+		err := mr.StoreSetting(ctx, &qr)
+		assert.NoError(t, err)
+		assert.NotZero(t, qr.ID)
+
+		res, err := mr.FetchSettings(ctx, m.ID)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, domain.Setting{ID: res[0].ID, MerchantID: m.ID, Color: "RED", PaymentType: "CARD", PaymentName: "VISA", Version: 1}, res[0])
+		assert.Equal(t, qr, res[1])
+
+		setting, err := mr.GetSetting(ctx, qr.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, qr, setting)
+
+		setting, err = mr.GetSettingByNMID(ctx, "ID1026000012345")
+		assert.NoError(t, err)
+		assert.Equal(t, qr, setting)
+
+		duplicate := qr
+		duplicate.ID = 0
+		assert.Error(t, mr.StoreSetting(ctx, &duplicate))
+
+		_, err = mr.GetSetting(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+		_, err = mr.GetSettingByNMID(ctx, "ID1026000099999")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("UpdateSetting", func(t *testing.T) {
+		mr := newRepo(t)
+		m := kopiJoss()
+		assert.NoError(t, mr.Store(ctx, &m))
+		s := domain.Setting{MerchantID: m.ID, Color: "RED", PaymentType: "CARD", PaymentName: "VISA"}
+		assert.NoError(t, mr.StoreSetting(ctx, &s))
+		assert.Equal(t, int64(1), s.Version)
+		stale := s
+
+		s.Color = "BLUE"
+		s.PaymentType = "QR"
+		s.PaymentName = "QRIS"
+		s.QR = &domain.QRAccount{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", City: "JAKARTA"}
+		err := mr.UpdateSetting(ctx, &s)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), s.Version)
+
+		res, err := mr.GetSetting(ctx, s.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, s, res)
+
+		res, err = mr.GetSettingByNMID(ctx, "ID1026000012345")
+		assert.NoError(t, err)
+		assert.Equal(t, s, res)
+
+		stale.Color = "GREEN"
+		assert.Equal(t, domain.ErrStaleVersion, mr.UpdateSetting(ctx, &stale))
+
+		stale.ID = 404
+		assert.Equal(t, domain.ErrNotFound, mr.UpdateSetting(ctx, &stale))
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		mr := newRepo(t)
+		parent, child, stranger := kopiJoss(), kopiJoss(), kopiJoss()
+		for _, m := range []*domain.Merchant{&parent, &child, &stranger} {
+			assert.NoError(t, mr.Store(ctx, m))
+		}
+
+		err := mr.SetChild(ctx, &domain.MerchantGroup{ParentMerchantID: parent.ID, ChildMerchantID: child.ID, ShareCustomers: true})
+		assert.NoError(t, err)
+		err = mr.SetChild(ctx, &domain.MerchantGroup{ParentMerchantID: parent.ID, ChildMerchantID: stranger.ID})
+		assert.NoError(t, err)
+
+		authorized, err := mr.IsAuthorizedParent(ctx, &domain.MerchantGroup{ParentMerchantID: parent.ID, ChildMerchantID: child.ID})
+		assert.NoError(t, err)
+		assert.True(t, authorized)
+
+		authorized, err = mr.IsAuthorizedParent(ctx, &domain.MerchantGroup{ParentMerchantID: child.ID, ChildMerchantID: parent.ID})
+		assert.NoError(t, err)
+		assert.False(t, authorized)
+
+		shared, err := mr.CanShareCustomers(ctx, &domain.MerchantGroup{ParentMerchantID: parent.ID, ChildMerchantID: child.ID})
+		assert.NoError(t, err)
+		assert.True(t, shared)
+
+		shared, err = mr.CanShareCustomers(ctx, &domain.MerchantGroup{ParentMerchantID: parent.ID, ChildMerchantID: stranger.ID})
+		assert.NoError(t, err)
+		assert.False(t, shared)
+	})
+}
+
+func kopiJoss() domain.Merchant {
+	return domain.Merchant{
+		Name:               "KOPI_JOSS",
+		LegalName:          "PT Kopi Joss",
+		EntityType:         "pt",
+		RegistrationNumber: "AHU-0012345",
+		TaxID:              "012345678901000",
+		Address:            "Jl. Sudirman 1",
+		BankAccount:        domain.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "PT KOPI JOSS"},
+		Owners: []domain.Owner{
+			{Name: "Budi Santoso", IDNumber: "3171010101800001", Share: 60},
+			{Name: "Siti Rahma", IDNumber: "3171010101850002", Share: 40},
+		},
+		Status: domain.MerchantSubmitted,
+	}
+}
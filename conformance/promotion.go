@@ -0,0 +1,101 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// PromotionRepository runs the checks every domain.PromotionRepository must
+// pass.
+func PromotionRepository(t *testing.T, newRepo func(t *testing.T) domain.PromotionRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndFetch", func(t *testing.T) {
+		pr := newRepo(t)
+		first, second, elsewhere := promotion("HEMAT10", now), promotion("HEMAT20", now), promotion("HEMAT10", now)
+		first.BINs = []string{"411111", "522222"}
+		elsewhere.MerchantID = 2
+		for _, p := range []*domain.Promotion{&first, &second, &elsewhere} {
+			assert.NoError(t, pr.Store(ctx, p))
+			assert.NotZero(t, p.ID)
+		}
+
+		res, err := pr.Fetch(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Promotion{first, second}, res)
+
+		p, err := pr.GetByCode(ctx, 2, "HEMAT10")
+		assert.NoError(t, err)
+		assert.Equal(t, elsewhere, p)
+
+		_, err = pr.GetByCode(ctx, 1, "HEMAT30")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("ClaimTotalLimit", func(t *testing.T) {
+		pr := newRepo(t)
+		p := promotion("HEMAT10", now)
+		p.TotalLimit = 1
+		assert.NoError(t, pr.Store(ctx, &p))
+
+		ok, err := pr.Claim(ctx, p, 5)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = pr.Claim(ctx, p, 6)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, pr.Release(ctx, p.ID, 5))
+		ok, err = pr.Claim(ctx, p, 6)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("ClaimCustomerLimit", func(t *testing.T) {
+		pr := newRepo(t)
+		p := promotion("HEMAT10", now)
+		p.CustomerLimit = 1
+		assert.NoError(t, pr.Store(ctx, &p))
+
+		ok, err := pr.Claim(ctx, p, 5)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = pr.Claim(ctx, p, 5)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = pr.Claim(ctx, p, 6)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		res, err := pr.GetByCode(ctx, 1, "HEMAT10")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), res.Redeemed)
+
+		assert.NoError(t, pr.Release(ctx, p.ID, 5))
+		ok, err = pr.Claim(ctx, p, 5)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func promotion(code string, now time.Time) domain.Promotion {
+	return domain.Promotion{
+		MerchantID:  1,
+		Code:        code,
+		Type:        domain.DiscountPercent,
+		Value:       1000,
+		MaxDiscount: 50000,
+		BINs:        []string{},
+		StartsAt:    now.Add(-24 * time.Hour),
+		EndsAt:      now.Add(24 * time.Hour),
+	}
+}
@@ -0,0 +1,57 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// QRPaymentRepository runs the checks every domain.QRPaymentRepository must
+// pass.
+func QRPaymentRepository(t *testing.T, newRepo func(t *testing.T) domain.QRPaymentRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("ClaimOncePerMerchant", func(t *testing.T) {
+		qr := newRepo(t)
+		first := domain.QRPayment{MerchantID: 6, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+		claimed, err := qr.ClaimPayment(ctx, &first)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NotZero(t, first.ID)
+
+		retry := first
+		retry.ID = 0
+		claimed, err = qr.ClaimPayment(ctx, &retry)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		other := first
+		other.ID = 0
+		other.MerchantID = 7
+		claimed, err = qr.ClaimPayment(ctx, &other)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+
+	t.Run("UpdateAndRelease", func(t *testing.T) {
+		qr := newRepo(t)
+		p := domain.QRPayment{MerchantID: 6, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+		claimed, err := qr.ClaimPayment(ctx, &p)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		p.TransactionID = 9
+		assert.NoError(t, qr.UpdatePayment(ctx, &p))
+
+		assert.NoError(t, qr.ReleasePayment(ctx, p.ID))
+		retry := domain.QRPayment{MerchantID: 6, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+		claimed, err = qr.ClaimPayment(ctx, &retry)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+}
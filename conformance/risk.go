@@ -0,0 +1,107 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// RiskRepository runs the checks every domain.RiskRepository must pass.
+// Velocity counts read the transactions table, so newRepos also returns a
+// transaction repository on the same store.
+func RiskRepository(t *testing.T, newRepos func(t *testing.T) (domain.RiskRepository, domain.TransactionRepository)) {
+	ctx := context.Background()
+
+	t.Run("StoreAndGetByID", func(t *testing.T) {
+		rr, _ := newRepos(t)
+		rule := blocklist(1)
+
+		err := rr.Store(ctx, &rule)
+		assert.NoError(t, err)
+		assert.NotZero(t, rule.ID)
+
+		res, err := rr.GetByID(ctx, rule.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, rule, res)
+
+		_, err = rr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("FetchEnabled", func(t *testing.T) {
+		rr, _ := newRepos(t)
+		own, parent, disabled, elsewhere := blocklist(2), blocklist(1), blocklist(2), blocklist(3)
+		disabled.Enabled = false
+		for _, rule := range []*domain.RiskRule{&own, &parent, &disabled, &elsewhere} {
+			assert.NoError(t, rr.Store(ctx, rule))
+		}
+
+		res, err := rr.FetchEnabled(ctx, 2, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.RiskRule{own, parent}, res)
+
+		res, err = rr.Fetch(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.RiskRule{own, disabled}, res)
+	})
+
+	t.Run("UpdateAndDelete", func(t *testing.T) {
+		rr, _ := newRepos(t)
+		rule := blocklist(1)
+		assert.NoError(t, rr.Store(ctx, &rule))
+
+		rule.Values = []string{}
+		rule.Enabled = false
+		assert.NoError(t, rr.Update(ctx, &rule))
+		res, err := rr.GetByID(ctx, rule.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, rule, res)
+
+		assert.NoError(t, rr.Delete(ctx, rule.ID))
+		_, err = rr.GetByID(ctx, rule.ID)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("CountSince", func(t *testing.T) {
+		rr, tr := newRepos(t)
+		since := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+		before, after, sameCard, otherCard := payment(1), payment(1), payment(1), payment(1)
+		before.CreatedAt = since.Add(-time.Minute)
+		after.CustomerID = 7
+		sameCard.CardToken = "tok_2"
+		otherCard.CardToken = "tok_3"
+		otherCard.CardFingerprint = "fp_3"
+		for _, data := range []*domain.Transaction{&before, &after, &sameCard, &otherCard} {
+			assert.NoError(t, tr.Store(ctx, data))
+		}
+
+		// Cards count by fingerprint, whatever token they were charged by.
+		count, err := rr.CountSince(ctx, 1, domain.RiskFieldCard, "fp_1", since)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		count, err = rr.CountSince(ctx, 1, domain.RiskFieldCustomer, "7", since)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		_, err = rr.CountSince(ctx, 1, domain.RiskFieldBIN, "411111", since)
+		assert.Error(t, err)
+	})
+}
+
+func blocklist(merchantID int64) domain.RiskRule {
+	return domain.RiskRule{
+		MerchantID: merchantID,
+		Name:       "blocked cards",
+		Type:       domain.RiskRuleBlocklist,
+		Action:     domain.RiskBlock,
+		Field:      domain.RiskFieldCard,
+		Values:     []string{"tok_9", "tok_8"},
+		Enabled:    true,
+		CreatedAt:  time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC),
+	}
+}
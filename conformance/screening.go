@@ -0,0 +1,51 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// ScreeningRepository runs the checks every domain.ScreeningRepository must
+// pass.
+func ScreeningRepository(t *testing.T, newRepo func(t *testing.T) domain.ScreeningRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndFetch", func(t *testing.T) {
+		sr := newRepo(t)
+		cleared := domain.Screening{MerchantID: 1, Result: domain.ScreeningClear, Hits: []domain.ScreeningHit{}, ListVersion: "2026-10-01", CreatedAt: now.Add(-time.Hour)}
+		match := domain.Screening{
+			MerchantID: 1,
+			Result:     domain.ScreeningMatch,
+			Score:      96,
+			Hits: []domain.ScreeningHit{
+				{List: "OFAC", EntryID: "1001", EntryName: "Budi Santoso", Subject: "owner", Score: 96},
+				{List: "UN", EntryID: "2002", EntryName: "Budi Santosa", Subject: "owner", Score: 88},
+			},
+			ListVersion: "2026-10-19",
+			CreatedAt:   now,
+		}
+		elsewhere := cleared
+		elsewhere.MerchantID = 2
+		for _, s := range []*domain.Screening{&cleared, &match, &elsewhere} {
+			assert.NoError(t, sr.Store(ctx, s))
+			assert.NotZero(t, s.ID)
+		}
+
+		res, err := sr.Fetch(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Screening{match, cleared}, res)
+
+		latest, err := sr.Latest(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, match, latest)
+
+		_, err = sr.Latest(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+}
@@ -0,0 +1,128 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// SubscriptionRepository runs the checks every domain.SubscriptionRepository
+// must pass.
+func SubscriptionRepository(t *testing.T, newRepo func(t *testing.T) domain.SubscriptionRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("Plans", func(t *testing.T) {
+		sr := newRepo(t)
+		basic := domain.Plan{MerchantID: 1, Name: "Basic", Amount: 99000, Currency: "IDR", IntervalMonths: 1, TrialDays: 14}
+		pro, elsewhere := basic, basic
+		pro.Name = "Pro"
+		elsewhere.MerchantID = 2
+		for _, p := range []*domain.Plan{&basic, &pro, &elsewhere} {
+			assert.NoError(t, sr.StorePlan(ctx, p))
+			assert.NotZero(t, p.ID)
+		}
+
+		res, err := sr.FetchPlans(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Plan{basic, pro}, res)
+
+		p, err := sr.GetPlan(ctx, elsewhere.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, elsewhere, p)
+
+		_, err = sr.GetPlan(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("StoreAndUpdate", func(t *testing.T) {
+		sr := newRepo(t)
+		s := subscription(now)
+		assert.NoError(t, sr.Store(ctx, &s))
+		assert.NotZero(t, s.ID)
+		assert.Equal(t, int64(1), s.Version)
+
+		res, err := sr.GetByID(ctx, s.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, s, res)
+
+		stale := s
+		s.Status = domain.SubscriptionPastDue
+		s.FailedAttempts = 1
+		s.NextRetryAt = now.Add(24 * time.Hour)
+		assert.NoError(t, sr.Update(ctx, &s))
+		assert.Equal(t, int64(2), s.Version)
+
+		res, err = sr.GetByID(ctx, s.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, s, res)
+
+		stale.Status = domain.SubscriptionCanceled
+		assert.Equal(t, domain.ErrStaleVersion, sr.Update(ctx, &stale))
+		stale.ID = 404
+		assert.Equal(t, domain.ErrNotFound, sr.Update(ctx, &stale))
+		_, err = sr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("FetchDue", func(t *testing.T) {
+		sr := newRepo(t)
+		ended, running, retrying, waiting, canceled := subscription(now), subscription(now), subscription(now), subscription(now), subscription(now)
+		running.CurrentPeriodEnd = now.Add(time.Hour)
+		retrying.Status = domain.SubscriptionPastDue
+		retrying.NextRetryAt = now
+		waiting.Status = domain.SubscriptionPastDue
+		waiting.NextRetryAt = now.Add(time.Hour)
+		canceled.Status = domain.SubscriptionCanceled
+		for _, s := range []*domain.Subscription{&ended, &running, &retrying, &waiting, &canceled} {
+			assert.NoError(t, sr.Store(ctx, s))
+		}
+
+		res, err := sr.FetchDue(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Subscription{ended, retrying}, res)
+	})
+
+	t.Run("ClaimCycle", func(t *testing.T) {
+		sr := newRepo(t)
+		c := domain.BillingCycle{SubscriptionID: 1, PeriodStart: now, Attempt: 1, Amount: 99000, Status: domain.CycleProcessing}
+
+		claimed, err := sr.ClaimCycle(ctx, &c)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NotZero(t, c.ID)
+
+		again := domain.BillingCycle{SubscriptionID: 1, PeriodStart: now, Attempt: 1, Amount: 99000, Status: domain.CycleProcessing}
+		claimed, err = sr.ClaimCycle(ctx, &again)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		c.Status = domain.CyclePaid
+		c.TransactionID = 4
+		assert.NoError(t, sr.UpdateCycle(ctx, &c))
+		res, err := sr.GetCycle(ctx, 1, now, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, c, res)
+
+		_, err = sr.GetCycle(ctx, 1, now, 2)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+}
+
+func subscription(now time.Time) domain.Subscription {
+	return domain.Subscription{
+		MerchantID:         1,
+		CustomerID:         2,
+		PaymentMethodID:    3,
+		SettingID:          1,
+		PlanID:             4,
+		Status:             domain.SubscriptionActive,
+		CurrentPeriodStart: now.AddDate(0, -1, 0),
+		CurrentPeriodEnd:   now,
+		Adjustment:         -5000,
+	}
+}
@@ -0,0 +1,200 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// TransactionRepository runs the checks every domain.TransactionRepository
+// must pass.
+func TransactionRepository(t *testing.T, newRepo func(t *testing.T) domain.TransactionRepository) {
+	ctx := context.Background()
+
+	t.Run("StoreAndGetByID", func(t *testing.T) {
+		tr := newRepo(t)
+		data := payment(1)
+
+		err := tr.Store(ctx, &data)
+		assert.NoError(t, err)
+		assert.NotZero(t, data.ID)
+		assert.Equal(t, int64(1), data.Version)
+
+		res, err := tr.GetByID(ctx, data.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, data, res)
+	})
+
+	t.Run("GetByIDNotFound", func(t *testing.T) {
+		tr := newRepo(t)
+
+		_, err := tr.GetByID(ctx, 404)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		tr := newRepo(t)
+		data := payment(1)
+		data.RiskRules = nil
+		assert.NoError(t, tr.Store(ctx, &data))
+
+		data.Status = true
+		data.State = domain.TransactionCaptured
+		data.Processor = "simulator-a"
+		data.ProcessorReference = "simulator-a-1"
+		data.ResponseCode = domain.ResponseApproved
+		data.RiskRules = []string{"velocity"}
+		data.Description = ""
+		data.Metadata = map[string]string{"order": "A2"}
+		createdAt := data.CreatedAt
+		data.CreatedAt = createdAt.Add(time.Hour)
+		err := tr.Update(ctx, &data)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), data.Version)
+
+		res, err := tr.GetByID(ctx, data.ID)
+		assert.NoError(t, err)
+		data.CreatedAt = createdAt
+		assert.Equal(t, data, res)
+	})
+
+	t.Run("UpdateStale", func(t *testing.T) {
+		tr := newRepo(t)
+		data := payment(1)
+		assert.NoError(t, tr.Store(ctx, &data))
+		stale := data
+		data.State = domain.TransactionAuthorized
+		assert.NoError(t, tr.Update(ctx, &data))
+
+		stale.State = domain.TransactionVoided
+		err := tr.Update(ctx, &stale)
+		assert.Equal(t, domain.ErrStaleVersion, err)
+
+		res, err := tr.GetByID(ctx, data.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, data, res)
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		tr := newRepo(t)
+		data := payment(1)
+		data.ID = 404
+
+		err := tr.Update(ctx, &data)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("FetchByState", func(t *testing.T) {
+		tr := newRepo(t)
+		res, err := tr.FetchByState(ctx, 1, domain.TransactionPending)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Transaction{}, res)
+
+		first, captured, elsewhere, second := payment(1), payment(1), payment(2), payment(1)
+		captured.State = domain.TransactionCaptured
+		for _, data := range []*domain.Transaction{&first, &captured, &elsewhere, &second} {
+			assert.NoError(t, tr.Store(ctx, data))
+		}
+
+		res, err = tr.FetchByState(ctx, 1, domain.TransactionPending)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Transaction{first, second}, res)
+	})
+
+	t.Run("GetByMerchantReference", func(t *testing.T) {
+		tr := newRepo(t)
+		data, unreferenced, elsewhere := payment(1), payment(1), payment(2)
+		data.MerchantReference = "order-1"
+		elsewhere.MerchantReference = "order-1"
+		for _, d := range []*domain.Transaction{&unreferenced, &data, &elsewhere} {
+			assert.NoError(t, tr.Store(ctx, d))
+		}
+
+		res, err := tr.GetByMerchantReference(ctx, 1, "order-1")
+		assert.NoError(t, err)
+		assert.Equal(t, data, res)
+
+		res, err = tr.GetByMerchantReference(ctx, 2, "order-1")
+		assert.NoError(t, err)
+		assert.Equal(t, elsewhere, res)
+
+		_, err = tr.GetByMerchantReference(ctx, 1, "order-2")
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("ReferenceTaken", func(t *testing.T) {
+		tr := newRepo(t)
+		data, duplicate, other := payment(1), payment(1), payment(1)
+		data.MerchantReference = "order-1"
+		duplicate.MerchantReference = "order-1"
+		other.MerchantReference = "order-2"
+		assert.NoError(t, tr.Store(ctx, &data))
+		assert.NoError(t, tr.Store(ctx, &other))
+
+		err := tr.Store(ctx, &duplicate)
+		assert.Equal(t, domain.ErrReferenceTaken, err)
+
+		other.MerchantReference = "order-1"
+		err = tr.Update(ctx, &other)
+		assert.Equal(t, domain.ErrReferenceTaken, err)
+
+		res, err := tr.GetByMerchantReference(ctx, 1, "order-1")
+		assert.NoError(t, err)
+		assert.Equal(t, data, res)
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		tr := newRepo(t)
+		data, other := payment(1), payment(1)
+		assert.NoError(t, tr.Store(ctx, &data))
+		assert.NoError(t, tr.Store(ctx, &other))
+
+		res, err := tr.FetchChanges(ctx, data.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.TransactionChange{}, res)
+
+		createdAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+		changes := []domain.TransactionChange{
+			{TransactionID: data.ID, Version: 2, Field: "description", Before: `"Order A1"`, After: `""`, CreatedAt: createdAt},
+			{TransactionID: other.ID, Version: 2, Field: "state", Before: `"pending"`, After: `"declined"`, CreatedAt: createdAt},
+			{TransactionID: data.ID, Version: 3, Field: "metadata", Before: `{}`, After: `{"order":"A1"}`, CreatedAt: createdAt},
+		}
+		err = tr.StoreChanges(ctx, changes)
+		assert.NoError(t, err)
+		for _, c := range changes {
+			assert.NotZero(t, c.ID)
+		}
+
+		res, err = tr.FetchChanges(ctx, data.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.TransactionChange{changes[0], changes[2]}, res)
+	})
+}
+
+func payment(merchantID int64) domain.Transaction {
+	return domain.Transaction{
+		MerchantID:         merchantID,
+		ParentMerchantID:   merchantID,
+		SettingID:          1,
+		Amount:             150000,
+		Currency:           "IDR",
+		PaymentType:        "CARD",
+		State:              domain.TransactionPending,
+		CardToken:          "tok_1",
+		CardLast4:          "1111",
+		CardBrand:          "VISA",
+		CardFingerprint:    "fp_1",
+		SettlementCurrency: "IDR",
+		IPAddress:          "203.0.113.7",
+		IPCountry:          "ID",
+		RiskDecision:       "allow",
+		RiskRules:          []string{"new_card", "velocity"},
+		Description:        "Order A1",
+		Metadata:           map[string]string{"order": "A1", "channel": "web"},
+		CreatedAt:          time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC),
+	}
+}
@@ -0,0 +1,131 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// VirtualAccountRepository runs the checks every
+// domain.VirtualAccountRepository must pass.
+func VirtualAccountRepository(t *testing.T, newRepo func(t *testing.T) domain.VirtualAccountRepository) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+
+	t.Run("StoreAndGet", func(t *testing.T) {
+		vr := newRepo(t)
+		va := virtualAccount(now)
+		assert.NoError(t, vr.Store(ctx, &va))
+		assert.NotZero(t, va.ID)
+
+		res, err := vr.GetByID(ctx, va.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, va, res)
+
+		res, err = vr.GetByNumber(ctx, va.Bank, va.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, va, res)
+
+		_, err = vr.GetByNumber(ctx, "MANDIRI", va.Number)
+		assert.Equal(t, domain.ErrNotFound, err)
+	})
+
+	t.Run("UnnumberedAccounts", func(t *testing.T) {
+		vr := newRepo(t)
+		first, second := virtualAccount(now), virtualAccount(now)
+		first.Number, second.Number = "", ""
+		assert.NoError(t, vr.Store(ctx, &first))
+		assert.NoError(t, vr.Store(ctx, &second))
+
+		first.Number = "8808123456789"
+		assert.NoError(t, vr.Update(ctx, &first))
+		res, err := vr.GetByID(ctx, first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, first, res)
+
+		res, err = vr.GetByID(ctx, second.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "", res.Number)
+	})
+
+	t.Run("LinkOnce", func(t *testing.T) {
+		vr := newRepo(t)
+		va := virtualAccount(now)
+		va.TransactionID = 0
+		assert.NoError(t, vr.Store(ctx, &va))
+
+		linked, err := vr.Link(ctx, va.ID, 7)
+		assert.NoError(t, err)
+		assert.True(t, linked)
+
+		linked, err = vr.Link(ctx, va.ID, 8)
+		assert.NoError(t, err)
+		assert.False(t, linked)
+
+		assert.NoError(t, vr.Unlink(ctx, va.ID, 8))
+		res, err := vr.GetByID(ctx, va.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), res.TransactionID)
+
+		assert.NoError(t, vr.Unlink(ctx, va.ID, 7))
+		res, err = vr.GetByID(ctx, va.ID)
+		assert.NoError(t, err)
+		assert.Zero(t, res.TransactionID)
+	})
+
+	t.Run("FetchExpired", func(t *testing.T) {
+		vr := newRepo(t)
+		expired, later, standing := virtualAccount(now), virtualAccount(now), virtualAccount(now)
+		expired.ExpiresAt = now.Add(-time.Minute)
+		later.Number = "8808000000002"
+		standing.Number = "8808000000003"
+		standing.ExpiresAt = time.Time{}
+		standing.Standing = true
+		for _, va := range []*domain.VirtualAccount{&expired, &later, &standing} {
+			assert.NoError(t, vr.Store(ctx, va))
+		}
+
+		res, err := vr.FetchExpired(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.VirtualAccount{expired}, res)
+	})
+
+	t.Run("ClaimPaymentOnce", func(t *testing.T) {
+		vr := newRepo(t)
+		p := domain.VirtualAccountPayment{VirtualAccountID: 1, Reference: "bank-1", Amount: 150000, PaidAt: now}
+		claimed, err := vr.ClaimPayment(ctx, &p)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		assert.NotZero(t, p.ID)
+
+		retry := domain.VirtualAccountPayment{VirtualAccountID: 1, Reference: "bank-1", Amount: 150000, PaidAt: now}
+		claimed, err = vr.ClaimPayment(ctx, &retry)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		p.TransactionID = 7
+		assert.NoError(t, vr.UpdatePayment(ctx, &p))
+		assert.NoError(t, vr.ReleasePayment(ctx, p.ID))
+		claimed, err = vr.ClaimPayment(ctx, &retry)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+}
+
+func virtualAccount(now time.Time) domain.VirtualAccount {
+	return domain.VirtualAccount{
+		MerchantID:    1,
+		SettingID:     1,
+		TransactionID: 7,
+		Bank:          "BCA",
+		Number:        "8808000000001",
+		Mode:          domain.VirtualAccountClosed,
+		Amount:        150000,
+		Status:        domain.VirtualAccountActive,
+		ExpiresAt:     now.Add(24 * time.Hour),
+	}
+}
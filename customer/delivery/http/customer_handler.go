@@ -0,0 +1,201 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type CustomerHandler struct {
+	Usecase domain.CustomerUsecase
+}
+
+func NewCustomerHandler(e *echo.Echo, u domain.CustomerUsecase) *CustomerHandler {
+	handler := &CustomerHandler{
+		Usecase: u,
+	}
+
+	e.POST("/customers", handler.Store)
+	e.GET("/customers", handler.Fetch)
+	e.GET("/customers/:id", handler.GetByID)
+	e.PUT("/customers/:id", handler.Update)
+	e.DELETE("/customers/:id", handler.Delete)
+	e.POST("/customers/:id/payment_methods", handler.AddPaymentMethod)
+	e.GET("/customers/:id/payment_methods", handler.FetchPaymentMethods)
+	e.DELETE("/payment_methods/:id", handler.DeletePaymentMethod)
+
+	return handler
+}
+
+func (h *CustomerHandler) Store(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Customer
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.Name == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Store(ctx, &data)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *CustomerHandler) Fetch(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, merchantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *CustomerHandler) GetByID(c echo.Context) error {
+	id, merchantID, ok := params(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *CustomerHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var data domain.Customer
+	c.Bind(&data)
+	data.ID = id
+	if data.MerchantID == 0 || data.Name == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err = h.Usecase.Update(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *CustomerHandler) Delete(c echo.Context) error {
+	id, merchantID, ok := params(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	err := h.Usecase.Delete(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+type paymentMethodRequest struct {
+	MerchantID int64  `json:"merchantId"`
+	CardToken  string `json:"cardToken"`
+}
+
+func (h *CustomerHandler) AddPaymentMethod(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var req paymentMethodRequest
+	c.Bind(&req)
+	if req.MerchantID == 0 || req.CardToken == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	data := domain.PaymentMethod{CustomerID: id, CardToken: req.CardToken}
+	err = h.Usecase.AddPaymentMethod(ctx, req.MerchantID, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *CustomerHandler) FetchPaymentMethods(c echo.Context) error {
+	id, merchantID, ok := params(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.FetchPaymentMethods(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *CustomerHandler) DeletePaymentMethod(c echo.Context) error {
+	id, merchantID, ok := params(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	err := h.Usecase.DeletePaymentMethod(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// params reads the :id path param and the merchantId query param that every
+// read and delete is scoped by.
+func params(c echo.Context) (int64, int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return id, merchantID, true
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrInvalidCard:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
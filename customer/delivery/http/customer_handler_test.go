@@ -0,0 +1,129 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	customerHttp "github.com/hezbymuhammad/payment-gateway/customer/delivery/http"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+func TestStore(t *testing.T) {
+	mockUsecase := new(mocks.CustomerUsecase)
+	mockUsecase.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+
+	j, err := json.Marshal(domain.Customer{MerchantID: 1, Name: "Budi"})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/customers", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/customers")
+
+	handler := customerHttp.NewCustomerHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestStoreInvalidParams(t *testing.T) {
+	mockUsecase := new(mocks.CustomerUsecase)
+
+	j, err := json.Marshal(domain.Customer{Name: "Budi"})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/customers", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/customers")
+
+	handler := customerHttp.NewCustomerHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	mockUsecase := new(mocks.CustomerUsecase)
+	mockUsecase.On("GetByID", mock.Anything, int64(2), int64(1)).Return(domain.Customer{}, domain.ErrNotFound).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/customers/1?merchantId=2", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/customers/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+	handler := customerHttp.NewCustomerHandler(echo.New(), mockUsecase)
+	err = handler.GetByID(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAddPaymentMethod(t *testing.T) {
+	mockUsecase := new(mocks.CustomerUsecase)
+	mockUsecase.On("AddPaymentMethod", mock.Anything, int64(1), mock.MatchedBy(func(pm *domain.PaymentMethod) bool {
+		return pm.CustomerID == 3 && pm.CardToken == "tok_1"
+	})).Return(nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/customers/3/payment_methods", strings.NewReader(`{"merchantId":1,"cardToken":"tok_1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/customers/:id/payment_methods")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("3")
+
+	handler := customerHttp.NewCustomerHandler(echo.New(), mockUsecase)
+	err = handler.AddPaymentMethod(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestFetchPaymentMethods(t *testing.T) {
+	mockUsecase := new(mocks.CustomerUsecase)
+	mockUsecase.On("FetchPaymentMethods", mock.Anything, int64(1), int64(3)).Return([]domain.PaymentMethod{{ID: 5, Last4: "1111"}}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/customers/3/payment_methods?merchantId=1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/customers/:id/payment_methods")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("3")
+
+	handler := customerHttp.NewCustomerHandler(echo.New(), mockUsecase)
+	err = handler.FetchPaymentMethods(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"last4":"1111"`)
+}
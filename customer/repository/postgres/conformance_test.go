@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	customerRepo "github.com/hezbymuhammad/payment-gateway/customer/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.CustomerRepository(t, func(t *testing.T) domain.CustomerRepository {
+		return customerRepo.NewCustomerRepository(migrationtest.NewPostgresDB(t))
+	})
+}
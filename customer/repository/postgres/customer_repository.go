@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const (
+	customerColumns      = "id, merchant_id, name, email, phone"
+	paymentMethodColumns = "id, customer_id, merchant_id, type, card_token, last4, brand"
+)
+
+type postgresCustomerRepo struct {
+	DB *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) domain.CustomerRepository {
+	return &postgresCustomerRepo{
+		DB: db,
+	}
+}
+
+func (cr *postgresCustomerRepo) Store(ctx context.Context, c *domain.Customer) error {
+	query := "INSERT INTO customers (merchant_id, name, email, phone) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	err := cr.DB.QueryRowContext(ctx, query, c.MerchantID, c.Name, c.Email, c.Phone).Scan(&c.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (cr *postgresCustomerRepo) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers WHERE merchant_id=$1 ORDER BY id"
+
+	rows, err := cr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Customer{}
+	for rows.Next() {
+		data := domain.Customer{}
+		err = rows.Scan(&data.ID, &data.MerchantID, &data.Name, &data.Email, &data.Phone)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (cr *postgresCustomerRepo) GetByID(ctx context.Context, id int64) (domain.Customer, error) {
+	query := "SELECT " + customerColumns + " FROM customers WHERE id=$1"
+
+	data := domain.Customer{}
+	err := cr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.MerchantID, &data.Name, &data.Email, &data.Phone)
+	if err == sql.ErrNoRows {
+		return domain.Customer{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Customer{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *postgresCustomerRepo) Update(ctx context.Context, c *domain.Customer) error {
+	query := "UPDATE customers SET name=$1, email=$2, phone=$3 WHERE id=$4"
+
+	return cr.exec(ctx, query, c.Name, c.Email, c.Phone, c.ID)
+}
+
+// Delete removes a customer and its payment methods in one database
+// transaction.
+func (cr *postgresCustomerRepo) Delete(ctx context.Context, id int64) error {
+	tx, err := cr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM payment_methods WHERE customer_id=$1"
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "DELETE FROM customers WHERE id=$1"
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cr *postgresCustomerRepo) StorePaymentMethod(ctx context.Context, pm *domain.PaymentMethod) error {
+	query := "INSERT INTO payment_methods (customer_id, merchant_id, type, card_token, last4, brand) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	err := cr.DB.QueryRowContext(ctx, query, pm.CustomerID, pm.MerchantID, pm.Type, pm.CardToken, pm.Last4, pm.Brand).Scan(&pm.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (cr *postgresCustomerRepo) FetchPaymentMethods(ctx context.Context, customerID int64) ([]domain.PaymentMethod, error) {
+	query := "SELECT " + paymentMethodColumns + " FROM payment_methods WHERE customer_id=$1 ORDER BY id"
+
+	rows, err := cr.DB.QueryContext(ctx, query, customerID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.PaymentMethod{}
+	for rows.Next() {
+		data := domain.PaymentMethod{}
+		err = rows.Scan(&data.ID, &data.CustomerID, &data.MerchantID, &data.Type, &data.CardToken, &data.Last4, &data.Brand)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (cr *postgresCustomerRepo) GetPaymentMethod(ctx context.Context, id int64) (domain.PaymentMethod, error) {
+	query := "SELECT " + paymentMethodColumns + " FROM payment_methods WHERE id=$1"
+
+	data := domain.PaymentMethod{}
+	err := cr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.CustomerID, &data.MerchantID, &data.Type, &data.CardToken, &data.Last4, &data.Brand)
+	if err == sql.ErrNoRows {
+		return domain.PaymentMethod{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.PaymentMethod{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *postgresCustomerRepo) DeletePaymentMethod(ctx context.Context, id int64) error {
+	query := "DELETE FROM payment_methods WHERE id=$1"
+
+	return cr.exec(ctx, query, id)
+}
+
+func (cr *postgresCustomerRepo) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := cr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	customerRepo "github.com/hezbymuhammad/payment-gateway/customer/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.CustomerRepository(t, func(t *testing.T) domain.CustomerRepository {
+		return customerRepo.NewCustomerRepository(migrationtest.NewDB(t))
+	})
+}
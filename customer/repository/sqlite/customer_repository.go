@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type sqliteCustomerRepo struct {
+	DB *sql.DB
+}
+
+func NewCustomerRepository(db *sql.DB) domain.CustomerRepository {
+	return &sqliteCustomerRepo{
+		DB: db,
+	}
+}
+
+func (cr *sqliteCustomerRepo) Store(ctx context.Context, c *domain.Customer) error {
+	query := "INSERT INTO customers (merchant_id, name, email, phone) VALUES (?, ?, ?, ?)"
+
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, c.MerchantID, c.Name, c.Email, c.Phone)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	c.ID = lastID
+	return nil
+}
+
+func (cr *sqliteCustomerRepo) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Customer, error) {
+	query := "SELECT id, merchant_id, name, email, phone FROM customers WHERE merchant_id=? ORDER BY id"
+
+	rows, err := cr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Customer{}
+	for rows.Next() {
+		data := domain.Customer{}
+		err = rows.Scan(&data.ID, &data.MerchantID, &data.Name, &data.Email, &data.Phone)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (cr *sqliteCustomerRepo) GetByID(ctx context.Context, id int64) (domain.Customer, error) {
+	query := "SELECT id, merchant_id, name, email, phone FROM customers WHERE id=? LIMIT 1"
+
+	data := domain.Customer{}
+	err := cr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.MerchantID, &data.Name, &data.Email, &data.Phone)
+	if err == sql.ErrNoRows {
+		return domain.Customer{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Customer{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *sqliteCustomerRepo) Update(ctx context.Context, c *domain.Customer) error {
+	query := "UPDATE customers SET name=?, email=?, phone=? WHERE id=?"
+
+	return cr.exec(ctx, query, c.Name, c.Email, c.Phone, c.ID)
+}
+
+func (cr *sqliteCustomerRepo) Delete(ctx context.Context, id int64) error {
+	tx, err := cr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM payment_methods WHERE customer_id=?"
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "DELETE FROM customers WHERE id=?"
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cr *sqliteCustomerRepo) StorePaymentMethod(ctx context.Context, pm *domain.PaymentMethod) error {
+	query := "INSERT INTO payment_methods (customer_id, merchant_id, type, card_token, last4, brand) VALUES (?, ?, ?, ?, ?, ?)"
+
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, pm.CustomerID, pm.MerchantID, pm.Type, pm.CardToken, pm.Last4, pm.Brand)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	pm.ID = lastID
+	return nil
+}
+
+func (cr *sqliteCustomerRepo) FetchPaymentMethods(ctx context.Context, customerID int64) ([]domain.PaymentMethod, error) {
+	query := "SELECT id, customer_id, merchant_id, type, card_token, last4, brand FROM payment_methods WHERE customer_id=? ORDER BY id"
+
+	rows, err := cr.DB.QueryContext(ctx, query, customerID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.PaymentMethod{}
+	for rows.Next() {
+		data := domain.PaymentMethod{}
+		err = rows.Scan(&data.ID, &data.CustomerID, &data.MerchantID, &data.Type, &data.CardToken, &data.Last4, &data.Brand)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (cr *sqliteCustomerRepo) GetPaymentMethod(ctx context.Context, id int64) (domain.PaymentMethod, error) {
+	query := "SELECT id, customer_id, merchant_id, type, card_token, last4, brand FROM payment_methods WHERE id=? LIMIT 1"
+
+	data := domain.PaymentMethod{}
+	err := cr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.CustomerID, &data.MerchantID, &data.Type, &data.CardToken, &data.Last4, &data.Brand)
+	if err == sql.ErrNoRows {
+		return domain.PaymentMethod{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.PaymentMethod{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *sqliteCustomerRepo) DeletePaymentMethod(ctx context.Context, id int64) error {
+	query := "DELETE FROM payment_methods WHERE id=?"
+
+	return cr.exec(ctx, query, id)
+}
+
+func (cr *sqliteCustomerRepo) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	customerRepo "github.com/hezbymuhammad/payment-gateway/customer/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.Customer{MerchantID: 1, Name: "Budi", Email: "budi@example.com", Phone: "0812"}
+	query := regexp.QuoteMeta("INSERT INTO customers (merchant_id, name, email, phone) VALUES (?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.MerchantID, data.Name, data.Email, data.Phone).WillReturnResult(sqlmock.NewResult(3, 1))
+	cr := customerRepo.NewCustomerRepository(db)
+
+	err = cr.Store(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), data.ID)
+}
+
+func TestFetchByMerchant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "name", "email", "phone"}).
+		AddRow(1, 1, "Budi", "budi@example.com", "0812").
+		AddRow(2, 1, "Sari", "sari@example.com", "0813")
+	query := regexp.QuoteMeta("SELECT id, merchant_id, name, email, phone FROM customers WHERE merchant_id=? ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+	cr := customerRepo.NewCustomerRepository(db)
+
+	res, err := cr.FetchByMerchant(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "Sari", res[1].Name)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "name", "email", "phone"})
+	query := regexp.QuoteMeta("SELECT id, merchant_id, name, email, phone FROM customers WHERE id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+	cr := customerRepo.NewCustomerRepository(db)
+
+	_, err = cr.GetByID(context.TODO(), 1)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.Customer{ID: 1, MerchantID: 1, Name: "Budi", Email: "budi@example.com", Phone: "0812"}
+	query := regexp.QuoteMeta("UPDATE customers SET name=?, email=?, phone=? WHERE id=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.Name, data.Email, data.Phone, data.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	cr := customerRepo.NewCustomerRepository(db)
+
+	err = cr.Update(context.TODO(), data)
+	assert.NoError(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM payment_methods WHERE customer_id=?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM customers WHERE id=?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	cr := customerRepo.NewCustomerRepository(db)
+
+	err = cr.Delete(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM payment_methods WHERE customer_id=?")).WithArgs(1).WillReturnError(fmt.Errorf("some error"))
+	mock.ExpectRollback()
+	cr := customerRepo.NewCustomerRepository(db)
+
+	err = cr.Delete(context.TODO(), 1)
+	assert.Error(t, err)
+}
+
+func TestStorePaymentMethod(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.PaymentMethod{CustomerID: 1, MerchantID: 1, Type: "CARD", CardToken: "tok_1", Last4: "1111", Brand: "VISA"}
+	query := regexp.QuoteMeta("INSERT INTO payment_methods (customer_id, merchant_id, type, card_token, last4, brand) VALUES (?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.CustomerID, data.MerchantID, data.Type, data.CardToken, data.Last4, data.Brand).WillReturnResult(sqlmock.NewResult(5, 1))
+	cr := customerRepo.NewCustomerRepository(db)
+
+	err = cr.StorePaymentMethod(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), data.ID)
+}
+
+func TestGetPaymentMethod(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := domain.PaymentMethod{ID: 5, CustomerID: 1, MerchantID: 1, Type: "CARD", CardToken: "tok_1", Last4: "1111", Brand: "VISA"}
+	rows := sqlmock.NewRows([]string{"id", "customer_id", "merchant_id", "type", "card_token", "last4", "brand"}).
+		AddRow(data.ID, data.CustomerID, data.MerchantID, data.Type, data.CardToken, data.Last4, data.Brand)
+	query := regexp.QuoteMeta("SELECT id, customer_id, merchant_id, type, card_token, last4, brand FROM payment_methods WHERE id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(5).WillReturnRows(rows)
+	cr := customerRepo.NewCustomerRepository(db)
+
+	res, err := cr.GetPaymentMethod(context.TODO(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, data, res)
+}
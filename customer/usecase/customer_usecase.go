@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type customerUsecase struct {
+	customerRepo domain.CustomerRepository
+	merchantRepo domain.MerchantRepository
+	cardVault    domain.CardVaultUsecase
+}
+
+func NewCustomerUsecase(cr domain.CustomerRepository, mr domain.MerchantRepository, cv domain.CardVaultUsecase) domain.CustomerUsecase {
+	return &customerUsecase{
+		customerRepo: cr,
+		merchantRepo: mr,
+		cardVault:    cv,
+	}
+}
+
+func (cu *customerUsecase) Store(ctx context.Context, c *domain.Customer) error {
+	return cu.customerRepo.Store(ctx, c)
+}
+
+func (cu *customerUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Customer, error) {
+	return cu.customerRepo.FetchByMerchant(ctx, merchantID)
+}
+
+func (cu *customerUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Customer, error) {
+	c, err := cu.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	err = cu.checkAccess(ctx, merchantID, c)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+
+	return c, nil
+}
+
+// Update and Delete are reserved to the merchant that owns the customer;
+// shared children can only read and charge.
+func (cu *customerUsecase) Update(ctx context.Context, c *domain.Customer) error {
+	existing, err := cu.customerRepo.GetByID(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if existing.MerchantID != c.MerchantID {
+		return domain.ErrNotFound
+	}
+
+	return cu.customerRepo.Update(ctx, c)
+}
+
+func (cu *customerUsecase) Delete(ctx context.Context, merchantID int64, id int64) error {
+	existing, err := cu.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing.MerchantID != merchantID {
+		return domain.ErrNotFound
+	}
+
+	return cu.customerRepo.Delete(ctx, id)
+}
+
+// AddPaymentMethod saves a card vaulted by merchantID against the customer.
+func (cu *customerUsecase) AddPaymentMethod(ctx context.Context, merchantID int64, pm *domain.PaymentMethod) error {
+	_, err := cu.GetByID(ctx, merchantID, pm.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	card, err := cu.cardVault.GetByToken(ctx, merchantID, pm.CardToken)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidCard
+	}
+	if err != nil {
+		return err
+	}
+
+	pm.MerchantID = merchantID
+	pm.Type = "CARD"
+	pm.Last4 = card.Last4
+	pm.Brand = card.Brand
+	return cu.customerRepo.StorePaymentMethod(ctx, pm)
+}
+
+func (cu *customerUsecase) FetchPaymentMethods(ctx context.Context, merchantID int64, customerID int64) ([]domain.PaymentMethod, error) {
+	_, err := cu.GetByID(ctx, merchantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cu.customerRepo.FetchPaymentMethods(ctx, customerID)
+}
+
+func (cu *customerUsecase) GetPaymentMethod(ctx context.Context, merchantID int64, id int64) (domain.PaymentMethod, error) {
+	pm, err := cu.customerRepo.GetPaymentMethod(ctx, id)
+	if err != nil {
+		return domain.PaymentMethod{}, err
+	}
+
+	_, err = cu.GetByID(ctx, merchantID, pm.CustomerID)
+	if err != nil {
+		return domain.PaymentMethod{}, err
+	}
+
+	return pm, nil
+}
+
+func (cu *customerUsecase) DeletePaymentMethod(ctx context.Context, merchantID int64, id int64) error {
+	pm, err := cu.customerRepo.GetPaymentMethod(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	c, err := cu.customerRepo.GetByID(ctx, pm.CustomerID)
+	if err != nil {
+		return err
+	}
+	if pm.MerchantID != merchantID && c.MerchantID != merchantID {
+		return domain.ErrNotFound
+	}
+
+	return cu.customerRepo.DeletePaymentMethod(ctx, id)
+}
+
+// checkAccess lets the owner through, as well as any child of the owner whose
+// merchant group has customer sharing switched on. Everyone else gets
+// ErrNotFound so customer IDs cannot be probed.
+func (cu *customerUsecase) checkAccess(ctx context.Context, merchantID int64, c domain.Customer) error {
+	if c.MerchantID == merchantID {
+		return nil
+	}
+
+	shared, err := cu.merchantRepo.CanShareCustomers(ctx, &domain.MerchantGroup{
+		ParentMerchantID: c.MerchantID,
+		ChildMerchantID:  merchantID,
+	})
+	if err != nil {
+		return err
+	}
+	if !shared {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
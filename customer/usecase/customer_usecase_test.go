@@ -0,0 +1,130 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	customerUsecase "github.com/hezbymuhammad/payment-gateway/customer/usecase"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+var customer = domain.Customer{ID: 1, MerchantID: 1, Name: "Budi"}
+
+func TestGetByIDOwner(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	res, err := u.GetByID(context.TODO(), 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, customer, res)
+	mockMerchantRepo.AssertNotCalled(t, "CanShareCustomers", mock.Anything, mock.Anything)
+}
+
+func TestGetByIDSharedChild(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockMerchantRepo.On("CanShareCustomers", mock.Anything, &domain.MerchantGroup{ParentMerchantID: 1, ChildMerchantID: 2}).Return(true, nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	res, err := u.GetByID(context.TODO(), 2, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, customer, res)
+}
+
+func TestGetByIDNotShared(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockMerchantRepo.On("CanShareCustomers", mock.Anything, mock.Anything).Return(false, nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	_, err := u.GetByID(context.TODO(), 3, 1)
+
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestUpdateByChildIsRejected(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	err := u.Update(context.TODO(), &domain.Customer{ID: 1, MerchantID: 2, Name: "Other"})
+
+	assert.Equal(t, domain.ErrNotFound, err)
+	mockCustomerRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestDelete(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockCustomerRepo.On("Delete", mock.Anything, int64(1)).Return(nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	err := u.Delete(context.TODO(), 1, 1)
+
+	assert.NoError(t, err)
+	mockCustomerRepo.AssertExpectations(t)
+}
+
+func TestAddPaymentMethod(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(domain.Card{Token: "tok_1", Last4: "1111", Brand: "VISA"}, nil).Once()
+	mockCustomerRepo.On("StorePaymentMethod", mock.Anything, mock.Anything).Return(nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	data := &domain.PaymentMethod{CustomerID: 1, CardToken: "tok_1"}
+	err := u.AddPaymentMethod(context.TODO(), 1, data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1111", data.Last4)
+	assert.Equal(t, "VISA", data.Brand)
+	assert.Equal(t, int64(1), data.MerchantID)
+}
+
+func TestAddPaymentMethodForeignCard(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_2").Return(domain.Card{}, domain.ErrNotFound).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	err := u.AddPaymentMethod(context.TODO(), 1, &domain.PaymentMethod{CustomerID: 1, CardToken: "tok_2"})
+
+	assert.Equal(t, domain.ErrInvalidCard, err)
+}
+
+func TestGetPaymentMethodSharedChild(t *testing.T) {
+	mockCustomerRepo := new(mocks.CustomerRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCardVault := new(mocks.CardVaultUsecase)
+	pm := domain.PaymentMethod{ID: 5, CustomerID: 1, MerchantID: 1, CardToken: "tok_1"}
+	mockCustomerRepo.On("GetPaymentMethod", mock.Anything, int64(5)).Return(pm, nil).Once()
+	mockCustomerRepo.On("GetByID", mock.Anything, int64(1)).Return(customer, nil).Once()
+	mockMerchantRepo.On("CanShareCustomers", mock.Anything, mock.Anything).Return(true, nil).Once()
+	u := customerUsecase.NewCustomerUsecase(mockCustomerRepo, mockMerchantRepo, mockCardVault)
+
+	res, err := u.GetPaymentMethod(context.TODO(), 2, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, pm, res)
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// maxEvidenceSize is the largest evidence file accepted, in bytes.
+const maxEvidenceSize = 5 << 20
+
+// evidenceTypes are the kinds of file card networks accept as evidence.
+var evidenceTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"text/plain":      true,
+}
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type DisputeHandler struct {
+	Usecase domain.DisputeUsecase
+}
+
+func NewDisputeHandler(e *echo.Echo, u domain.DisputeUsecase) *DisputeHandler {
+	handler := &DisputeHandler{
+		Usecase: u,
+	}
+
+	e.POST("/disputes", handler.Open)
+	e.GET("/disputes/:id", handler.GetByID)
+	e.POST("/disputes/:id/evidence", handler.AddEvidence)
+	e.GET("/disputes/:id/evidence/:evidenceId", handler.GetEvidence)
+	e.POST("/disputes/:id/accept", handler.Accept)
+	e.POST("/disputes/:id/contest", handler.Contest)
+	e.POST("/disputes/:id/resolve", handler.Resolve)
+
+	return handler
+}
+
+// Open simulates the card network raising a dispute.
+func (h *DisputeHandler) Open(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Dispute
+	c.Bind(&data)
+	if _, ok := domain.DisputeReasons[data.ReasonCode]; !ok || data.TransactionID == 0 || data.Amount < 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Open(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *DisputeHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// AddEvidence takes one file in the multipart field "file". The type is
+// sniffed from the content rather than trusted from the upload.
+func (h *DisputeHandler) AddEvidence(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	if fh.Size > maxEvidenceSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, ResponseError{Message: "Evidence file is too large"})
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !evidenceTypes[contentType] {
+		return c.JSON(http.StatusUnsupportedMediaType, ResponseError{Message: "Unsupported evidence type"})
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	data := domain.DisputeEvidence{
+		DisputeID:   id,
+		FileName:    filepath.Base(fh.Filename),
+		ContentType: contentType,
+	}
+	ctx := c.Request().Context()
+	err = h.Usecase.AddEvidence(ctx, merchantID, &data, f)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *DisputeHandler) GetEvidence(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	evidenceID, err := strconv.ParseInt(c.Param("evidenceId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	e, r, err := h.Usecase.GetEvidence(ctx, merchantID, id, evidenceID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	defer r.Close()
+
+	c.Response().Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.FileName}))
+	return c.Stream(http.StatusOK, e.ContentType, r)
+}
+
+func (h *DisputeHandler) Accept(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Accept(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *DisputeHandler) Contest(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Contest(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type resolution struct {
+	Outcome string `json:"outcome"`
+}
+
+// Resolve simulates the card network deciding a contested dispute.
+func (h *DisputeHandler) Resolve(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	var data resolution
+	c.Bind(&data)
+	if data.Outcome != domain.DisputeWon && data.Outcome != domain.DisputeLost {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Resolve(ctx, id, data.Outcome == domain.DisputeWon)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrInvalidState, domain.ErrAmountMismatch, domain.ErrNoEvidence:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrDisputeState, domain.ErrDisputeDeadline, domain.ErrDisputed:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
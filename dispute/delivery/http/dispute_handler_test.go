@@ -0,0 +1,143 @@
+package http_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	disputeHttp "github.com/hezbymuhammad/payment-gateway/dispute/delivery/http"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+func TestOpenUnknownReason(t *testing.T) {
+	mockUsecase := new(mocks.DisputeUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/disputes", strings.NewReader(`{"transactionId":4,"reasonCode":"bored"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := disputeHttp.NewDisputeHandler(echo.New(), mockUsecase)
+	err = handler.Open(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "Open", mock.Anything, mock.Anything)
+}
+
+func TestAddEvidence(t *testing.T) {
+	mockUsecase := new(mocks.DisputeUsecase)
+	mockUsecase.On("AddEvidence", mock.Anything, int64(6), mock.MatchedBy(func(e *domain.DisputeEvidence) bool {
+		return e.DisputeID == 2 && e.FileName == "receipt.txt" && e.ContentType == "text/plain"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		b, _ := io.ReadAll(args.Get(3).(io.Reader))
+		args.Get(2).(*domain.DisputeEvidence).Size = int64(len(b))
+	}).Return(nil).Once()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "../../receipt.txt")
+	assert.NoError(t, err)
+	part.Write([]byte("Order 9 delivered and signed for."))
+	w.Close()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/disputes/2/evidence?merchantId=6", &body)
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/disputes/:id/evidence")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("2")
+
+	handler := disputeHttp.NewDisputeHandler(echo.New(), mockUsecase)
+	err = handler.AddEvidence(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"size":33`)
+}
+
+func TestAddEvidenceUnsupportedType(t *testing.T) {
+	mockUsecase := new(mocks.DisputeUsecase)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "receipt.pdf")
+	assert.NoError(t, err)
+	part.Write([]byte{0x7f, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0})
+	w.Close()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/disputes/2/evidence?merchantId=6", &body)
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/disputes/:id/evidence")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("2")
+
+	handler := disputeHttp.NewDisputeHandler(echo.New(), mockUsecase)
+	err = handler.AddEvidence(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	mockUsecase.AssertNotCalled(t, "AddEvidence", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContestAfterDeadline(t *testing.T) {
+	mockUsecase := new(mocks.DisputeUsecase)
+	mockUsecase.On("Contest", mock.Anything, int64(6), int64(2)).Return(domain.Dispute{}, domain.ErrDisputeDeadline).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/disputes/2/contest?merchantId=6", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/disputes/:id/contest")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("2")
+
+	handler := disputeHttp.NewDisputeHandler(echo.New(), mockUsecase)
+	err = handler.Contest(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestOpenDisputed(t *testing.T) {
+	mockUsecase := new(mocks.DisputeUsecase)
+	mockUsecase.On("Open", mock.Anything, mock.AnythingOfType("*domain.Dispute")).Return(domain.ErrDisputed).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/disputes", strings.NewReader(`{"transactionId":4,"reasonCode":"fraudulent"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := disputeHttp.NewDisputeHandler(echo.New(), mockUsecase)
+	err = handler.Open(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	disputeRepo "github.com/hezbymuhammad/payment-gateway/dispute/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.DisputeRepository(t, func(t *testing.T) domain.DisputeRepository {
+		return disputeRepo.NewDisputeRepository(migrationtest.NewPostgresDB(t))
+	})
+}
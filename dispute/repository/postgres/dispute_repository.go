@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const disputeColumns = "id, transaction_id, merchant_id, reason_code, amount, currency, status, respond_by, created_at"
+
+type postgresDisputeRepo struct {
+	DB *sql.DB
+}
+
+func NewDisputeRepository(db *sql.DB) domain.DisputeRepository {
+	return &postgresDisputeRepo{
+		DB: db,
+	}
+}
+
+// Store records a new dispute. A transaction can only have one dispute that
+// has not been won, so the network cannot charge it back twice.
+func (dr *postgresDisputeRepo) Store(ctx context.Context, d *domain.Dispute) error {
+	query := "INSERT INTO disputes (transaction_id, merchant_id, reason_code, amount, currency, status, respond_by, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+
+	err := dr.DB.QueryRowContext(ctx, query, d.TransactionID, d.MerchantID, d.ReasonCode, d.Amount, d.Currency, d.Status, d.RespondBy, d.CreatedAt).Scan(&d.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return disputedError(err)
+	}
+
+	return nil
+}
+
+func (dr *postgresDisputeRepo) GetByID(ctx context.Context, id int64) (domain.Dispute, error) {
+	query := "SELECT " + disputeColumns + " FROM disputes WHERE id=$1"
+
+	data, err := scanDispute(dr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Dispute{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Dispute{}, err
+	}
+
+	return data, nil
+}
+
+// FetchOverdue lists disputes still waiting on the merchant past their
+// deadline.
+func (dr *postgresDisputeRepo) FetchOverdue(ctx context.Context, now time.Time) ([]domain.Dispute, error) {
+	query := "SELECT " + disputeColumns + " FROM disputes WHERE status=$1 AND respond_by <= $2 ORDER BY id"
+
+	rows, err := dr.DB.QueryContext(ctx, query, domain.DisputeNeedsResponse, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Dispute{}
+	for rows.Next() {
+		data, err := scanDispute(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// Transition moves a dispute from one status to another and reports whether
+// it was still in the from status, so that only one caller acts on a move.
+func (dr *postgresDisputeRepo) Transition(ctx context.Context, id int64, from string, to string) (bool, error) {
+	query := "UPDATE disputes SET status=$1 WHERE id=$2 AND status=$3"
+
+	res, err := dr.DB.ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Lose moves a dispute from the from status to lost and books its
+// chargeback in the same database transaction, so a lost dispute always has
+// its debit. It reports whether the dispute was still in the from status.
+func (dr *postgresDisputeRepo) Lose(ctx context.Context, id int64, from string, e *domain.LedgerEntry) (bool, error) {
+	tx, err := dr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE disputes SET status=$1 WHERE id=$2 AND status=$3"
+	res, err := tx.ExecContext(ctx, query, domain.DisputeLost, id, from)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	var entryID int64
+	query = "INSERT INTO ledger_entries (merchant_id, transaction_id, type, reference, amount, currency, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+	err = tx.QueryRowContext(ctx, query, e.MerchantID, e.TransactionID, e.Type, e.Reference, e.Amount, e.Currency, e.CreatedAt).Scan(&entryID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	e.ID = entryID
+	return true, nil
+}
+
+func (dr *postgresDisputeRepo) StoreEvidence(ctx context.Context, e *domain.DisputeEvidence) error {
+	query := "INSERT INTO dispute_evidence (dispute_id, file_name, content_type, size, storage_key, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	err := dr.DB.QueryRowContext(ctx, query, e.DisputeID, e.FileName, e.ContentType, e.Size, e.StorageKey, e.CreatedAt).Scan(&e.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (dr *postgresDisputeRepo) FetchEvidence(ctx context.Context, disputeID int64) ([]domain.DisputeEvidence, error) {
+	query := "SELECT id, dispute_id, file_name, content_type, size, storage_key, created_at FROM dispute_evidence WHERE dispute_id=$1 ORDER BY id"
+
+	rows, err := dr.DB.QueryContext(ctx, query, disputeID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.DisputeEvidence{}
+	for rows.Next() {
+		data := domain.DisputeEvidence{}
+		err = rows.Scan(
+			&data.ID,
+			&data.DisputeID,
+			&data.FileName,
+			&data.ContentType,
+			&data.Size,
+			&data.StorageKey,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		data.CreatedAt = data.CreatedAt.UTC()
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDispute(row scanner) (domain.Dispute, error) {
+	data := domain.Dispute{}
+	err := row.Scan(
+		&data.ID,
+		&data.TransactionID,
+		&data.MerchantID,
+		&data.ReasonCode,
+		&data.Amount,
+		&data.Currency,
+		&data.Status,
+		&data.RespondBy,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	data.RespondBy = data.RespondBy.UTC()
+	data.CreatedAt = data.CreatedAt.UTC()
+	return data, nil
+}
+
+// disputedError turns a violation of the unique index on the transaction of
+// disputes not yet won into domain.ErrDisputed.
+func disputedError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "disputes_transaction" {
+		return domain.ErrDisputed
+	}
+	return err
+}
@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	disputeRepo "github.com/hezbymuhammad/payment-gateway/dispute/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.DisputeRepository(t, func(t *testing.T) domain.DisputeRepository {
+		return disputeRepo.NewDisputeRepository(migrationtest.NewDB(t))
+	})
+}
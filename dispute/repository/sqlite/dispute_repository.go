@@ -0,0 +1,267 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const disputeColumns = "id, transaction_id, merchant_id, reason_code, amount, currency, status, respond_by, created_at"
+
+type sqliteDisputeRepo struct {
+	DB *sql.DB
+}
+
+func NewDisputeRepository(db *sql.DB) domain.DisputeRepository {
+	return &sqliteDisputeRepo{
+		DB: db,
+	}
+}
+
+// Store records a new dispute. A transaction can only have one dispute that
+// has not been won, so the network cannot charge it back twice.
+func (dr *sqliteDisputeRepo) Store(ctx context.Context, d *domain.Dispute) error {
+	query := "INSERT INTO disputes (transaction_id, merchant_id, reason_code, amount, currency, status, respond_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := dr.exec(ctx, query, d.TransactionID, d.MerchantID, d.ReasonCode, d.Amount, d.Currency, d.Status, d.RespondBy, d.CreatedAt)
+	if err != nil {
+		return disputedError(err)
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	d.ID = lastID
+	return nil
+}
+
+func (dr *sqliteDisputeRepo) GetByID(ctx context.Context, id int64) (domain.Dispute, error) {
+	query := "SELECT " + disputeColumns + " FROM disputes WHERE id=? LIMIT 1"
+
+	data, err := scanDispute(dr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Dispute{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Dispute{}, err
+	}
+
+	return data, nil
+}
+
+// FetchOverdue lists disputes still waiting on the merchant past their
+// deadline.
+func (dr *sqliteDisputeRepo) FetchOverdue(ctx context.Context, now time.Time) ([]domain.Dispute, error) {
+	query := "SELECT " + disputeColumns + " FROM disputes WHERE status=? AND respond_by <= ? ORDER BY id"
+
+	rows, err := dr.DB.QueryContext(ctx, query, domain.DisputeNeedsResponse, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Dispute{}
+	for rows.Next() {
+		data, err := scanDispute(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// Transition moves a dispute from one status to another and reports whether
+// it was still in the from status, so that only one caller acts on a move.
+func (dr *sqliteDisputeRepo) Transition(ctx context.Context, id int64, from string, to string) (bool, error) {
+	query := "UPDATE disputes SET status=? WHERE id=? AND status=?"
+
+	res, err := dr.exec(ctx, query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Lose moves a dispute from the from status to lost and books its
+// chargeback in the same database transaction, so a lost dispute always has
+// its debit. It reports whether the dispute was still in the from status.
+func (dr *sqliteDisputeRepo) Lose(ctx context.Context, id int64, from string, e *domain.LedgerEntry) (bool, error) {
+	tx, err := dr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE disputes SET status=? WHERE id=? AND status=?"
+	res, err := tx.ExecContext(ctx, query, domain.DisputeLost, id, from)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	query = "INSERT INTO ledger_entries (merchant_id, transaction_id, type, reference, amount, currency, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	res, err = tx.ExecContext(ctx, query, e.MerchantID, e.TransactionID, e.Type, e.Reference, e.Amount, e.Currency, e.CreatedAt)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	e.ID = lastID
+	return true, nil
+}
+
+func (dr *sqliteDisputeRepo) StoreEvidence(ctx context.Context, e *domain.DisputeEvidence) error {
+	query := "INSERT INTO dispute_evidence (dispute_id, file_name, content_type, size, storage_key, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+
+	res, err := dr.exec(ctx, query, e.DisputeID, e.FileName, e.ContentType, e.Size, e.StorageKey, e.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	e.ID = lastID
+	return nil
+}
+
+func (dr *sqliteDisputeRepo) FetchEvidence(ctx context.Context, disputeID int64) ([]domain.DisputeEvidence, error) {
+	query := "SELECT id, dispute_id, file_name, content_type, size, storage_key, created_at FROM dispute_evidence WHERE dispute_id=? ORDER BY id"
+
+	rows, err := dr.DB.QueryContext(ctx, query, disputeID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.DisputeEvidence{}
+	for rows.Next() {
+		data := domain.DisputeEvidence{}
+		err = rows.Scan(
+			&data.ID,
+			&data.DisputeID,
+			&data.FileName,
+			&data.ContentType,
+			&data.Size,
+			&data.StorageKey,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (dr *sqliteDisputeRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := dr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDispute(row scanner) (domain.Dispute, error) {
+	data := domain.Dispute{}
+	err := row.Scan(
+		&data.ID,
+		&data.TransactionID,
+		&data.MerchantID,
+		&data.ReasonCode,
+		&data.Amount,
+		&data.Currency,
+		&data.Status,
+		&data.RespondBy,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	return data, nil
+}
+
+// disputedError turns a violation of the unique index on the transaction of
+// disputes not yet won into domain.ErrDisputed.
+func disputedError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), "disputes.transaction_id") {
+		return domain.ErrDisputed
+	}
+	return err
+}
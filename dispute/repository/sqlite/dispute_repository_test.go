@@ -0,0 +1,124 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	disputeRepo "github.com/hezbymuhammad/payment-gateway/dispute/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+var disputeColumns = []string{"id", "transaction_id", "merchant_id", "reason_code", "amount", "currency", "status", "respond_by", "created_at"}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now().UTC()
+	rows := sqlmock.NewRows(disputeColumns).AddRow(2, 4, 6, "fraudulent", 150000, "IDR", "needs_response", now.Add(time.Hour), now)
+	query := regexp.QuoteMeta("SELECT id, transaction_id, merchant_id, reason_code, amount, currency, status, respond_by, created_at FROM disputes WHERE id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(2).WillReturnRows(rows)
+	dr := disputeRepo.NewDisputeRepository(db)
+
+	res, err := dr.GetByID(context.TODO(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), res.TransactionID)
+	assert.Equal(t, domain.DisputeNeedsResponse, res.Status)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows(disputeColumns))
+	dr := disputeRepo.NewDisputeRepository(db)
+
+	_, err = dr.GetByID(context.TODO(), 2)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestTransition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE disputes SET status=? WHERE id=? AND status=?")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs("lost", 2, "needs_response").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(query).ExpectExec().WithArgs("under_review", 2, "needs_response").WillReturnResult(sqlmock.NewResult(0, 0))
+	dr := disputeRepo.NewDisputeRepository(db)
+
+	ok, err := dr.Transition(context.TODO(), 2, domain.DisputeNeedsResponse, domain.DisputeLost)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = dr.Transition(context.TODO(), 2, domain.DisputeNeedsResponse, domain.DisputeUnderReview)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLose(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	dr := disputeRepo.NewDisputeRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	d := domain.Dispute{TransactionID: 4, MerchantID: 6, ReasonCode: "fraudulent", Amount: 150000, Currency: "IDR", Status: domain.DisputeNeedsResponse, RespondBy: now, CreatedAt: now}
+	assert.NoError(t, dr.Store(context.TODO(), &d))
+	e := domain.LedgerEntry{MerchantID: 6, TransactionID: 4, Type: domain.LedgerChargeback, Reference: "dispute:1", Amount: -150000, Currency: "IDR", CreatedAt: now}
+
+	ok, err := dr.Lose(context.TODO(), d.ID, domain.DisputeNeedsResponse, &e)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NotZero(t, e.ID)
+
+	ok, err = dr.Lose(context.TODO(), d.ID, domain.DisputeNeedsResponse, &domain.LedgerEntry{Type: domain.LedgerChargeback, Reference: "dispute:1"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	var entries int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM ledger_entries").Scan(&entries))
+	assert.Equal(t, 1, entries)
+}
+
+func TestLoseRollsBack(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	dr := disputeRepo.NewDisputeRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	d := domain.Dispute{TransactionID: 4, MerchantID: 6, ReasonCode: "fraudulent", Amount: 150000, Currency: "IDR", Status: domain.DisputeNeedsResponse, RespondBy: now, CreatedAt: now}
+	assert.NoError(t, dr.Store(context.TODO(), &d))
+	_, err := db.Exec("INSERT INTO ledger_entries (merchant_id, transaction_id, type, reference, amount, currency, created_at) VALUES (6, 4, 'chargeback', 'dispute:1', -150000, 'IDR', ?)", now)
+	assert.NoError(t, err)
+
+	_, err = dr.Lose(context.TODO(), d.ID, domain.DisputeNeedsResponse, &domain.LedgerEntry{MerchantID: 6, TransactionID: 4, Type: domain.LedgerChargeback, Reference: "dispute:1", Amount: -150000, Currency: "IDR", CreatedAt: now})
+	assert.Error(t, err)
+
+	res, err := dr.GetByID(context.TODO(), d.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeNeedsResponse, res.Status)
+}
+
+func TestStoreDisputed(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	dr := disputeRepo.NewDisputeRepository(db)
+	now := time.Now().UTC().Truncate(time.Second)
+	d := domain.Dispute{TransactionID: 4, MerchantID: 6, ReasonCode: "fraudulent", Amount: 150000, Currency: "IDR", Status: domain.DisputeNeedsResponse, RespondBy: now, CreatedAt: now}
+	assert.NoError(t, dr.Store(context.TODO(), &d))
+
+	again := d
+	assert.Equal(t, domain.ErrDisputed, dr.Store(context.TODO(), &again))
+
+	ok, err := dr.Transition(context.TODO(), d.ID, domain.DisputeNeedsResponse, domain.DisputeWon)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, dr.Store(context.TODO(), &again))
+	assert.NotEqual(t, d.ID, again.ID)
+}
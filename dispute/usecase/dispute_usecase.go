@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/fx"
+)
+
+// Config holds the dispute settings that come from config.json.
+// ResponseWindow is how long a merchant has to respond when the network does
+// not give a deadline.
+type Config struct {
+	ResponseWindow time.Duration
+}
+
+type disputeUsecase struct {
+	disputeRepo  domain.DisputeRepository
+	transactions domain.TransactionUsecase
+	blobs        domain.BlobStore
+	cfg          Config
+}
+
+func NewDisputeUsecase(dr domain.DisputeRepository, tu domain.TransactionUsecase, bs domain.BlobStore, cfg Config) domain.DisputeUsecase {
+	return &disputeUsecase{
+		disputeRepo:  dr,
+		transactions: tu,
+		blobs:        bs,
+		cfg:          cfg,
+	}
+}
+
+// Open records a dispute the network raised against a captured transaction.
+// Without an amount the whole transaction is disputed. A transaction with a
+// dispute that is still open or was lost cannot be disputed again.
+func (du *disputeUsecase) Open(ctx context.Context, d *domain.Dispute) error {
+	t, err := du.transactions.GetByID(ctx, d.TransactionID)
+	if err != nil {
+		return err
+	}
+	if t.State != domain.TransactionCaptured {
+		return domain.ErrInvalidState
+	}
+	if d.Amount == 0 {
+		d.Amount = t.Amount
+	}
+	if d.Amount > t.Amount {
+		return domain.ErrAmountMismatch
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	d.MerchantID = t.MerchantID
+	d.Currency = t.Currency
+	d.Status = domain.DisputeNeedsResponse
+	d.CreatedAt = now
+	if d.RespondBy.IsZero() {
+		d.RespondBy = now.Add(du.cfg.ResponseWindow)
+	}
+	d.Evidence = []domain.DisputeEvidence{}
+
+	return du.disputeRepo.Store(ctx, d)
+}
+
+func (du *disputeUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	d, err := du.get(ctx, merchantID, id)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	d.Evidence, err = du.disputeRepo.FetchEvidence(ctx, d.ID)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	return d, nil
+}
+
+// AddEvidence stores a file for a dispute the merchant can still respond to.
+func (du *disputeUsecase) AddEvidence(ctx context.Context, merchantID int64, e *domain.DisputeEvidence, r io.Reader) error {
+	d, err := du.get(ctx, merchantID, e.DisputeID)
+	if err != nil {
+		return err
+	}
+	err = respondable(d, time.Now())
+	if err != nil {
+		return err
+	}
+
+	name, err := newKey()
+	if err != nil {
+		return err
+	}
+	e.StorageKey = fmt.Sprintf("disputes/%d/%s", d.ID, name)
+	e.Size, err = du.blobs.Put(ctx, e.StorageKey, r)
+	if err != nil {
+		return err
+	}
+	e.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return du.disputeRepo.StoreEvidence(ctx, e)
+}
+
+func (du *disputeUsecase) GetEvidence(ctx context.Context, merchantID int64, disputeID int64, id int64) (domain.DisputeEvidence, io.ReadCloser, error) {
+	d, err := du.GetByID(ctx, merchantID, disputeID)
+	if err != nil {
+		return domain.DisputeEvidence{}, nil, err
+	}
+
+	for _, e := range d.Evidence {
+		if e.ID == id {
+			r, err := du.blobs.Get(ctx, e.StorageKey)
+			if err != nil {
+				return domain.DisputeEvidence{}, nil, err
+			}
+			return e, r, nil
+		}
+	}
+
+	return domain.DisputeEvidence{}, nil, domain.ErrNotFound
+}
+
+// Accept concedes the dispute, which loses it straight away.
+func (du *disputeUsecase) Accept(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	d, err := du.get(ctx, merchantID, id)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	err = du.lose(ctx, &d, domain.DisputeNeedsResponse)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	return d, nil
+}
+
+// Contest submits the dispute's evidence to the network for review.
+func (du *disputeUsecase) Contest(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	d, err := du.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+	err = respondable(d, time.Now())
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+	if len(d.Evidence) == 0 {
+		return domain.Dispute{}, domain.ErrNoEvidence
+	}
+
+	err = du.transition(ctx, &d, domain.DisputeNeedsResponse, domain.DisputeUnderReview)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	return d, nil
+}
+
+// Resolve records the network's decision on a contested dispute.
+func (du *disputeUsecase) Resolve(ctx context.Context, id int64, won bool) (domain.Dispute, error) {
+	d, err := du.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	if won {
+		err = du.transition(ctx, &d, domain.DisputeUnderReview, domain.DisputeWon)
+	} else {
+		err = du.lose(ctx, &d, domain.DisputeUnderReview)
+	}
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+
+	return d, nil
+}
+
+// RunDeadlines loses every dispute the merchant let run past its deadline.
+func (du *disputeUsecase) RunDeadlines(ctx context.Context, now time.Time) error {
+	overdue, err := du.disputeRepo.FetchOverdue(ctx, now.UTC())
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for i := range overdue {
+		d := overdue[i]
+		err = du.lose(ctx, &d, domain.DisputeNeedsResponse)
+		if err != nil {
+			log.Printf("dispute %d: %v", d.ID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (du *disputeUsecase) get(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	d, err := du.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Dispute{}, err
+	}
+	if d.MerchantID != merchantID {
+		return domain.Dispute{}, domain.ErrNotFound
+	}
+
+	return d, nil
+}
+
+func (du *disputeUsecase) transition(ctx context.Context, d *domain.Dispute, from string, to string) error {
+	ok, err := du.disputeRepo.Transition(ctx, d.ID, from, to)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrDisputeState
+	}
+
+	d.Status = to
+	return nil
+}
+
+// lose marks the dispute lost and debits the disputed amount from the
+// merchant. Both are written together, and only by the caller that moved the
+// dispute; the amount is converted at the rate the transaction settled at.
+func (du *disputeUsecase) lose(ctx context.Context, d *domain.Dispute, from string) error {
+	if d.Status != from {
+		return domain.ErrDisputeState
+	}
+
+	amount := d.Amount
+	if d.Currency != domain.SettlementCurrency {
+		t, err := du.transactions.GetByID(ctx, d.TransactionID)
+		if err != nil {
+			return err
+		}
+		amount, err = fx.Convert(d.Amount, d.Currency, t.FXRate, t.FXMarkup)
+		if err != nil {
+			return err
+		}
+	}
+
+	ok, err := du.disputeRepo.Lose(ctx, d.ID, from, &domain.LedgerEntry{
+		MerchantID:    d.MerchantID,
+		TransactionID: d.TransactionID,
+		Type:          domain.LedgerChargeback,
+		Reference:     fmt.Sprintf("dispute:%d", d.ID),
+		Amount:        -amount,
+		Currency:      domain.SettlementCurrency,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrDisputeState
+	}
+
+	d.Status = domain.DisputeLost
+	return nil
+}
+
+func respondable(d domain.Dispute, now time.Time) error {
+	if d.Status != domain.DisputeNeedsResponse {
+		return domain.ErrDisputeState
+	}
+	if !now.Before(d.RespondBy) {
+		return domain.ErrDisputeDeadline
+	}
+
+	return nil
+}
+
+func newKey() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
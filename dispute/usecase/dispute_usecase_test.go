@@ -0,0 +1,209 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	disputeUsecase "github.com/hezbymuhammad/payment-gateway/dispute/usecase"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+)
+
+var cfg = disputeUsecase.Config{ResponseWindow: 7 * 24 * time.Hour}
+
+var captured = domain.Transaction{ID: 4, MerchantID: 6, Amount: 150000, Currency: "IDR", State: domain.TransactionCaptured}
+
+func open() domain.Dispute {
+	return domain.Dispute{
+		ID:            2,
+		TransactionID: 4,
+		MerchantID:    6,
+		ReasonCode:    "fraudulent",
+		Amount:        150000,
+		Currency:      "IDR",
+		Status:        domain.DisputeNeedsResponse,
+		RespondBy:     time.Now().Add(time.Hour),
+	}
+}
+
+func TestOpen(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(captured, nil).Once()
+	mockRepo.On("Store", mock.Anything, mock.AnythingOfType("*domain.Dispute")).Return(nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, mockTransactions, new(mocks.BlobStore), cfg)
+	d := domain.Dispute{TransactionID: 4, ReasonCode: "fraudulent"}
+
+	err := u.Open(context.TODO(), &d)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), d.MerchantID)
+	assert.Equal(t, int64(150000), d.Amount)
+	assert.Equal(t, domain.DisputeNeedsResponse, d.Status)
+	assert.WithinDuration(t, time.Now().Add(cfg.ResponseWindow), d.RespondBy, 2*time.Second)
+}
+
+func TestOpenOnUncapturedTransaction(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	authorized := captured
+	authorized.State = domain.TransactionAuthorized
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(authorized, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, mockTransactions, new(mocks.BlobStore), cfg)
+
+	err := u.Open(context.TODO(), &domain.Dispute{TransactionID: 4, ReasonCode: "fraudulent"})
+	assert.Equal(t, domain.ErrInvalidState, err)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestAddEvidence(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockBlobs := new(mocks.BlobStore)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	mockBlobs.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "disputes/2/")
+	}), mock.Anything).Return(int64(7), nil).Once()
+	mockRepo.On("StoreEvidence", mock.Anything, mock.AnythingOfType("*domain.DisputeEvidence")).Return(nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), mockBlobs, cfg)
+	e := domain.DisputeEvidence{DisputeID: 2, FileName: "receipt.txt", ContentType: "text/plain"}
+
+	err := u.AddEvidence(context.TODO(), 6, &e, strings.NewReader("receipt"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), e.Size)
+}
+
+func TestAddEvidenceAfterDeadline(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockBlobs := new(mocks.BlobStore)
+	d := open()
+	d.RespondBy = time.Now().Add(-time.Minute)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(d, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), mockBlobs, cfg)
+
+	err := u.AddEvidence(context.TODO(), 6, &domain.DisputeEvidence{DisputeID: 2}, strings.NewReader("receipt"))
+	assert.Equal(t, domain.ErrDisputeDeadline, err)
+	mockBlobs.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddEvidenceForAnotherMerchant(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	err := u.AddEvidence(context.TODO(), 7, &domain.DisputeEvidence{DisputeID: 2}, strings.NewReader("receipt"))
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestContestWithoutEvidence(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	mockRepo.On("FetchEvidence", mock.Anything, int64(2)).Return([]domain.DisputeEvidence{}, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	_, err := u.Contest(context.TODO(), 6, 2)
+	assert.Equal(t, domain.ErrNoEvidence, err)
+	mockRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContest(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	mockRepo.On("FetchEvidence", mock.Anything, int64(2)).Return([]domain.DisputeEvidence{{ID: 1, DisputeID: 2}}, nil).Once()
+	mockRepo.On("Transition", mock.Anything, int64(2), domain.DisputeNeedsResponse, domain.DisputeUnderReview).Return(true, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	res, err := u.Contest(context.TODO(), 6, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeUnderReview, res.Status)
+}
+
+func TestAccept(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	mockRepo.On("Lose", mock.Anything, int64(2), domain.DisputeNeedsResponse, mock.MatchedBy(func(e *domain.LedgerEntry) bool {
+		return e.MerchantID == 6 && e.TransactionID == 4 && e.Amount == -150000 && e.Type == domain.LedgerChargeback && e.Reference == "dispute:2"
+	})).Return(true, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	res, err := u.Accept(context.TODO(), 6, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeLost, res.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAcceptAlreadyDecided(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(open(), nil).Once()
+	mockRepo.On("Lose", mock.Anything, int64(2), domain.DisputeNeedsResponse, mock.Anything).Return(false, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	_, err := u.Accept(context.TODO(), 6, 2)
+	assert.Equal(t, domain.ErrDisputeState, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveLostInForeignCurrency(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	d := open()
+	d.Status = domain.DisputeUnderReview
+	d.Currency = "USD"
+	d.Amount = 1000
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(d, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, Currency: "USD", FXRate: "16000", FXMarkup: 200}, nil).Once()
+	mockRepo.On("Lose", mock.Anything, int64(2), domain.DisputeUnderReview, mock.MatchedBy(func(e *domain.LedgerEntry) bool {
+		return e.Amount == -156800 && e.Currency == "IDR"
+	})).Return(true, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, mockTransactions, new(mocks.BlobStore), cfg)
+
+	res, err := u.Resolve(context.TODO(), 2, false)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeLost, res.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveLostWithoutRate(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	d := open()
+	d.Status = domain.DisputeUnderReview
+	d.Currency = "USD"
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(d, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{}, fmt.Errorf("some error")).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, mockTransactions, new(mocks.BlobStore), cfg)
+
+	_, err := u.Resolve(context.TODO(), 2, false)
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Lose", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResolveWon(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	d := open()
+	d.Status = domain.DisputeUnderReview
+	mockRepo.On("GetByID", mock.Anything, int64(2)).Return(d, nil).Once()
+	mockRepo.On("Transition", mock.Anything, int64(2), domain.DisputeUnderReview, domain.DisputeWon).Return(true, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	res, err := u.Resolve(context.TODO(), 2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DisputeWon, res.Status)
+	mockRepo.AssertNotCalled(t, "Lose", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunDeadlines(t *testing.T) {
+	mockRepo := new(mocks.DisputeRepository)
+	now := time.Now()
+	mockRepo.On("FetchOverdue", mock.Anything, now.UTC()).Return([]domain.Dispute{open()}, nil).Once()
+	mockRepo.On("Lose", mock.Anything, int64(2), domain.DisputeNeedsResponse, mock.Anything).Return(true, nil).Once()
+	u := disputeUsecase.NewDisputeUsecase(mockRepo, new(mocks.TransactionUsecase), new(mocks.BlobStore), cfg)
+
+	err := u.RunDeadlines(context.TODO(), now)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditMerchant         = "merchant"
+	AuditMerchantDocument = "merchant_document"
+	AuditMerchantGroup    = "merchant_group"
+	AuditSetting          = "setting"
+	AuditTransaction      = "transaction"
+)
+
+// AuditEntry records one change made to a merchant, its settings or a
+// transaction: who made it, from where, and the fields it changed. Before
+// and After hold the changed fields' JSON values, Before being null when
+// the entity was created. ClaimedActor is who the caller said it was, kept
+// apart from Actor because nothing has verified it.
+//
+// Entries form a hash chain. Hash covers the entry and PrevHash, the hash
+// of the entry before it, so that an entry edited or removed after the fact
+// breaks the chain from there on.
+type AuditEntry struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	ClaimedActor string          `json:"claimedActor,omitempty"`
+	MerchantID   int64           `json:"merchantId"`
+	Action       string          `json:"action"`
+	Entity       string          `json:"entity"`
+	EntityID     int64           `json:"entityId"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	RequestID    string          `json:"requestId"`
+	SourceIP     string          `json:"sourceIp"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	PrevHash     string          `json:"prevHash"`
+	Hash         string          `json:"hash"`
+}
+
+// AuditFilter narrows a query of the audit log. Zero fields match
+// anything; From is inclusive and To exclusive.
+type AuditFilter struct {
+	Entity   string
+	EntityID int64
+	From     time.Time
+	To       time.Time
+}
+
+// AuditVerification is the result of checking the audit log's hash chain.
+// BrokenAt is the first entry that does not match its hash or does not
+// follow the one before it.
+type AuditVerification struct {
+	Valid    bool  `json:"valid"`
+	Entries  int   `json:"entries"`
+	BrokenAt int64 `json:"brokenAt,omitempty"`
+}
+
+type AuditUsecase interface {
+	Record(ctx context.Context, e *AuditEntry) error
+	Fetch(ctx context.Context, f AuditFilter) ([]AuditEntry, error)
+	Verify(ctx context.Context) (AuditVerification, error)
+}
+
+// AuditRepository only ever appends. Append fails with ErrAuditChain if the
+// entry does not follow the last one.
+type AuditRepository interface {
+	Append(ctx context.Context, e *AuditEntry) error
+	Last(ctx context.Context) (AuditEntry, error)
+	Fetch(ctx context.Context, f AuditFilter) ([]AuditEntry, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+)
+
+// Card is the vault's view of a card. Number is only ever set on the way in
+// to Tokenize and on the way out of Detokenize. Fingerprint is a keyed hash
+// of the number: every token for the same card shares it, whichever
+// merchant vaulted it, so it is never sent out.
+type Card struct {
+	Token       string `json:"token"`
+	MerchantID  int64  `json:"merchantId"`
+	Number      string `json:"number,omitempty"`
+	ExpiryMonth int    `json:"expiryMonth"`
+	ExpiryYear  int    `json:"expiryYear"`
+	BIN         string `json:"bin"`
+	Last4       string `json:"last4"`
+	Brand       string `json:"brand"`
+	Fingerprint string `json:"-"`
+}
+
+// VaultedCard is a card as persisted: the PAN is encrypted with a per-card
+// data key, which is itself encrypted with the vault key from config.
+type VaultedCard struct {
+	Card
+	EncryptedKey []byte
+	Ciphertext   []byte
+}
+
+type CardVaultUsecase interface {
+	Tokenize(ctx context.Context, c *Card) error
+	GetByToken(ctx context.Context, merchantID int64, token string) (Card, error)
+	Delete(ctx context.Context, merchantID int64, token string) error
+}
+
+// CardDetokenizer is handed to processor adapters only; nothing else gets to
+// see a PAN once it is in the vault.
+type CardDetokenizer interface {
+	Detokenize(ctx context.Context, token string) (Card, error)
+}
+
+type CardVaultRepository interface {
+	Store(ctx context.Context, c *VaultedCard) error
+	GetByToken(ctx context.Context, token string) (VaultedCard, error)
+	Delete(ctx context.Context, token string) error
+	FetchWithoutFingerprint(ctx context.Context) ([]VaultedCard, error)
+	SetFingerprint(ctx context.Context, token string, fingerprint string) error
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	CheckoutOpen       = "open"
+	CheckoutProcessing = "processing"
+	CheckoutComplete   = "complete"
+	CheckoutExpired    = "expired"
+	CheckoutCanceled   = "canceled"
+)
+
+// CheckoutSession is a single hosted payment page. It can be completed once;
+// after ExpiresAt it can no longer be paid.
+type CheckoutSession struct {
+	ID            string    `json:"id"`
+	MerchantID    int64     `json:"merchantId"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	SuccessURL    string    `json:"successUrl"`
+	CancelURL     string    `json:"cancelUrl"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	TransactionID int64     `json:"transactionId"`
+	URL           string    `json:"url,omitempty"`
+}
+
+// CheckoutPage is everything the hosted page shows: the session, the
+// merchant's branding and the payment methods the merchant has enabled.
+type CheckoutPage struct {
+	Session  CheckoutSession
+	Merchant Merchant
+	Setting  Setting
+	Methods  []Setting
+}
+
+// CheckoutPayment is what the customer submits on the hosted page.
+type CheckoutPayment struct {
+	SettingID int64
+	Card      Card
+}
+
+type CheckoutUsecase interface {
+	Create(ctx context.Context, s *CheckoutSession) error
+	GetPage(ctx context.Context, id string) (CheckoutPage, error)
+	Complete(ctx context.Context, id string, p *CheckoutPayment) (CheckoutSession, Transaction, error)
+	Cancel(ctx context.Context, id string) (CheckoutSession, error)
+	RunSchedule(ctx context.Context, now time.Time) error
+}
+
+type CheckoutRepository interface {
+	Store(ctx context.Context, s *CheckoutSession) error
+	GetByID(ctx context.Context, id string) (CheckoutSession, error)
+	Claim(ctx context.Context, id string, now time.Time) (bool, error)
+	Update(ctx context.Context, s *CheckoutSession, from string) error
+	FetchProcessing(ctx context.Context) ([]CheckoutSession, error)
+}
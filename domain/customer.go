@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+)
+
+// Customer belongs to the merchant that created it. Children of that merchant
+// can read and charge it when their merchant group shares customers.
+type Customer struct {
+	ID         int64  `json:"id"`
+	MerchantID int64  `json:"merchantId"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+}
+
+type PaymentMethod struct {
+	ID         int64  `json:"id"`
+	CustomerID int64  `json:"customerId"`
+	MerchantID int64  `json:"merchantId"`
+	Type       string `json:"type"`
+	CardToken  string `json:"cardToken"`
+	Last4      string `json:"last4"`
+	Brand      string `json:"brand"`
+}
+
+type CustomerUsecase interface {
+	Store(ctx context.Context, c *Customer) error
+	Fetch(ctx context.Context, merchantID int64) ([]Customer, error)
+	GetByID(ctx context.Context, merchantID int64, id int64) (Customer, error)
+	Update(ctx context.Context, c *Customer) error
+	Delete(ctx context.Context, merchantID int64, id int64) error
+	AddPaymentMethod(ctx context.Context, merchantID int64, pm *PaymentMethod) error
+	FetchPaymentMethods(ctx context.Context, merchantID int64, customerID int64) ([]PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, merchantID int64, id int64) (PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, merchantID int64, id int64) error
+}
+
+type CustomerRepository interface {
+	Store(ctx context.Context, c *Customer) error
+	FetchByMerchant(ctx context.Context, merchantID int64) ([]Customer, error)
+	GetByID(ctx context.Context, id int64) (Customer, error)
+	Update(ctx context.Context, c *Customer) error
+	Delete(ctx context.Context, id int64) error
+	StorePaymentMethod(ctx context.Context, pm *PaymentMethod) error
+	FetchPaymentMethods(ctx context.Context, customerID int64) ([]PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, id int64) (PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, id int64) error
+}
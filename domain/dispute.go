@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+const (
+	DisputeNeedsResponse = "needs_response"
+	DisputeUnderReview   = "under_review"
+	DisputeWon           = "won"
+	DisputeLost          = "lost"
+)
+
+// DisputeReasons are the reason codes a card network raises a dispute with.
+var DisputeReasons = map[string]string{
+	"fraudulent":            "Cardholder does not recognise the charge",
+	"duplicate":             "Cardholder was charged more than once",
+	"product_not_received":  "Goods or services were not delivered",
+	"product_unacceptable":  "Goods or services were defective or not as described",
+	"credit_not_processed":  "A promised refund was not received",
+	"subscription_canceled": "Charged after cancelling a subscription",
+	"general":               "Other",
+}
+
+// Dispute is a cardholder's challenge of a captured transaction. The merchant
+// has until RespondBy to accept it or contest it with evidence; one left
+// unanswered is lost.
+type Dispute struct {
+	ID            int64             `json:"id"`
+	TransactionID int64             `json:"transactionId"`
+	MerchantID    int64             `json:"merchantId"`
+	ReasonCode    string            `json:"reasonCode"`
+	Amount        int64             `json:"amount"`
+	Currency      string            `json:"currency"`
+	Status        string            `json:"status"`
+	RespondBy     time.Time         `json:"respondBy"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Evidence      []DisputeEvidence `json:"evidence"`
+}
+
+// DisputeEvidence is a file the merchant submitted for a dispute. The content
+// lives in blob storage under StorageKey.
+type DisputeEvidence struct {
+	ID          int64     `json:"id"`
+	DisputeID   int64     `json:"disputeId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// BlobStore keeps files by key. Keys are slash separated paths.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type DisputeUsecase interface {
+	Open(ctx context.Context, d *Dispute) error
+	GetByID(ctx context.Context, merchantID int64, id int64) (Dispute, error)
+	AddEvidence(ctx context.Context, merchantID int64, e *DisputeEvidence, r io.Reader) error
+	GetEvidence(ctx context.Context, merchantID int64, disputeID int64, id int64) (DisputeEvidence, io.ReadCloser, error)
+	Accept(ctx context.Context, merchantID int64, id int64) (Dispute, error)
+	Contest(ctx context.Context, merchantID int64, id int64) (Dispute, error)
+	Resolve(ctx context.Context, id int64, won bool) (Dispute, error)
+	RunDeadlines(ctx context.Context, now time.Time) error
+}
+
+type DisputeRepository interface {
+	Store(ctx context.Context, d *Dispute) error
+	GetByID(ctx context.Context, id int64) (Dispute, error)
+	FetchOverdue(ctx context.Context, now time.Time) ([]Dispute, error)
+	Transition(ctx context.Context, id int64, from string, to string) (bool, error)
+	Lose(ctx context.Context, id int64, from string, e *LedgerEntry) (bool, error)
+	StoreEvidence(ctx context.Context, e *DisputeEvidence) error
+	FetchEvidence(ctx context.Context, disputeID int64) ([]DisputeEvidence, error)
+}
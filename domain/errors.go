@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+)
+
+var (
+	ErrUnauthorized     = errors.New("Unauthorized")
+	ErrNotFound         = errors.New("Not found")
+	ErrInvalidState     = errors.New("Invalid transaction state")
+	ErrProcessorTimeout = errors.New("Processor timeout")
+	ErrInvalidCard      = errors.New("Invalid card")
+	ErrCardExpired      = errors.New("Card expired")
+	ErrUnknownBrand     = errors.New("Unsupported card brand")
+	ErrInvalidCustomer  = errors.New("Invalid customer")
+	ErrInactive         = errors.New("Subscription is not active")
+	ErrInvoiceState     = errors.New("Invalid invoice state")
+	ErrSessionExpired   = errors.New("Checkout session expired")
+	ErrSessionClosed    = errors.New("Checkout session is no longer open")
+	ErrPaymentMethod    = errors.New("Payment method not available")
+	ErrInvalidQR        = errors.New("Invalid QR payload")
+	ErrAmountMismatch   = errors.New("Paid amount does not match")
+	ErrUnderpaid        = errors.New("Paid amount is less than the amount due")
+	ErrVAExpired        = errors.New("Virtual account expired")
+	ErrDuplicatePayment = errors.New("Payment already recorded")
+	ErrInvalidSignature = errors.New("Invalid signature")
+	ErrProvider         = errors.New("Payment provider unavailable")
+	ErrInstallmentPlan  = errors.New("Installment plan not available")
+	ErrPromotion        = errors.New("Promotion not applicable")
+	ErrPromotionQuota   = errors.New("Promotion quota exhausted")
+	ErrPromoCodeTaken   = errors.New("Promo code already in use")
+	ErrCurrency         = errors.New("Currency not supported")
+	ErrFXQuote          = errors.New("FX quote expired or does not match")
+	ErrDisputeState     = errors.New("Invalid dispute state")
+	ErrDisputeDeadline  = errors.New("Dispute response deadline has passed")
+	ErrNoEvidence       = errors.New("Dispute has no evidence")
+	ErrDisputed         = errors.New("Transaction already has a dispute")
+	ErrLimitExceeded    = errors.New("Transaction limit exceeded")
+	ErrMerchantState    = errors.New("Invalid merchant state")
+	ErrMerchantInactive = errors.New("Merchant is not approved to take payments")
+	ErrIncompleteKYC    = errors.New("Merchant details are incomplete")
+	ErrStaleVersion     = errors.New("Resource was changed since it was fetched, refetch it and retry")
+	ErrImmutableField   = errors.New("Field cannot be changed")
+	ErrUnknownField     = errors.New("Unknown field")
+	ErrReferenceTaken   = errors.New("Merchant reference already in use")
+	ErrReference        = errors.New("Merchant reference is too long")
+	ErrMetadataLimit    = errors.New("Metadata has too many keys or a key or value is too long")
+	ErrAuditChain       = errors.New("Audit entry does not follow the last one")
+)
+
+// FieldError is returned for a request that sets a field it may not. It
+// names the field.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error() + ": " + e.Field
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EWalletPending = "pending"
+	EWalletPaid    = "paid"
+	EWalletFailed  = "failed"
+)
+
+// EWalletPayment is a payment opened with an e-wallet provider for one
+// transaction. The customer finishes it on the provider's side through the
+// checkout URL or the app deeplink.
+type EWalletPayment struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transactionId"`
+	Provider      string    `json:"provider"`
+	Reference     string    `json:"reference"`
+	CheckoutURL   string    `json:"checkoutUrl"`
+	Deeplink      string    `json:"deeplink"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// EWalletCharge is what we ask a provider to collect. PartnerReference is our
+// id for the payment and comes back in callbacks and status checks.
+type EWalletCharge struct {
+	PartnerReference string
+	Amount           int64
+	Currency         string
+}
+
+// EWalletStatus is a provider's view of a payment, either pushed in a callback
+// or pulled by polling. Status is one of the EWallet constants.
+type EWalletStatus struct {
+	Reference        string
+	PartnerReference string
+	Status           string
+	Amount           int64
+}
+
+// EWalletProvider adapts one e-wallet's API. ParseCallback authenticates a
+// callback body with its signature before decoding it.
+type EWalletProvider interface {
+	Name() string
+	Charge(ctx context.Context, c *EWalletCharge) (EWalletPayment, error)
+	Status(ctx context.Context, reference string) (EWalletStatus, error)
+	ParseCallback(body []byte, signature string) (EWalletStatus, error)
+}
+
+type EWalletUsecase interface {
+	GetByTransaction(ctx context.Context, merchantID int64, transactionID int64) (EWalletPayment, error)
+	Callback(ctx context.Context, provider string, body []byte, signature string) (Transaction, error)
+	RunPolling(ctx context.Context, now time.Time) error
+}
+
+type EWalletRepository interface {
+	Store(ctx context.Context, p *EWalletPayment) error
+	GetByTransaction(ctx context.Context, transactionID int64) (EWalletPayment, error)
+	Update(ctx context.Context, p *EWalletPayment) error
+	FetchPending(ctx context.Context, before time.Time) ([]EWalletPayment, error)
+}
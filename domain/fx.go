@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SettlementCurrency is what merchants are paid out in.
+const SettlementCurrency = "IDR"
+
+// FXRate is the mid rate of one unit of Currency in the settlement currency,
+// kept as a decimal string so it is never rounded.
+type FXRate struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// FXQuote locks a rate and markup for a payment of Amount, in the minor unit
+// of Currency, until ExpiresAt. Markup is in basis points and comes off what
+// the merchant settles.
+type FXQuote struct {
+	ID                 int64     `json:"id"`
+	MerchantID         int64     `json:"merchantId"`
+	Currency           string    `json:"currency"`
+	Amount             int64     `json:"amount"`
+	SettlementCurrency string    `json:"settlementCurrency"`
+	Rate               string    `json:"rate"`
+	Markup             int64     `json:"markup"`
+	SettlementAmount   int64     `json:"settlementAmount"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+}
+
+type FXUsecase interface {
+	StoreRates(ctx context.Context, rates []FXRate) error
+	FetchRates(ctx context.Context) ([]FXRate, error)
+	Quote(ctx context.Context, q *FXQuote) error
+	Lock(ctx context.Context, t *Transaction) error
+}
+
+type FXRepository interface {
+	StoreRate(ctx context.Context, r *FXRate) error
+	GetRate(ctx context.Context, currency string) (FXRate, error)
+	FetchRates(ctx context.Context) ([]FXRate, error)
+	StoreQuote(ctx context.Context, q *FXQuote) error
+	GetQuote(ctx context.Context, id int64) (FXQuote, error)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+)
+
+// InstallmentPlan lets a card setting split payments over Tenor months.
+// CustomerRate is the interest the customer pays on top of the amount and
+// MerchantRate the subsidy the merchant pays out of it, both in basis points
+// of the amount over the whole tenor. BINs limits the plan to cards from
+// participating issuers; an empty list accepts any card.
+type InstallmentPlan struct {
+	ID           int64    `json:"id"`
+	MerchantID   int64    `json:"merchantId"`
+	SettingID    int64    `json:"settingId"`
+	Tenor        int      `json:"tenor"`
+	CustomerRate int64    `json:"customerRate"`
+	MerchantRate int64    `json:"merchantRate"`
+	MinAmount    int64    `json:"minAmount"`
+	BINs         []string `json:"bins"`
+}
+
+// InstallmentQuote is what a plan costs for a given amount.
+type InstallmentQuote struct {
+	PlanID      int64 `json:"planId"`
+	Tenor       int   `json:"tenor"`
+	Interest    int64 `json:"interest"`
+	Total       int64 `json:"total"`
+	Monthly     int64 `json:"monthly"`
+	MerchantFee int64 `json:"merchantFee"`
+}
+
+type InstallmentUsecase interface {
+	StorePlan(ctx context.Context, p *InstallmentPlan) error
+	FetchPlans(ctx context.Context, merchantID int64, settingID int64) ([]InstallmentPlan, error)
+	Quote(ctx context.Context, merchantID int64, settingID int64, amount int64, bin string) ([]InstallmentQuote, error)
+	Apply(ctx context.Context, t *Transaction, bin string) error
+}
+
+type InstallmentRepository interface {
+	StorePlan(ctx context.Context, p *InstallmentPlan) error
+	GetPlan(ctx context.Context, id int64) (InstallmentPlan, error)
+	FetchPlans(ctx context.Context, settingID int64) ([]InstallmentPlan, error)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	InvoiceDraft   = "draft"
+	InvoiceOpen    = "open"
+	InvoicePaid    = "paid"
+	InvoiceVoid    = "void"
+	InvoiceOverdue = "overdue"
+	// InvoiceProcessing is an invoice a payment has been started for. It
+	// cannot be paid again until that payment is captured or fails.
+	InvoiceProcessing = "processing"
+)
+
+// Invoice amounts are in minor units. TaxRate is in basis points, so 1100 is
+// 11%. Subtotal, Tax and Total are computed from the line items.
+type Invoice struct {
+	ID            int64             `json:"id"`
+	MerchantID    int64             `json:"merchantId"`
+	SettingID     int64             `json:"settingId"`
+	CustomerID    int64             `json:"customerId"`
+	Status        string            `json:"status"`
+	Currency      string            `json:"currency"`
+	LineItems     []InvoiceLineItem `json:"lineItems"`
+	TaxRate       int64             `json:"taxRate"`
+	Subtotal      int64             `json:"subtotal"`
+	Tax           int64             `json:"tax"`
+	Total         int64             `json:"total"`
+	DueDate       time.Time         `json:"dueDate"`
+	LinkToken     string            `json:"-"`
+	PaymentLink   string            `json:"paymentLink,omitempty"`
+	TransactionID int64             `json:"transactionId"`
+}
+
+type InvoiceLineItem struct {
+	ID          int64  `json:"id"`
+	InvoiceID   int64  `json:"invoiceId"`
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unitPrice"`
+	Amount      int64  `json:"amount"`
+}
+
+type InvoiceReminder struct {
+	ID        int64     `json:"id"`
+	InvoiceID int64     `json:"invoiceId"`
+	RemindAt  time.Time `json:"remindAt"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// InvoicePage is what a payment link shows: the invoice and the branding of
+// the merchant that issued it.
+type InvoicePage struct {
+	Invoice  Invoice
+	Merchant Merchant
+	Setting  Setting
+}
+
+type InvoiceUsecase interface {
+	Store(ctx context.Context, i *Invoice) error
+	Fetch(ctx context.Context, merchantID int64) ([]Invoice, error)
+	GetByID(ctx context.Context, merchantID int64, id int64) (Invoice, error)
+	Finalize(ctx context.Context, merchantID int64, id int64) (Invoice, error)
+	Void(ctx context.Context, merchantID int64, id int64) (Invoice, error)
+	GetPage(ctx context.Context, token string) (InvoicePage, error)
+	Pay(ctx context.Context, token string, t *Transaction) error
+	RunSchedule(ctx context.Context, now time.Time) error
+}
+
+type InvoiceRepository interface {
+	Store(ctx context.Context, i *Invoice) error
+	FetchByMerchant(ctx context.Context, merchantID int64) ([]Invoice, error)
+	GetByID(ctx context.Context, id int64) (Invoice, error)
+	GetByLinkToken(ctx context.Context, token string) (Invoice, error)
+	Claim(ctx context.Context, id int64) (bool, error)
+	Update(ctx context.Context, i *Invoice, from string) error
+	FetchProcessing(ctx context.Context) ([]Invoice, error)
+	MarkOverdue(ctx context.Context, now time.Time) (int64, error)
+	StoreReminders(ctx context.Context, reminders []InvoiceReminder) error
+	FetchDueReminders(ctx context.Context, now time.Time) ([]InvoiceReminder, error)
+	MarkReminderSent(ctx context.Context, r *InvoiceReminder) error
+}
+
+// InvoiceNotifier delivers invoice reminders to the customer.
+type InvoiceNotifier interface {
+	SendReminder(ctx context.Context, i Invoice) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	LedgerChargeback = "chargeback"
+)
+
+// LedgerEntry is an adjustment to what a merchant is owed, in the settlement
+// currency. Debits are negative. Reference names what caused the entry and is
+// unique per type, so an adjustment is never booked twice.
+type LedgerEntry struct {
+	ID            int64     `json:"id"`
+	MerchantID    int64     `json:"merchantId"`
+	TransactionID int64     `json:"transactionId"`
+	Type          string    `json:"type"`
+	Reference     string    `json:"reference"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	LimitMaxAmount     = "max_amount"
+	LimitDailyVolume   = "daily_volume"
+	LimitMonthlyVolume = "monthly_volume"
+)
+
+// Limit caps what a merchant can charge, in rupiah. A merchant has at most
+// one limit per payment type; one with an empty PaymentType covers all
+// payment types. Zero means no cap. Limits are inherited down merchant
+// groups: a child merchant is held to its own limits and to those of every
+// merchant above it, each measured against the child's own volume.
+type Limit struct {
+	MerchantID    int64     `json:"merchantId"`
+	PaymentType   string    `json:"paymentType"`
+	MaxAmount     int64     `json:"maxAmount"`
+	DailyVolume   int64     `json:"dailyVolume"`
+	MonthlyVolume int64     `json:"monthlyVolume"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// LimitStatus is how much of one cap a merchant has left.
+type LimitStatus struct {
+	Limit       string `json:"limit"`
+	PaymentType string `json:"paymentType"`
+	Cap         int64  `json:"cap"`
+	Used        int64  `json:"used"`
+	Remaining   int64  `json:"remaining"`
+}
+
+// LimitError is returned for a transaction that would go over a limit. It
+// says which cap was hit and how much of it is left.
+type LimitError struct {
+	LimitStatus
+}
+
+func (e *LimitError) Error() string {
+	return ErrLimitExceeded.Error() + ": " + e.Limit + " remaining " + strconv.FormatInt(e.Remaining, 10)
+}
+
+type LimitUsecase interface {
+	Store(ctx context.Context, l *Limit) error
+	Fetch(ctx context.Context, merchantID int64) ([]Limit, error)
+	Status(ctx context.Context, merchantID int64) ([]LimitStatus, error)
+	Reserve(ctx context.Context, t *Transaction) error
+	Release(ctx context.Context, t *Transaction) error
+}
+
+type LimitRepository interface {
+	Upsert(ctx context.Context, l *Limit) error
+	Fetch(ctx context.Context, merchantID int64) ([]Limit, error)
+	FetchEffective(ctx context.Context, merchantID int64) ([]Limit, error)
+	Usage(ctx context.Context, merchantID int64, paymentType string, period string) (int64, error)
+	Reserve(ctx context.Context, merchantID int64, paymentType string, period string, amount int64, cap int64) (bool, error)
+	Release(ctx context.Context, merchantID int64, paymentType string, period string, amount int64) error
+}
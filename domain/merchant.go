@@ -2,26 +2,153 @@ package domain
 
 import (
 	"context"
+	"io"
+	"time"
 )
 
+const (
+	MerchantDraft         = "draft"
+	MerchantSubmitted     = "submitted"
+	MerchantPendingReview = "pending_review"
+	MerchantApproved      = "approved"
+	MerchantRejected      = "rejected"
+)
+
+// Merchant is a business taking payments through the gateway. It starts out
+// as a draft while its KYC details and documents are collected, then is
+// submitted for review. Submitting screens its name and owners against
+// sanctions lists and blocklists, and a hit holds it in pending_review. A
+// reviewer approves or rejects it, and only an approved merchant can take
+// payments. A later list update can hold an approved merchant again.
+//
+// Version counts the merchant's updates. Like those of transactions and
+// settings, an update is only written over the version it was made from,
+// and fails with ErrStaleVersion otherwise.
 type Merchant struct {
+	ID                 int64       `json:"id"`
+	Name               string      `json:"name"`
+	LegalName          string      `json:"legalName"`
+	EntityType         string      `json:"entityType"`
+	RegistrationNumber string      `json:"registrationNumber"`
+	TaxID              string      `json:"taxId"`
+	Address            string      `json:"address"`
+	BankAccount        BankAccount `json:"bankAccount"`
+	Owners             []Owner     `json:"owners"`
+	Status             string      `json:"status"`
+	ReviewNotes        string      `json:"reviewNotes"`
+	ScreeningResult    string      `json:"screeningResult"`
+	ScreeningScore     int         `json:"screeningScore"`
+	Version            int64       `json:"version"`
+}
+
+// BankAccount is where the merchant's settlements are paid out.
+type BankAccount struct {
+	BankCode      string `json:"bankCode"`
+	AccountNumber string `json:"accountNumber"`
+	AccountName   string `json:"accountName"`
+}
+
+// Owner is a beneficial owner of a merchant. IDNumber is their national ID
+// (NIK) or passport number and Share their stake in percent.
+type Owner struct {
+	Name     string `json:"name"`
+	IDNumber string `json:"idNumber"`
+	Share    int    `json:"share"`
+}
+
+// MerchantDocument is a KYC document the merchant uploaded. The content
+// lives in blob storage under StorageKey.
+type MerchantDocument struct {
+	ID          int64     `json:"id"`
+	MerchantID  int64     `json:"merchantId"`
+	Type        string    `json:"type"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// MerchantEntityTypes are the kinds of business that can onboard.
+var MerchantEntityTypes = map[string]bool{
+	"individual": true,
+	"pt":         true,
+	"cv":         true,
+	"firma":      true,
+	"koperasi":   true,
+	"yayasan":    true,
+}
+
+// MerchantDocumentTypes are the KYC documents a merchant can upload. The
+// required ones must all be there before it can be submitted.
+var MerchantDocumentTypes = map[string]bool{
+	"id_card":          true,
+	"tax_card":         true,
+	"bank_statement":   true,
+	"deed":             true,
+	"business_license": true,
+}
+
+var RequiredMerchantDocuments = []string{"id_card", "tax_card", "bank_statement"}
+
+type Setting struct {
 	ID          int64      `json:"id"`
-	Name        string     `json:"name"`
+	MerchantID  int64      `json:"merchantId"`
+	Color       string     `json:"color"`
+	PaymentType string     `json:"paymentType"`
+	PaymentName string     `json:"paymentName"`
+	QR          *QRAccount `json:"qr,omitempty"`
+	Version     int64      `json:"version"`
+}
+
+// QRAccount is the merchant account a QR setting presents in its QRIS
+// payloads. NMID is the national merchant ID assigned by the QRIS switch.
+type QRAccount struct {
+	Acquirer    string     `json:"acquirer"`
+	MerchantPAN string     `json:"merchantPan"`
+	NMID        string     `json:"nmid"`
+	Criteria    string     `json:"criteria"`
+	MCC         string     `json:"mcc"`
+	City        string     `json:"city"`
+	PostalCode  string     `json:"postalCode"`
 }
 
 type MerchantGroup struct {
 	ParentMerchantID         int64      `json:"parentMerchantId"`
 	ChildMerchantID          int64      `json:"childMerchantId"`
+	ShareCustomers           bool       `json:"shareCustomers"`
 }
 
 type MerchantUsecase interface {
         Store(ctx context.Context, m *Merchant) error
+        GetByID(ctx context.Context, id int64) (Merchant, error)
+        Update(ctx context.Context, m *Merchant) error
+        AddDocument(ctx context.Context, d *MerchantDocument, r io.Reader) error
+        FetchDocuments(ctx context.Context, merchantID int64) ([]MerchantDocument, error)
+        GetDocument(ctx context.Context, merchantID int64, id int64) (MerchantDocument, io.ReadCloser, error)
+        Submit(ctx context.Context, id int64) (Merchant, error)
+        Approve(ctx context.Context, id int64, notes string) (Merchant, error)
+        Reject(ctx context.Context, id int64, notes string) (Merchant, error)
         SetChild(ctx context.Context, mg *MerchantGroup) error
+        StoreSetting(ctx context.Context, s *Setting) error
+        GetSetting(ctx context.Context, id int64) (Setting, error)
+        UpdateSetting(ctx context.Context, s *Setting) error
 }
 
 type MerchantRepository interface {
         Store(ctx context.Context, m *Merchant) error
+        GetByID(ctx context.Context, id int64) (Merchant, error)
+        Fetch(ctx context.Context) ([]Merchant, error)
+        Update(ctx context.Context, m *Merchant) error
+        StoreDocument(ctx context.Context, d *MerchantDocument) error
+        FetchDocuments(ctx context.Context, merchantID int64) ([]MerchantDocument, error)
+        GetSetting(ctx context.Context, id int64) (Setting, error)
+        FetchSettings(ctx context.Context, merchantID int64) ([]Setting, error)
+        GetSettingByNMID(ctx context.Context, nmid string) (Setting, error)
+        StoreSetting(ctx context.Context, s *Setting) error
+        UpdateSetting(ctx context.Context, s *Setting) error
         InitSetting(ctx context.Context, m *Merchant) error
         SetChild(ctx context.Context, mg *MerchantGroup) error
         IsAuthorizedParent(ctx context.Context, mg *MerchantGroup) (bool, error)
+        CanShareCustomers(ctx context.Context, mg *MerchantGroup) (bool, error)
 }
@@ -0,0 +1,73 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditRepository is an autogenerated mock type for the AuditRepository type
+type AuditRepository struct {
+	mock.Mock
+}
+
+// Append provides a mock function with given fields: ctx, e
+func (_m *AuditRepository) Append(ctx context.Context, e *domain.AuditEntry) error {
+	ret := _m.Called(ctx, e)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuditEntry) error); ok {
+		r0 = rf(ctx, e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Fetch provides a mock function with given fields: ctx, f
+func (_m *AuditRepository) Fetch(ctx context.Context, f domain.AuditFilter) ([]domain.AuditEntry, error) {
+	ret := _m.Called(ctx, f)
+
+	var r0 []domain.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context, domain.AuditFilter) []domain.AuditEntry); ok {
+		r0 = rf(ctx, f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AuditEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.AuditFilter) error); ok {
+		r1 = rf(ctx, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Last provides a mock function with given fields: ctx
+func (_m *AuditRepository) Last(ctx context.Context) (domain.AuditEntry, error) {
+	ret := _m.Called(ctx)
+
+	var r0 domain.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context) domain.AuditEntry); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.AuditEntry)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,36 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CardDetokenizer is an autogenerated mock type for the CardDetokenizer type
+type CardDetokenizer struct {
+	mock.Mock
+}
+
+// Detokenize provides a mock function with given fields: ctx, token
+func (_m *CardDetokenizer) Detokenize(ctx context.Context, token string) (domain.Card, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 domain.Card
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Card); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(domain.Card)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
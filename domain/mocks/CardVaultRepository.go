@@ -0,0 +1,101 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CardVaultRepository is an autogenerated mock type for the CardVaultRepository type
+type CardVaultRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, token
+func (_m *CardVaultRepository) Delete(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchWithoutFingerprint provides a mock function with given fields: ctx
+func (_m *CardVaultRepository) FetchWithoutFingerprint(ctx context.Context) ([]domain.VaultedCard, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.VaultedCard
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.VaultedCard); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.VaultedCard)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByToken provides a mock function with given fields: ctx, token
+func (_m *CardVaultRepository) GetByToken(ctx context.Context, token string) (domain.VaultedCard, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 domain.VaultedCard
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.VaultedCard); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(domain.VaultedCard)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFingerprint provides a mock function with given fields: ctx, token, fingerprint
+func (_m *CardVaultRepository) SetFingerprint(ctx context.Context, token string, fingerprint string) error {
+	ret := _m.Called(ctx, token, fingerprint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, fingerprint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, c
+func (_m *CardVaultRepository) Store(ctx context.Context, c *domain.VaultedCard) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VaultedCard) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,64 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CardVaultUsecase is an autogenerated mock type for the CardVaultUsecase type
+type CardVaultUsecase struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, merchantID, token
+func (_m *CardVaultUsecase) Delete(ctx context.Context, merchantID int64, token string) error {
+	ret := _m.Called(ctx, merchantID, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, merchantID, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByToken provides a mock function with given fields: ctx, merchantID, token
+func (_m *CardVaultUsecase) GetByToken(ctx context.Context, merchantID int64, token string) (domain.Card, error) {
+	ret := _m.Called(ctx, merchantID, token)
+
+	var r0 domain.Card
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Card); ok {
+		r0 = rf(ctx, merchantID, token)
+	} else {
+		r0 = ret.Get(0).(domain.Card)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Tokenize provides a mock function with given fields: ctx, c
+func (_m *CardVaultUsecase) Tokenize(ctx context.Context, c *domain.Card) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Card) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
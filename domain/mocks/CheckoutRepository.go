@@ -0,0 +1,109 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CheckoutRepository is an autogenerated mock type for the CheckoutRepository type
+type CheckoutRepository struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, id, now
+func (_m *CheckoutRepository) Claim(ctx context.Context, id string, now time.Time) (bool, error) {
+	ret := _m.Called(ctx, id, now)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, id, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, id, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchProcessing provides a mock function with given fields: ctx
+func (_m *CheckoutRepository) FetchProcessing(ctx context.Context) ([]domain.CheckoutSession, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.CheckoutSession
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.CheckoutSession); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CheckoutSession)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *CheckoutRepository) GetByID(ctx context.Context, id string) (domain.CheckoutSession, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.CheckoutSession
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.CheckoutSession); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.CheckoutSession)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, s
+func (_m *CheckoutRepository) Store(ctx context.Context, s *domain.CheckoutSession) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CheckoutSession) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, s, from
+func (_m *CheckoutRepository) Update(ctx context.Context, s *domain.CheckoutSession, from string) error {
+	ret := _m.Called(ctx, s, from)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CheckoutSession, string) error); ok {
+		r0 = rf(ctx, s, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
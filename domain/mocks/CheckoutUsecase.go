@@ -0,0 +1,114 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CheckoutUsecase is an autogenerated mock type for the CheckoutUsecase type
+type CheckoutUsecase struct {
+	mock.Mock
+}
+
+// Cancel provides a mock function with given fields: ctx, id
+func (_m *CheckoutUsecase) Cancel(ctx context.Context, id string) (domain.CheckoutSession, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.CheckoutSession
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.CheckoutSession); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.CheckoutSession)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Complete provides a mock function with given fields: ctx, id, p
+func (_m *CheckoutUsecase) Complete(ctx context.Context, id string, p *domain.CheckoutPayment) (domain.CheckoutSession, domain.Transaction, error) {
+	ret := _m.Called(ctx, id, p)
+
+	var r0 domain.CheckoutSession
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.CheckoutPayment) domain.CheckoutSession); ok {
+		r0 = rf(ctx, id, p)
+	} else {
+		r0 = ret.Get(0).(domain.CheckoutSession)
+	}
+
+	var r1 domain.Transaction
+	if rf, ok := ret.Get(1).(func(context.Context, string, *domain.CheckoutPayment) domain.Transaction); ok {
+		r1 = rf(ctx, id, p)
+	} else {
+		r1 = ret.Get(1).(domain.Transaction)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, *domain.CheckoutPayment) error); ok {
+		r2 = rf(ctx, id, p)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Create provides a mock function with given fields: ctx, s
+func (_m *CheckoutUsecase) Create(ctx context.Context, s *domain.CheckoutSession) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.CheckoutSession) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPage provides a mock function with given fields: ctx, id
+func (_m *CheckoutUsecase) GetPage(ctx context.Context, id string) (domain.CheckoutPage, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.CheckoutPage
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.CheckoutPage); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.CheckoutPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunSchedule provides a mock function with given fields: ctx, now
+func (_m *CheckoutUsecase) RunSchedule(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
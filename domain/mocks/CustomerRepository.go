@@ -0,0 +1,173 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CustomerRepository is an autogenerated mock type for the CustomerRepository type
+type CustomerRepository struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *CustomerRepository) Delete(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePaymentMethod provides a mock function with given fields: ctx, id
+func (_m *CustomerRepository) DeletePaymentMethod(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchByMerchant provides a mock function with given fields: ctx, merchantID
+func (_m *CustomerRepository) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Customer, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Customer); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Customer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchPaymentMethods provides a mock function with given fields: ctx, customerID
+func (_m *CustomerRepository) FetchPaymentMethods(ctx context.Context, customerID int64) ([]domain.PaymentMethod, error) {
+	ret := _m.Called(ctx, customerID)
+
+	var r0 []domain.PaymentMethod
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.PaymentMethod); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PaymentMethod)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *CustomerRepository) GetByID(ctx context.Context, id int64) (domain.Customer, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Customer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Customer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentMethod provides a mock function with given fields: ctx, id
+func (_m *CustomerRepository) GetPaymentMethod(ctx context.Context, id int64) (domain.PaymentMethod, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.PaymentMethod
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.PaymentMethod); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.PaymentMethod)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, c
+func (_m *CustomerRepository) Store(ctx context.Context, c *domain.Customer) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Customer) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorePaymentMethod provides a mock function with given fields: ctx, pm
+func (_m *CustomerRepository) StorePaymentMethod(ctx context.Context, pm *domain.PaymentMethod) error {
+	ret := _m.Called(ctx, pm)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PaymentMethod) error); ok {
+		r0 = rf(ctx, pm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, c
+func (_m *CustomerRepository) Update(ctx context.Context, c *domain.Customer) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Customer) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
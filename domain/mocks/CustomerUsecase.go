@@ -0,0 +1,173 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CustomerUsecase is an autogenerated mock type for the CustomerUsecase type
+type CustomerUsecase struct {
+	mock.Mock
+}
+
+// AddPaymentMethod provides a mock function with given fields: ctx, merchantID, pm
+func (_m *CustomerUsecase) AddPaymentMethod(ctx context.Context, merchantID int64, pm *domain.PaymentMethod) error {
+	ret := _m.Called(ctx, merchantID, pm)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *domain.PaymentMethod) error); ok {
+		r0 = rf(ctx, merchantID, pm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, merchantID, id
+func (_m *CustomerUsecase) Delete(ctx context.Context, merchantID int64, id int64) error {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePaymentMethod provides a mock function with given fields: ctx, merchantID, id
+func (_m *CustomerUsecase) DeletePaymentMethod(ctx context.Context, merchantID int64, id int64) error {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *CustomerUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Customer, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Customer); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Customer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchPaymentMethods provides a mock function with given fields: ctx, merchantID, customerID
+func (_m *CustomerUsecase) FetchPaymentMethods(ctx context.Context, merchantID int64, customerID int64) ([]domain.PaymentMethod, error) {
+	ret := _m.Called(ctx, merchantID, customerID)
+
+	var r0 []domain.PaymentMethod
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []domain.PaymentMethod); ok {
+		r0 = rf(ctx, merchantID, customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PaymentMethod)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, merchantID, id
+func (_m *CustomerUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Customer, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Customer
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Customer); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Customer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentMethod provides a mock function with given fields: ctx, merchantID, id
+func (_m *CustomerUsecase) GetPaymentMethod(ctx context.Context, merchantID int64, id int64) (domain.PaymentMethod, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.PaymentMethod
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.PaymentMethod); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.PaymentMethod)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, c
+func (_m *CustomerUsecase) Store(ctx context.Context, c *domain.Customer) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Customer) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, c
+func (_m *CustomerUsecase) Update(ctx context.Context, c *domain.Customer) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Customer) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
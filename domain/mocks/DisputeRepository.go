@@ -0,0 +1,153 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DisputeRepository is an autogenerated mock type for the DisputeRepository type
+type DisputeRepository struct {
+	mock.Mock
+}
+
+// FetchEvidence provides a mock function with given fields: ctx, disputeID
+func (_m *DisputeRepository) FetchEvidence(ctx context.Context, disputeID int64) ([]domain.DisputeEvidence, error) {
+	ret := _m.Called(ctx, disputeID)
+
+	var r0 []domain.DisputeEvidence
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.DisputeEvidence); ok {
+		r0 = rf(ctx, disputeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.DisputeEvidence)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, disputeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchOverdue provides a mock function with given fields: ctx, now
+func (_m *DisputeRepository) FetchOverdue(ctx context.Context, now time.Time) ([]domain.Dispute, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.Dispute); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Dispute)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *DisputeRepository) GetByID(ctx context.Context, id int64) (domain.Dispute, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Dispute); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Dispute)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Lose provides a mock function with given fields: ctx, id, from, e
+func (_m *DisputeRepository) Lose(ctx context.Context, id int64, from string, e *domain.LedgerEntry) (bool, error) {
+	ret := _m.Called(ctx, id, from, e)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, *domain.LedgerEntry) bool); ok {
+		r0 = rf(ctx, id, from, e)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, *domain.LedgerEntry) error); ok {
+		r1 = rf(ctx, id, from, e)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, d
+func (_m *DisputeRepository) Store(ctx context.Context, d *domain.Dispute) error {
+	ret := _m.Called(ctx, d)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Dispute) error); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreEvidence provides a mock function with given fields: ctx, e
+func (_m *DisputeRepository) StoreEvidence(ctx context.Context, e *domain.DisputeEvidence) error {
+	ret := _m.Called(ctx, e)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DisputeEvidence) error); ok {
+		r0 = rf(ctx, e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Transition provides a mock function with given fields: ctx, id, from, to
+func (_m *DisputeRepository) Transition(ctx context.Context, id int64, from string, to string) (bool, error) {
+	ret := _m.Called(ctx, id, from, to)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) bool); ok {
+		r0 = rf(ctx, id, from, to)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = rf(ctx, id, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,173 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DisputeUsecase is an autogenerated mock type for the DisputeUsecase type
+type DisputeUsecase struct {
+	mock.Mock
+}
+
+// Accept provides a mock function with given fields: ctx, merchantID, id
+func (_m *DisputeUsecase) Accept(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Dispute); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Dispute)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddEvidence provides a mock function with given fields: ctx, merchantID, e, r
+func (_m *DisputeUsecase) AddEvidence(ctx context.Context, merchantID int64, e *domain.DisputeEvidence, r io.Reader) error {
+	ret := _m.Called(ctx, merchantID, e, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *domain.DisputeEvidence, io.Reader) error); ok {
+		r0 = rf(ctx, merchantID, e, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Contest provides a mock function with given fields: ctx, merchantID, id
+func (_m *DisputeUsecase) Contest(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Dispute); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Dispute)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, merchantID, id
+func (_m *DisputeUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Dispute, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Dispute); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Dispute)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEvidence provides a mock function with given fields: ctx, merchantID, disputeID, id
+func (_m *DisputeUsecase) GetEvidence(ctx context.Context, merchantID int64, disputeID int64, id int64) (domain.DisputeEvidence, io.ReadCloser, error) {
+	ret := _m.Called(ctx, merchantID, disputeID, id)
+
+	var r0 domain.DisputeEvidence
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) domain.DisputeEvidence); ok {
+		r0 = rf(ctx, merchantID, disputeID, id)
+	} else {
+		r0 = ret.Get(0).(domain.DisputeEvidence)
+	}
+
+	var r1 io.ReadCloser
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) io.ReadCloser); ok {
+		r1 = rf(ctx, merchantID, disputeID, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadCloser)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64, int64) error); ok {
+		r2 = rf(ctx, merchantID, disputeID, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Open provides a mock function with given fields: ctx, d
+func (_m *DisputeUsecase) Open(ctx context.Context, d *domain.Dispute) error {
+	ret := _m.Called(ctx, d)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Dispute) error); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Resolve provides a mock function with given fields: ctx, id, won
+func (_m *DisputeUsecase) Resolve(ctx context.Context, id int64, won bool) (domain.Dispute, error) {
+	ret := _m.Called(ctx, id, won)
+
+	var r0 domain.Dispute
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) domain.Dispute); ok {
+		r0 = rf(ctx, id, won)
+	} else {
+		r0 = ret.Get(0).(domain.Dispute)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, bool) error); ok {
+		r1 = rf(ctx, id, won)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunDeadlines provides a mock function with given fields: ctx, now
+func (_m *DisputeUsecase) RunDeadlines(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,92 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EWalletProvider is an autogenerated mock type for the EWalletProvider type
+type EWalletProvider struct {
+	mock.Mock
+}
+
+// Charge provides a mock function with given fields: ctx, c
+func (_m *EWalletProvider) Charge(ctx context.Context, c *domain.EWalletCharge) (domain.EWalletPayment, error) {
+	ret := _m.Called(ctx, c)
+
+	var r0 domain.EWalletPayment
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.EWalletCharge) domain.EWalletPayment); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Get(0).(domain.EWalletPayment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.EWalletCharge) error); ok {
+		r1 = rf(ctx, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with given fields:
+func (_m *EWalletProvider) Name() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ParseCallback provides a mock function with given fields: body, signature
+func (_m *EWalletProvider) ParseCallback(body []byte, signature string) (domain.EWalletStatus, error) {
+	ret := _m.Called(body, signature)
+
+	var r0 domain.EWalletStatus
+	if rf, ok := ret.Get(0).(func([]byte, string) domain.EWalletStatus); ok {
+		r0 = rf(body, signature)
+	} else {
+		r0 = ret.Get(0).(domain.EWalletStatus)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]byte, string) error); ok {
+		r1 = rf(body, signature)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Status provides a mock function with given fields: ctx, reference
+func (_m *EWalletProvider) Status(ctx context.Context, reference string) (domain.EWalletStatus, error) {
+	ret := _m.Called(ctx, reference)
+
+	var r0 domain.EWalletStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.EWalletStatus); ok {
+		r0 = rf(ctx, reference)
+	} else {
+		r0 = ret.Get(0).(domain.EWalletStatus)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
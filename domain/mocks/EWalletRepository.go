@@ -0,0 +1,88 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EWalletRepository is an autogenerated mock type for the EWalletRepository type
+type EWalletRepository struct {
+	mock.Mock
+}
+
+// FetchPending provides a mock function with given fields: ctx, before
+func (_m *EWalletRepository) FetchPending(ctx context.Context, before time.Time) ([]domain.EWalletPayment, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []domain.EWalletPayment
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.EWalletPayment); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.EWalletPayment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByTransaction provides a mock function with given fields: ctx, transactionID
+func (_m *EWalletRepository) GetByTransaction(ctx context.Context, transactionID int64) (domain.EWalletPayment, error) {
+	ret := _m.Called(ctx, transactionID)
+
+	var r0 domain.EWalletPayment
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.EWalletPayment); ok {
+		r0 = rf(ctx, transactionID)
+	} else {
+		r0 = ret.Get(0).(domain.EWalletPayment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, p
+func (_m *EWalletRepository) Store(ctx context.Context, p *domain.EWalletPayment) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.EWalletPayment) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, p
+func (_m *EWalletRepository) Update(ctx context.Context, p *domain.EWalletPayment) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.EWalletPayment) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,72 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EWalletUsecase is an autogenerated mock type for the EWalletUsecase type
+type EWalletUsecase struct {
+	mock.Mock
+}
+
+// Callback provides a mock function with given fields: ctx, provider, body, signature
+func (_m *EWalletUsecase) Callback(ctx context.Context, provider string, body []byte, signature string) (domain.Transaction, error) {
+	ret := _m.Called(ctx, provider, body, signature)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, string) domain.Transaction); ok {
+		r0 = rf(ctx, provider, body, signature)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, string) error); ok {
+		r1 = rf(ctx, provider, body, signature)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByTransaction provides a mock function with given fields: ctx, merchantID, transactionID
+func (_m *EWalletUsecase) GetByTransaction(ctx context.Context, merchantID int64, transactionID int64) (domain.EWalletPayment, error) {
+	ret := _m.Called(ctx, merchantID, transactionID)
+
+	var r0 domain.EWalletPayment
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.EWalletPayment); ok {
+		r0 = rf(ctx, merchantID, transactionID)
+	} else {
+		r0 = ret.Get(0).(domain.EWalletPayment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunPolling provides a mock function with given fields: ctx, now
+func (_m *EWalletUsecase) RunPolling(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
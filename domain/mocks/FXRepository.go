@@ -0,0 +1,108 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FXRepository is an autogenerated mock type for the FXRepository type
+type FXRepository struct {
+	mock.Mock
+}
+
+// FetchRates provides a mock function with given fields: ctx
+func (_m *FXRepository) FetchRates(ctx context.Context) ([]domain.FXRate, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.FXRate
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.FXRate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FXRate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetQuote provides a mock function with given fields: ctx, id
+func (_m *FXRepository) GetQuote(ctx context.Context, id int64) (domain.FXQuote, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.FXQuote
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.FXQuote); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.FXQuote)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRate provides a mock function with given fields: ctx, currency
+func (_m *FXRepository) GetRate(ctx context.Context, currency string) (domain.FXRate, error) {
+	ret := _m.Called(ctx, currency)
+
+	var r0 domain.FXRate
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.FXRate); ok {
+		r0 = rf(ctx, currency)
+	} else {
+		r0 = ret.Get(0).(domain.FXRate)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreQuote provides a mock function with given fields: ctx, q
+func (_m *FXRepository) StoreQuote(ctx context.Context, q *domain.FXQuote) error {
+	ret := _m.Called(ctx, q)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.FXQuote) error); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreRate provides a mock function with given fields: ctx, r
+func (_m *FXRepository) StoreRate(ctx context.Context, r *domain.FXRate) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.FXRate) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,80 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// FXUsecase is an autogenerated mock type for the FXUsecase type
+type FXUsecase struct {
+	mock.Mock
+}
+
+// FetchRates provides a mock function with given fields: ctx
+func (_m *FXUsecase) FetchRates(ctx context.Context) ([]domain.FXRate, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.FXRate
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.FXRate); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FXRate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Lock provides a mock function with given fields: ctx, t
+func (_m *FXUsecase) Lock(ctx context.Context, t *domain.Transaction) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Quote provides a mock function with given fields: ctx, q
+func (_m *FXUsecase) Quote(ctx context.Context, q *domain.FXQuote) error {
+	ret := _m.Called(ctx, q)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.FXQuote) error); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreRates provides a mock function with given fields: ctx, rates
+func (_m *FXUsecase) StoreRates(ctx context.Context, rates []domain.FXRate) error {
+	ret := _m.Called(ctx, rates)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.FXRate) error); ok {
+		r0 = rf(ctx, rates)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,73 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InstallmentRepository is an autogenerated mock type for the InstallmentRepository type
+type InstallmentRepository struct {
+	mock.Mock
+}
+
+// FetchPlans provides a mock function with given fields: ctx, settingID
+func (_m *InstallmentRepository) FetchPlans(ctx context.Context, settingID int64) ([]domain.InstallmentPlan, error) {
+	ret := _m.Called(ctx, settingID)
+
+	var r0 []domain.InstallmentPlan
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.InstallmentPlan); ok {
+		r0 = rf(ctx, settingID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InstallmentPlan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, settingID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlan provides a mock function with given fields: ctx, id
+func (_m *InstallmentRepository) GetPlan(ctx context.Context, id int64) (domain.InstallmentPlan, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.InstallmentPlan
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.InstallmentPlan); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.InstallmentPlan)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorePlan provides a mock function with given fields: ctx, p
+func (_m *InstallmentRepository) StorePlan(ctx context.Context, p *domain.InstallmentPlan) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.InstallmentPlan) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InstallmentUsecase is an autogenerated mock type for the InstallmentUsecase type
+type InstallmentUsecase struct {
+	mock.Mock
+}
+
+// Apply provides a mock function with given fields: ctx, t, bin
+func (_m *InstallmentUsecase) Apply(ctx context.Context, t *domain.Transaction, bin string) error {
+	ret := _m.Called(ctx, t, bin)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction, string) error); ok {
+		r0 = rf(ctx, t, bin)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchPlans provides a mock function with given fields: ctx, merchantID, settingID
+func (_m *InstallmentUsecase) FetchPlans(ctx context.Context, merchantID int64, settingID int64) ([]domain.InstallmentPlan, error) {
+	ret := _m.Called(ctx, merchantID, settingID)
+
+	var r0 []domain.InstallmentPlan
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []domain.InstallmentPlan); ok {
+		r0 = rf(ctx, merchantID, settingID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InstallmentPlan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, settingID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Quote provides a mock function with given fields: ctx, merchantID, settingID, amount, bin
+func (_m *InstallmentUsecase) Quote(ctx context.Context, merchantID int64, settingID int64, amount int64, bin string) ([]domain.InstallmentQuote, error) {
+	ret := _m.Called(ctx, merchantID, settingID, amount, bin)
+
+	var r0 []domain.InstallmentQuote
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, string) []domain.InstallmentQuote); ok {
+		r0 = rf(ctx, merchantID, settingID, amount, bin)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InstallmentQuote)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, settingID, amount, bin)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StorePlan provides a mock function with given fields: ctx, p
+func (_m *InstallmentUsecase) StorePlan(ctx context.Context, p *domain.InstallmentPlan) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.InstallmentPlan) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,29 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InvoiceNotifier is an autogenerated mock type for the InvoiceNotifier type
+type InvoiceNotifier struct {
+	mock.Mock
+}
+
+// SendReminder provides a mock function with given fields: ctx, i
+func (_m *InvoiceNotifier) SendReminder(ctx context.Context, i domain.Invoice) error {
+	ret := _m.Called(ctx, i)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Invoice) error); ok {
+		r0 = rf(ctx, i)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,225 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InvoiceRepository is an autogenerated mock type for the InvoiceRepository type
+type InvoiceRepository struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, id
+func (_m *InvoiceRepository) Claim(ctx context.Context, id int64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchByMerchant provides a mock function with given fields: ctx, merchantID
+func (_m *InvoiceRepository) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Invoice, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Invoice); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Invoice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDueReminders provides a mock function with given fields: ctx, now
+func (_m *InvoiceRepository) FetchDueReminders(ctx context.Context, now time.Time) ([]domain.InvoiceReminder, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []domain.InvoiceReminder
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.InvoiceReminder); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.InvoiceReminder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchProcessing provides a mock function with given fields: ctx
+func (_m *InvoiceRepository) FetchProcessing(ctx context.Context) ([]domain.Invoice, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Invoice); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Invoice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *InvoiceRepository) GetByID(ctx context.Context, id int64) (domain.Invoice, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Invoice); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Invoice)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByLinkToken provides a mock function with given fields: ctx, token
+func (_m *InvoiceRepository) GetByLinkToken(ctx context.Context, token string) (domain.Invoice, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Invoice); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(domain.Invoice)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkOverdue provides a mock function with given fields: ctx, now
+func (_m *InvoiceRepository) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkReminderSent provides a mock function with given fields: ctx, r
+func (_m *InvoiceRepository) MarkReminderSent(ctx context.Context, r *domain.InvoiceReminder) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.InvoiceReminder) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, i
+func (_m *InvoiceRepository) Store(ctx context.Context, i *domain.Invoice) error {
+	ret := _m.Called(ctx, i)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Invoice) error); ok {
+		r0 = rf(ctx, i)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreReminders provides a mock function with given fields: ctx, reminders
+func (_m *InvoiceRepository) StoreReminders(ctx context.Context, reminders []domain.InvoiceReminder) error {
+	ret := _m.Called(ctx, reminders)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.InvoiceReminder) error); ok {
+		r0 = rf(ctx, reminders)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, i, from
+func (_m *InvoiceRepository) Update(ctx context.Context, i *domain.Invoice, from string) error {
+	ret := _m.Called(ctx, i, from)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Invoice, string) error); ok {
+		r0 = rf(ctx, i, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,165 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InvoiceUsecase is an autogenerated mock type for the InvoiceUsecase type
+type InvoiceUsecase struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *InvoiceUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Invoice, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Invoice); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Invoice)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Finalize provides a mock function with given fields: ctx, merchantID, id
+func (_m *InvoiceUsecase) Finalize(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Invoice); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Invoice)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, merchantID, id
+func (_m *InvoiceUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Invoice); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Invoice)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPage provides a mock function with given fields: ctx, token
+func (_m *InvoiceUsecase) GetPage(ctx context.Context, token string) (domain.InvoicePage, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 domain.InvoicePage
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.InvoicePage); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(domain.InvoicePage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Pay provides a mock function with given fields: ctx, token, t
+func (_m *InvoiceUsecase) Pay(ctx context.Context, token string, t *domain.Transaction) error {
+	ret := _m.Called(ctx, token, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Transaction) error); ok {
+		r0 = rf(ctx, token, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RunSchedule provides a mock function with given fields: ctx, now
+func (_m *InvoiceUsecase) RunSchedule(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, i
+func (_m *InvoiceUsecase) Store(ctx context.Context, i *domain.Invoice) error {
+	ret := _m.Called(ctx, i)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Invoice) error); ok {
+		r0 = rf(ctx, i)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Void provides a mock function with given fields: ctx, merchantID, id
+func (_m *InvoiceUsecase) Void(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Invoice
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Invoice); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Invoice)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
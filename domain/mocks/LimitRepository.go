@@ -0,0 +1,131 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LimitRepository is an autogenerated mock type for the LimitRepository type
+type LimitRepository struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *LimitRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Limit
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Limit); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Limit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchEffective provides a mock function with given fields: ctx, merchantID
+func (_m *LimitRepository) FetchEffective(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Limit
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Limit); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Limit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, merchantID, paymentType, period, amount
+func (_m *LimitRepository) Release(ctx context.Context, merchantID int64, paymentType string, period string, amount int64) error {
+	ret := _m.Called(ctx, merchantID, paymentType, period, amount)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) error); ok {
+		r0 = rf(ctx, merchantID, paymentType, period, amount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, merchantID, paymentType, period, amount, cap
+func (_m *LimitRepository) Reserve(ctx context.Context, merchantID int64, paymentType string, period string, amount int64, cap int64) (bool, error) {
+	ret := _m.Called(ctx, merchantID, paymentType, period, amount, cap)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64, int64) bool); ok {
+		r0 = rf(ctx, merchantID, paymentType, period, amount, cap)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, paymentType, period, amount, cap)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: ctx, l
+func (_m *LimitRepository) Upsert(ctx context.Context, l *domain.Limit) error {
+	ret := _m.Called(ctx, l)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Limit) error); ok {
+		r0 = rf(ctx, l)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Usage provides a mock function with given fields: ctx, merchantID, paymentType, period
+func (_m *LimitRepository) Usage(ctx context.Context, merchantID int64, paymentType string, period string) (int64, error) {
+	ret := _m.Called(ctx, merchantID, paymentType, period)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) int64); ok {
+		r0 = rf(ctx, merchantID, paymentType, period)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = rf(ctx, merchantID, paymentType, period)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,103 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LimitUsecase is an autogenerated mock type for the LimitUsecase type
+type LimitUsecase struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *LimitUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Limit
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Limit); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Limit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, t
+func (_m *LimitUsecase) Release(ctx context.Context, t *domain.Transaction) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reserve provides a mock function with given fields: ctx, t
+func (_m *LimitUsecase) Reserve(ctx context.Context, t *domain.Transaction) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Status provides a mock function with given fields: ctx, merchantID
+func (_m *LimitUsecase) Status(ctx context.Context, merchantID int64) ([]domain.LimitStatus, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.LimitStatus
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.LimitStatus); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LimitStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, l
+func (_m *LimitUsecase) Store(ctx context.Context, l *domain.Limit) error {
+	ret := _m.Called(ctx, l)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Limit) error); ok {
+		r0 = rf(ctx, l)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
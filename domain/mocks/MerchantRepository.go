@@ -14,6 +14,159 @@ type MerchantRepository struct {
 	mock.Mock
 }
 
+// CanShareCustomers provides a mock function with given fields: ctx, mg
+func (_m *MerchantRepository) CanShareCustomers(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+	ret := _m.Called(ctx, mg)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.MerchantGroup) bool); ok {
+		r0 = rf(ctx, mg)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.MerchantGroup) error); ok {
+		r1 = rf(ctx, mg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fetch provides a mock function with given fields: ctx
+func (_m *MerchantRepository) Fetch(ctx context.Context) ([]domain.Merchant, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Merchant); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Merchant)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDocuments provides a mock function with given fields: ctx, merchantID
+func (_m *MerchantRepository) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.MerchantDocument
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.MerchantDocument); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MerchantDocument)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchSettings provides a mock function with given fields: ctx, merchantID
+func (_m *MerchantRepository) FetchSettings(ctx context.Context, merchantID int64) ([]domain.Setting, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Setting
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Setting); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Setting)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *MerchantRepository) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Merchant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Merchant)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSetting provides a mock function with given fields: ctx, id
+func (_m *MerchantRepository) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Setting
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Setting); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Setting)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSettingByNMID provides a mock function with given fields: ctx, nmid
+func (_m *MerchantRepository) GetSettingByNMID(ctx context.Context, nmid string) (domain.Setting, error) {
+	ret := _m.Called(ctx, nmid)
+
+	var r0 domain.Setting
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Setting); ok {
+		r0 = rf(ctx, nmid)
+	} else {
+		r0 = ret.Get(0).(domain.Setting)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nmid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InitSetting provides a mock function with given fields: ctx, m
 func (_m *MerchantRepository) InitSetting(ctx context.Context, m *domain.Merchant) error {
 	ret := _m.Called(ctx, m)
@@ -76,3 +229,59 @@ func (_m *MerchantRepository) Store(ctx context.Context, m *domain.Merchant) err
 
 	return r0
 }
+
+// StoreDocument provides a mock function with given fields: ctx, d
+func (_m *MerchantRepository) StoreDocument(ctx context.Context, d *domain.MerchantDocument) error {
+	ret := _m.Called(ctx, d)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.MerchantDocument) error); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StoreSetting provides a mock function with given fields: ctx, s
+func (_m *MerchantRepository) StoreSetting(ctx context.Context, s *domain.Setting) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Setting) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, m
+func (_m *MerchantRepository) Update(ctx context.Context, m *domain.Merchant) error {
+	ret := _m.Called(ctx, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Merchant) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSetting provides a mock function with given fields: ctx, s
+func (_m *MerchantRepository) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Setting) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
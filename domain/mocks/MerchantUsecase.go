@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 
 	domain "github.com/hezbymuhammad/payment-gateway/domain"
 	mock "github.com/stretchr/testify/mock"
@@ -14,6 +15,157 @@ type MerchantUsecase struct {
 	mock.Mock
 }
 
+// AddDocument provides a mock function with given fields: ctx, d, r
+func (_m *MerchantUsecase) AddDocument(ctx context.Context, d *domain.MerchantDocument, r io.Reader) error {
+	ret := _m.Called(ctx, d, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.MerchantDocument, io.Reader) error); ok {
+		r0 = rf(ctx, d, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Approve provides a mock function with given fields: ctx, id, notes
+func (_m *MerchantUsecase) Approve(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+	ret := _m.Called(ctx, id, notes)
+
+	var r0 domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Merchant); ok {
+		r0 = rf(ctx, id, notes)
+	} else {
+		r0 = ret.Get(0).(domain.Merchant)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, id, notes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDocuments provides a mock function with given fields: ctx, merchantID
+func (_m *MerchantUsecase) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.MerchantDocument
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.MerchantDocument); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MerchantDocument)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *MerchantUsecase) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Merchant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Merchant)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDocument provides a mock function with given fields: ctx, merchantID, id
+func (_m *MerchantUsecase) GetDocument(ctx context.Context, merchantID int64, id int64) (domain.MerchantDocument, io.ReadCloser, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.MerchantDocument
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.MerchantDocument); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.MerchantDocument)
+	}
+
+	var r1 io.ReadCloser
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) io.ReadCloser); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadCloser)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, merchantID, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSetting provides a mock function with given fields: ctx, id
+func (_m *MerchantUsecase) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Setting
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Setting); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Setting)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: ctx, id, notes
+func (_m *MerchantUsecase) Reject(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+	ret := _m.Called(ctx, id, notes)
+
+	var r0 domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Merchant); ok {
+		r0 = rf(ctx, id, notes)
+	} else {
+		r0 = ret.Get(0).(domain.Merchant)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, id, notes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetChild provides a mock function with given fields: ctx, mg
 func (_m *MerchantUsecase) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
 	ret := _m.Called(ctx, mg)
@@ -41,3 +193,66 @@ func (_m *MerchantUsecase) Store(ctx context.Context, m *domain.Merchant) error
 
 	return r0
 }
+
+// StoreSetting provides a mock function with given fields: ctx, s
+func (_m *MerchantUsecase) StoreSetting(ctx context.Context, s *domain.Setting) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Setting) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Submit provides a mock function with given fields: ctx, id
+func (_m *MerchantUsecase) Submit(ctx context.Context, id int64) (domain.Merchant, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Merchant
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Merchant); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Merchant)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, m
+func (_m *MerchantUsecase) Update(ctx context.Context, m *domain.Merchant) error {
+	ret := _m.Called(ctx, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Merchant) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSetting provides a mock function with given fields: ctx, s
+func (_m *MerchantUsecase) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Setting) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
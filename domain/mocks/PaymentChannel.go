@@ -0,0 +1,43 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PaymentChannel is an autogenerated mock type for the PaymentChannel type
+type PaymentChannel struct {
+	mock.Mock
+}
+
+// Initiate provides a mock function with given fields: ctx, t, s
+func (_m *PaymentChannel) Initiate(ctx context.Context, t *domain.Transaction, s domain.Setting) error {
+	ret := _m.Called(ctx, t, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction, domain.Setting) error); ok {
+		r0 = rf(ctx, t, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PaymentType provides a mock function with given fields:
+func (_m *PaymentChannel) PaymentType() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
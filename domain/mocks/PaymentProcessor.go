@@ -0,0 +1,113 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PaymentProcessor is an autogenerated mock type for the PaymentProcessor type
+type PaymentProcessor struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, req
+func (_m *PaymentProcessor) Authorize(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.ProcessorResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PaymentRequest) domain.ProcessorResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.ProcessorResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.PaymentRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Capture provides a mock function with given fields: ctx, req
+func (_m *PaymentProcessor) Capture(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.ProcessorResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PaymentRequest) domain.ProcessorResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.ProcessorResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.PaymentRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with given fields:
+func (_m *PaymentProcessor) Name() string {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Refund provides a mock function with given fields: ctx, req
+func (_m *PaymentProcessor) Refund(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.ProcessorResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PaymentRequest) domain.ProcessorResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.ProcessorResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.PaymentRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Void provides a mock function with given fields: ctx, req
+func (_m *PaymentProcessor) Void(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.ProcessorResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.PaymentRequest) domain.ProcessorResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.ProcessorResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.PaymentRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
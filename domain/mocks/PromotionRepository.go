@@ -0,0 +1,108 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PromotionRepository is an autogenerated mock type for the PromotionRepository type
+type PromotionRepository struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ctx, p, customerID
+func (_m *PromotionRepository) Claim(ctx context.Context, p domain.Promotion, customerID int64) (bool, error) {
+	ret := _m.Called(ctx, p, customerID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Promotion, int64) bool); ok {
+		r0 = rf(ctx, p, customerID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Promotion, int64) error); ok {
+		r1 = rf(ctx, p, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *PromotionRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.Promotion, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Promotion
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Promotion); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Promotion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByCode provides a mock function with given fields: ctx, merchantID, code
+func (_m *PromotionRepository) GetByCode(ctx context.Context, merchantID int64, code string) (domain.Promotion, error) {
+	ret := _m.Called(ctx, merchantID, code)
+
+	var r0 domain.Promotion
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Promotion); ok {
+		r0 = rf(ctx, merchantID, code)
+	} else {
+		r0 = ret.Get(0).(domain.Promotion)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ctx, promotionID, customerID
+func (_m *PromotionRepository) Release(ctx context.Context, promotionID int64, customerID int64) error {
+	ret := _m.Called(ctx, promotionID, customerID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, promotionID, customerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, p
+func (_m *PromotionRepository) Store(ctx context.Context, p *domain.Promotion) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Promotion) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,80 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PromotionUsecase is an autogenerated mock type for the PromotionUsecase type
+type PromotionUsecase struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *PromotionUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Promotion, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Promotion
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Promotion); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Promotion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeem provides a mock function with given fields: ctx, t, bin
+func (_m *PromotionUsecase) Redeem(ctx context.Context, t *domain.Transaction, bin string) error {
+	ret := _m.Called(ctx, t, bin)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction, string) error); ok {
+		r0 = rf(ctx, t, bin)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: ctx, t
+func (_m *PromotionUsecase) Release(ctx context.Context, t *domain.Transaction) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, p
+func (_m *PromotionUsecase) Store(ctx context.Context, p *domain.Promotion) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Promotion) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// QRISUsecase is an autogenerated mock type for the QRISUsecase type
+type QRISUsecase struct {
+	mock.Mock
+}
+
+// Notify provides a mock function with given fields: ctx, n
+func (_m *QRISUsecase) Notify(ctx context.Context, n *domain.QRNotification) (domain.Transaction, error) {
+	ret := _m.Called(ctx, n)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QRNotification) domain.Transaction); ok {
+		r0 = rf(ctx, n)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.QRNotification) error); ok {
+		r1 = rf(ctx, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StaticPayload provides a mock function with given fields: ctx, merchantID, settingID
+func (_m *QRISUsecase) StaticPayload(ctx context.Context, merchantID int64, settingID int64) (string, error) {
+	ret := _m.Called(ctx, merchantID, settingID)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) string); ok {
+		r0 = rf(ctx, merchantID, settingID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, settingID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,64 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// QRPaymentRepository is an autogenerated mock type for the QRPaymentRepository type
+type QRPaymentRepository struct {
+	mock.Mock
+}
+
+// ClaimPayment provides a mock function with given fields: ctx, p
+func (_m *QRPaymentRepository) ClaimPayment(ctx context.Context, p *domain.QRPayment) (bool, error) {
+	ret := _m.Called(ctx, p)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QRPayment) bool); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.QRPayment) error); ok {
+		r1 = rf(ctx, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleasePayment provides a mock function with given fields: ctx, id
+func (_m *QRPaymentRepository) ReleasePayment(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePayment provides a mock function with given fields: ctx, p
+func (_m *QRPaymentRepository) UpdatePayment(ctx context.Context, p *domain.QRPayment) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QRPayment) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
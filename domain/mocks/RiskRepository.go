@@ -0,0 +1,146 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RiskRepository is an autogenerated mock type for the RiskRepository type
+type RiskRepository struct {
+	mock.Mock
+}
+
+// CountSince provides a mock function with given fields: ctx, merchantID, field, value, since
+func (_m *RiskRepository) CountSince(ctx context.Context, merchantID int64, field string, value string, since time.Time) (int64, error) {
+	ret := _m.Called(ctx, merchantID, field, value, since)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, time.Time) int64); ok {
+		r0 = rf(ctx, merchantID, field, value, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, time.Time) error); ok {
+		r1 = rf(ctx, merchantID, field, value, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *RiskRepository) Delete(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *RiskRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.RiskRule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.RiskRule); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RiskRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchEnabled provides a mock function with given fields: ctx, merchantID, parentMerchantID
+func (_m *RiskRepository) FetchEnabled(ctx context.Context, merchantID int64, parentMerchantID int64) ([]domain.RiskRule, error) {
+	ret := _m.Called(ctx, merchantID, parentMerchantID)
+
+	var r0 []domain.RiskRule
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []domain.RiskRule); ok {
+		r0 = rf(ctx, merchantID, parentMerchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RiskRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, parentMerchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *RiskRepository) GetByID(ctx context.Context, id int64) (domain.RiskRule, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.RiskRule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.RiskRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.RiskRule)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, r
+func (_m *RiskRepository) Store(ctx context.Context, r *domain.RiskRule) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RiskRule) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, r
+func (_m *RiskRepository) Update(ctx context.Context, r *domain.RiskRule) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RiskRule) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,94 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RiskUsecase is an autogenerated mock type for the RiskUsecase type
+type RiskUsecase struct {
+	mock.Mock
+}
+
+// DeleteRule provides a mock function with given fields: ctx, merchantID, id
+func (_m *RiskUsecase) DeleteRule(ctx context.Context, merchantID int64, id int64) error {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Evaluate provides a mock function with given fields: ctx, t, bin
+func (_m *RiskUsecase) Evaluate(ctx context.Context, t *domain.Transaction, bin string) error {
+	ret := _m.Called(ctx, t, bin)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction, string) error); ok {
+		r0 = rf(ctx, t, bin)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FetchRules provides a mock function with given fields: ctx, merchantID
+func (_m *RiskUsecase) FetchRules(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.RiskRule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.RiskRule); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RiskRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreRule provides a mock function with given fields: ctx, r
+func (_m *RiskUsecase) StoreRule(ctx context.Context, r *domain.RiskRule) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RiskRule) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRule provides a mock function with given fields: ctx, r
+func (_m *RiskUsecase) UpdateRule(ctx context.Context, r *domain.RiskRule) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.RiskRule) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
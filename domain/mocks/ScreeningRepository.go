@@ -0,0 +1,73 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ScreeningRepository is an autogenerated mock type for the ScreeningRepository type
+type ScreeningRepository struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *ScreeningRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.Screening, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Screening
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Screening); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Screening)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Latest provides a mock function with given fields: ctx, merchantID
+func (_m *ScreeningRepository) Latest(ctx context.Context, merchantID int64) (domain.Screening, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 domain.Screening
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Screening); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		r0 = ret.Get(0).(domain.Screening)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, s
+func (_m *ScreeningRepository) Store(ctx context.Context, s *domain.Screening) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Screening) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
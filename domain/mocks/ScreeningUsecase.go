@@ -0,0 +1,67 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ScreeningUsecase is an autogenerated mock type for the ScreeningUsecase type
+type ScreeningUsecase struct {
+	mock.Mock
+}
+
+// Fetch provides a mock function with given fields: ctx, merchantID
+func (_m *ScreeningUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Screening, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Screening
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Screening); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Screening)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunRefresh provides a mock function with given fields: ctx, now
+func (_m *ScreeningUsecase) RunRefresh(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Screen provides a mock function with given fields: ctx, m
+func (_m *ScreeningUsecase) Screen(ctx context.Context, m *domain.Merchant) error {
+	ret := _m.Called(ctx, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Merchant) error); ok {
+		r0 = rf(ctx, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,202 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SubscriptionRepository is an autogenerated mock type for the SubscriptionRepository type
+type SubscriptionRepository struct {
+	mock.Mock
+}
+
+// ClaimCycle provides a mock function with given fields: ctx, c
+func (_m *SubscriptionRepository) ClaimCycle(ctx context.Context, c *domain.BillingCycle) (bool, error) {
+	ret := _m.Called(ctx, c)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.BillingCycle) bool); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.BillingCycle) error); ok {
+		r1 = rf(ctx, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchDue provides a mock function with given fields: ctx, now
+func (_m *SubscriptionRepository) FetchDue(ctx context.Context, now time.Time) ([]domain.Subscription, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.Subscription); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchPlans provides a mock function with given fields: ctx, merchantID
+func (_m *SubscriptionRepository) FetchPlans(ctx context.Context, merchantID int64) ([]domain.Plan, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Plan
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Plan); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Plan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *SubscriptionRepository) GetByID(ctx context.Context, id int64) (domain.Subscription, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Subscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Subscription)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCycle provides a mock function with given fields: ctx, subscriptionID, periodStart, attempt
+func (_m *SubscriptionRepository) GetCycle(ctx context.Context, subscriptionID int64, periodStart time.Time, attempt int) (domain.BillingCycle, error) {
+	ret := _m.Called(ctx, subscriptionID, periodStart, attempt)
+
+	var r0 domain.BillingCycle
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, int) domain.BillingCycle); ok {
+		r0 = rf(ctx, subscriptionID, periodStart, attempt)
+	} else {
+		r0 = ret.Get(0).(domain.BillingCycle)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time, int) error); ok {
+		r1 = rf(ctx, subscriptionID, periodStart, attempt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPlan provides a mock function with given fields: ctx, id
+func (_m *SubscriptionRepository) GetPlan(ctx context.Context, id int64) (domain.Plan, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Plan
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Plan); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Plan)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Store provides a mock function with given fields: ctx, s
+func (_m *SubscriptionRepository) Store(ctx context.Context, s *domain.Subscription) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Subscription) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorePlan provides a mock function with given fields: ctx, p
+func (_m *SubscriptionRepository) StorePlan(ctx context.Context, p *domain.Plan) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Plan) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, s
+func (_m *SubscriptionRepository) Update(ctx context.Context, s *domain.Subscription) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Subscription) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCycle provides a mock function with given fields: ctx, c
+func (_m *SubscriptionRepository) UpdateCycle(ctx context.Context, c *domain.BillingCycle) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.BillingCycle) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
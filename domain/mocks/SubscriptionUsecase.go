@@ -0,0 +1,144 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SubscriptionUsecase is an autogenerated mock type for the SubscriptionUsecase type
+type SubscriptionUsecase struct {
+	mock.Mock
+}
+
+// Cancel provides a mock function with given fields: ctx, merchantID, id, atPeriodEnd
+func (_m *SubscriptionUsecase) Cancel(ctx context.Context, merchantID int64, id int64, atPeriodEnd bool) (domain.Subscription, error) {
+	ret := _m.Called(ctx, merchantID, id, atPeriodEnd)
+
+	var r0 domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) domain.Subscription); ok {
+		r0 = rf(ctx, merchantID, id, atPeriodEnd)
+	} else {
+		r0 = ret.Get(0).(domain.Subscription)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool) error); ok {
+		r1 = rf(ctx, merchantID, id, atPeriodEnd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePlan provides a mock function with given fields: ctx, merchantID, id, planID
+func (_m *SubscriptionUsecase) ChangePlan(ctx context.Context, merchantID int64, id int64, planID int64) (domain.Subscription, error) {
+	ret := _m.Called(ctx, merchantID, id, planID)
+
+	var r0 domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) domain.Subscription); ok {
+		r0 = rf(ctx, merchantID, id, planID)
+	} else {
+		r0 = ret.Get(0).(domain.Subscription)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id, planID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchPlans provides a mock function with given fields: ctx, merchantID
+func (_m *SubscriptionUsecase) FetchPlans(ctx context.Context, merchantID int64) ([]domain.Plan, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Plan
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Plan); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Plan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, merchantID, id
+func (_m *SubscriptionUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Subscription, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.Subscription); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Subscription)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunBilling provides a mock function with given fields: ctx, now
+func (_m *SubscriptionUsecase) RunBilling(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StorePlan provides a mock function with given fields: ctx, p
+func (_m *SubscriptionUsecase) StorePlan(ctx context.Context, p *domain.Plan) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Plan) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: ctx, s
+func (_m *SubscriptionUsecase) Subscribe(ctx context.Context, s *domain.Subscription) error {
+	ret := _m.Called(ctx, s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Subscription) error); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -14,6 +14,52 @@ type TransactionRepository struct {
 	mock.Mock
 }
 
+// FetchByState provides a mock function with given fields: ctx, merchantID, state
+func (_m *TransactionRepository) FetchByState(ctx context.Context, merchantID int64, state string) ([]domain.Transaction, error) {
+	ret := _m.Called(ctx, merchantID, state)
+
+	var r0 []domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []domain.Transaction); ok {
+		r0 = rf(ctx, merchantID, state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchChanges provides a mock function with given fields: ctx, transactionID
+func (_m *TransactionRepository) FetchChanges(ctx context.Context, transactionID int64) ([]domain.TransactionChange, error) {
+	ret := _m.Called(ctx, transactionID)
+
+	var r0 []domain.TransactionChange
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.TransactionChange); ok {
+		r0 = rf(ctx, transactionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TransactionChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *TransactionRepository) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
 	ret := _m.Called(ctx, id)
@@ -35,6 +81,27 @@ func (_m *TransactionRepository) GetByID(ctx context.Context, id int64) (domain.
 	return r0, r1
 }
 
+// GetByMerchantReference provides a mock function with given fields: ctx, merchantID, reference
+func (_m *TransactionRepository) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+	ret := _m.Called(ctx, merchantID, reference)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Transaction); ok {
+		r0 = rf(ctx, merchantID, reference)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: ctx, t
 func (_m *TransactionRepository) Store(ctx context.Context, t *domain.Transaction) error {
 	ret := _m.Called(ctx, t)
@@ -49,6 +116,20 @@ func (_m *TransactionRepository) Store(ctx context.Context, t *domain.Transactio
 	return r0
 }
 
+// StoreChanges provides a mock function with given fields: ctx, changes
+func (_m *TransactionRepository) StoreChanges(ctx context.Context, changes []domain.TransactionChange) error {
+	ret := _m.Called(ctx, changes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.TransactionChange) error); ok {
+		r0 = rf(ctx, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, t
 func (_m *TransactionRepository) Update(ctx context.Context, t *domain.Transaction) error {
 	ret := _m.Called(ctx, t)
@@ -14,6 +14,136 @@ type TransactionUsecase struct {
 	mock.Mock
 }
 
+// Approve provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) Approve(ctx context.Context, id int64) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Transaction); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Capture provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) Capture(ctx context.Context, id int64) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Transaction); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Complete provides a mock function with given fields: ctx, id, amount, reference
+func (_m *TransactionUsecase) Complete(ctx context.Context, id int64, amount int64, reference string) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id, amount, reference)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) domain.Transaction); ok {
+		r0 = rf(ctx, id, amount, reference)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
+		r1 = rf(ctx, id, amount, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Fail provides a mock function with given fields: ctx, id, message
+func (_m *TransactionUsecase) Fail(ctx context.Context, id int64, message string) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id, message)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Transaction); ok {
+		r0 = rf(ctx, id, message)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, id, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchChanges provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) FetchChanges(ctx context.Context, id int64) ([]domain.TransactionChange, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []domain.TransactionChange
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.TransactionChange); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.TransactionChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchReviews provides a mock function with given fields: ctx, merchantID
+func (_m *TransactionUsecase) FetchReviews(ctx context.Context, merchantID int64) ([]domain.Transaction, error) {
+	ret := _m.Called(ctx, merchantID)
+
+	var r0 []domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []domain.Transaction); ok {
+		r0 = rf(ctx, merchantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, merchantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *TransactionUsecase) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
 	ret := _m.Called(ctx, id)
@@ -35,6 +165,90 @@ func (_m *TransactionUsecase) GetByID(ctx context.Context, id int64) (domain.Tra
 	return r0, r1
 }
 
+// GetByMerchantReference provides a mock function with given fields: ctx, merchantID, reference
+func (_m *TransactionUsecase) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+	ret := _m.Called(ctx, merchantID, reference)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) domain.Transaction); ok {
+		r0 = rf(ctx, merchantID, reference)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, merchantID, reference)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Patch provides a mock function with given fields: ctx, id, p
+func (_m *TransactionUsecase) Patch(ctx context.Context, id int64, p *domain.TransactionPatch) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id, p)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *domain.TransactionPatch) domain.Transaction); ok {
+		r0 = rf(ctx, id, p)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *domain.TransactionPatch) error); ok {
+		r1 = rf(ctx, id, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Refund provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) Refund(ctx context.Context, id int64) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Transaction); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) Reject(ctx context.Context, id int64) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Transaction); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Store provides a mock function with given fields: ctx, t
 func (_m *TransactionUsecase) Store(ctx context.Context, t *domain.Transaction) error {
 	ret := _m.Called(ctx, t)
@@ -49,16 +263,23 @@ func (_m *TransactionUsecase) Store(ctx context.Context, t *domain.Transaction)
 	return r0
 }
 
-// Update provides a mock function with given fields: ctx, t
-func (_m *TransactionUsecase) Update(ctx context.Context, t *domain.Transaction) error {
-	ret := _m.Called(ctx, t)
+// Void provides a mock function with given fields: ctx, id
+func (_m *TransactionUsecase) Void(ctx context.Context, id int64) (domain.Transaction, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *domain.Transaction) error); ok {
-		r0 = rf(ctx, t)
+	var r0 domain.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.Transaction); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Transaction)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
@@ -0,0 +1,193 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// VirtualAccountRepository is an autogenerated mock type for the VirtualAccountRepository type
+type VirtualAccountRepository struct {
+	mock.Mock
+}
+
+// ClaimPayment provides a mock function with given fields: ctx, p
+func (_m *VirtualAccountRepository) ClaimPayment(ctx context.Context, p *domain.VirtualAccountPayment) (bool, error) {
+	ret := _m.Called(ctx, p)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VirtualAccountPayment) bool); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.VirtualAccountPayment) error); ok {
+		r1 = rf(ctx, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FetchExpired provides a mock function with given fields: ctx, now
+func (_m *VirtualAccountRepository) FetchExpired(ctx context.Context, now time.Time) ([]domain.VirtualAccount, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []domain.VirtualAccount
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []domain.VirtualAccount); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.VirtualAccount)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *VirtualAccountRepository) GetByID(ctx context.Context, id int64) (domain.VirtualAccount, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 domain.VirtualAccount
+	if rf, ok := ret.Get(0).(func(context.Context, int64) domain.VirtualAccount); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.VirtualAccount)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByNumber provides a mock function with given fields: ctx, bank, number
+func (_m *VirtualAccountRepository) GetByNumber(ctx context.Context, bank string, number string) (domain.VirtualAccount, error) {
+	ret := _m.Called(ctx, bank, number)
+
+	var r0 domain.VirtualAccount
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.VirtualAccount); ok {
+		r0 = rf(ctx, bank, number)
+	} else {
+		r0 = ret.Get(0).(domain.VirtualAccount)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, bank, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Link provides a mock function with given fields: ctx, id, transactionID
+func (_m *VirtualAccountRepository) Link(ctx context.Context, id int64, transactionID int64) (bool, error) {
+	ret := _m.Called(ctx, id, transactionID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = rf(ctx, id, transactionID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, id, transactionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleasePayment provides a mock function with given fields: ctx, id
+func (_m *VirtualAccountRepository) ReleasePayment(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, va
+func (_m *VirtualAccountRepository) Store(ctx context.Context, va *domain.VirtualAccount) error {
+	ret := _m.Called(ctx, va)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VirtualAccount) error); ok {
+		r0 = rf(ctx, va)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unlink provides a mock function with given fields: ctx, id, transactionID
+func (_m *VirtualAccountRepository) Unlink(ctx context.Context, id int64, transactionID int64) error {
+	ret := _m.Called(ctx, id, transactionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, transactionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, va
+func (_m *VirtualAccountRepository) Update(ctx context.Context, va *domain.VirtualAccount) error {
+	ret := _m.Called(ctx, va)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VirtualAccount) error); ok {
+		r0 = rf(ctx, va)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePayment provides a mock function with given fields: ctx, p
+func (_m *VirtualAccountRepository) UpdatePayment(ctx context.Context, p *domain.VirtualAccountPayment) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VirtualAccountPayment) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,86 @@
+// Code generated by mockery 2.9.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	domain "github.com/hezbymuhammad/payment-gateway/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// VirtualAccountUsecase is an autogenerated mock type for the VirtualAccountUsecase type
+type VirtualAccountUsecase struct {
+	mock.Mock
+}
+
+// GetByID provides a mock function with given fields: ctx, merchantID, id
+func (_m *VirtualAccountUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.VirtualAccount, error) {
+	ret := _m.Called(ctx, merchantID, id)
+
+	var r0 domain.VirtualAccount
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) domain.VirtualAccount); ok {
+		r0 = rf(ctx, merchantID, id)
+	} else {
+		r0 = ret.Get(0).(domain.VirtualAccount)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, merchantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Notify provides a mock function with given fields: ctx, n
+func (_m *VirtualAccountUsecase) Notify(ctx context.Context, n *domain.VANotification) (domain.VirtualAccountPayment, error) {
+	ret := _m.Called(ctx, n)
+
+	var r0 domain.VirtualAccountPayment
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VANotification) domain.VirtualAccountPayment); ok {
+		r0 = rf(ctx, n)
+	} else {
+		r0 = ret.Get(0).(domain.VirtualAccountPayment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.VANotification) error); ok {
+		r1 = rf(ctx, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunExpiry provides a mock function with given fields: ctx, now
+func (_m *VirtualAccountUsecase) RunExpiry(ctx context.Context, now time.Time) error {
+	ret := _m.Called(ctx, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Store provides a mock function with given fields: ctx, va
+func (_m *VirtualAccountUsecase) Store(ctx context.Context, va *domain.VirtualAccount) error {
+	ret := _m.Called(ctx, va)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.VirtualAccount) error); ok {
+		r0 = rf(ctx, va)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
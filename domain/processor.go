@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+)
+
+const (
+	ResponseApproved          = "00"
+	ResponseDeclined          = "05"
+	ResponseInvalidCard       = "14"
+	ResponseInsufficientFunds = "51"
+	ResponseTimeout           = "68"
+)
+
+// PaymentRequest is what a processor needs to act on a transaction. Reference
+// is the processor's own reference returned by Authorize and, together with
+// Processor, is required for capture, refund and void. Installments is the
+// number of months the issuer should split the charge over, if any.
+type PaymentRequest struct {
+	TransactionID int64
+	MerchantID    int64
+	Amount        int64
+	Currency      string
+	PaymentType   string
+	CardToken     string
+	Processor     string
+	Reference     string
+	Installments  int
+}
+
+type ProcessorResponse struct {
+	Processor string
+	Reference string
+	Code      string
+	Message   string
+	Approved  bool
+	Route     string
+}
+
+type PaymentProcessor interface {
+	Name() string
+	Authorize(ctx context.Context, req *PaymentRequest) (ProcessorResponse, error)
+	Capture(ctx context.Context, req *PaymentRequest) (ProcessorResponse, error)
+	Refund(ctx context.Context, req *PaymentRequest) (ProcessorResponse, error)
+	Void(ctx context.Context, req *PaymentRequest) (ProcessorResponse, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Promotion is a promo code a merchant hands out. A percent discount's Value
+// is in basis points and may be capped by MaxDiscount; a fixed discount's
+// Value is in rupiah. A parent-wide promotion also applies to transactions
+// the merchant submits for its child merchants. TotalLimit and CustomerLimit
+// cap redemptions overall and per customer; zero means no cap. BINs limits
+// the promotion to cards from participating issuers.
+type Promotion struct {
+	ID            int64     `json:"id"`
+	MerchantID    int64     `json:"merchantId"`
+	Code          string    `json:"code"`
+	Type          string    `json:"type"`
+	Value         int64     `json:"value"`
+	MaxDiscount   int64     `json:"maxDiscount"`
+	MinAmount     int64     `json:"minAmount"`
+	ParentWide    bool      `json:"parentWide"`
+	BINs          []string  `json:"bins"`
+	TotalLimit    int64     `json:"totalLimit"`
+	CustomerLimit int64     `json:"customerLimit"`
+	Redeemed      int64     `json:"redeemed"`
+	StartsAt      time.Time `json:"startsAt"`
+	EndsAt        time.Time `json:"endsAt"`
+}
+
+type PromotionUsecase interface {
+	Store(ctx context.Context, p *Promotion) error
+	Fetch(ctx context.Context, merchantID int64) ([]Promotion, error)
+	Redeem(ctx context.Context, t *Transaction, bin string) error
+	Release(ctx context.Context, t *Transaction) error
+}
+
+type PromotionRepository interface {
+	Store(ctx context.Context, p *Promotion) error
+	GetByCode(ctx context.Context, merchantID int64, code string) (Promotion, error)
+	Fetch(ctx context.Context, merchantID int64) ([]Promotion, error)
+	Claim(ctx context.Context, p Promotion, customerID int64) (bool, error)
+	Release(ctx context.Context, promotionID int64, customerID int64) error
+}
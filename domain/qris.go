@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// QRNotification is what an issuer sends once a customer has paid a QRIS
+// code: the scanned payload, the amount debited and the issuer's own
+// reference for the payment.
+type QRNotification struct {
+	Payload         string `json:"payload"`
+	Amount          int64  `json:"amount"`
+	IssuerReference string `json:"issuerReference"`
+}
+
+// QRPayment is a payment to a static QRIS code. The issuer's reference
+// identifies it within the merchant, so a retried notification is only
+// booked once.
+type QRPayment struct {
+	ID              int64     `json:"id"`
+	MerchantID      int64     `json:"merchantId"`
+	TransactionID   int64     `json:"transactionId"`
+	IssuerReference string    `json:"issuerReference"`
+	Amount          int64     `json:"amount"`
+	PaidAt          time.Time `json:"paidAt"`
+}
+
+type QRISUsecase interface {
+	StaticPayload(ctx context.Context, merchantID int64, settingID int64) (string, error)
+	Notify(ctx context.Context, n *QRNotification) (Transaction, error)
+}
+
+type QRPaymentRepository interface {
+	ClaimPayment(ctx context.Context, p *QRPayment) (bool, error)
+	UpdatePayment(ctx context.Context, p *QRPayment) error
+	ReleasePayment(ctx context.Context, id int64) error
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskBlock  = "block"
+)
+
+const (
+	RiskRuleVelocity        = "velocity"
+	RiskRuleAmount          = "amount"
+	RiskRuleCountryMismatch = "country_mismatch"
+	RiskRuleBlocklist       = "blocklist"
+)
+
+// Fields a velocity or blocklist rule can look at. Velocity rules count by
+// card fingerprint, customer or IP; blocklists can also match BIN prefixes and
+// countries.
+const (
+	RiskFieldCard     = "card"
+	RiskFieldCustomer = "customer"
+	RiskFieldIP       = "ip"
+	RiskFieldBIN      = "bin"
+	RiskFieldCountry  = "country"
+)
+
+// RiskRule is one check the risk engine runs before a transaction is
+// stored. A velocity rule triggers once Threshold transactions with the same
+// Field value were already made in the last WindowSeconds; an amount rule
+// once the settlement amount is above Threshold; a country mismatch rule
+// when the IP and billing countries differ; a blocklist rule when Field
+// matches one of Values. A triggered rule applies its Action. Block beats
+// review, which beats allow, so an allow rule only ever records that it
+// matched.
+type RiskRule struct {
+	ID            int64     `json:"id"`
+	MerchantID    int64     `json:"merchantId"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Action        string    `json:"action"`
+	Field         string    `json:"field,omitempty"`
+	Threshold     int64     `json:"threshold,omitempty"`
+	WindowSeconds int64     `json:"windowSeconds,omitempty"`
+	Values        []string  `json:"values"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type RiskUsecase interface {
+	StoreRule(ctx context.Context, r *RiskRule) error
+	FetchRules(ctx context.Context, merchantID int64) ([]RiskRule, error)
+	UpdateRule(ctx context.Context, r *RiskRule) error
+	DeleteRule(ctx context.Context, merchantID int64, id int64) error
+	Evaluate(ctx context.Context, t *Transaction, bin string) error
+}
+
+type RiskRepository interface {
+	Store(ctx context.Context, r *RiskRule) error
+	GetByID(ctx context.Context, id int64) (RiskRule, error)
+	Fetch(ctx context.Context, merchantID int64) ([]RiskRule, error)
+	FetchEnabled(ctx context.Context, merchantID int64, parentMerchantID int64) ([]RiskRule, error)
+	Update(ctx context.Context, r *RiskRule) error
+	Delete(ctx context.Context, id int64) error
+	CountSince(ctx context.Context, merchantID int64, field string, value string, since time.Time) (int64, error)
+}
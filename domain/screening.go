@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ScreeningClear = "clear"
+	ScreeningMatch = "match"
+)
+
+// ListEntry is one party on a sanctions list or blocklist.
+type ListEntry struct {
+	List string `json:"list"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ScreeningHit is a list entry that a merchant's name, or one of its
+// owners' names, matched closely enough. Score runs from 0 to 100.
+type ScreeningHit struct {
+	List      string `json:"list"`
+	EntryID   string `json:"entryId"`
+	EntryName string `json:"entryName"`
+	Subject   string `json:"subject"`
+	Score     int    `json:"score"`
+}
+
+// Screening is one run of a merchant against the lists. Score is that of
+// the closest hit and ListVersion identifies the lists it was run against.
+type Screening struct {
+	ID          int64          `json:"id"`
+	MerchantID  int64          `json:"merchantId"`
+	Result      string         `json:"result"`
+	Score       int            `json:"score"`
+	Hits        []ScreeningHit `json:"hits"`
+	ListVersion string         `json:"listVersion"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+type ScreeningUsecase interface {
+	Screen(ctx context.Context, m *Merchant) error
+	Fetch(ctx context.Context, merchantID int64) ([]Screening, error)
+	RunRefresh(ctx context.Context, now time.Time) error
+}
+
+type ScreeningRepository interface {
+	Store(ctx context.Context, s *Screening) error
+	Fetch(ctx context.Context, merchantID int64) ([]Screening, error)
+	Latest(ctx context.Context, merchantID int64) (Screening, error)
+}
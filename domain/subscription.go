@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	SubscriptionTrialing = "trialing"
+	SubscriptionActive   = "active"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+)
+
+const (
+	CycleProcessing = "processing"
+	CyclePaid       = "paid"
+	CycleFailed     = "failed"
+)
+
+type Plan struct {
+	ID             int64  `json:"id"`
+	MerchantID     int64  `json:"merchantId"`
+	Name           string `json:"name"`
+	Amount         int64  `json:"amount"`
+	Currency       string `json:"currency"`
+	IntervalMonths int    `json:"intervalMonths"`
+	TrialDays      int    `json:"trialDays"`
+}
+
+// Subscription bills its plan at the end of every period. Adjustment is added
+// to the next bill and is where plan change proration accumulates; a negative
+// value is a credit. Version goes up with every update, so that billing and
+// a merchant's change cannot overwrite each other.
+type Subscription struct {
+	ID                 int64     `json:"id"`
+	MerchantID         int64     `json:"merchantId"`
+	CustomerID         int64     `json:"customerId"`
+	PaymentMethodID    int64     `json:"paymentMethodId"`
+	SettingID          int64     `json:"settingId"`
+	PlanID             int64     `json:"planId"`
+	Status             string    `json:"status"`
+	CurrentPeriodStart time.Time `json:"currentPeriodStart"`
+	CurrentPeriodEnd   time.Time `json:"currentPeriodEnd"`
+	CancelAtPeriodEnd  bool      `json:"cancelAtPeriodEnd"`
+	Adjustment         int64     `json:"adjustment"`
+	FailedAttempts     int       `json:"failedAttempts"`
+	NextRetryAt        time.Time `json:"nextRetryAt"`
+	Version            int64     `json:"version"`
+}
+
+// BillingCycle records one charge attempt for one period. The pair
+// (SubscriptionID, PeriodStart, Attempt) is unique, which is what keeps a
+// restarted scheduler from charging the same period twice.
+type BillingCycle struct {
+	ID             int64     `json:"id"`
+	SubscriptionID int64     `json:"subscriptionId"`
+	PeriodStart    time.Time `json:"periodStart"`
+	Attempt        int       `json:"attempt"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	TransactionID  int64     `json:"transactionId"`
+}
+
+type SubscriptionUsecase interface {
+	StorePlan(ctx context.Context, p *Plan) error
+	FetchPlans(ctx context.Context, merchantID int64) ([]Plan, error)
+	Subscribe(ctx context.Context, s *Subscription) error
+	GetByID(ctx context.Context, merchantID int64, id int64) (Subscription, error)
+	ChangePlan(ctx context.Context, merchantID int64, id int64, planID int64) (Subscription, error)
+	Cancel(ctx context.Context, merchantID int64, id int64, atPeriodEnd bool) (Subscription, error)
+	RunBilling(ctx context.Context, now time.Time) error
+}
+
+type SubscriptionRepository interface {
+	StorePlan(ctx context.Context, p *Plan) error
+	GetPlan(ctx context.Context, id int64) (Plan, error)
+	FetchPlans(ctx context.Context, merchantID int64) ([]Plan, error)
+	Store(ctx context.Context, s *Subscription) error
+	GetByID(ctx context.Context, id int64) (Subscription, error)
+	Update(ctx context.Context, s *Subscription) error
+	FetchDue(ctx context.Context, now time.Time) ([]Subscription, error)
+	ClaimCycle(ctx context.Context, c *BillingCycle) (bool, error)
+	GetCycle(ctx context.Context, subscriptionID int64, periodStart time.Time, attempt int) (BillingCycle, error)
+	UpdateCycle(ctx context.Context, c *BillingCycle) error
+}
@@ -2,24 +2,136 @@ package domain
 
 import (
 	"context"
+	"time"
+)
+
+// A transaction is processing while a capture, refund or void of it is
+// with the processor.
+const (
+	TransactionPending    = "pending"
+	TransactionAuthorized = "authorized"
+	TransactionCaptured   = "captured"
+	TransactionDeclined   = "declined"
+	TransactionFailed     = "failed"
+	TransactionRefunded   = "refunded"
+	TransactionVoided     = "voided"
+	TransactionReview     = "review"
+	TransactionProcessing = "processing"
+)
+
+// Bounds on what a merchant can attach to a transaction. Metadata is stored
+// and returned with every transaction, so it is kept small.
+const (
+	MaxMerchantReferenceLength = 64
+	MaxMetadataKeys            = 20
+	MaxMetadataKeyLength       = 40
+	MaxMetadataValueLength     = 500
 )
 
 type Transaction struct {
-	ID                int64    `json:"id"`
-	MerchantID        int64    `json:"merchantId"`
-	ParentMerchantID  int64    `json:"parentMerchantId"`
-	SettingID         int64    `json:"settingId"`
-        Status            bool     `json:"status"`
+	ID                 int64     `json:"id"`
+	MerchantID         int64     `json:"merchantId"`
+	ParentMerchantID   int64     `json:"parentMerchantId"`
+	SettingID          int64     `json:"settingId"`
+        Status             bool      `json:"status"`
+	Amount             int64     `json:"amount"`
+	Currency           string    `json:"currency"`
+	PaymentType        string    `json:"paymentType"`
+	CustomerID         int64     `json:"customerId"`
+	PaymentMethodID    int64     `json:"paymentMethodId"`
+	CardToken          string    `json:"cardToken"`
+	CardLast4          string    `json:"cardLast4"`
+	CardBrand          string    `json:"cardBrand"`
+	CardFingerprint    string    `json:"-"`
+	State              string    `json:"state"`
+	Processor          string    `json:"processor"`
+	ProcessorReference string    `json:"processorReference"`
+	ResponseCode       string    `json:"responseCode"`
+	ResponseMessage    string    `json:"responseMessage"`
+	RoutingDecision    string    `json:"routingDecision"`
+	PaymentCode        string    `json:"paymentCode,omitempty"`
+	InstallmentPlanID  int64     `json:"installmentPlanId,omitempty"`
+	InstallmentTenor   int       `json:"installmentTenor,omitempty"`
+	Fee                int64     `json:"fee"`
+	SettlementAmount   int64     `json:"settlementAmount"`
+	PromoCode          string    `json:"promoCode,omitempty"`
+	PromotionID        int64     `json:"promotionId,omitempty"`
+	OriginalAmount     int64     `json:"originalAmount"`
+	Discount           int64     `json:"discount"`
+	SettlementCurrency string    `json:"settlementCurrency"`
+	FXQuoteID          int64     `json:"fxQuoteId,omitempty"`
+	FXRate             string    `json:"fxRate,omitempty"`
+	FXMarkup           int64     `json:"fxMarkup,omitempty"`
+	IPAddress          string    `json:"ipAddress,omitempty"`
+	IPCountry          string    `json:"ipCountry,omitempty"`
+	BillingCountry     string    `json:"billingCountry,omitempty"`
+	RiskDecision       string    `json:"riskDecision,omitempty"`
+	RiskRules          []string  `json:"riskRules,omitempty"`
+	MerchantReference  string    `json:"merchantReference,omitempty"`
+	Description        string    `json:"description,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	CreatedAt          time.Time `json:"createdAt"`
+	Version            int64     `json:"version"`
+}
+
+// TransactionPatch is a JSON Merge Patch (RFC 7396) of the fields a caller
+// may change on an existing transaction. A nil field is left as it is.
+// Metadata is merged key by key, a nil value removing the key, and
+// ClearMetadata drops all of it first. State asks for a move through the
+// state machine, as capture, refund, void or review reject would make it.
+// Version is the version the patch was made against.
+type TransactionPatch struct {
+	Description   *string
+	Metadata      map[string]*string
+	ClearMetadata bool
+	State         *string
+	Version       int64
+}
+
+// TransactionChange is one field of a transaction changed by a patch, kept
+// as its audit trail. Before and After are the field's JSON values and
+// Version the transaction's version once the change was made.
+type TransactionChange struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transactionId"`
+	Version       int64     `json:"version"`
+	Field         string    `json:"field"`
+	Before        string    `json:"before"`
+	After         string    `json:"after"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// PaymentChannel starts payments that are finished outside the gateway, such
+// as a QR the customer scans with their own app. Initiate fills in whatever
+// the customer needs to pay, and the transaction stays pending until Complete
+// is called for it.
+type PaymentChannel interface {
+	PaymentType() string
+	Initiate(ctx context.Context, t *Transaction, s Setting) error
 }
 
 type TransactionUsecase interface {
 	GetByID(ctx context.Context, id int64) (Transaction, error)
         Store(ctx context.Context, t *Transaction) error
-        Update(ctx context.Context, t *Transaction) error
+        Patch(ctx context.Context, id int64, p *TransactionPatch) (Transaction, error)
+        FetchChanges(ctx context.Context, id int64) ([]TransactionChange, error)
+        GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (Transaction, error)
+        Capture(ctx context.Context, id int64) (Transaction, error)
+        Refund(ctx context.Context, id int64) (Transaction, error)
+        Void(ctx context.Context, id int64) (Transaction, error)
+        Complete(ctx context.Context, id int64, amount int64, reference string) (Transaction, error)
+        Fail(ctx context.Context, id int64, message string) (Transaction, error)
+        FetchReviews(ctx context.Context, merchantID int64) ([]Transaction, error)
+        Approve(ctx context.Context, id int64) (Transaction, error)
+        Reject(ctx context.Context, id int64) (Transaction, error)
 }
 
 type TransactionRepository interface {
 	GetByID(ctx context.Context, id int64) (Transaction, error)
         Store(ctx context.Context, t *Transaction) error
         Update(ctx context.Context, t *Transaction) error
+        FetchByState(ctx context.Context, merchantID int64, state string) ([]Transaction, error)
+        StoreChanges(ctx context.Context, changes []TransactionChange) error
+        FetchChanges(ctx context.Context, transactionID int64) ([]TransactionChange, error)
+        GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (Transaction, error)
 }
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	VirtualAccountClosed = "closed"
+	VirtualAccountOpen   = "open"
+
+	VirtualAccountActive  = "active"
+	VirtualAccountPaid    = "paid"
+	VirtualAccountExpired = "expired"
+)
+
+// VirtualAccount is a bank account number customers transfer money to. One
+// issued for a transaction is closed-amount and can be paid once. One issued
+// for a customer is Standing: it stays active and each transfer to it becomes
+// a transaction, unless a transaction was created against its number, which
+// the next transfer pays. In open mode the customer chooses how much to send.
+// A zero ExpiresAt means the account does not expire.
+type VirtualAccount struct {
+	ID            int64     `json:"id"`
+	MerchantID    int64     `json:"merchantId"`
+	SettingID     int64     `json:"settingId"`
+	CustomerID    int64     `json:"customerId"`
+	TransactionID int64     `json:"transactionId"`
+	Bank          string    `json:"bank"`
+	Number        string    `json:"number"`
+	Mode          string    `json:"mode"`
+	Amount        int64     `json:"amount"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	Standing      bool      `json:"standing"`
+}
+
+// VirtualAccountPayment is one transfer the bank reported. Overpaid is what
+// the customer sent on top of the amount due and is owed back to them.
+type VirtualAccountPayment struct {
+	ID               int64     `json:"id"`
+	VirtualAccountID int64     `json:"virtualAccountId"`
+	TransactionID    int64     `json:"transactionId"`
+	Reference        string    `json:"reference"`
+	Amount           int64     `json:"amount"`
+	Overpaid         int64     `json:"overpaid"`
+	PaidAt           time.Time `json:"paidAt"`
+}
+
+// VANotification is the bank's callback for a transfer into a virtual
+// account. Reference is the bank's own id for the transfer.
+type VANotification struct {
+	Bank      string `json:"bank"`
+	Number    string `json:"number"`
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+}
+
+type VirtualAccountUsecase interface {
+	Store(ctx context.Context, va *VirtualAccount) error
+	GetByID(ctx context.Context, merchantID int64, id int64) (VirtualAccount, error)
+	Notify(ctx context.Context, n *VANotification) (VirtualAccountPayment, error)
+	RunExpiry(ctx context.Context, now time.Time) error
+}
+
+type VirtualAccountRepository interface {
+	Store(ctx context.Context, va *VirtualAccount) error
+	GetByID(ctx context.Context, id int64) (VirtualAccount, error)
+	GetByNumber(ctx context.Context, bank string, number string) (VirtualAccount, error)
+	Update(ctx context.Context, va *VirtualAccount) error
+	Link(ctx context.Context, id int64, transactionID int64) (bool, error)
+	Unlink(ctx context.Context, id int64, transactionID int64) error
+	FetchExpired(ctx context.Context, now time.Time) ([]VirtualAccount, error)
+	ClaimPayment(ctx context.Context, p *VirtualAccountPayment) (bool, error)
+	UpdatePayment(ctx context.Context, p *VirtualAccountPayment) error
+	ReleasePayment(ctx context.Context, id int64) error
+}
@@ -0,0 +1,63 @@
+// Package etag turns record versions into HTTP entity tags and back, so
+// that clients can make updates conditional with If-Match.
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format gives the strong entity tag for a version.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Parse reads the version out of an If-Match header holding a single
+// entity tag. If-Match compares strongly, so a weak tag, a list or "*" is
+// not a version and reports false.
+func Parse(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// Expect fills in the version an update replaces from the request's
+// If-Match header, if it has one, and reports whether it did. Otherwise
+// the version given in the body stands. A non-zero status means the
+// update cannot go ahead: 412 for an If-Match that is none of our tags,
+// 428 when there is no version at all.
+func Expect(r *http.Request, version *int64) (ifMatch bool, status int) {
+	header := r.Header.Get("If-Match")
+	if header != "" {
+		v, ok := Parse(header)
+		if !ok {
+			return true, http.StatusPreconditionFailed
+		}
+		*version = v
+		return true, 0
+	}
+	if *version == 0 {
+		return false, http.StatusPreconditionRequired
+	}
+
+	return false, 0
+}
+
+// StaleStatus is the status for an update made against an old version:
+// 412 if it was conditional on If-Match, 409 if on the body's version.
+func StaleStatus(ifMatch bool) int {
+	if ifMatch {
+		return http.StatusPreconditionFailed
+	}
+
+	return http.StatusConflict
+}
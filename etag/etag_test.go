@@ -0,0 +1,50 @@
+package etag_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/etag"
+)
+
+func TestFormatAndParse(t *testing.T) {
+	version, ok := etag.Parse(etag.Format(7))
+
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), version)
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, header := range []string{"", "*", "7", `W/"7"`, `"7", "8"`, `"0"`, `"seven"`} {
+		_, ok := etag.Parse(header)
+		assert.False(t, ok, header)
+	}
+}
+
+func TestExpect(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPut, "/transactions/1", nil)
+	version := int64(0)
+	ifMatch, status := etag.Expect(r, &version)
+	assert.False(t, ifMatch)
+	assert.Equal(t, http.StatusPreconditionRequired, status)
+
+	version = 3
+	ifMatch, status = etag.Expect(r, &version)
+	assert.False(t, ifMatch)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, http.StatusConflict, etag.StaleStatus(ifMatch))
+
+	r.Header.Set("If-Match", `"4"`)
+	ifMatch, status = etag.Expect(r, &version)
+	assert.True(t, ifMatch)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, int64(4), version)
+	assert.Equal(t, http.StatusPreconditionFailed, etag.StaleStatus(ifMatch))
+
+	r.Header.Set("If-Match", "*")
+	_, status = etag.Expect(r, &version)
+	assert.Equal(t, http.StatusPreconditionFailed, status)
+}
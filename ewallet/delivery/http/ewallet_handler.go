@@ -0,0 +1,83 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/ewallet/provider"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type EWalletHandler struct {
+	Usecase domain.EWalletUsecase
+}
+
+func NewEWalletHandler(e *echo.Echo, u domain.EWalletUsecase) *EWalletHandler {
+	handler := &EWalletHandler{
+		Usecase: u,
+	}
+
+	e.GET("/ewallet/payments/:transactionId", handler.GetByTransaction)
+	e.POST("/ewallet/callbacks/:provider", handler.Callback)
+
+	return handler
+}
+
+func (h *EWalletHandler) GetByTransaction(c echo.Context) error {
+	transactionID, err := strconv.ParseInt(c.Param("transactionId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByTransaction(ctx, merchantID, transactionID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Callback takes the raw body because the signature covers its exact bytes.
+func (h *EWalletHandler) Callback(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil || len(body) == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Callback(ctx, c.Param("provider"), body, c.Request().Header.Get(provider.SignatureHeader))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrInvalidSignature:
+		return c.JSON(http.StatusUnauthorized, ResponseError{Message: err.Error()})
+	case domain.ErrAmountMismatch:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrInvalidState:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	case domain.ErrProvider:
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	ewalletHttp "github.com/hezbymuhammad/payment-gateway/ewallet/delivery/http"
+)
+
+const body = `{"reference":"dana-1","partnerReference":"TX4","status":"SUCCESS","amount":50000}`
+
+func TestCallback(t *testing.T) {
+	mockUsecase := new(mocks.EWalletUsecase)
+	mockUsecase.On("Callback", mock.Anything, "DANA", []byte(body), "abc").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/ewallet/callbacks/DANA", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Signature", "abc")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/ewallet/callbacks/:provider")
+	ctx.SetParamNames("provider")
+	ctx.SetParamValues("DANA")
+
+	handler := ewalletHttp.NewEWalletHandler(echo.New(), mockUsecase)
+	err = handler.Callback(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCallbackBadSignature(t *testing.T) {
+	mockUsecase := new(mocks.EWalletUsecase)
+	mockUsecase.On("Callback", mock.Anything, "DANA", mock.Anything, "").Return(domain.Transaction{}, domain.ErrInvalidSignature).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/ewallet/callbacks/DANA", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/ewallet/callbacks/:provider")
+	ctx.SetParamNames("provider")
+	ctx.SetParamValues("DANA")
+
+	handler := ewalletHttp.NewEWalletHandler(echo.New(), mockUsecase)
+	err = handler.Callback(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestGetByTransaction(t *testing.T) {
+	mockUsecase := new(mocks.EWalletUsecase)
+	mockUsecase.On("GetByTransaction", mock.Anything, int64(6), int64(4)).Return(domain.EWalletPayment{ID: 1, TransactionID: 4, Deeplink: "dana://pay/dana-1"}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/ewallet/payments/4?merchantId=6", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/ewallet/payments/:transactionId")
+	ctx.SetParamNames("transactionId")
+	ctx.SetParamValues("4")
+
+	handler := ewalletHttp.NewEWalletHandler(echo.New(), mockUsecase)
+	err = handler.GetByTransaction(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "dana://pay/dana-1")
+}
@@ -0,0 +1,178 @@
+// Package provider talks to e-wallet providers over their HTTP APIs.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the
+// shared secret, on requests in both directions.
+const SignatureHeader = "X-Signature"
+
+var statuses = map[string]string{
+	"PENDING": domain.EWalletPending,
+	"SUCCESS": domain.EWalletPaid,
+	"FAILED":  domain.EWalletFailed,
+	"EXPIRED": domain.EWalletFailed,
+}
+
+// Config points a provider at its API. Secret signs our requests and
+// authenticates its callbacks.
+type Config struct {
+	BaseURL string
+	Secret  string
+	Timeout time.Duration
+}
+
+type chargeRequest struct {
+	PartnerReference string `json:"partnerReference"`
+	Amount           int64  `json:"amount"`
+	Currency         string `json:"currency"`
+}
+
+type paymentResponse struct {
+	Reference        string `json:"reference"`
+	PartnerReference string `json:"partnerReference"`
+	CheckoutURL      string `json:"checkoutUrl"`
+	Deeplink         string `json:"deeplink"`
+	Status           string `json:"status"`
+	Amount           int64  `json:"amount"`
+}
+
+type httpProvider struct {
+	name   string
+	cfg    Config
+	client *http.Client
+}
+
+// NewHTTPProvider returns an adapter for a provider that speaks the common
+// redirect API: POST /payments opens a payment, GET /payments/:reference
+// reports on it and signed callbacks announce the outcome.
+func NewHTTPProvider(name string, cfg Config) domain.EWalletProvider {
+	return &httpProvider{
+		name:   name,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (p *httpProvider) Name() string {
+	return p.name
+}
+
+func (p *httpProvider) Charge(ctx context.Context, c *domain.EWalletCharge) (domain.EWalletPayment, error) {
+	body, err := json.Marshal(chargeRequest{
+		PartnerReference: c.PartnerReference,
+		Amount:           c.Amount,
+		Currency:         c.Currency,
+	})
+	if err != nil {
+		return domain.EWalletPayment{}, err
+	}
+
+	res, err := p.do(ctx, http.MethodPost, "/payments", body)
+	if err != nil {
+		return domain.EWalletPayment{}, err
+	}
+	status, ok := statuses[res.Status]
+	if !ok || res.Reference == "" || res.CheckoutURL == "" {
+		log.Printf("%s: unexpected charge response %+v", p.name, res)
+		return domain.EWalletPayment{}, domain.ErrProvider
+	}
+
+	return domain.EWalletPayment{
+		Provider:    p.name,
+		Reference:   res.Reference,
+		CheckoutURL: res.CheckoutURL,
+		Deeplink:    res.Deeplink,
+		Status:      status,
+	}, nil
+}
+
+func (p *httpProvider) Status(ctx context.Context, reference string) (domain.EWalletStatus, error) {
+	res, err := p.do(ctx, http.MethodGet, "/payments/"+url.PathEscape(reference), nil)
+	if err != nil {
+		return domain.EWalletStatus{}, err
+	}
+
+	return p.status(res)
+}
+
+func (p *httpProvider) ParseCallback(body []byte, signature string) (domain.EWalletStatus, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sign(body)) {
+		return domain.EWalletStatus{}, domain.ErrInvalidSignature
+	}
+
+	var res paymentResponse
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return domain.EWalletStatus{}, domain.ErrProvider
+	}
+
+	return p.status(res)
+}
+
+func (p *httpProvider) status(res paymentResponse) (domain.EWalletStatus, error) {
+	status, ok := statuses[res.Status]
+	if !ok || res.Reference == "" {
+		log.Printf("%s: unexpected payment status %+v", p.name, res)
+		return domain.EWalletStatus{}, domain.ErrProvider
+	}
+
+	return domain.EWalletStatus{
+		Reference:        res.Reference,
+		PartnerReference: res.PartnerReference,
+		Status:           status,
+		Amount:           res.Amount,
+	}, nil
+}
+
+// do sends a signed request and decodes the JSON reply. Transport failures
+// and non-2xx answers are logged and reported as ErrProvider.
+func (p *httpProvider) do(ctx context.Context, method string, path string, body []byte) (paymentResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.cfg.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return paymentResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, hex.EncodeToString(p.sign(body)))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("%s: %v", p.name, err)
+		return paymentResponse{}, domain.ErrProvider
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Printf("%s: %s %s returned %d", p.name, method, path, resp.StatusCode)
+		return paymentResponse{}, domain.ErrProvider
+	}
+
+	var res paymentResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		log.Printf("%s: %v", p.name, err)
+		return paymentResponse{}, domain.ErrProvider
+	}
+
+	return res, nil
+}
+
+func (p *httpProvider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(p.cfg.Secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
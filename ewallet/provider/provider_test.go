@@ -0,0 +1,124 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/ewallet/provider"
+)
+
+const secret = "stub-secret"
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// stubProvider answers like a redirect e-wallet and refuses unsigned calls.
+func stubProvider(t *testing.T, status string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if r.Header.Get(provider.SignatureHeader) != sign(body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/payments":
+			var req map[string]interface{}
+			assert.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, "TX4", req["partnerReference"])
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"reference":   "dana-1",
+				"checkoutUrl": "https://wallet.example.com/pay/dana-1",
+				"deeplink":    "dana://pay/dana-1",
+				"status":      "PENDING",
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/payments/dana-1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"reference":        "dana-1",
+				"partnerReference": "TX4",
+				"status":           status,
+				"amount":           50000,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCharge(t *testing.T) {
+	srv := stubProvider(t, "PENDING")
+	defer srv.Close()
+	p := provider.NewHTTPProvider("DANA", provider.Config{BaseURL: srv.URL, Secret: secret, Timeout: time.Second})
+
+	res, err := p.Charge(context.TODO(), &domain.EWalletCharge{PartnerReference: "TX4", Amount: 50000, Currency: "IDR"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DANA", res.Provider)
+	assert.Equal(t, "dana-1", res.Reference)
+	assert.Equal(t, "https://wallet.example.com/pay/dana-1", res.CheckoutURL)
+	assert.Equal(t, "dana://pay/dana-1", res.Deeplink)
+	assert.Equal(t, domain.EWalletPending, res.Status)
+}
+
+func TestChargeWithWrongSecret(t *testing.T) {
+	srv := stubProvider(t, "PENDING")
+	defer srv.Close()
+	p := provider.NewHTTPProvider("DANA", provider.Config{BaseURL: srv.URL, Secret: "wrong", Timeout: time.Second})
+
+	_, err := p.Charge(context.TODO(), &domain.EWalletCharge{PartnerReference: "TX4", Amount: 50000, Currency: "IDR"})
+	assert.Equal(t, domain.ErrProvider, err)
+}
+
+func TestChargeProviderDown(t *testing.T) {
+	srv := stubProvider(t, "PENDING")
+	srv.Close()
+	p := provider.NewHTTPProvider("DANA", provider.Config{BaseURL: srv.URL, Secret: secret, Timeout: time.Second})
+
+	_, err := p.Charge(context.TODO(), &domain.EWalletCharge{PartnerReference: "TX4", Amount: 50000, Currency: "IDR"})
+	assert.Equal(t, domain.ErrProvider, err)
+}
+
+func TestStatus(t *testing.T) {
+	srv := stubProvider(t, "EXPIRED")
+	defer srv.Close()
+	p := provider.NewHTTPProvider("DANA", provider.Config{BaseURL: srv.URL, Secret: secret, Timeout: time.Second})
+
+	res, err := p.Status(context.TODO(), "dana-1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EWalletFailed, res.Status)
+	assert.Equal(t, "TX4", res.PartnerReference)
+}
+
+func TestParseCallback(t *testing.T) {
+	p := provider.NewHTTPProvider("DANA", provider.Config{Secret: secret})
+	body := []byte(`{"reference":"dana-1","partnerReference":"TX4","status":"SUCCESS","amount":50000}`)
+
+	res, err := p.ParseCallback(body, sign(body))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EWalletPaid, res.Status)
+	assert.Equal(t, int64(50000), res.Amount)
+}
+
+func TestParseCallbackTampered(t *testing.T) {
+	p := provider.NewHTTPProvider("DANA", provider.Config{Secret: secret})
+	body := []byte(`{"reference":"dana-1","partnerReference":"TX4","status":"SUCCESS","amount":50000}`)
+	tampered := []byte(`{"reference":"dana-1","partnerReference":"TX4","status":"SUCCESS","amount":5000000}`)
+
+	_, err := p.ParseCallback(tampered, sign(body))
+	assert.Equal(t, domain.ErrInvalidSignature, err)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	ewalletRepo "github.com/hezbymuhammad/payment-gateway/ewallet/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.EWalletRepository(t, func(t *testing.T) domain.EWalletRepository {
+		return ewalletRepo.NewEWalletRepository(migrationtest.NewPostgresDB(t))
+	})
+}
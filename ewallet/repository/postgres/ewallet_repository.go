@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const paymentColumns = "id, transaction_id, provider, reference, checkout_url, deeplink, status, created_at"
+
+type postgresEWalletRepo struct {
+	DB *sql.DB
+}
+
+func NewEWalletRepository(db *sql.DB) domain.EWalletRepository {
+	return &postgresEWalletRepo{
+		DB: db,
+	}
+}
+
+func (er *postgresEWalletRepo) Store(ctx context.Context, p *domain.EWalletPayment) error {
+	query := "INSERT INTO ewallet_payments (transaction_id, provider, reference, checkout_url, deeplink, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
+	err := er.DB.QueryRowContext(ctx, query, p.TransactionID, p.Provider, p.Reference, p.CheckoutURL, p.Deeplink, p.Status, p.CreatedAt).Scan(&p.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (er *postgresEWalletRepo) GetByTransaction(ctx context.Context, transactionID int64) (domain.EWalletPayment, error) {
+	query := "SELECT " + paymentColumns + " FROM ewallet_payments WHERE transaction_id=$1"
+
+	data, err := scanPayment(er.DB.QueryRowContext(ctx, query, transactionID))
+	if err == sql.ErrNoRows {
+		return domain.EWalletPayment{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.EWalletPayment{}, err
+	}
+
+	return data, nil
+}
+
+func (er *postgresEWalletRepo) Update(ctx context.Context, p *domain.EWalletPayment) error {
+	query := "UPDATE ewallet_payments SET status=$1 WHERE id=$2"
+
+	_, err := er.DB.ExecContext(ctx, query, p.Status, p.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+	}
+
+	return err
+}
+
+// FetchPending returns payments still waiting on the provider that were
+// opened before the given time, oldest first.
+func (er *postgresEWalletRepo) FetchPending(ctx context.Context, before time.Time) ([]domain.EWalletPayment, error) {
+	query := "SELECT " + paymentColumns + " FROM ewallet_payments WHERE status='pending' AND created_at <= $1 ORDER BY created_at"
+
+	rows, err := er.DB.QueryContext(ctx, query, before)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.EWalletPayment{}
+	for rows.Next() {
+		data, err := scanPayment(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row scanner) (domain.EWalletPayment, error) {
+	data := domain.EWalletPayment{}
+	err := row.Scan(
+		&data.ID,
+		&data.TransactionID,
+		&data.Provider,
+		&data.Reference,
+		&data.CheckoutURL,
+		&data.Deeplink,
+		&data.Status,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return domain.EWalletPayment{}, err
+	}
+
+	data.CreatedAt = data.CreatedAt.UTC()
+	return data, nil
+}
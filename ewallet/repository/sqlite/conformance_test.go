@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	ewalletRepo "github.com/hezbymuhammad/payment-gateway/ewallet/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.EWalletRepository(t, func(t *testing.T) domain.EWalletRepository {
+		return ewalletRepo.NewEWalletRepository(migrationtest.NewDB(t))
+	})
+}
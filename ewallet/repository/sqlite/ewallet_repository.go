@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const paymentColumns = "id, transaction_id, provider, reference, checkout_url, deeplink, status, created_at"
+
+type sqliteEWalletRepo struct {
+	DB *sql.DB
+}
+
+func NewEWalletRepository(db *sql.DB) domain.EWalletRepository {
+	return &sqliteEWalletRepo{
+		DB: db,
+	}
+}
+
+func (er *sqliteEWalletRepo) Store(ctx context.Context, p *domain.EWalletPayment) error {
+	query := "INSERT INTO ewallet_payments (transaction_id, provider, reference, checkout_url, deeplink, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := er.exec(ctx, query, p.TransactionID, p.Provider, p.Reference, p.CheckoutURL, p.Deeplink, p.Status, p.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	p.ID = lastID
+	return nil
+}
+
+func (er *sqliteEWalletRepo) GetByTransaction(ctx context.Context, transactionID int64) (domain.EWalletPayment, error) {
+	query := "SELECT " + paymentColumns + " FROM ewallet_payments WHERE transaction_id=? LIMIT 1"
+
+	data, err := scanPayment(er.DB.QueryRowContext(ctx, query, transactionID))
+	if err == sql.ErrNoRows {
+		return domain.EWalletPayment{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.EWalletPayment{}, err
+	}
+
+	return data, nil
+}
+
+func (er *sqliteEWalletRepo) Update(ctx context.Context, p *domain.EWalletPayment) error {
+	query := "UPDATE ewallet_payments SET status=? WHERE id=?"
+
+	_, err := er.exec(ctx, query, p.Status, p.ID)
+	return err
+}
+
+// FetchPending returns payments still waiting on the provider that were
+// opened before the given time, oldest first.
+func (er *sqliteEWalletRepo) FetchPending(ctx context.Context, before time.Time) ([]domain.EWalletPayment, error) {
+	query := "SELECT " + paymentColumns + " FROM ewallet_payments WHERE status='pending' AND created_at <= ? ORDER BY created_at"
+
+	rows, err := er.DB.QueryContext(ctx, query, before)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.EWalletPayment{}
+	for rows.Next() {
+		data, err := scanPayment(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row scanner) (domain.EWalletPayment, error) {
+	data := domain.EWalletPayment{}
+	err := row.Scan(
+		&data.ID,
+		&data.TransactionID,
+		&data.Provider,
+		&data.Reference,
+		&data.CheckoutURL,
+		&data.Deeplink,
+		&data.Status,
+		&data.CreatedAt,
+	)
+
+	return data, err
+}
+
+func (er *sqliteEWalletRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := er.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	ewalletRepo "github.com/hezbymuhammad/payment-gateway/ewallet/repository/sqlite"
+)
+
+var now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+var paymentColumns = []string{"id", "transaction_id", "provider", "reference", "checkout_url", "deeplink", "status", "created_at"}
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.EWalletPayment{TransactionID: 4, Provider: "DANA", Reference: "dana-1", CheckoutURL: "https://wallet.example.com/pay/dana-1", Status: domain.EWalletPending, CreatedAt: now}
+	query := regexp.QuoteMeta("INSERT INTO ewallet_payments (transaction_id, provider, reference, checkout_url, deeplink, status, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(4, "DANA", "dana-1", data.CheckoutURL, "", domain.EWalletPending, now).WillReturnResult(sqlmock.NewResult(1, 1))
+	er := ewalletRepo.NewEWalletRepository(db)
+
+	err = er.Store(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), data.ID)
+}
+
+func TestGetByTransactionNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("SELECT id, transaction_id, provider, reference, checkout_url, deeplink, status, created_at FROM ewallet_payments WHERE transaction_id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(4).WillReturnRows(sqlmock.NewRows(paymentColumns))
+	er := ewalletRepo.NewEWalletRepository(db)
+
+	_, err = er.GetByTransaction(context.TODO(), 4)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestFetchPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(paymentColumns).
+		AddRow(1, 4, "DANA", "dana-1", "https://wallet.example.com/pay/dana-1", "dana://pay/dana-1", domain.EWalletPending, now.Add(-time.Hour))
+	query := regexp.QuoteMeta("SELECT id, transaction_id, provider, reference, checkout_url, deeplink, status, created_at FROM ewallet_payments WHERE status='pending' AND created_at <= ? ORDER BY created_at")
+	mock.ExpectQuery(query).WithArgs(now).WillReturnRows(rows)
+	er := ewalletRepo.NewEWalletRepository(db)
+
+	res, err := er.FetchPending(context.TODO(), now)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "dana://pay/dana-1", res[0].Deeplink)
+}
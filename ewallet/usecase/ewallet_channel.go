@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// PaymentType is the setting and transaction payment type paid with an
+// e-wallet. An EWALLET setting names its provider in PaymentName.
+const PaymentType = "EWALLET"
+
+type ewalletChannel struct {
+	ewalletRepo domain.EWalletRepository
+	providers   map[string]domain.EWalletProvider
+}
+
+// NewEWalletChannel returns the payment channel that opens a payment with the
+// setting's e-wallet provider and hands back its checkout URL.
+func NewEWalletChannel(er domain.EWalletRepository, providers map[string]domain.EWalletProvider) domain.PaymentChannel {
+	return &ewalletChannel{
+		ewalletRepo: er,
+		providers:   providers,
+	}
+}
+
+func (c *ewalletChannel) PaymentType() string {
+	return PaymentType
+}
+
+func (c *ewalletChannel) Initiate(ctx context.Context, t *domain.Transaction, s domain.Setting) error {
+	provider, ok := c.providers[s.PaymentName]
+	if !ok {
+		return domain.ErrPaymentMethod
+	}
+
+	p, err := provider.Charge(ctx, &domain.EWalletCharge{
+		PartnerReference: partnerReference(t.ID),
+		Amount:           t.Amount,
+		Currency:         t.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.TransactionID = t.ID
+	p.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	err = c.ewalletRepo.Store(ctx, &p)
+	if err != nil {
+		return err
+	}
+
+	t.PaymentCode = p.CheckoutURL
+	t.Processor = "ewallet"
+	t.ProcessorReference = p.Reference
+	return nil
+}
+
+func partnerReference(id int64) string {
+	return fmt.Sprintf("TX%d", id)
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Config holds the e-wallet settings that come from config.json. Payments
+// still pending PollAfter after they were opened are checked with the
+// provider in case their callback was lost. One the provider still reports
+// as pending once its provider's TTL has passed is failed.
+type Config struct {
+	PollAfter time.Duration
+	TTL       map[string]time.Duration
+}
+
+type ewalletUsecase struct {
+	ewalletRepo  domain.EWalletRepository
+	transactions domain.TransactionUsecase
+	providers    map[string]domain.EWalletProvider
+	cfg          Config
+}
+
+func NewEWalletUsecase(er domain.EWalletRepository, tu domain.TransactionUsecase, providers map[string]domain.EWalletProvider, cfg Config) domain.EWalletUsecase {
+	return &ewalletUsecase{
+		ewalletRepo:  er,
+		transactions: tu,
+		providers:    providers,
+		cfg:          cfg,
+	}
+}
+
+func (eu *ewalletUsecase) GetByTransaction(ctx context.Context, merchantID int64, transactionID int64) (domain.EWalletPayment, error) {
+	t, err := eu.transactions.GetByID(ctx, transactionID)
+	if err != nil {
+		return domain.EWalletPayment{}, err
+	}
+	if t.MerchantID != merchantID {
+		return domain.EWalletPayment{}, domain.ErrNotFound
+	}
+
+	return eu.ewalletRepo.GetByTransaction(ctx, transactionID)
+}
+
+// Callback applies a provider's signed notification to the payment it names.
+// Repeated callbacks for a settled payment just return its transaction.
+func (eu *ewalletUsecase) Callback(ctx context.Context, provider string, body []byte, signature string) (domain.Transaction, error) {
+	pr, ok := eu.providers[provider]
+	if !ok {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+	st, err := pr.ParseCallback(body, signature)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(st.PartnerReference, "TX"), 10, 64)
+	if err != nil || !strings.HasPrefix(st.PartnerReference, "TX") {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+	p, err := eu.ewalletRepo.GetByTransaction(ctx, id)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if p.Provider != provider || p.Reference != st.Reference {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+
+	return eu.apply(ctx, &p, st)
+}
+
+// RunPolling asks providers about payments whose callback is overdue.
+func (eu *ewalletUsecase) RunPolling(ctx context.Context, now time.Time) error {
+	pending, err := eu.ewalletRepo.FetchPending(ctx, now.UTC().Add(-eu.cfg.PollAfter))
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for i := range pending {
+		p := pending[i]
+		err = eu.poll(ctx, &p, now)
+		if err != nil {
+			log.Printf("ewallet payment %d: %v", p.ID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (eu *ewalletUsecase) poll(ctx context.Context, p *domain.EWalletPayment, now time.Time) error {
+	pr, ok := eu.providers[p.Provider]
+	if !ok {
+		return domain.ErrPaymentMethod
+	}
+	st, err := pr.Status(ctx, p.Reference)
+	if err != nil {
+		return err
+	}
+
+	ttl := eu.cfg.TTL[p.Provider]
+	if st.Status == domain.EWalletPending && ttl > 0 && !now.Before(p.CreatedAt.Add(ttl)) {
+		return eu.expire(ctx, p)
+	}
+
+	_, err = eu.apply(ctx, p, st)
+	return err
+}
+
+// expire fails a payment the customer did not finish in time, which gives
+// back what its transaction reserved. A transaction that was settled in the
+// meantime is left for its callback.
+func (eu *ewalletUsecase) expire(ctx context.Context, p *domain.EWalletPayment) error {
+	t, err := eu.transactions.Fail(ctx, p.TransactionID, "E-wallet payment expired")
+	if err != nil {
+		return err
+	}
+	if t.State != domain.TransactionFailed {
+		return nil
+	}
+
+	p.Status = domain.EWalletFailed
+	return eu.ewalletRepo.Update(ctx, p)
+}
+
+// apply moves a pending payment and its transaction to the provider's
+// outcome. A payment the provider still reports as pending is left alone.
+func (eu *ewalletUsecase) apply(ctx context.Context, p *domain.EWalletPayment, st domain.EWalletStatus) (domain.Transaction, error) {
+	if p.Status != domain.EWalletPending {
+		return eu.transactions.GetByID(ctx, p.TransactionID)
+	}
+
+	switch st.Status {
+	case domain.EWalletPaid:
+		t, err := eu.transactions.Complete(ctx, p.TransactionID, st.Amount, st.Reference)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		p.Status = domain.EWalletPaid
+		return t, eu.ewalletRepo.Update(ctx, p)
+	case domain.EWalletFailed:
+		t, err := eu.transactions.Fail(ctx, p.TransactionID, "E-wallet payment failed")
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		p.Status = domain.EWalletFailed
+		return t, eu.ewalletRepo.Update(ctx, p)
+	default:
+		return eu.transactions.GetByID(ctx, p.TransactionID)
+	}
+}
@@ -0,0 +1,227 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/ewallet/provider"
+	ewalletUsecase "github.com/hezbymuhammad/payment-gateway/ewallet/usecase"
+)
+
+var cfg = ewalletUsecase.Config{PollAfter: 5 * time.Minute, TTL: map[string]time.Duration{"DANA": 30 * time.Minute}}
+
+var pending = domain.EWalletPayment{ID: 1, TransactionID: 4, Provider: "DANA", Reference: "dana-1", Status: domain.EWalletPending}
+
+// stubProvider is a local DANA that opens payment dana-1 and reports it with
+// the given status. It does not check signatures; the provider tests do.
+func stubProvider(status string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"reference":        "dana-1",
+			"partnerReference": "TX4",
+			"checkoutUrl":      "https://wallet.example.com/pay/dana-1",
+			"status":           status,
+			"amount":           50000,
+		})
+	}))
+}
+
+func providers(srv *httptest.Server) map[string]domain.EWalletProvider {
+	return map[string]domain.EWalletProvider{
+		"DANA": provider.NewHTTPProvider("DANA", provider.Config{BaseURL: srv.URL, Secret: "stub-secret", Timeout: time.Second}),
+	}
+}
+
+func TestInitiate(t *testing.T) {
+	srv := stubProvider("PENDING")
+	defer srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockRepo.On("Store", mock.Anything, mock.MatchedBy(func(p *domain.EWalletPayment) bool {
+		return p.TransactionID == 4 && p.Reference == "dana-1" && !p.CreatedAt.IsZero()
+	})).Return(nil).Once()
+	c := ewalletUsecase.NewEWalletChannel(mockRepo, providers(srv))
+	data := domain.Transaction{ID: 4, MerchantID: 6, Amount: 50000, Currency: "IDR"}
+
+	err := c.Initiate(context.TODO(), &data, domain.Setting{ID: 9, MerchantID: 6, PaymentType: "EWALLET", PaymentName: "DANA"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://wallet.example.com/pay/dana-1", data.PaymentCode)
+	assert.Equal(t, "dana-1", data.ProcessorReference)
+}
+
+func TestInitiateUnknownProvider(t *testing.T) {
+	srv := stubProvider("PENDING")
+	defer srv.Close()
+	c := ewalletUsecase.NewEWalletChannel(new(mocks.EWalletRepository), providers(srv))
+	data := domain.Transaction{ID: 4, MerchantID: 6, Amount: 50000, Currency: "IDR"}
+
+	err := c.Initiate(context.TODO(), &data, domain.Setting{ID: 9, MerchantID: 6, PaymentType: "EWALLET", PaymentName: "GOPAY"})
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+}
+
+func TestCallbackPaid(t *testing.T) {
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockProvider := new(mocks.EWalletProvider)
+	mockProvider.On("ParseCallback", []byte("body"), "sig").Return(domain.EWalletStatus{Reference: "dana-1", PartnerReference: "TX4", Status: domain.EWalletPaid, Amount: 50000}, nil).Once()
+	mockRepo.On("GetByTransaction", mock.Anything, int64(4)).Return(pending, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(4), int64(50000), "dana-1").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.EWalletPayment) bool {
+		return p.Status == domain.EWalletPaid
+	})).Return(nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, map[string]domain.EWalletProvider{"DANA": mockProvider}, cfg)
+
+	res, err := u.Callback(context.TODO(), "DANA", []byte("body"), "sig")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TransactionCaptured, res.State)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCallbackRepeated(t *testing.T) {
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockProvider := new(mocks.EWalletProvider)
+	paid := pending
+	paid.Status = domain.EWalletPaid
+	mockProvider.On("ParseCallback", mock.Anything, mock.Anything).Return(domain.EWalletStatus{Reference: "dana-1", PartnerReference: "TX4", Status: domain.EWalletPaid, Amount: 50000}, nil).Once()
+	mockRepo.On("GetByTransaction", mock.Anything, int64(4)).Return(paid, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, map[string]domain.EWalletProvider{"DANA": mockProvider}, cfg)
+
+	res, err := u.Callback(context.TODO(), "DANA", []byte("body"), "sig")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TransactionCaptured, res.State)
+	mockTransactions.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCallbackForAnotherProvidersPayment(t *testing.T) {
+	mockRepo := new(mocks.EWalletRepository)
+	mockProvider := new(mocks.EWalletProvider)
+	mockProvider.On("ParseCallback", mock.Anything, mock.Anything).Return(domain.EWalletStatus{Reference: "ovo-7", PartnerReference: "TX4", Status: domain.EWalletPaid, Amount: 50000}, nil).Once()
+	mockRepo.On("GetByTransaction", mock.Anything, int64(4)).Return(pending, nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, new(mocks.TransactionUsecase), map[string]domain.EWalletProvider{"OVO": mockProvider}, cfg)
+
+	_, err := u.Callback(context.TODO(), "OVO", []byte("body"), "sig")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestCallbackBadSignature(t *testing.T) {
+	mockProvider := new(mocks.EWalletProvider)
+	mockProvider.On("ParseCallback", mock.Anything, mock.Anything).Return(domain.EWalletStatus{}, domain.ErrInvalidSignature).Once()
+	u := ewalletUsecase.NewEWalletUsecase(new(mocks.EWalletRepository), new(mocks.TransactionUsecase), map[string]domain.EWalletProvider{"DANA": mockProvider}, cfg)
+
+	_, err := u.Callback(context.TODO(), "DANA", []byte("body"), "sig")
+	assert.Equal(t, domain.ErrInvalidSignature, err)
+}
+
+func TestRunPollingPaid(t *testing.T) {
+	srv := stubProvider("SUCCESS")
+	defer srv.Close()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchPending", mock.Anything, now.Add(-5*time.Minute)).Return([]domain.EWalletPayment{pending}, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(4), int64(50000), "dana-1").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.EWalletPayment) bool {
+		return p.Status == domain.EWalletPaid
+	})).Return(nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), now)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunPollingFailed(t *testing.T) {
+	srv := stubProvider("EXPIRED")
+	defer srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchPending", mock.Anything, mock.Anything).Return([]domain.EWalletPayment{pending}, nil).Once()
+	mockTransactions.On("Fail", mock.Anything, int64(4), "E-wallet payment failed").Return(domain.Transaction{ID: 4, State: domain.TransactionFailed}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.EWalletPayment) bool {
+		return p.Status == domain.EWalletFailed
+	})).Return(nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), time.Now())
+	assert.NoError(t, err)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestRunPollingStillPending(t *testing.T) {
+	srv := stubProvider("PENDING")
+	defer srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	recent := pending
+	recent.CreatedAt = now.Add(-10 * time.Minute)
+	mockRepo.On("FetchPending", mock.Anything, mock.Anything).Return([]domain.EWalletPayment{recent}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionPending}, nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), now)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRunPollingExpired(t *testing.T) {
+	srv := stubProvider("PENDING")
+	defer srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	stale := pending
+	stale.CreatedAt = now.Add(-30 * time.Minute)
+	mockRepo.On("FetchPending", mock.Anything, mock.Anything).Return([]domain.EWalletPayment{stale}, nil).Once()
+	mockTransactions.On("Fail", mock.Anything, int64(4), "E-wallet payment expired").Return(domain.Transaction{ID: 4, State: domain.TransactionFailed}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.EWalletPayment) bool {
+		return p.Status == domain.EWalletFailed
+	})).Return(nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), now)
+	assert.NoError(t, err)
+	mockTransactions.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunPollingExpiredAfterPaid(t *testing.T) {
+	srv := stubProvider("PENDING")
+	defer srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	stale := pending
+	stale.CreatedAt = now.Add(-time.Hour)
+	mockRepo.On("FetchPending", mock.Anything, mock.Anything).Return([]domain.EWalletPayment{stale}, nil).Once()
+	mockTransactions.On("Fail", mock.Anything, int64(4), "E-wallet payment expired").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, mockTransactions, providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), now)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRunPollingProviderDown(t *testing.T) {
+	srv := stubProvider("PENDING")
+	srv.Close()
+	mockRepo := new(mocks.EWalletRepository)
+	mockRepo.On("FetchPending", mock.Anything, mock.Anything).Return([]domain.EWalletPayment{pending}, nil).Once()
+	u := ewalletUsecase.NewEWalletUsecase(mockRepo, new(mocks.TransactionUsecase), providers(srv), cfg)
+
+	err := u.RunPolling(context.TODO(), time.Now())
+	assert.Equal(t, domain.ErrProvider, err)
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/fx"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type FXHandler struct {
+	Usecase domain.FXUsecase
+}
+
+func NewFXHandler(e *echo.Echo, u domain.FXUsecase) *FXHandler {
+	handler := &FXHandler{
+		Usecase: u,
+	}
+
+	e.POST("/fx/rates", handler.StoreRates)
+	e.GET("/fx/rates", handler.FetchRates)
+	e.POST("/fx/quotes", handler.Quote)
+
+	return handler
+}
+
+// StoreRates takes a rate table in the same shape as the rates file, a JSON
+// object of currency to rate.
+func (h *FXHandler) StoreRates(c echo.Context) error {
+	ctx := c.Request().Context()
+	table := map[string]string{}
+	err := c.Bind(&table)
+	if err != nil || len(table) == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	rates, err := fx.Rates(table)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err = h.Usecase.StoreRates(ctx, rates)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, rates)
+}
+
+func (h *FXHandler) FetchRates(c echo.Context) error {
+	ctx := c.Request().Context()
+	res, err := h.Usecase.FetchRates(ctx)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *FXHandler) Quote(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.FXQuote
+	c.Bind(&data)
+	data.Currency = strings.ToUpper(data.Currency)
+	if data.MerchantID == 0 || data.Amount <= 0 || len(data.Currency) != 3 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Quote(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrCurrency:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
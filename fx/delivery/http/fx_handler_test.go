@@ -0,0 +1,74 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	fxHttp "github.com/hezbymuhammad/payment-gateway/fx/delivery/http"
+)
+
+func TestStoreRates(t *testing.T) {
+	mockUsecase := new(mocks.FXUsecase)
+	mockUsecase.On("StoreRates", mock.Anything, []domain.FXRate{{Currency: "USD", Rate: "16250.00"}}).Return(nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/fx/rates", strings.NewReader(`{"usd":"16250.00"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := fxHttp.NewFXHandler(echo.New(), mockUsecase)
+	err = handler.StoreRates(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestStoreRatesInvalidRate(t *testing.T) {
+	mockUsecase := new(mocks.FXUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/fx/rates", strings.NewReader(`{"USD":"-3"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := fxHttp.NewFXHandler(echo.New(), mockUsecase)
+	err = handler.StoreRates(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "StoreRates", mock.Anything, mock.Anything)
+}
+
+func TestQuoteUnsupportedCurrency(t *testing.T) {
+	mockUsecase := new(mocks.FXUsecase)
+	mockUsecase.On("Quote", mock.Anything, mock.AnythingOfType("*domain.FXQuote")).Return(domain.ErrCurrency).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/fx/quotes", strings.NewReader(`{"merchantId":6,"currency":"eur","amount":1000}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := fxHttp.NewFXHandler(echo.New(), mockUsecase)
+	err = handler.Quote(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
@@ -0,0 +1,104 @@
+// Package fx converts amounts between currencies at exact decimal rates.
+// Amounts are integers in each currency's minor unit; rates are decimal
+// strings giving the settlement currency price of one major unit.
+package fx
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// exponents lists currencies whose minor unit is not a hundredth of the
+// major one. The rupiah is handled in whole units throughout the gateway.
+var exponents = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+// Exponent is the number of decimal places the currency's minor unit has.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+
+	return 2
+}
+
+// ParseRate reads a positive decimal rate such as "16250.50".
+func ParseRate(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() <= 0 || strings.ContainsAny(s, "/eE") {
+		return nil, domain.ErrCurrency
+	}
+
+	return r, nil
+}
+
+// Convert turns amount, in the minor unit of currency, into the settlement
+// currency at rate less a markup in basis points, rounding half up.
+func Convert(amount int64, currency string, rate string, markup int64) (int64, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	v := new(big.Rat).SetInt64(amount)
+	v.Mul(v, r)
+	v.Mul(v, big.NewRat(10000-markup, 10000))
+	v.Quo(v, new(big.Rat).SetInt(pow10(Exponent(currency))))
+	v.Mul(v, new(big.Rat).SetInt(pow10(Exponent(domain.SettlementCurrency))))
+
+	return round(v), nil
+}
+
+// LoadRates reads a rate table file, a JSON object of currency to rate.
+func LoadRates(path string) ([]domain.FXRate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	table := map[string]string{}
+	err = json.Unmarshal(b, &table)
+	if err != nil {
+		return nil, err
+	}
+
+	return Rates(table)
+}
+
+// Rates validates a rate table and turns it into rates, currency codes
+// upper cased.
+func Rates(table map[string]string) ([]domain.FXRate, error) {
+	rates := []domain.FXRate{}
+	for currency, rate := range table {
+		currency = strings.ToUpper(currency)
+		if len(currency) != 3 || currency == domain.SettlementCurrency {
+			return nil, domain.ErrCurrency
+		}
+		_, err := ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, domain.FXRate{Currency: currency, Rate: rate})
+	}
+
+	return rates, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func round(v *big.Rat) int64 {
+	half := new(big.Rat).Add(v, big.NewRat(1, 2))
+	q := new(big.Int).Quo(half.Num(), half.Denom())
+
+	return q.Int64()
+}
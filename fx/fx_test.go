@@ -0,0 +1,55 @@
+package fx_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	. "github.com/hezbymuhammad/payment-gateway/fx"
+)
+
+func TestConvert(t *testing.T) {
+	// USD 12.34 at 16250.50 less 2% is 196,520.55 rupiah.
+	res, err := Convert(1234, "USD", "16250.50", 200)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(196521), res)
+
+	// Half a rupiah rounds up.
+	res, err = Convert(1, "USD", "50", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res)
+
+	// The yen has no minor unit.
+	res, err = Convert(1000, "JPY", "105.25", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(105250), res)
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"", "0", "-1", "abc", "1/3", "1e5"} {
+		_, err := ParseRate(s)
+		assert.Equal(t, domain.ErrCurrency, err, s)
+	}
+
+	r, err := ParseRate("12150.125")
+	assert.NoError(t, err)
+	assert.Equal(t, "97201/8", r.String())
+}
+
+func TestLoadRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"usd": "16250.00"}`), 0600)
+	assert.NoError(t, err)
+
+	rates, err := LoadRates(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.FXRate{{Currency: "USD", Rate: "16250.00"}}, rates)
+}
+
+func TestRatesRejectsSettlementCurrency(t *testing.T) {
+	_, err := Rates(map[string]string{"IDR": "1"})
+	assert.Equal(t, domain.ErrCurrency, err)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	fxRepo "github.com/hezbymuhammad/payment-gateway/fx/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.FXRepository(t, func(t *testing.T) domain.FXRepository {
+		return fxRepo.NewFXRepository(migrationtest.NewPostgresDB(t))
+	})
+}
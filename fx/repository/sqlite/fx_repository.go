@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const quoteColumns = "id, merchant_id, currency, amount, settlement_currency, rate, markup, settlement_amount, expires_at"
+
+type sqliteFXRepo struct {
+	DB *sql.DB
+}
+
+func NewFXRepository(db *sql.DB) domain.FXRepository {
+	return &sqliteFXRepo{
+		DB: db,
+	}
+}
+
+// StoreRate adds the currency to the rate table or replaces its rate.
+func (fr *sqliteFXRepo) StoreRate(ctx context.Context, r *domain.FXRate) error {
+	query := "INSERT INTO fx_rates (currency, rate, updated_at) VALUES (?, ?, ?) ON CONFLICT(currency) DO UPDATE SET rate=excluded.rate, updated_at=excluded.updated_at"
+
+	_, err := fr.exec(ctx, query, r.Currency, r.Rate, r.UpdatedAt)
+	return err
+}
+
+func (fr *sqliteFXRepo) GetRate(ctx context.Context, currency string) (domain.FXRate, error) {
+	query := "SELECT currency, rate, updated_at FROM fx_rates WHERE currency=? LIMIT 1"
+
+	data := domain.FXRate{}
+	err := fr.DB.QueryRowContext(ctx, query, currency).Scan(&data.Currency, &data.Rate, &data.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.FXRate{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.FXRate{}, err
+	}
+
+	return data, nil
+}
+
+func (fr *sqliteFXRepo) FetchRates(ctx context.Context) ([]domain.FXRate, error) {
+	query := "SELECT currency, rate, updated_at FROM fx_rates ORDER BY currency"
+
+	rows, err := fr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.FXRate{}
+	for rows.Next() {
+		data := domain.FXRate{}
+		err = rows.Scan(&data.Currency, &data.Rate, &data.UpdatedAt)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (fr *sqliteFXRepo) StoreQuote(ctx context.Context, q *domain.FXQuote) error {
+	query := "INSERT INTO fx_quotes (merchant_id, currency, amount, settlement_currency, rate, markup, settlement_amount, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := fr.exec(ctx, query, q.MerchantID, q.Currency, q.Amount, q.SettlementCurrency, q.Rate, q.Markup, q.SettlementAmount, q.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	q.ID = lastID
+	return nil
+}
+
+func (fr *sqliteFXRepo) GetQuote(ctx context.Context, id int64) (domain.FXQuote, error) {
+	query := "SELECT " + quoteColumns + " FROM fx_quotes WHERE id=? LIMIT 1"
+
+	data := domain.FXQuote{}
+	err := fr.DB.QueryRowContext(ctx, query, id).Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Currency,
+		&data.Amount,
+		&data.SettlementCurrency,
+		&data.Rate,
+		&data.Markup,
+		&data.SettlementAmount,
+		&data.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.FXQuote{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.FXQuote{}, err
+	}
+
+	return data, nil
+}
+
+func (fr *sqliteFXRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := fr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
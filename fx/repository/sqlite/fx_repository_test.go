@@ -0,0 +1,61 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	fxRepo "github.com/hezbymuhammad/payment-gateway/fx/repository/sqlite"
+)
+
+func TestStoreRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now().UTC()
+	query := regexp.QuoteMeta("INSERT INTO fx_rates (currency, rate, updated_at) VALUES (?, ?, ?) ON CONFLICT(currency) DO UPDATE SET rate=excluded.rate, updated_at=excluded.updated_at")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs("USD", "16250.00", now).WillReturnResult(sqlmock.NewResult(1, 1))
+	fr := fxRepo.NewFXRepository(db)
+
+	err = fr.StoreRate(context.TODO(), &domain.FXRate{Currency: "USD", Rate: "16250.00", UpdatedAt: now})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRateNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("SELECT currency, rate, updated_at FROM fx_rates WHERE currency=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs("EUR").WillReturnRows(sqlmock.NewRows([]string{"currency", "rate", "updated_at"}))
+	fr := fxRepo.NewFXRepository(db)
+
+	_, err = fr.GetRate(context.TODO(), "EUR")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestStoreQuote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	expires := time.Now().UTC().Add(15 * time.Minute)
+	q := &domain.FXQuote{MerchantID: 6, Currency: "USD", Amount: 1000, SettlementCurrency: "IDR", Rate: "16250.00", Markup: 200, SettlementAmount: 159250, ExpiresAt: expires}
+	query := regexp.QuoteMeta("INSERT INTO fx_quotes (merchant_id, currency, amount, settlement_currency, rate, markup, settlement_amount, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(6, "USD", 1000, "IDR", "16250.00", 200, 159250, expires).WillReturnResult(sqlmock.NewResult(4, 1))
+	fr := fxRepo.NewFXRepository(db)
+
+	err = fr.StoreQuote(context.TODO(), q)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), q.ID)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/fx"
+)
+
+// Config holds the FX settings that come from config.json. Markup is the
+// gateway's margin on conversions in basis points and QuoteTTL how long a
+// quoted rate stays locked.
+type Config struct {
+	Markup   int64
+	QuoteTTL time.Duration
+}
+
+type fxUsecase struct {
+	fxRepo       domain.FXRepository
+	merchantRepo domain.MerchantRepository
+	cfg          Config
+}
+
+func NewFXUsecase(fr domain.FXRepository, mr domain.MerchantRepository, cfg Config) domain.FXUsecase {
+	return &fxUsecase{
+		fxRepo:       fr,
+		merchantRepo: mr,
+		cfg:          cfg,
+	}
+}
+
+func (fu *fxUsecase) StoreRates(ctx context.Context, rates []domain.FXRate) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := range rates {
+		rates[i].UpdatedAt = now
+		err := fu.fxRepo.StoreRate(ctx, &rates[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fu *fxUsecase) FetchRates(ctx context.Context) ([]domain.FXRate, error) {
+	return fu.fxRepo.FetchRates(ctx)
+}
+
+// Quote prices a payment in a foreign currency and locks the rate for the
+// quote window.
+func (fu *fxUsecase) Quote(ctx context.Context, q *domain.FXQuote) error {
+	_, err := fu.merchantRepo.GetByID(ctx, q.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	r, err := fu.rate(ctx, q.Currency)
+	if err != nil {
+		return err
+	}
+
+	q.SettlementCurrency = domain.SettlementCurrency
+	q.Rate = r.Rate
+	q.Markup = fu.cfg.Markup
+	q.SettlementAmount, err = fx.Convert(q.Amount, q.Currency, q.Rate, q.Markup)
+	if err != nil {
+		return err
+	}
+	q.ExpiresAt = time.Now().UTC().Add(fu.cfg.QuoteTTL).Truncate(time.Second)
+
+	return fu.fxRepo.StoreQuote(ctx, q)
+}
+
+// Lock fixes the rate a transaction settles at. A transaction that brings a
+// quote settles at the quoted rate as long as the quote is its own and still
+// open; one without settles at the current rate. Either way the rate is
+// recorded on the transaction and nothing downstream looks it up again.
+func (fu *fxUsecase) Lock(ctx context.Context, t *domain.Transaction) error {
+	t.SettlementCurrency = domain.SettlementCurrency
+	t.FXRate = ""
+	t.FXMarkup = 0
+	if t.Currency == domain.SettlementCurrency {
+		t.FXQuoteID = 0
+		return nil
+	}
+
+	if t.FXQuoteID != 0 {
+		q, err := fu.fxRepo.GetQuote(ctx, t.FXQuoteID)
+		if err == domain.ErrNotFound {
+			return domain.ErrFXQuote
+		}
+		if err != nil {
+			return err
+		}
+		if q.MerchantID != t.MerchantID || q.Currency != t.Currency || q.Amount != t.OriginalAmount || !time.Now().Before(q.ExpiresAt) {
+			return domain.ErrFXQuote
+		}
+		t.FXRate = q.Rate
+		t.FXMarkup = q.Markup
+	} else {
+		r, err := fu.rate(ctx, t.Currency)
+		if err != nil {
+			return err
+		}
+		t.FXRate = r.Rate
+		t.FXMarkup = fu.cfg.Markup
+	}
+
+	settlement, err := fx.Convert(t.Amount-t.Fee, t.Currency, t.FXRate, t.FXMarkup)
+	if err != nil {
+		return err
+	}
+
+	t.SettlementAmount = settlement
+	return nil
+}
+
+func (fu *fxUsecase) rate(ctx context.Context, currency string) (domain.FXRate, error) {
+	if currency == domain.SettlementCurrency {
+		return domain.FXRate{}, domain.ErrCurrency
+	}
+
+	r, err := fu.fxRepo.GetRate(ctx, currency)
+	if err == domain.ErrNotFound {
+		return domain.FXRate{}, domain.ErrCurrency
+	}
+
+	return r, err
+}
@@ -0,0 +1,128 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	fxUsecase "github.com/hezbymuhammad/payment-gateway/fx/usecase"
+)
+
+var cfg = fxUsecase.Config{Markup: 200, QuoteTTL: 15 * time.Minute}
+
+func TestQuote(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6}, nil).Once()
+	mockRepo.On("GetRate", mock.Anything, "USD").Return(domain.FXRate{Currency: "USD", Rate: "16250.00"}, nil).Once()
+	mockRepo.On("StoreQuote", mock.Anything, mock.AnythingOfType("*domain.FXQuote")).Return(nil).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, mockMerchantRepo, cfg)
+	q := domain.FXQuote{MerchantID: 6, Currency: "USD", Amount: 1000}
+
+	err := u.Quote(context.TODO(), &q)
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", q.SettlementCurrency)
+	assert.Equal(t, "16250.00", q.Rate)
+	assert.Equal(t, int64(200), q.Markup)
+	assert.Equal(t, int64(159250), q.SettlementAmount)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), q.ExpiresAt, 2*time.Second)
+}
+
+func TestQuoteUnknownCurrency(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6}, nil).Once()
+	mockRepo.On("GetRate", mock.Anything, "EUR").Return(domain.FXRate{}, domain.ErrNotFound).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, mockMerchantRepo, cfg)
+
+	err := u.Quote(context.TODO(), &domain.FXQuote{MerchantID: 6, Currency: "EUR", Amount: 1000})
+	assert.Equal(t, domain.ErrCurrency, err)
+	mockRepo.AssertNotCalled(t, "StoreQuote", mock.Anything, mock.Anything)
+}
+
+func TestLockSettlementCurrency(t *testing.T) {
+	u := fxUsecase.NewFXUsecase(new(mocks.FXRepository), new(mocks.MerchantRepository), cfg)
+	data := domain.Transaction{Currency: "IDR", Amount: 50000, SettlementAmount: 50000, FXQuoteID: 4}
+
+	err := u.Lock(context.TODO(), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", data.SettlementCurrency)
+	assert.Equal(t, int64(0), data.FXQuoteID)
+	assert.Equal(t, "", data.FXRate)
+	assert.Equal(t, int64(50000), data.SettlementAmount)
+}
+
+func TestLockWithQuote(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockRepo.On("GetQuote", mock.Anything, int64(4)).Return(domain.FXQuote{
+		ID:         4,
+		MerchantID: 6,
+		Currency:   "USD",
+		Amount:     1000,
+		Rate:       "15000.00",
+		Markup:     100,
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}, nil).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, new(mocks.MerchantRepository), cfg)
+	data := domain.Transaction{MerchantID: 6, Currency: "USD", Amount: 1000, OriginalAmount: 1000, FXQuoteID: 4}
+
+	err := u.Lock(context.TODO(), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "15000.00", data.FXRate)
+	assert.Equal(t, int64(100), data.FXMarkup)
+	assert.Equal(t, int64(148500), data.SettlementAmount)
+	mockRepo.AssertNotCalled(t, "GetRate", mock.Anything, mock.Anything)
+}
+
+func TestLockWithExpiredQuote(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockRepo.On("GetQuote", mock.Anything, int64(4)).Return(domain.FXQuote{
+		ID:         4,
+		MerchantID: 6,
+		Currency:   "USD",
+		Amount:     1000,
+		Rate:       "15000.00",
+		ExpiresAt:  time.Now().Add(-time.Second),
+	}, nil).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, new(mocks.MerchantRepository), cfg)
+	data := domain.Transaction{MerchantID: 6, Currency: "USD", Amount: 1000, OriginalAmount: 1000, FXQuoteID: 4}
+
+	err := u.Lock(context.TODO(), &data)
+	assert.Equal(t, domain.ErrFXQuote, err)
+}
+
+func TestLockWithQuoteForAnotherAmount(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockRepo.On("GetQuote", mock.Anything, int64(4)).Return(domain.FXQuote{
+		ID:         4,
+		MerchantID: 6,
+		Currency:   "USD",
+		Amount:     1000,
+		Rate:       "15000.00",
+		ExpiresAt:  time.Now().Add(time.Minute),
+	}, nil).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, new(mocks.MerchantRepository), cfg)
+	data := domain.Transaction{MerchantID: 6, Currency: "USD", Amount: 2000, OriginalAmount: 2000, FXQuoteID: 4}
+
+	err := u.Lock(context.TODO(), &data)
+	assert.Equal(t, domain.ErrFXQuote, err)
+}
+
+func TestLockAtCurrentRate(t *testing.T) {
+	mockRepo := new(mocks.FXRepository)
+	mockRepo.On("GetRate", mock.Anything, "SGD").Return(domain.FXRate{Currency: "SGD", Rate: "12150.00"}, nil).Once()
+	u := fxUsecase.NewFXUsecase(mockRepo, new(mocks.MerchantRepository), cfg)
+	data := domain.Transaction{MerchantID: 6, Currency: "SGD", Amount: 500, OriginalAmount: 500}
+
+	err := u.Lock(context.TODO(), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "IDR", data.SettlementCurrency)
+	assert.Equal(t, "12150.00", data.FXRate)
+	assert.Equal(t, int64(200), data.FXMarkup)
+	assert.Equal(t, int64(59535), data.SettlementAmount)
+}
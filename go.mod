@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.3.0 // indirect
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-sqlite3 v1.14.8
 	github.com/spf13/viper v1.8.1
 	github.com/stretchr/testify v1.7.0
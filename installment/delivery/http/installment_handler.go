@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// tenors are the installment lengths issuers offer, in months.
+var tenors = map[int]bool{3: true, 6: true, 12: true}
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type InstallmentHandler struct {
+	Usecase domain.InstallmentUsecase
+}
+
+func NewInstallmentHandler(e *echo.Echo, u domain.InstallmentUsecase) *InstallmentHandler {
+	handler := &InstallmentHandler{
+		Usecase: u,
+	}
+
+	e.POST("/installments/plans", handler.StorePlan)
+	e.GET("/installments/plans", handler.FetchPlans)
+	e.GET("/installments/quote", handler.Quote)
+
+	return handler
+}
+
+func (h *InstallmentHandler) StorePlan(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.InstallmentPlan
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.SettingID == 0 || !tenors[data.Tenor] || data.CustomerRate < 0 || data.MerchantRate < 0 || data.MinAmount < 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.StorePlan(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *InstallmentHandler) FetchPlans(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	settingID, err := strconv.ParseInt(c.QueryParam("settingId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.FetchPlans(ctx, merchantID, settingID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *InstallmentHandler) Quote(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	settingID, err := strconv.ParseInt(c.QueryParam("settingId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	amount, err := strconv.ParseInt(c.QueryParam("amount"), 10, 64)
+	if err != nil || amount <= 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	bin := c.QueryParam("bin")
+	if len(bin) < 6 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Quote(ctx, merchantID, settingID, amount, bin)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrPaymentMethod:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
@@ -0,0 +1,71 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	installmentHttp "github.com/hezbymuhammad/payment-gateway/installment/delivery/http"
+)
+
+func TestStorePlanInvalidTenor(t *testing.T) {
+	mockUsecase := new(mocks.InstallmentUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/installments/plans", strings.NewReader(`{"merchantId":6,"settingId":1,"tenor":4}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := installmentHttp.NewInstallmentHandler(echo.New(), mockUsecase)
+	err = handler.StorePlan(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "StorePlan", mock.Anything, mock.Anything)
+}
+
+func TestQuote(t *testing.T) {
+	mockUsecase := new(mocks.InstallmentUsecase)
+	mockUsecase.On("Quote", mock.Anything, int64(6), int64(1), int64(1000000), "411111").Return([]domain.InstallmentQuote{{PlanID: 2, Tenor: 6, Monthly: 171667}}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/installments/quote?merchantId=6&settingId=1&amount=1000000&bin=411111", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := installmentHttp.NewInstallmentHandler(echo.New(), mockUsecase)
+	err = handler.Quote(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"monthly":171667`)
+}
+
+func TestQuoteWithoutBIN(t *testing.T) {
+	mockUsecase := new(mocks.InstallmentUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/installments/quote?merchantId=6&settingId=1&amount=1000000", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := installmentHttp.NewInstallmentHandler(echo.New(), mockUsecase)
+	err = handler.Quote(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	installmentRepo "github.com/hezbymuhammad/payment-gateway/installment/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.InstallmentRepository(t, func(t *testing.T) domain.InstallmentRepository {
+		return installmentRepo.NewInstallmentRepository(migrationtest.NewPostgresDB(t))
+	})
+}
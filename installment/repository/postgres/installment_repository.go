@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/lib/pq"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const planColumns = "id, merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins"
+
+type postgresInstallmentRepo struct {
+	DB *sql.DB
+}
+
+func NewInstallmentRepository(db *sql.DB) domain.InstallmentRepository {
+	return &postgresInstallmentRepo{
+		DB: db,
+	}
+}
+
+func (ir *postgresInstallmentRepo) StorePlan(ctx context.Context, p *domain.InstallmentPlan) error {
+	query := "INSERT INTO installment_plans (merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
+	err := ir.DB.QueryRowContext(ctx, query, p.MerchantID, p.SettingID, p.Tenor, p.CustomerRate, p.MerchantRate, p.MinAmount, bins(p.BINs)).Scan(&p.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (ir *postgresInstallmentRepo) GetPlan(ctx context.Context, id int64) (domain.InstallmentPlan, error) {
+	query := "SELECT " + planColumns + " FROM installment_plans WHERE id=$1"
+
+	data, err := scanPlan(ir.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.InstallmentPlan{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.InstallmentPlan{}, err
+	}
+
+	return data, nil
+}
+
+func (ir *postgresInstallmentRepo) FetchPlans(ctx context.Context, settingID int64) ([]domain.InstallmentPlan, error) {
+	query := "SELECT " + planColumns + " FROM installment_plans WHERE setting_id=$1 ORDER BY tenor"
+
+	rows, err := ir.DB.QueryContext(ctx, query, settingID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InstallmentPlan{}
+	for rows.Next() {
+		data, err := scanPlan(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPlan reads a plan row. BINs are a text array.
+func scanPlan(row scanner) (domain.InstallmentPlan, error) {
+	data := domain.InstallmentPlan{}
+	var values pq.StringArray
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.Tenor,
+		&data.CustomerRate,
+		&data.MerchantRate,
+		&data.MinAmount,
+		&values,
+	)
+	if err != nil {
+		return domain.InstallmentPlan{}, err
+	}
+
+	data.BINs = []string(values)
+	if data.BINs == nil {
+		data.BINs = []string{}
+	}
+
+	return data, nil
+}
+
+// bins stores the BINs as a text array, empty rather than NULL when the plan
+// is open to every card.
+func bins(values []string) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+
+	return pq.StringArray(values)
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const planColumns = "id, merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins"
+
+type sqliteInstallmentRepo struct {
+	DB *sql.DB
+}
+
+func NewInstallmentRepository(db *sql.DB) domain.InstallmentRepository {
+	return &sqliteInstallmentRepo{
+		DB: db,
+	}
+}
+
+func (ir *sqliteInstallmentRepo) StorePlan(ctx context.Context, p *domain.InstallmentPlan) error {
+	query := "INSERT INTO installment_plans (merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins) VALUES (?, ?, ?, ?, ?, ?, ?)"
+
+	stmt, err := ir.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, p.MerchantID, p.SettingID, p.Tenor, p.CustomerRate, p.MerchantRate, p.MinAmount, strings.Join(p.BINs, ","))
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	p.ID = lastID
+	return nil
+}
+
+func (ir *sqliteInstallmentRepo) GetPlan(ctx context.Context, id int64) (domain.InstallmentPlan, error) {
+	query := "SELECT " + planColumns + " FROM installment_plans WHERE id=? LIMIT 1"
+
+	data, err := scanPlan(ir.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.InstallmentPlan{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.InstallmentPlan{}, err
+	}
+
+	return data, nil
+}
+
+func (ir *sqliteInstallmentRepo) FetchPlans(ctx context.Context, settingID int64) ([]domain.InstallmentPlan, error) {
+	query := "SELECT " + planColumns + " FROM installment_plans WHERE setting_id=? ORDER BY tenor"
+
+	rows, err := ir.DB.QueryContext(ctx, query, settingID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InstallmentPlan{}
+	for rows.Next() {
+		data, err := scanPlan(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPlan reads a plan row. BINs are stored comma separated.
+func scanPlan(row scanner) (domain.InstallmentPlan, error) {
+	data := domain.InstallmentPlan{}
+	var bins string
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.Tenor,
+		&data.CustomerRate,
+		&data.MerchantRate,
+		&data.MinAmount,
+		&bins,
+	)
+	if err != nil {
+		return domain.InstallmentPlan{}, err
+	}
+
+	data.BINs = []string{}
+	if bins != "" {
+		data.BINs = strings.Split(bins, ",")
+	}
+
+	return data, nil
+}
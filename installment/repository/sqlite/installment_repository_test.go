@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	installmentRepo "github.com/hezbymuhammad/payment-gateway/installment/repository/sqlite"
+)
+
+var planColumns = []string{"id", "merchant_id", "setting_id", "tenor", "customer_rate", "merchant_rate", "min_amount", "bins"}
+
+func TestStorePlan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.InstallmentPlan{MerchantID: 6, SettingID: 1, Tenor: 6, CustomerRate: 300, MerchantRate: 100, MinAmount: 500000, BINs: []string{"411111", "522222"}}
+	query := regexp.QuoteMeta("INSERT INTO installment_plans (merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(6, 1, 6, 300, 100, 500000, "411111,522222").WillReturnResult(sqlmock.NewResult(2, 1))
+	ir := installmentRepo.NewInstallmentRepository(db)
+
+	err = ir.StorePlan(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), data.ID)
+}
+
+func TestFetchPlans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(planColumns).
+		AddRow(1, 6, 1, 3, 0, 250, 500000, "").
+		AddRow(2, 6, 1, 6, 300, 100, 500000, "411111,522222")
+	query := regexp.QuoteMeta("SELECT id, merchant_id, setting_id, tenor, customer_rate, merchant_rate, min_amount, bins FROM installment_plans WHERE setting_id=? ORDER BY tenor")
+	mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+	ir := installmentRepo.NewInstallmentRepository(db)
+
+	res, err := ir.FetchPlans(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Empty(t, res[0].BINs)
+	assert.Equal(t, []string{"411111", "522222"}, res[1].BINs)
+}
+
+func TestGetPlanNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(9).WillReturnRows(sqlmock.NewRows(planColumns))
+	ir := installmentRepo.NewInstallmentRepository(db)
+
+	_, err = ir.GetPlan(context.TODO(), 9)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type installmentUsecase struct {
+	installmentRepo domain.InstallmentRepository
+	merchantRepo    domain.MerchantRepository
+}
+
+func NewInstallmentUsecase(ir domain.InstallmentRepository, mr domain.MerchantRepository) domain.InstallmentUsecase {
+	return &installmentUsecase{
+		installmentRepo: ir,
+		merchantRepo:    mr,
+	}
+}
+
+// StorePlan adds a plan to one of the merchant's card settings.
+func (iu *installmentUsecase) StorePlan(ctx context.Context, p *domain.InstallmentPlan) error {
+	err := iu.checkSetting(ctx, p.MerchantID, p.SettingID)
+	if err != nil {
+		return err
+	}
+
+	return iu.installmentRepo.StorePlan(ctx, p)
+}
+
+func (iu *installmentUsecase) FetchPlans(ctx context.Context, merchantID int64, settingID int64) ([]domain.InstallmentPlan, error) {
+	err := iu.checkSetting(ctx, merchantID, settingID)
+	if err != nil {
+		return nil, err
+	}
+
+	return iu.installmentRepo.FetchPlans(ctx, settingID)
+}
+
+// Quote prices every plan of the setting that the amount and card qualify
+// for, shortest tenor first.
+func (iu *installmentUsecase) Quote(ctx context.Context, merchantID int64, settingID int64, amount int64, bin string) ([]domain.InstallmentQuote, error) {
+	plans, err := iu.FetchPlans(ctx, merchantID, settingID)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := []domain.InstallmentQuote{}
+	for _, p := range plans {
+		if eligible(p, amount, bin) {
+			quotes = append(quotes, quote(p, amount))
+		}
+	}
+
+	return quotes, nil
+}
+
+// Apply puts the transaction on its chosen plan and charges the merchant's
+// share of the cost as a fee against the settlement. Issuers only split
+// rupiah charges.
+func (iu *installmentUsecase) Apply(ctx context.Context, t *domain.Transaction, bin string) error {
+	if t.Currency != domain.SettlementCurrency {
+		return domain.ErrInstallmentPlan
+	}
+
+	p, err := iu.installmentRepo.GetPlan(ctx, t.InstallmentPlanID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInstallmentPlan
+	}
+	if err != nil {
+		return err
+	}
+	if p.SettingID != t.SettingID || !eligible(p, t.Amount, bin) {
+		return domain.ErrInstallmentPlan
+	}
+
+	q := quote(p, t.Amount)
+	t.InstallmentTenor = p.Tenor
+	t.Fee += q.MerchantFee
+	t.SettlementAmount = t.Amount - t.Fee
+	return nil
+}
+
+func (iu *installmentUsecase) checkSetting(ctx context.Context, merchantID int64, settingID int64) error {
+	s, err := iu.merchantRepo.GetSetting(ctx, settingID)
+	if err != nil {
+		return err
+	}
+	if s.MerchantID != merchantID {
+		return domain.ErrNotFound
+	}
+	if s.PaymentType != "CARD" {
+		return domain.ErrPaymentMethod
+	}
+
+	return nil
+}
+
+func eligible(p domain.InstallmentPlan, amount int64, bin string) bool {
+	if amount < p.MinAmount {
+		return false
+	}
+	if len(p.BINs) == 0 {
+		return true
+	}
+	for _, prefix := range p.BINs {
+		if prefix != "" && strings.HasPrefix(bin, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quote rounds interest and fee to the nearest rupiah and spreads the total
+// so that no month is short; the last one absorbs the remainder.
+func quote(p domain.InstallmentPlan, amount int64) domain.InstallmentQuote {
+	interest := (amount*p.CustomerRate + 5000) / 10000
+	total := amount + interest
+	tenor := int64(p.Tenor)
+
+	return domain.InstallmentQuote{
+		PlanID:      p.ID,
+		Tenor:       p.Tenor,
+		Interest:    interest,
+		Total:       total,
+		Monthly:     (total + tenor - 1) / tenor,
+		MerchantFee: (amount*p.MerchantRate + 5000) / 10000,
+	}
+}
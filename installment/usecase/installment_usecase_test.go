@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	installmentUsecase "github.com/hezbymuhammad/payment-gateway/installment/usecase"
+)
+
+var card = domain.Setting{ID: 1, MerchantID: 6, PaymentType: "CARD", PaymentName: "VISA"}
+
+var plans = []domain.InstallmentPlan{
+	{ID: 1, MerchantID: 6, SettingID: 1, Tenor: 3, MerchantRate: 250, MinAmount: 500000, BINs: []string{}},
+	{ID: 2, MerchantID: 6, SettingID: 1, Tenor: 6, CustomerRate: 300, MerchantRate: 100, MinAmount: 500000, BINs: []string{"411111", "522222"}},
+	{ID: 3, MerchantID: 6, SettingID: 1, Tenor: 12, CustomerRate: 600, MinAmount: 2000000, BINs: []string{}},
+}
+
+func TestQuote(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockRepo.On("FetchPlans", mock.Anything, int64(1)).Return(plans, nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, mockMerchantRepo)
+
+	res, err := u.Quote(context.TODO(), 6, 1, 1000000, "411111")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, 3, res[0].Tenor)
+	assert.Equal(t, int64(0), res[0].Interest)
+	assert.Equal(t, int64(333334), res[0].Monthly)
+	assert.Equal(t, int64(25000), res[0].MerchantFee)
+
+	assert.Equal(t, 6, res[1].Tenor)
+	assert.Equal(t, int64(30000), res[1].Interest)
+	assert.Equal(t, int64(1030000), res[1].Total)
+	assert.Equal(t, int64(171667), res[1].Monthly)
+	assert.Equal(t, int64(10000), res[1].MerchantFee)
+}
+
+func TestQuoteOtherIssuer(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	mockRepo.On("FetchPlans", mock.Anything, int64(1)).Return(plans, nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, mockMerchantRepo)
+
+	res, err := u.Quote(context.TODO(), 6, 1, 3000000, "455555")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, 3, res[0].Tenor)
+	assert.Equal(t, 12, res[1].Tenor)
+}
+
+func TestQuoteAnotherMerchantsSetting(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(card, nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(new(mocks.InstallmentRepository), mockMerchantRepo)
+
+	_, err := u.Quote(context.TODO(), 1, 1, 1000000, "411111")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestStorePlanOnQRSetting(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(8)).Return(domain.Setting{ID: 8, MerchantID: 6, PaymentType: "QR"}, nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, mockMerchantRepo)
+
+	err := u.StorePlan(context.TODO(), &domain.InstallmentPlan{MerchantID: 6, SettingID: 8, Tenor: 3})
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+	mockRepo.AssertNotCalled(t, "StorePlan", mock.Anything, mock.Anything)
+}
+
+func TestApply(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plans[1], nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, SettingID: 1, Amount: 1000000, Currency: "IDR", InstallmentPlanID: 2, SettlementAmount: 1000000}
+
+	err := u.Apply(context.TODO(), &data, "522222")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, data.InstallmentTenor)
+	assert.Equal(t, int64(10000), data.Fee)
+	assert.Equal(t, int64(990000), data.SettlementAmount)
+}
+
+func TestApplyBelowMinimum(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plans[1], nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, SettingID: 1, Amount: 100000, Currency: "IDR", InstallmentPlanID: 2}
+
+	err := u.Apply(context.TODO(), &data, "411111")
+	assert.Equal(t, domain.ErrInstallmentPlan, err)
+}
+
+func TestApplyPlanOfAnotherSetting(t *testing.T) {
+	mockRepo := new(mocks.InstallmentRepository)
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plans[1], nil).Once()
+	u := installmentUsecase.NewInstallmentUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, SettingID: 5, Amount: 1000000, Currency: "IDR", InstallmentPlanID: 2}
+
+	err := u.Apply(context.TODO(), &data, "411111")
+	assert.Equal(t, domain.ErrInstallmentPlan, err)
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/branding"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+//go:embed templates/invoice.html
+var templates embed.FS
+
+var page = template.Must(template.New("invoice.html").Funcs(branding.Funcs).ParseFS(templates, "templates/invoice.html"))
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// LimitResponse tells the payer which limit paying the invoice would have
+// gone over and how much of it is left.
+type LimitResponse struct {
+	Message string `json:"message"`
+	domain.LimitStatus
+}
+
+type InvoiceHandler struct {
+	Usecase domain.InvoiceUsecase
+}
+
+func NewInvoiceHandler(e *echo.Echo, u domain.InvoiceUsecase) *InvoiceHandler {
+	handler := &InvoiceHandler{
+		Usecase: u,
+	}
+
+	e.POST("/invoices", handler.Store)
+	e.GET("/invoices", handler.Fetch)
+	e.GET("/invoices/:id", handler.GetByID)
+	e.POST("/invoices/:id/finalize", handler.Finalize)
+	e.POST("/invoices/:id/void", handler.Void)
+	e.GET("/pay/:token", handler.Render)
+	e.POST("/pay/:token", handler.Pay)
+
+	return handler
+}
+
+func (h *InvoiceHandler) Store(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Invoice
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.SettingID == 0 || data.DueDate.IsZero() || len(data.LineItems) == 0 || data.TaxRate < 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	for _, item := range data.LineItems {
+		if item.Description == "" || item.Quantity <= 0 || item.UnitPrice < 0 {
+			return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+		}
+	}
+
+	err := h.Usecase.Store(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *InvoiceHandler) Fetch(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, merchantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *InvoiceHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type merchantRequest struct {
+	MerchantID int64 `json:"merchantId"`
+}
+
+func (h *InvoiceHandler) Finalize(c echo.Context) error {
+	return h.transition(c, h.Usecase.Finalize)
+}
+
+func (h *InvoiceHandler) Void(c echo.Context) error {
+	return h.transition(c, h.Usecase.Void)
+}
+
+func (h *InvoiceHandler) transition(c echo.Context, move func(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error)) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var req merchantRequest
+	c.Bind(&req)
+	if req.MerchantID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	res, err := move(ctx, req.MerchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Render shows the invoice behind a payment link as an HTML page in the
+// merchant's colours.
+func (h *InvoiceHandler) Render(c echo.Context) error {
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetPage(ctx, c.Param("token"))
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	var buf bytes.Buffer
+	err = page.Execute(&buf, res)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.HTML(http.StatusOK, buf.String())
+}
+
+type payRequest struct {
+	CardToken string `json:"cardToken"`
+}
+
+func (h *InvoiceHandler) Pay(c echo.Context) error {
+	ctx := c.Request().Context()
+	var req payRequest
+	c.Bind(&req)
+	if req.CardToken == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	data := domain.Transaction{CardToken: req.CardToken}
+	err := h.Usecase.Pay(ctx, c.Param("token"), &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+// respondError maps an error to a response. Paying an invoice stores a
+// transaction, so its errors get the statuses the transaction endpoints use.
+func respondError(c echo.Context, err error) error {
+	if le, ok := err.(*domain.LimitError); ok {
+		c.Response().Header().Set("X-Limit", le.Limit)
+		c.Response().Header().Set("X-Limit-Remaining", strconv.FormatInt(le.Remaining, 10))
+		return c.JSON(http.StatusUnprocessableEntity, LimitResponse{Message: domain.ErrLimitExceeded.Error(), LimitStatus: le.LimitStatus})
+	}
+
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrUnauthorized:
+		return c.JSON(http.StatusUnauthorized, ResponseError{Message: err.Error()})
+	case domain.ErrMerchantInactive:
+		return c.JSON(http.StatusForbidden, ResponseError{Message: err.Error()})
+	case domain.ErrInvalidCard, domain.ErrInvalidCustomer, domain.ErrPaymentMethod, domain.ErrInstallmentPlan, domain.ErrPromotion, domain.ErrCurrency, domain.ErrFXQuote:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrInvoiceState, domain.ErrPromotionQuota:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	case domain.ErrProvider:
+		return c.JSON(http.StatusBadGateway, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
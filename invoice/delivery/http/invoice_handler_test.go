@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	invoiceHttp "github.com/hezbymuhammad/payment-gateway/invoice/delivery/http"
+)
+
+func TestStoreInvalidLineItem(t *testing.T) {
+	mockUsecase := new(mocks.InvoiceUsecase)
+
+	e := echo.New()
+	body := `{"merchantId":1,"settingId":1,"dueDate":"2026-11-01T00:00:00Z","lineItems":[{"description":"Coffee","quantity":0,"unitPrice":50000}]}`
+	req, err := http.NewRequest(echo.POST, "/invoices", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/invoices")
+
+	handler := invoiceHttp.NewInvoiceHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestRender(t *testing.T) {
+	mockUsecase := new(mocks.InvoiceUsecase)
+	mockUsecase.On("GetPage", mock.Anything, "inv_1").Return(domain.InvoicePage{
+		Invoice: domain.Invoice{
+			ID:       7,
+			Status:   domain.InvoiceOpen,
+			Currency: "IDR",
+			Total:    111000,
+			DueDate:  time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			LineItems: []domain.InvoiceLineItem{
+				{Description: "Coffee <beans>", Quantity: 2, UnitPrice: 50000, Amount: 100000},
+			},
+		},
+		Merchant: domain.Merchant{ID: 1, Name: "CAFE"},
+		Setting:  domain.Setting{Color: "red;}</style><script>", PaymentName: "VISA"},
+	}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/pay/inv_1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/pay/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("inv_1")
+
+	handler := invoiceHttp.NewInvoiceHandler(echo.New(), mockUsecase)
+	err = handler.Render(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "<h1>CAFE</h1>")
+	assert.Contains(t, body, "IDR 111,000")
+	assert.Contains(t, body, "Coffee &lt;beans&gt;")
+	assert.Contains(t, body, "1 November 2026")
+	assert.NotContains(t, body, "<script>")
+}
+
+func TestPayPaidInvoice(t *testing.T) {
+	mockUsecase := new(mocks.InvoiceUsecase)
+	mockUsecase.On("Pay", mock.Anything, "inv_1", mock.Anything).Return(domain.ErrInvoiceState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/pay/inv_1", strings.NewReader(`{"cardToken":"tok_1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/pay/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("inv_1")
+
+	handler := invoiceHttp.NewInvoiceHandler(echo.New(), mockUsecase)
+	err = handler.Pay(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestPayOverLimit(t *testing.T) {
+	mockUsecase := new(mocks.InvoiceUsecase)
+	limitErr := &domain.LimitError{LimitStatus: domain.LimitStatus{Limit: domain.LimitDailyVolume, Remaining: 50000}}
+	mockUsecase.On("Pay", mock.Anything, "inv_1", mock.Anything).Return(limitErr).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/pay/inv_1", strings.NewReader(`{"cardToken":"tok_1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/pay/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("inv_1")
+
+	handler := invoiceHttp.NewInvoiceHandler(echo.New(), mockUsecase)
+	err = handler.Pay(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, domain.LimitDailyVolume, rec.Header().Get("X-Limit"))
+	assert.Equal(t, "50000", rec.Header().Get("X-Limit-Remaining"))
+}
+
+func TestPayInactiveMerchant(t *testing.T) {
+	mockUsecase := new(mocks.InvoiceUsecase)
+	mockUsecase.On("Pay", mock.Anything, "inv_1", mock.Anything).Return(domain.ErrMerchantInactive).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/pay/inv_1", strings.NewReader(`{"cardToken":"tok_1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/pay/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("inv_1")
+
+	handler := invoiceHttp.NewInvoiceHandler(echo.New(), mockUsecase)
+	err = handler.Pay(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"context"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier writes reminders to the log. It stands in until there is a
+// mail or messaging provider to deliver them.
+func NewLogNotifier() domain.InvoiceNotifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) SendReminder(ctx context.Context, i domain.Invoice) error {
+	log.Printf("reminder: invoice %d for customer %d, %d %s due %s, pay at %s", i.ID, i.CustomerID, i.Total, i.Currency, i.DueDate.Format("2006-01-02"), i.PaymentLink)
+	return nil
+}
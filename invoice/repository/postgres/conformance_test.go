@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	invoiceRepo "github.com/hezbymuhammad/payment-gateway/invoice/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.InvoiceRepository(t, func(t *testing.T) domain.InvoiceRepository {
+		return invoiceRepo.NewInvoiceRepository(migrationtest.NewPostgresDB(t))
+	})
+}
@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const invoiceColumns = "id, merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id"
+
+type postgresInvoiceRepo struct {
+	DB *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) domain.InvoiceRepository {
+	return &postgresInvoiceRepo{
+		DB: db,
+	}
+}
+
+// Store inserts the invoice together with its line items.
+func (ir *postgresInvoiceRepo) Store(ctx context.Context, i *domain.Invoice) error {
+	tx, err := ir.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	query := "INSERT INTO invoices (merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id"
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		i.MerchantID,
+		i.SettingID,
+		i.CustomerID,
+		i.Status,
+		i.Currency,
+		i.TaxRate,
+		i.Subtotal,
+		i.Tax,
+		i.Total,
+		i.DueDate,
+		nullString(i.LinkToken),
+		i.TransactionID,
+	).Scan(&id)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "INSERT INTO invoice_line_items (invoice_id, description, quantity, unit_price, amount) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	for n := range i.LineItems {
+		item := &i.LineItems[n]
+		err = tx.QueryRowContext(ctx, query, id, item.Description, item.Quantity, item.UnitPrice, item.Amount).Scan(&item.ID)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+		item.InvoiceID = id
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	i.ID = id
+	return nil
+}
+
+// FetchByMerchant lists invoices without their line items.
+func (ir *postgresInvoiceRepo) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE merchant_id=$1 ORDER BY id"
+
+	return ir.fetch(ctx, query, merchantID)
+}
+
+func (ir *postgresInvoiceRepo) GetByID(ctx context.Context, id int64) (domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE id=$1"
+
+	return ir.get(ctx, query, id)
+}
+
+func (ir *postgresInvoiceRepo) GetByLinkToken(ctx context.Context, token string) (domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE link_token=$1"
+
+	return ir.get(ctx, query, token)
+}
+
+// Claim moves an open or overdue invoice to processing. Only one caller can
+// win, which is what stops a payment link from being paid twice.
+func (ir *postgresInvoiceRepo) Claim(ctx context.Context, id int64) (bool, error) {
+	query := "UPDATE invoices SET status='processing' WHERE id=$1 AND status IN ('open', 'overdue')"
+
+	res, err := ir.exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Update saves the invoice if it is still in the from status, and returns
+// domain.ErrInvoiceState if something else moved it first.
+func (ir *postgresInvoiceRepo) Update(ctx context.Context, i *domain.Invoice, from string) error {
+	query := "UPDATE invoices SET status=$1, link_token=$2, transaction_id=$3 WHERE id=$4 AND status=$5"
+
+	res, err := ir.exec(ctx, query, i.Status, nullString(i.LinkToken), i.TransactionID, i.ID, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvoiceState
+	}
+
+	return nil
+}
+
+// FetchProcessing lists the invoices waiting on a payment that was neither
+// captured nor failed when it was made, without their line items.
+func (ir *postgresInvoiceRepo) FetchProcessing(ctx context.Context) ([]domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE status='processing' AND transaction_id <> 0 ORDER BY id"
+
+	return ir.fetch(ctx, query)
+}
+
+// MarkOverdue moves every open invoice whose due date has passed to overdue
+// and returns how many were moved.
+func (ir *postgresInvoiceRepo) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+	query := "UPDATE invoices SET status='overdue' WHERE status='open' AND due_date < $1"
+
+	res, err := ir.exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (ir *postgresInvoiceRepo) StoreReminders(ctx context.Context, reminders []domain.InvoiceReminder) error {
+	tx, err := ir.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO invoice_reminders (invoice_id, remind_at) VALUES ($1, $2) RETURNING id"
+	for n := range reminders {
+		err = tx.QueryRowContext(ctx, query, reminders[n].InvoiceID, reminders[n].RemindAt).Scan(&reminders[n].ID)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (ir *postgresInvoiceRepo) FetchDueReminders(ctx context.Context, now time.Time) ([]domain.InvoiceReminder, error) {
+	query := "SELECT id, invoice_id, remind_at FROM invoice_reminders WHERE sent_at IS NULL AND remind_at <= $1 ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InvoiceReminder{}
+	for rows.Next() {
+		data := domain.InvoiceReminder{}
+		err = rows.Scan(&data.ID, &data.InvoiceID, &data.RemindAt)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		data.RemindAt = data.RemindAt.UTC()
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (ir *postgresInvoiceRepo) MarkReminderSent(ctx context.Context, r *domain.InvoiceReminder) error {
+	query := "UPDATE invoice_reminders SET sent_at=$1 WHERE id=$2"
+
+	_, err := ir.exec(ctx, query, r.SentAt, r.ID)
+	return err
+}
+
+func (ir *postgresInvoiceRepo) get(ctx context.Context, query string, arg interface{}) (domain.Invoice, error) {
+	data, err := scanInvoice(ir.DB.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return domain.Invoice{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Invoice{}, err
+	}
+
+	data.LineItems, err = ir.fetchLineItems(ctx, data.ID)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	return data, nil
+}
+
+func (ir *postgresInvoiceRepo) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.Invoice, error) {
+	rows, err := ir.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Invoice{}
+	for rows.Next() {
+		data, err := scanInvoice(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (ir *postgresInvoiceRepo) fetchLineItems(ctx context.Context, invoiceID int64) ([]domain.InvoiceLineItem, error) {
+	query := "SELECT id, invoice_id, description, quantity, unit_price, amount FROM invoice_line_items WHERE invoice_id=$1 ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query, invoiceID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InvoiceLineItem{}
+	for rows.Next() {
+		data := domain.InvoiceLineItem{}
+		err = rows.Scan(&data.ID, &data.InvoiceID, &data.Description, &data.Quantity, &data.UnitPrice, &data.Amount)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (ir *postgresInvoiceRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := ir.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row scanner) (domain.Invoice, error) {
+	data := domain.Invoice{}
+	var linkToken sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.CustomerID,
+		&data.Status,
+		&data.Currency,
+		&data.TaxRate,
+		&data.Subtotal,
+		&data.Tax,
+		&data.Total,
+		&data.DueDate,
+		&linkToken,
+		&data.TransactionID,
+	)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	data.LinkToken = linkToken.String
+	data.DueDate = data.DueDate.UTC()
+
+	return data, nil
+}
+
+// nullString keeps drafts, which have no payment link yet, out of the unique
+// index on link_token.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
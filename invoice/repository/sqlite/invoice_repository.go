@@ -0,0 +1,365 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const invoiceColumns = "id, merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id"
+
+type sqliteInvoiceRepo struct {
+	DB *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) domain.InvoiceRepository {
+	return &sqliteInvoiceRepo{
+		DB: db,
+	}
+}
+
+// Store inserts the invoice together with its line items.
+func (ir *sqliteInvoiceRepo) Store(ctx context.Context, i *domain.Invoice) error {
+	tx, err := ir.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO invoices (merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		i.MerchantID,
+		i.SettingID,
+		i.CustomerID,
+		i.Status,
+		i.Currency,
+		i.TaxRate,
+		i.Subtotal,
+		i.Tax,
+		i.Total,
+		i.DueDate,
+		nullString(i.LinkToken),
+		i.TransactionID,
+	)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "INSERT INTO invoice_line_items (invoice_id, description, quantity, unit_price, amount) VALUES (?, ?, ?, ?, ?)"
+	for n := range i.LineItems {
+		item := &i.LineItems[n]
+		res, err = tx.ExecContext(ctx, query, lastID, item.Description, item.Quantity, item.UnitPrice, item.Amount)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+
+		item.ID, err = res.LastInsertId()
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+		item.InvoiceID = lastID
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	i.ID = lastID
+	return nil
+}
+
+// FetchByMerchant lists invoices without their line items.
+func (ir *sqliteInvoiceRepo) FetchByMerchant(ctx context.Context, merchantID int64) ([]domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE merchant_id=? ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Invoice{}
+	for rows.Next() {
+		data, err := scanInvoice(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (ir *sqliteInvoiceRepo) GetByID(ctx context.Context, id int64) (domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE id=? LIMIT 1"
+
+	return ir.get(ctx, query, id)
+}
+
+func (ir *sqliteInvoiceRepo) GetByLinkToken(ctx context.Context, token string) (domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE link_token=? LIMIT 1"
+
+	return ir.get(ctx, query, token)
+}
+
+// Claim moves an open or overdue invoice to processing. Only one caller can
+// win, which is what stops a payment link from being paid twice.
+func (ir *sqliteInvoiceRepo) Claim(ctx context.Context, id int64) (bool, error) {
+	query := "UPDATE invoices SET status='processing' WHERE id=? AND status IN ('open', 'overdue')"
+
+	res, err := ir.exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Update saves the invoice if it is still in the from status, and returns
+// domain.ErrInvoiceState if something else moved it first.
+func (ir *sqliteInvoiceRepo) Update(ctx context.Context, i *domain.Invoice, from string) error {
+	query := "UPDATE invoices SET status=?, link_token=?, transaction_id=? WHERE id=? AND status=?"
+
+	res, err := ir.exec(ctx, query, i.Status, nullString(i.LinkToken), i.TransactionID, i.ID, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvoiceState
+	}
+
+	return nil
+}
+
+// FetchProcessing lists the invoices waiting on a payment that was neither
+// captured nor failed when it was made, without their line items.
+func (ir *sqliteInvoiceRepo) FetchProcessing(ctx context.Context) ([]domain.Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoices WHERE status='processing' AND transaction_id != 0 ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Invoice{}
+	for rows.Next() {
+		data, err := scanInvoice(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// MarkOverdue moves every open invoice whose due date has passed to overdue
+// and returns how many were moved.
+func (ir *sqliteInvoiceRepo) MarkOverdue(ctx context.Context, now time.Time) (int64, error) {
+	query := "UPDATE invoices SET status='overdue' WHERE status='open' AND due_date < ?"
+
+	res, err := ir.exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (ir *sqliteInvoiceRepo) StoreReminders(ctx context.Context, reminders []domain.InvoiceReminder) error {
+	tx, err := ir.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO invoice_reminders (invoice_id, remind_at) VALUES (?, ?)"
+	for n := range reminders {
+		res, err := tx.ExecContext(ctx, query, reminders[n].InvoiceID, reminders[n].RemindAt)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+
+		reminders[n].ID, err = res.LastInsertId()
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (ir *sqliteInvoiceRepo) FetchDueReminders(ctx context.Context, now time.Time) ([]domain.InvoiceReminder, error) {
+	query := "SELECT id, invoice_id, remind_at FROM invoice_reminders WHERE sent_at IS NULL AND remind_at <= ? ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InvoiceReminder{}
+	for rows.Next() {
+		data := domain.InvoiceReminder{}
+		err = rows.Scan(&data.ID, &data.InvoiceID, &data.RemindAt)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (ir *sqliteInvoiceRepo) MarkReminderSent(ctx context.Context, r *domain.InvoiceReminder) error {
+	query := "UPDATE invoice_reminders SET sent_at=? WHERE id=?"
+
+	_, err := ir.exec(ctx, query, r.SentAt, r.ID)
+	return err
+}
+
+func (ir *sqliteInvoiceRepo) get(ctx context.Context, query string, arg interface{}) (domain.Invoice, error) {
+	data, err := scanInvoice(ir.DB.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return domain.Invoice{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Invoice{}, err
+	}
+
+	data.LineItems, err = ir.fetchLineItems(ctx, data.ID)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	return data, nil
+}
+
+func (ir *sqliteInvoiceRepo) fetchLineItems(ctx context.Context, invoiceID int64) ([]domain.InvoiceLineItem, error) {
+	query := "SELECT id, invoice_id, description, quantity, unit_price, amount FROM invoice_line_items WHERE invoice_id=? ORDER BY id"
+
+	rows, err := ir.DB.QueryContext(ctx, query, invoiceID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.InvoiceLineItem{}
+	for rows.Next() {
+		data := domain.InvoiceLineItem{}
+		err = rows.Scan(&data.ID, &data.InvoiceID, &data.Description, &data.Quantity, &data.UnitPrice, &data.Amount)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row scanner) (domain.Invoice, error) {
+	data := domain.Invoice{}
+	var linkToken sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.CustomerID,
+		&data.Status,
+		&data.Currency,
+		&data.TaxRate,
+		&data.Subtotal,
+		&data.Tax,
+		&data.Total,
+		&data.DueDate,
+		&linkToken,
+		&data.TransactionID,
+	)
+	data.LinkToken = linkToken.String
+
+	return data, err
+}
+
+// nullString keeps drafts, which have no payment link yet, out of the unique
+// index on link_token.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (ir *sqliteInvoiceRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := ir.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,175 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	invoiceRepo "github.com/hezbymuhammad/payment-gateway/invoice/repository/sqlite"
+)
+
+var dueDate = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+var invoiceColumns = []string{"id", "merchant_id", "setting_id", "customer_id", "status", "currency", "tax_rate", "subtotal", "tax", "total", "due_date", "link_token", "transaction_id"}
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.Invoice{
+		MerchantID: 1,
+		SettingID:  1,
+		Status:     domain.InvoiceDraft,
+		Currency:   "IDR",
+		TaxRate:    1100,
+		Subtotal:   100000,
+		Tax:        11000,
+		Total:      111000,
+		DueDate:    dueDate,
+		LineItems: []domain.InvoiceLineItem{
+			{Description: "Coffee beans", Quantity: 2, UnitPrice: 50000, Amount: 100000},
+		},
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO invoices (merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")).
+		WithArgs(1, 1, 0, domain.InvoiceDraft, "IDR", 1100, 100000, 11000, 111000, dueDate, nil, 0).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO invoice_line_items (invoice_id, description, quantity, unit_price, amount) VALUES (?, ?, ?, ?, ?)")).
+		WithArgs(7, "Coffee beans", 2, 50000, 100000).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	err = ir.Store(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), data.ID)
+	assert.Equal(t, int64(7), data.LineItems[0].InvoiceID)
+	assert.Equal(t, int64(3), data.LineItems[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO invoices").WillReturnError(fmt.Errorf("some error"))
+	mock.ExpectRollback()
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	err = ir.Store(context.TODO(), &domain.Invoice{MerchantID: 1})
+	assert.Error(t, err)
+}
+
+func TestGetByLinkToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(invoiceColumns).
+		AddRow(7, 1, 1, 0, domain.InvoiceOpen, "IDR", 1100, 100000, 11000, 111000, dueDate, "inv_1", 0)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, setting_id, customer_id, status, currency, tax_rate, subtotal, tax, total, due_date, link_token, transaction_id FROM invoices WHERE link_token=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs("inv_1").WillReturnRows(rows)
+	items := sqlmock.NewRows([]string{"id", "invoice_id", "description", "quantity", "unit_price", "amount"}).
+		AddRow(3, 7, "Coffee beans", 2, 50000, 100000)
+	query = regexp.QuoteMeta("SELECT id, invoice_id, description, quantity, unit_price, amount FROM invoice_line_items WHERE invoice_id=? ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(7).WillReturnRows(items)
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	res, err := ir.GetByLinkToken(context.TODO(), "inv_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "inv_1", res.LinkToken)
+	assert.Len(t, res.LineItems, 1)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(7).WillReturnRows(sqlmock.NewRows(invoiceColumns))
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	_, err = ir.GetByID(context.TODO(), 7)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestMarkOverdue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE invoices SET status='overdue' WHERE status='open' AND due_date < ?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(dueDate).WillReturnResult(sqlmock.NewResult(0, 2))
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	n, err := ir.MarkOverdue(context.TODO(), dueDate)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestFetchDueReminders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "invoice_id", "remind_at"}).AddRow(1, 7, dueDate.AddDate(0, 0, -3))
+	query := regexp.QuoteMeta("SELECT id, invoice_id, remind_at FROM invoice_reminders WHERE sent_at IS NULL AND remind_at <= ? ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(dueDate).WillReturnRows(rows)
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	res, err := ir.FetchDueReminders(context.TODO(), dueDate)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, int64(7), res[0].InvoiceID)
+}
+
+func TestClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE invoices SET status='processing' WHERE id=? AND status IN ('open', 'overdue')")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	claimed, err := ir.Claim(context.TODO(), 7)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = ir.Claim(context.TODO(), 7)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestUpdateMovedInvoice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE invoices SET status=?, link_token=?, transaction_id=? WHERE id=? AND status=?")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(domain.InvoicePaid, "inv_1", 4, 7, domain.InvoiceProcessing).WillReturnResult(sqlmock.NewResult(0, 0))
+	ir := invoiceRepo.NewInvoiceRepository(db)
+
+	err = ir.Update(context.TODO(), &domain.Invoice{ID: 7, Status: domain.InvoicePaid, LinkToken: "inv_1", TransactionID: 4}, domain.InvoiceProcessing)
+	assert.Equal(t, domain.ErrInvoiceState, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
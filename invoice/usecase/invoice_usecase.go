@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Config holds the invoice settings that come from config.json. BaseURL is
+// where payment links point. ReminderDays lists how many days before the due
+// date a reminder goes out; negative values remind after it.
+type Config struct {
+	BaseURL      string
+	ReminderDays []int
+}
+
+type invoiceUsecase struct {
+	invoiceRepo  domain.InvoiceRepository
+	merchantRepo domain.MerchantRepository
+	customers    domain.CustomerUsecase
+	transactions domain.TransactionUsecase
+	notifier     domain.InvoiceNotifier
+	cfg          Config
+}
+
+func NewInvoiceUsecase(ir domain.InvoiceRepository, mr domain.MerchantRepository, cu domain.CustomerUsecase, tu domain.TransactionUsecase, n domain.InvoiceNotifier, cfg Config) domain.InvoiceUsecase {
+	return &invoiceUsecase{
+		invoiceRepo:  ir,
+		merchantRepo: mr,
+		customers:    cu,
+		transactions: tu,
+		notifier:     n,
+		cfg:          cfg,
+	}
+}
+
+// Store saves a draft invoice. Line item amounts and the totals are always
+// computed here; whatever the caller sent for them is ignored.
+func (iu *invoiceUsecase) Store(ctx context.Context, i *domain.Invoice) error {
+	setting, err := iu.merchantRepo.GetSetting(ctx, i.SettingID)
+	if err != nil {
+		return err
+	}
+	if setting.MerchantID != i.MerchantID {
+		return domain.ErrNotFound
+	}
+
+	if i.CustomerID != 0 {
+		_, err = iu.customers.GetByID(ctx, i.MerchantID, i.CustomerID)
+		if err == domain.ErrNotFound {
+			return domain.ErrInvalidCustomer
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if i.Currency == "" {
+		i.Currency = "IDR"
+	}
+	i.Status = domain.InvoiceDraft
+	i.DueDate = i.DueDate.UTC().Truncate(time.Second)
+	i.LinkToken = ""
+	i.TransactionID = 0
+	total(i)
+
+	return iu.invoiceRepo.Store(ctx, i)
+}
+
+func (iu *invoiceUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Invoice, error) {
+	res, err := iu.invoiceRepo.FetchByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for n := range res {
+		iu.link(&res[n])
+	}
+
+	return res, nil
+}
+
+func (iu *invoiceUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	i, err := iu.invoiceRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	if i.MerchantID != merchantID {
+		return domain.Invoice{}, domain.ErrNotFound
+	}
+
+	iu.link(&i)
+	return i, nil
+}
+
+// Finalize opens a draft invoice: it gets its payment link and its reminders
+// are scheduled.
+func (iu *invoiceUsecase) Finalize(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	i, err := iu.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	if i.Status != domain.InvoiceDraft {
+		return domain.Invoice{}, domain.ErrInvoiceState
+	}
+
+	i.LinkToken, err = newLinkToken()
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	i.Status = domain.InvoiceOpen
+
+	err = iu.invoiceRepo.Update(ctx, &i, domain.InvoiceDraft)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	now := time.Now().UTC()
+	reminders := []domain.InvoiceReminder{}
+	for _, days := range iu.cfg.ReminderDays {
+		at := i.DueDate.AddDate(0, 0, -days)
+		if at.After(now) {
+			reminders = append(reminders, domain.InvoiceReminder{InvoiceID: i.ID, RemindAt: at})
+		}
+	}
+	if len(reminders) > 0 {
+		err = iu.invoiceRepo.StoreReminders(ctx, reminders)
+		if err != nil {
+			return domain.Invoice{}, err
+		}
+	}
+
+	iu.link(&i)
+	return i, nil
+}
+
+func (iu *invoiceUsecase) Void(ctx context.Context, merchantID int64, id int64) (domain.Invoice, error) {
+	i, err := iu.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	if !payable(i) && i.Status != domain.InvoiceDraft {
+		return domain.Invoice{}, domain.ErrInvoiceState
+	}
+
+	from := i.Status
+	i.Status = domain.InvoiceVoid
+	err = iu.invoiceRepo.Update(ctx, &i, from)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	return i, nil
+}
+
+func (iu *invoiceUsecase) GetPage(ctx context.Context, token string) (domain.InvoicePage, error) {
+	i, err := iu.invoiceRepo.GetByLinkToken(ctx, token)
+	if err != nil {
+		return domain.InvoicePage{}, err
+	}
+
+	m, err := iu.merchantRepo.GetByID(ctx, i.MerchantID)
+	if err != nil {
+		return domain.InvoicePage{}, err
+	}
+	s, err := iu.merchantRepo.GetSetting(ctx, i.SettingID)
+	if err != nil {
+		return domain.InvoicePage{}, err
+	}
+
+	iu.link(&i)
+	return domain.InvoicePage{Invoice: i, Merchant: m, Setting: s}, nil
+}
+
+// Pay charges the invoice total to the card in t and captures it straight
+// away. The invoice is claimed first so that two payments on the same link
+// cannot both charge. It is only marked paid once the capture succeeds; a
+// declined payment reopens it so the customer can try another card, and a
+// payment that is neither, such as one held for review, keeps it claimed
+// until RunSchedule sees how the payment ended.
+func (iu *invoiceUsecase) Pay(ctx context.Context, token string, t *domain.Transaction) error {
+	i, err := iu.invoiceRepo.GetByLinkToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !payable(i) {
+		return domain.ErrInvoiceState
+	}
+	claimed, err := iu.invoiceRepo.Claim(ctx, i.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.ErrInvoiceState
+	}
+	from := i.Status
+	i.Status = domain.InvoiceProcessing
+
+	t.MerchantID = i.MerchantID
+	t.ParentMerchantID = i.MerchantID
+	t.SettingID = i.SettingID
+	t.Amount = i.Total
+	t.Currency = i.Currency
+	t.CustomerID = i.CustomerID
+
+	err = iu.transactions.Store(ctx, t)
+	if t.ID == 0 {
+		iu.release(ctx, &i, from)
+		return err
+	}
+	if err == nil && t.State == domain.TransactionAuthorized {
+		var captured domain.Transaction
+		captured, err = iu.transactions.Capture(ctx, t.ID)
+		if err == nil {
+			*t = captured
+		}
+	}
+
+	serr := iu.settle(ctx, &i, from, *t)
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// settle moves a claimed invoice on from how its payment ended: captured
+// pays it, a payment that failed reopens it in the reopen status, and
+// anything else keeps it claimed, linked to the payment.
+func (iu *invoiceUsecase) settle(ctx context.Context, i *domain.Invoice, reopen string, t domain.Transaction) error {
+	switch {
+	case t.State == domain.TransactionCaptured:
+		i.Status = domain.InvoicePaid
+		i.TransactionID = t.ID
+	case failed(t):
+		i.Status = reopen
+		i.TransactionID = 0
+	default:
+		i.TransactionID = t.ID
+	}
+
+	return iu.invoiceRepo.Update(ctx, i, domain.InvoiceProcessing)
+}
+
+// release hands a claimed invoice back when nothing was charged.
+func (iu *invoiceUsecase) release(ctx context.Context, i *domain.Invoice, status string) {
+	i.Status = status
+	err := iu.invoiceRepo.Update(ctx, i, domain.InvoiceProcessing)
+	if err != nil {
+		log.Printf("invoice %d: %v", i.ID, err)
+	}
+}
+
+// RunSchedule settles invoices whose payment has since ended, moves open
+// invoices past their due date to overdue and sends the reminders that are
+// due. A reminder that fails to send is kept and tried again on the next
+// run.
+func (iu *invoiceUsecase) RunSchedule(ctx context.Context, now time.Time) error {
+	now = now.UTC().Truncate(time.Second)
+	var lastErr error
+	processing, err := iu.invoiceRepo.FetchProcessing(ctx)
+	if err != nil {
+		return err
+	}
+	for n := range processing {
+		err = iu.followUp(ctx, &processing[n])
+		if err != nil {
+			log.Printf("invoice %d: %v", processing[n].ID, err)
+			lastErr = err
+		}
+	}
+
+	_, err = iu.invoiceRepo.MarkOverdue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	reminders, err := iu.invoiceRepo.FetchDueReminders(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for n := range reminders {
+		r := reminders[n]
+		err = iu.remind(ctx, &r, now)
+		if err != nil {
+			log.Printf("invoice %d: %v", r.InvoiceID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// followUp settles a claimed invoice once its payment has moved on, for
+// example out of review. An authorized payment is captured, as Pay would
+// have. A reopened invoice goes back to open; MarkOverdue catches one that
+// is past due.
+func (iu *invoiceUsecase) followUp(ctx context.Context, i *domain.Invoice) error {
+	t, err := iu.transactions.GetByID(ctx, i.TransactionID)
+	if err != nil {
+		return err
+	}
+	if t.State == domain.TransactionAuthorized {
+		t, err = iu.transactions.Capture(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if t.State != domain.TransactionCaptured && !failed(t) {
+		return nil
+	}
+
+	return iu.settle(ctx, i, domain.InvoiceOpen, t)
+}
+
+func (iu *invoiceUsecase) remind(ctx context.Context, r *domain.InvoiceReminder, now time.Time) error {
+	i, err := iu.invoiceRepo.GetByID(ctx, r.InvoiceID)
+	if err != nil {
+		return err
+	}
+
+	if payable(i) {
+		iu.link(&i)
+		err = iu.notifier.SendReminder(ctx, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.SentAt = now
+	return iu.invoiceRepo.MarkReminderSent(ctx, r)
+}
+
+func (iu *invoiceUsecase) link(i *domain.Invoice) {
+	if i.LinkToken != "" {
+		i.PaymentLink = iu.cfg.BaseURL + "/pay/" + i.LinkToken
+	}
+}
+
+func payable(i domain.Invoice) bool {
+	return i.Status == domain.InvoiceOpen || i.Status == domain.InvoiceOverdue
+}
+
+// failed tells whether a payment has ended without taking any money.
+func failed(t domain.Transaction) bool {
+	return t.State == domain.TransactionDeclined || t.State == domain.TransactionFailed || t.State == domain.TransactionVoided
+}
+
+// total fills in line item amounts, the subtotal, tax rounded half up, and
+// the total.
+func total(i *domain.Invoice) {
+	i.Subtotal = 0
+	for n := range i.LineItems {
+		item := &i.LineItems[n]
+		item.Amount = item.Quantity * item.UnitPrice
+		i.Subtotal += item.Amount
+	}
+	i.Tax = (i.Subtotal*i.TaxRate + 5000) / 10000
+	i.Total = i.Subtotal + i.Tax
+}
+
+func newLinkToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "inv_" + hex.EncodeToString(b), nil
+}
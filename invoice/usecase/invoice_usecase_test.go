@@ -0,0 +1,375 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	invoiceRepo "github.com/hezbymuhammad/payment-gateway/invoice/repository/sqlite"
+	invoiceUsecase "github.com/hezbymuhammad/payment-gateway/invoice/usecase"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+var cfg = invoiceUsecase.Config{BaseURL: "https://pay.example.com", ReminderDays: []int{3, -1}}
+
+func openInvoice() domain.Invoice {
+	return domain.Invoice{ID: 7, MerchantID: 1, SettingID: 1, Status: domain.InvoiceOpen, Currency: "IDR", Total: 111000, LinkToken: "inv_1"}
+}
+
+func TestStoreComputesTotals(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(domain.Setting{ID: 1, MerchantID: 1}, nil).Once()
+	mockInvoiceRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	data := &domain.Invoice{
+		MerchantID: 1,
+		SettingID:  1,
+		TaxRate:    1100,
+		Total:      1,
+		LineItems: []domain.InvoiceLineItem{
+			{Description: "Coffee beans", Quantity: 2, UnitPrice: 50000},
+			{Description: "Filter paper", Quantity: 1, UnitPrice: 12345},
+		},
+	}
+	err := u.Store(context.TODO(), data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.InvoiceDraft, data.Status)
+	assert.Equal(t, "IDR", data.Currency)
+	assert.Equal(t, int64(100000), data.LineItems[0].Amount)
+	assert.Equal(t, int64(112345), data.Subtotal)
+	assert.Equal(t, int64(12358), data.Tax)
+	assert.Equal(t, int64(124703), data.Total)
+}
+
+func TestStoreWithForeignSetting(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(2)).Return(domain.Setting{ID: 2, MerchantID: 2}, nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Store(context.TODO(), &domain.Invoice{MerchantID: 1, SettingID: 2})
+
+	assert.Equal(t, domain.ErrNotFound, err)
+	mockInvoiceRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestFinalize(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	draft := openInvoice()
+	draft.Status = domain.InvoiceDraft
+	draft.LinkToken = ""
+	draft.DueDate = time.Now().UTC().AddDate(0, 0, 10)
+	mockInvoiceRepo.On("GetByID", mock.Anything, int64(7)).Return(draft, nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.Anything, domain.InvoiceDraft).Return(nil).Once()
+	mockInvoiceRepo.On("StoreReminders", mock.Anything, mock.MatchedBy(func(r []domain.InvoiceReminder) bool {
+		return len(r) == 2 && r[0].RemindAt.Equal(draft.DueDate.AddDate(0, 0, -3)) && r[1].RemindAt.Equal(draft.DueDate.AddDate(0, 0, 1))
+	})).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	res, err := u.Finalize(context.TODO(), 1, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.InvoiceOpen, res.Status)
+	assert.True(t, strings.HasPrefix(res.PaymentLink, "https://pay.example.com/pay/inv_"))
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestFinalizeOpenInvoice(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByID", mock.Anything, int64(7)).Return(openInvoice(), nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	_, err := u.Finalize(context.TODO(), 1, 7)
+
+	assert.Equal(t, domain.ErrInvoiceState, err)
+}
+
+func TestPay(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+		return t.MerchantID == 1 && t.Amount == 111000 && t.CardToken == "tok_1"
+	})).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.Status == domain.InvoicePaid && i.TransactionID == 4
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	data := &domain.Transaction{CardToken: "tok_1"}
+	err := u.Pay(context.TODO(), "inv_1", data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TransactionCaptured, data.State)
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestPayDeclined(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	overdue := openInvoice()
+	overdue.Status = domain.InvoiceOverdue
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(overdue, nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionDeclined
+	}).Return(nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.Status == domain.InvoiceOverdue && i.TransactionID == 0
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.NoError(t, err)
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestPayRefused(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Return(domain.ErrInvalidCard).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.Status == domain.InvoiceOpen
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.Equal(t, domain.ErrInvalidCard, err)
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestPayHeldForReview(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionReview
+	}).Return(nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.Status == domain.InvoiceProcessing && i.TransactionID == 4
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.NoError(t, err)
+	mockInvoiceRepo.AssertExpectations(t)
+	mockTransactions.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+}
+
+func TestPayCaptureFails(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{}, domain.ErrProcessorTimeout).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.Status == domain.InvoiceProcessing && i.TransactionID == 4
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.Equal(t, domain.ErrProcessorTimeout, err)
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestPayClaimed(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("Claim", mock.Anything, int64(7)).Return(false, nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.Equal(t, domain.ErrInvoiceState, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestPayConcurrently(t *testing.T) {
+	ir := invoiceRepo.NewInvoiceRepository(migrationtest.NewDB(t))
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	i := openInvoice()
+	i.ID = 0
+	i.DueDate = time.Now().UTC().AddDate(0, 0, 10).Truncate(time.Second)
+	assert.NoError(t, ir.Store(context.TODO(), &i))
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+		time.Sleep(10 * time.Millisecond)
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(ir, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	errs := make(chan error, 5)
+	var wg sync.WaitGroup
+	for n := 0; n < 5; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	paid := 0
+	for err := range errs {
+		if err == nil {
+			paid++
+			continue
+		}
+		assert.Equal(t, domain.ErrInvoiceState, err)
+	}
+	assert.Equal(t, 1, paid)
+	mockTransactions.AssertNumberOfCalls(t, "Store", 1)
+	res, err := ir.GetByID(context.TODO(), i.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.InvoicePaid, res.Status)
+	assert.Equal(t, int64(4), res.TransactionID)
+}
+
+func TestPayPaidInvoice(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	paid := openInvoice()
+	paid.Status = domain.InvoicePaid
+	mockInvoiceRepo.On("GetByLinkToken", mock.Anything, "inv_1").Return(paid, nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.Pay(context.TODO(), "inv_1", &domain.Transaction{CardToken: "tok_1"})
+
+	assert.Equal(t, domain.ErrInvoiceState, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestRunSchedule(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	now := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	paid := openInvoice()
+	paid.ID = 8
+	paid.Status = domain.InvoicePaid
+	mockInvoiceRepo.On("FetchProcessing", mock.Anything).Return([]domain.Invoice{}, nil).Once()
+	mockInvoiceRepo.On("MarkOverdue", mock.Anything, now).Return(int64(1), nil).Once()
+	mockInvoiceRepo.On("FetchDueReminders", mock.Anything, now).Return([]domain.InvoiceReminder{{ID: 1, InvoiceID: 7}, {ID: 2, InvoiceID: 8}}, nil).Once()
+	mockInvoiceRepo.On("GetByID", mock.Anything, int64(7)).Return(openInvoice(), nil).Once()
+	mockInvoiceRepo.On("GetByID", mock.Anything, int64(8)).Return(paid, nil).Once()
+	mockNotifier.On("SendReminder", mock.Anything, mock.MatchedBy(func(i domain.Invoice) bool {
+		return i.ID == 7 && i.PaymentLink == "https://pay.example.com/pay/inv_1"
+	})).Return(nil).Once()
+	mockInvoiceRepo.On("MarkReminderSent", mock.Anything, mock.Anything).Return(nil).Twice()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.RunSchedule(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockNotifier.AssertExpectations(t)
+	mockInvoiceRepo.AssertExpectations(t)
+}
+
+func TestRunScheduleFollowsUpPayments(t *testing.T) {
+	mockInvoiceRepo := new(mocks.InvoiceRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockNotifier := new(mocks.InvoiceNotifier)
+	now := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	approved, rejected, held := openInvoice(), openInvoice(), openInvoice()
+	approved.Status, approved.TransactionID = domain.InvoiceProcessing, 4
+	rejected.ID, rejected.Status, rejected.TransactionID = 8, domain.InvoiceProcessing, 5
+	held.ID, held.Status, held.TransactionID = 9, domain.InvoiceProcessing, 6
+	mockInvoiceRepo.On("FetchProcessing", mock.Anything).Return([]domain.Invoice{approved, rejected, held}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionAuthorized}, nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(5)).Return(domain.Transaction{ID: 5, State: domain.TransactionDeclined}, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(6)).Return(domain.Transaction{ID: 6, State: domain.TransactionReview}, nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.ID == 7 && i.Status == domain.InvoicePaid && i.TransactionID == 4
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	mockInvoiceRepo.On("Update", mock.Anything, mock.MatchedBy(func(i *domain.Invoice) bool {
+		return i.ID == 8 && i.Status == domain.InvoiceOpen && i.TransactionID == 0
+	}), domain.InvoiceProcessing).Return(nil).Once()
+	mockInvoiceRepo.On("MarkOverdue", mock.Anything, now).Return(int64(1), nil).Once()
+	mockInvoiceRepo.On("FetchDueReminders", mock.Anything, now).Return([]domain.InvoiceReminder{}, nil).Once()
+	u := invoiceUsecase.NewInvoiceUsecase(mockInvoiceRepo, mockMerchantRepo, mockCustomers, mockTransactions, mockNotifier, cfg)
+
+	err := u.RunSchedule(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockInvoiceRepo.AssertExpectations(t)
+	mockTransactions.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type LimitHandler struct {
+	Usecase domain.LimitUsecase
+}
+
+func NewLimitHandler(e *echo.Echo, u domain.LimitUsecase) *LimitHandler {
+	handler := &LimitHandler{
+		Usecase: u,
+	}
+
+	e.PUT("/limits", handler.Store)
+	e.GET("/limits", handler.Fetch)
+	e.GET("/limits/status", handler.Status)
+
+	return handler
+}
+
+// Store sets a merchant's limit for one payment type, or for all of them
+// when paymentType is left out.
+func (h *LimitHandler) Store(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Limit
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.MaxAmount < 0 || data.DailyVolume < 0 || data.MonthlyVolume < 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Store(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *LimitHandler) Fetch(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, merchantID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *LimitHandler) Status(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Status(ctx, merchantID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
@@ -0,0 +1,59 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	limitHttp "github.com/hezbymuhammad/payment-gateway/limit/delivery/http"
+)
+
+func TestStore(t *testing.T) {
+	mockUCase := new(mocks.LimitUsecase)
+	mockUCase.On("Store", mock.Anything, mock.MatchedBy(func(l *domain.Limit) bool {
+		return l.MerchantID == 6 && l.PaymentType == "CARD" && l.DailyVolume == 500000
+	})).Return(nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/limits", strings.NewReader(`{"merchantId":6,"paymentType":"CARD","dailyVolume":500000}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := limitHttp.LimitHandler{
+		Usecase: mockUCase,
+	}
+	err = handler.Store(c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUCase.AssertExpectations(t)
+}
+
+func TestStoreNegative(t *testing.T) {
+	mockUCase := new(mocks.LimitUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/limits", strings.NewReader(`{"merchantId":6,"maxAmount":-1}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	handler := limitHttp.LimitHandler{
+		Usecase: mockUCase,
+	}
+	err = handler.Store(c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUCase.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	limitRepo "github.com/hezbymuhammad/payment-gateway/limit/repository/memory"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.LimitRepository(t, func(t *testing.T) (domain.LimitRepository, domain.MerchantRepository) {
+		mr := merchantRepo.NewMerchantRepository()
+		return limitRepo.NewLimitRepository(mr), mr
+	})
+}
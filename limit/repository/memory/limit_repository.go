@@ -0,0 +1,151 @@
+// Package memory keeps merchant limits in process memory, for tests and
+// demos that should not need a database. It behaves like the SQL
+// repositories.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Groups is where effective limits find a merchant's parents. The
+// in-memory merchant repository is one.
+type Groups interface {
+	Groups() []domain.MerchantGroup
+}
+
+type limitKey struct {
+	merchantID  int64
+	paymentType string
+}
+
+type usageKey struct {
+	limitKey
+	period string
+}
+
+// LimitRepository is a domain.LimitRepository safe for concurrent use.
+type LimitRepository struct {
+	mu     sync.RWMutex
+	limits map[limitKey]domain.Limit
+	usages map[usageKey]int64
+	groups Groups
+}
+
+var _ domain.LimitRepository = (*LimitRepository)(nil)
+
+func NewLimitRepository(groups Groups) *LimitRepository {
+	return &LimitRepository{
+		limits: map[limitKey]domain.Limit{},
+		usages: map[usageKey]int64{},
+		groups: groups,
+	}
+}
+
+// Upsert sets the merchant's limit for the payment type, replacing any it
+// had.
+func (lr *LimitRepository) Upsert(ctx context.Context, l *domain.Limit) error {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	stored := *l
+	stored.UpdatedAt = stored.UpdatedAt.UTC()
+	lr.limits[limitKey{l.MerchantID, l.PaymentType}] = stored
+
+	return nil
+}
+
+func (lr *LimitRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	return lr.fetch(map[int64]bool{merchantID: true}), nil
+}
+
+// FetchEffective lists the limits of the merchant and of every merchant
+// above it in the groups. Each merchant is visited once, so a cycle in the
+// groups ends the walk.
+func (lr *LimitRepository) FetchEffective(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	groups := lr.groups.Groups()
+	ancestors := map[int64]bool{merchantID: true}
+	next := []int64{merchantID}
+	for len(next) > 0 {
+		id := next[0]
+		next = next[1:]
+		for _, g := range groups {
+			if g.ChildMerchantID == id && !ancestors[g.ParentMerchantID] {
+				ancestors[g.ParentMerchantID] = true
+				next = append(next, g.ParentMerchantID)
+			}
+		}
+	}
+
+	return lr.fetch(ancestors), nil
+}
+
+func (lr *LimitRepository) Usage(ctx context.Context, merchantID int64, paymentType string, period string) (int64, error) {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+
+	return lr.usages[usageKey{limitKey{merchantID, paymentType}, period}], nil
+}
+
+// Reserve adds amount to the merchant's volume for the period unless that
+// would take it over cap; a zero cap always succeeds. The first
+// reservation of a period is taken unchecked, so callers must not pass an
+// amount above cap.
+func (lr *LimitRepository) Reserve(ctx context.Context, merchantID int64, paymentType string, period string, amount int64, cap int64) (bool, error) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	key := usageKey{limitKey{merchantID, paymentType}, period}
+	volume, ok := lr.usages[key]
+	if ok && cap != 0 && volume+amount > cap {
+		return false, nil
+	}
+	lr.usages[key] = volume + amount
+
+	return true, nil
+}
+
+// Release hands back volume reserved for a transaction that did not go
+// through.
+func (lr *LimitRepository) Release(ctx context.Context, merchantID int64, paymentType string, period string, amount int64) error {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	key := usageKey{limitKey{merchantID, paymentType}, period}
+	volume, ok := lr.usages[key]
+	if !ok {
+		return nil
+	}
+	volume -= amount
+	if volume < 0 {
+		volume = 0
+	}
+	lr.usages[key] = volume
+
+	return nil
+}
+
+// fetch lists the limits of the given merchants by merchant and payment
+// type.
+func (lr *LimitRepository) fetch(merchantIDs map[int64]bool) []domain.Limit {
+	lr.mu.RLock()
+	defer lr.mu.RUnlock()
+
+	result := []domain.Limit{}
+	for key, l := range lr.limits {
+		if merchantIDs[key.merchantID] {
+			result = append(result, l)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].MerchantID != result[j].MerchantID {
+			return result[i].MerchantID < result[j].MerchantID
+		}
+		return result[i].PaymentType < result[j].PaymentType
+	})
+
+	return result
+}
@@ -0,0 +1,21 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	limitRepo "github.com/hezbymuhammad/payment-gateway/limit/repository/postgres"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.LimitRepository(t, func(t *testing.T) (domain.LimitRepository, domain.MerchantRepository) {
+		db := migrationtest.NewPostgresDB(t)
+		return limitRepo.NewLimitRepository(db), merchantRepo.NewMerchantRepository(db)
+	})
+}
@@ -0,0 +1,18 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	limitRepo "github.com/hezbymuhammad/payment-gateway/limit/repository/sqlite"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.LimitRepository(t, func(t *testing.T) (domain.LimitRepository, domain.MerchantRepository) {
+		db := migrationtest.NewDB(t)
+		return limitRepo.NewLimitRepository(db), merchantRepo.NewMerchantRepository(db)
+	})
+}
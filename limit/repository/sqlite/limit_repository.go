@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const limitColumns = "merchant_id, payment_type, max_amount, daily_volume, monthly_volume, updated_at"
+
+type sqliteLimitRepo struct {
+	DB *sql.DB
+}
+
+func NewLimitRepository(db *sql.DB) domain.LimitRepository {
+	return &sqliteLimitRepo{
+		DB: db,
+	}
+}
+
+// Upsert sets the merchant's limit for the payment type, replacing any it
+// had.
+func (lr *sqliteLimitRepo) Upsert(ctx context.Context, l *domain.Limit) error {
+	query := "INSERT INTO limits (merchant_id, payment_type, max_amount, daily_volume, monthly_volume, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(merchant_id, payment_type) DO UPDATE SET max_amount=excluded.max_amount, daily_volume=excluded.daily_volume, monthly_volume=excluded.monthly_volume, updated_at=excluded.updated_at"
+
+	_, err := lr.exec(ctx, query, l.MerchantID, l.PaymentType, l.MaxAmount, l.DailyVolume, l.MonthlyVolume, l.UpdatedAt)
+	return err
+}
+
+func (lr *sqliteLimitRepo) Fetch(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	query := "SELECT " + limitColumns + " FROM limits WHERE merchant_id=? ORDER BY payment_type"
+
+	return lr.fetch(ctx, query, merchantID)
+}
+
+// FetchEffective lists the limits of the merchant and of every merchant
+// above it in merchant_groups. UNION rather than UNION ALL keeps a cycle in
+// the groups from recursing forever.
+func (lr *sqliteLimitRepo) FetchEffective(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	query := "WITH RECURSIVE ancestors(id) AS (SELECT ? UNION SELECT merchant_groups.parent_merchant_id FROM merchant_groups JOIN ancestors ON merchant_groups.child_merchant_id = ancestors.id) SELECT " + limitColumns + " FROM limits WHERE merchant_id IN (SELECT id FROM ancestors) ORDER BY merchant_id, payment_type"
+
+	return lr.fetch(ctx, query, merchantID)
+}
+
+func (lr *sqliteLimitRepo) Usage(ctx context.Context, merchantID int64, paymentType string, period string) (int64, error) {
+	query := "SELECT volume FROM limit_usages WHERE merchant_id=? AND payment_type=? AND period=?"
+
+	var volume int64
+	err := lr.DB.QueryRowContext(ctx, query, merchantID, paymentType, period).Scan(&volume)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	return volume, nil
+}
+
+// Reserve adds amount to the merchant's volume for the period unless that
+// would take it over cap; a zero cap always succeeds. It is a single
+// conditional statement so concurrent reservations cannot overshoot. The
+// first reservation of a period inserts the row unchecked, so callers must
+// not pass an amount above cap.
+func (lr *sqliteLimitRepo) Reserve(ctx context.Context, merchantID int64, paymentType string, period string, amount int64, cap int64) (bool, error) {
+	query := "INSERT INTO limit_usages (merchant_id, payment_type, period, volume) VALUES (?, ?, ?, ?) ON CONFLICT(merchant_id, payment_type, period) DO UPDATE SET volume = volume + excluded.volume WHERE ? = 0 OR volume + excluded.volume <= ?"
+
+	res, err := lr.exec(ctx, query, merchantID, paymentType, period, amount, cap, cap)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Release hands back volume reserved for a transaction that did not go
+// through.
+func (lr *sqliteLimitRepo) Release(ctx context.Context, merchantID int64, paymentType string, period string, amount int64) error {
+	query := "UPDATE limit_usages SET volume = MAX(volume - ?, 0) WHERE merchant_id=? AND payment_type=? AND period=?"
+
+	_, err := lr.exec(ctx, query, amount, merchantID, paymentType, period)
+	return err
+}
+
+func (lr *sqliteLimitRepo) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.Limit, error) {
+	rows, err := lr.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Limit{}
+	for rows.Next() {
+		data := domain.Limit{}
+		err := rows.Scan(
+			&data.MerchantID,
+			&data.PaymentType,
+			&data.MaxAmount,
+			&data.DailyVolume,
+			&data.MonthlyVolume,
+			&data.UpdatedAt,
+		)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (lr *sqliteLimitRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := lr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
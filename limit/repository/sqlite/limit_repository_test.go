@@ -0,0 +1,70 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	limitRepo "github.com/hezbymuhammad/payment-gateway/limit/repository/sqlite"
+)
+
+func TestReserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("INSERT INTO limit_usages (merchant_id, payment_type, period, volume) VALUES (?, ?, ?, ?) ON CONFLICT(merchant_id, payment_type, period) DO UPDATE SET volume = volume + excluded.volume WHERE ? = 0 OR volume + excluded.volume <= ?")
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(6, "CARD", "2026-11-01", 100000, 500000, 500000).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(query).ExpectExec().WithArgs(6, "CARD", "2026-11-01", 100000, 500000, 500000).WillReturnResult(sqlmock.NewResult(0, 0))
+	lr := limitRepo.NewLimitRepository(db)
+
+	ok, err := lr.Reserve(context.TODO(), 6, "CARD", "2026-11-01", 100000, 500000)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = lr.Reserve(context.TODO(), 6, "CARD", "2026-11-01", 100000, 500000)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestUsageWithoutRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("SELECT volume FROM limit_usages WHERE merchant_id=? AND payment_type=? AND period=?")
+	mock.ExpectQuery(query).WithArgs(6, "", "2026-11").WillReturnRows(sqlmock.NewRows([]string{"volume"}))
+	lr := limitRepo.NewLimitRepository(db)
+
+	used, err := lr.Usage(context.TODO(), 6, "", "2026-11")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), used)
+}
+
+func TestFetchEffective(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	updatedAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"merchant_id", "payment_type", "max_amount", "daily_volume", "monthly_volume", "updated_at"}).
+		AddRow(1, "CARD", 0, 300000, 0, updatedAt).
+		AddRow(2, "", 1000000, 0, 0, updatedAt)
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE ancestors(id)")).WithArgs(2).WillReturnRows(rows)
+	lr := limitRepo.NewLimitRepository(db)
+
+	res, err := lr.FetchEffective(context.TODO(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Limit{
+		{MerchantID: 1, PaymentType: "CARD", DailyVolume: 300000, UpdatedAt: updatedAt},
+		{MerchantID: 2, MaxAmount: 1000000, UpdatedAt: updatedAt},
+	}, res)
+}
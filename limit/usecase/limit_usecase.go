@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Config holds the limit settings that come from config.json. Days and
+// months are counted in Location.
+type Config struct {
+	Location *time.Location
+}
+
+type limitUsecase struct {
+	limitRepo    domain.LimitRepository
+	merchantRepo domain.MerchantRepository
+	cfg          Config
+}
+
+func NewLimitUsecase(lr domain.LimitRepository, mr domain.MerchantRepository, cfg Config) domain.LimitUsecase {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+
+	return &limitUsecase{
+		limitRepo:    lr,
+		merchantRepo: mr,
+		cfg:          cfg,
+	}
+}
+
+func (lu *limitUsecase) Store(ctx context.Context, l *domain.Limit) error {
+	_, err := lu.merchantRepo.GetByID(ctx, l.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	l.PaymentType = strings.ToUpper(l.PaymentType)
+	l.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return lu.limitRepo.Upsert(ctx, l)
+}
+
+func (lu *limitUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Limit, error) {
+	return lu.limitRepo.Fetch(ctx, merchantID)
+}
+
+// Status reports every cap the merchant is held to, inherited ones
+// included, with what is left of it today and this month.
+func (lu *limitUsecase) Status(ctx context.Context, merchantID int64) ([]domain.LimitStatus, error) {
+	limits, err := lu.limitRepo.FetchEffective(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{""}
+	seen := map[string]bool{"": true}
+	for _, l := range limits {
+		if !seen[l.PaymentType] {
+			seen[l.PaymentType] = true
+			scopes = append(scopes, l.PaymentType)
+		}
+	}
+
+	result := []domain.LimitStatus{}
+	now := time.Now()
+	for _, scope := range scopes {
+		max := maxAmount(limits, scope, true)
+		if max.Cap > 0 {
+			result = append(result, max)
+		}
+		for _, c := range lu.counters(limits, scope, now) {
+			if c.paymentType != scope || c.cap == 0 {
+				continue
+			}
+			used, err := lu.limitRepo.Usage(ctx, merchantID, c.paymentType, c.period)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, status(c, used))
+		}
+	}
+
+	return result, nil
+}
+
+// Reserve takes the transaction's amount off the merchant's daily and
+// monthly volume, both overall and for its payment type. Every counter is
+// moved even without a cap on it, so that a cap added later starts from the
+// real volume. If any cap would be exceeded the counters already moved are
+// handed back and a *domain.LimitError is returned.
+func (lu *limitUsecase) Reserve(ctx context.Context, t *domain.Transaction) error {
+	limits, err := lu.limitRepo.FetchEffective(ctx, t.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	amount := volume(t)
+	max := maxAmount(limits, t.PaymentType, false)
+	if max.Cap > 0 && amount > max.Cap {
+		return &domain.LimitError{LimitStatus: max}
+	}
+
+	counters := lu.counters(limits, t.PaymentType, t.CreatedAt)
+	for i, c := range counters {
+		ok := c.cap == 0 || amount <= c.cap
+		if ok {
+			ok, err = lu.limitRepo.Reserve(ctx, t.MerchantID, c.paymentType, c.period, amount, c.cap)
+		}
+		if err != nil || !ok {
+			lu.release(ctx, t.MerchantID, counters[:i], amount)
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return lu.exceeded(ctx, t.MerchantID, c)
+		}
+	}
+
+	return nil
+}
+
+// Release hands back what Reserve took for a transaction that did not go
+// through.
+func (lu *limitUsecase) Release(ctx context.Context, t *domain.Transaction) error {
+	return lu.release(ctx, t.MerchantID, lu.counters(nil, t.PaymentType, t.CreatedAt), volume(t))
+}
+
+func (lu *limitUsecase) release(ctx context.Context, merchantID int64, counters []counter, amount int64) error {
+	var lastErr error
+	for _, c := range counters {
+		err := lu.limitRepo.Release(ctx, merchantID, c.paymentType, c.period, amount)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (lu *limitUsecase) exceeded(ctx context.Context, merchantID int64, c counter) error {
+	used, err := lu.limitRepo.Usage(ctx, merchantID, c.paymentType, c.period)
+	if err != nil {
+		return err
+	}
+
+	return &domain.LimitError{LimitStatus: status(c, used)}
+}
+
+// counter is one volume total a transaction counts towards, with the
+// tightest cap any applicable limit puts on it.
+type counter struct {
+	limit       string
+	paymentType string
+	period      string
+	cap         int64
+}
+
+// counters lists the daily and monthly totals for all payment types and,
+// when given one, for paymentType.
+func (lu *limitUsecase) counters(limits []domain.Limit, paymentType string, at time.Time) []counter {
+	at = at.In(lu.cfg.Location)
+	day := at.Format("2006-01-02")
+	month := at.Format("2006-01")
+
+	counters := []counter{
+		{limit: domain.LimitDailyVolume, period: day},
+		{limit: domain.LimitMonthlyVolume, period: month},
+	}
+	if paymentType != "" {
+		counters = append(counters,
+			counter{limit: domain.LimitDailyVolume, paymentType: paymentType, period: day},
+			counter{limit: domain.LimitMonthlyVolume, paymentType: paymentType, period: month},
+		)
+	}
+
+	for i, c := range counters {
+		for _, l := range limits {
+			if l.PaymentType != c.paymentType {
+				continue
+			}
+			if c.limit == domain.LimitDailyVolume {
+				counters[i].cap = tighter(counters[i].cap, l.DailyVolume)
+			} else {
+				counters[i].cap = tighter(counters[i].cap, l.MonthlyVolume)
+			}
+		}
+	}
+
+	return counters
+}
+
+// maxAmount finds the tightest single transaction cap for a payment type.
+// Unless exact is set, limits covering all payment types count too.
+func maxAmount(limits []domain.Limit, paymentType string, exact bool) domain.LimitStatus {
+	max := domain.LimitStatus{Limit: domain.LimitMaxAmount}
+	for _, l := range limits {
+		if l.PaymentType != paymentType && (exact || l.PaymentType != "") {
+			continue
+		}
+		if cap := tighter(max.Cap, l.MaxAmount); cap != max.Cap {
+			max.Cap = cap
+			max.PaymentType = l.PaymentType
+		}
+	}
+	max.Remaining = max.Cap
+
+	return max
+}
+
+func status(c counter, used int64) domain.LimitStatus {
+	remaining := c.cap - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return domain.LimitStatus{
+		Limit:       c.limit,
+		PaymentType: c.paymentType,
+		Cap:         c.cap,
+		Used:        used,
+		Remaining:   remaining,
+	}
+}
+
+// tighter picks the lower of two caps, zero meaning no cap.
+func tighter(a int64, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+
+	return a
+}
+
+// volume is what a transaction counts towards the caps: the rupiah amount
+// charged, before any installment fee is taken off.
+func volume(t *domain.Transaction) int64 {
+	return t.SettlementAmount + t.Fee
+}
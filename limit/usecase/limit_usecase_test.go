@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	limitUsecase "github.com/hezbymuhammad/payment-gateway/limit/usecase"
+)
+
+var jakarta = time.FixedZone("WIB", 7*60*60)
+
+// createdAt is late on 31 October in UTC, which is already 1 November in
+// Jakarta.
+var createdAt = time.Date(2026, 10, 31, 20, 0, 0, 0, time.UTC)
+
+func TestReserve(t *testing.T) {
+	mockLimitRepo := new(mocks.LimitRepository)
+	limits := []domain.Limit{
+		{MerchantID: 2, DailyVolume: 500000},
+		{MerchantID: 1, PaymentType: "CARD", MonthlyVolume: 2000000},
+		{MerchantID: 2, DailyVolume: 800000, MonthlyVolume: 3000000},
+	}
+	mockLimitRepo.On("FetchEffective", mock.Anything, int64(2)).Return(limits, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "", "2026-11-01", int64(100000), int64(500000)).Return(true, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "", "2026-11", int64(100000), int64(3000000)).Return(true, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "CARD", "2026-11-01", int64(100000), int64(0)).Return(true, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "CARD", "2026-11", int64(100000), int64(2000000)).Return(true, nil).Once()
+	u := limitUsecase.NewLimitUsecase(mockLimitRepo, new(mocks.MerchantRepository), limitUsecase.Config{Location: jakarta})
+
+	err := u.Reserve(context.TODO(), &domain.Transaction{MerchantID: 2, PaymentType: "CARD", SettlementAmount: 100000, CreatedAt: createdAt})
+
+	assert.NoError(t, err)
+	mockLimitRepo.AssertExpectations(t)
+}
+
+func TestReserveOverMaxAmount(t *testing.T) {
+	mockLimitRepo := new(mocks.LimitRepository)
+	limits := []domain.Limit{
+		{MerchantID: 1, MaxAmount: 5000000},
+		{MerchantID: 2, PaymentType: "CARD", MaxAmount: 1000000},
+		{MerchantID: 2, PaymentType: "QR", MaxAmount: 100000},
+	}
+	mockLimitRepo.On("FetchEffective", mock.Anything, int64(2)).Return(limits, nil).Once()
+	u := limitUsecase.NewLimitUsecase(mockLimitRepo, new(mocks.MerchantRepository), limitUsecase.Config{Location: jakarta})
+
+	err := u.Reserve(context.TODO(), &domain.Transaction{MerchantID: 2, PaymentType: "CARD", SettlementAmount: 1500000, CreatedAt: createdAt})
+
+	le, ok := err.(*domain.LimitError)
+	assert.True(t, ok)
+	assert.Equal(t, domain.LimitStatus{Limit: domain.LimitMaxAmount, PaymentType: "CARD", Cap: 1000000, Remaining: 1000000}, le.LimitStatus)
+	mockLimitRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReserveOverDailyVolume(t *testing.T) {
+	mockLimitRepo := new(mocks.LimitRepository)
+	limits := []domain.Limit{
+		{MerchantID: 2, MonthlyVolume: 3000000},
+		{MerchantID: 2, PaymentType: "CARD", DailyVolume: 500000},
+	}
+	mockLimitRepo.On("FetchEffective", mock.Anything, int64(2)).Return(limits, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "", "2026-11-01", int64(100000), int64(0)).Return(true, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "", "2026-11", int64(100000), int64(3000000)).Return(true, nil).Once()
+	mockLimitRepo.On("Reserve", mock.Anything, int64(2), "CARD", "2026-11-01", int64(100000), int64(500000)).Return(false, nil).Once()
+	mockLimitRepo.On("Release", mock.Anything, int64(2), "", "2026-11-01", int64(100000)).Return(nil).Once()
+	mockLimitRepo.On("Release", mock.Anything, int64(2), "", "2026-11", int64(100000)).Return(nil).Once()
+	mockLimitRepo.On("Usage", mock.Anything, int64(2), "CARD", "2026-11-01").Return(int64(450000), nil).Once()
+	u := limitUsecase.NewLimitUsecase(mockLimitRepo, new(mocks.MerchantRepository), limitUsecase.Config{Location: jakarta})
+
+	err := u.Reserve(context.TODO(), &domain.Transaction{MerchantID: 2, PaymentType: "CARD", SettlementAmount: 100000, CreatedAt: createdAt})
+
+	le, ok := err.(*domain.LimitError)
+	assert.True(t, ok)
+	assert.Equal(t, domain.LimitStatus{Limit: domain.LimitDailyVolume, PaymentType: "CARD", Cap: 500000, Used: 450000, Remaining: 50000}, le.LimitStatus)
+	mockLimitRepo.AssertExpectations(t)
+}
+
+func TestRelease(t *testing.T) {
+	mockLimitRepo := new(mocks.LimitRepository)
+	for _, scope := range []string{"", "QR"} {
+		mockLimitRepo.On("Release", mock.Anything, int64(6), scope, "2026-11-01", int64(50000)).Return(nil).Once()
+		mockLimitRepo.On("Release", mock.Anything, int64(6), scope, "2026-11", int64(50000)).Return(nil).Once()
+	}
+	u := limitUsecase.NewLimitUsecase(mockLimitRepo, new(mocks.MerchantRepository), limitUsecase.Config{Location: jakarta})
+
+	err := u.Release(context.TODO(), &domain.Transaction{MerchantID: 6, PaymentType: "QR", SettlementAmount: 50000, CreatedAt: createdAt})
+
+	assert.NoError(t, err)
+	mockLimitRepo.AssertExpectations(t)
+}
+
+func TestStatus(t *testing.T) {
+	mockLimitRepo := new(mocks.LimitRepository)
+	limits := []domain.Limit{
+		{MerchantID: 6, MaxAmount: 1000000, DailyVolume: 500000},
+		{MerchantID: 6, PaymentType: "CARD", MonthlyVolume: 2000000},
+	}
+	mockLimitRepo.On("FetchEffective", mock.Anything, int64(6)).Return(limits, nil).Once()
+	mockLimitRepo.On("Usage", mock.Anything, int64(6), "", mock.Anything).Return(int64(600000), nil).Once()
+	mockLimitRepo.On("Usage", mock.Anything, int64(6), "CARD", mock.Anything).Return(int64(400000), nil).Once()
+	u := limitUsecase.NewLimitUsecase(mockLimitRepo, new(mocks.MerchantRepository), limitUsecase.Config{})
+
+	res, err := u.Status(context.TODO(), 6)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.LimitStatus{
+		{Limit: domain.LimitMaxAmount, Cap: 1000000, Remaining: 1000000},
+		{Limit: domain.LimitDailyVolume, Cap: 500000, Used: 600000, Remaining: 0},
+		{Limit: domain.LimitMonthlyVolume, PaymentType: "CARD", Cap: 2000000, Used: 400000, Remaining: 1600000},
+	}, res)
+}
@@ -2,19 +2,123 @@ package main
 
 import (
 	"database/sql"
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/labstack/echo"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/viper"
 
 	transactionDelivery "github.com/hezbymuhammad/payment-gateway/transaction/delivery/http"
 	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
+	transactionPostgres "github.com/hezbymuhammad/payment-gateway/transaction/repository/postgres"
+	transactionMemory "github.com/hezbymuhammad/payment-gateway/transaction/repository/memory"
 	transactionUsecase "github.com/hezbymuhammad/payment-gateway/transaction/usecase"
 
 	merchantDelivery "github.com/hezbymuhammad/payment-gateway/merchant/delivery/http"
 	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/sqlite"
+	merchantPostgres "github.com/hezbymuhammad/payment-gateway/merchant/repository/postgres"
+	merchantMemory "github.com/hezbymuhammad/payment-gateway/merchant/repository/memory"
 	merchantUsecase "github.com/hezbymuhammad/payment-gateway/merchant/usecase"
+
+	screeningDelivery "github.com/hezbymuhammad/payment-gateway/screening/delivery/http"
+	screeningRepo "github.com/hezbymuhammad/payment-gateway/screening/repository/sqlite"
+	screeningPostgres "github.com/hezbymuhammad/payment-gateway/screening/repository/postgres"
+	screeningUsecase "github.com/hezbymuhammad/payment-gateway/screening/usecase"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration"
+	"github.com/hezbymuhammad/payment-gateway/processor/router"
+	"github.com/hezbymuhammad/payment-gateway/processor/simulator"
+	"github.com/hezbymuhammad/payment-gateway/scheduler"
+
+	customerDelivery "github.com/hezbymuhammad/payment-gateway/customer/delivery/http"
+	customerRepo "github.com/hezbymuhammad/payment-gateway/customer/repository/sqlite"
+	customerPostgres "github.com/hezbymuhammad/payment-gateway/customer/repository/postgres"
+	customerUsecase "github.com/hezbymuhammad/payment-gateway/customer/usecase"
+
+	vaultDelivery "github.com/hezbymuhammad/payment-gateway/vault/delivery/http"
+	vaultRepo "github.com/hezbymuhammad/payment-gateway/vault/repository/sqlite"
+	vaultPostgres "github.com/hezbymuhammad/payment-gateway/vault/repository/postgres"
+	vaultUsecase "github.com/hezbymuhammad/payment-gateway/vault/usecase"
+
+	subscriptionDelivery "github.com/hezbymuhammad/payment-gateway/subscription/delivery/http"
+	subscriptionRepo "github.com/hezbymuhammad/payment-gateway/subscription/repository/sqlite"
+	subscriptionPostgres "github.com/hezbymuhammad/payment-gateway/subscription/repository/postgres"
+	subscriptionUsecase "github.com/hezbymuhammad/payment-gateway/subscription/usecase"
+
+	qrisDelivery "github.com/hezbymuhammad/payment-gateway/qris/delivery/http"
+	qrisRepo "github.com/hezbymuhammad/payment-gateway/qris/repository/sqlite"
+	qrisPostgres "github.com/hezbymuhammad/payment-gateway/qris/repository/postgres"
+	qrisUsecase "github.com/hezbymuhammad/payment-gateway/qris/usecase"
+
+	vaDelivery "github.com/hezbymuhammad/payment-gateway/virtualaccount/delivery/http"
+	vaRepo "github.com/hezbymuhammad/payment-gateway/virtualaccount/repository/sqlite"
+	vaPostgres "github.com/hezbymuhammad/payment-gateway/virtualaccount/repository/postgres"
+	vaUsecase "github.com/hezbymuhammad/payment-gateway/virtualaccount/usecase"
+
+	ewalletDelivery "github.com/hezbymuhammad/payment-gateway/ewallet/delivery/http"
+	ewalletProvider "github.com/hezbymuhammad/payment-gateway/ewallet/provider"
+	ewalletRepo "github.com/hezbymuhammad/payment-gateway/ewallet/repository/sqlite"
+	ewalletPostgres "github.com/hezbymuhammad/payment-gateway/ewallet/repository/postgres"
+	ewalletUsecase "github.com/hezbymuhammad/payment-gateway/ewallet/usecase"
+
+	installmentDelivery "github.com/hezbymuhammad/payment-gateway/installment/delivery/http"
+	installmentRepo "github.com/hezbymuhammad/payment-gateway/installment/repository/sqlite"
+	installmentPostgres "github.com/hezbymuhammad/payment-gateway/installment/repository/postgres"
+	installmentUsecase "github.com/hezbymuhammad/payment-gateway/installment/usecase"
+
+	"github.com/hezbymuhammad/payment-gateway/blob"
+	disputeDelivery "github.com/hezbymuhammad/payment-gateway/dispute/delivery/http"
+	disputeRepo "github.com/hezbymuhammad/payment-gateway/dispute/repository/sqlite"
+	disputePostgres "github.com/hezbymuhammad/payment-gateway/dispute/repository/postgres"
+	disputeUsecase "github.com/hezbymuhammad/payment-gateway/dispute/usecase"
+
+	riskDelivery "github.com/hezbymuhammad/payment-gateway/risk/delivery/http"
+	riskRepo "github.com/hezbymuhammad/payment-gateway/risk/repository/sqlite"
+	riskPostgres "github.com/hezbymuhammad/payment-gateway/risk/repository/postgres"
+	riskMemory "github.com/hezbymuhammad/payment-gateway/risk/repository/memory"
+	riskUsecase "github.com/hezbymuhammad/payment-gateway/risk/usecase"
+
+	limitDelivery "github.com/hezbymuhammad/payment-gateway/limit/delivery/http"
+	limitRepo "github.com/hezbymuhammad/payment-gateway/limit/repository/sqlite"
+	limitPostgres "github.com/hezbymuhammad/payment-gateway/limit/repository/postgres"
+	limitMemory "github.com/hezbymuhammad/payment-gateway/limit/repository/memory"
+	limitUsecase "github.com/hezbymuhammad/payment-gateway/limit/usecase"
+
+	"github.com/hezbymuhammad/payment-gateway/fx"
+	fxDelivery "github.com/hezbymuhammad/payment-gateway/fx/delivery/http"
+	fxRepo "github.com/hezbymuhammad/payment-gateway/fx/repository/sqlite"
+	fxPostgres "github.com/hezbymuhammad/payment-gateway/fx/repository/postgres"
+	fxUsecase "github.com/hezbymuhammad/payment-gateway/fx/usecase"
+
+	promotionDelivery "github.com/hezbymuhammad/payment-gateway/promotion/delivery/http"
+	promotionRepo "github.com/hezbymuhammad/payment-gateway/promotion/repository/sqlite"
+	promotionPostgres "github.com/hezbymuhammad/payment-gateway/promotion/repository/postgres"
+	promotionUsecase "github.com/hezbymuhammad/payment-gateway/promotion/usecase"
+
+	invoiceDelivery "github.com/hezbymuhammad/payment-gateway/invoice/delivery/http"
+	"github.com/hezbymuhammad/payment-gateway/invoice/notifier"
+	invoiceRepo "github.com/hezbymuhammad/payment-gateway/invoice/repository/sqlite"
+	invoicePostgres "github.com/hezbymuhammad/payment-gateway/invoice/repository/postgres"
+	invoiceUsecase "github.com/hezbymuhammad/payment-gateway/invoice/usecase"
+
+	checkoutDelivery "github.com/hezbymuhammad/payment-gateway/checkout/delivery/http"
+	checkoutRepo "github.com/hezbymuhammad/payment-gateway/checkout/repository/sqlite"
+	checkoutPostgres "github.com/hezbymuhammad/payment-gateway/checkout/repository/postgres"
+	checkoutUsecase "github.com/hezbymuhammad/payment-gateway/checkout/usecase"
+
+	auditDelivery "github.com/hezbymuhammad/payment-gateway/audit/delivery/http"
+	auditRepo "github.com/hezbymuhammad/payment-gateway/audit/repository/sqlite"
+	auditPostgres "github.com/hezbymuhammad/payment-gateway/audit/repository/postgres"
+	auditUsecase "github.com/hezbymuhammad/payment-gateway/audit/usecase"
 )
 
 func init() {
@@ -26,11 +130,35 @@ func init() {
 }
 
 func main() {
+	storage := flag.String("storage", "database", "where to keep data: database, or ephemeral for a demo that keeps everything in memory and writes nothing to disk")
+	flag.Parse()
+	args := flag.Args()
+
 	dbDriver := viper.GetString("database.driver")
 	dbFile := viper.GetString("database.file")
+	ephemeral := false
+	switch *storage {
+	case "database":
+	case "ephemeral":
+		// Only merchants, transactions, risk rules and limits have
+		// in-memory repositories. Every other feature, the audit log
+		// included, uses a SQLite database that lives in memory for as
+		// long as the process.
+		ephemeral = true
+		dbDriver = "sqlite3"
+		dbFile = "file:payment?mode=memory&cache=shared"
+	default:
+		log.Fatal("--storage must be database or ephemeral")
+	}
+	// database.file names the SQLite database and database.dsn the
+	// PostgreSQL one.
+	dsn := dbFile
+	if dbDriver == "postgres" {
+		dsn = viper.GetString("database.dsn")
+	}
 	log.Println("database driver: " + dbDriver)
-	log.Println("database file: " + dbFile)
-	dbConn, err := sql.Open(dbDriver, dbFile)
+	log.Println("database: " + dsn)
+	dbConn, err := sql.Open(dbDriver, dsn)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -38,15 +166,274 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if ephemeral {
+		// The shared in-memory database is dropped with its last
+		// connection, so hold one open.
+		_, err = dbConn.Conn(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		if ephemeral {
+			log.Fatal("migrate needs --storage=database")
+		}
+		m, err := migration.New(dbConn, dbDriver)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = migration.Run(context.Background(), m, args[1:], os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if viper.GetBool("database.autoMigrate") || ephemeral {
+		m, err := migration.New(dbConn, dbDriver)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, err = m.Up(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	vaultKey, err := vaultUsecase.LoadKey(os.Getenv("VAULT_KEY"), viper.GetString("vault.keyFile"), viper.GetString("vault.key"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var mr domain.MerchantRepository
+	var tr domain.TransactionRepository
+	var rr domain.RiskRepository
+	var lr domain.LimitRepository
+	var ar domain.AuditRepository
+	var scr domain.ScreeningRepository
+	var cr domain.CardVaultRepository
+	var cur domain.CustomerRepository
+	var vr domain.VirtualAccountRepository
+	var er domain.EWalletRepository
+	var fr domain.FXRepository
+	var pr domain.PromotionRepository
+	var inr domain.InstallmentRepository
+	var sr domain.SubscriptionRepository
+	var ir domain.InvoiceRepository
+	var chr domain.CheckoutRepository
+	var qr domain.QRPaymentRepository
+	var dr domain.DisputeRepository
+	switch dbDriver {
+	case "postgres":
+		mr = merchantPostgres.NewMerchantRepository(dbConn)
+		tr = transactionPostgres.NewTransactionRepository(dbConn)
+		rr = riskPostgres.NewRiskRepository(dbConn)
+		lr = limitPostgres.NewLimitRepository(dbConn)
+		ar = auditPostgres.NewAuditRepository(dbConn)
+		scr = screeningPostgres.NewScreeningRepository(dbConn)
+		cr = vaultPostgres.NewCardRepository(dbConn)
+		cur = customerPostgres.NewCustomerRepository(dbConn)
+		vr = vaPostgres.NewVirtualAccountRepository(dbConn)
+		er = ewalletPostgres.NewEWalletRepository(dbConn)
+		fr = fxPostgres.NewFXRepository(dbConn)
+		pr = promotionPostgres.NewPromotionRepository(dbConn)
+		inr = installmentPostgres.NewInstallmentRepository(dbConn)
+		sr = subscriptionPostgres.NewSubscriptionRepository(dbConn)
+		ir = invoicePostgres.NewInvoiceRepository(dbConn)
+		chr = checkoutPostgres.NewCheckoutRepository(dbConn)
+		qr = qrisPostgres.NewQRPaymentRepository(dbConn)
+		dr = disputePostgres.NewDisputeRepository(dbConn)
+	default:
+		mr = merchantRepo.NewMerchantRepository(dbConn)
+		tr = transactionRepo.NewTransactionRepository(dbConn)
+		rr = riskRepo.NewRiskRepository(dbConn)
+		lr = limitRepo.NewLimitRepository(dbConn)
+		ar = auditRepo.NewAuditRepository(dbConn)
+		scr = screeningRepo.NewScreeningRepository(dbConn)
+		cr = vaultRepo.NewCardRepository(dbConn)
+		cur = customerRepo.NewCustomerRepository(dbConn)
+		vr = vaRepo.NewVirtualAccountRepository(dbConn)
+		er = ewalletRepo.NewEWalletRepository(dbConn)
+		fr = fxRepo.NewFXRepository(dbConn)
+		pr = promotionRepo.NewPromotionRepository(dbConn)
+		inr = installmentRepo.NewInstallmentRepository(dbConn)
+		sr = subscriptionRepo.NewSubscriptionRepository(dbConn)
+		ir = invoiceRepo.NewInvoiceRepository(dbConn)
+		chr = checkoutRepo.NewCheckoutRepository(dbConn)
+		qr = qrisRepo.NewQRPaymentRepository(dbConn)
+		dr = disputeRepo.NewDisputeRepository(dbConn)
+	}
+	if ephemeral {
+		// The features with in-memory repositories use them instead
+		// of the in-memory SQLite database.
+		merchants := merchantMemory.NewMerchantRepository()
+		transactions := transactionMemory.NewTransactionRepository()
+		mr = merchants
+		tr = transactions
+		rr = riskMemory.NewRiskRepository(transactions)
+		lr = limitMemory.NewLimitRepository(merchants)
+	}
+
+	if len(args) > 0 && args[0] == "fingerprint-cards" {
+		n, err := vaultUsecase.FingerprintCards(context.Background(), cr, vaultKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("fingerprinted %d cards\n", n)
+		return
+	}
 
 	e := echo.New()
 
-	mr := merchantRepo.NewMerchantRepository(dbConn)
-	mu := merchantUsecase.NewMerchantUsecase(mr)
-	tr := transactionRepo.NewTransactionRepository(dbConn)
-	tu := transactionUsecase.NewTransactionUsecase(mr, tr)
+	au := auditUsecase.NewAuditUsecase(ar)
+	// Screening changes merchants' status on its own when the lists
+	// are refreshed, so its merchant updates are audited as well.
+	scu := screeningUsecase.NewScreeningUsecase(scr, auditUsecase.NewMerchantRepositoryAuditor(mr, au), screeningUsecase.Config{
+		Lists:     viper.GetStringMapString("screening.lists"),
+		Threshold: viper.GetInt("screening.threshold"),
+	})
+	mu := auditUsecase.NewMerchantAuditor(merchantUsecase.NewMerchantUsecase(mr, blob.NewLocalStore(viper.GetString("kyc.documentsDir")), merchantUsecase.WithScreening(scu)), au)
+	cv := vaultUsecase.NewCardVaultUsecase(cr, vaultKey)
+	cd := vaultUsecase.NewCardDetokenizer(cr, vaultKey)
+	cu := customerUsecase.NewCustomerUsecase(cur, mr, cv)
+	var processors []domain.PaymentProcessor
+	for _, name := range viper.GetStringSlice("processors") {
+		processors = append(processors, simulator.NewSimulator(name, cd))
+	}
+	var rules []router.Rule
+	err = viper.UnmarshalKey("routing.rules", &rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pp := router.NewRouter(processors, rules, router.Config{
+		BreakerThreshold: viper.GetInt("routing.breakerThreshold"),
+		BreakerCooldown:  viper.GetDuration("routing.breakerCooldown"),
+	})
+	banks := map[string]string{}
+	for bank, prefix := range viper.GetStringMapString("virtualAccounts.banks") {
+		banks[strings.ToUpper(bank)] = prefix
+	}
+	vaCfg := vaUsecase.Config{
+		Banks: banks,
+		TTL:   viper.GetDuration("virtualAccounts.ttl"),
+	}
+	wallets := map[string]domain.EWalletProvider{}
+	walletTTLs := map[string]time.Duration{}
+	for name := range viper.GetStringMap("ewallets.providers") {
+		key := "ewallets.providers." + name
+		provider := strings.ToUpper(name)
+		// Callbacks are only as trustworthy as the secret they are signed
+		// with, so it is kept out of config.json and may not be empty.
+		secretEnv := "EWALLET_" + provider + "_SECRET"
+		secret := os.Getenv(secretEnv)
+		if secret == "" {
+			log.Fatalf("%s must be set", secretEnv)
+		}
+		walletTTLs[provider] = viper.GetDuration(key + ".ttl")
+		if walletTTLs[provider] <= 0 {
+			log.Fatalf("%s.ttl must be a positive duration", key)
+		}
+		wallets[provider] = ewalletProvider.NewHTTPProvider(provider, ewalletProvider.Config{
+			BaseURL: viper.GetString(key + ".baseUrl"),
+			Secret:  secret,
+			Timeout: viper.GetDuration("ewallets.timeout"),
+		})
+	}
+	fu := fxUsecase.NewFXUsecase(fr, mr, fxUsecase.Config{
+		Markup:   viper.GetInt64("fx.markup"),
+		QuoteTTL: viper.GetDuration("fx.quoteTtl"),
+	})
+	if ratesFile := viper.GetString("fx.ratesFile"); ratesFile != "" {
+		rates, err := fx.LoadRates(ratesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = fu.StoreRates(context.Background(), rates)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	pu := promotionUsecase.NewPromotionUsecase(pr, mr)
+	inu := installmentUsecase.NewInstallmentUsecase(inr, mr)
+	ru := riskUsecase.NewRiskUsecase(rr, mr)
+	limitLocation, err := time.LoadLocation(viper.GetString("limits.timezone"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	lu := limitUsecase.NewLimitUsecase(lr, mr, limitUsecase.Config{
+		Location: limitLocation,
+	})
+	tu := transactionUsecase.NewTransactionUsecase(mr, tr, cu, cv, pp,
+		transactionUsecase.WithChannel(qrisUsecase.NewQRChannel(mr)),
+		transactionUsecase.WithChannel(vaUsecase.NewVAChannel(vr, vaCfg)),
+		transactionUsecase.WithChannel(ewalletUsecase.NewEWalletChannel(er, wallets)),
+		transactionUsecase.WithInstallments(inu),
+		transactionUsecase.WithPromotions(pu),
+		transactionUsecase.WithFX(fu),
+		transactionUsecase.WithRisk(ru),
+		transactionUsecase.WithLimits(lu),
+	)
+	tu = auditUsecase.NewTransactionAuditor(tu, au)
+	var retries []time.Duration
+	for _, days := range viper.GetIntSlice("subscriptions.retryDays") {
+		retries = append(retries, time.Duration(days)*24*time.Hour)
+	}
+	su := subscriptionUsecase.NewSubscriptionUsecase(sr, cu, tu, retries)
+	iu := invoiceUsecase.NewInvoiceUsecase(ir, mr, cu, tu, notifier.NewLogNotifier(), invoiceUsecase.Config{
+		BaseURL:      viper.GetString("invoices.baseUrl"),
+		ReminderDays: viper.GetIntSlice("invoices.reminderDays"),
+	})
+	e.Use(auditDelivery.Recorder)
+	auditDelivery.NewAuditHandler(e, au)
 	merchantDelivery.NewMerchantHandler(e, mu)
+	screeningDelivery.NewScreeningHandler(e, scu)
 	transactionDelivery.NewTransactionHandler(e, tu)
+	vaultDelivery.NewVaultHandler(e, cv)
+	customerDelivery.NewCustomerHandler(e, cu)
+	chu := checkoutUsecase.NewCheckoutUsecase(chr, mr, cv, tu, checkoutUsecase.Config{
+		BaseURL: viper.GetString("checkout.baseUrl"),
+		TTL:     viper.GetDuration("checkout.ttl"),
+	})
+	subscriptionDelivery.NewSubscriptionHandler(e, su)
+	invoiceDelivery.NewInvoiceHandler(e, iu)
+	checkoutDelivery.NewCheckoutHandler(e, chu)
+	qrisDelivery.NewQRISHandler(e, qrisUsecase.NewQRISUsecase(mr, qr, tu))
+	vau := vaUsecase.NewVirtualAccountUsecase(vr, mr, cu, tu, vaCfg)
+	vaDelivery.NewVirtualAccountHandler(e, vau)
+	eu := ewalletUsecase.NewEWalletUsecase(er, tu, wallets, ewalletUsecase.Config{
+		PollAfter: viper.GetDuration("ewallets.pollAfter"),
+		TTL:       walletTTLs,
+	})
+	ewalletDelivery.NewEWalletHandler(e, eu)
+	installmentDelivery.NewInstallmentHandler(e, inu)
+	promotionDelivery.NewPromotionHandler(e, pu)
+	fxDelivery.NewFXHandler(e, fu)
+	du := disputeUsecase.NewDisputeUsecase(dr, tu, blob.NewLocalStore(viper.GetString("disputes.evidenceDir")), disputeUsecase.Config{
+		ResponseWindow: viper.GetDuration("disputes.responseWindow"),
+	})
+	disputeDelivery.NewDisputeHandler(e, du)
+	riskDelivery.NewRiskHandler(e, ru)
+	limitDelivery.NewLimitHandler(e, lu)
+
+	schedules := []struct {
+		job scheduler.Job
+		key string
+	}{
+		{su.RunBilling, "subscriptions.interval"},
+		{iu.RunSchedule, "invoices.interval"},
+		{eu.RunPolling, "ewallets.pollInterval"},
+		{du.RunDeadlines, "disputes.interval"},
+		{scu.RunRefresh, "screening.interval"},
+		{chu.RunSchedule, "checkout.interval"},
+		{vau.RunExpiry, "virtualAccounts.interval"},
+	}
+	for _, sc := range schedules {
+		interval := viper.GetDuration(sc.key)
+		if interval <= 0 {
+			log.Fatalf("%s must be a positive duration", sc.key)
+		}
+		go scheduler.NewScheduler(sc.job, interval).Start(context.Background())
+	}
 
 	log.Fatal(e.Start(viper.GetString("server.address")))
 }
@@ -1,17 +1,40 @@
 package http
 
 import (
+	"context"
+	"io"
+	"mime"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/etag"
 )
 
+// maxDocumentSize is the largest KYC document accepted, in bytes.
+const maxDocumentSize = 10 << 20
+
+// documentContentTypes are the kinds of file accepted as KYC documents.
+var documentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
 type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// ReviewRequest carries a reviewer's notes on approving or rejecting a
+// merchant.
+type ReviewRequest struct {
+	Notes string `json:"notes"`
+}
+
 type MerchantHandler struct {
         Usecase domain.MerchantUsecase
 }
@@ -22,16 +45,28 @@ func NewMerchantHandler(e *echo.Echo, u domain.MerchantUsecase) *MerchantHandler
         }
 
         e.POST("/merchants", handler.Store)
+        e.GET("/merchants/:id", handler.GetByID)
+        e.PUT("/merchants/:id", handler.Update)
+        e.POST("/merchants/:id/documents", handler.AddDocument)
+        e.GET("/merchants/:id/documents", handler.FetchDocuments)
+        e.GET("/merchants/:id/documents/:documentId", handler.GetDocument)
+        e.POST("/merchants/:id/submit", handler.Submit)
+        e.POST("/merchants/:id/approve", handler.Approve)
+        e.POST("/merchants/:id/reject", handler.Reject)
         e.POST("/merchants/set_child", handler.SetChild)
+        e.POST("/merchants/settings", handler.StoreSetting)
+        e.GET("/merchants/settings/:id", handler.GetSetting)
+        e.PUT("/merchants/settings/:id", handler.UpdateSetting)
 
         return handler
 }
 
+// Store creates a draft merchant.
 func (h *MerchantHandler) Store(c echo.Context) error {
 	ctx := c.Request().Context()
         var data domain.Merchant
         c.Bind(&data)
-	if data.Name == "" {
+	if data.Name == "" || !validDetails(data) {
 		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
 	}
         err := h.Usecase.Store(ctx, &data)
@@ -39,7 +74,188 @@ func (h *MerchantHandler) Store(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
 	}
 
-        return c.NoContent(http.StatusCreated)
+        c.Response().Header().Set("ETag", etag.Format(data.Version))
+        return c.JSON(http.StatusCreated, data)
+}
+
+func (h *MerchantHandler) GetByID(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.GetByID(ctx, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+// Update replaces a draft merchant's KYC details. Like transaction updates
+// it must name the version it replaces, in If-Match or the body.
+func (h *MerchantHandler) Update(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        var data domain.Merchant
+        c.Bind(&data)
+        data.ID = id
+	if data.Name == "" || !validDetails(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        ifMatch, status := etag.Expect(c.Request(), &data.Version)
+	if status != 0 {
+		return respondPrecondition(c, status, "merchant")
+	}
+
+        err = h.Usecase.Update(ctx, &data)
+	if err == domain.ErrStaleVersion {
+		return c.JSON(etag.StaleStatus(ifMatch), ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(data.Version))
+        return c.JSON(http.StatusOK, data)
+}
+
+// AddDocument takes one file in the multipart field "file" and its kind in
+// "type". The content type is sniffed rather than trusted from the upload.
+func (h *MerchantHandler) AddDocument(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+        documentType := c.FormValue("type")
+        if !domain.MerchantDocumentTypes[documentType] {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        fh, err := c.FormFile("file")
+        if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        if fh.Size > maxDocumentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, ResponseError{Message: "Document is too large"})
+	}
+
+        f, err := fh.Open()
+        if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        defer f.Close()
+
+        head := make([]byte, 512)
+        n, err := io.ReadFull(f, head)
+        if err != nil && err != io.ErrUnexpectedEOF {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+        if !documentContentTypes[contentType] {
+		return c.JSON(http.StatusUnsupportedMediaType, ResponseError{Message: "Unsupported document type"})
+	}
+        _, err = f.Seek(0, io.SeekStart)
+        if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        data := domain.MerchantDocument{
+                MerchantID: id,
+                Type: documentType,
+                FileName: filepath.Base(fh.Filename),
+                ContentType: contentType,
+        }
+	ctx := c.Request().Context()
+        err = h.Usecase.AddDocument(ctx, &data, f)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        return c.JSON(http.StatusCreated, data)
+}
+
+func (h *MerchantHandler) FetchDocuments(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.FetchDocuments(ctx, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        return c.JSON(http.StatusOK, res)
+}
+
+func (h *MerchantHandler) GetDocument(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+        documentID, err := strconv.ParseInt(c.Param("documentId"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        d, r, err := h.Usecase.GetDocument(ctx, id, documentID)
+	if err != nil {
+		return respondError(c, err)
+	}
+        defer r.Close()
+
+        c.Response().Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.FileName}))
+        return c.Stream(http.StatusOK, d.ContentType, r)
+}
+
+func (h *MerchantHandler) Submit(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.Submit(ctx, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+func (h *MerchantHandler) Approve(c echo.Context) error {
+        return h.review(c, h.Usecase.Approve)
+}
+
+func (h *MerchantHandler) Reject(c echo.Context) error {
+        return h.review(c, h.Usecase.Reject)
+}
+
+func (h *MerchantHandler) review(c echo.Context, call func(ctx context.Context, id int64, notes string) (domain.Merchant, error)) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        var data ReviewRequest
+        c.Bind(&data)
+        res, err := call(ctx, id, data.Notes)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
 }
 
 func (h *MerchantHandler) SetChild(c echo.Context) error {
@@ -58,3 +274,125 @@ func (h *MerchantHandler) SetChild(c echo.Context) error {
 
         return c.NoContent(http.StatusCreated)
 }
+
+func (h *MerchantHandler) StoreSetting(c echo.Context) error {
+	ctx := c.Request().Context()
+
+        var data domain.Setting
+        c.Bind(&data)
+	if data.MerchantID == 0 || data.PaymentType == "" || !validQR(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+        err := h.Usecase.StoreSetting(ctx, &data)
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(data.Version))
+        return c.JSON(http.StatusCreated, data)
+}
+
+func (h *MerchantHandler) GetSetting(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.GetSetting(ctx, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+// UpdateSetting replaces a payment setting. It must name the version it
+// replaces, in If-Match or the body.
+func (h *MerchantHandler) UpdateSetting(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        var data domain.Setting
+        c.Bind(&data)
+        data.ID = id
+	if data.PaymentType == "" || !validQR(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        ifMatch, status := etag.Expect(c.Request(), &data.Version)
+	if status != 0 {
+		return respondPrecondition(c, status, "setting")
+	}
+
+        err = h.Usecase.UpdateSetting(ctx, &data)
+	if err == domain.ErrStaleVersion {
+		return c.JSON(etag.StaleStatus(ifMatch), ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(data.Version))
+        return c.JSON(http.StatusOK, data)
+}
+
+// validQR checks that a QR setting has everything a QRIS payload needs.
+func validQR(s domain.Setting) bool {
+        if s.PaymentType != "QR" {
+                return true
+        }
+
+        return s.QR != nil && s.QR.NMID != "" && s.QR.Criteria != "" && s.QR.MCC != "" && s.QR.City != ""
+}
+
+// validDetails checks the KYC details that were given are well formed.
+// Missing ones are only caught when the merchant is submitted.
+func validDetails(m domain.Merchant) bool {
+        if m.EntityType != "" && !domain.MerchantEntityTypes[strings.ToLower(m.EntityType)] {
+                return false
+        }
+        if strings.Trim(m.TaxID, "0123456789.-") != "" {
+                return false
+        }
+
+        total := 0
+        for _, o := range m.Owners {
+                if strings.TrimSpace(o.Name) == "" || o.Share < 0 || o.Share > 100 {
+                        return false
+                }
+                total += o.Share
+        }
+
+        return total <= 100
+}
+
+func respondError(c echo.Context, err error) error {
+        switch err {
+        case domain.ErrNotFound:
+                return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+        case domain.ErrMerchantState, domain.ErrStaleVersion:
+                return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+        case domain.ErrIncompleteKYC:
+                return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+        default:
+                return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+        }
+}
+
+// respondPrecondition answers an update whose version could not be worked
+// out, with the status etag.Expect gave.
+func respondPrecondition(c echo.Context, status int, what string) error {
+        if status == http.StatusPreconditionRequired {
+                return c.JSON(status, ResponseError{Message: "If-Match or version is required, fetch the " + what + " first"})
+        }
+
+        return c.JSON(status, ResponseError{Message: domain.ErrStaleVersion.Error()})
+}
@@ -1,8 +1,11 @@
 package http_test
 
 import (
+        "bytes"
 	"encoding/json"
         "errors"
+        "io"
+        "mime/multipart"
         "testing"
 	"net/http"
 	"net/http/httptest"
@@ -128,3 +131,420 @@ func TestFailedSetChild(t *testing.T) {
         assert.NoError(t, err)
         assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
+
+func TestStoreQRSetting(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("StoreSetting", mock.Anything, mock.Anything).Return(nil).Once()
+
+        data := &domain.Setting{
+                MerchantID: 6,
+                PaymentType: "QR",
+                PaymentName: "QRIS",
+                QR: &domain.QRAccount{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", City: "JAKARTA"},
+        }
+        j, err := json.Marshal(data)
+        assert.NoError(t, err)
+
+	e := echo.New()
+
+	req, err := http.NewRequest(echo.POST, "/merchants/settings", strings.NewReader(string(j)))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.StoreSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestStoreQRSettingWithoutAccount(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+
+        data := &domain.Setting{
+                MerchantID: 6,
+                PaymentType: "QR",
+                PaymentName: "QRIS",
+        }
+        j, err := json.Marshal(data)
+        assert.NoError(t, err)
+
+	e := echo.New()
+
+	req, err := http.NewRequest(echo.POST, "/merchants/settings", strings.NewReader(string(j)))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.StoreSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusBadRequest, rec.Code)
+        mockUsecase.AssertNotCalled(t, "StoreSetting", mock.Anything, mock.Anything)
+}
+
+func TestStoreInvalidOwners(t *testing.T) {
+        bodies := []string{
+                `{"name":"lorem","owners":[{"name":""}]}`,
+                `{"name":"lorem","owners":[{"name":"Budi Santoso","share":60},{"name":"Siti Rahma","share":50}]}`,
+                `{"name":"lorem","entityType":"plc"}`,
+                `{"name":"lorem","taxId":"01.234.567.8-901.00X"}`,
+        }
+
+        for _, body := range bodies {
+                mockUsecase := new(mocks.MerchantUsecase)
+
+                e := echo.New()
+                req, err := http.NewRequest(echo.POST, "/merchants", strings.NewReader(body))
+                assert.NoError(t, err)
+
+                req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+                rec := httptest.NewRecorder()
+                ctx := e.NewContext(req, rec)
+                ctx.SetPath("/merchants")
+
+                handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+                err = handler.Store(ctx)
+
+                assert.NoError(t, err)
+                assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+                mockUsecase.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+        }
+}
+
+func TestUpdateSubmitted(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.ID == 11 && m.LegalName == "PT Kopi Joss"
+        })).Return(domain.ErrMerchantState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/merchants/11", strings.NewReader(`{"name":"KOPI_JOSS","legalName":"PT Kopi Joss","version":2}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.Update(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestUpdateStale(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.ID == 11 && m.Version == 2
+        })).Return(domain.ErrStaleVersion).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/merchants/11", strings.NewReader(`{"name":"KOPI_JOSS","version":1}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+        req.Header.Set("If-Match", `"2"`)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.Update(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
+
+func TestGetSetting(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("GetSetting", mock.Anything, int64(6)).Return(domain.Setting{ID: 6, MerchantID: 11, PaymentType: "CARD", PaymentName: "VISA", Version: 3}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/merchants/settings/6", nil)
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("6")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.GetSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+}
+
+func TestUpdateSetting(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("UpdateSetting", mock.Anything, mock.MatchedBy(func(s *domain.Setting) bool {
+                return s.ID == 6 && s.PaymentName == "MASTERCARD" && s.Version == 3
+        })).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Setting).Version++
+        }).Return(nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/merchants/settings/6", strings.NewReader(`{"color":"BLUE","paymentType":"CARD","paymentName":"MASTERCARD"}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+        req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("6")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.UpdateSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+}
+
+func TestUpdateSettingWithoutVersion(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/merchants/settings/6", strings.NewReader(`{"paymentType":"CARD","paymentName":"MASTERCARD"}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("6")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.UpdateSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+        mockUsecase.AssertNotCalled(t, "UpdateSetting", mock.Anything, mock.Anything)
+}
+
+func TestUpdateSettingNotFound(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("UpdateSetting", mock.Anything, mock.Anything).Return(domain.ErrNotFound).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.PUT, "/merchants/settings/404", strings.NewReader(`{"paymentType":"CARD","paymentName":"VISA","version":1}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/settings/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("404")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.UpdateSetting(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAddDocument(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("AddDocument", mock.Anything, mock.MatchedBy(func(d *domain.MerchantDocument) bool {
+                return d.MerchantID == 11 && d.Type == "tax_card" && d.FileName == "npwp.pdf" && d.ContentType == "application/pdf"
+        }), mock.Anything).Run(func(args mock.Arguments) {
+                b, _ := io.ReadAll(args.Get(2).(io.Reader))
+                args.Get(1).(*domain.MerchantDocument).Size = int64(len(b))
+        }).Return(nil).Once()
+
+        var body bytes.Buffer
+        w := multipart.NewWriter(&body)
+        w.WriteField("type", "tax_card")
+        part, err := w.CreateFormFile("file", "../npwp.pdf")
+        assert.NoError(t, err)
+        part.Write([]byte("%PDF-1.4\n%EOF\n"))
+        w.Close()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/documents", &body)
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/documents")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.AddDocument(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusCreated, rec.Code)
+        assert.Contains(t, rec.Body.String(), `"size":14`)
+}
+
+func TestAddDocumentUnknownType(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+
+        var body bytes.Buffer
+        w := multipart.NewWriter(&body)
+        w.WriteField("type", "selfie")
+        part, err := w.CreateFormFile("file", "me.png")
+        assert.NoError(t, err)
+        part.Write([]byte("\x89PNG\r\n\x1a\n"))
+        w.Close()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/documents", &body)
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/documents")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.AddDocument(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusBadRequest, rec.Code)
+        mockUsecase.AssertNotCalled(t, "AddDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAddDocumentUnsupportedContent(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+
+        var body bytes.Buffer
+        w := multipart.NewWriter(&body)
+        w.WriteField("type", "id_card")
+        part, err := w.CreateFormFile("file", "ktp.jpg")
+        assert.NoError(t, err)
+        part.Write([]byte("just some text"))
+        w.Close()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/documents", &body)
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/documents")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.AddDocument(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+        mockUsecase.AssertNotCalled(t, "AddDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetDocument(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        d := domain.MerchantDocument{ID: 3, MerchantID: 11, Type: "tax_card", FileName: "npwp.pdf", ContentType: "application/pdf"}
+        mockUsecase.On("GetDocument", mock.Anything, int64(11), int64(3)).Return(d, io.NopCloser(strings.NewReader("%PDF-1.4")), nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/merchants/11/documents/3", nil)
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/documents/:documentId")
+	ctx.SetParamNames("id", "documentId")
+	ctx.SetParamValues("11", "3")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.GetDocument(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+        assert.Equal(t, "attachment; filename=npwp.pdf", rec.Header().Get("Content-Disposition"))
+        assert.Equal(t, "%PDF-1.4", rec.Body.String())
+}
+
+func TestSubmitIncomplete(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("Submit", mock.Anything, int64(11)).Return(domain.Merchant{}, domain.ErrIncompleteKYC).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/submit", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/submit")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.Submit(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestApprove(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("Approve", mock.Anything, int64(11), "false positive").Return(domain.Merchant{ID: 11, Status: domain.MerchantApproved, ReviewNotes: "false positive"}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/approve", strings.NewReader(`{"notes":"false positive"}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/approve")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.Approve(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Contains(t, rec.Body.String(), `"status":"approved"`)
+}
+
+func TestRejectNotHeld(t *testing.T) {
+        mockUsecase := new(mocks.MerchantUsecase)
+        mockUsecase.On("Reject", mock.Anything, int64(11), "").Return(domain.Merchant{}, domain.ErrMerchantState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/merchants/11/reject", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/merchants/:id/reject")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("11")
+
+        handler := merchantHttp.NewMerchantHandler(echo.New(), mockUsecase)
+        err = handler.Reject(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.MerchantRepository(t, func(t *testing.T) domain.MerchantRepository {
+		return merchantRepo.NewMerchantRepository()
+	})
+}
@@ -0,0 +1,309 @@
+// Package memory keeps merchants in process memory, for tests and demos
+// that should not need a database. It behaves like the SQL repositories.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// MerchantRepository is a domain.MerchantRepository safe for concurrent
+// use.
+type MerchantRepository struct {
+	mu        sync.RWMutex
+	merchants map[int64]domain.Merchant
+	documents map[int64]domain.MerchantDocument
+	settings  map[int64]domain.Setting
+	groups    []domain.MerchantGroup
+	lastID    struct{ merchant, document, setting int64 }
+}
+
+var _ domain.MerchantRepository = (*MerchantRepository)(nil)
+
+func NewMerchantRepository() *MerchantRepository {
+	return &MerchantRepository{
+		merchants: map[int64]domain.Merchant{},
+		documents: map[int64]domain.MerchantDocument{},
+		settings:  map[int64]domain.Setting{},
+	}
+}
+
+func (mr *MerchantRepository) Store(ctx context.Context, m *domain.Merchant) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.lastID.merchant++
+	m.ID = mr.lastID.merchant
+	m.Version = 1
+	mr.merchants[m.ID] = copyMerchant(*m)
+
+	return nil
+}
+
+func (mr *MerchantRepository) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	m, ok := mr.merchants[id]
+	if !ok {
+		return domain.Merchant{}, domain.ErrNotFound
+	}
+
+	return copyMerchant(m), nil
+}
+
+func (mr *MerchantRepository) Fetch(ctx context.Context) ([]domain.Merchant, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	result := []domain.Merchant{}
+	for _, m := range mr.merchants {
+		result = append(result, copyMerchant(m))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+// Update replaces the merchant and its owners if it is still at the
+// version it was read at, and bumps its version. It fails with
+// domain.ErrStaleVersion if the merchant was changed since, or
+// domain.ErrNotFound if there is no such merchant.
+func (mr *MerchantRepository) Update(ctx context.Context, m *domain.Merchant) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	stored, ok := mr.merchants[m.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Version != m.Version {
+		return domain.ErrStaleVersion
+	}
+	m.Version++
+	mr.merchants[m.ID] = copyMerchant(*m)
+
+	return nil
+}
+
+func (mr *MerchantRepository) StoreDocument(ctx context.Context, d *domain.MerchantDocument) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.lastID.document++
+	d.ID = mr.lastID.document
+	stored := *d
+	stored.CreatedAt = stored.CreatedAt.UTC()
+	mr.documents[d.ID] = stored
+
+	return nil
+}
+
+func (mr *MerchantRepository) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	result := []domain.MerchantDocument{}
+	for _, d := range mr.documents {
+		if d.MerchantID == merchantID {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+func (mr *MerchantRepository) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	s, ok := mr.settings[id]
+	if !ok {
+		return domain.Setting{}, domain.ErrNotFound
+	}
+
+	return copySetting(s), nil
+}
+
+func (mr *MerchantRepository) FetchSettings(ctx context.Context, merchantID int64) ([]domain.Setting, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	result := []domain.Setting{}
+	for _, s := range mr.settings {
+		if s.MerchantID == merchantID {
+			result = append(result, copySetting(s))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+func (mr *MerchantRepository) GetSettingByNMID(ctx context.Context, nmid string) (domain.Setting, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	s, ok := mr.settingByNMID(nmid)
+	if !ok {
+		return domain.Setting{}, domain.ErrNotFound
+	}
+
+	return copySetting(s), nil
+}
+
+// StoreSetting adds a setting. Like the unique index in the SQL schemas, it
+// refuses an NMID another setting already has.
+func (mr *MerchantRepository) StoreSetting(ctx context.Context, s *domain.Setting) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	err := mr.checkNMID(*s)
+	if err != nil {
+		return err
+	}
+
+	mr.lastID.setting++
+	s.ID = mr.lastID.setting
+	s.Version = 1
+	mr.settings[s.ID] = copySetting(*s)
+
+	return nil
+}
+
+// UpdateSetting replaces the setting if it is still at the version it was
+// read at, and bumps its version. The merchant it belongs to does not
+// change. It fails with domain.ErrStaleVersion if the setting was changed
+// since, or domain.ErrNotFound if there is no such setting.
+func (mr *MerchantRepository) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	stored, ok := mr.settings[s.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Version != s.Version {
+		return domain.ErrStaleVersion
+	}
+	err := mr.checkNMID(*s)
+	if err != nil {
+		return err
+	}
+
+	s.MerchantID = stored.MerchantID
+	s.Version++
+	mr.settings[s.ID] = copySetting(*s)
+
+	return nil
+}
+
+func (mr *MerchantRepository) InitSetting(ctx context.Context, m *domain.Merchant) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.lastID.setting++
+	mr.settings[mr.lastID.setting] = domain.Setting{
+		ID:          mr.lastID.setting,
+		MerchantID:  m.ID,
+		Color:       "RED",
+		PaymentType: "CARD",
+		PaymentName: "VISA",
+		Version:     1,
+	}
+
+	return nil
+}
+
+func (mr *MerchantRepository) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.groups = append(mr.groups, *mg)
+
+	return nil
+}
+
+func (mr *MerchantRepository) IsAuthorizedParent(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for _, g := range mr.groups {
+		if g.ParentMerchantID == mg.ParentMerchantID && g.ChildMerchantID == mg.ChildMerchantID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (mr *MerchantRepository) CanShareCustomers(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for _, g := range mr.groups {
+		if g.ParentMerchantID == mg.ParentMerchantID && g.ChildMerchantID == mg.ChildMerchantID && g.ShareCustomers {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Groups lists every parent and child link, for the in-memory limit
+// repository to walk.
+func (mr *MerchantRepository) Groups() []domain.MerchantGroup {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	return append([]domain.MerchantGroup{}, mr.groups...)
+}
+
+// checkNMID refuses an NMID another setting already has, like the unique
+// index in the SQL schemas.
+func (mr *MerchantRepository) checkNMID(s domain.Setting) error {
+	if s.QR == nil || s.QR.NMID == "" {
+		return nil
+	}
+	other, ok := mr.settingByNMID(s.QR.NMID)
+	if ok && other.ID != s.ID {
+		return fmt.Errorf("memory: setting with NMID %s already exists", s.QR.NMID)
+	}
+
+	return nil
+}
+
+func (mr *MerchantRepository) settingByNMID(nmid string) (domain.Setting, bool) {
+	for _, s := range mr.settings {
+		if s.QR != nil && s.QR.NMID == nmid {
+			return s, true
+		}
+	}
+
+	return domain.Setting{}, false
+}
+
+func copyMerchant(m domain.Merchant) domain.Merchant {
+	m.Owners = append([]domain.Owner{}, m.Owners...)
+	return m
+}
+
+// copySetting detaches the QR account, dropping one without an NMID as the
+// SQL repositories do.
+func copySetting(s domain.Setting) domain.Setting {
+	if s.QR != nil {
+		if s.QR.NMID == "" {
+			s.QR = nil
+		} else {
+			qr := *s.QR
+			s.QR = &qr
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,47 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/memory"
+)
+
+func TestConcurrentStore(t *testing.T) {
+	mr := merchantRepo.NewMerchantRepository()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := domain.Merchant{Name: "KOPI_JOSS", Owners: []domain.Owner{}}
+			assert.NoError(t, mr.Store(context.TODO(), &m))
+			assert.NoError(t, mr.InitSetting(context.TODO(), &m))
+			_, err := mr.Fetch(context.TODO())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	res, err := mr.Fetch(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, res, 50)
+	assert.Equal(t, int64(50), res[49].ID)
+}
+
+func TestStoredCopy(t *testing.T) {
+	mr := merchantRepo.NewMerchantRepository()
+	m := domain.Merchant{Name: "KOPI_JOSS", Owners: []domain.Owner{{Name: "Budi Santoso", Share: 100}}}
+	assert.NoError(t, mr.Store(context.TODO(), &m))
+
+	m.Owners[0].Name = "Siti Rahma"
+	res, err := mr.GetByID(context.TODO(), m.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Budi Santoso", res.Owners[0].Name)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/postgres"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.MerchantRepository(t, func(t *testing.T) domain.MerchantRepository {
+		return merchantRepo.NewMerchantRepository(migrationtest.NewPostgresDB(t))
+	})
+}
@@ -0,0 +1,567 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const merchantColumns = "id, name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score, version"
+
+const documentColumns = "id, merchant_id, type, file_name, content_type, size, storage_key, created_at"
+
+const settingColumns = "id, merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code, version"
+
+type postgresMerchantRepo struct {
+	DB *sql.DB
+}
+
+func NewMerchantRepository(db *sql.DB) domain.MerchantRepository {
+	return &postgresMerchantRepo{
+		DB: db,
+	}
+}
+
+func (mr *postgresMerchantRepo) IsAuthorizedParent(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM merchant_groups WHERE parent_merchant_id=$1 AND child_merchant_id=$2)"
+
+	var authorized bool
+	err := mr.DB.QueryRowContext(ctx, query, mg.ParentMerchantID, mg.ChildMerchantID).Scan(&authorized)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return authorized, nil
+}
+
+func (mr *postgresMerchantRepo) CanShareCustomers(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM merchant_groups WHERE parent_merchant_id=$1 AND child_merchant_id=$2 AND share_customers)"
+
+	var shared bool
+	err := mr.DB.QueryRowContext(ctx, query, mg.ParentMerchantID, mg.ChildMerchantID).Scan(&shared)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return shared, nil
+}
+
+// Store inserts the merchant together with its owners.
+func (mr *postgresMerchantRepo) Store(ctx context.Context, m *domain.Merchant) error {
+	tx, err := mr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO merchants (name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, version"
+
+	var id int64
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		m.Name,
+		m.LegalName,
+		m.EntityType,
+		m.RegistrationNumber,
+		m.TaxID,
+		m.Address,
+		m.BankAccount.BankCode,
+		m.BankAccount.AccountNumber,
+		m.BankAccount.AccountName,
+		m.Status,
+		m.ReviewNotes,
+		m.ScreeningResult,
+		m.ScreeningScore,
+	).Scan(&id, &m.Version)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	err = storeOwners(ctx, tx, id, m.Owners)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	m.ID = id
+	return nil
+}
+
+func (mr *postgresMerchantRepo) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+	query := "SELECT " + merchantColumns + " FROM merchants WHERE id=$1"
+
+	data, err := scanMerchant(mr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Merchant{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Merchant{}, err
+	}
+
+	data.Owners, err = fetchOwners(ctx, mr.DB, data.ID)
+	if err != nil {
+		return domain.Merchant{}, err
+	}
+
+	return data, nil
+}
+
+func (mr *postgresMerchantRepo) Fetch(ctx context.Context) ([]domain.Merchant, error) {
+	query := "SELECT " + merchantColumns + " FROM merchants ORDER BY id"
+
+	rows, err := mr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Merchant{}
+	for rows.Next() {
+		data, err := scanMerchant(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		result[i].Owners, err = fetchOwners(ctx, mr.DB, result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Update writes the merchant back over the version it was read at,
+// replacing its owners and bumping its version. The merchant's row is
+// locked while its version is checked, so concurrent updates replace the
+// owners one after the other and all but the first fail with
+// domain.ErrStaleVersion. It fails with domain.ErrNotFound if there is no
+// such merchant.
+func (mr *postgresMerchantRepo) Update(ctx context.Context, m *domain.Merchant) error {
+	tx, err := mr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "SELECT version FROM merchants WHERE id=$1 FOR UPDATE"
+	var version int64
+	err = tx.QueryRowContext(ctx, query, m.ID).Scan(&version)
+	if err == sql.ErrNoRows {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if version != m.Version {
+		return domain.ErrStaleVersion
+	}
+
+	query = "UPDATE merchants SET name=$1, legal_name=$2, entity_type=$3, registration_number=$4, tax_id=$5, address=$6, bank_code=$7, bank_account_number=$8, bank_account_name=$9, status=$10, review_notes=$11, screening_result=$12, screening_score=$13, version=version+1 WHERE id=$14"
+
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		m.Name,
+		m.LegalName,
+		m.EntityType,
+		m.RegistrationNumber,
+		m.TaxID,
+		m.Address,
+		m.BankAccount.BankCode,
+		m.BankAccount.AccountNumber,
+		m.BankAccount.AccountName,
+		m.Status,
+		m.ReviewNotes,
+		m.ScreeningResult,
+		m.ScreeningScore,
+		m.ID,
+	)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "DELETE FROM merchant_owners WHERE merchant_id=$1"
+	_, err = tx.ExecContext(ctx, query, m.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	err = storeOwners(ctx, tx, m.ID, m.Owners)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	m.Version++
+	return nil
+}
+
+func (mr *postgresMerchantRepo) StoreDocument(ctx context.Context, d *domain.MerchantDocument) error {
+	query := "INSERT INTO merchant_documents (merchant_id, type, file_name, content_type, size, storage_key, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
+	err := mr.DB.QueryRowContext(ctx, query, d.MerchantID, d.Type, d.FileName, d.ContentType, d.Size, d.StorageKey, d.CreatedAt).Scan(&d.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (mr *postgresMerchantRepo) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+	query := "SELECT " + documentColumns + " FROM merchant_documents WHERE merchant_id=$1 ORDER BY id"
+
+	rows, err := mr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.MerchantDocument{}
+	for rows.Next() {
+		data := domain.MerchantDocument{}
+		err := rows.Scan(
+			&data.ID,
+			&data.MerchantID,
+			&data.Type,
+			&data.FileName,
+			&data.ContentType,
+			&data.Size,
+			&data.StorageKey,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		data.CreatedAt = data.CreatedAt.UTC()
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (mr *postgresMerchantRepo) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+	query := "SELECT " + settingColumns + " FROM settings WHERE id=$1"
+
+	return mr.getSetting(ctx, query, id)
+}
+
+func (mr *postgresMerchantRepo) GetSettingByNMID(ctx context.Context, nmid string) (domain.Setting, error) {
+	query := "SELECT " + settingColumns + " FROM settings WHERE qr_nmid=$1"
+
+	return mr.getSetting(ctx, query, nmid)
+}
+
+func (mr *postgresMerchantRepo) FetchSettings(ctx context.Context, merchantID int64) ([]domain.Setting, error) {
+	query := "SELECT " + settingColumns + " FROM settings WHERE merchant_id=$1 ORDER BY id"
+
+	rows, err := mr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Setting{}
+	for rows.Next() {
+		data, err := scanSetting(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (mr *postgresMerchantRepo) StoreSetting(ctx context.Context, s *domain.Setting) error {
+	query := "INSERT INTO settings (merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, version"
+
+	qr := domain.QRAccount{}
+	if s.QR != nil {
+		qr = *s.QR
+	}
+
+	err := mr.DB.QueryRowContext(
+		ctx,
+		query,
+		s.MerchantID,
+		s.Color,
+		s.PaymentType,
+		s.PaymentName,
+		nullString(qr.Acquirer),
+		nullString(qr.MerchantPAN),
+		nullString(qr.NMID),
+		nullString(qr.Criteria),
+		nullString(qr.MCC),
+		nullString(qr.City),
+		nullString(qr.PostalCode),
+	).Scan(&s.ID, &s.Version)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateSetting writes the setting back over the version it was read at
+// and bumps its version. The merchant it belongs to does not change. It
+// fails with domain.ErrStaleVersion if the setting was changed since, or
+// domain.ErrNotFound if there is no such setting.
+func (mr *postgresMerchantRepo) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+	query := "UPDATE settings SET color=$1, payment_type=$2, payment_name=$3, qr_acquirer=$4, qr_merchant_pan=$5, qr_nmid=$6, qr_criteria=$7, qr_mcc=$8, qr_city=$9, qr_postal_code=$10, version=version+1 WHERE id=$11 AND version=$12"
+
+	qr := domain.QRAccount{}
+	if s.QR != nil {
+		qr = *s.QR
+	}
+
+	res, err := mr.DB.ExecContext(
+		ctx,
+		query,
+		s.Color,
+		s.PaymentType,
+		s.PaymentName,
+		nullString(qr.Acquirer),
+		nullString(qr.MerchantPAN),
+		nullString(qr.NMID),
+		nullString(qr.Criteria),
+		nullString(qr.MCC),
+		nullString(qr.City),
+		nullString(qr.PostalCode),
+		s.ID,
+		s.Version,
+	)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		_, err = mr.GetSetting(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+		return domain.ErrStaleVersion
+	}
+
+	s.Version++
+	return nil
+}
+
+func (mr *postgresMerchantRepo) getSetting(ctx context.Context, query string, arg interface{}) (domain.Setting, error) {
+	data, err := scanSetting(mr.DB.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return domain.Setting{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Setting{}, err
+	}
+
+	return data, nil
+}
+
+func (mr *postgresMerchantRepo) InitSetting(ctx context.Context, m *domain.Merchant) error {
+	query := "INSERT INTO settings (merchant_id, color, payment_type, payment_name) VALUES ($1, $2, $3, $4)"
+
+	_, err := mr.DB.ExecContext(ctx, query, m.ID, "RED", "CARD", "VISA")
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (mr *postgresMerchantRepo) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
+	query := "INSERT INTO merchant_groups (parent_merchant_id, child_merchant_id, share_customers) VALUES ($1, $2, $3)"
+
+	_, err := mr.DB.ExecContext(ctx, query, mg.ParentMerchantID, mg.ChildMerchantID, mg.ShareCustomers)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// storeOwners inserts a merchant's owners in the order given.
+func storeOwners(ctx context.Context, tx *sql.Tx, merchantID int64, owners []domain.Owner) error {
+	query := "INSERT INTO merchant_owners (merchant_id, position, name, id_number, share) VALUES ($1, $2, $3, $4, $5)"
+	for i, o := range owners {
+		_, err := tx.ExecContext(ctx, query, merchantID, i, o.Name, o.IDNumber, o.Share)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fetchOwners(ctx context.Context, db queryer, merchantID int64) ([]domain.Owner, error) {
+	query := "SELECT name, id_number, share FROM merchant_owners WHERE merchant_id=$1 ORDER BY position"
+
+	rows, err := db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Owner{}
+	for rows.Next() {
+		data := domain.Owner{}
+		err := rows.Scan(&data.Name, &data.IDNumber, &data.Share)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// scanMerchant reads a merchants row. The name is nullable, as in the
+// SQLite schema.
+func scanMerchant(row scanner) (domain.Merchant, error) {
+	data := domain.Merchant{}
+	var name sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&name,
+		&data.LegalName,
+		&data.EntityType,
+		&data.RegistrationNumber,
+		&data.TaxID,
+		&data.Address,
+		&data.BankAccount.BankCode,
+		&data.BankAccount.AccountNumber,
+		&data.BankAccount.AccountName,
+		&data.Status,
+		&data.ReviewNotes,
+		&data.ScreeningResult,
+		&data.ScreeningScore,
+		&data.Version,
+	)
+	if err != nil {
+		return domain.Merchant{}, err
+	}
+
+	data.Name = name.String
+	return data, nil
+}
+
+// scanSetting reads a settings row. Every column but the ids is nullable;
+// the QR account is only filled in for settings that have an NMID.
+func scanSetting(row scanner) (domain.Setting, error) {
+	data := domain.Setting{}
+	var color, paymentType, paymentName sql.NullString
+	var acquirer, pan, nmid, criteria, mcc, city, postalCode sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&color,
+		&paymentType,
+		&paymentName,
+		&acquirer,
+		&pan,
+		&nmid,
+		&criteria,
+		&mcc,
+		&city,
+		&postalCode,
+		&data.Version,
+	)
+	if err != nil {
+		return domain.Setting{}, err
+	}
+
+	data.Color = color.String
+	data.PaymentType = paymentType.String
+	data.PaymentName = paymentName.String
+	if nmid.Valid {
+		data.QR = &domain.QRAccount{
+			Acquirer:    acquirer.String,
+			MerchantPAN: pan.String,
+			NMID:        nmid.String,
+			Criteria:    criteria.String,
+			MCC:         mcc.String,
+			City:        city.String,
+			PostalCode:  postalCode.String,
+		}
+	}
+
+	return data, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/sqlite"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.MerchantRepository(t, func(t *testing.T) domain.MerchantRepository {
+		return merchantRepo.NewMerchantRepository(migrationtest.NewDB(t))
+	})
+}
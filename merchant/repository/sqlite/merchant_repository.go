@@ -8,6 +8,12 @@ import (
 	"github.com/hezbymuhammad/payment-gateway/domain"
 )
 
+const merchantColumns = "id, name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score, version"
+
+const documentColumns = "id, merchant_id, type, file_name, content_type, size, storage_key, created_at"
+
+const settingColumns = "id, merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code, version"
+
 type sqliteMerchantRepo struct {
 	DB *sql.DB
 }
@@ -39,8 +45,201 @@ func (mr *sqliteMerchantRepo) IsAuthorizedParent(ctx context.Context, mg *domain
         return false, nil
 }
 
+func (mr *sqliteMerchantRepo) CanShareCustomers(ctx context.Context, mg *domain.MerchantGroup) (bool, error) {
+        query := "SELECT EXISTS (SELECT 1 FROM merchant_groups WHERE parent_merchant_id=? AND child_merchant_id=? AND share_customers=1 LIMIT 1) as shared"
+
+        var shared int
+        err := mr.DB.QueryRowContext(ctx, query, mg.ParentMerchantID, mg.ChildMerchantID).Scan(&shared)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return false, err
+        }
+
+        return shared != 0, nil
+}
+
+// Store inserts the merchant together with its owners.
 func (mr *sqliteMerchantRepo) Store(ctx context.Context, m *domain.Merchant) error {
-        query := "INSERT INTO merchants(name) VALUES(?)"
+        tx, err := mr.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return err
+        }
+        defer tx.Rollback()
+
+        query := "INSERT INTO merchants(name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+        res, err := tx.ExecContext(
+                ctx,
+                query,
+                m.Name,
+                m.LegalName,
+                m.EntityType,
+                m.RegistrationNumber,
+                m.TaxID,
+                m.Address,
+                m.BankAccount.BankCode,
+                m.BankAccount.AccountNumber,
+                m.BankAccount.AccountName,
+                m.Status,
+                m.ReviewNotes,
+                m.ScreeningResult,
+                m.ScreeningScore,
+        )
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        lastID, err := res.LastInsertId()
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        err = storeOwners(ctx, tx, lastID, m.Owners)
+        if err != nil {
+                return err
+        }
+
+        err = tx.Commit()
+        if err != nil {
+                return err
+        }
+
+        m.ID = lastID
+        m.Version = 1
+        return nil
+}
+
+func (mr *sqliteMerchantRepo) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+        query := "SELECT " + merchantColumns + " FROM merchants WHERE id=? LIMIT 1"
+
+        data, err := scanMerchant(mr.DB.QueryRowContext(ctx, query, id))
+        if err == sql.ErrNoRows {
+                return domain.Merchant{}, domain.ErrNotFound
+        }
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return domain.Merchant{}, err
+        }
+
+        data.Owners, err = mr.fetchOwners(ctx, data.ID)
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+
+        return data, nil
+}
+
+func (mr *sqliteMerchantRepo) Fetch(ctx context.Context) ([]domain.Merchant, error) {
+        query := "SELECT " + merchantColumns + " FROM merchants ORDER BY id"
+
+        rows, err := mr.DB.QueryContext(ctx, query)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.Merchant{}
+        for rows.Next() {
+                data, err := scanMerchant(rows)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, data)
+        }
+        err = rows.Err()
+        if err != nil {
+                return nil, err
+        }
+
+        for i := range result {
+                result[i].Owners, err = mr.fetchOwners(ctx, result[i].ID)
+                if err != nil {
+                        return nil, err
+                }
+        }
+
+        return result, nil
+}
+
+// Update writes the merchant back over the version it was read at,
+// replacing its owners and bumping its version. It fails with
+// domain.ErrStaleVersion if the merchant was changed since, or
+// domain.ErrNotFound if there is no such merchant.
+func (mr *sqliteMerchantRepo) Update(ctx context.Context, m *domain.Merchant) error {
+        tx, err := mr.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return err
+        }
+        defer tx.Rollback()
+
+        query := "UPDATE merchants SET name=?, legal_name=?, entity_type=?, registration_number=?, tax_id=?, address=?, bank_code=?, bank_account_number=?, bank_account_name=?, status=?, review_notes=?, screening_result=?, screening_score=?, version=version+1 WHERE id=? AND version=?"
+
+        res, err := tx.ExecContext(
+                ctx,
+                query,
+                m.Name,
+                m.LegalName,
+                m.EntityType,
+                m.RegistrationNumber,
+                m.TaxID,
+                m.Address,
+                m.BankAccount.BankCode,
+                m.BankAccount.AccountNumber,
+                m.BankAccount.AccountName,
+                m.Status,
+                m.ReviewNotes,
+                m.ScreeningResult,
+                m.ScreeningScore,
+                m.ID,
+                m.Version,
+        )
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+        n, err := res.RowsAffected()
+        if err != nil {
+                return err
+        }
+        if n == 0 {
+                return missingOrStale(ctx, tx, "merchants", m.ID)
+        }
+
+        query = "DELETE FROM merchant_owners WHERE merchant_id=?"
+        _, err = tx.ExecContext(ctx, query, m.ID)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        err = storeOwners(ctx, tx, m.ID, m.Owners)
+        if err != nil {
+                return err
+        }
+
+        err = tx.Commit()
+        if err != nil {
+                return err
+        }
+
+        m.Version++
+        return nil
+}
+
+func (mr *sqliteMerchantRepo) StoreDocument(ctx context.Context, d *domain.MerchantDocument) error {
+        query := "INSERT INTO merchant_documents(merchant_id, type, file_name, content_type, size, storage_key, created_at) VALUES(?, ?, ?, ?, ?, ?, ?)"
 
         stmt, err := mr.DB.PrepareContext(ctx, query)
         if err != nil {
@@ -49,7 +248,7 @@ func (mr *sqliteMerchantRepo) Store(ctx context.Context, m *domain.Merchant) err
                 return err
         }
 
-        res, err := stmt.ExecContext(ctx, m.Name)
+        res, err := stmt.ExecContext(ctx, d.MerchantID, d.Type, d.FileName, d.ContentType, d.Size, d.StorageKey, d.CreatedAt)
         if err != nil {
                 log.Println(query)
                 log.Println(err)
@@ -63,10 +262,214 @@ func (mr *sqliteMerchantRepo) Store(ctx context.Context, m *domain.Merchant) err
                 return err
         }
 
-        m.ID = lastID
+        d.ID = lastID
+        return nil
+}
+
+func (mr *sqliteMerchantRepo) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+        query := "SELECT " + documentColumns + " FROM merchant_documents WHERE merchant_id=? ORDER BY id"
+
+        rows, err := mr.DB.QueryContext(ctx, query, merchantID)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.MerchantDocument{}
+        for rows.Next() {
+                data := domain.MerchantDocument{}
+                err := rows.Scan(
+                        &data.ID,
+                        &data.MerchantID,
+                        &data.Type,
+                        &data.FileName,
+                        &data.ContentType,
+                        &data.Size,
+                        &data.StorageKey,
+                        &data.CreatedAt,
+                )
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, data)
+        }
+
+        return result, rows.Err()
+}
+
+func (mr *sqliteMerchantRepo) fetchOwners(ctx context.Context, merchantID int64) ([]domain.Owner, error) {
+        query := "SELECT name, id_number, share FROM merchant_owners WHERE merchant_id=? ORDER BY position"
+
+        rows, err := mr.DB.QueryContext(ctx, query, merchantID)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.Owner{}
+        for rows.Next() {
+                data := domain.Owner{}
+                err := rows.Scan(&data.Name, &data.IDNumber, &data.Share)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, data)
+        }
+
+        return result, rows.Err()
+}
+
+func (mr *sqliteMerchantRepo) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+        query := "SELECT " + settingColumns + " FROM settings WHERE id=? LIMIT 1"
+
+        return mr.getSetting(ctx, query, id)
+}
+
+func (mr *sqliteMerchantRepo) GetSettingByNMID(ctx context.Context, nmid string) (domain.Setting, error) {
+        query := "SELECT " + settingColumns + " FROM settings WHERE qr_nmid=? LIMIT 1"
+
+        return mr.getSetting(ctx, query, nmid)
+}
+
+func (mr *sqliteMerchantRepo) FetchSettings(ctx context.Context, merchantID int64) ([]domain.Setting, error) {
+        query := "SELECT " + settingColumns + " FROM settings WHERE merchant_id=? ORDER BY id"
+
+        rows, err := mr.DB.QueryContext(ctx, query, merchantID)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.Setting{}
+        for rows.Next() {
+                data, err := scanSetting(rows)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, data)
+        }
+
+        return result, rows.Err()
+}
+
+func (mr *sqliteMerchantRepo) StoreSetting(ctx context.Context, s *domain.Setting) error {
+        query := "INSERT INTO settings(merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+        qr := domain.QRAccount{}
+        if s.QR != nil {
+                qr = *s.QR
+        }
+
+        stmt, err := mr.DB.PrepareContext(ctx, query)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        res, err := stmt.ExecContext(
+                ctx,
+                s.MerchantID,
+                s.Color,
+                s.PaymentType,
+                s.PaymentName,
+                nullString(qr.Acquirer),
+                nullString(qr.MerchantPAN),
+                nullString(qr.NMID),
+                nullString(qr.Criteria),
+                nullString(qr.MCC),
+                nullString(qr.City),
+                nullString(qr.PostalCode),
+        )
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        lastID, err := res.LastInsertId()
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        s.ID = lastID
+        s.Version = 1
+        return nil
+}
+
+// UpdateSetting writes the setting back over the version it was read at
+// and bumps its version. The merchant it belongs to does not change. It
+// fails with domain.ErrStaleVersion if the setting was changed since, or
+// domain.ErrNotFound if there is no such setting.
+func (mr *sqliteMerchantRepo) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+        query := "UPDATE settings SET color=?, payment_type=?, payment_name=?, qr_acquirer=?, qr_merchant_pan=?, qr_nmid=?, qr_criteria=?, qr_mcc=?, qr_city=?, qr_postal_code=?, version=version+1 WHERE id=? AND version=?"
+
+        qr := domain.QRAccount{}
+        if s.QR != nil {
+                qr = *s.QR
+        }
+
+        res, err := mr.DB.ExecContext(
+                ctx,
+                query,
+                s.Color,
+                s.PaymentType,
+                s.PaymentName,
+                nullString(qr.Acquirer),
+                nullString(qr.MerchantPAN),
+                nullString(qr.NMID),
+                nullString(qr.Criteria),
+                nullString(qr.MCC),
+                nullString(qr.City),
+                nullString(qr.PostalCode),
+                s.ID,
+                s.Version,
+        )
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+        n, err := res.RowsAffected()
+        if err != nil {
+                return err
+        }
+        if n == 0 {
+                return missingOrStale(ctx, mr.DB, "settings", s.ID)
+        }
+
+        s.Version++
         return nil
 }
 
+func (mr *sqliteMerchantRepo) getSetting(ctx context.Context, query string, arg interface{}) (domain.Setting, error) {
+        data, err := scanSetting(mr.DB.QueryRowContext(ctx, query, arg))
+        if err == sql.ErrNoRows {
+                return domain.Setting{}, domain.ErrNotFound
+        }
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return domain.Setting{}, err
+        }
+
+        return data, nil
+}
+
 func (mr *sqliteMerchantRepo) InitSetting(ctx context.Context, m *domain.Merchant) error {
         query := "INSERT INTO settings(merchant_id, color, payment_type, payment_name) VALUES(?, ?, ?, ?)"
 
@@ -87,7 +490,7 @@ func (mr *sqliteMerchantRepo) InitSetting(ctx context.Context, m *domain.Merchan
 }
 
 func (mr *sqliteMerchantRepo) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
-        query := "INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id) VALUES(?, ?)"
+        query := "INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id, share_customers) VALUES(?, ?, ?)"
 
         stmt, err := mr.DB.PrepareContext(ctx, query)
         if err != nil {
@@ -96,12 +499,131 @@ func (mr *sqliteMerchantRepo) SetChild(ctx context.Context, mg *domain.MerchantG
                 return err
         }
 
-        _, err = stmt.ExecContext(ctx, mg.ParentMerchantID, mg.ChildMerchantID)
+        _, err = stmt.ExecContext(ctx, mg.ParentMerchantID, mg.ChildMerchantID, mg.ShareCustomers)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+
+        return nil
+}
+
+type scanner interface {
+        Scan(dest ...interface{}) error
+}
+
+type queryer interface {
+        QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// missingOrStale tells why an update of the table's row with the given id
+// matched nothing.
+func missingOrStale(ctx context.Context, q queryer, table string, id int64) error {
+        query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE id=?)"
+
+        var exists bool
+        err := q.QueryRowContext(ctx, query, id).Scan(&exists)
         if err != nil {
                 log.Println(query)
                 log.Println(err)
                 return err
         }
+        if exists {
+                return domain.ErrStaleVersion
+        }
+
+        return domain.ErrNotFound
+}
+
+// storeOwners inserts a merchant's owners in the order given.
+func storeOwners(ctx context.Context, tx *sql.Tx, merchantID int64, owners []domain.Owner) error {
+        query := "INSERT INTO merchant_owners(merchant_id, position, name, id_number, share) VALUES(?, ?, ?, ?, ?)"
+        for i, o := range owners {
+                _, err := tx.ExecContext(ctx, query, merchantID, i, o.Name, o.IDNumber, o.Share)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return err
+                }
+        }
 
         return nil
 }
+
+// scanMerchant reads a merchants row. Merchants stored before onboarding
+// had only a name, which is still nullable.
+func scanMerchant(row scanner) (domain.Merchant, error) {
+        data := domain.Merchant{}
+        var name sql.NullString
+        err := row.Scan(
+                &data.ID,
+                &name,
+                &data.LegalName,
+                &data.EntityType,
+                &data.RegistrationNumber,
+                &data.TaxID,
+                &data.Address,
+                &data.BankAccount.BankCode,
+                &data.BankAccount.AccountNumber,
+                &data.BankAccount.AccountName,
+                &data.Status,
+                &data.ReviewNotes,
+                &data.ScreeningResult,
+                &data.ScreeningScore,
+                &data.Version,
+        )
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+
+        data.Name = name.String
+        return data, nil
+}
+
+// scanSetting reads a settings row. Every column but the ids is nullable;
+// the QR account is only filled in for settings that have an NMID.
+func scanSetting(row scanner) (domain.Setting, error) {
+        data := domain.Setting{}
+        var color, paymentType, paymentName sql.NullString
+        var acquirer, pan, nmid, criteria, mcc, city, postalCode sql.NullString
+        err := row.Scan(
+                &data.ID,
+                &data.MerchantID,
+                &color,
+                &paymentType,
+                &paymentName,
+                &acquirer,
+                &pan,
+                &nmid,
+                &criteria,
+                &mcc,
+                &city,
+                &postalCode,
+                &data.Version,
+        )
+        if err != nil {
+                return domain.Setting{}, err
+        }
+
+        data.Color = color.String
+        data.PaymentType = paymentType.String
+        data.PaymentName = paymentName.String
+        if nmid.Valid {
+                data.QR = &domain.QRAccount{
+                        Acquirer:    acquirer.String,
+                        MerchantPAN: pan.String,
+                        NMID:        nmid.String,
+                        Criteria:    criteria.String,
+                        MCC:         mcc.String,
+                        City:        city.String,
+                        PostalCode:  postalCode.String,
+                }
+        }
+
+        return data, nil
+}
+
+func nullString(s string) sql.NullString {
+        return sql.NullString{String: s, Valid: s != ""}
+}
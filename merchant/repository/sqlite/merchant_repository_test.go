@@ -5,14 +5,24 @@ import (
         "fmt"
 	"testing"
         "regexp"
+        "time"
 
         "github.com/stretchr/testify/assert"
         sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
 	merchantRepo "github.com/hezbymuhammad/payment-gateway/merchant/repository/sqlite"
 )
 
+var merchantColumns = []string{"id", "name", "legal_name", "entity_type", "registration_number", "tax_id", "address", "bank_code", "bank_account_number", "bank_account_name", "status", "review_notes", "screening_result", "screening_score", "version"}
+
+var ownerColumns = []string{"name", "id_number", "share"}
+
+var documentColumns = []string{"id", "merchant_id", "type", "file_name", "content_type", "size", "storage_key", "created_at"}
+
+var settingColumns = []string{"id", "merchant_id", "color", "payment_type", "payment_name", "qr_acquirer", "qr_merchant_pan", "qr_nmid", "qr_criteria", "qr_mcc", "qr_city", "qr_postal_code", "version"}
+
 func TestIsAuthorizedParentSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
         if err != nil {
@@ -74,23 +84,70 @@ func TestIsAuthorizedParentNotAuthorized(t *testing.T) {
         assert.Equal(t, res, false)
 }
 
+func TestCanShareCustomers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows([]string{"shared"}).AddRow(1)
+        query := regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM merchant_groups WHERE parent_merchant_id=? AND child_merchant_id=? AND share_customers=1 LIMIT 1) as shared")
+
+        mock.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+        m := merchantRepo.NewMerchantRepository(db)
+        data := &domain.MerchantGroup{
+                ParentMerchantID: 1,
+                ChildMerchantID: 2,
+        }
+
+        res, err := m.CanShareCustomers(context.TODO(), data)
+        assert.NoError(t, err)
+        assert.Equal(t, res, true)
+}
+
+func TestCanShareCustomersNotShared(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows([]string{"shared"}).AddRow(0)
+        query := regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM merchant_groups WHERE parent_merchant_id=? AND child_merchant_id=? AND share_customers=1 LIMIT 1) as shared")
+
+        mock.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+        m := merchantRepo.NewMerchantRepository(db)
+        data := &domain.MerchantGroup{
+                ParentMerchantID: 1,
+                ChildMerchantID: 2,
+        }
+
+        res, err := m.CanShareCustomers(context.TODO(), data)
+        assert.NoError(t, err)
+        assert.Equal(t, res, false)
+}
+
 func TestStore(t *testing.T) {
 	db, mock, err := sqlmock.New()
         if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        m := &domain.Merchant{Name: "lorem"}
+        m := &domain.Merchant{Name: "lorem", Owners: []domain.Owner{{Name: "Budi Santoso", Share: 60}, {Name: "Siti Rahma", Share: 40}}, Status: domain.MerchantDraft}
 
-        query := regexp.QuoteMeta("INSERT INTO merchants(name) VALUES(?)")
+        query := regexp.QuoteMeta("INSERT INTO merchants(name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+        ownerQuery := regexp.QuoteMeta("INSERT INTO merchant_owners(merchant_id, position, name, id_number, share) VALUES(?, ?, ?, ?, ?)")
 
-        prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(m.Name).WillReturnResult(sqlmock.NewResult(12, 1))
+        mock.ExpectBegin()
+        mock.ExpectExec(query).WithArgs(m.Name, "", "", "", "", "", "", "", "", domain.MerchantDraft, "", "", 0).WillReturnResult(sqlmock.NewResult(12, 1))
+        mock.ExpectExec(ownerQuery).WithArgs(12, 0, "Budi Santoso", "", 60).WillReturnResult(sqlmock.NewResult(0, 1))
+        mock.ExpectExec(ownerQuery).WithArgs(12, 1, "Siti Rahma", "", 40).WillReturnResult(sqlmock.NewResult(0, 1))
+        mock.ExpectCommit()
         mr := merchantRepo.NewMerchantRepository(db)
 
         err = mr.Store(context.TODO(), m)
         assert.NoError(t, err)
         assert.Equal(t, m.ID, int64(12))
+        assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestStoreLastInsertedError(t *testing.T) {
@@ -99,34 +156,41 @@ func TestStoreLastInsertedError(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        m := &domain.Merchant{Name: "lorem"}
+        m := &domain.Merchant{Name: "lorem", Status: domain.MerchantDraft}
 
-        query := regexp.QuoteMeta("INSERT INTO merchants(name) VALUES(?)")
+        query := regexp.QuoteMeta("INSERT INTO merchants(name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 
-        prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(m.Name).WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+        mock.ExpectBegin()
+        mock.ExpectExec(query).WillReturnResult(sqlmock.NewErrorResult(fmt.Errorf("some error")))
+        mock.ExpectRollback()
         mr := merchantRepo.NewMerchantRepository(db)
 
         err = mr.Store(context.TODO(), m)
         assert.Error(t, err)
+        assert.Equal(t, int64(0), m.ID)
 }
 
-func TestStoreError(t *testing.T) {
+func TestStoreOwnerError(t *testing.T) {
 	db, mock, err := sqlmock.New()
         if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        m := &domain.Merchant{Name: "lorem"}
+        m := &domain.Merchant{Name: "lorem", Owners: []domain.Owner{{Name: "Budi Santoso", Share: 60}}, Status: domain.MerchantDraft}
 
-        query := regexp.QuoteMeta("INSERT INTO merchants(name) VALUES(?)")
+        query := regexp.QuoteMeta("INSERT INTO merchants(name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+        ownerQuery := regexp.QuoteMeta("INSERT INTO merchant_owners(merchant_id, position, name, id_number, share) VALUES(?, ?, ?, ?, ?)")
 
-        prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(m.Name).WillReturnError(fmt.Errorf("some error"))
+        mock.ExpectBegin()
+        mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(12, 1))
+        mock.ExpectExec(ownerQuery).WillReturnError(fmt.Errorf("some error"))
+        mock.ExpectRollback()
         mr := merchantRepo.NewMerchantRepository(db)
 
         err = mr.Store(context.TODO(), m)
         assert.Error(t, err)
+        assert.Equal(t, int64(0), m.ID)
+        assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestInitSetting(t *testing.T) {
@@ -135,7 +199,7 @@ func TestInitSetting(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        m := &domain.Merchant{Name: "lorem"}
+        m := &domain.Merchant{Name: "lorem", Status: domain.MerchantDraft}
 
         query := regexp.QuoteMeta("INSERT INTO settings(merchant_id, color, payment_type, payment_name) VALUES(?, ?, ?, ?)")
 
@@ -153,7 +217,7 @@ func TestInitSettingError(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        m := &domain.Merchant{Name: "lorem"}
+        m := &domain.Merchant{Name: "lorem", Status: domain.MerchantDraft}
 
         query := regexp.QuoteMeta("INSERT INTO settings(merchant_id, color, payment_type, payment_name) VALUES(?, ?, ?, ?)")
 
@@ -171,10 +235,10 @@ func TestSetChild(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        query := regexp.QuoteMeta("INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id) VALUES(?, ?)")
+        query := regexp.QuoteMeta("INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id, share_customers) VALUES(?, ?, ?)")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(12, 1))
+        prep.ExpectExec().WithArgs(1, 2, false).WillReturnResult(sqlmock.NewResult(12, 1))
         mr := merchantRepo.NewMerchantRepository(db)
         data := &domain.MerchantGroup{
                 ParentMerchantID: 1,
@@ -191,10 +255,10 @@ func TestSetChildError(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        query := regexp.QuoteMeta("INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id) VALUES(?, ?)")
+        query := regexp.QuoteMeta("INSERT INTO merchant_groups(parent_merchant_id, child_merchant_id, share_customers) VALUES(?, ?, ?)")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(1, 2).WillReturnError(fmt.Errorf("some error"))
+        prep.ExpectExec().WithArgs(1, 2, false).WillReturnError(fmt.Errorf("some error"))
         mr := merchantRepo.NewMerchantRepository(db)
         data := &domain.MerchantGroup{
                 ParentMerchantID: 1,
@@ -204,3 +268,291 @@ func TestSetChildError(t *testing.T) {
         err = mr.SetChild(context.TODO(), data)
         assert.Error(t, err)
 }
+
+func TestGetSetting(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows(settingColumns).AddRow(1, 1, "RED", "CARD", "VISA", nil, nil, nil, nil, nil, nil, nil, 1)
+        query := regexp.QuoteMeta("SELECT id, merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code, version FROM settings WHERE id=? LIMIT 1")
+
+        mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        res, err := mr.GetSetting(context.TODO(), 1)
+        assert.NoError(t, err)
+        assert.Equal(t, domain.Setting{ID: 1, MerchantID: 1, Color: "RED", PaymentType: "CARD", PaymentName: "VISA", Version: 1}, res)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows(merchantColumns)
+        query := regexp.QuoteMeta("SELECT id, name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score, version FROM merchants WHERE id=? LIMIT 1")
+
+        mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        _, err = mr.GetByID(context.TODO(), 1)
+        assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestFetchSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows(settingColumns).
+                AddRow(1, 6, "RED", "CARD", "VISA", nil, nil, nil, nil, nil, nil, nil, 1).
+                AddRow(6, 6, nil, "QR", "QRIS", "ID.CO.BANKSIM.WWW", "9360000812345678901", "ID1026000012345", "UMI", "5812", "JAKARTA", "12190", 3)
+        query := regexp.QuoteMeta("SELECT id, merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code, version FROM settings WHERE merchant_id=? ORDER BY id")
+
+        mock.ExpectQuery(query).WithArgs(6).WillReturnRows(rows)
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        res, err := mr.FetchSettings(context.TODO(), 6)
+        assert.NoError(t, err)
+        assert.Len(t, res, 2)
+        assert.Equal(t, "", res[1].Color)
+        assert.Nil(t, res[0].QR)
+        assert.Equal(t, "ID1026000012345", res[1].QR.NMID)
+        assert.Equal(t, int64(3), res[1].Version)
+}
+
+func TestStoreQRSetting(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        query := regexp.QuoteMeta("INSERT INTO settings(merchant_id, color, payment_type, payment_name, qr_acquirer, qr_merchant_pan, qr_nmid, qr_criteria, qr_mcc, qr_city, qr_postal_code) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+
+        prep := mock.ExpectPrepare(query)
+        prep.ExpectExec().WithArgs(6, "RED", "QR", "QRIS", nil, nil, "ID1026000012345", "UMI", "5812", "JAKARTA", nil).WillReturnResult(sqlmock.NewResult(6, 1))
+        mr := merchantRepo.NewMerchantRepository(db)
+        data := &domain.Setting{
+                MerchantID: 6,
+                Color: "RED",
+                PaymentType: "QR",
+                PaymentName: "QRIS",
+                QR: &domain.QRAccount{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", City: "JAKARTA"},
+        }
+
+        err = mr.StoreSetting(context.TODO(), data)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(6), data.ID)
+}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows(merchantColumns).AddRow(7, "KOPI_JOSS", "PT Kopi Joss", "pt", "AHU-0012345", "012345678901000", "Jl. Sudirman 1", "BCA", "1234567890", "PT KOPI JOSS", domain.MerchantPendingReview, "", domain.ScreeningMatch, 93, 2)
+        ownerRows := sqlmock.NewRows(ownerColumns).AddRow("Budi Santoso", "3171010101800001", 60).AddRow("Siti Rahma", "3171010101850002", 40)
+        query := regexp.QuoteMeta("SELECT id, name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score, version FROM merchants WHERE id=? LIMIT 1")
+        ownerQuery := regexp.QuoteMeta("SELECT name, id_number, share FROM merchant_owners WHERE merchant_id=? ORDER BY position")
+
+        mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
+        mock.ExpectQuery(ownerQuery).WithArgs(7).WillReturnRows(ownerRows)
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        res, err := mr.GetByID(context.TODO(), 7)
+        assert.NoError(t, err)
+        assert.Equal(t, domain.Merchant{
+                ID: 7,
+                Name: "KOPI_JOSS",
+                LegalName: "PT Kopi Joss",
+                EntityType: "pt",
+                RegistrationNumber: "AHU-0012345",
+                TaxID: "012345678901000",
+                Address: "Jl. Sudirman 1",
+                BankAccount: domain.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "PT KOPI JOSS"},
+                Owners: []domain.Owner{
+                        {Name: "Budi Santoso", IDNumber: "3171010101800001", Share: 60},
+                        {Name: "Siti Rahma", IDNumber: "3171010101850002", Share: 40},
+                },
+                Status: domain.MerchantPendingReview,
+                ScreeningResult: domain.ScreeningMatch,
+                ScreeningScore: 93,
+                Version: 2,
+        }, res)
+}
+
+func TestGetByIDNullName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        rows := sqlmock.NewRows(merchantColumns).AddRow(1, nil, "", "", "", "", "", "", "", "", domain.MerchantApproved, "", "", 0, 1)
+        query := regexp.QuoteMeta("SELECT id, name, legal_name, entity_type, registration_number, tax_id, address, bank_code, bank_account_number, bank_account_name, status, review_notes, screening_result, screening_score, version FROM merchants WHERE id=? LIMIT 1")
+        ownerQuery := regexp.QuoteMeta("SELECT name, id_number, share FROM merchant_owners WHERE merchant_id=? ORDER BY position")
+
+        mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+        mock.ExpectQuery(ownerQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows(ownerColumns))
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        res, err := mr.GetByID(context.TODO(), 1)
+        assert.NoError(t, err)
+        assert.Equal(t, "", res.Name)
+        assert.Equal(t, []domain.Owner{}, res.Owners)
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        m := &domain.Merchant{ID: 7, Name: "KOPI_JOSS", Owners: []domain.Owner{{Name: "Budi Santoso", Share: 100}}, Status: domain.MerchantApproved, ReviewNotes: "ok", ScreeningResult: domain.ScreeningClear, Version: 3}
+        query := regexp.QuoteMeta("UPDATE merchants SET name=?, legal_name=?, entity_type=?, registration_number=?, tax_id=?, address=?, bank_code=?, bank_account_number=?, bank_account_name=?, status=?, review_notes=?, screening_result=?, screening_score=?, version=version+1 WHERE id=? AND version=?")
+
+        mock.ExpectBegin()
+        mock.ExpectExec(query).WithArgs("KOPI_JOSS", "", "", "", "", "", "", "", "", domain.MerchantApproved, "ok", domain.ScreeningClear, 0, 7, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+        mock.ExpectExec(regexp.QuoteMeta("DELETE FROM merchant_owners WHERE merchant_id=?")).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 2))
+        mock.ExpectExec(regexp.QuoteMeta("INSERT INTO merchant_owners(merchant_id, position, name, id_number, share) VALUES(?, ?, ?, ?, ?)")).WithArgs(7, 0, "Budi Santoso", "", 100).WillReturnResult(sqlmock.NewResult(0, 1))
+        mock.ExpectCommit()
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        err = mr.Update(context.TODO(), m)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(4), m.Version)
+        assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        m := &domain.Merchant{ID: 7, Name: "KOPI_JOSS", Status: domain.MerchantDraft, Version: 3}
+        query := regexp.QuoteMeta("UPDATE merchants SET name=?, legal_name=?, entity_type=?, registration_number=?, tax_id=?, address=?, bank_code=?, bank_account_number=?, bank_account_name=?, status=?, review_notes=?, screening_result=?, screening_score=?, version=version+1 WHERE id=? AND version=?")
+
+        mock.ExpectBegin()
+        mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+        mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM merchants WHERE id=?)")).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+        mock.ExpectRollback()
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        err = mr.Update(context.TODO(), m)
+        assert.Equal(t, domain.ErrStaleVersion, err)
+        assert.Equal(t, int64(3), m.Version)
+        assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSetting(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        query := regexp.QuoteMeta("UPDATE settings SET color=?, payment_type=?, payment_name=?, qr_acquirer=?, qr_merchant_pan=?, qr_nmid=?, qr_criteria=?, qr_mcc=?, qr_city=?, qr_postal_code=?, version=version+1 WHERE id=? AND version=?")
+
+        mock.ExpectExec(query).WithArgs("BLUE", "CARD", "MASTERCARD", nil, nil, nil, nil, nil, nil, nil, 6, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+        mr := merchantRepo.NewMerchantRepository(db)
+        data := &domain.Setting{ID: 6, MerchantID: 6, Color: "BLUE", PaymentType: "CARD", PaymentName: "MASTERCARD", Version: 2}
+
+        err = mr.UpdateSetting(context.TODO(), data)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(3), data.Version)
+}
+
+func TestUpdateSettingNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        query := regexp.QuoteMeta("UPDATE settings SET color=?, payment_type=?, payment_name=?, qr_acquirer=?, qr_merchant_pan=?, qr_nmid=?, qr_criteria=?, qr_mcc=?, qr_city=?, qr_postal_code=?, version=version+1 WHERE id=? AND version=?")
+
+        mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+        mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM settings WHERE id=?)")).WithArgs(404).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        err = mr.UpdateSetting(context.TODO(), &domain.Setting{ID: 404, PaymentType: "CARD", Version: 1})
+        assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestStoreDocument(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+        d := &domain.MerchantDocument{MerchantID: 7, Type: "id_card", FileName: "ktp.jpg", ContentType: "image/jpeg", Size: 2048, StorageKey: "merchants/7/abc", CreatedAt: createdAt}
+        query := regexp.QuoteMeta("INSERT INTO merchant_documents(merchant_id, type, file_name, content_type, size, storage_key, created_at) VALUES(?, ?, ?, ?, ?, ?, ?)")
+
+        mock.ExpectPrepare(query).ExpectExec().WithArgs(7, "id_card", "ktp.jpg", "image/jpeg", 2048, "merchants/7/abc", createdAt).WillReturnResult(sqlmock.NewResult(3, 1))
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        err = mr.StoreDocument(context.TODO(), d)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(3), d.ID)
+}
+
+func TestFetchDocuments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+        rows := sqlmock.NewRows(documentColumns).
+                AddRow(3, 7, "id_card", "ktp.jpg", "image/jpeg", 2048, "merchants/7/abc", createdAt).
+                AddRow(4, 7, "tax_card", "npwp.pdf", "application/pdf", 4096, "merchants/7/def", createdAt)
+        query := regexp.QuoteMeta("SELECT id, merchant_id, type, file_name, content_type, size, storage_key, created_at FROM merchant_documents WHERE merchant_id=? ORDER BY id")
+
+        mock.ExpectQuery(query).WithArgs(7).WillReturnRows(rows)
+        mr := merchantRepo.NewMerchantRepository(db)
+
+        res, err := mr.FetchDocuments(context.TODO(), 7)
+        assert.NoError(t, err)
+        assert.Len(t, res, 2)
+        assert.Equal(t, "merchants/7/def", res[1].StorageKey)
+        assert.Equal(t, createdAt, res[0].CreatedAt)
+}
+
+func TestStoreAndUpdateMigrated(t *testing.T) {
+        db := migrationtest.NewDB(t)
+        mr := merchantRepo.NewMerchantRepository(db)
+        m := &domain.Merchant{
+                Name: "KOPI_JOSS",
+                LegalName: "PT Kopi Joss",
+                EntityType: "pt",
+                TaxID: "012345678901000",
+                BankAccount: domain.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "PT KOPI JOSS"},
+                Owners: []domain.Owner{{Name: "Budi Santoso", Share: 60}, {Name: "Siti Rahma", Share: 40}},
+                Status: domain.MerchantDraft,
+        }
+
+        err := mr.Store(context.TODO(), m)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(1), m.ID)
+
+        m.Owners = []domain.Owner{{Name: "Siti Rahma", IDNumber: "3171010101850002", Share: 100}}
+        m.Status = domain.MerchantSubmitted
+        err = mr.Update(context.TODO(), m)
+        assert.NoError(t, err)
+
+        res, err := mr.GetByID(context.TODO(), m.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, *m, res)
+
+        d := &domain.MerchantDocument{MerchantID: m.ID, Type: "id_card", FileName: "ktp.jpg", ContentType: "image/jpeg", Size: 2048, StorageKey: "merchants/1/abc", CreatedAt: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)}
+        err = mr.StoreDocument(context.TODO(), d)
+        assert.NoError(t, err)
+
+        documents, err := mr.FetchDocuments(context.TODO(), m.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, []domain.MerchantDocument{*d}, documents)
+}
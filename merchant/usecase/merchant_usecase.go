@@ -2,21 +2,55 @@ package usecase
 
 import (
         "context"
+        "crypto/rand"
+        "encoding/hex"
+        "fmt"
+        "io"
+        "strings"
+        "time"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
 )
 
 type merchantUsecase struct {
         merchantRepo domain.MerchantRepository
+        documents domain.BlobStore
+        screening domain.ScreeningUsecase
 }
 
-func NewMerchantUsecase(mr domain.MerchantRepository) domain.MerchantUsecase {
-        return &merchantUsecase{
+// Option configures the optional parts of the merchant usecase.
+type Option func(*merchantUsecase)
+
+// WithScreening screens merchants against sanctions lists and blocklists
+// when they are submitted. A hit holds them for review.
+func WithScreening(su domain.ScreeningUsecase) Option {
+        return func(mu *merchantUsecase) {
+                mu.screening = su
+        }
+}
+
+// NewMerchantUsecase keeps merchants' KYC documents in documents.
+func NewMerchantUsecase(mr domain.MerchantRepository, documents domain.BlobStore, opts ...Option) domain.MerchantUsecase {
+        mu := &merchantUsecase{
                 merchantRepo: mr,
+                documents: documents,
         }
+        for _, opt := range opts {
+                opt(mu)
+        }
+
+        return mu
 }
 
+// Store creates a draft merchant. Its KYC details can be given now or
+// filled in later with Update.
 func (mu *merchantUsecase) Store(ctx context.Context, m *domain.Merchant) error {
+        normalize(m)
+        m.Status = domain.MerchantDraft
+        m.ReviewNotes = ""
+        m.ScreeningResult = ""
+        m.ScreeningScore = 0
+
         err := mu.merchantRepo.Store(ctx, m)
         if err != nil {
                 return err
@@ -25,6 +59,233 @@ func (mu *merchantUsecase) Store(ctx context.Context, m *domain.Merchant) error
         return mu.merchantRepo.InitSetting(ctx, m)
 }
 
+func (mu *merchantUsecase) GetByID(ctx context.Context, id int64) (domain.Merchant, error) {
+        return mu.merchantRepo.GetByID(ctx, id)
+}
+
+// Update replaces a draft merchant's details and owners as of the version
+// the caller read. Once submitted they can no longer be changed.
+func (mu *merchantUsecase) Update(ctx context.Context, m *domain.Merchant) error {
+        existing, err := mu.merchantRepo.GetByID(ctx, m.ID)
+        if err != nil {
+                return err
+        }
+        if m.Version != existing.Version {
+                return domain.ErrStaleVersion
+        }
+        if existing.Status != domain.MerchantDraft {
+                return domain.ErrMerchantState
+        }
+
+        normalize(m)
+        m.Status = existing.Status
+        m.ReviewNotes = existing.ReviewNotes
+        m.ScreeningResult = existing.ScreeningResult
+        m.ScreeningScore = existing.ScreeningScore
+
+        return mu.merchantRepo.Update(ctx, m)
+}
+
+// AddDocument uploads a KYC document for a draft merchant.
+func (mu *merchantUsecase) AddDocument(ctx context.Context, d *domain.MerchantDocument, r io.Reader) error {
+        m, err := mu.merchantRepo.GetByID(ctx, d.MerchantID)
+        if err != nil {
+                return err
+        }
+        if m.Status != domain.MerchantDraft {
+                return domain.ErrMerchantState
+        }
+
+        name, err := newKey()
+        if err != nil {
+                return err
+        }
+        d.StorageKey = fmt.Sprintf("merchants/%d/%s", m.ID, name)
+        d.Size, err = mu.documents.Put(ctx, d.StorageKey, r)
+        if err != nil {
+                return err
+        }
+        d.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+        return mu.merchantRepo.StoreDocument(ctx, d)
+}
+
+func (mu *merchantUsecase) FetchDocuments(ctx context.Context, merchantID int64) ([]domain.MerchantDocument, error) {
+        _, err := mu.merchantRepo.GetByID(ctx, merchantID)
+        if err != nil {
+                return nil, err
+        }
+
+        return mu.merchantRepo.FetchDocuments(ctx, merchantID)
+}
+
+func (mu *merchantUsecase) GetDocument(ctx context.Context, merchantID int64, id int64) (domain.MerchantDocument, io.ReadCloser, error) {
+        documents, err := mu.FetchDocuments(ctx, merchantID)
+        if err != nil {
+                return domain.MerchantDocument{}, nil, err
+        }
+
+        for _, d := range documents {
+                if d.ID == id {
+                        r, err := mu.documents.Get(ctx, d.StorageKey)
+                        if err != nil {
+                                return domain.MerchantDocument{}, nil, err
+                        }
+                        return d, r, nil
+                }
+        }
+
+        return domain.MerchantDocument{}, nil, domain.ErrNotFound
+}
+
+// Submit sends a draft merchant with complete details and the required
+// documents for review. With screening on it is screened straight away and
+// held in pending_review on a hit; if screening fails it stays a draft.
+func (mu *merchantUsecase) Submit(ctx context.Context, id int64) (domain.Merchant, error) {
+        m, err := mu.merchantRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+        if m.Status != domain.MerchantDraft {
+                return domain.Merchant{}, domain.ErrMerchantState
+        }
+
+        documents, err := mu.merchantRepo.FetchDocuments(ctx, id)
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+        if !complete(m, documents) {
+                return domain.Merchant{}, domain.ErrIncompleteKYC
+        }
+
+        m.Status = domain.MerchantSubmitted
+        if mu.screening != nil {
+                err = mu.screening.Screen(ctx, &m)
+        } else {
+                err = mu.merchantRepo.Update(ctx, &m)
+        }
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+
+        return m, nil
+}
+
+// Approve lets a submitted merchant take payments. Approving one held by
+// screening clears its hits; it is only held again for new ones.
+func (mu *merchantUsecase) Approve(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+        return mu.review(ctx, id, domain.MerchantApproved, notes)
+}
+
+// Reject turns down a submitted merchant for good.
+func (mu *merchantUsecase) Reject(ctx context.Context, id int64, notes string) (domain.Merchant, error) {
+        return mu.review(ctx, id, domain.MerchantRejected, notes)
+}
+
+func (mu *merchantUsecase) review(ctx context.Context, id int64, status string, notes string) (domain.Merchant, error) {
+        m, err := mu.merchantRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+        if m.Status != domain.MerchantSubmitted && m.Status != domain.MerchantPendingReview {
+                return domain.Merchant{}, domain.ErrMerchantState
+        }
+
+        m.Status = status
+        m.ReviewNotes = notes
+        err = mu.merchantRepo.Update(ctx, &m)
+        if err != nil {
+                return domain.Merchant{}, err
+        }
+
+        return m, nil
+}
+
 func (mu *merchantUsecase) SetChild(ctx context.Context, mg *domain.MerchantGroup) error {
         return mu.merchantRepo.SetChild(ctx, mg)
 }
+
+// StoreSetting adds a payment setting for an existing merchant. Only QR
+// settings carry a QR merchant account.
+func (mu *merchantUsecase) StoreSetting(ctx context.Context, s *domain.Setting) error {
+        _, err := mu.merchantRepo.GetByID(ctx, s.MerchantID)
+        if err != nil {
+                return err
+        }
+        if s.PaymentType != "QR" {
+                s.QR = nil
+        }
+
+        return mu.merchantRepo.StoreSetting(ctx, s)
+}
+
+func (mu *merchantUsecase) GetSetting(ctx context.Context, id int64) (domain.Setting, error) {
+        return mu.merchantRepo.GetSetting(ctx, id)
+}
+
+// UpdateSetting replaces a payment setting as of the version the caller
+// read. It stays with the merchant it was created for.
+func (mu *merchantUsecase) UpdateSetting(ctx context.Context, s *domain.Setting) error {
+        existing, err := mu.merchantRepo.GetSetting(ctx, s.ID)
+        if err != nil {
+                return err
+        }
+        if s.Version != existing.Version {
+                return domain.ErrStaleVersion
+        }
+        s.MerchantID = existing.MerchantID
+        if s.PaymentType != "QR" {
+                s.QR = nil
+        }
+
+        return mu.merchantRepo.UpdateSetting(ctx, s)
+}
+
+// complete reports whether a merchant has everything review needs. Only
+// individuals may go without a business registration number.
+func complete(m domain.Merchant, documents []domain.MerchantDocument) bool {
+        if m.LegalName == "" || m.EntityType == "" || m.Address == "" || len(m.Owners) == 0 {
+                return false
+        }
+        if m.RegistrationNumber == "" && m.EntityType != "individual" {
+                return false
+        }
+        if len(m.TaxID) != 15 && len(m.TaxID) != 16 {
+                return false
+        }
+        if m.BankAccount.BankCode == "" || m.BankAccount.AccountNumber == "" || m.BankAccount.AccountName == "" {
+                return false
+        }
+
+        uploaded := map[string]bool{}
+        for _, d := range documents {
+                uploaded[d.Type] = true
+        }
+        for _, t := range domain.RequiredMerchantDocuments {
+                if !uploaded[t] {
+                        return false
+                }
+        }
+
+        return true
+}
+
+// normalize tidies up the details as given: the entity type is lower cased
+// and the tax ID (NPWP) loses the dots and dashes it is usually written with.
+func normalize(m *domain.Merchant) {
+        m.EntityType = strings.ToLower(m.EntityType)
+        m.TaxID = strings.NewReplacer(".", "", "-", "").Replace(m.TaxID)
+        if m.Owners == nil {
+                m.Owners = []domain.Owner{}
+        }
+}
+
+func newKey() (string, error) {
+        b := make([]byte, 16)
+        _, err := rand.Read(b)
+        if err != nil {
+                return "", err
+        }
+
+        return hex.EncodeToString(b), nil
+}
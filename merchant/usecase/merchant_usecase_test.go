@@ -3,6 +3,7 @@ package usecase_test
 import (
 	"context"
         "errors"
+        "strings"
         "testing"
 
         "github.com/stretchr/testify/assert"
@@ -19,7 +20,7 @@ func TestStore(t *testing.T) {
 
         mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
         mockRepo.On("InitSetting", mock.Anything, mock.Anything).Return(nil).Once()
-        u := merchantUsecase.NewMerchantUsecase(mockRepo)
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
 
         err := u.Store(context.TODO(), &data)
 
@@ -34,7 +35,7 @@ func TestFailedStore(t *testing.T) {
 
         mockRepo.On("Store", mock.Anything, mock.Anything).Return(dummyErr).Once()
         mockRepo.On("InitSetting", mock.Anything, mock.Anything).Return(nil).Once()
-        u := merchantUsecase.NewMerchantUsecase(mockRepo)
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
 
         err := u.Store(context.TODO(), &data)
         assert.Equal(t, err, dummyErr)
@@ -44,7 +45,7 @@ func TestSetChild(t *testing.T) {
         mockRepo := new(mocks.MerchantRepository)
         mockRepo.On("SetChild", mock.Anything, mock.Anything).Return(nil).Once()
 
-        u := merchantUsecase.NewMerchantUsecase(mockRepo)
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
         data := &domain.MerchantGroup{
                 ParentMerchantID: 1,
                 ChildMerchantID: 2,
@@ -59,7 +60,7 @@ func TestFailedSetChild(t *testing.T) {
         dummyErr := errors.New("some err")
         mockRepo.On("SetChild", mock.Anything, mock.Anything).Return(dummyErr).Once()
 
-        u := merchantUsecase.NewMerchantUsecase(mockRepo)
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
         data := &domain.MerchantGroup{
                 ParentMerchantID: 1,
                 ChildMerchantID: 2,
@@ -68,3 +69,323 @@ func TestFailedSetChild(t *testing.T) {
         err := u.SetChild(context.TODO(), data)
         assert.Equal(t, err, dummyErr)
 }
+
+func TestStoreSetting(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6, Name: "CAFE"}, nil).Once()
+        mockRepo.On("StoreSetting", mock.Anything, mock.Anything).Return(nil).Once()
+
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := &domain.Setting{
+                MerchantID: 6,
+                PaymentType: "CARD",
+                PaymentName: "VISA",
+                QR: &domain.QRAccount{NMID: "ID1026000012345"},
+        }
+
+        err := u.StoreSetting(context.TODO(), data)
+        assert.NoError(t, err)
+        assert.Nil(t, data.QR)
+}
+
+func TestStoreSettingUnknownMerchant(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(99)).Return(domain.Merchant{}, domain.ErrNotFound).Once()
+
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := &domain.Setting{MerchantID: 99, PaymentType: "QR"}
+
+        err := u.StoreSetting(context.TODO(), data)
+        assert.Equal(t, domain.ErrNotFound, err)
+        mockRepo.AssertNotCalled(t, "StoreSetting", mock.Anything, mock.Anything)
+}
+
+// kyc is a merchant with every detail review needs.
+var kyc = domain.Merchant{
+        ID: 11,
+        Name: "KOPI_JOSS",
+        LegalName: "PT Kopi Joss",
+        EntityType: "pt",
+        RegistrationNumber: "AHU-0012345",
+        TaxID: "012345678901000",
+        Address: "Jl. Sudirman 1",
+        BankAccount: domain.BankAccount{BankCode: "BCA", AccountNumber: "1234567890", AccountName: "PT KOPI JOSS"},
+        Owners: []domain.Owner{{Name: "Budi Santoso", Share: 100}},
+        Status: domain.MerchantDraft,
+}
+
+var documents = []domain.MerchantDocument{
+        {ID: 1, MerchantID: 11, Type: "id_card"},
+        {ID: 2, MerchantID: 11, Type: "tax_card"},
+        {ID: 3, MerchantID: 11, Type: "bank_statement"},
+}
+
+func TestStoreDraft(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        data := domain.Merchant{Name: "lorem", EntityType: "PT", TaxID: "01.234.567.8-901.000", Status: domain.MerchantApproved}
+
+        mockRepo.On("Store", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.Status == domain.MerchantDraft
+        })).Return(nil).Once()
+        mockRepo.On("InitSetting", mock.Anything, mock.Anything).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, "pt", data.EntityType)
+        assert.Equal(t, "012345678901000", data.TaxID)
+        assert.Equal(t, []domain.Owner{}, data.Owners)
+}
+
+func TestUpdateKeepsStatus(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantDraft}, nil).Once()
+        mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.Status == domain.MerchantDraft && m.LegalName == "PT Kopi Joss"
+        })).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := kyc
+        data.Status = domain.MerchantApproved
+
+        err := u.Update(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSubmitted(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantSubmitted}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := kyc
+
+        err := u.Update(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrMerchantState, err)
+        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateStale(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantDraft, Version: 3}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := kyc
+        data.Version = 2
+
+        err := u.Update(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrStaleVersion, err)
+        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateSetting(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetSetting", mock.Anything, int64(6)).Return(domain.Setting{ID: 6, MerchantID: 11, PaymentType: "QR", Version: 2}, nil).Once()
+        mockRepo.On("UpdateSetting", mock.Anything, mock.MatchedBy(func(s *domain.Setting) bool {
+                return s.MerchantID == 11 && s.PaymentType == "CARD" && s.QR == nil
+        })).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := domain.Setting{ID: 6, MerchantID: 12, PaymentType: "CARD", PaymentName: "VISA", QR: &domain.QRAccount{NMID: "ID1026000012345"}, Version: 2}
+
+        err := u.UpdateSetting(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateSettingStale(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetSetting", mock.Anything, int64(6)).Return(domain.Setting{ID: 6, MerchantID: 11, PaymentType: "CARD", Version: 3}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+        data := domain.Setting{ID: 6, PaymentType: "CARD", PaymentName: "VISA", Version: 2}
+
+        err := u.UpdateSetting(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrStaleVersion, err)
+        mockRepo.AssertNotCalled(t, "UpdateSetting", mock.Anything, mock.Anything)
+}
+
+func TestAddDocument(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockBlobs := new(mocks.BlobStore)
+        r := strings.NewReader("%PDF-1.4")
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(kyc, nil).Once()
+        mockBlobs.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+                return strings.HasPrefix(key, "merchants/11/")
+        }), r).Return(int64(8), nil).Once()
+        mockRepo.On("StoreDocument", mock.Anything, mock.Anything).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, mockBlobs)
+        data := domain.MerchantDocument{MerchantID: 11, Type: "tax_card", FileName: "npwp.pdf", ContentType: "application/pdf"}
+
+        err := u.AddDocument(context.TODO(), &data, r)
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(8), data.Size)
+        assert.False(t, data.CreatedAt.IsZero())
+}
+
+func TestAddDocumentSubmitted(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockBlobs := new(mocks.BlobStore)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantSubmitted}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, mockBlobs)
+        data := domain.MerchantDocument{MerchantID: 11, Type: "tax_card"}
+
+        err := u.AddDocument(context.TODO(), &data, strings.NewReader("%PDF-1.4"))
+
+        assert.Equal(t, domain.ErrMerchantState, err)
+        mockBlobs.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetDocumentOtherMerchant(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockBlobs := new(mocks.BlobStore)
+        mockRepo.On("GetByID", mock.Anything, int64(12)).Return(domain.Merchant{ID: 12}, nil).Once()
+        mockRepo.On("FetchDocuments", mock.Anything, int64(12)).Return([]domain.MerchantDocument{}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, mockBlobs)
+
+        _, _, err := u.GetDocument(context.TODO(), 12, 1)
+
+        assert.Equal(t, domain.ErrNotFound, err)
+        mockBlobs.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestSubmit(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(kyc, nil).Once()
+        mockRepo.On("FetchDocuments", mock.Anything, int64(11)).Return(documents, nil).Once()
+        mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.Status == domain.MerchantSubmitted
+        })).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        res, err := u.Submit(context.TODO(), 11)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.MerchantSubmitted, res.Status)
+}
+
+func TestSubmitIncomplete(t *testing.T) {
+        tests := map[string]func(m *domain.Merchant) []domain.MerchantDocument{
+                "no tax id": func(m *domain.Merchant) []domain.MerchantDocument {
+                        m.TaxID = ""
+                        return documents
+                },
+                "short tax id": func(m *domain.Merchant) []domain.MerchantDocument {
+                        m.TaxID = "0123456789"
+                        return documents
+                },
+                "no bank account": func(m *domain.Merchant) []domain.MerchantDocument {
+                        m.BankAccount.AccountNumber = ""
+                        return documents
+                },
+                "no owners": func(m *domain.Merchant) []domain.MerchantDocument {
+                        m.Owners = []domain.Owner{}
+                        return documents
+                },
+                "no registration": func(m *domain.Merchant) []domain.MerchantDocument {
+                        m.RegistrationNumber = ""
+                        return documents
+                },
+                "missing document": func(m *domain.Merchant) []domain.MerchantDocument {
+                        return documents[:2]
+                },
+        }
+
+        for name, change := range tests {
+                t.Run(name, func(t *testing.T) {
+                        mockRepo := new(mocks.MerchantRepository)
+                        m := kyc
+                        docs := change(&m)
+                        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(m, nil).Once()
+                        mockRepo.On("FetchDocuments", mock.Anything, int64(11)).Return(docs, nil).Once()
+                        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+                        _, err := u.Submit(context.TODO(), 11)
+
+                        assert.Equal(t, domain.ErrIncompleteKYC, err)
+                        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+                })
+        }
+}
+
+func TestSubmitIndividualWithoutRegistration(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        m := kyc
+        m.EntityType = "individual"
+        m.RegistrationNumber = ""
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(m, nil).Once()
+        mockRepo.On("FetchDocuments", mock.Anything, int64(11)).Return(documents, nil).Once()
+        mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        _, err := u.Submit(context.TODO(), 11)
+
+        assert.NoError(t, err)
+}
+
+func TestSubmitWithScreening(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockScreening := new(mocks.ScreeningUsecase)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(kyc, nil).Once()
+        mockRepo.On("FetchDocuments", mock.Anything, int64(11)).Return(documents, nil).Once()
+        mockScreening.On("Screen", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.Status == domain.MerchantSubmitted
+        })).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Merchant).Status = domain.MerchantPendingReview
+        }).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil, merchantUsecase.WithScreening(mockScreening))
+
+        res, err := u.Submit(context.TODO(), 11)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.MerchantPendingReview, res.Status)
+        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestSubmitNotDraft(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantApproved}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        _, err := u.Submit(context.TODO(), 11)
+
+        assert.Equal(t, domain.ErrMerchantState, err)
+}
+
+func TestApprove(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantPendingReview}, nil).Once()
+        mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+                return m.Status == domain.MerchantApproved && m.ReviewNotes == "false positive"
+        })).Return(nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        res, err := u.Approve(context.TODO(), 11, "false positive")
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.MerchantApproved, res.Status)
+}
+
+func TestApproveDraft(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantDraft}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        _, err := u.Approve(context.TODO(), 11, "")
+
+        assert.Equal(t, domain.ErrMerchantState, err)
+        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRejectNotHeld(t *testing.T) {
+        mockRepo := new(mocks.MerchantRepository)
+        mockRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantApproved}, nil).Once()
+        u := merchantUsecase.NewMerchantUsecase(mockRepo, nil)
+
+        _, err := u.Reject(context.TODO(), 11, "")
+
+        assert.Equal(t, domain.ErrMerchantState, err)
+        mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
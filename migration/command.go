@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Run carries out the migrate subcommand given its arguments: "up", the
+// default, "down [steps]", which reverts one migration unless told
+// otherwise, or "status".
+func Run(ctx context.Context, m *Migrator, args []string, w io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch {
+	case command == "up" && len(args) <= 1:
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			fmt.Fprintf(w, "applied %04d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(w, "already up to date")
+		}
+		return err
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: steps must be a positive number, got %q", args[1])
+			}
+			steps = n
+		}
+		done, err := m.Down(ctx, steps)
+		for _, mg := range done {
+			fmt.Fprintf(w, "reverted %04d_%s\n", mg.Version, mg.Name)
+		}
+		return err
+	case command == "status" && len(args) == 1:
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("usage: migrate [up | down [steps] | status]")
+	}
+}
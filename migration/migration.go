@@ -0,0 +1,267 @@
+// Package migration keeps the database schema up to date. Migrations are
+// SQL files embedded in the binary under sql/<driver>, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql. The versions
+// applied so far are recorded in the schema_migrations table, which is
+// queried with $n placeholders so that SQLite and PostgreSQL both take it.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql
+var files embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one schema change and the way back from it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied, and when.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New returns a migrator for the migrations embedded for driver.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	fsys, err := fs.Sub(files, path.Join("sql", driver))
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("migration: no migrations for driver %q", driver)
+	}
+
+	return NewMigrator(db, migrations), nil
+}
+
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Load reads the migrations in the top directory of fsys, oldest first.
+// Every version needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		match := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		b, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration: version %d is named both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration: %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every migration not applied yet, oldest first, each in its
+// own transaction. It returns the migrations it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+		err = m.apply(ctx, mg.Up, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)", mg.Version, mg.Name, time.Now().UTC().Truncate(time.Second))
+		if err != nil {
+			return done, fmt.Errorf("migration: %d_%s up: %w", mg.Version, mg.Name, err)
+		}
+		log.Printf("migration: applied %04d_%s", mg.Version, mg.Name)
+		done = append(done, mg)
+	}
+
+	return done, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first. It
+// returns the migrations it reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 0 {
+		return nil, fmt.Errorf("migration: cannot revert %d steps", steps)
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	done := []Migration{}
+	for _, v := range versions {
+		mg, ok := m.find(v)
+		if !ok {
+			return done, fmt.Errorf("migration: version %d is applied but not known to this build", v)
+		}
+		err = m.apply(ctx, mg.Down, "DELETE FROM schema_migrations WHERE version=$1", mg.Version)
+		if err != nil {
+			return done, fmt.Errorf("migration: %d_%s down: %w", mg.Version, mg.Name, err)
+		}
+		log.Printf("migration: reverted %04d_%s", mg.Version, mg.Name)
+		done = append(done, mg)
+	}
+
+	return done, nil
+}
+
+// Status lists every known migration, oldest first.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		at, ok := applied[mg.Version]
+		result = append(result, Status{Migration: mg, Applied: ok, AppliedAt: at})
+	}
+
+	return result, nil
+}
+
+// apply runs a migration's SQL and records it in one transaction, so a
+// failed migration leaves neither half behind.
+func (m *Migrator) apply(ctx context.Context, script string, record string, args ...interface{}) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, record, args...)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applied creates the schema_migrations table if need be and returns when
+// each applied version was applied.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	query := "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL)"
+	_, err := m.db.ExecContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	query = "SELECT version, applied_at FROM schema_migrations"
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		err := rows.Scan(&version, &at)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result[version] = at
+	}
+
+	return result, rows.Err()
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return mg, true
+		}
+	}
+
+	return Migration{}, false
+}
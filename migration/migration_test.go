@@ -0,0 +1,143 @@
+package migration_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/migration"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "payment.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func tables(t *testing.T, db *sql.DB) []string {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func TestUpFreshDatabase(t *testing.T) {
+	db := openDB(t)
+	m, err := migration.New(db, "sqlite3")
+	assert.NoError(t, err)
+
+	done, err := m.Up(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), done[0].Version)
+	assert.Contains(t, tables(t, db), "merchants")
+	assert.Contains(t, tables(t, db), "transactions")
+	assert.Contains(t, tables(t, db), "schema_migrations")
+
+	done, err = m.Up(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+}
+
+func TestDownAndUpAgain(t *testing.T) {
+	db := openDB(t)
+	m, err := migration.New(db, "sqlite3")
+	assert.NoError(t, err)
+	_, err = m.Up(context.TODO())
+	assert.NoError(t, err)
+
+	done, err := m.Down(context.TODO(), 100)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, done)
+	assert.Equal(t, []string{"schema_migrations"}, tables(t, db))
+
+	_, err = m.Up(context.TODO())
+	assert.NoError(t, err)
+	assert.Contains(t, tables(t, db), "merchants")
+}
+
+func TestUpStopsAtFailedMigration(t *testing.T) {
+	db := openDB(t)
+	migrations, err := migration.Load(fstest.MapFS{
+		"0001_lorem.up.sql":   {Data: []byte("CREATE TABLE lorem (id INTEGER);")},
+		"0001_lorem.down.sql": {Data: []byte("DROP TABLE lorem;")},
+		"0002_ipsum.up.sql":   {Data: []byte("CREATE TABLE ipsum (id INTEGER); CREATE TABLE broken (;")},
+		"0002_ipsum.down.sql": {Data: []byte("DROP TABLE ipsum;")},
+	})
+	assert.NoError(t, err)
+	m := migration.NewMigrator(db, migrations)
+
+	done, err := m.Up(context.TODO())
+
+	assert.Error(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, []string{"lorem", "schema_migrations"}, tables(t, db))
+
+	status, err := m.Status(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, status[0].Applied)
+	assert.False(t, status[1].Applied)
+}
+
+func TestDownSteps(t *testing.T) {
+	db := openDB(t)
+	migrations, err := migration.Load(fstest.MapFS{
+		"0002_ipsum.up.sql":   {Data: []byte("CREATE TABLE ipsum (id INTEGER);")},
+		"0002_ipsum.down.sql": {Data: []byte("DROP TABLE ipsum;")},
+		"0001_lorem.up.sql":   {Data: []byte("CREATE TABLE lorem (id INTEGER);")},
+		"0001_lorem.down.sql": {Data: []byte("DROP TABLE lorem;")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "lorem", migrations[0].Name)
+	m := migration.NewMigrator(db, migrations)
+	_, err = m.Up(context.TODO())
+	assert.NoError(t, err)
+
+	done, err := m.Down(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ipsum", done[0].Name)
+	assert.Equal(t, []string{"lorem", "schema_migrations"}, tables(t, db))
+}
+
+func TestLoadWithoutDown(t *testing.T) {
+	_, err := migration.Load(fstest.MapFS{
+		"0001_lorem.up.sql": {Data: []byte("CREATE TABLE lorem (id INTEGER);")},
+	})
+
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	db := openDB(t)
+	m, err := migration.New(db, "sqlite3")
+	assert.NoError(t, err)
+	var out bytes.Buffer
+
+	assert.NoError(t, migration.Run(context.TODO(), m, []string{"status"}, &out))
+	assert.Equal(t, "0001_init\tpending\n0002_versions\tpending\n0003_transaction_changes\tpending\n0004_merchant_reference\tpending\n0005_audit_log\tpending\n0006_standing_virtual_accounts\tpending\n0007_card_fingerprints\tpending\n0008_audit_claimed_actor\tpending\n0009_subscription_versions\tpending\n0010_dispute_per_transaction\tpending\n0011_qr_payments\tpending\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, migration.Run(context.TODO(), m, nil, &out))
+	assert.Equal(t, "applied 0001_init\napplied 0002_versions\napplied 0003_transaction_changes\napplied 0004_merchant_reference\napplied 0005_audit_log\napplied 0006_standing_virtual_accounts\napplied 0007_card_fingerprints\napplied 0008_audit_claimed_actor\napplied 0009_subscription_versions\napplied 0010_dispute_per_transaction\napplied 0011_qr_payments\n", out.String())
+
+	assert.Error(t, migration.Run(context.TODO(), m, []string{"down", "0"}, &out))
+	assert.Error(t, migration.Run(context.TODO(), m, []string{"sideways"}, &out))
+}
@@ -0,0 +1,37 @@
+// Package migrationtest gives tests a fresh SQLite database with every
+// migration applied, for checking queries against the real schema.
+package migrationtest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/hezbymuhammad/payment-gateway/migration"
+)
+
+// NewDB migrates a database in a temporary directory. It is closed and
+// removed when the test ends.
+func NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "payment.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migration.New(db, "sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
@@ -0,0 +1,9 @@
+DROP TABLE limit_usages;
+DROP TABLE limits;
+DROP TABLE risk_rules;
+DROP TABLE transactions;
+DROP TABLE settings;
+DROP TABLE merchant_groups;
+DROP TABLE merchant_documents;
+DROP TABLE merchant_owners;
+DROP TABLE merchants;
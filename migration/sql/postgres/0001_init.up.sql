@@ -0,0 +1,141 @@
+CREATE TABLE merchants (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT,
+	legal_name TEXT NOT NULL DEFAULT '',
+	entity_type TEXT NOT NULL DEFAULT '',
+	registration_number TEXT NOT NULL DEFAULT '',
+	tax_id TEXT NOT NULL DEFAULT '',
+	address TEXT NOT NULL DEFAULT '',
+	bank_code TEXT NOT NULL DEFAULT '',
+	bank_account_number TEXT NOT NULL DEFAULT '',
+	bank_account_name TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'draft',
+	review_notes TEXT NOT NULL DEFAULT '',
+	screening_result TEXT NOT NULL DEFAULT '',
+	screening_score INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE merchant_owners (
+	merchant_id BIGINT NOT NULL REFERENCES merchants (id),
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	id_number TEXT NOT NULL,
+	share INTEGER NOT NULL,
+	PRIMARY KEY (merchant_id, position)
+);
+
+CREATE TABLE merchant_documents (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL REFERENCES merchants (id),
+	type TEXT NOT NULL,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	storage_key TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX merchant_documents_merchant ON merchant_documents (merchant_id);
+
+CREATE TABLE merchant_groups (
+	id BIGSERIAL PRIMARY KEY,
+	parent_merchant_id BIGINT NOT NULL,
+	child_merchant_id BIGINT NOT NULL,
+	share_customers BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE settings (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	color TEXT,
+	payment_type TEXT,
+	payment_name TEXT,
+	qr_acquirer TEXT,
+	qr_merchant_pan TEXT,
+	qr_nmid TEXT,
+	qr_criteria TEXT,
+	qr_mcc TEXT,
+	qr_city TEXT,
+	qr_postal_code TEXT
+);
+
+CREATE UNIQUE INDEX settings_qr_nmid ON settings (qr_nmid);
+
+CREATE TABLE transactions (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	parent_merchant_id BIGINT,
+	setting_id BIGINT NOT NULL,
+	status BOOLEAN NOT NULL DEFAULT FALSE,
+	amount BIGINT NOT NULL DEFAULT 0,
+	currency TEXT NOT NULL DEFAULT 'IDR',
+	payment_type TEXT NOT NULL DEFAULT 'CARD',
+	state TEXT NOT NULL DEFAULT 'pending',
+	processor TEXT NOT NULL DEFAULT '',
+	processor_reference TEXT NOT NULL DEFAULT '',
+	response_code TEXT NOT NULL DEFAULT '',
+	response_message TEXT NOT NULL DEFAULT '',
+	routing_decision TEXT NOT NULL DEFAULT '',
+	card_token TEXT NOT NULL DEFAULT '',
+	card_last4 TEXT NOT NULL DEFAULT '',
+	card_brand TEXT NOT NULL DEFAULT '',
+	customer_id BIGINT NOT NULL DEFAULT 0,
+	payment_method_id BIGINT NOT NULL DEFAULT 0,
+	payment_code TEXT NOT NULL DEFAULT '',
+	installment_plan_id BIGINT NOT NULL DEFAULT 0,
+	installment_tenor INTEGER NOT NULL DEFAULT 0,
+	fee BIGINT NOT NULL DEFAULT 0,
+	settlement_amount BIGINT NOT NULL DEFAULT 0,
+	promo_code TEXT NOT NULL DEFAULT '',
+	promotion_id BIGINT NOT NULL DEFAULT 0,
+	original_amount BIGINT NOT NULL DEFAULT 0,
+	discount BIGINT NOT NULL DEFAULT 0,
+	settlement_currency TEXT NOT NULL DEFAULT 'IDR',
+	fx_quote_id BIGINT NOT NULL DEFAULT 0,
+	fx_rate TEXT NOT NULL DEFAULT '',
+	fx_markup INTEGER NOT NULL DEFAULT 0,
+	ip_address TEXT NOT NULL DEFAULT '',
+	ip_country TEXT NOT NULL DEFAULT '',
+	billing_country TEXT NOT NULL DEFAULT '',
+	risk_decision TEXT NOT NULL DEFAULT '',
+	risk_rules TEXT[] NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT '1970-01-01 00:00:00+00'
+);
+
+CREATE INDEX transactions_merchant_state ON transactions (merchant_id, state);
+
+CREATE INDEX transactions_merchant_created ON transactions (merchant_id, created_at);
+
+CREATE TABLE risk_rules (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	action TEXT NOT NULL,
+	field TEXT NOT NULL DEFAULT '',
+	threshold BIGINT NOT NULL DEFAULT 0,
+	window_seconds BIGINT NOT NULL DEFAULT 0,
+	entries TEXT[] NOT NULL DEFAULT '{}',
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX risk_rules_merchant ON risk_rules (merchant_id);
+
+CREATE TABLE limits (
+	merchant_id BIGINT NOT NULL,
+	payment_type TEXT NOT NULL DEFAULT '',
+	max_amount BIGINT NOT NULL DEFAULT 0,
+	daily_volume BIGINT NOT NULL DEFAULT 0,
+	monthly_volume BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (merchant_id, payment_type)
+);
+
+CREATE TABLE limit_usages (
+	merchant_id BIGINT NOT NULL,
+	payment_type TEXT NOT NULL,
+	period TEXT NOT NULL,
+	volume BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (merchant_id, payment_type, period)
+);
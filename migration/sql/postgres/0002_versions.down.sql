@@ -0,0 +1,3 @@
+ALTER TABLE transactions DROP COLUMN version;
+ALTER TABLE settings DROP COLUMN version;
+ALTER TABLE merchants DROP COLUMN version;
@@ -0,0 +1,3 @@
+ALTER TABLE merchants ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE settings ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE transactions ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP TABLE transaction_changes;
+ALTER TABLE transactions DROP COLUMN metadata;
+ALTER TABLE transactions DROP COLUMN description;
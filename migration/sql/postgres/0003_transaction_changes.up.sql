@@ -0,0 +1,14 @@
+ALTER TABLE transactions ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE transaction_changes (
+	id BIGSERIAL PRIMARY KEY,
+	transaction_id BIGINT NOT NULL REFERENCES transactions (id),
+	version BIGINT NOT NULL,
+	field TEXT NOT NULL,
+	before TEXT NOT NULL,
+	after TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX transaction_changes_transaction ON transaction_changes (transaction_id);
@@ -0,0 +1,2 @@
+DROP INDEX transactions_merchant_reference;
+ALTER TABLE transactions DROP COLUMN merchant_reference;
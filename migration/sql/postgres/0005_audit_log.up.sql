@@ -0,0 +1,27 @@
+CREATE TABLE audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	actor TEXT NOT NULL,
+	merchant_id BIGINT NOT NULL,
+	action TEXT NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id BIGINT NOT NULL,
+	before TEXT NOT NULL,
+	after TEXT NOT NULL,
+	request_id TEXT NOT NULL,
+	source_ip TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	prev_hash TEXT NOT NULL UNIQUE,
+	hash TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_log_entity ON audit_log (entity, entity_id, created_at);
+CREATE INDEX audit_log_created_at ON audit_log (created_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+	FOR EACH STATEMENT EXECUTE PROCEDURE audit_log_append_only();
@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN card_fingerprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,24 @@
+DROP TABLE qr_payments;
+DROP TABLE screening_hits;
+DROP TABLE screenings;
+DROP TABLE ledger_entries;
+DROP TABLE dispute_evidence;
+DROP TABLE disputes;
+DROP TABLE fx_quotes;
+DROP TABLE fx_rates;
+DROP TABLE promotion_usages;
+DROP TABLE promotions;
+DROP TABLE installment_plans;
+DROP TABLE ewallet_payments;
+DROP TABLE virtual_account_payments;
+DROP TABLE virtual_accounts;
+DROP TABLE checkout_sessions;
+DROP TABLE invoice_reminders;
+DROP TABLE invoice_line_items;
+DROP TABLE invoices;
+DROP TABLE billing_cycles;
+DROP TABLE subscriptions;
+DROP TABLE plans;
+DROP TABLE payment_methods;
+DROP TABLE customers;
+DROP TABLE cards;
@@ -0,0 +1,278 @@
+CREATE TABLE cards (
+	token TEXT PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	bin TEXT NOT NULL,
+	last4 TEXT NOT NULL,
+	brand TEXT NOT NULL,
+	expiry_month INTEGER NOT NULL,
+	expiry_year INTEGER NOT NULL,
+	encrypted_key BYTEA NOT NULL,
+	ciphertext BYTEA NOT NULL,
+	fingerprint TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE customers (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE payment_methods (
+	id BIGSERIAL PRIMARY KEY,
+	customer_id BIGINT NOT NULL,
+	merchant_id BIGINT NOT NULL,
+	type TEXT NOT NULL,
+	card_token TEXT NOT NULL,
+	last4 TEXT NOT NULL,
+	brand TEXT NOT NULL
+);
+
+CREATE TABLE plans (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	interval_months INTEGER NOT NULL,
+	trial_days INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE subscriptions (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	customer_id BIGINT NOT NULL,
+	payment_method_id BIGINT NOT NULL,
+	setting_id BIGINT NOT NULL,
+	plan_id BIGINT NOT NULL,
+	status TEXT NOT NULL,
+	current_period_start TIMESTAMPTZ NOT NULL,
+	current_period_end TIMESTAMPTZ NOT NULL,
+	cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+	adjustment BIGINT NOT NULL DEFAULT 0,
+	failed_attempts INTEGER NOT NULL DEFAULT 0,
+	next_retry_at TIMESTAMPTZ NOT NULL,
+	version BIGINT NOT NULL DEFAULT 1
+);
+
+CREATE TABLE billing_cycles (
+	id BIGSERIAL PRIMARY KEY,
+	subscription_id BIGINT NOT NULL,
+	period_start TIMESTAMPTZ NOT NULL,
+	attempt INTEGER NOT NULL,
+	amount BIGINT NOT NULL,
+	status TEXT NOT NULL,
+	transaction_id BIGINT NOT NULL DEFAULT 0,
+	UNIQUE (subscription_id, period_start, attempt)
+);
+
+CREATE TABLE invoices (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	setting_id BIGINT NOT NULL,
+	customer_id BIGINT NOT NULL DEFAULT 0,
+	status TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	tax_rate BIGINT NOT NULL DEFAULT 0,
+	subtotal BIGINT NOT NULL,
+	tax BIGINT NOT NULL,
+	total BIGINT NOT NULL,
+	due_date TIMESTAMPTZ NOT NULL,
+	link_token TEXT,
+	transaction_id BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX invoices_link_token ON invoices (link_token);
+
+CREATE TABLE invoice_line_items (
+	id BIGSERIAL PRIMARY KEY,
+	invoice_id BIGINT NOT NULL,
+	description TEXT NOT NULL,
+	quantity BIGINT NOT NULL,
+	unit_price BIGINT NOT NULL,
+	amount BIGINT NOT NULL
+);
+
+CREATE TABLE invoice_reminders (
+	id BIGSERIAL PRIMARY KEY,
+	invoice_id BIGINT NOT NULL,
+	remind_at TIMESTAMPTZ NOT NULL,
+	sent_at TIMESTAMPTZ
+);
+
+CREATE TABLE checkout_sessions (
+	id TEXT PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	success_url TEXT NOT NULL,
+	cancel_url TEXT NOT NULL,
+	status TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	transaction_id BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE virtual_accounts (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	setting_id BIGINT NOT NULL,
+	customer_id BIGINT NOT NULL DEFAULT 0,
+	transaction_id BIGINT NOT NULL DEFAULT 0,
+	bank TEXT NOT NULL,
+	number TEXT,
+	mode TEXT NOT NULL,
+	amount BIGINT NOT NULL DEFAULT 0,
+	status TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	standing BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX virtual_accounts_number ON virtual_accounts (bank, number);
+
+CREATE TABLE virtual_account_payments (
+	id BIGSERIAL PRIMARY KEY,
+	virtual_account_id BIGINT NOT NULL,
+	transaction_id BIGINT NOT NULL DEFAULT 0,
+	reference TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	overpaid BIGINT NOT NULL DEFAULT 0,
+	paid_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (virtual_account_id, reference)
+);
+
+CREATE TABLE ewallet_payments (
+	id BIGSERIAL PRIMARY KEY,
+	transaction_id BIGINT NOT NULL UNIQUE,
+	provider TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	checkout_url TEXT NOT NULL,
+	deeplink TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX ewallet_payments_pending ON ewallet_payments (status, created_at);
+
+CREATE TABLE installment_plans (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	setting_id BIGINT NOT NULL,
+	tenor INTEGER NOT NULL,
+	customer_rate BIGINT NOT NULL DEFAULT 0,
+	merchant_rate BIGINT NOT NULL DEFAULT 0,
+	min_amount BIGINT NOT NULL DEFAULT 0,
+	bins TEXT[] NOT NULL DEFAULT '{}',
+	UNIQUE (setting_id, tenor)
+);
+
+CREATE TABLE promotions (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	code TEXT NOT NULL,
+	type TEXT NOT NULL,
+	value BIGINT NOT NULL,
+	max_discount BIGINT NOT NULL DEFAULT 0,
+	min_amount BIGINT NOT NULL DEFAULT 0,
+	parent_wide BOOLEAN NOT NULL DEFAULT FALSE,
+	bins TEXT[] NOT NULL DEFAULT '{}',
+	total_limit BIGINT NOT NULL DEFAULT 0,
+	customer_limit BIGINT NOT NULL DEFAULT 0,
+	redeemed BIGINT NOT NULL DEFAULT 0,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (merchant_id, code)
+);
+
+CREATE TABLE promotion_usages (
+	promotion_id BIGINT NOT NULL,
+	customer_id BIGINT NOT NULL,
+	used BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (promotion_id, customer_id)
+);
+
+CREATE TABLE fx_rates (
+	currency TEXT PRIMARY KEY,
+	rate TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE fx_quotes (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	settlement_currency TEXT NOT NULL,
+	rate TEXT NOT NULL,
+	markup BIGINT NOT NULL,
+	settlement_amount BIGINT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE disputes (
+	id BIGSERIAL PRIMARY KEY,
+	transaction_id BIGINT NOT NULL,
+	merchant_id BIGINT NOT NULL,
+	reason_code TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	status TEXT NOT NULL,
+	respond_by TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX disputes_transaction ON disputes (transaction_id) WHERE status <> 'won';
+
+CREATE TABLE dispute_evidence (
+	id BIGSERIAL PRIMARY KEY,
+	dispute_id BIGINT NOT NULL,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size BIGINT NOT NULL,
+	storage_key TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE ledger_entries (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	transaction_id BIGINT NOT NULL,
+	type TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (type, reference)
+);
+
+CREATE TABLE screenings (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	result TEXT NOT NULL,
+	score INTEGER NOT NULL,
+	list_version TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX screenings_merchant ON screenings (merchant_id, id);
+
+CREATE TABLE screening_hits (
+	screening_id BIGINT NOT NULL,
+	list TEXT NOT NULL,
+	entry_id TEXT NOT NULL,
+	entry_name TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	score INTEGER NOT NULL
+);
+
+CREATE INDEX screening_hits_screening ON screening_hits (screening_id);
+
+CREATE TABLE qr_payments (
+	id BIGSERIAL PRIMARY KEY,
+	merchant_id BIGINT NOT NULL,
+	transaction_id BIGINT NOT NULL DEFAULT 0,
+	issuer_reference TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	paid_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (merchant_id, issuer_reference)
+);
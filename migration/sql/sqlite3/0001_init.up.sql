@@ -0,0 +1,430 @@
+CREATE TABLE "merchant_groups" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"parent_merchant_id" INTEGER NOT NULL,
+	"child_merchant_id" INTEGER NOT NULL,
+	share_customers INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "transactions" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"parent_merchant_id" INTEGER,
+	"setting_id" INTEGER NOT NULL,
+	"status" INTEGER NOT NULL,
+	amount INTEGER NOT NULL DEFAULT 0,
+	currency TEXT NOT NULL DEFAULT 'IDR',
+	payment_type TEXT NOT NULL DEFAULT 'CARD',
+	state TEXT NOT NULL DEFAULT 'pending',
+	processor TEXT NOT NULL DEFAULT '',
+	processor_reference TEXT NOT NULL DEFAULT '',
+	response_code TEXT NOT NULL DEFAULT '',
+	response_message TEXT NOT NULL DEFAULT '',
+	routing_decision TEXT NOT NULL DEFAULT '',
+	card_token TEXT NOT NULL DEFAULT '',
+	card_last4 TEXT NOT NULL DEFAULT '',
+	card_brand TEXT NOT NULL DEFAULT '',
+	customer_id INTEGER NOT NULL DEFAULT 0,
+	payment_method_id INTEGER NOT NULL DEFAULT 0,
+	payment_code TEXT NOT NULL DEFAULT '',
+	installment_plan_id INTEGER NOT NULL DEFAULT 0,
+	installment_tenor INTEGER NOT NULL DEFAULT 0,
+	fee INTEGER NOT NULL DEFAULT 0,
+	settlement_amount INTEGER NOT NULL DEFAULT 0,
+	promo_code TEXT NOT NULL DEFAULT '',
+	promotion_id INTEGER NOT NULL DEFAULT 0,
+	original_amount INTEGER NOT NULL DEFAULT 0,
+	discount INTEGER NOT NULL DEFAULT 0,
+	settlement_currency TEXT NOT NULL DEFAULT 'IDR',
+	fx_quote_id INTEGER NOT NULL DEFAULT 0,
+	fx_rate TEXT NOT NULL DEFAULT '',
+	fx_markup INTEGER NOT NULL DEFAULT 0,
+	ip_address TEXT NOT NULL DEFAULT '',
+	ip_country TEXT NOT NULL DEFAULT '',
+	billing_country TEXT NOT NULL DEFAULT '',
+	risk_decision TEXT NOT NULL DEFAULT '',
+	risk_rules TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00',
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "settings" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"color" TEXT,
+	"payment_type" TEXT,
+	"payment_name" TEXT,
+	"merchant_id" INTEGER NOT NULL,
+	qr_acquirer TEXT,
+	qr_merchant_pan TEXT,
+	qr_nmid TEXT,
+	qr_criteria TEXT,
+	qr_mcc TEXT,
+	qr_city TEXT,
+	qr_postal_code TEXT,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "cards" (
+	"token" TEXT NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"bin" TEXT NOT NULL,
+	"last4" TEXT NOT NULL,
+	"brand" TEXT NOT NULL,
+	"expiry_month" INTEGER NOT NULL,
+	"expiry_year" INTEGER NOT NULL,
+	"encrypted_key" BLOB NOT NULL,
+	"ciphertext" BLOB NOT NULL,
+	PRIMARY KEY("token")
+);
+
+CREATE TABLE "customers" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"name" TEXT NOT NULL,
+	"email" TEXT NOT NULL DEFAULT '',
+	"phone" TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "payment_methods" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"customer_id" INTEGER NOT NULL,
+	"merchant_id" INTEGER NOT NULL,
+	"type" TEXT NOT NULL,
+	"card_token" TEXT NOT NULL,
+	"last4" TEXT NOT NULL,
+	"brand" TEXT NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "plans" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"name" TEXT NOT NULL,
+	"amount" INTEGER NOT NULL,
+	"currency" TEXT NOT NULL,
+	"interval_months" INTEGER NOT NULL,
+	"trial_days" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "subscriptions" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"customer_id" INTEGER NOT NULL,
+	"payment_method_id" INTEGER NOT NULL,
+	"setting_id" INTEGER NOT NULL,
+	"plan_id" INTEGER NOT NULL,
+	"status" TEXT NOT NULL,
+	"current_period_start" DATETIME NOT NULL,
+	"current_period_end" DATETIME NOT NULL,
+	"cancel_at_period_end" INTEGER NOT NULL DEFAULT 0,
+	"adjustment" INTEGER NOT NULL DEFAULT 0,
+	"failed_attempts" INTEGER NOT NULL DEFAULT 0,
+	"next_retry_at" DATETIME,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "billing_cycles" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"subscription_id" INTEGER NOT NULL,
+	"period_start" DATETIME NOT NULL,
+	"attempt" INTEGER NOT NULL,
+	"amount" INTEGER NOT NULL,
+	"status" TEXT NOT NULL,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id" AUTOINCREMENT),
+	UNIQUE("subscription_id", "period_start", "attempt")
+);
+
+CREATE TABLE "invoices" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"setting_id" INTEGER NOT NULL,
+	"customer_id" INTEGER NOT NULL DEFAULT 0,
+	"status" TEXT NOT NULL,
+	"currency" TEXT NOT NULL,
+	"tax_rate" INTEGER NOT NULL DEFAULT 0,
+	"subtotal" INTEGER NOT NULL,
+	"tax" INTEGER NOT NULL,
+	"total" INTEGER NOT NULL,
+	"due_date" DATETIME NOT NULL,
+	"link_token" TEXT,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE UNIQUE INDEX "invoices_link_token" ON "invoices" ("link_token");
+
+CREATE TABLE "invoice_line_items" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"invoice_id" INTEGER NOT NULL,
+	"description" TEXT NOT NULL,
+	"quantity" INTEGER NOT NULL,
+	"unit_price" INTEGER NOT NULL,
+	"amount" INTEGER NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "invoice_reminders" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"invoice_id" INTEGER NOT NULL,
+	"remind_at" DATETIME NOT NULL,
+	"sent_at" DATETIME,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "checkout_sessions" (
+	"id" TEXT NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"amount" INTEGER NOT NULL,
+	"currency" TEXT NOT NULL,
+	"success_url" TEXT NOT NULL,
+	"cancel_url" TEXT NOT NULL,
+	"status" TEXT NOT NULL,
+	"expires_at" DATETIME NOT NULL,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id")
+);
+
+CREATE UNIQUE INDEX settings_qr_nmid ON settings (qr_nmid);
+
+CREATE TABLE "virtual_accounts" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"setting_id" INTEGER NOT NULL,
+	"customer_id" INTEGER NOT NULL DEFAULT 0,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	"bank" TEXT NOT NULL,
+	"number" TEXT,
+	"mode" TEXT NOT NULL,
+	"amount" INTEGER NOT NULL DEFAULT 0,
+	"status" TEXT NOT NULL,
+	"expires_at" DATETIME NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE UNIQUE INDEX "virtual_accounts_number" ON "virtual_accounts" ("bank", "number");
+
+CREATE TABLE "virtual_account_payments" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"virtual_account_id" INTEGER NOT NULL,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	"reference" TEXT NOT NULL,
+	"amount" INTEGER NOT NULL,
+	"overpaid" INTEGER NOT NULL DEFAULT 0,
+	"paid_at" DATETIME NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT),
+	UNIQUE("virtual_account_id", "reference")
+);
+
+CREATE TABLE "ewallet_payments" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"transaction_id" INTEGER NOT NULL UNIQUE,
+	"provider" TEXT NOT NULL,
+	"reference" TEXT NOT NULL,
+	"checkout_url" TEXT NOT NULL,
+	"deeplink" TEXT NOT NULL DEFAULT '',
+	"status" TEXT NOT NULL,
+	"created_at" DATETIME NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE INDEX "ewallet_payments_pending" ON "ewallet_payments" ("status", "created_at");
+
+CREATE TABLE "installment_plans" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"setting_id" INTEGER NOT NULL,
+	"tenor" INTEGER NOT NULL,
+	"customer_rate" INTEGER NOT NULL DEFAULT 0,
+	"merchant_rate" INTEGER NOT NULL DEFAULT 0,
+	"min_amount" INTEGER NOT NULL DEFAULT 0,
+	"bins" TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY("id" AUTOINCREMENT),
+	UNIQUE("setting_id", "tenor")
+);
+
+CREATE TABLE "promotions" (
+	id INTEGER NOT NULL UNIQUE,
+	merchant_id INTEGER NOT NULL,
+	code TEXT NOT NULL,
+	type TEXT NOT NULL,
+	value INTEGER NOT NULL,
+	max_discount INTEGER NOT NULL DEFAULT 0,
+	min_amount INTEGER NOT NULL DEFAULT 0,
+	parent_wide INTEGER NOT NULL DEFAULT 0,
+	bins TEXT NOT NULL DEFAULT '',
+	total_limit INTEGER NOT NULL DEFAULT 0,
+	customer_limit INTEGER NOT NULL DEFAULT 0,
+	redeemed INTEGER NOT NULL DEFAULT 0,
+	starts_at DATETIME NOT NULL,
+	ends_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT),
+	UNIQUE(merchant_id, code)
+);
+
+CREATE TABLE "promotion_usages" (
+	promotion_id INTEGER NOT NULL,
+	customer_id INTEGER NOT NULL,
+	used INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(promotion_id, customer_id)
+);
+
+CREATE TABLE "fx_rates" (
+	currency TEXT NOT NULL UNIQUE,
+	rate TEXT NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY(currency)
+);
+
+CREATE TABLE "fx_quotes" (
+	id INTEGER NOT NULL UNIQUE,
+	merchant_id INTEGER NOT NULL,
+	currency TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	settlement_currency TEXT NOT NULL,
+	rate TEXT NOT NULL,
+	markup INTEGER NOT NULL,
+	settlement_amount INTEGER NOT NULL,
+	expires_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT)
+);
+
+CREATE TABLE "disputes" (
+	id INTEGER NOT NULL UNIQUE,
+	transaction_id INTEGER NOT NULL,
+	merchant_id INTEGER NOT NULL,
+	reason_code TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	currency TEXT NOT NULL,
+	status TEXT NOT NULL,
+	respond_by DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT)
+);
+
+CREATE TABLE "dispute_evidence" (
+	id INTEGER NOT NULL UNIQUE,
+	dispute_id INTEGER NOT NULL,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	storage_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT)
+);
+
+CREATE TABLE "ledger_entries" (
+	id INTEGER NOT NULL UNIQUE,
+	merchant_id INTEGER NOT NULL,
+	transaction_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	currency TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT),
+	UNIQUE(type, reference)
+);
+
+CREATE INDEX transactions_merchant_state ON transactions (merchant_id, state);
+
+CREATE TABLE "risk_rules" (
+	id INTEGER NOT NULL UNIQUE,
+	merchant_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	action TEXT NOT NULL,
+	field TEXT NOT NULL DEFAULT '',
+	threshold INTEGER NOT NULL DEFAULT 0,
+	window_seconds INTEGER NOT NULL DEFAULT 0,
+	entries TEXT NOT NULL DEFAULT '',
+	enabled INTEGER NOT NULL DEFAULT 1,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY(id AUTOINCREMENT)
+);
+
+CREATE INDEX risk_rules_merchant ON risk_rules (merchant_id);
+
+CREATE INDEX transactions_merchant_created ON transactions (merchant_id, created_at);
+
+CREATE TABLE "limit_usages" (
+	merchant_id INTEGER NOT NULL,
+	payment_type TEXT NOT NULL,
+	period TEXT NOT NULL,
+	volume INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY(merchant_id, payment_type, period)
+);
+
+CREATE TABLE "limits" (
+	merchant_id INTEGER NOT NULL,
+	payment_type TEXT NOT NULL DEFAULT '',
+	max_amount INTEGER NOT NULL DEFAULT 0,
+	daily_volume INTEGER NOT NULL DEFAULT 0,
+	monthly_volume INTEGER NOT NULL DEFAULT 0,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY(merchant_id, payment_type)
+);
+
+CREATE TABLE "screenings" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	merchant_id INTEGER NOT NULL,
+	result TEXT NOT NULL,
+	score INTEGER NOT NULL,
+	list_version TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX screenings_merchant ON screenings(merchant_id, id);
+
+CREATE TABLE "screening_hits" (
+	screening_id INTEGER NOT NULL,
+	list TEXT NOT NULL,
+	entry_id TEXT NOT NULL,
+	entry_name TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	score INTEGER NOT NULL
+);
+
+CREATE INDEX screening_hits_screening ON screening_hits(screening_id);
+
+CREATE TABLE "merchants" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"name" TEXT,
+	"legal_name" TEXT NOT NULL DEFAULT '',
+	"entity_type" TEXT NOT NULL DEFAULT '',
+	"registration_number" TEXT NOT NULL DEFAULT '',
+	"tax_id" TEXT NOT NULL DEFAULT '',
+	"address" TEXT NOT NULL DEFAULT '',
+	"bank_code" TEXT NOT NULL DEFAULT '',
+	"bank_account_number" TEXT NOT NULL DEFAULT '',
+	"bank_account_name" TEXT NOT NULL DEFAULT '',
+	"status" TEXT NOT NULL DEFAULT 'draft',
+	"review_notes" TEXT NOT NULL DEFAULT '',
+	"screening_result" TEXT NOT NULL DEFAULT '',
+	"screening_score" INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY("id" AUTOINCREMENT)
+);
+
+CREATE TABLE "merchant_owners" (
+	merchant_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	id_number TEXT NOT NULL,
+	share INTEGER NOT NULL,
+	PRIMARY KEY (merchant_id, position)
+);
+
+CREATE TABLE "merchant_documents" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	merchant_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	file_name TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	storage_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX merchant_documents_merchant ON merchant_documents(merchant_id);
@@ -0,0 +1,14 @@
+ALTER TABLE transactions ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
+
+CREATE TABLE "transaction_changes" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	transaction_id INTEGER NOT NULL,
+	version INTEGER NOT NULL,
+	field TEXT NOT NULL,
+	before TEXT NOT NULL,
+	after TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX transaction_changes_transaction ON transaction_changes(transaction_id);
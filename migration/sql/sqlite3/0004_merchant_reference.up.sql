@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN merchant_reference TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX transactions_merchant_reference ON transactions(merchant_id, merchant_reference) WHERE merchant_reference <> '';
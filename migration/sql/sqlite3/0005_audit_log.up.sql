@@ -0,0 +1,28 @@
+CREATE TABLE "audit_log" (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	actor TEXT NOT NULL,
+	merchant_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	entity TEXT NOT NULL,
+	entity_id INTEGER NOT NULL,
+	before TEXT NOT NULL,
+	after TEXT NOT NULL,
+	request_id TEXT NOT NULL,
+	source_ip TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	prev_hash TEXT NOT NULL UNIQUE,
+	hash TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX audit_log_entity ON audit_log(entity, entity_id, created_at);
+CREATE INDEX audit_log_created_at ON audit_log(created_at);
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+	SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
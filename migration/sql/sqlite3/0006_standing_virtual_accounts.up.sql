@@ -0,0 +1,5 @@
+ALTER TABLE virtual_accounts ADD COLUMN standing INTEGER NOT NULL DEFAULT 0;
+
+-- Accounts issued for a transaction carry it from the start; the rest were
+-- issued for a customer.
+UPDATE virtual_accounts SET standing=1 WHERE transaction_id=0;
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN card_fingerprint;
+ALTER TABLE cards DROP COLUMN fingerprint;
@@ -0,0 +1,2 @@
+ALTER TABLE cards ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN card_fingerprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE audit_log ADD COLUMN claimed_actor TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE subscriptions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1 @@
+DROP INDEX disputes_transaction;
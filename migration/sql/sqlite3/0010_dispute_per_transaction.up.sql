@@ -0,0 +1 @@
+CREATE UNIQUE INDEX disputes_transaction ON disputes(transaction_id) WHERE status <> 'won';
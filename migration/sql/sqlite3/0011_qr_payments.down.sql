@@ -0,0 +1 @@
+DROP TABLE "qr_payments";
@@ -0,0 +1,10 @@
+CREATE TABLE "qr_payments" (
+	"id" INTEGER NOT NULL UNIQUE,
+	"merchant_id" INTEGER NOT NULL,
+	"transaction_id" INTEGER NOT NULL DEFAULT 0,
+	"issuer_reference" TEXT NOT NULL,
+	"amount" INTEGER NOT NULL,
+	"paid_at" DATETIME NOT NULL,
+	PRIMARY KEY("id" AUTOINCREMENT),
+	UNIQUE("merchant_id", "issuer_reference")
+);
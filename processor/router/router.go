@@ -0,0 +1,171 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Rule sends matching transactions to Processors, tried in order. Empty or
+// zero fields match anything.
+type Rule struct {
+	Name        string   `mapstructure:"name"`
+	PaymentType string   `mapstructure:"paymentType"`
+	Currency    string   `mapstructure:"currency"`
+	MinAmount   int64    `mapstructure:"minAmount"`
+	MaxAmount   int64    `mapstructure:"maxAmount"`
+	MerchantID  int64    `mapstructure:"merchantId"`
+	Processors  []string `mapstructure:"processors"`
+}
+
+func (r Rule) matches(req *domain.PaymentRequest) bool {
+	if r.PaymentType != "" && r.PaymentType != req.PaymentType {
+		return false
+	}
+	if r.Currency != "" && r.Currency != req.Currency {
+		return false
+	}
+	if r.MinAmount != 0 && req.Amount < r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != 0 && req.Amount > r.MaxAmount {
+		return false
+	}
+	if r.MerchantID != 0 && r.MerchantID != req.MerchantID {
+		return false
+	}
+
+	return true
+}
+
+type Config struct {
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// Soft declines and timeouts are worth retrying on another processor; any
+// other decline is final.
+var retryable = map[string]bool{
+	domain.ResponseDeclined: true,
+	domain.ResponseTimeout:  true,
+}
+
+type router struct {
+	processors map[string]domain.PaymentProcessor
+	order      []string
+	breakers   map[string]*breaker
+	rules      []Rule
+}
+
+// NewRouter returns a processor that picks one of ps for every authorization.
+// Transactions matching no rule are tried against ps in the given order.
+func NewRouter(ps []domain.PaymentProcessor, rules []Rule, cfg Config) domain.PaymentProcessor {
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = 5
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+
+	r := &router{
+		processors: map[string]domain.PaymentProcessor{},
+		breakers:   map[string]*breaker{},
+		rules:      rules,
+	}
+	for _, p := range ps {
+		r.processors[p.Name()] = p
+		r.breakers[p.Name()] = newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown)
+		r.order = append(r.order, p.Name())
+	}
+
+	return r
+}
+
+func (r *router) Name() string {
+	return "router"
+}
+
+func (r *router) Authorize(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	ruleName, candidates := r.route(req)
+	attempts := []string{}
+
+	res := domain.ProcessorResponse{Code: domain.ResponseTimeout, Message: "No processor available"}
+	err := domain.ErrProcessorTimeout
+	for _, name := range candidates {
+		p, ok := r.processors[name]
+		if !ok {
+			continue
+		}
+		b := r.breakers[name]
+		if !b.Allow() {
+			attempts = append(attempts, name+":circuit_open")
+			continue
+		}
+
+		res, err = p.Authorize(ctx, req)
+		attempts = append(attempts, name+":"+res.Code)
+		if err != nil && err != domain.ErrProcessorTimeout {
+			b.Failure()
+			break
+		}
+		if err == domain.ErrProcessorTimeout {
+			b.Failure()
+		} else {
+			b.Success()
+		}
+		if res.Approved || !retryable[res.Code] {
+			break
+		}
+	}
+
+	res.Route = fmt.Sprintf("rule=%s attempts=%s", ruleName, strings.Join(attempts, ","))
+	return res, err
+}
+
+func (r *router) Capture(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	p, err := r.owner(req)
+	if err != nil {
+		return domain.ProcessorResponse{}, err
+	}
+	return p.Capture(ctx, req)
+}
+
+func (r *router) Refund(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	p, err := r.owner(req)
+	if err != nil {
+		return domain.ProcessorResponse{}, err
+	}
+	return p.Refund(ctx, req)
+}
+
+func (r *router) Void(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	p, err := r.owner(req)
+	if err != nil {
+		return domain.ProcessorResponse{}, err
+	}
+	return p.Void(ctx, req)
+}
+
+func (r *router) route(req *domain.PaymentRequest) (string, []string) {
+	for _, rule := range r.rules {
+		if rule.matches(req) {
+			return rule.Name, rule.Processors
+		}
+	}
+
+	return "default", r.order
+}
+
+// owner finds the processor that authorized the transaction; follow-up
+// operations never fail over since no other processor knows the reference.
+func (r *router) owner(req *domain.PaymentRequest) (domain.PaymentProcessor, error) {
+	p, ok := r.processors[req.Processor]
+	if !ok {
+		return nil, fmt.Errorf("unknown processor %q", req.Processor)
+	}
+
+	return p, nil
+}
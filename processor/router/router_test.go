@@ -0,0 +1,143 @@
+package router_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/processor/router"
+)
+
+func newProcessor(name string) *mocks.PaymentProcessor {
+	p := new(mocks.PaymentProcessor)
+	p.On("Name").Return(name)
+	return p
+}
+
+func response(name string, code string) domain.ProcessorResponse {
+	return domain.ProcessorResponse{
+		Processor: name,
+		Reference: name + "-1",
+		Code:      code,
+		Approved:  code == domain.ResponseApproved,
+	}
+}
+
+func TestAuthorizeUsesMatchingRule(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	b.On("Authorize", mock.Anything, mock.Anything).Return(response("b", domain.ResponseApproved), nil).Once()
+	rules := []router.Rule{
+		{Name: "small-usd", Currency: "USD", MaxAmount: 1000, Processors: []string{"a"}},
+		{Name: "idr-cards", Currency: "IDR", PaymentType: "CARD", Processors: []string{"b", "a"}},
+	}
+	r := router.NewRouter([]domain.PaymentProcessor{a, b}, rules, router.Config{})
+
+	res, err := r.Authorize(context.TODO(), &domain.PaymentRequest{Amount: 5000, Currency: "IDR", PaymentType: "CARD"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "b", res.Processor)
+	assert.Equal(t, "rule=idr-cards attempts=b:00", res.Route)
+	a.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeMatchesMerchant(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	b.On("Authorize", mock.Anything, mock.Anything).Return(response("b", domain.ResponseApproved), nil).Once()
+	rules := []router.Rule{
+		{Name: "merchant-7", MerchantID: 7, Processors: []string{"b"}},
+	}
+	r := router.NewRouter([]domain.PaymentProcessor{a, b}, rules, router.Config{})
+
+	res, err := r.Authorize(context.TODO(), &domain.PaymentRequest{MerchantID: 7, Amount: 5000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "b", res.Processor)
+}
+
+func TestAuthorizeFailsOverOnSoftDeclineAndTimeout(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	c := newProcessor("c")
+	a.On("Authorize", mock.Anything, mock.Anything).Return(response("a", domain.ResponseTimeout), domain.ErrProcessorTimeout).Once()
+	b.On("Authorize", mock.Anything, mock.Anything).Return(response("b", domain.ResponseDeclined), nil).Once()
+	c.On("Authorize", mock.Anything, mock.Anything).Return(response("c", domain.ResponseApproved), nil).Once()
+	r := router.NewRouter([]domain.PaymentProcessor{a, b, c}, nil, router.Config{})
+
+	res, err := r.Authorize(context.TODO(), &domain.PaymentRequest{Amount: 5000})
+
+	assert.NoError(t, err)
+	assert.True(t, res.Approved)
+	assert.Equal(t, "rule=default attempts=a:68,b:05,c:00", res.Route)
+}
+
+func TestAuthorizeStopsOnHardDecline(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	a.On("Authorize", mock.Anything, mock.Anything).Return(response("a", domain.ResponseInsufficientFunds), nil).Once()
+	r := router.NewRouter([]domain.PaymentProcessor{a, b}, nil, router.Config{})
+
+	res, err := r.Authorize(context.TODO(), &domain.PaymentRequest{Amount: 5000})
+
+	assert.NoError(t, err)
+	assert.False(t, res.Approved)
+	assert.Equal(t, domain.ResponseInsufficientFunds, res.Code)
+	b.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestAuthorizeAllTimedOut(t *testing.T) {
+	a := newProcessor("a")
+	a.On("Authorize", mock.Anything, mock.Anything).Return(response("a", domain.ResponseTimeout), domain.ErrProcessorTimeout).Once()
+	r := router.NewRouter([]domain.PaymentProcessor{a}, nil, router.Config{})
+
+	res, err := r.Authorize(context.TODO(), &domain.PaymentRequest{Amount: 5000})
+
+	assert.Equal(t, domain.ErrProcessorTimeout, err)
+	assert.Equal(t, "rule=default attempts=a:68", res.Route)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	a.On("Authorize", mock.Anything, mock.Anything).Return(response("a", domain.ResponseTimeout), domain.ErrProcessorTimeout).Twice()
+	b.On("Authorize", mock.Anything, mock.Anything).Return(response("b", domain.ResponseApproved), nil)
+	r := router.NewRouter([]domain.PaymentProcessor{a, b}, nil, router.Config{BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond})
+	req := &domain.PaymentRequest{Amount: 5000}
+
+	r.Authorize(context.TODO(), req)
+	r.Authorize(context.TODO(), req)
+	res, err := r.Authorize(context.TODO(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "rule=default attempts=a:circuit_open,b:00", res.Route)
+	a.AssertNumberOfCalls(t, "Authorize", 2)
+
+	time.Sleep(30 * time.Millisecond)
+	a.On("Authorize", mock.Anything, mock.Anything).Return(response("a", domain.ResponseApproved), nil).Once()
+	res, err = r.Authorize(context.TODO(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "rule=default attempts=a:00", res.Route)
+}
+
+func TestFollowUpGoesToAuthorizingProcessor(t *testing.T) {
+	a := newProcessor("a")
+	b := newProcessor("b")
+	b.On("Capture", mock.Anything, mock.Anything).Return(response("b", domain.ResponseApproved), nil).Once()
+	r := router.NewRouter([]domain.PaymentProcessor{a, b}, nil, router.Config{})
+
+	res, err := r.Capture(context.TODO(), &domain.PaymentRequest{Processor: "b", Reference: "b-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "b", res.Processor)
+	a.AssertNotCalled(t, "Capture", mock.Anything, mock.Anything)
+
+	_, err = r.Refund(context.TODO(), &domain.PaymentRequest{Processor: "unknown"})
+	assert.Error(t, err)
+}
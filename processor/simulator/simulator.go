@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Magic card numbers understood by the simulator. Any other card is approved
+// unless the amount says otherwise.
+const (
+	CardApproved          = "4111111111111111"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+	CardTimeout           = "4000000000000119"
+)
+
+var cardResponses = map[string]string{
+	CardApproved:          domain.ResponseApproved,
+	CardDeclined:          domain.ResponseDeclined,
+	CardInsufficientFunds: domain.ResponseInsufficientFunds,
+	CardTimeout:           domain.ResponseTimeout,
+}
+
+// Amounts whose last two digits match a response code trigger that response,
+// e.g. 10005 is declined and 20051 fails with insufficient funds.
+var amountResponses = map[int64]string{
+	5:  domain.ResponseDeclined,
+	51: domain.ResponseInsufficientFunds,
+	68: domain.ResponseTimeout,
+}
+
+var messages = map[string]string{
+	domain.ResponseApproved:          "Approved",
+	domain.ResponseDeclined:          "Do not honor",
+	domain.ResponseInvalidCard:       "Invalid card number",
+	domain.ResponseInsufficientFunds: "Insufficient funds",
+	domain.ResponseTimeout:           "Response received too late",
+}
+
+type simulator struct {
+	name  string
+	cards domain.CardDetokenizer
+}
+
+// NewSimulator returns a deterministic acquirer that never leaves the process.
+func NewSimulator(name string, d domain.CardDetokenizer) domain.PaymentProcessor {
+	return &simulator{
+		name:  name,
+		cards: d,
+	}
+}
+
+func (s *simulator) Name() string {
+	return s.name
+}
+
+func (s *simulator) Authorize(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	card, err := s.cards.Detokenize(ctx, req.CardToken)
+	if err == domain.ErrNotFound {
+		return s.respond(domain.ResponseInvalidCard, "")
+	}
+	if err != nil {
+		return domain.ProcessorResponse{}, err
+	}
+
+	code := domain.ResponseApproved
+	if c, ok := cardResponses[card.Number]; ok {
+		code = c
+	}
+	if c, ok := amountResponses[req.Amount%100]; ok && code == domain.ResponseApproved {
+		code = c
+	}
+
+	return s.respond(code, fmt.Sprintf("%s-%d", s.name, req.TransactionID))
+}
+
+func (s *simulator) Capture(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	return s.followUp(req)
+}
+
+func (s *simulator) Refund(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	return s.followUp(req)
+}
+
+func (s *simulator) Void(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	return s.followUp(req)
+}
+
+func (s *simulator) followUp(req *domain.PaymentRequest) (domain.ProcessorResponse, error) {
+	if req.Reference == "" {
+		return s.respond(domain.ResponseInvalidCard, "")
+	}
+
+	return s.respond(domain.ResponseApproved, req.Reference)
+}
+
+func (s *simulator) respond(code string, reference string) (domain.ProcessorResponse, error) {
+	res := domain.ProcessorResponse{
+		Processor: s.name,
+		Reference: reference,
+		Code:      code,
+		Message:   messages[code],
+		Approved:  code == domain.ResponseApproved,
+	}
+	if code == domain.ResponseTimeout {
+		return res, domain.ErrProcessorTimeout
+	}
+
+	return res, nil
+}
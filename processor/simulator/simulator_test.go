@@ -0,0 +1,90 @@
+package simulator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/processor/simulator"
+)
+
+// cards is a detokenizer whose tokens are the card numbers themselves.
+type cards struct{}
+
+func (cards) Detokenize(ctx context.Context, token string) (domain.Card, error) {
+	if token == "" {
+		return domain.Card{}, domain.ErrNotFound
+	}
+	return domain.Card{Token: token, Number: token}, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	cases := []struct {
+		card     string
+		amount   int64
+		code     string
+		approved bool
+		err      error
+	}{
+		{simulator.CardApproved, 10000, domain.ResponseApproved, true, nil},
+		{"5555555555554444", 10000, domain.ResponseApproved, true, nil},
+		{simulator.CardDeclined, 10000, domain.ResponseDeclined, false, nil},
+		{simulator.CardInsufficientFunds, 10000, domain.ResponseInsufficientFunds, false, nil},
+		{simulator.CardTimeout, 10000, domain.ResponseTimeout, false, domain.ErrProcessorTimeout},
+		{simulator.CardApproved, 10005, domain.ResponseDeclined, false, nil},
+		{simulator.CardApproved, 20051, domain.ResponseInsufficientFunds, false, nil},
+		{simulator.CardApproved, 30068, domain.ResponseTimeout, false, domain.ErrProcessorTimeout},
+		{"", 10000, domain.ResponseInvalidCard, false, nil},
+	}
+
+	p := simulator.NewSimulator("sim", cards{})
+	for _, c := range cases {
+		res, err := p.Authorize(context.TODO(), &domain.PaymentRequest{TransactionID: 7, CardToken: c.card, Amount: c.amount})
+
+		assert.Equal(t, c.err, err, c.card)
+		assert.Equal(t, c.code, res.Code, c.card)
+		assert.Equal(t, c.approved, res.Approved, c.card)
+		assert.Equal(t, "sim", res.Processor)
+	}
+}
+
+func TestAuthorizeIsDeterministic(t *testing.T) {
+	p := simulator.NewSimulator("sim", cards{})
+	req := &domain.PaymentRequest{TransactionID: 7, CardToken: simulator.CardApproved, Amount: 10000}
+
+	first, err := p.Authorize(context.TODO(), req)
+	assert.NoError(t, err)
+	second, err := p.Authorize(context.TODO(), req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, "sim-7", first.Reference)
+}
+
+func TestFollowUp(t *testing.T) {
+	p := simulator.NewSimulator("sim", cards{})
+	req := &domain.PaymentRequest{TransactionID: 7, Reference: "sim-7"}
+
+	res, err := p.Capture(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.True(t, res.Approved)
+
+	res, err = p.Refund(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.True(t, res.Approved)
+
+	res, err = p.Void(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.True(t, res.Approved)
+}
+
+func TestFollowUpWithoutReference(t *testing.T) {
+	p := simulator.NewSimulator("sim", cards{})
+
+	res, err := p.Capture(context.TODO(), &domain.PaymentRequest{TransactionID: 7})
+
+	assert.NoError(t, err)
+	assert.False(t, res.Approved)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type PromotionHandler struct {
+	Usecase domain.PromotionUsecase
+}
+
+func NewPromotionHandler(e *echo.Echo, u domain.PromotionUsecase) *PromotionHandler {
+	handler := &PromotionHandler{
+		Usecase: u,
+	}
+
+	e.POST("/promotions", handler.Store)
+	e.GET("/promotions", handler.Fetch)
+
+	return handler
+}
+
+func (h *PromotionHandler) Store(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Promotion
+	c.Bind(&data)
+	if !valid(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Store(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *PromotionHandler) Fetch(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, merchantID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func valid(p domain.Promotion) bool {
+	if p.MerchantID == 0 || p.Code == "" || p.Value <= 0 {
+		return false
+	}
+	if p.Type != domain.DiscountPercent && p.Type != domain.DiscountFixed {
+		return false
+	}
+	if p.Type == domain.DiscountPercent && p.Value > 10000 {
+		return false
+	}
+	if p.MaxDiscount < 0 || p.MinAmount < 0 || p.TotalLimit < 0 || p.CustomerLimit < 0 {
+		return false
+	}
+
+	return !p.StartsAt.IsZero() && p.EndsAt.After(p.StartsAt)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrPromoCodeTaken:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
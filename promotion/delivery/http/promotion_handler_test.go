@@ -0,0 +1,78 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	promotionHttp "github.com/hezbymuhammad/payment-gateway/promotion/delivery/http"
+)
+
+func TestStore(t *testing.T) {
+	mockUsecase := new(mocks.PromotionUsecase)
+	mockUsecase.On("Store", mock.Anything, mock.AnythingOfType("*domain.Promotion")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Promotion).ID = 3
+	}).Return(nil).Once()
+
+	e := echo.New()
+	body := `{"merchantId":6,"code":"HEMAT","type":"percent","value":1000,"totalLimit":100,"startsAt":"2026-10-01T00:00:00Z","endsAt":"2026-11-01T00:00:00Z"}`
+	req, err := http.NewRequest(echo.POST, "/promotions", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := promotionHttp.NewPromotionHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":3`)
+}
+
+func TestStorePercentOverHundred(t *testing.T) {
+	mockUsecase := new(mocks.PromotionUsecase)
+
+	e := echo.New()
+	body := `{"merchantId":6,"code":"HEMAT","type":"percent","value":12000,"startsAt":"2026-10-01T00:00:00Z","endsAt":"2026-11-01T00:00:00Z"}`
+	req, err := http.NewRequest(echo.POST, "/promotions", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := promotionHttp.NewPromotionHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreEndsBeforeStart(t *testing.T) {
+	mockUsecase := new(mocks.PromotionUsecase)
+
+	e := echo.New()
+	body := `{"merchantId":6,"code":"HEMAT","type":"fixed","value":10000,"startsAt":"2026-11-01T00:00:00Z","endsAt":"2026-10-01T00:00:00Z"}`
+	req, err := http.NewRequest(echo.POST, "/promotions", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := promotionHttp.NewPromotionHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
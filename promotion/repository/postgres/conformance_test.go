@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	promotionRepo "github.com/hezbymuhammad/payment-gateway/promotion/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.PromotionRepository(t, func(t *testing.T) domain.PromotionRepository {
+		return promotionRepo.NewPromotionRepository(migrationtest.NewPostgresDB(t))
+	})
+}
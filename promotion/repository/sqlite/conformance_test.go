@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	promotionRepo "github.com/hezbymuhammad/payment-gateway/promotion/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.PromotionRepository(t, func(t *testing.T) domain.PromotionRepository {
+		return promotionRepo.NewPromotionRepository(migrationtest.NewDB(t))
+	})
+}
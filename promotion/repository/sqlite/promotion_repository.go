@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const promotionColumns = "id, merchant_id, code, type, value, max_discount, min_amount, parent_wide, bins, total_limit, customer_limit, redeemed, starts_at, ends_at"
+
+type sqlitePromotionRepo struct {
+	DB *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) domain.PromotionRepository {
+	return &sqlitePromotionRepo{
+		DB: db,
+	}
+}
+
+func (pr *sqlitePromotionRepo) Store(ctx context.Context, p *domain.Promotion) error {
+	query := "INSERT INTO promotions (merchant_id, code, type, value, max_discount, min_amount, parent_wide, bins, total_limit, customer_limit, starts_at, ends_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := pr.exec(ctx, query, p.MerchantID, p.Code, p.Type, p.Value, p.MaxDiscount, p.MinAmount, p.ParentWide, strings.Join(p.BINs, ","), p.TotalLimit, p.CustomerLimit, p.StartsAt, p.EndsAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	p.ID = lastID
+	return nil
+}
+
+func (pr *sqlitePromotionRepo) GetByCode(ctx context.Context, merchantID int64, code string) (domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE merchant_id=? AND code=? LIMIT 1"
+
+	data, err := scanPromotion(pr.DB.QueryRowContext(ctx, query, merchantID, code))
+	if err == sql.ErrNoRows {
+		return domain.Promotion{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Promotion{}, err
+	}
+
+	return data, nil
+}
+
+func (pr *sqlitePromotionRepo) Fetch(ctx context.Context, merchantID int64) ([]domain.Promotion, error) {
+	query := "SELECT " + promotionColumns + " FROM promotions WHERE merchant_id=? ORDER BY id"
+
+	rows, err := pr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Promotion{}
+	for rows.Next() {
+		data, err := scanPromotion(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// Claim takes one redemption off the promotion's total quota and, when the
+// promotion caps use per customer, off the customer's. Each step is a single
+// conditional statement so concurrent claims cannot overshoot a quota; a
+// claim that fails on the customer cap hands its total back.
+func (pr *sqlitePromotionRepo) Claim(ctx context.Context, p domain.Promotion, customerID int64) (bool, error) {
+	query := "UPDATE promotions SET redeemed = redeemed + 1 WHERE id=? AND (total_limit = 0 OR redeemed < total_limit)"
+
+	ok, err := pr.claim(ctx, query, p.ID)
+	if err != nil || !ok || p.CustomerLimit == 0 {
+		return ok, err
+	}
+
+	query = "INSERT INTO promotion_usages (promotion_id, customer_id, used) VALUES (?, ?, 1) ON CONFLICT(promotion_id, customer_id) DO UPDATE SET used = used + 1 WHERE used < ?"
+
+	ok, err = pr.claim(ctx, query, p.ID, customerID, p.CustomerLimit)
+	if err == nil && ok {
+		return true, nil
+	}
+
+	_, releaseErr := pr.exec(ctx, "UPDATE promotions SET redeemed = redeemed - 1 WHERE id=? AND redeemed > 0", p.ID)
+	if releaseErr != nil {
+		return false, releaseErr
+	}
+
+	return false, err
+}
+
+// Release hands back a redemption whose payment did not go through.
+func (pr *sqlitePromotionRepo) Release(ctx context.Context, promotionID int64, customerID int64) error {
+	_, err := pr.exec(ctx, "UPDATE promotions SET redeemed = redeemed - 1 WHERE id=? AND redeemed > 0", promotionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = pr.exec(ctx, "UPDATE promotion_usages SET used = used - 1 WHERE promotion_id=? AND customer_id=? AND used > 0", promotionID, customerID)
+	return err
+}
+
+func (pr *sqlitePromotionRepo) claim(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	res, err := pr.exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (pr *sqlitePromotionRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := pr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPromotion reads a promotion row. BINs are stored comma separated.
+func scanPromotion(row scanner) (domain.Promotion, error) {
+	data := domain.Promotion{}
+	var bins string
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Code,
+		&data.Type,
+		&data.Value,
+		&data.MaxDiscount,
+		&data.MinAmount,
+		&data.ParentWide,
+		&bins,
+		&data.TotalLimit,
+		&data.CustomerLimit,
+		&data.Redeemed,
+		&data.StartsAt,
+		&data.EndsAt,
+	)
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	data.BINs = []string{}
+	if bins != "" {
+		data.BINs = strings.Split(bins, ",")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,107 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	promotionRepo "github.com/hezbymuhammad/payment-gateway/promotion/repository/sqlite"
+)
+
+var (
+	claimTotal    = regexp.QuoteMeta("UPDATE promotions SET redeemed = redeemed + 1 WHERE id=? AND (total_limit = 0 OR redeemed < total_limit)")
+	claimCustomer = regexp.QuoteMeta("INSERT INTO promotion_usages (promotion_id, customer_id, used) VALUES (?, ?, 1) ON CONFLICT(promotion_id, customer_id) DO UPDATE SET used = used + 1 WHERE used < ?")
+	releaseTotal  = regexp.QuoteMeta("UPDATE promotions SET redeemed = redeemed - 1 WHERE id=? AND redeemed > 0")
+)
+
+func TestGetByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	starts := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "code", "type", "value", "max_discount", "min_amount", "parent_wide", "bins", "total_limit", "customer_limit", "redeemed", "starts_at", "ends_at"}).
+		AddRow(3, 6, "HEMAT", "percent", 1000, 25000, 50000, true, "411111,522222", 100, 1, 7, starts, starts.AddDate(0, 1, 0))
+	query := regexp.QuoteMeta("SELECT id, merchant_id, code, type, value, max_discount, min_amount, parent_wide, bins, total_limit, customer_limit, redeemed, starts_at, ends_at FROM promotions WHERE merchant_id=? AND code=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(6, "HEMAT").WillReturnRows(rows)
+	pr := promotionRepo.NewPromotionRepository(db)
+
+	res, err := pr.GetByCode(context.TODO(), 6, "HEMAT")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.ID)
+	assert.True(t, res.ParentWide)
+	assert.Equal(t, []string{"411111", "522222"}, res.BINs)
+	assert.Equal(t, int64(7), res.Redeemed)
+}
+
+func TestClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare(claimTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(claimCustomer).ExpectExec().WithArgs(3, 9, 2).WillReturnResult(sqlmock.NewResult(1, 1))
+	pr := promotionRepo.NewPromotionRepository(db)
+
+	ok, err := pr.Claim(context.TODO(), domain.Promotion{ID: 3, CustomerLimit: 2}, 9)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimTotalExhausted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare(claimTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 0))
+	pr := promotionRepo.NewPromotionRepository(db)
+
+	ok, err := pr.Claim(context.TODO(), domain.Promotion{ID: 3, CustomerLimit: 2}, 9)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimCustomerExhausted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare(claimTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(claimCustomer).ExpectExec().WithArgs(3, 9, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectPrepare(releaseTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	pr := promotionRepo.NewPromotionRepository(db)
+
+	ok, err := pr.Claim(context.TODO(), domain.Promotion{ID: 3, CustomerLimit: 1}, 9)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimCustomerError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectPrepare(claimTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(claimCustomer).ExpectExec().WithArgs(3, 9, 1).WillReturnError(fmt.Errorf("some error"))
+	mock.ExpectPrepare(releaseTotal).ExpectExec().WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	pr := promotionRepo.NewPromotionRepository(db)
+
+	ok, err := pr.Claim(context.TODO(), domain.Promotion{ID: 3, CustomerLimit: 1}, 9)
+	assert.Error(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
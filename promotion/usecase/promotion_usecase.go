@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type promotionUsecase struct {
+	promotionRepo domain.PromotionRepository
+	merchantRepo  domain.MerchantRepository
+}
+
+func NewPromotionUsecase(pr domain.PromotionRepository, mr domain.MerchantRepository) domain.PromotionUsecase {
+	return &promotionUsecase{
+		promotionRepo: pr,
+		merchantRepo:  mr,
+	}
+}
+
+func (pu *promotionUsecase) Store(ctx context.Context, p *domain.Promotion) error {
+	_, err := pu.merchantRepo.GetByID(ctx, p.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	p.Code = strings.ToUpper(p.Code)
+	_, err = pu.promotionRepo.GetByCode(ctx, p.MerchantID, p.Code)
+	if err == nil {
+		return domain.ErrPromoCodeTaken
+	}
+	if err != domain.ErrNotFound {
+		return err
+	}
+
+	p.Redeemed = 0
+	if p.BINs == nil {
+		p.BINs = []string{}
+	}
+
+	return pu.promotionRepo.Store(ctx, p)
+}
+
+func (pu *promotionUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Promotion, error) {
+	return pu.promotionRepo.Fetch(ctx, merchantID)
+}
+
+// Redeem applies the transaction's promo code and takes a redemption off the
+// promotion's quota. The merchant's own promotions are looked up first, then
+// parent-wide ones of the parent submitting for it. The transaction keeps
+// its original amount and is charged the discounted one. Promotion amounts
+// are in rupiah, so only rupiah transactions qualify.
+func (pu *promotionUsecase) Redeem(ctx context.Context, t *domain.Transaction, bin string) error {
+	if t.Currency != domain.SettlementCurrency {
+		return domain.ErrPromotion
+	}
+
+	p, err := pu.find(ctx, t)
+	if err != nil {
+		return err
+	}
+	if !eligible(p, t, bin, time.Now()) {
+		return domain.ErrPromotion
+	}
+	if p.CustomerLimit > 0 && t.CustomerID == 0 {
+		return domain.ErrPromotion
+	}
+
+	d := discount(p, t.OriginalAmount)
+	if d >= t.OriginalAmount {
+		return domain.ErrPromotion
+	}
+
+	claimed, err := pu.promotionRepo.Claim(ctx, p, t.CustomerID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.ErrPromotionQuota
+	}
+
+	t.PromoCode = p.Code
+	t.PromotionID = p.ID
+	t.Discount = d
+	t.Amount = t.OriginalAmount - d
+	t.SettlementAmount = t.Amount - t.Fee
+	return nil
+}
+
+// Release gives back the redemption of a transaction that was not paid.
+func (pu *promotionUsecase) Release(ctx context.Context, t *domain.Transaction) error {
+	if t.PromotionID == 0 {
+		return nil
+	}
+
+	return pu.promotionRepo.Release(ctx, t.PromotionID, t.CustomerID)
+}
+
+func (pu *promotionUsecase) find(ctx context.Context, t *domain.Transaction) (domain.Promotion, error) {
+	code := strings.ToUpper(t.PromoCode)
+	p, err := pu.promotionRepo.GetByCode(ctx, t.MerchantID, code)
+	if err == domain.ErrNotFound && t.ParentMerchantID != 0 && t.ParentMerchantID != t.MerchantID {
+		p, err = pu.promotionRepo.GetByCode(ctx, t.ParentMerchantID, code)
+		if err == nil && !p.ParentWide {
+			err = domain.ErrNotFound
+		}
+	}
+	if err == domain.ErrNotFound {
+		return domain.Promotion{}, domain.ErrPromotion
+	}
+	if err != nil {
+		return domain.Promotion{}, err
+	}
+
+	return p, nil
+}
+
+func eligible(p domain.Promotion, t *domain.Transaction, bin string, now time.Time) bool {
+	if now.Before(p.StartsAt) || !now.Before(p.EndsAt) {
+		return false
+	}
+	if t.OriginalAmount < p.MinAmount {
+		return false
+	}
+	if len(p.BINs) == 0 {
+		return true
+	}
+	for _, prefix := range p.BINs {
+		if prefix != "" && strings.HasPrefix(bin, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// discount rounds a percent discount to the nearest rupiah before capping it.
+func discount(p domain.Promotion, amount int64) int64 {
+	if p.Type == domain.DiscountFixed {
+		return p.Value
+	}
+
+	d := (amount*p.Value + 5000) / 10000
+	if p.MaxDiscount > 0 && d > p.MaxDiscount {
+		d = p.MaxDiscount
+	}
+
+	return d
+}
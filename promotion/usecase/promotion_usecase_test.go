@@ -0,0 +1,154 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	promotionUsecase "github.com/hezbymuhammad/payment-gateway/promotion/usecase"
+)
+
+func promotion() domain.Promotion {
+	return domain.Promotion{
+		ID:          3,
+		MerchantID:  6,
+		Code:        "HEMAT",
+		Type:        domain.DiscountPercent,
+		Value:       1000,
+		MaxDiscount: 25000,
+		MinAmount:   50000,
+		BINs:        []string{},
+		StartsAt:    time.Now().Add(-time.Hour),
+		EndsAt:      time.Now().Add(time.Hour),
+	}
+}
+
+func TestRedeem(t *testing.T) {
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(promotion(), nil).Once()
+	mockRepo.On("Claim", mock.Anything, mock.Anything, int64(0)).Return(true, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 100005, OriginalAmount: 100005, Currency: "IDR", PromoCode: "hemat"}
+
+	err := u.Redeem(context.TODO(), &data, "411111")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), data.PromotionID)
+	assert.Equal(t, "HEMAT", data.PromoCode)
+	assert.Equal(t, int64(10001), data.Discount)
+	assert.Equal(t, int64(90004), data.Amount)
+	assert.Equal(t, int64(90004), data.SettlementAmount)
+	assert.Equal(t, int64(100005), data.OriginalAmount)
+}
+
+func TestRedeemCapped(t *testing.T) {
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(promotion(), nil).Once()
+	mockRepo.On("Claim", mock.Anything, mock.Anything, int64(0)).Return(true, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 1000000, OriginalAmount: 1000000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25000), data.Discount)
+	assert.Equal(t, int64(975000), data.Amount)
+}
+
+func TestRedeemParentWide(t *testing.T) {
+	p := promotion()
+	p.MerchantID = 1
+	p.ParentWide = true
+	p.Type = domain.DiscountFixed
+	p.Value = 15000
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(2), "HEMAT").Return(domain.Promotion{}, domain.ErrNotFound).Once()
+	mockRepo.On("GetByCode", mock.Anything, int64(1), "HEMAT").Return(p, nil).Once()
+	mockRepo.On("Claim", mock.Anything, mock.Anything, int64(0)).Return(true, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 2, ParentMerchantID: 1, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(85000), data.Amount)
+}
+
+func TestRedeemParentPromotionNotParentWide(t *testing.T) {
+	p := promotion()
+	p.MerchantID = 1
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(2), "HEMAT").Return(domain.Promotion{}, domain.ErrNotFound).Once()
+	mockRepo.On("GetByCode", mock.Anything, int64(1), "HEMAT").Return(p, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 2, ParentMerchantID: 1, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.Equal(t, domain.ErrPromotion, err)
+}
+
+func TestRedeemOutsideWindow(t *testing.T) {
+	p := promotion()
+	p.EndsAt = time.Now().Add(-time.Minute)
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(p, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.Equal(t, domain.ErrPromotion, err)
+	mockRepo.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRedeemOtherIssuer(t *testing.T) {
+	p := promotion()
+	p.BINs = []string{"411111"}
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(p, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "522222")
+	assert.Equal(t, domain.ErrPromotion, err)
+}
+
+func TestRedeemPerCustomerWithoutCustomer(t *testing.T) {
+	p := promotion()
+	p.CustomerLimit = 1
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(p, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.Equal(t, domain.ErrPromotion, err)
+}
+
+func TestRedeemQuotaExhausted(t *testing.T) {
+	mockRepo := new(mocks.PromotionRepository)
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(promotion(), nil).Once()
+	mockRepo.On("Claim", mock.Anything, mock.Anything, int64(0)).Return(false, nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, new(mocks.MerchantRepository))
+	data := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, Amount: 100000, OriginalAmount: 100000, Currency: "IDR", PromoCode: "HEMAT"}
+
+	err := u.Redeem(context.TODO(), &data, "")
+	assert.Equal(t, domain.ErrPromotionQuota, err)
+	assert.Equal(t, int64(100000), data.Amount)
+	assert.Equal(t, int64(0), data.PromotionID)
+}
+
+func TestStoreTakenCode(t *testing.T) {
+	mockRepo := new(mocks.PromotionRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6}, nil).Once()
+	mockRepo.On("GetByCode", mock.Anything, int64(6), "HEMAT").Return(promotion(), nil).Once()
+	u := promotionUsecase.NewPromotionUsecase(mockRepo, mockMerchantRepo)
+	p := promotion()
+	p.Code = "hemat"
+
+	err := u.Store(context.TODO(), &p)
+	assert.Equal(t, domain.ErrPromoCodeTaken, err)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/qris"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type qrRequest struct {
+	Payload string `json:"payload"`
+}
+
+type QRISHandler struct {
+	Usecase domain.QRISUsecase
+}
+
+func NewQRISHandler(e *echo.Echo, u domain.QRISUsecase) *QRISHandler {
+	handler := &QRISHandler{
+		Usecase: u,
+	}
+
+	e.GET("/qris/static", handler.Static)
+	e.POST("/qris/parse", handler.Parse)
+	e.POST("/qris/notifications", handler.Notify)
+
+	return handler
+}
+
+func (h *QRISHandler) Static(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	settingID, err := strconv.ParseInt(c.QueryParam("settingId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	payload, err := h.Usecase.StaticPayload(ctx, merchantID, settingID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, qrRequest{Payload: payload})
+}
+
+// Parse decodes and validates a QR string without touching any transaction.
+func (h *QRISHandler) Parse(c echo.Context) error {
+	var data qrRequest
+	c.Bind(&data)
+	if data.Payload == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	p, err := qris.Parse(data.Payload)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, p)
+}
+
+// Notify simulates the issuer telling us a QR code has been paid.
+func (h *QRISHandler) Notify(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.QRNotification
+	c.Bind(&data)
+	if data.Payload == "" || data.Amount <= 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	res, err := h.Usecase.Notify(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrInvalidQR, domain.ErrAmountMismatch, domain.ErrPaymentMethod:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrInvalidState, domain.ErrDuplicatePayment:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
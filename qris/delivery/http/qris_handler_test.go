@@ -0,0 +1,110 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/qris"
+	qrisHttp "github.com/hezbymuhammad/payment-gateway/qris/delivery/http"
+)
+
+func TestStatic(t *testing.T) {
+	mockUsecase := new(mocks.QRISUsecase)
+	mockUsecase.On("StaticPayload", mock.Anything, int64(6), int64(8)).Return("000201", nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/qris/static?merchantId=6&settingId=8", nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := qrisHttp.NewQRISHandler(echo.New(), mockUsecase)
+	err = handler.Static(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "000201")
+}
+
+func TestParse(t *testing.T) {
+	payload := qris.Encode(qris.Payload{Dynamic: true, NMID: "ID1026000012345", MCC: "5812", Currency: qris.CurrencyIDR, Amount: 25000, Country: "ID", Name: "CAFE", City: "JAKARTA", Reference: "TX12"})
+	j, err := json.Marshal(map[string]string{"payload": payload})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/qris/parse", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := qrisHttp.NewQRISHandler(echo.New(), new(mocks.QRISUsecase))
+	err = handler.Parse(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var res qris.Payload
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, int64(25000), res.Amount)
+	assert.Equal(t, "TX12", res.Reference)
+}
+
+func TestParseInvalid(t *testing.T) {
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/qris/parse", strings.NewReader(`{"payload":"000201"}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := qrisHttp.NewQRISHandler(echo.New(), new(mocks.QRISUsecase))
+	err = handler.Parse(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestNotify(t *testing.T) {
+	mockUsecase := new(mocks.QRISUsecase)
+	mockUsecase.On("Notify", mock.Anything, mock.Anything).Return(domain.Transaction{ID: 12, State: domain.TransactionCaptured}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/qris/notifications", strings.NewReader(`{"payload":"000201","amount":25000}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := qrisHttp.NewQRISHandler(echo.New(), mockUsecase)
+	err = handler.Notify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNotifyAlreadyPaid(t *testing.T) {
+	mockUsecase := new(mocks.QRISUsecase)
+	mockUsecase.On("Notify", mock.Anything, mock.Anything).Return(domain.Transaction{}, domain.ErrInvalidState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/qris/notifications", strings.NewReader(`{"payload":"000201","amount":25000}`))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := qrisHttp.NewQRISHandler(echo.New(), mockUsecase)
+	err = handler.Notify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
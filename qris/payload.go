@@ -0,0 +1,254 @@
+// Package qris encodes and decodes QRIS payloads, the Indonesian profile of
+// the EMVCo merchant-presented QR code specification.
+package qris
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const (
+	tagFormat     = "00"
+	tagInitiation = "01"
+	tagAcquirer   = "26"
+	tagNational   = "51"
+	tagMCC        = "52"
+	tagCurrency   = "53"
+	tagAmount     = "54"
+	tagCountry    = "58"
+	tagName       = "59"
+	tagCity       = "60"
+	tagPostalCode = "61"
+	tagAdditional = "62"
+	tagCRC        = "63"
+
+	initiationStatic  = "11"
+	initiationDynamic = "12"
+
+	// NationalGUID identifies the QRIS national merchant account template.
+	NationalGUID = "ID.CO.QRIS.WWW"
+
+	// CurrencyIDR is the ISO 4217 numeric code QRIS payloads carry.
+	CurrencyIDR = "360"
+)
+
+// Payload is the decoded content of a QRIS code. A static payload has no
+// amount and no reference; the customer keys in what to pay.
+type Payload struct {
+	Dynamic     bool   `json:"dynamic"`
+	Acquirer    string `json:"acquirer,omitempty"`
+	MerchantPAN string `json:"merchantPan,omitempty"`
+	NMID        string `json:"nmid,omitempty"`
+	Criteria    string `json:"criteria,omitempty"`
+	MCC         string `json:"mcc"`
+	Currency    string `json:"currency"`
+	Amount      int64  `json:"amount"`
+	Country     string `json:"country"`
+	Name        string `json:"name"`
+	City        string `json:"city"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+// Encode renders p as a QRIS string, CRC included. Name and city are cut to
+// the lengths the specification allows.
+func Encode(p Payload) string {
+	var b strings.Builder
+	field(&b, tagFormat, "01")
+	if p.Dynamic {
+		field(&b, tagInitiation, initiationDynamic)
+	} else {
+		field(&b, tagInitiation, initiationStatic)
+	}
+
+	if p.Acquirer != "" {
+		var acquirer strings.Builder
+		field(&acquirer, "00", p.Acquirer)
+		field(&acquirer, "01", p.MerchantPAN)
+		field(&acquirer, "03", p.Criteria)
+		field(&b, tagAcquirer, acquirer.String())
+	}
+
+	var national strings.Builder
+	field(&national, "00", NationalGUID)
+	field(&national, "02", p.NMID)
+	field(&national, "03", p.Criteria)
+	field(&b, tagNational, national.String())
+
+	field(&b, tagMCC, p.MCC)
+	field(&b, tagCurrency, p.Currency)
+	if p.Dynamic {
+		field(&b, tagAmount, strconv.FormatInt(p.Amount, 10))
+	}
+	field(&b, tagCountry, p.Country)
+	field(&b, tagName, truncate(p.Name, 25))
+	field(&b, tagCity, truncate(p.City, 15))
+	field(&b, tagPostalCode, p.PostalCode)
+
+	if p.Reference != "" {
+		var additional strings.Builder
+		field(&additional, "05", p.Reference)
+		field(&b, tagAdditional, additional.String())
+	}
+
+	b.WriteString(tagCRC + "04")
+	s := b.String()
+
+	return s + fmt.Sprintf("%04X", crc16(s))
+}
+
+// Parse decodes and validates a QRIS string. It rejects payloads with a bad
+// checksum, malformed fields or missing mandatory fields.
+func Parse(s string) (Payload, error) {
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != tagCRC+"04" {
+		return Payload{}, domain.ErrInvalidQR
+	}
+	crc, err := strconv.ParseUint(s[len(s)-4:], 16, 16)
+	if err != nil || uint16(crc) != crc16(s[:len(s)-4]) {
+		return Payload{}, domain.ErrInvalidQR
+	}
+
+	fields, err := fieldsOf(s[:len(s)-8])
+	if err != nil {
+		return Payload{}, err
+	}
+	if fields[tagFormat] != "01" {
+		return Payload{}, domain.ErrInvalidQR
+	}
+
+	p := Payload{
+		Dynamic:    fields[tagInitiation] == initiationDynamic,
+		MCC:        fields[tagMCC],
+		Currency:   fields[tagCurrency],
+		Country:    fields[tagCountry],
+		Name:       fields[tagName],
+		City:       fields[tagCity],
+		PostalCode: fields[tagPostalCode],
+	}
+	for _, tag := range []string{tagMCC, tagCurrency, tagCountry, tagName, tagCity} {
+		if fields[tag] == "" {
+			return Payload{}, domain.ErrInvalidQR
+		}
+	}
+
+	if v, ok := fields[tagAcquirer]; ok {
+		sub, err := fieldsOf(v)
+		if err != nil {
+			return Payload{}, err
+		}
+		p.Acquirer = sub["00"]
+		p.MerchantPAN = sub["01"]
+		p.Criteria = sub["03"]
+	}
+	if v, ok := fields[tagNational]; ok {
+		sub, err := fieldsOf(v)
+		if err != nil {
+			return Payload{}, err
+		}
+		if sub["00"] != NationalGUID {
+			return Payload{}, domain.ErrInvalidQR
+		}
+		p.NMID = sub["02"]
+		p.Criteria = sub["03"]
+	}
+	if p.NMID == "" && p.MerchantPAN == "" {
+		return Payload{}, domain.ErrInvalidQR
+	}
+
+	if v, ok := fields[tagAmount]; ok {
+		p.Amount, err = parseAmount(v)
+		if err != nil {
+			return Payload{}, err
+		}
+	}
+	if p.Dynamic && p.Amount <= 0 {
+		return Payload{}, domain.ErrInvalidQR
+	}
+
+	if v, ok := fields[tagAdditional]; ok {
+		sub, err := fieldsOf(v)
+		if err != nil {
+			return Payload{}, err
+		}
+		p.Reference = sub["05"]
+	}
+
+	return p, nil
+}
+
+func field(b *strings.Builder, tag string, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s%02d%s", tag, len(value), value)
+}
+
+// fieldsOf splits a run of ID, length, value triplets into a map keyed by
+// ID.
+func fieldsOf(s string) (map[string]string, error) {
+	fields := map[string]string{}
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, domain.ErrInvalidQR
+		}
+		n, err := strconv.Atoi(s[2:4])
+		if err != nil || n == 0 || len(s) < 4+n {
+			return nil, domain.ErrInvalidQR
+		}
+		if _, dup := fields[s[:2]]; dup {
+			return nil, domain.ErrInvalidQR
+		}
+		fields[s[:2]] = s[4 : 4+n]
+		s = s[4+n:]
+	}
+
+	return fields, nil
+}
+
+// parseAmount reads a whole rupiah amount. EMV allows a decimal part, which
+// for IDR can only be zero.
+func parseAmount(s string) (int64, error) {
+	whole := s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole = s[:i]
+		if strings.Trim(s[i+1:], "0") != "" {
+			return 0, domain.ErrInvalidQR
+		}
+	}
+
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n < 0 {
+		return 0, domain.ErrInvalidQR
+	}
+
+	return n, nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n])
+}
+
+// crc16 is CRC-16/CCITT-FALSE, the checksum EMVCo specifies for tag 63.
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,132 @@
+package qris_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	. "github.com/hezbymuhammad/payment-gateway/qris"
+)
+
+var dynamic = Payload{
+	Dynamic:     true,
+	Acquirer:    "ID.CO.BANKSIM.WWW",
+	MerchantPAN: "9360000812345678901",
+	NMID:        "ID1026000012345",
+	Criteria:    "UMI",
+	MCC:         "5812",
+	Currency:    CurrencyIDR,
+	Amount:      75000,
+	Country:     "ID",
+	Name:        "CAFE",
+	City:        "JAKARTA",
+	PostalCode:  "12190",
+	Reference:   "TX7",
+}
+
+func TestEncode(t *testing.T) {
+	s := Encode(dynamic)
+
+	assert.Equal(t, "000201010212"+
+		"2651"+"0017ID.CO.BANKSIM.WWW"+"01199360000812345678901"+"0303UMI"+
+		"5144"+"0014ID.CO.QRIS.WWW"+"0215ID1026000012345"+"0303UMI"+
+		"52045812"+"5303360"+"540575000"+"5802ID"+"5904CAFE"+"6007JAKARTA"+"610512190"+
+		"6207"+"0503TX7"+
+		"6304", s[:len(s)-4])
+	assert.Equal(t, "0268", s[len(s)-4:])
+	assert.Equal(t, dynamic, mustParse(t, s))
+}
+
+func TestEncodeStatic(t *testing.T) {
+	static := dynamic
+	static.Dynamic = false
+	static.Amount = 0
+	static.Reference = ""
+
+	s := Encode(static)
+
+	assert.Equal(t, "010211", s[6:12])
+	assert.NotContains(t, s, "5405")
+	assert.Equal(t, static, mustParse(t, s))
+}
+
+func TestEncodeTruncatesOnRunes(t *testing.T) {
+	long := dynamic
+	long.Name = strings.Repeat("é", 30)
+	long.City = "KOTA BARU SÃO PAULO"
+
+	p := mustParse(t, Encode(long))
+
+	assert.Equal(t, strings.Repeat("é", 25), p.Name)
+	assert.Equal(t, "KOTA BARU SÃO P", p.City)
+}
+
+func TestParseRejectsBadChecksum(t *testing.T) {
+	s := Encode(dynamic)
+	tampered := s[:len(s)-4] + "0000"
+	if tampered == s {
+		tampered = s[:len(s)-4] + "FFFF"
+	}
+
+	_, err := Parse(tampered)
+
+	assert.Equal(t, domain.ErrInvalidQR, err)
+}
+
+func TestParseRejectsTamperedAmount(t *testing.T) {
+	s := Encode(dynamic)
+
+	_, err := Parse(strings.Replace(s, "540575000", "540515000", 1))
+
+	assert.Equal(t, domain.ErrInvalidQR, err)
+}
+
+func TestParseRejectsMissingFields(t *testing.T) {
+	p := dynamic
+	p.City = ""
+
+	_, err := Parse(Encode(p))
+
+	assert.Equal(t, domain.ErrInvalidQR, err)
+}
+
+func TestParseAmountWithDecimals(t *testing.T) {
+	p, err := Parse(withCRC("00020101021251440014ID.CO.QRIS.WWW0215ID10260000123450303UMI520458125303360540875000.005802ID5904CAFE6007JAKARTA6304"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(75000), p.Amount)
+
+	_, err = Parse(withCRC("00020101021251440014ID.CO.QRIS.WWW0215ID10260000123450303UMI520458125303360540875000.505802ID5904CAFE6007JAKARTA6304"))
+	assert.Equal(t, domain.ErrInvalidQR, err)
+}
+
+func TestParseGarbage(t *testing.T) {
+	for _, s := range []string{"", "6304", "hello", "000201" + "6304ABCD"} {
+		_, err := Parse(s)
+		assert.Equal(t, domain.ErrInvalidQR, err, s)
+	}
+}
+
+// withCRC completes a hand written payload the way Encode would.
+func withCRC(s string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%s%04X", s, crc)
+}
+
+func mustParse(t *testing.T, s string) Payload {
+	p, err := Parse(s)
+	assert.NoError(t, err)
+	return p
+}
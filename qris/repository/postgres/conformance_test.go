@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	qrRepo "github.com/hezbymuhammad/payment-gateway/qris/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.QRPaymentRepository(t, func(t *testing.T) domain.QRPaymentRepository {
+		return qrRepo.NewQRPaymentRepository(migrationtest.NewPostgresDB(t))
+	})
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type postgresQRPaymentRepo struct {
+	DB *sql.DB
+}
+
+func NewQRPaymentRepository(db *sql.DB) domain.QRPaymentRepository {
+	return &postgresQRPaymentRepo{
+		DB: db,
+	}
+}
+
+// ClaimPayment records a static QR payment unless the issuer already
+// reported the same reference for the merchant, and reports whether this
+// caller won.
+func (qr *postgresQRPaymentRepo) ClaimPayment(ctx context.Context, p *domain.QRPayment) (bool, error) {
+	query := "INSERT INTO qr_payments (merchant_id, transaction_id, issuer_reference, amount, paid_at) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (merchant_id, issuer_reference) DO NOTHING RETURNING id"
+
+	err := qr.DB.QueryRowContext(ctx, query, p.MerchantID, p.TransactionID, p.IssuerReference, p.Amount, p.PaidAt).Scan(&p.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (qr *postgresQRPaymentRepo) UpdatePayment(ctx context.Context, p *domain.QRPayment) error {
+	query := "UPDATE qr_payments SET transaction_id=$1 WHERE id=$2"
+
+	return qr.exec(ctx, query, p.TransactionID, p.ID)
+}
+
+// ReleasePayment drops a claimed payment that could not be booked so that
+// the issuer can retry it.
+func (qr *postgresQRPaymentRepo) ReleasePayment(ctx context.Context, id int64) error {
+	query := "DELETE FROM qr_payments WHERE id=$1"
+
+	return qr.exec(ctx, query, id)
+}
+
+func (qr *postgresQRPaymentRepo) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := qr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+	}
+
+	return err
+}
@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	qrRepo "github.com/hezbymuhammad/payment-gateway/qris/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.QRPaymentRepository(t, func(t *testing.T) domain.QRPaymentRepository {
+		return qrRepo.NewQRPaymentRepository(migrationtest.NewDB(t))
+	})
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type sqliteQRPaymentRepo struct {
+	DB *sql.DB
+}
+
+func NewQRPaymentRepository(db *sql.DB) domain.QRPaymentRepository {
+	return &sqliteQRPaymentRepo{
+		DB: db,
+	}
+}
+
+// ClaimPayment records a static QR payment unless the issuer already
+// reported the same reference for the merchant, and reports whether this
+// caller won.
+func (qr *sqliteQRPaymentRepo) ClaimPayment(ctx context.Context, p *domain.QRPayment) (bool, error) {
+	query := "INSERT OR IGNORE INTO qr_payments (merchant_id, transaction_id, issuer_reference, amount, paid_at) VALUES (?, ?, ?, ?, ?)"
+
+	res, err := qr.exec(ctx, query, p.MerchantID, p.TransactionID, p.IssuerReference, p.Amount, p.PaidAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	p.ID = lastID
+	return true, nil
+}
+
+func (qr *sqliteQRPaymentRepo) UpdatePayment(ctx context.Context, p *domain.QRPayment) error {
+	query := "UPDATE qr_payments SET transaction_id=? WHERE id=?"
+
+	_, err := qr.exec(ctx, query, p.TransactionID, p.ID)
+	return err
+}
+
+// ReleasePayment drops a claimed payment that could not be booked so that
+// the issuer can retry it.
+func (qr *sqliteQRPaymentRepo) ReleasePayment(ctx context.Context, id int64) error {
+	query := "DELETE FROM qr_payments WHERE id=?"
+
+	_, err := qr.exec(ctx, query, id)
+	return err
+}
+
+func (qr *sqliteQRPaymentRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := qr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
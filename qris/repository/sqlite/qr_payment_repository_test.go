@@ -0,0 +1,60 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	qrRepo "github.com/hezbymuhammad/payment-gateway/qris/repository/sqlite"
+)
+
+var now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func TestClaimPayment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.QRPayment{MerchantID: 6, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+	query := regexp.QuoteMeta("INSERT OR IGNORE INTO qr_payments (merchant_id, transaction_id, issuer_reference, amount, paid_at) VALUES (?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(6, 0, "issuer-2", 15000, now).WillReturnResult(sqlmock.NewResult(8, 1))
+	qr := qrRepo.NewQRPaymentRepository(db)
+
+	claimed, err := qr.ClaimPayment(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, int64(8), data.ID)
+}
+
+func TestClaimPaymentPerMerchant(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	qr := qrRepo.NewQRPaymentRepository(db)
+
+	first := domain.QRPayment{MerchantID: 6, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+	claimed, err := qr.ClaimPayment(context.TODO(), &first)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	retry := first
+	claimed, err = qr.ClaimPayment(context.TODO(), &retry)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	other := domain.QRPayment{MerchantID: 7, IssuerReference: "issuer-2", Amount: 15000, PaidAt: now}
+	claimed, err = qr.ClaimPayment(context.TODO(), &other)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	assert.NoError(t, qr.ReleasePayment(context.TODO(), first.ID))
+	claimed, err = qr.ClaimPayment(context.TODO(), &retry)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/qris"
+)
+
+// PaymentType is the setting and transaction payment type paid by QRIS.
+const PaymentType = "QR"
+
+type qrChannel struct {
+	merchantRepo domain.MerchantRepository
+}
+
+// NewQRChannel returns the payment channel that answers QR transactions with
+// a dynamic QRIS code for the exact amount.
+func NewQRChannel(mr domain.MerchantRepository) domain.PaymentChannel {
+	return &qrChannel{
+		merchantRepo: mr,
+	}
+}
+
+func (c *qrChannel) PaymentType() string {
+	return PaymentType
+}
+
+func (c *qrChannel) Initiate(ctx context.Context, t *domain.Transaction, s domain.Setting) error {
+	if s.QR == nil || t.Currency != "IDR" {
+		return domain.ErrPaymentMethod
+	}
+	m, err := c.merchantRepo.GetByID(ctx, t.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	p := payloadFor(m, s)
+	p.Dynamic = true
+	p.Amount = t.Amount
+	p.Reference = reference(t.ID)
+
+	t.PaymentCode = qris.Encode(p)
+	t.Processor = "qris"
+	return nil
+}
+
+func payloadFor(m domain.Merchant, s domain.Setting) qris.Payload {
+	return qris.Payload{
+		Acquirer:    s.QR.Acquirer,
+		MerchantPAN: s.QR.MerchantPAN,
+		NMID:        s.QR.NMID,
+		Criteria:    s.QR.Criteria,
+		MCC:         s.QR.MCC,
+		Currency:    qris.CurrencyIDR,
+		Country:     "ID",
+		Name:        m.Name,
+		City:        s.QR.City,
+		PostalCode:  s.QR.PostalCode,
+	}
+}
+
+// reference is the bill number a dynamic code carries so that a payment
+// notification can be matched back to its transaction.
+func reference(id int64) string {
+	return fmt.Sprintf("TX%d", id)
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/qris"
+)
+
+type qrisUsecase struct {
+	merchantRepo  domain.MerchantRepository
+	qrPaymentRepo domain.QRPaymentRepository
+	transactions  domain.TransactionUsecase
+}
+
+func NewQRISUsecase(mr domain.MerchantRepository, qr domain.QRPaymentRepository, tu domain.TransactionUsecase) domain.QRISUsecase {
+	return &qrisUsecase{
+		merchantRepo:  mr,
+		qrPaymentRepo: qr,
+		transactions:  tu,
+	}
+}
+
+// StaticPayload renders the printable code of a merchant's QR setting. It has
+// no amount, so every payment made with it becomes a new transaction.
+func (qu *qrisUsecase) StaticPayload(ctx context.Context, merchantID int64, settingID int64) (string, error) {
+	s, err := qu.merchantRepo.GetSetting(ctx, settingID)
+	if err != nil {
+		return "", err
+	}
+	if s.MerchantID != merchantID {
+		return "", domain.ErrNotFound
+	}
+	if s.PaymentType != PaymentType || s.QR == nil {
+		return "", domain.ErrPaymentMethod
+	}
+
+	m, err := qu.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return "", err
+	}
+
+	return qris.Encode(payloadFor(m, s)), nil
+}
+
+// Notify completes the transaction a paid code belongs to. A dynamic code
+// must be the one issued for its transaction; a static code opens a new QR
+// transaction for the amount the customer keyed in, once per issuer
+// reference.
+func (qu *qrisUsecase) Notify(ctx context.Context, n *domain.QRNotification) (domain.Transaction, error) {
+	p, err := qris.Parse(n.Payload)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if p.Dynamic {
+		return qu.completeDynamic(ctx, p, n)
+	}
+	return qu.completeStatic(ctx, p, n)
+}
+
+func (qu *qrisUsecase) completeDynamic(ctx context.Context, p qris.Payload, n *domain.QRNotification) (domain.Transaction, error) {
+	if !strings.HasPrefix(p.Reference, "TX") {
+		return domain.Transaction{}, domain.ErrInvalidQR
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(p.Reference, "TX"), 10, 64)
+	if err != nil {
+		return domain.Transaction{}, domain.ErrInvalidQR
+	}
+
+	t, err := qu.transactions.GetByID(ctx, id)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if t.PaymentCode != n.Payload {
+		return domain.Transaction{}, domain.ErrInvalidQR
+	}
+
+	return qu.transactions.Complete(ctx, t.ID, n.Amount, n.IssuerReference)
+}
+
+func (qu *qrisUsecase) completeStatic(ctx context.Context, p qris.Payload, n *domain.QRNotification) (domain.Transaction, error) {
+	if n.Amount <= 0 {
+		return domain.Transaction{}, domain.ErrAmountMismatch
+	}
+	s, err := qu.merchantRepo.GetSettingByNMID(ctx, p.NMID)
+	if err == domain.ErrNotFound {
+		return domain.Transaction{}, domain.ErrInvalidQR
+	}
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if n.IssuerReference == "" {
+		return domain.Transaction{}, domain.ErrInvalidQR
+	}
+
+	payment := domain.QRPayment{
+		MerchantID:      s.MerchantID,
+		IssuerReference: n.IssuerReference,
+		Amount:          n.Amount,
+		PaidAt:          time.Now().UTC().Truncate(time.Second),
+	}
+	claimed, err := qu.qrPaymentRepo.ClaimPayment(ctx, &payment)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if !claimed {
+		return domain.Transaction{}, domain.ErrDuplicatePayment
+	}
+
+	t, err := qu.book(ctx, s, n)
+	if err != nil {
+		qu.release(ctx, payment.ID)
+		return domain.Transaction{}, err
+	}
+
+	payment.TransactionID = t.ID
+	err = qu.qrPaymentRepo.UpdatePayment(ctx, &payment)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	return t, nil
+}
+
+// book opens and completes the transaction a static QR payment becomes.
+func (qu *qrisUsecase) book(ctx context.Context, s domain.Setting, n *domain.QRNotification) (domain.Transaction, error) {
+	t := domain.Transaction{
+		MerchantID:       s.MerchantID,
+		ParentMerchantID: s.MerchantID,
+		SettingID:        s.ID,
+		Amount:           n.Amount,
+		Currency:         "IDR",
+		PaymentType:      PaymentType,
+	}
+	err := qu.transactions.Store(ctx, &t)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	return qu.transactions.Complete(ctx, t.ID, n.Amount, n.IssuerReference)
+}
+
+func (qu *qrisUsecase) release(ctx context.Context, id int64) {
+	err := qu.qrPaymentRepo.ReleasePayment(ctx, id)
+	if err != nil {
+		log.Printf("qr payment %d: %v", id, err)
+	}
+}
@@ -0,0 +1,182 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/qris"
+	qrisUsecase "github.com/hezbymuhammad/payment-gateway/qris/usecase"
+)
+
+var merchant = domain.Merchant{ID: 6, Name: "CAFE"}
+
+var setting = domain.Setting{
+	ID:          8,
+	MerchantID:  6,
+	PaymentType: "QR",
+	PaymentName: "QRIS",
+	QR: &domain.QRAccount{
+		NMID:     "ID1026000012345",
+		Criteria: "UMI",
+		MCC:      "5812",
+		City:     "JAKARTA",
+	},
+}
+
+func TestInitiate(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(merchant, nil).Once()
+	c := qrisUsecase.NewQRChannel(mockMerchantRepo)
+	data := domain.Transaction{ID: 12, MerchantID: 6, Amount: 25000, Currency: "IDR"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.NoError(t, err)
+
+	p, err := qris.Parse(data.PaymentCode)
+	assert.NoError(t, err)
+	assert.True(t, p.Dynamic)
+	assert.Equal(t, int64(25000), p.Amount)
+	assert.Equal(t, "TX12", p.Reference)
+	assert.Equal(t, "CAFE", p.Name)
+	assert.Equal(t, "ID1026000012345", p.NMID)
+}
+
+func TestInitiateForeignCurrency(t *testing.T) {
+	c := qrisUsecase.NewQRChannel(new(mocks.MerchantRepository))
+	data := domain.Transaction{ID: 12, MerchantID: 6, Amount: 25000, Currency: "USD"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+}
+
+func TestStaticPayload(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(8)).Return(setting, nil).Once()
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(merchant, nil).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, new(mocks.QRPaymentRepository), new(mocks.TransactionUsecase))
+
+	res, err := u.StaticPayload(context.TODO(), 6, 8)
+	assert.NoError(t, err)
+
+	p, err := qris.Parse(res)
+	assert.NoError(t, err)
+	assert.False(t, p.Dynamic)
+	assert.Equal(t, int64(0), p.Amount)
+}
+
+func TestStaticPayloadOfAnotherMerchant(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(8)).Return(setting, nil).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, new(mocks.QRPaymentRepository), new(mocks.TransactionUsecase))
+
+	_, err := u.StaticPayload(context.TODO(), 1, 8)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestNotifyDynamic(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(merchant, nil).Once()
+	pending := domain.Transaction{ID: 12, MerchantID: 6, Amount: 25000, Currency: "IDR", PaymentType: "QR", State: domain.TransactionPending}
+	err := qrisUsecase.NewQRChannel(mockMerchantRepo).Initiate(context.TODO(), &pending, setting)
+	assert.NoError(t, err)
+
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockTransactions.On("GetByID", mock.Anything, int64(12)).Return(pending, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(12), int64(25000), "issuer-1").Return(domain.Transaction{ID: 12, State: domain.TransactionCaptured}, nil).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, new(mocks.QRPaymentRepository), mockTransactions)
+
+	res, err := u.Notify(context.TODO(), &domain.QRNotification{Payload: pending.PaymentCode, Amount: 25000, IssuerReference: "issuer-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TransactionCaptured, res.State)
+}
+
+func TestNotifyDynamicForAnotherTransaction(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(merchant, nil)
+	issued := domain.Transaction{ID: 12, MerchantID: 6, Amount: 25000, Currency: "IDR"}
+	err := qrisUsecase.NewQRChannel(mockMerchantRepo).Initiate(context.TODO(), &issued, setting)
+	assert.NoError(t, err)
+	other := domain.Transaction{ID: 12, MerchantID: 6, Amount: 99000, Currency: "IDR"}
+	err = qrisUsecase.NewQRChannel(mockMerchantRepo).Initiate(context.TODO(), &other, setting)
+	assert.NoError(t, err)
+
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockTransactions.On("GetByID", mock.Anything, int64(12)).Return(issued, nil).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, new(mocks.QRPaymentRepository), mockTransactions)
+
+	_, err = u.Notify(context.TODO(), &domain.QRNotification{Payload: other.PaymentCode, Amount: 99000})
+	assert.Equal(t, domain.ErrInvalidQR, err)
+	mockTransactions.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotifyStatic(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSettingByNMID", mock.Anything, "ID1026000012345").Return(setting, nil).Once()
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockTransactions.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+		return t.MerchantID == 6 && t.SettingID == 8 && t.Amount == 15000 && t.PaymentType == "QR"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Transaction).ID = 13
+	}).Return(nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(13), int64(15000), "issuer-2").Return(domain.Transaction{ID: 13, State: domain.TransactionCaptured}, nil).Once()
+	mockQRPaymentRepo := new(mocks.QRPaymentRepository)
+	mockQRPaymentRepo.On("ClaimPayment", mock.Anything, mock.MatchedBy(func(p *domain.QRPayment) bool {
+		return p.MerchantID == 6 && p.IssuerReference == "issuer-2" && p.Amount == 15000
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.QRPayment).ID = 3
+	}).Return(true, nil).Once()
+	mockQRPaymentRepo.On("UpdatePayment", mock.Anything, mock.MatchedBy(func(p *domain.QRPayment) bool {
+		return p.ID == 3 && p.TransactionID == 13
+	})).Return(nil).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, mockQRPaymentRepo, mockTransactions)
+
+	static := qris.Encode(qris.Payload{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", Currency: qris.CurrencyIDR, Country: "ID", Name: "CAFE", City: "JAKARTA"})
+	res, err := u.Notify(context.TODO(), &domain.QRNotification{Payload: static, Amount: 15000, IssuerReference: "issuer-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), res.ID)
+	mockQRPaymentRepo.AssertExpectations(t)
+}
+
+func TestNotifyStaticRetried(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSettingByNMID", mock.Anything, "ID1026000012345").Return(setting, nil).Once()
+	mockQRPaymentRepo := new(mocks.QRPaymentRepository)
+	mockQRPaymentRepo.On("ClaimPayment", mock.Anything, mock.AnythingOfType("*domain.QRPayment")).Return(false, nil).Once()
+	mockTransactions := new(mocks.TransactionUsecase)
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, mockQRPaymentRepo, mockTransactions)
+
+	static := qris.Encode(qris.Payload{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", Currency: qris.CurrencyIDR, Country: "ID", Name: "CAFE", City: "JAKARTA"})
+	_, err := u.Notify(context.TODO(), &domain.QRNotification{Payload: static, Amount: 15000, IssuerReference: "issuer-2"})
+	assert.Equal(t, domain.ErrDuplicatePayment, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestNotifyStaticReleasesClaim(t *testing.T) {
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetSettingByNMID", mock.Anything, "ID1026000012345").Return(setting, nil).Once()
+	mockQRPaymentRepo := new(mocks.QRPaymentRepository)
+	mockQRPaymentRepo.On("ClaimPayment", mock.Anything, mock.AnythingOfType("*domain.QRPayment")).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.QRPayment).ID = 3
+	}).Return(true, nil).Once()
+	mockQRPaymentRepo.On("ReleasePayment", mock.Anything, int64(3)).Return(nil).Once()
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockTransactions.On("Store", mock.Anything, mock.AnythingOfType("*domain.Transaction")).Return(domain.ErrMerchantInactive).Once()
+	u := qrisUsecase.NewQRISUsecase(mockMerchantRepo, mockQRPaymentRepo, mockTransactions)
+
+	static := qris.Encode(qris.Payload{NMID: "ID1026000012345", Criteria: "UMI", MCC: "5812", Currency: qris.CurrencyIDR, Country: "ID", Name: "CAFE", City: "JAKARTA"})
+	_, err := u.Notify(context.TODO(), &domain.QRNotification{Payload: static, Amount: 15000, IssuerReference: "issuer-2"})
+	assert.Equal(t, domain.ErrMerchantInactive, err)
+	mockQRPaymentRepo.AssertExpectations(t)
+}
+
+func TestNotifyInvalidPayload(t *testing.T) {
+	u := qrisUsecase.NewQRISUsecase(new(mocks.MerchantRepository), new(mocks.QRPaymentRepository), new(mocks.TransactionUsecase))
+
+	_, err := u.Notify(context.TODO(), &domain.QRNotification{Payload: "not a qr", Amount: 15000})
+	assert.Equal(t, domain.ErrInvalidQR, err)
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type RiskHandler struct {
+	Usecase domain.RiskUsecase
+}
+
+func NewRiskHandler(e *echo.Echo, u domain.RiskUsecase) *RiskHandler {
+	handler := &RiskHandler{
+		Usecase: u,
+	}
+
+	e.POST("/risk/rules", handler.StoreRule)
+	e.GET("/risk/rules", handler.FetchRules)
+	e.PUT("/risk/rules/:id", handler.UpdateRule)
+	e.DELETE("/risk/rules/:id", handler.DeleteRule)
+
+	return handler
+}
+
+// StoreRule adds a rule. Rules are enabled unless the request says
+// otherwise.
+func (h *RiskHandler) StoreRule(c echo.Context) error {
+	ctx := c.Request().Context()
+	data := domain.RiskRule{Enabled: true}
+	c.Bind(&data)
+	if !valid(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.StoreRule(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *RiskHandler) FetchRules(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.FetchRules(ctx, merchantID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *RiskHandler) UpdateRule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var data domain.RiskRule
+	c.Bind(&data)
+	data.ID = id
+	if !valid(data) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err = h.Usecase.UpdateRule(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, data)
+}
+
+func (h *RiskHandler) DeleteRule(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	err = h.Usecase.DeleteRule(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// valid checks a rule has what its type needs. Names and blocklist values
+// are stored comma separated, so they cannot contain commas themselves.
+func valid(r domain.RiskRule) bool {
+	if r.MerchantID == 0 || r.Name == "" || strings.Contains(r.Name, ",") {
+		return false
+	}
+	if r.Action != domain.RiskAllow && r.Action != domain.RiskReview && r.Action != domain.RiskBlock {
+		return false
+	}
+
+	switch r.Type {
+	case domain.RiskRuleVelocity:
+		switch r.Field {
+		case domain.RiskFieldCard, domain.RiskFieldCustomer, domain.RiskFieldIP:
+		default:
+			return false
+		}
+		return r.Threshold > 0 && r.WindowSeconds > 0
+	case domain.RiskRuleAmount:
+		return r.Threshold > 0
+	case domain.RiskRuleCountryMismatch:
+		return true
+	case domain.RiskRuleBlocklist:
+		switch r.Field {
+		case domain.RiskFieldCard, domain.RiskFieldCustomer, domain.RiskFieldIP, domain.RiskFieldBIN, domain.RiskFieldCountry:
+		default:
+			return false
+		}
+		if len(r.Values) == 0 {
+			return false
+		}
+		for _, v := range r.Values {
+			if v == "" || strings.Contains(v, ",") {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
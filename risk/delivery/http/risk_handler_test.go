@@ -0,0 +1,80 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	riskHttp "github.com/hezbymuhammad/payment-gateway/risk/delivery/http"
+)
+
+func TestStoreRule(t *testing.T) {
+	mockUsecase := new(mocks.RiskUsecase)
+	mockUsecase.On("StoreRule", mock.Anything, mock.MatchedBy(func(r *domain.RiskRule) bool {
+		return r.Enabled && r.Field == domain.RiskFieldCard && r.WindowSeconds == 600
+	})).Return(nil).Once()
+
+	e := echo.New()
+	body := `{"merchantId":6,"name":"card velocity","type":"velocity","action":"review","field":"card","threshold":3,"windowSeconds":600}`
+	req, err := http.NewRequest(echo.POST, "/risk/rules", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := riskHttp.NewRiskHandler(echo.New(), mockUsecase)
+	err = handler.StoreRule(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestStoreRuleInvalid(t *testing.T) {
+	mockUsecase := new(mocks.RiskUsecase)
+
+	e := echo.New()
+	body := `{"merchantId":6,"name":"bad list","type":"blocklist","action":"block","field":"email","values":["a@example.com"]}`
+	req, err := http.NewRequest(echo.POST, "/risk/rules", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := riskHttp.NewRiskHandler(echo.New(), mockUsecase)
+	err = handler.StoreRule(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "StoreRule", mock.Anything, mock.Anything)
+}
+
+func TestDeleteRuleNotFound(t *testing.T) {
+	mockUsecase := new(mocks.RiskUsecase)
+	mockUsecase.On("DeleteRule", mock.Anything, int64(6), int64(2)).Return(domain.ErrNotFound).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.DELETE, "/risk/rules/2?merchantId=6", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/risk/rules/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("2")
+
+	handler := riskHttp.NewRiskHandler(echo.New(), mockUsecase)
+	err = handler.DeleteRule(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	riskRepo "github.com/hezbymuhammad/payment-gateway/risk/repository/memory"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RiskRepository(t, func(t *testing.T) (domain.RiskRepository, domain.TransactionRepository) {
+		tr := transactionRepo.NewTransactionRepository()
+		return riskRepo.NewRiskRepository(tr), tr
+	})
+}
@@ -0,0 +1,149 @@
+// Package memory keeps risk rules in process memory, for tests and demos
+// that should not need a database. It behaves like the SQL repositories.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// Transactions is where velocity rules count from. The in-memory
+// transaction repository is one.
+type Transactions interface {
+	All() []domain.Transaction
+}
+
+// velocityFields reads the value velocity rules count by from a
+// transaction.
+var velocityFields = map[string]func(t domain.Transaction) string{
+	domain.RiskFieldCard:     func(t domain.Transaction) string { return t.CardFingerprint },
+	domain.RiskFieldCustomer: func(t domain.Transaction) string { return strconv.FormatInt(t.CustomerID, 10) },
+	domain.RiskFieldIP:       func(t domain.Transaction) string { return t.IPAddress },
+}
+
+// RiskRepository is a domain.RiskRepository safe for concurrent use.
+type RiskRepository struct {
+	mu           sync.RWMutex
+	rules        map[int64]domain.RiskRule
+	lastID       int64
+	transactions Transactions
+}
+
+var _ domain.RiskRepository = (*RiskRepository)(nil)
+
+func NewRiskRepository(transactions Transactions) *RiskRepository {
+	return &RiskRepository{
+		rules:        map[int64]domain.RiskRule{},
+		transactions: transactions,
+	}
+}
+
+func (rr *RiskRepository) Store(ctx context.Context, r *domain.RiskRule) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.lastID++
+	r.ID = rr.lastID
+	rr.rules[r.ID] = copyRule(*r)
+
+	return nil
+}
+
+func (rr *RiskRepository) GetByID(ctx context.Context, id int64) (domain.RiskRule, error) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	r, ok := rr.rules[id]
+	if !ok {
+		return domain.RiskRule{}, domain.ErrNotFound
+	}
+
+	return copyRule(r), nil
+}
+
+func (rr *RiskRepository) Fetch(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	return rr.fetch(func(r domain.RiskRule) bool {
+		return r.MerchantID == merchantID
+	}), nil
+}
+
+// FetchEnabled lists the rules that apply to a transaction: the merchant's
+// own and, for a child merchant, its parent's.
+func (rr *RiskRepository) FetchEnabled(ctx context.Context, merchantID int64, parentMerchantID int64) ([]domain.RiskRule, error) {
+	return rr.fetch(func(r domain.RiskRule) bool {
+		return r.Enabled && (r.MerchantID == merchantID || r.MerchantID == parentMerchantID)
+	}), nil
+}
+
+// Update changes everything but the merchant and creation time. Like the
+// SQL repositories it does nothing for an unknown rule.
+func (rr *RiskRepository) Update(ctx context.Context, r *domain.RiskRule) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	stored, ok := rr.rules[r.ID]
+	if !ok {
+		return nil
+	}
+	updated := copyRule(*r)
+	updated.MerchantID = stored.MerchantID
+	updated.CreatedAt = stored.CreatedAt
+	rr.rules[r.ID] = updated
+
+	return nil
+}
+
+func (rr *RiskRepository) Delete(ctx context.Context, id int64) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	delete(rr.rules, id)
+
+	return nil
+}
+
+// CountSince counts a merchant's transactions sharing the given card,
+// customer or IP made at or after since.
+func (rr *RiskRepository) CountSince(ctx context.Context, merchantID int64, field string, value string, since time.Time) (int64, error) {
+	read, ok := velocityFields[field]
+	if !ok {
+		return 0, fmt.Errorf("risk: cannot count by %q", field)
+	}
+
+	var count int64
+	for _, t := range rr.transactions.All() {
+		if t.MerchantID == merchantID && read(t) == value && !t.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (rr *RiskRepository) fetch(match func(r domain.RiskRule) bool) []domain.RiskRule {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	result := []domain.RiskRule{}
+	for _, r := range rr.rules {
+		if match(r) {
+			result = append(result, copyRule(r))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result
+}
+
+func copyRule(r domain.RiskRule) domain.RiskRule {
+	r.Values = append([]string{}, r.Values...)
+	r.CreatedAt = r.CreatedAt.UTC()
+
+	return r
+}
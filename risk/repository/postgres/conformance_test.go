@@ -0,0 +1,21 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	riskRepo "github.com/hezbymuhammad/payment-gateway/risk/repository/postgres"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RiskRepository(t, func(t *testing.T) (domain.RiskRepository, domain.TransactionRepository) {
+		db := migrationtest.NewPostgresDB(t)
+		return riskRepo.NewRiskRepository(db), transactionRepo.NewTransactionRepository(db)
+	})
+}
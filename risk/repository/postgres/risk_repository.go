@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const ruleColumns = "id, merchant_id, name, type, action, field, threshold, window_seconds, entries, enabled, created_at"
+
+// velocityColumns maps the fields velocity rules count by to the
+// transaction columns holding them.
+var velocityColumns = map[string]string{
+	domain.RiskFieldCard:     "card_fingerprint",
+	domain.RiskFieldCustomer: "customer_id",
+	domain.RiskFieldIP:       "ip_address",
+}
+
+type postgresRiskRepo struct {
+	DB *sql.DB
+}
+
+func NewRiskRepository(db *sql.DB) domain.RiskRepository {
+	return &postgresRiskRepo{
+		DB: db,
+	}
+}
+
+func (rr *postgresRiskRepo) Store(ctx context.Context, r *domain.RiskRule) error {
+	query := "INSERT INTO risk_rules (merchant_id, name, type, action, field, threshold, window_seconds, entries, enabled, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id"
+
+	err := rr.DB.QueryRowContext(ctx, query, r.MerchantID, r.Name, r.Type, r.Action, r.Field, r.Threshold, r.WindowSeconds, entries(r.Values), r.Enabled, r.CreatedAt).Scan(&r.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (rr *postgresRiskRepo) GetByID(ctx context.Context, id int64) (domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE id=$1"
+
+	data, err := scanRule(rr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.RiskRule{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.RiskRule{}, err
+	}
+
+	return data, nil
+}
+
+func (rr *postgresRiskRepo) Fetch(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE merchant_id=$1 ORDER BY id"
+
+	return rr.fetch(ctx, query, merchantID)
+}
+
+// FetchEnabled lists the rules that apply to a transaction: the merchant's
+// own and, for a child merchant, its parent's.
+func (rr *postgresRiskRepo) FetchEnabled(ctx context.Context, merchantID int64, parentMerchantID int64) ([]domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE merchant_id IN ($1, $2) AND enabled ORDER BY id"
+
+	return rr.fetch(ctx, query, merchantID, parentMerchantID)
+}
+
+func (rr *postgresRiskRepo) Update(ctx context.Context, r *domain.RiskRule) error {
+	query := "UPDATE risk_rules SET name=$1, type=$2, action=$3, field=$4, threshold=$5, window_seconds=$6, entries=$7, enabled=$8 WHERE id=$9"
+
+	_, err := rr.exec(ctx, query, r.Name, r.Type, r.Action, r.Field, r.Threshold, r.WindowSeconds, entries(r.Values), r.Enabled, r.ID)
+	return err
+}
+
+func (rr *postgresRiskRepo) Delete(ctx context.Context, id int64) error {
+	_, err := rr.exec(ctx, "DELETE FROM risk_rules WHERE id=$1", id)
+	return err
+}
+
+// CountSince counts a merchant's transactions sharing the given card,
+// customer or IP made at or after since. customer_id is a number here, so
+// the value is compared as text.
+func (rr *postgresRiskRepo) CountSince(ctx context.Context, merchantID int64, field string, value string, since time.Time) (int64, error) {
+	column, ok := velocityColumns[field]
+	if !ok {
+		return 0, fmt.Errorf("risk: cannot count by %q", field)
+	}
+	query := "SELECT COUNT(*) FROM transactions WHERE merchant_id=$1 AND " + column + "::text=$2 AND created_at >= $3"
+
+	var count int64
+	err := rr.DB.QueryRowContext(ctx, query, merchantID, value, since).Scan(&count)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (rr *postgresRiskRepo) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.RiskRule, error) {
+	rows, err := rr.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.RiskRule{}
+	for rows.Next() {
+		data, err := scanRule(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (rr *postgresRiskRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := rr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRule reads a rule row. Blocklist values are a text array.
+func scanRule(row scanner) (domain.RiskRule, error) {
+	data := domain.RiskRule{}
+	var values pq.StringArray
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Name,
+		&data.Type,
+		&data.Action,
+		&data.Field,
+		&data.Threshold,
+		&data.WindowSeconds,
+		&values,
+		&data.Enabled,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return domain.RiskRule{}, err
+	}
+
+	data.Values = []string(values)
+	if data.Values == nil {
+		data.Values = []string{}
+	}
+	data.CreatedAt = data.CreatedAt.UTC()
+
+	return data, nil
+}
+
+func entries(values []string) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+
+	return pq.StringArray(values)
+}
@@ -0,0 +1,18 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	riskRepo "github.com/hezbymuhammad/payment-gateway/risk/repository/sqlite"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RiskRepository(t, func(t *testing.T) (domain.RiskRepository, domain.TransactionRepository) {
+		db := migrationtest.NewDB(t)
+		return riskRepo.NewRiskRepository(db), transactionRepo.NewTransactionRepository(db)
+	})
+}
@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const ruleColumns = "id, merchant_id, name, type, action, field, threshold, window_seconds, entries, enabled, created_at"
+
+// velocityColumns maps the fields velocity rules count by to the
+// transaction columns holding them.
+var velocityColumns = map[string]string{
+	domain.RiskFieldCard:     "card_fingerprint",
+	domain.RiskFieldCustomer: "customer_id",
+	domain.RiskFieldIP:       "ip_address",
+}
+
+type sqliteRiskRepo struct {
+	DB *sql.DB
+}
+
+func NewRiskRepository(db *sql.DB) domain.RiskRepository {
+	return &sqliteRiskRepo{
+		DB: db,
+	}
+}
+
+func (rr *sqliteRiskRepo) Store(ctx context.Context, r *domain.RiskRule) error {
+	query := "INSERT INTO risk_rules (merchant_id, name, type, action, field, threshold, window_seconds, entries, enabled, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := rr.exec(ctx, query, r.MerchantID, r.Name, r.Type, r.Action, r.Field, r.Threshold, r.WindowSeconds, strings.Join(r.Values, ","), r.Enabled, r.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	r.ID = lastID
+	return nil
+}
+
+func (rr *sqliteRiskRepo) GetByID(ctx context.Context, id int64) (domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE id=? LIMIT 1"
+
+	data, err := scanRule(rr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.RiskRule{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.RiskRule{}, err
+	}
+
+	return data, nil
+}
+
+func (rr *sqliteRiskRepo) Fetch(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE merchant_id=? ORDER BY id"
+
+	return rr.fetch(ctx, query, merchantID)
+}
+
+// FetchEnabled lists the rules that apply to a transaction: the merchant's
+// own and, for a child merchant, its parent's.
+func (rr *sqliteRiskRepo) FetchEnabled(ctx context.Context, merchantID int64, parentMerchantID int64) ([]domain.RiskRule, error) {
+	query := "SELECT " + ruleColumns + " FROM risk_rules WHERE merchant_id IN (?, ?) AND enabled=1 ORDER BY id"
+
+	return rr.fetch(ctx, query, merchantID, parentMerchantID)
+}
+
+func (rr *sqliteRiskRepo) Update(ctx context.Context, r *domain.RiskRule) error {
+	query := "UPDATE risk_rules SET name=?, type=?, action=?, field=?, threshold=?, window_seconds=?, entries=?, enabled=? WHERE id=?"
+
+	_, err := rr.exec(ctx, query, r.Name, r.Type, r.Action, r.Field, r.Threshold, r.WindowSeconds, strings.Join(r.Values, ","), r.Enabled, r.ID)
+	return err
+}
+
+func (rr *sqliteRiskRepo) Delete(ctx context.Context, id int64) error {
+	_, err := rr.exec(ctx, "DELETE FROM risk_rules WHERE id=?", id)
+	return err
+}
+
+// CountSince counts a merchant's transactions sharing the given card,
+// customer or IP made at or after since.
+func (rr *sqliteRiskRepo) CountSince(ctx context.Context, merchantID int64, field string, value string, since time.Time) (int64, error) {
+	column, ok := velocityColumns[field]
+	if !ok {
+		return 0, fmt.Errorf("risk: cannot count by %q", field)
+	}
+	query := "SELECT COUNT(*) FROM transactions WHERE merchant_id=? AND " + column + "=? AND created_at >= ?"
+
+	var count int64
+	err := rr.DB.QueryRowContext(ctx, query, merchantID, value, since).Scan(&count)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (rr *sqliteRiskRepo) fetch(ctx context.Context, query string, args ...interface{}) ([]domain.RiskRule, error) {
+	rows, err := rr.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.RiskRule{}
+	for rows.Next() {
+		data, err := scanRule(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (rr *sqliteRiskRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := rr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanRule reads a rule row. Blocklist values are stored comma separated.
+func scanRule(row scanner) (domain.RiskRule, error) {
+	data := domain.RiskRule{}
+	var entries string
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Name,
+		&data.Type,
+		&data.Action,
+		&data.Field,
+		&data.Threshold,
+		&data.WindowSeconds,
+		&entries,
+		&data.Enabled,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		return domain.RiskRule{}, err
+	}
+
+	data.Values = []string{}
+	if entries != "" {
+		data.Values = strings.Split(entries, ",")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	riskRepo "github.com/hezbymuhammad/payment-gateway/risk/repository/sqlite"
+)
+
+func TestFetchEnabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	now := time.Now().UTC()
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "name", "type", "action", "field", "threshold", "window_seconds", "entries", "enabled", "created_at"}).
+		AddRow(1, 6, "blocked bins", "blocklist", "block", "bin", 0, 0, "5555,4000", true, now).
+		AddRow(2, 1, "big ticket", "amount", "review", "", 5000000, 0, "", true, now)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, name, type, action, field, threshold, window_seconds, entries, enabled, created_at FROM risk_rules WHERE merchant_id IN (?, ?) AND enabled=1 ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(6, 1).WillReturnRows(rows)
+	rr := riskRepo.NewRiskRepository(db)
+
+	res, err := rr.FetchEnabled(context.TODO(), 6, 1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, []string{"5555", "4000"}, res[0].Values)
+	assert.Equal(t, []string{}, res[1].Values)
+}
+
+func TestCountSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	since := time.Now().UTC().Add(-time.Hour)
+	query := regexp.QuoteMeta("SELECT COUNT(*) FROM transactions WHERE merchant_id=? AND ip_address=? AND created_at >= ?")
+	mock.ExpectQuery(query).WithArgs(6, "198.51.100.9", since).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	rr := riskRepo.NewRiskRepository(db)
+
+	count, err := rr.CountSince(context.TODO(), 6, domain.RiskFieldIP, "198.51.100.9", since)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestCountSinceUnknownField(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	rr := riskRepo.NewRiskRepository(db)
+
+	_, err = rr.CountSince(context.TODO(), 6, domain.RiskFieldBIN, "411111", time.Now())
+	assert.Error(t, err)
+}
+
+func TestGetByIDNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	rr := riskRepo.NewRiskRepository(db)
+
+	_, err = rr.GetByID(context.TODO(), 2)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
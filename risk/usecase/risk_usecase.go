@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// severity orders rule actions; the decision is the most severe action of
+// all triggered rules.
+var severity = map[string]int{
+	domain.RiskAllow:  0,
+	domain.RiskReview: 1,
+	domain.RiskBlock:  2,
+}
+
+type riskUsecase struct {
+	riskRepo     domain.RiskRepository
+	merchantRepo domain.MerchantRepository
+}
+
+func NewRiskUsecase(rr domain.RiskRepository, mr domain.MerchantRepository) domain.RiskUsecase {
+	return &riskUsecase{
+		riskRepo:     rr,
+		merchantRepo: mr,
+	}
+}
+
+func (ru *riskUsecase) StoreRule(ctx context.Context, r *domain.RiskRule) error {
+	_, err := ru.merchantRepo.GetByID(ctx, r.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	normalize(r)
+	r.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return ru.riskRepo.Store(ctx, r)
+}
+
+func (ru *riskUsecase) FetchRules(ctx context.Context, merchantID int64) ([]domain.RiskRule, error) {
+	return ru.riskRepo.Fetch(ctx, merchantID)
+}
+
+// UpdateRule replaces a rule's settings. A rule cannot be moved to another
+// merchant.
+func (ru *riskUsecase) UpdateRule(ctx context.Context, r *domain.RiskRule) error {
+	existing, err := ru.rule(ctx, r.MerchantID, r.ID)
+	if err != nil {
+		return err
+	}
+
+	normalize(r)
+	r.CreatedAt = existing.CreatedAt
+
+	return ru.riskRepo.Update(ctx, r)
+}
+
+func (ru *riskUsecase) DeleteRule(ctx context.Context, merchantID int64, id int64) error {
+	_, err := ru.rule(ctx, merchantID, id)
+	if err != nil {
+		return err
+	}
+
+	return ru.riskRepo.Delete(ctx, id)
+}
+
+// Evaluate runs the enabled rules of the transaction's merchant, and of its
+// parent, against a transaction about to be stored. It records the decision
+// and the names of the rules that triggered on the transaction; acting on
+// the decision is up to the caller.
+func (ru *riskUsecase) Evaluate(ctx context.Context, t *domain.Transaction, bin string) error {
+	rules, err := ru.riskRepo.FetchEnabled(ctx, t.MerchantID, t.ParentMerchantID)
+	if err != nil {
+		return err
+	}
+
+	t.RiskDecision = domain.RiskAllow
+	t.RiskRules = nil
+	now := time.Now().UTC()
+	for _, r := range rules {
+		hit, err := ru.triggers(ctx, r, t, bin, now)
+		if err != nil {
+			return err
+		}
+		if !hit {
+			continue
+		}
+
+		t.RiskRules = append(t.RiskRules, r.Name)
+		if severity[r.Action] > severity[t.RiskDecision] {
+			t.RiskDecision = r.Action
+		}
+	}
+
+	return nil
+}
+
+func (ru *riskUsecase) triggers(ctx context.Context, r domain.RiskRule, t *domain.Transaction, bin string, now time.Time) (bool, error) {
+	switch r.Type {
+	case domain.RiskRuleVelocity:
+		value := fieldValue(r.Field, t)
+		if value == "" {
+			return false, nil
+		}
+		since := now.Add(-time.Duration(r.WindowSeconds) * time.Second)
+		count, err := ru.riskRepo.CountSince(ctx, t.MerchantID, r.Field, value, since)
+		if err != nil {
+			return false, err
+		}
+		return count >= r.Threshold, nil
+	case domain.RiskRuleAmount:
+		return t.SettlementAmount > r.Threshold, nil
+	case domain.RiskRuleCountryMismatch:
+		return t.IPCountry != "" && t.BillingCountry != "" && t.IPCountry != t.BillingCountry, nil
+	case domain.RiskRuleBlocklist:
+		return listed(r, t, bin), nil
+	}
+
+	return false, nil
+}
+
+// listed reports whether the transaction matches one of a blocklist's
+// values. BINs match by prefix, cards match either the token or the card's
+// fingerprint, and countries match either the IP or the billing country.
+func listed(r domain.RiskRule, t *domain.Transaction, bin string) bool {
+	for _, v := range r.Values {
+		switch r.Field {
+		case domain.RiskFieldBIN:
+			if bin != "" && strings.HasPrefix(bin, v) {
+				return true
+			}
+		case domain.RiskFieldCountry:
+			if v == t.IPCountry || v == t.BillingCountry {
+				return true
+			}
+		case domain.RiskFieldCard:
+			if v == t.CardToken || (t.CardFingerprint != "" && v == t.CardFingerprint) {
+				return true
+			}
+		default:
+			if value := fieldValue(r.Field, t); value != "" && value == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func fieldValue(field string, t *domain.Transaction) string {
+	switch field {
+	case domain.RiskFieldCard:
+		// Tokens are issued per save, so the same card is counted by its
+		// fingerprint.
+		return t.CardFingerprint
+	case domain.RiskFieldCustomer:
+		if t.CustomerID == 0 {
+			return ""
+		}
+		return strconv.FormatInt(t.CustomerID, 10)
+	case domain.RiskFieldIP:
+		return t.IPAddress
+	}
+
+	return ""
+}
+
+// rule loads a rule, treating one that belongs to another merchant as
+// missing.
+func (ru *riskUsecase) rule(ctx context.Context, merchantID int64, id int64) (domain.RiskRule, error) {
+	r, err := ru.riskRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.RiskRule{}, err
+	}
+	if r.MerchantID != merchantID {
+		return domain.RiskRule{}, domain.ErrNotFound
+	}
+
+	return r, nil
+}
+
+func normalize(r *domain.RiskRule) {
+	if r.Values == nil {
+		r.Values = []string{}
+	}
+	if r.Field == domain.RiskFieldCountry {
+		for i, v := range r.Values {
+			r.Values[i] = strings.ToUpper(v)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	riskUsecase "github.com/hezbymuhammad/payment-gateway/risk/usecase"
+)
+
+func TestEvaluate(t *testing.T) {
+	mockRiskRepo := new(mocks.RiskRepository)
+	rules := []domain.RiskRule{
+		{ID: 1, MerchantID: 6, Name: "card velocity", Type: domain.RiskRuleVelocity, Action: domain.RiskReview, Field: domain.RiskFieldCard, Threshold: 3, WindowSeconds: 3600},
+		{ID: 2, MerchantID: 6, Name: "big ticket", Type: domain.RiskRuleAmount, Action: domain.RiskReview, Threshold: 5000000},
+		{ID: 3, MerchantID: 6, Name: "country mismatch", Type: domain.RiskRuleCountryMismatch, Action: domain.RiskAllow},
+		{ID: 4, MerchantID: 1, Name: "blocked bins", Type: domain.RiskRuleBlocklist, Action: domain.RiskBlock, Field: domain.RiskFieldBIN, Values: []string{"5555"}},
+	}
+	mockRiskRepo.On("FetchEnabled", mock.Anything, int64(6), int64(1)).Return(rules, nil).Once()
+	mockRiskRepo.On("CountSince", mock.Anything, int64(6), domain.RiskFieldCard, "fp_1", mock.Anything).Return(int64(3), nil).Once()
+	u := riskUsecase.NewRiskUsecase(mockRiskRepo, new(mocks.MerchantRepository))
+
+	tx := domain.Transaction{MerchantID: 6, ParentMerchantID: 1, CardToken: "tok_1", CardFingerprint: "fp_1", SettlementAmount: 100000, IPCountry: "SG", BillingCountry: "ID"}
+	err := u.Evaluate(context.TODO(), &tx, "411111")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RiskReview, tx.RiskDecision)
+	assert.Equal(t, []string{"card velocity", "country mismatch"}, tx.RiskRules)
+}
+
+func TestEvaluateBlockWins(t *testing.T) {
+	mockRiskRepo := new(mocks.RiskRepository)
+	rules := []domain.RiskRule{
+		{ID: 1, MerchantID: 6, Name: "blocked ips", Type: domain.RiskRuleBlocklist, Action: domain.RiskBlock, Field: domain.RiskFieldIP, Values: []string{"198.51.100.9"}},
+		{ID: 2, MerchantID: 6, Name: "big ticket", Type: domain.RiskRuleAmount, Action: domain.RiskReview, Threshold: 5000000},
+	}
+	mockRiskRepo.On("FetchEnabled", mock.Anything, int64(6), int64(6)).Return(rules, nil).Once()
+	u := riskUsecase.NewRiskUsecase(mockRiskRepo, new(mocks.MerchantRepository))
+
+	tx := domain.Transaction{MerchantID: 6, ParentMerchantID: 6, SettlementAmount: 9000000, IPAddress: "198.51.100.9"}
+	err := u.Evaluate(context.TODO(), &tx, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RiskBlock, tx.RiskDecision)
+	assert.Equal(t, []string{"blocked ips", "big ticket"}, tx.RiskRules)
+}
+
+func TestEvaluateVelocityWithoutValue(t *testing.T) {
+	mockRiskRepo := new(mocks.RiskRepository)
+	rules := []domain.RiskRule{
+		{ID: 1, MerchantID: 6, Name: "customer velocity", Type: domain.RiskRuleVelocity, Action: domain.RiskBlock, Field: domain.RiskFieldCustomer, Threshold: 1, WindowSeconds: 60},
+	}
+	mockRiskRepo.On("FetchEnabled", mock.Anything, int64(6), int64(6)).Return(rules, nil).Once()
+	u := riskUsecase.NewRiskUsecase(mockRiskRepo, new(mocks.MerchantRepository))
+
+	tx := domain.Transaction{MerchantID: 6, ParentMerchantID: 6}
+	err := u.Evaluate(context.TODO(), &tx, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.RiskAllow, tx.RiskDecision)
+	mockRiskRepo.AssertNotCalled(t, "CountSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateRuleOfAnotherMerchant(t *testing.T) {
+	mockRiskRepo := new(mocks.RiskRepository)
+	mockRiskRepo.On("GetByID", mock.Anything, int64(2)).Return(domain.RiskRule{ID: 2, MerchantID: 7}, nil).Once()
+	u := riskUsecase.NewRiskUsecase(mockRiskRepo, new(mocks.MerchantRepository))
+
+	err := u.UpdateRule(context.TODO(), &domain.RiskRule{ID: 2, MerchantID: 6, Name: "big ticket", Type: domain.RiskRuleAmount, Action: domain.RiskReview, Threshold: 1})
+
+	assert.Equal(t, domain.ErrNotFound, err)
+	mockRiskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestStoreRule(t *testing.T) {
+	mockRiskRepo := new(mocks.RiskRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6}, nil).Once()
+	mockRiskRepo.On("Store", mock.Anything, mock.MatchedBy(func(r *domain.RiskRule) bool {
+		return r.Values[0] == "KP" && !r.CreatedAt.IsZero()
+	})).Return(nil).Once()
+	u := riskUsecase.NewRiskUsecase(mockRiskRepo, mockMerchantRepo)
+
+	err := u.StoreRule(context.TODO(), &domain.RiskRule{MerchantID: 6, Name: "blocked countries", Type: domain.RiskRuleBlocklist, Action: domain.RiskBlock, Field: domain.RiskFieldCountry, Values: []string{"kp"}})
+
+	assert.NoError(t, err)
+	mockRiskRepo.AssertExpectations(t)
+}
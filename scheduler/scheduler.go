@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a periodic task such as subscription billing. Jobs must be
+// idempotent, so several instances or a restart mid-run are harmless.
+type Job func(ctx context.Context, now time.Time) error
+
+// Scheduler runs a job on a fixed interval.
+type Scheduler struct {
+	job      Job
+	interval time.Duration
+}
+
+func NewScheduler(job Job, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		job:      job,
+		interval: interval,
+	}
+}
+
+// Start runs the job once immediately and then on every tick until ctx is
+// done.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	err := s.job(ctx, time.Now())
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,35 @@
+package scheduler_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/scheduler"
+)
+
+func TestStartStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	runs := 0
+	s := scheduler.NewScheduler(func(ctx context.Context, now time.Time) error {
+		runs++
+		cancel()
+		return fmt.Errorf("some error")
+	}, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	assert.Equal(t, 1, runs)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type ScreeningHandler struct {
+	Usecase domain.ScreeningUsecase
+}
+
+func NewScreeningHandler(e *echo.Echo, u domain.ScreeningUsecase) *ScreeningHandler {
+	handler := &ScreeningHandler{
+		Usecase: u,
+	}
+
+	e.GET("/merchants/:id/screenings", handler.Fetch)
+
+	return handler
+}
+
+// Fetch lists a merchant's screenings with their hits, latest first.
+func (h *ScreeningHandler) Fetch(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.Fetch(ctx, id)
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,63 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	screeningHttp "github.com/hezbymuhammad/payment-gateway/screening/delivery/http"
+)
+
+func TestFetch(t *testing.T) {
+	mockUCase := new(mocks.ScreeningUsecase)
+	mockUCase.On("Fetch", mock.Anything, int64(11)).Return([]domain.Screening{
+		{ID: 4, MerchantID: 11, Result: domain.ScreeningMatch, Score: 96, Hits: []domain.ScreeningHit{{List: "ofac", EntryID: "12", Subject: "Ahmad Yusuf", Score: 96}}},
+	}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/merchants/11/screenings", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/merchants/:id/screenings")
+	c.SetParamNames("id")
+	c.SetParamValues("11")
+	handler := screeningHttp.ScreeningHandler{
+		Usecase: mockUCase,
+	}
+	err = handler.Fetch(c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"subject":"Ahmad Yusuf"`)
+}
+
+func TestFetchUnknownMerchant(t *testing.T) {
+	mockUCase := new(mocks.ScreeningUsecase)
+	mockUCase.On("Fetch", mock.Anything, int64(99)).Return(nil, domain.ErrNotFound).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/merchants/99/screenings", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/merchants/:id/screenings")
+	c.SetParamNames("id")
+	c.SetParamValues("99")
+	handler := screeningHttp.ScreeningHandler{
+		Usecase: mockUCase,
+	}
+	err = handler.Fetch(c)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
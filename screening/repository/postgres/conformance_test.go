@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	screeningRepo "github.com/hezbymuhammad/payment-gateway/screening/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.ScreeningRepository(t, func(t *testing.T) domain.ScreeningRepository {
+		return screeningRepo.NewScreeningRepository(migrationtest.NewPostgresDB(t))
+	})
+}
@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const screeningColumns = "id, merchant_id, result, score, list_version, created_at"
+
+type sqliteScreeningRepo struct {
+	DB *sql.DB
+}
+
+func NewScreeningRepository(db *sql.DB) domain.ScreeningRepository {
+	return &sqliteScreeningRepo{
+		DB: db,
+	}
+}
+
+// Store inserts the screening together with its hits.
+func (sr *sqliteScreeningRepo) Store(ctx context.Context, s *domain.Screening) error {
+	tx, err := sr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO screenings (merchant_id, result, score, list_version, created_at) VALUES (?, ?, ?, ?, ?)"
+	res, err := tx.ExecContext(ctx, query, s.MerchantID, s.Result, s.Score, s.ListVersion, s.CreatedAt)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "INSERT INTO screening_hits (screening_id, list, entry_id, entry_name, subject, score) VALUES (?, ?, ?, ?, ?, ?)"
+	for _, h := range s.Hits {
+		_, err = tx.ExecContext(ctx, query, lastID, h.List, h.EntryID, h.EntryName, h.Subject, h.Score)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	s.ID = lastID
+	return nil
+}
+
+// Fetch lists the merchant's screenings, latest first.
+func (sr *sqliteScreeningRepo) Fetch(ctx context.Context, merchantID int64) ([]domain.Screening, error) {
+	query := "SELECT " + screeningColumns + " FROM screenings WHERE merchant_id=? ORDER BY id DESC"
+
+	rows, err := sr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Screening{}
+	for rows.Next() {
+		data, err := scanScreening(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range result {
+		result[i].Hits, err = sr.fetchHits(ctx, result[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (sr *sqliteScreeningRepo) Latest(ctx context.Context, merchantID int64) (domain.Screening, error) {
+	query := "SELECT " + screeningColumns + " FROM screenings WHERE merchant_id=? ORDER BY id DESC LIMIT 1"
+
+	data, err := scanScreening(sr.DB.QueryRowContext(ctx, query, merchantID))
+	if err == sql.ErrNoRows {
+		return domain.Screening{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Screening{}, err
+	}
+
+	data.Hits, err = sr.fetchHits(ctx, data.ID)
+	if err != nil {
+		return domain.Screening{}, err
+	}
+
+	return data, nil
+}
+
+func (sr *sqliteScreeningRepo) fetchHits(ctx context.Context, screeningID int64) ([]domain.ScreeningHit, error) {
+	query := "SELECT list, entry_id, entry_name, subject, score FROM screening_hits WHERE screening_id=? ORDER BY score DESC"
+
+	rows, err := sr.DB.QueryContext(ctx, query, screeningID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.ScreeningHit{}
+	for rows.Next() {
+		data := domain.ScreeningHit{}
+		err := rows.Scan(&data.List, &data.EntryID, &data.EntryName, &data.Subject, &data.Score)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanScreening(row scanner) (domain.Screening, error) {
+	data := domain.Screening{}
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.Result,
+		&data.Score,
+		&data.ListVersion,
+		&data.CreatedAt,
+	)
+
+	return data, err
+}
@@ -0,0 +1,90 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	screeningRepo "github.com/hezbymuhammad/payment-gateway/screening/repository/sqlite"
+)
+
+var screeningColumns = []string{"id", "merchant_id", "result", "score", "list_version", "created_at"}
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	data := &domain.Screening{
+		MerchantID:  11,
+		Result:      domain.ScreeningMatch,
+		Score:       96,
+		Hits:        []domain.ScreeningHit{{List: "ofac", EntryID: "12", EntryName: "YUSUF, Ahmed", Subject: "Ahmad Yusuf", Score: 96}},
+		ListVersion: "3f2a",
+		CreatedAt:   createdAt,
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO screenings (merchant_id, result, score, list_version, created_at) VALUES (?, ?, ?, ?, ?)")).
+		WithArgs(11, domain.ScreeningMatch, 96, "3f2a", createdAt).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO screening_hits (screening_id, list, entry_id, entry_name, subject, score) VALUES (?, ?, ?, ?, ?, ?)")).
+		WithArgs(4, "ofac", "12", "YUSUF, Ahmed", "Ahmad Yusuf", 96).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	sr := screeningRepo.NewScreeningRepository(db)
+
+	err = sr.Store(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), data.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLatestNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, merchant_id, result, score, list_version, created_at FROM screenings WHERE merchant_id=? ORDER BY id DESC LIMIT 1")).
+		WithArgs(11).
+		WillReturnRows(sqlmock.NewRows(screeningColumns))
+	sr := screeningRepo.NewScreeningRepository(db)
+
+	_, err = sr.Latest(context.TODO(), 11)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestLatest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, merchant_id, result, score, list_version, created_at FROM screenings WHERE merchant_id=? ORDER BY id DESC LIMIT 1")).
+		WithArgs(11).
+		WillReturnRows(sqlmock.NewRows(screeningColumns).AddRow(4, 11, domain.ScreeningMatch, 96, "3f2a", createdAt))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT list, entry_id, entry_name, subject, score FROM screening_hits WHERE screening_id=? ORDER BY score DESC")).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"list", "entry_id", "entry_name", "subject", "score"}).AddRow("ofac", "12", "YUSUF, Ahmed", "Ahmad Yusuf", 96))
+	sr := screeningRepo.NewScreeningRepository(db)
+
+	res, err := sr.Latest(context.TODO(), 11)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Screening{
+		ID:          4,
+		MerchantID:  11,
+		Result:      domain.ScreeningMatch,
+		Score:       96,
+		Hits:        []domain.ScreeningHit{{List: "ofac", EntryID: "12", EntryName: "YUSUF, Ahmed", Subject: "Ahmad Yusuf", Score: 96}},
+		ListVersion: "3f2a",
+		CreatedAt:   createdAt,
+	}, res)
+}
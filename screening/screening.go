@@ -0,0 +1,246 @@
+// Package screening matches names against sanctions lists and blocklists.
+// Lists are CSV files in the OFAC SDN layout: the first column is the
+// entry's ID and the second its name. Other columns are ignored.
+package screening
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// noise lists words that say what kind of entity a name belongs to rather
+// than which one, so "PT Kopi Joss" and "KOPI JOSS LTD" compare equal.
+var noise = map[string]bool{
+	"AND":         true,
+	"CO":          true,
+	"COMPANY":     true,
+	"CORP":        true,
+	"CORPORATION": true,
+	"CV":          true,
+	"INC":         true,
+	"LIMITED":     true,
+	"LLC":         true,
+	"LTD":         true,
+	"OF":          true,
+	"PT":          true,
+	"TBK":         true,
+	"THE":         true,
+}
+
+// LoadList reads a list file, naming its entries after list. A header row
+// starting with ent_num is skipped, as are rows without a name.
+func LoadList(list string, path string) ([]domain.ListEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	entries := []domain.ListEntry{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 || strings.EqualFold(record[0], "ent_num") {
+			continue
+		}
+		name := strings.TrimSpace(record[1])
+		if name == "" || name == "-0-" {
+			continue
+		}
+		entries = append(entries, domain.ListEntry{List: list, ID: strings.TrimSpace(record[0]), Name: name})
+	}
+
+	return entries, nil
+}
+
+// Version fingerprints the contents of list files so that a change to any
+// of them can be told apart from a reload of the same lists.
+func Version(paths map[string]string) (string, error) {
+	lists := make([]string, 0, len(paths))
+	for list := range paths {
+		lists = append(lists, list)
+	}
+	sort.Strings(lists)
+
+	h := sha256.New()
+	for _, list := range lists {
+		b, err := os.ReadFile(paths[list])
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(list))
+		h.Write([]byte{0})
+		h.Write(b)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// Match screens each subject against the entries and returns the hits
+// scoring at least threshold, best first.
+func Match(subjects []string, entries []domain.ListEntry, threshold int) []domain.ScreeningHit {
+	hits := []domain.ScreeningHit{}
+	for _, subject := range subjects {
+		tokens := normalize(subject)
+		if len(tokens) == 0 {
+			continue
+		}
+		for _, e := range entries {
+			score := score(tokens, normalize(e.Name))
+			if score >= threshold {
+				hits = append(hits, domain.ScreeningHit{
+					List:      e.List,
+					EntryID:   e.ID,
+					EntryName: e.Name,
+					Subject:   subject,
+					Score:     score,
+				})
+			}
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits
+}
+
+// Score rates how alike two names are from 0 to 100.
+func Score(a string, b string) int {
+	return score(normalize(a), normalize(b))
+}
+
+// score takes the better of two comparisons: the names' words in sorted
+// order as one string, which copes with misspellings, and word by word,
+// which copes with reordering and an extra or missing middle name.
+func score(a []string, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	sa := append([]string(nil), a...)
+	sb := append([]string(nil), b...)
+	sort.Strings(sa)
+	sort.Strings(sb)
+	whole := jaroWinkler(strings.Join(sa, " "), strings.Join(sb, " "))
+
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	var sum float64
+	for _, s := range short {
+		best := 0.0
+		for _, l := range long {
+			best = math.Max(best, jaroWinkler(s, l))
+		}
+		sum += best
+	}
+	words := sum / (float64(len(short)+len(long)) / 2)
+
+	return int(math.Round(100 * math.Max(whole, words)))
+}
+
+// normalize upper cases a name and splits it into words, dropping
+// punctuation and noise words.
+func normalize(name string) []string {
+	fields := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := []string{}
+	for _, f := range fields {
+		if !noise[f] {
+			tokens = append(tokens, f)
+		}
+	}
+
+	return tokens
+}
+
+// jaroWinkler is the Jaro-Winkler similarity of two strings, from 0 to 1.
+func jaroWinkler(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	window := len(ra)
+	if len(rb) > window {
+		window = len(rb)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(rb) {
+			hi = len(rb)
+		}
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,69 @@
+package screening_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/screening"
+)
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, 100, screening.Score("PT Kopi Joss", "KOPI JOSS LTD"))
+	assert.Equal(t, 96, screening.Score("Ahmad Yusuf", "YUSUF, Ahmed"))
+	assert.Equal(t, 86, screening.Score("John Smith", "SMITH, John Michael"))
+	assert.Equal(t, 45, screening.Score("Acme Coffee", "BANK MELLAT"))
+	assert.Equal(t, 0, screening.Score("PT", "BANK MELLAT"))
+}
+
+func TestLoadList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	err := os.WriteFile(path, []byte(`ent_num,SDN_Name,SDN_Type,Program
+36,"AEROCARIBBEAN AIRLINES",-0-,"CUBA"
+173,"ANGLO-CARIBBEAN CO., LTD.",-0-,"CUBA"
+306,-0-,-0-,"CUBA"
+`), 0644)
+	assert.NoError(t, err)
+
+	res, err := screening.LoadList("ofac", path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ListEntry{
+		{List: "ofac", ID: "36", Name: "AEROCARIBBEAN AIRLINES"},
+		{List: "ofac", ID: "173", Name: "ANGLO-CARIBBEAN CO., LTD."},
+	}, res)
+}
+
+func TestMatch(t *testing.T) {
+	entries := []domain.ListEntry{
+		{List: "ofac", ID: "36", Name: "AEROCARIBBEAN AIRLINES"},
+		{List: "internal", ID: "B-1", Name: "YUSUF, Ahmed"},
+	}
+
+	res := screening.Match([]string{"Aero Caribbean Airlines", "Budi Santoso", "Ahmad Yusuf"}, entries, 85)
+
+	assert.Equal(t, []domain.ScreeningHit{
+		{List: "internal", EntryID: "B-1", EntryName: "YUSUF, Ahmed", Subject: "Ahmad Yusuf", Score: 96},
+		{List: "ofac", EntryID: "36", EntryName: "AEROCARIBBEAN AIRLINES", Subject: "Aero Caribbean Airlines", Score: 92},
+	}, res)
+}
+
+func TestVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sdn.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("36,AEROCARIBBEAN AIRLINES\n"), 0644))
+
+	v1, err := screening.Version(map[string]string{"ofac": path})
+	assert.NoError(t, err)
+	v2, err := screening.Version(map[string]string{"ofac": path})
+	assert.NoError(t, err)
+	assert.Equal(t, v1, v2)
+
+	assert.NoError(t, os.WriteFile(path, []byte("36,AEROCARIBBEAN AIRLINES\n173,ANGLO-CARIBBEAN CO., LTD.\n"), 0644))
+	v3, err := screening.Version(map[string]string{"ofac": path})
+	assert.NoError(t, err)
+	assert.NotEqual(t, v1, v3)
+}
@@ -0,0 +1,229 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/screening"
+)
+
+// Config holds the screening settings that come from config.json. Lists
+// maps each list's name to its file; a hit needs a score of at least
+// Threshold.
+type Config struct {
+	Lists     map[string]string
+	Threshold int
+}
+
+// screenAttempts is how many times RunRefresh screens a merchant that keeps
+// changing under it before leaving it for the next run.
+const screenAttempts = 3
+
+type screeningUsecase struct {
+	screeningRepo domain.ScreeningRepository
+	merchantRepo  domain.MerchantRepository
+	cfg           Config
+
+	mu      sync.RWMutex
+	entries []domain.ListEntry
+	version string
+}
+
+func NewScreeningUsecase(sr domain.ScreeningRepository, mr domain.MerchantRepository, cfg Config) domain.ScreeningUsecase {
+	return &screeningUsecase{
+		screeningRepo: sr,
+		merchantRepo:  mr,
+		cfg:           cfg,
+	}
+}
+
+// Screen runs the merchant's name and owners against the lists, records the
+// result and updates the merchant. A hit the merchant's previous screening
+// did not have holds it for review, even if it had been approved since.
+// Rejected merchants stay rejected.
+func (su *screeningUsecase) Screen(ctx context.Context, m *domain.Merchant) error {
+	entries, version, err := su.lists()
+	if err != nil {
+		return err
+	}
+
+	previous, err := su.screeningRepo.Latest(ctx, m.ID)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+
+	subjects := []string{m.Name}
+	if m.LegalName != "" && m.LegalName != m.Name {
+		subjects = append(subjects, m.LegalName)
+	}
+	for _, o := range m.Owners {
+		subjects = append(subjects, o.Name)
+	}
+	s := domain.Screening{
+		MerchantID:  m.ID,
+		Result:      domain.ScreeningClear,
+		Hits:        screening.Match(subjects, entries, su.cfg.Threshold),
+		ListVersion: version,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	if len(s.Hits) > 0 {
+		s.Result = domain.ScreeningMatch
+		s.Score = s.Hits[0].Score
+	}
+
+	m.ScreeningResult = s.Result
+	m.ScreeningScore = s.Score
+	if m.Status != domain.MerchantRejected && hasNewHits(s.Hits, previous.Hits) {
+		m.Status = domain.MerchantPendingReview
+	}
+
+	// The merchant goes first: its hits only count as seen once the
+	// screening is stored, so a merchant update that loses to another
+	// leaves them new for the next screening to hold the merchant on.
+	err = su.merchantRepo.Update(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	return su.screeningRepo.Store(ctx, &s)
+}
+
+func (su *screeningUsecase) Fetch(ctx context.Context, merchantID int64) ([]domain.Screening, error) {
+	_, err := su.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return su.screeningRepo.Fetch(ctx, merchantID)
+}
+
+// RunRefresh reloads the list files and screens again every submitted
+// merchant that was last screened against other lists, or never was. It is
+// meant to be run by the scheduler, which makes list updates take effect
+// without a restart.
+func (su *screeningUsecase) RunRefresh(ctx context.Context, now time.Time) error {
+	version, err := su.reload()
+	if err != nil {
+		return err
+	}
+
+	merchants, err := su.merchantRepo.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for i := range merchants {
+		m := &merchants[i]
+		if m.Status == domain.MerchantDraft || m.Status == domain.MerchantRejected {
+			continue
+		}
+		latest, err := su.screeningRepo.Latest(ctx, m.ID)
+		if err == nil && latest.ListVersion == version {
+			continue
+		}
+		if err != nil && err != domain.ErrNotFound {
+			lastErr = err
+			continue
+		}
+
+		err = su.rescreen(ctx, m)
+		if err != nil {
+			log.Printf("merchant %d: screening: %v", m.ID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// rescreen screens a merchant for RunRefresh. A merchant changed since it
+// was fetched, for example by a reviewer, is read again and screened again,
+// a few times before giving up until the next run.
+func (su *screeningUsecase) rescreen(ctx context.Context, m *domain.Merchant) error {
+	for attempt := 1; ; attempt++ {
+		err := su.Screen(ctx, m)
+		if err != domain.ErrStaleVersion || attempt == screenAttempts {
+			return err
+		}
+
+		*m, err = su.merchantRepo.GetByID(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// lists returns the loaded lists, loading them the first time.
+func (su *screeningUsecase) lists() ([]domain.ListEntry, string, error) {
+	su.mu.RLock()
+	entries, version := su.entries, su.version
+	su.mu.RUnlock()
+	if version != "" {
+		return entries, version, nil
+	}
+
+	_, err := su.reload()
+	if err != nil {
+		return nil, "", err
+	}
+
+	su.mu.RLock()
+	defer su.mu.RUnlock()
+	return su.entries, su.version, nil
+}
+
+// reload reads the list files again if they changed since they were last
+// loaded and returns their version.
+func (su *screeningUsecase) reload() (string, error) {
+	version, err := screening.Version(su.cfg.Lists)
+	if err != nil {
+		return "", err
+	}
+
+	su.mu.RLock()
+	current := su.version
+	su.mu.RUnlock()
+	if version == current {
+		return version, nil
+	}
+
+	entries := []domain.ListEntry{}
+	for list, path := range su.cfg.Lists {
+		e, err := screening.LoadList(list, path)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, e...)
+	}
+
+	su.mu.Lock()
+	su.entries, su.version = entries, version
+	su.mu.Unlock()
+
+	log.Printf("screening lists loaded: %d entries, version %s", len(entries), version)
+	return version, nil
+}
+
+// hasNewHits reports whether any hit is for an entry and subject that the
+// previous screening did not turn up.
+func hasNewHits(hits []domain.ScreeningHit, previous []domain.ScreeningHit) bool {
+	seen := map[domain.ScreeningHit]bool{}
+	for _, h := range previous {
+		seen[key(h)] = true
+	}
+	for _, h := range hits {
+		if !seen[key(h)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func key(h domain.ScreeningHit) domain.ScreeningHit {
+	return domain.ScreeningHit{List: h.List, EntryID: h.EntryID, Subject: h.Subject}
+}
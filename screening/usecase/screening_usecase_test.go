@@ -0,0 +1,188 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	screeningUsecase "github.com/hezbymuhammad/payment-gateway/screening/usecase"
+)
+
+// lists writes a sanctions list file and returns a config screening against
+// it.
+func lists(t *testing.T, content string) screeningUsecase.Config {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	return screeningUsecase.Config{Lists: map[string]string{"ofac": path}, Threshold: 85}
+}
+
+var mellat = domain.ScreeningHit{List: "ofac", EntryID: "7", EntryName: "BANK MELLAT", Subject: "Bank Mellat", Score: 100}
+
+func TestScreenNewMerchantClear(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	m := domain.Merchant{ID: 11, Name: "Kopi Joss", Owners: []domain.Owner{{Name: "Budi Santoso", Share: 100}}, Status: domain.MerchantSubmitted}
+
+	mockScreeningRepo.On("Latest", mock.Anything, int64(11)).Return(domain.Screening{}, domain.ErrNotFound).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.Screening) bool {
+		return s.MerchantID == 11 && s.Result == domain.ScreeningClear && len(s.Hits) == 0 && s.ListVersion != ""
+	})).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n"))
+
+	err := u.Screen(context.TODO(), &m)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MerchantSubmitted, m.Status)
+	assert.Equal(t, domain.ScreeningClear, m.ScreeningResult)
+}
+
+func TestScreenOwnerMatch(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	m := domain.Merchant{ID: 11, Name: "Kopi Joss", Owners: []domain.Owner{{Name: "Ahmad Yusuf", Share: 100}}, Status: domain.MerchantSubmitted}
+
+	mockScreeningRepo.On("Latest", mock.Anything, int64(11)).Return(domain.Screening{}, domain.ErrNotFound).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n12,\"YUSUF, Ahmed\"\n"))
+
+	err := u.Screen(context.TODO(), &m)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MerchantPendingReview, m.Status)
+	assert.Equal(t, domain.ScreeningMatch, m.ScreeningResult)
+	assert.Equal(t, 96, m.ScreeningScore)
+}
+
+func TestScreenLegalNameMatch(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	m := domain.Merchant{ID: 11, Name: "Kopi Joss", LegalName: "PT Bank Mellat", Owners: []domain.Owner{}, Status: domain.MerchantSubmitted}
+
+	mockScreeningRepo.On("Latest", mock.Anything, int64(11)).Return(domain.Screening{}, domain.ErrNotFound).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.Screening) bool {
+		return len(s.Hits) == 1 && s.Hits[0].Subject == "PT Bank Mellat"
+	})).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n"))
+
+	err := u.Screen(context.TODO(), &m)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MerchantPendingReview, m.Status)
+}
+
+func TestScreenClearedMerchantSameHit(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	m := domain.Merchant{ID: 11, Name: "Bank Mellat", Status: domain.MerchantApproved}
+
+	mockScreeningRepo.On("Latest", mock.Anything, int64(11)).Return(domain.Screening{ID: 3, Result: domain.ScreeningMatch, Hits: []domain.ScreeningHit{mellat}}, nil).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n"))
+
+	err := u.Screen(context.TODO(), &m)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MerchantApproved, m.Status)
+}
+
+func TestScreenClearedMerchantNewHit(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	m := domain.Merchant{ID: 11, Name: "Bank Mellat", Owners: []domain.Owner{{Name: "Ahmad Yusuf", Share: 100}}, Status: domain.MerchantApproved}
+
+	mockScreeningRepo.On("Latest", mock.Anything, int64(11)).Return(domain.Screening{ID: 3, Result: domain.ScreeningMatch, Hits: []domain.ScreeningHit{mellat}}, nil).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+		return m.Status == domain.MerchantPendingReview
+	})).Return(nil).Once()
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n12,\"YUSUF, Ahmed\"\n"))
+
+	err := u.Screen(context.TODO(), &m)
+
+	assert.NoError(t, err)
+	mockMerchantRepo.AssertExpectations(t)
+}
+
+func TestRunRefresh(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	cfg := lists(t, "7,BANK MELLAT\n")
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, cfg)
+
+	// Screening a first merchant loads the lists and shows their version.
+	var version string
+	first := domain.Merchant{ID: 1, Name: "Kopi Joss", Status: domain.MerchantApproved}
+	mockScreeningRepo.On("Latest", mock.Anything, int64(1)).Return(domain.Screening{}, domain.ErrNotFound).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		version = args.Get(1).(*domain.Screening).ListVersion
+	}).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	assert.NoError(t, u.Screen(context.TODO(), &first))
+
+	// Only the merchant screened against other lists is screened again;
+	// drafts and rejected merchants are left alone.
+	mockMerchantRepo.On("Fetch", mock.Anything).Return([]domain.Merchant{
+		first,
+		{ID: 2, Name: "Bank Mellat", Status: domain.MerchantApproved},
+		{ID: 3, Name: "Bank Mellat", Status: domain.MerchantRejected},
+		{ID: 4, Name: "Bank Mellat", Status: domain.MerchantDraft},
+	}, nil).Once()
+	mockScreeningRepo.On("Latest", mock.Anything, int64(1)).Return(domain.Screening{ID: 5, ListVersion: version}, nil).Once()
+	mockScreeningRepo.On("Latest", mock.Anything, int64(2)).Return(domain.Screening{ID: 4, ListVersion: "old"}, nil).Twice()
+	mockScreeningRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.Screening) bool {
+		return s.MerchantID == 2 && s.Result == domain.ScreeningMatch
+	})).Return(nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+		return m.ID == 2 && m.Status == domain.MerchantPendingReview
+	})).Return(nil).Once()
+
+	err := u.RunRefresh(context.TODO(), time.Now())
+
+	assert.NoError(t, err)
+	mockScreeningRepo.AssertExpectations(t)
+	mockMerchantRepo.AssertExpectations(t)
+}
+
+func TestRunRefreshMerchantChanged(t *testing.T) {
+	mockScreeningRepo := new(mocks.ScreeningRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	u := screeningUsecase.NewScreeningUsecase(mockScreeningRepo, mockMerchantRepo, lists(t, "7,BANK MELLAT\n"))
+	fetched := domain.Merchant{ID: 2, Name: "Bank Mellat", Status: domain.MerchantPendingReview, Version: 3}
+	approved := fetched
+	approved.Status, approved.Version = domain.MerchantApproved, 4
+
+	// A reviewer approves the merchant while it is being screened. The
+	// screening is not stored until the hold has been written over the
+	// approval.
+	mockMerchantRepo.On("Fetch", mock.Anything).Return([]domain.Merchant{fetched}, nil).Once()
+	mockScreeningRepo.On("Latest", mock.Anything, int64(2)).Return(domain.Screening{ID: 4, ListVersion: "old"}, nil).Times(3)
+	mockMerchantRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+		return m.Version == 3
+	})).Return(domain.ErrStaleVersion).Once()
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(2)).Return(approved, nil).Once()
+	mockMerchantRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *domain.Merchant) bool {
+		return m.Version == 4 && m.Status == domain.MerchantPendingReview
+	})).Return(nil).Once()
+	mockScreeningRepo.On("Store", mock.Anything, mock.MatchedBy(func(s *domain.Screening) bool {
+		return s.MerchantID == 2 && s.Result == domain.ScreeningMatch
+	})).Return(nil).Once()
+
+	err := u.RunRefresh(context.TODO(), time.Now())
+
+	assert.NoError(t, err)
+	mockScreeningRepo.AssertExpectations(t)
+	mockMerchantRepo.AssertExpectations(t)
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type SubscriptionHandler struct {
+	Usecase domain.SubscriptionUsecase
+}
+
+func NewSubscriptionHandler(e *echo.Echo, u domain.SubscriptionUsecase) *SubscriptionHandler {
+	handler := &SubscriptionHandler{
+		Usecase: u,
+	}
+
+	e.POST("/plans", handler.StorePlan)
+	e.GET("/plans", handler.FetchPlans)
+	e.POST("/subscriptions", handler.Subscribe)
+	e.GET("/subscriptions/:id", handler.GetByID)
+	e.POST("/subscriptions/:id/change_plan", handler.ChangePlan)
+	e.POST("/subscriptions/:id/cancel", handler.Cancel)
+
+	return handler
+}
+
+func (h *SubscriptionHandler) StorePlan(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Plan
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.Name == "" || data.Amount <= 0 || data.IntervalMonths < 0 || data.TrialDays < 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.StorePlan(ctx, &data)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *SubscriptionHandler) FetchPlans(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.FetchPlans(ctx, merchantID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *SubscriptionHandler) Subscribe(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Subscription
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.CustomerID == 0 || data.PaymentMethodID == 0 || data.SettingID == 0 || data.PlanID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Subscribe(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *SubscriptionHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type changePlanRequest struct {
+	MerchantID int64 `json:"merchantId"`
+	PlanID     int64 `json:"planId"`
+}
+
+func (h *SubscriptionHandler) ChangePlan(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var req changePlanRequest
+	c.Bind(&req)
+	if req.MerchantID == 0 || req.PlanID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	res, err := h.Usecase.ChangePlan(ctx, req.MerchantID, id, req.PlanID)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type cancelRequest struct {
+	MerchantID  int64 `json:"merchantId"`
+	AtPeriodEnd bool  `json:"atPeriodEnd"`
+}
+
+func (h *SubscriptionHandler) Cancel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+	var req cancelRequest
+	c.Bind(&req)
+	if req.MerchantID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	res, err := h.Usecase.Cancel(ctx, req.MerchantID, id, req.AtPeriodEnd)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrInvalidCard, domain.ErrInvalidCustomer:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrInactive, domain.ErrStaleVersion:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	subscriptionHttp "github.com/hezbymuhammad/payment-gateway/subscription/delivery/http"
+)
+
+func TestSubscribe(t *testing.T) {
+	mockUsecase := new(mocks.SubscriptionUsecase)
+	mockUsecase.On("Subscribe", mock.Anything, mock.Anything).Return(nil).Once()
+
+	j, err := json.Marshal(domain.Subscription{MerchantID: 1, CustomerID: 3, PaymentMethodID: 5, SettingID: 1, PlanID: 2})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/subscriptions", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/subscriptions")
+
+	handler := subscriptionHttp.NewSubscriptionHandler(echo.New(), mockUsecase)
+	err = handler.Subscribe(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestSubscribeInvalidParams(t *testing.T) {
+	mockUsecase := new(mocks.SubscriptionUsecase)
+
+	j, err := json.Marshal(domain.Subscription{MerchantID: 1, PlanID: 2})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/subscriptions", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/subscriptions")
+
+	handler := subscriptionHttp.NewSubscriptionHandler(echo.New(), mockUsecase)
+	err = handler.Subscribe(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCancelInactive(t *testing.T) {
+	mockUsecase := new(mocks.SubscriptionUsecase)
+	mockUsecase.On("Cancel", mock.Anything, int64(1), int64(1), true).Return(domain.Subscription{}, domain.ErrInactive).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/subscriptions/1/cancel", strings.NewReader(`{"merchantId":1,"atPeriodEnd":true}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/subscriptions/:id/cancel")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+	handler := subscriptionHttp.NewSubscriptionHandler(echo.New(), mockUsecase)
+	err = handler.Cancel(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -0,0 +1,19 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	subscriptionRepo "github.com/hezbymuhammad/payment-gateway/subscription/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.SubscriptionRepository(t, func(t *testing.T) domain.SubscriptionRepository {
+		return subscriptionRepo.NewSubscriptionRepository(migrationtest.NewPostgresDB(t))
+	})
+}
@@ -0,0 +1,304 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const (
+	planColumns         = "id, merchant_id, name, amount, currency, interval_months, trial_days"
+	subscriptionColumns = "id, merchant_id, customer_id, payment_method_id, setting_id, plan_id, status, current_period_start, current_period_end, cancel_at_period_end, adjustment, failed_attempts, next_retry_at, version"
+	cycleColumns        = "id, subscription_id, period_start, attempt, amount, status, transaction_id"
+)
+
+type postgresSubscriptionRepo struct {
+	DB *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) domain.SubscriptionRepository {
+	return &postgresSubscriptionRepo{
+		DB: db,
+	}
+}
+
+func (sr *postgresSubscriptionRepo) StorePlan(ctx context.Context, p *domain.Plan) error {
+	query := "INSERT INTO plans (merchant_id, name, amount, currency, interval_months, trial_days) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	err := sr.DB.QueryRowContext(ctx, query, p.MerchantID, p.Name, p.Amount, p.Currency, p.IntervalMonths, p.TrialDays).Scan(&p.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (sr *postgresSubscriptionRepo) GetPlan(ctx context.Context, id int64) (domain.Plan, error) {
+	query := "SELECT " + planColumns + " FROM plans WHERE id=$1"
+
+	data := domain.Plan{}
+	err := sr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.MerchantID, &data.Name, &data.Amount, &data.Currency, &data.IntervalMonths, &data.TrialDays)
+	if err == sql.ErrNoRows {
+		return domain.Plan{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Plan{}, err
+	}
+
+	return data, nil
+}
+
+func (sr *postgresSubscriptionRepo) FetchPlans(ctx context.Context, merchantID int64) ([]domain.Plan, error) {
+	query := "SELECT " + planColumns + " FROM plans WHERE merchant_id=$1 ORDER BY id"
+
+	rows, err := sr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Plan{}
+	for rows.Next() {
+		data := domain.Plan{}
+		err = rows.Scan(&data.ID, &data.MerchantID, &data.Name, &data.Amount, &data.Currency, &data.IntervalMonths, &data.TrialDays)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (sr *postgresSubscriptionRepo) Store(ctx context.Context, s *domain.Subscription) error {
+	query := "INSERT INTO subscriptions (merchant_id, customer_id, payment_method_id, setting_id, plan_id, status, current_period_start, current_period_end, cancel_at_period_end, adjustment, failed_attempts, next_retry_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id"
+
+	err := sr.DB.QueryRowContext(
+		ctx,
+		query,
+		s.MerchantID,
+		s.CustomerID,
+		s.PaymentMethodID,
+		s.SettingID,
+		s.PlanID,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.CancelAtPeriodEnd,
+		s.Adjustment,
+		s.FailedAttempts,
+		s.NextRetryAt,
+	).Scan(&s.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	s.Version = 1
+	return nil
+}
+
+func (sr *postgresSubscriptionRepo) GetByID(ctx context.Context, id int64) (domain.Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE id=$1"
+
+	data, err := scanSubscription(sr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Subscription{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Subscription{}, err
+	}
+
+	return data, nil
+}
+
+// Update writes the subscription back over the version it was read at and
+// bumps its version. It fails with domain.ErrStaleVersion if the
+// subscription was changed since, or domain.ErrNotFound if there is no such
+// subscription.
+func (sr *postgresSubscriptionRepo) Update(ctx context.Context, s *domain.Subscription) error {
+	query := "UPDATE subscriptions SET payment_method_id=$1, plan_id=$2, status=$3, current_period_start=$4, current_period_end=$5, cancel_at_period_end=$6, adjustment=$7, failed_attempts=$8, next_retry_at=$9, version=version+1 WHERE id=$10 AND version=$11"
+
+	res, err := sr.exec(
+		ctx,
+		query,
+		s.PaymentMethodID,
+		s.PlanID,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.CancelAtPeriodEnd,
+		s.Adjustment,
+		s.FailedAttempts,
+		s.NextRetryAt,
+		s.ID,
+		s.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sr.missingOrStale(ctx, s.ID)
+	}
+
+	s.Version++
+	return nil
+}
+
+func (sr *postgresSubscriptionRepo) missingOrStale(ctx context.Context, id int64) error {
+	query := "SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id=$1)"
+
+	var exists bool
+	err := sr.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if exists {
+		return domain.ErrStaleVersion
+	}
+
+	return domain.ErrNotFound
+}
+
+// FetchDue returns subscriptions whose period has ended, plus past due ones
+// whose next dunning retry has come around.
+func (sr *postgresSubscriptionRepo) FetchDue(ctx context.Context, now time.Time) ([]domain.Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE (status IN ('trialing', 'active') AND current_period_end <= $1) OR (status = 'past_due' AND next_retry_at <= $1) ORDER BY id"
+
+	rows, err := sr.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Subscription{}
+	for rows.Next() {
+		data, err := scanSubscription(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// ClaimCycle inserts the cycle unless the same attempt for the same period
+// already exists, and reports whether this caller won.
+func (sr *postgresSubscriptionRepo) ClaimCycle(ctx context.Context, c *domain.BillingCycle) (bool, error) {
+	query := "INSERT INTO billing_cycles (subscription_id, period_start, attempt, amount, status, transaction_id) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (subscription_id, period_start, attempt) DO NOTHING RETURNING id"
+
+	err := sr.DB.QueryRowContext(ctx, query, c.SubscriptionID, c.PeriodStart, c.Attempt, c.Amount, c.Status, c.TransactionID).Scan(&c.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (sr *postgresSubscriptionRepo) GetCycle(ctx context.Context, subscriptionID int64, periodStart time.Time, attempt int) (domain.BillingCycle, error) {
+	query := "SELECT " + cycleColumns + " FROM billing_cycles WHERE subscription_id=$1 AND period_start=$2 AND attempt=$3"
+
+	data := domain.BillingCycle{}
+	err := sr.DB.QueryRowContext(ctx, query, subscriptionID, periodStart, attempt).Scan(
+		&data.ID,
+		&data.SubscriptionID,
+		&data.PeriodStart,
+		&data.Attempt,
+		&data.Amount,
+		&data.Status,
+		&data.TransactionID,
+	)
+	if err == sql.ErrNoRows {
+		return domain.BillingCycle{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.BillingCycle{}, err
+	}
+
+	data.PeriodStart = data.PeriodStart.UTC()
+	return data, nil
+}
+
+func (sr *postgresSubscriptionRepo) UpdateCycle(ctx context.Context, c *domain.BillingCycle) error {
+	query := "UPDATE billing_cycles SET status=$1, transaction_id=$2 WHERE id=$3"
+
+	_, err := sr.exec(ctx, query, c.Status, c.TransactionID, c.ID)
+	return err
+}
+
+func (sr *postgresSubscriptionRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := sr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row scanner) (domain.Subscription, error) {
+	data := domain.Subscription{}
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.CustomerID,
+		&data.PaymentMethodID,
+		&data.SettingID,
+		&data.PlanID,
+		&data.Status,
+		&data.CurrentPeriodStart,
+		&data.CurrentPeriodEnd,
+		&data.CancelAtPeriodEnd,
+		&data.Adjustment,
+		&data.FailedAttempts,
+		&data.NextRetryAt,
+		&data.Version,
+	)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	data.CurrentPeriodStart = data.CurrentPeriodStart.UTC()
+	data.CurrentPeriodEnd = data.CurrentPeriodEnd.UTC()
+	data.NextRetryAt = data.NextRetryAt.UTC()
+
+	return data, nil
+}
@@ -0,0 +1,351 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const subscriptionColumns = "id, merchant_id, customer_id, payment_method_id, setting_id, plan_id, status, current_period_start, current_period_end, cancel_at_period_end, adjustment, failed_attempts, next_retry_at, version"
+
+type sqliteSubscriptionRepo struct {
+	DB *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) domain.SubscriptionRepository {
+	return &sqliteSubscriptionRepo{
+		DB: db,
+	}
+}
+
+func (sr *sqliteSubscriptionRepo) StorePlan(ctx context.Context, p *domain.Plan) error {
+	query := "INSERT INTO plans (merchant_id, name, amount, currency, interval_months, trial_days) VALUES (?, ?, ?, ?, ?, ?)"
+
+	lastID, err := sr.insert(ctx, query, p.MerchantID, p.Name, p.Amount, p.Currency, p.IntervalMonths, p.TrialDays)
+	if err != nil {
+		return err
+	}
+
+	p.ID = lastID
+	return nil
+}
+
+func (sr *sqliteSubscriptionRepo) GetPlan(ctx context.Context, id int64) (domain.Plan, error) {
+	query := "SELECT id, merchant_id, name, amount, currency, interval_months, trial_days FROM plans WHERE id=? LIMIT 1"
+
+	data := domain.Plan{}
+	err := sr.DB.QueryRowContext(ctx, query, id).Scan(&data.ID, &data.MerchantID, &data.Name, &data.Amount, &data.Currency, &data.IntervalMonths, &data.TrialDays)
+	if err == sql.ErrNoRows {
+		return domain.Plan{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Plan{}, err
+	}
+
+	return data, nil
+}
+
+func (sr *sqliteSubscriptionRepo) FetchPlans(ctx context.Context, merchantID int64) ([]domain.Plan, error) {
+	query := "SELECT id, merchant_id, name, amount, currency, interval_months, trial_days FROM plans WHERE merchant_id=? ORDER BY id"
+
+	rows, err := sr.DB.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Plan{}
+	for rows.Next() {
+		data := domain.Plan{}
+		err = rows.Scan(&data.ID, &data.MerchantID, &data.Name, &data.Amount, &data.Currency, &data.IntervalMonths, &data.TrialDays)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+func (sr *sqliteSubscriptionRepo) Store(ctx context.Context, s *domain.Subscription) error {
+	query := "INSERT INTO subscriptions (merchant_id, customer_id, payment_method_id, setting_id, plan_id, status, current_period_start, current_period_end, cancel_at_period_end, adjustment, failed_attempts, next_retry_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	lastID, err := sr.insert(
+		ctx,
+		query,
+		s.MerchantID,
+		s.CustomerID,
+		s.PaymentMethodID,
+		s.SettingID,
+		s.PlanID,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.CancelAtPeriodEnd,
+		s.Adjustment,
+		s.FailedAttempts,
+		s.NextRetryAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.ID = lastID
+	s.Version = 1
+	return nil
+}
+
+func (sr *sqliteSubscriptionRepo) GetByID(ctx context.Context, id int64) (domain.Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE id=? LIMIT 1"
+
+	data, err := scanSubscription(sr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Subscription{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Subscription{}, err
+	}
+
+	return data, nil
+}
+
+// Update writes the subscription back over the version it was read at and
+// bumps its version. It fails with domain.ErrStaleVersion if the
+// subscription was changed since, or domain.ErrNotFound if there is no such
+// subscription.
+func (sr *sqliteSubscriptionRepo) Update(ctx context.Context, s *domain.Subscription) error {
+	query := "UPDATE subscriptions SET payment_method_id=?, plan_id=?, status=?, current_period_start=?, current_period_end=?, cancel_at_period_end=?, adjustment=?, failed_attempts=?, next_retry_at=?, version=version+1 WHERE id=? AND version=?"
+
+	stmt, err := sr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	res, err := stmt.ExecContext(
+		ctx,
+		s.PaymentMethodID,
+		s.PlanID,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.CancelAtPeriodEnd,
+		s.Adjustment,
+		s.FailedAttempts,
+		s.NextRetryAt,
+		s.ID,
+		s.Version,
+	)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sr.missingOrStale(ctx, s.ID)
+	}
+
+	s.Version++
+	return nil
+}
+
+func (sr *sqliteSubscriptionRepo) missingOrStale(ctx context.Context, id int64) error {
+	query := "SELECT EXISTS (SELECT 1 FROM subscriptions WHERE id=?)"
+
+	var exists bool
+	err := sr.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if exists {
+		return domain.ErrStaleVersion
+	}
+
+	return domain.ErrNotFound
+}
+
+// FetchDue returns subscriptions whose period has ended, plus past due ones
+// whose next dunning retry has come around.
+func (sr *sqliteSubscriptionRepo) FetchDue(ctx context.Context, now time.Time) ([]domain.Subscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE (status IN ('trialing', 'active') AND current_period_end <= ?) OR (status = 'past_due' AND next_retry_at <= ?) ORDER BY id"
+
+	rows, err := sr.DB.QueryContext(ctx, query, now, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Subscription{}
+	for rows.Next() {
+		data, err := scanSubscription(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// ClaimCycle inserts the cycle unless the same attempt for the same period
+// already exists, and reports whether this caller won.
+func (sr *sqliteSubscriptionRepo) ClaimCycle(ctx context.Context, c *domain.BillingCycle) (bool, error) {
+	query := "INSERT OR IGNORE INTO billing_cycles (subscription_id, period_start, attempt, amount, status, transaction_id) VALUES (?, ?, ?, ?, ?, ?)"
+
+	stmt, err := sr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	res, err := stmt.ExecContext(ctx, c.SubscriptionID, c.PeriodStart, c.Attempt, c.Amount, c.Status, c.TransactionID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	c.ID = lastID
+	return true, nil
+}
+
+func (sr *sqliteSubscriptionRepo) GetCycle(ctx context.Context, subscriptionID int64, periodStart time.Time, attempt int) (domain.BillingCycle, error) {
+	query := "SELECT id, subscription_id, period_start, attempt, amount, status, transaction_id FROM billing_cycles WHERE subscription_id=? AND period_start=? AND attempt=? LIMIT 1"
+
+	data := domain.BillingCycle{}
+	err := sr.DB.QueryRowContext(ctx, query, subscriptionID, periodStart, attempt).Scan(
+		&data.ID,
+		&data.SubscriptionID,
+		&data.PeriodStart,
+		&data.Attempt,
+		&data.Amount,
+		&data.Status,
+		&data.TransactionID,
+	)
+	if err == sql.ErrNoRows {
+		return domain.BillingCycle{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.BillingCycle{}, err
+	}
+
+	return data, nil
+}
+
+func (sr *sqliteSubscriptionRepo) UpdateCycle(ctx context.Context, c *domain.BillingCycle) error {
+	query := "UPDATE billing_cycles SET status=?, transaction_id=? WHERE id=?"
+
+	return sr.exec(ctx, query, c.Status, c.TransactionID, c.ID)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row scanner) (domain.Subscription, error) {
+	data := domain.Subscription{}
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.CustomerID,
+		&data.PaymentMethodID,
+		&data.SettingID,
+		&data.PlanID,
+		&data.Status,
+		&data.CurrentPeriodStart,
+		&data.CurrentPeriodEnd,
+		&data.CancelAtPeriodEnd,
+		&data.Adjustment,
+		&data.FailedAttempts,
+		&data.NextRetryAt,
+		&data.Version,
+	)
+
+	return data, err
+}
+
+func (sr *sqliteSubscriptionRepo) insert(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	stmt, err := sr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return 0, err
+	}
+
+	return lastID, nil
+}
+
+func (sr *sqliteSubscriptionRepo) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := sr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	subscriptionRepo "github.com/hezbymuhammad/payment-gateway/subscription/repository/sqlite"
+)
+
+var now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+var subscriptionColumns = []string{"id", "merchant_id", "customer_id", "payment_method_id", "setting_id", "plan_id", "status", "current_period_start", "current_period_end", "cancel_at_period_end", "adjustment", "failed_attempts", "next_retry_at", "version"}
+
+func TestStorePlan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.Plan{MerchantID: 1, Name: "Monthly", Amount: 50000, Currency: "IDR", IntervalMonths: 1, TrialDays: 7}
+	query := regexp.QuoteMeta("INSERT INTO plans (merchant_id, name, amount, currency, interval_months, trial_days) VALUES (?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.MerchantID, data.Name, data.Amount, data.Currency, data.IntervalMonths, data.TrialDays).WillReturnResult(sqlmock.NewResult(2, 1))
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	err = sr.StorePlan(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), data.ID)
+}
+
+func TestGetPlanNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "merchant_id", "name", "amount", "currency", "interval_months", "trial_days"})
+	query := regexp.QuoteMeta("SELECT id, merchant_id, name, amount, currency, interval_months, trial_days FROM plans WHERE id=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs(2).WillReturnRows(rows)
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	_, err = sr.GetPlan(context.TODO(), 2)
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestFetchDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(subscriptionColumns).
+		AddRow(1, 1, 3, 5, 1, 2, domain.SubscriptionActive, now.AddDate(0, -1, 0), now, false, 0, 0, time.Time{}, 1)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, customer_id, payment_method_id, setting_id, plan_id, status, current_period_start, current_period_end, cancel_at_period_end, adjustment, failed_attempts, next_retry_at, version FROM subscriptions WHERE (status IN ('trialing', 'active') AND current_period_end <= ?) OR (status = 'past_due' AND next_retry_at <= ?) ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(now, now).WillReturnRows(rows)
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	res, err := sr.FetchDue(context.TODO(), now)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, now, res[0].CurrentPeriodEnd)
+}
+
+func TestUpdate(t *testing.T) {
+	sr := subscriptionRepo.NewSubscriptionRepository(migrationtest.NewDB(t))
+	data := domain.Subscription{MerchantID: 1, CustomerID: 3, PaymentMethodID: 5, SettingID: 1, PlanID: 2, Status: domain.SubscriptionActive, CurrentPeriodStart: now.AddDate(0, -1, 0), CurrentPeriodEnd: now}
+	assert.NoError(t, sr.Store(context.TODO(), &data))
+	assert.Equal(t, int64(1), data.Version)
+	stale := data
+
+	data.CancelAtPeriodEnd = true
+	assert.NoError(t, sr.Update(context.TODO(), &data))
+	assert.Equal(t, int64(2), data.Version)
+
+	stale.Status = domain.SubscriptionPastDue
+	assert.Equal(t, domain.ErrStaleVersion, sr.Update(context.TODO(), &stale))
+
+	res, err := sr.GetByID(context.TODO(), data.ID)
+	assert.NoError(t, err)
+	assert.True(t, res.CancelAtPeriodEnd)
+	assert.Equal(t, domain.SubscriptionActive, res.Status)
+	assert.Equal(t, int64(2), res.Version)
+
+	missing := domain.Subscription{ID: 99, Version: 1}
+	assert.Equal(t, domain.ErrNotFound, sr.Update(context.TODO(), &missing))
+}
+
+func TestFetchDueError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnError(fmt.Errorf("some error"))
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	_, err = sr.FetchDue(context.TODO(), now)
+	assert.Error(t, err)
+}
+
+func TestClaimCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.BillingCycle{SubscriptionID: 1, PeriodStart: now, Attempt: 1, Amount: 50000, Status: domain.CycleProcessing}
+	query := regexp.QuoteMeta("INSERT OR IGNORE INTO billing_cycles (subscription_id, period_start, attempt, amount, status, transaction_id) VALUES (?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.SubscriptionID, data.PeriodStart, data.Attempt, data.Amount, data.Status, data.TransactionID).WillReturnResult(sqlmock.NewResult(9, 1))
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	claimed, err := sr.ClaimCycle(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, int64(9), data.ID)
+}
+
+func TestClaimCycleAlreadyClaimed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.BillingCycle{SubscriptionID: 1, PeriodStart: now, Attempt: 1, Amount: 50000, Status: domain.CycleProcessing}
+	query := regexp.QuoteMeta("INSERT OR IGNORE INTO billing_cycles")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	claimed, err := sr.ClaimCycle(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func TestUpdateCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.BillingCycle{ID: 9, Status: domain.CyclePaid, TransactionID: 4}
+	query := regexp.QuoteMeta("UPDATE billing_cycles SET status=?, transaction_id=? WHERE id=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(data.Status, data.TransactionID, data.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	sr := subscriptionRepo.NewSubscriptionRepository(db)
+
+	err = sr.UpdateCycle(context.TODO(), data)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// updateAttempts is how many times a subscription update that keeps losing
+// to other updates is made before giving up.
+const updateAttempts = 3
+
+type subscriptionUsecase struct {
+	subscriptionRepo domain.SubscriptionRepository
+	customers        domain.CustomerUsecase
+	transactions     domain.TransactionUsecase
+	retries          []time.Duration
+}
+
+// NewSubscriptionUsecase bills subscriptions through tu. retries is the
+// dunning schedule: the delay before each retry of a failed charge. Once it
+// is exhausted the subscription is canceled.
+func NewSubscriptionUsecase(sr domain.SubscriptionRepository, cu domain.CustomerUsecase, tu domain.TransactionUsecase, retries []time.Duration) domain.SubscriptionUsecase {
+	return &subscriptionUsecase{
+		subscriptionRepo: sr,
+		customers:        cu,
+		transactions:     tu,
+		retries:          retries,
+	}
+}
+
+func (su *subscriptionUsecase) StorePlan(ctx context.Context, p *domain.Plan) error {
+	if p.Currency == "" {
+		p.Currency = "IDR"
+	}
+	if p.IntervalMonths == 0 {
+		p.IntervalMonths = 1
+	}
+
+	return su.subscriptionRepo.StorePlan(ctx, p)
+}
+
+func (su *subscriptionUsecase) FetchPlans(ctx context.Context, merchantID int64) ([]domain.Plan, error) {
+	return su.subscriptionRepo.FetchPlans(ctx, merchantID)
+}
+
+// Subscribe starts a trial when the plan has one and charges the first period
+// straight away otherwise. A failed first charge leaves the subscription past
+// due rather than failing the call.
+func (su *subscriptionUsecase) Subscribe(ctx context.Context, s *domain.Subscription) error {
+	plan, err := su.getPlan(ctx, s.MerchantID, s.PlanID)
+	if err != nil {
+		return err
+	}
+
+	pm, err := su.customers.GetPaymentMethod(ctx, s.MerchantID, s.PaymentMethodID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidCard
+	}
+	if err != nil {
+		return err
+	}
+	if pm.CustomerID != s.CustomerID {
+		return domain.ErrInvalidCustomer
+	}
+
+	now := normalize(time.Now())
+	s.CurrentPeriodStart = now
+	s.CurrentPeriodEnd = now
+	s.Status = domain.SubscriptionActive
+	if plan.TrialDays > 0 {
+		s.Status = domain.SubscriptionTrialing
+		s.CurrentPeriodEnd = now.AddDate(0, 0, plan.TrialDays)
+	}
+
+	err = su.subscriptionRepo.Store(ctx, s)
+	if err != nil {
+		return err
+	}
+	if s.Status == domain.SubscriptionTrialing {
+		return nil
+	}
+
+	return su.renew(ctx, s, plan, now)
+}
+
+func (su *subscriptionUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.Subscription, error) {
+	s, err := su.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if s.MerchantID != merchantID {
+		return domain.Subscription{}, domain.ErrNotFound
+	}
+
+	return s, nil
+}
+
+// ChangePlan switches plans immediately. The unused share of the old plan is
+// credited and the same share of the new plan charged, both on the next bill.
+func (su *subscriptionUsecase) ChangePlan(ctx context.Context, merchantID int64, id int64, planID int64) (domain.Subscription, error) {
+	s, err := su.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	newPlan, err := su.getPlan(ctx, merchantID, planID)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	err = su.update(ctx, &s, func(s *domain.Subscription) error {
+		if s.Status == domain.SubscriptionCanceled {
+			return domain.ErrInactive
+		}
+		oldPlan, err := su.subscriptionRepo.GetPlan(ctx, s.PlanID)
+		if err != nil {
+			return err
+		}
+
+		if s.Status != domain.SubscriptionTrialing {
+			s.Adjustment += prorate(newPlan.Amount-oldPlan.Amount, *s, normalize(time.Now()))
+		}
+		s.PlanID = newPlan.ID
+		return nil
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s, nil
+}
+
+// Cancel either ends the subscription now or lets the paid period run out.
+// Past due subscriptions have no paid period left and always end now.
+func (su *subscriptionUsecase) Cancel(ctx context.Context, merchantID int64, id int64, atPeriodEnd bool) (domain.Subscription, error) {
+	s, err := su.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	err = su.update(ctx, &s, func(s *domain.Subscription) error {
+		if s.Status == domain.SubscriptionCanceled {
+			return domain.ErrInactive
+		}
+
+		if atPeriodEnd && s.Status != domain.SubscriptionPastDue {
+			s.CancelAtPeriodEnd = true
+		} else {
+			s.Status = domain.SubscriptionCanceled
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return s, nil
+}
+
+// RunBilling charges everything that is due at now. It is safe to run again
+// after a crash: a period that was already attempted is never charged twice.
+func (su *subscriptionUsecase) RunBilling(ctx context.Context, now time.Time) error {
+	now = normalize(now)
+	due, err := su.subscriptionRepo.FetchDue(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for i := range due {
+		s := due[i]
+		err = su.bill(ctx, &s, now)
+		if err != nil {
+			log.Printf("subscription %d: %v", s.ID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (su *subscriptionUsecase) bill(ctx context.Context, s *domain.Subscription, now time.Time) error {
+	if s.CancelAtPeriodEnd && s.Status != domain.SubscriptionPastDue {
+		s.Status = domain.SubscriptionCanceled
+		return su.subscriptionRepo.Update(ctx, s)
+	}
+
+	plan, err := su.subscriptionRepo.GetPlan(ctx, s.PlanID)
+	if err != nil {
+		return err
+	}
+
+	return su.renew(ctx, s, plan, now)
+}
+
+// renew charges the period starting at the end of the current one.
+func (su *subscriptionUsecase) renew(ctx context.Context, s *domain.Subscription, plan domain.Plan, now time.Time) error {
+	amount := plan.Amount + s.Adjustment
+	var carry int64
+	if amount < 0 {
+		carry = amount
+		amount = 0
+	}
+
+	cycle := domain.BillingCycle{
+		SubscriptionID: s.ID,
+		PeriodStart:    s.CurrentPeriodEnd,
+		Attempt:        s.FailedAttempts + 1,
+		Amount:         amount,
+		Status:         domain.CycleProcessing,
+	}
+	claimed, err := su.subscriptionRepo.ClaimCycle(ctx, &cycle)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return su.resume(ctx, s, plan, cycle, carry, now)
+	}
+
+	return su.collect(ctx, s, plan, cycle, carry, now)
+}
+
+// resume picks up a cycle that an earlier run already claimed. Finished
+// cycles are applied to the subscription; one still processing is collected
+// again, which finds the charge the earlier run made rather than making a
+// second one.
+func (su *subscriptionUsecase) resume(ctx context.Context, s *domain.Subscription, plan domain.Plan, cycle domain.BillingCycle, carry int64, now time.Time) error {
+	existing, err := su.subscriptionRepo.GetCycle(ctx, cycle.SubscriptionID, cycle.PeriodStart, cycle.Attempt)
+	if err != nil {
+		return err
+	}
+	if existing.Status == domain.CycleProcessing {
+		return su.collect(ctx, s, plan, existing, carry, now)
+	}
+
+	return su.settle(ctx, s, plan, existing, carry, now)
+}
+
+// collect charges a claimed cycle and applies the outcome. A cycle whose
+// charge is held for review or could not be captured yet stays processing,
+// and the subscription stays due, so a later run collects it again.
+func (su *subscriptionUsecase) collect(ctx context.Context, s *domain.Subscription, plan domain.Plan, cycle domain.BillingCycle, carry int64, now time.Time) error {
+	cycle.Status = domain.CyclePaid
+	if cycle.Amount > 0 {
+		cycle.TransactionID, cycle.Status = su.charge(ctx, s, plan, cycle)
+	}
+
+	err := su.subscriptionRepo.UpdateCycle(ctx, &cycle)
+	if err != nil {
+		return err
+	}
+	if cycle.Status == domain.CycleProcessing {
+		return nil
+	}
+
+	return su.settle(ctx, s, plan, cycle, carry, now)
+}
+
+// charge bills the cycle and says how that went. The transaction carries a
+// reference unique to the cycle, so a charge an interrupted run already made
+// is found again instead of being made twice.
+func (su *subscriptionUsecase) charge(ctx context.Context, s *domain.Subscription, plan domain.Plan, cycle domain.BillingCycle) (int64, string) {
+	reference := fmt.Sprintf("subscription:%d:%d:%d", s.ID, cycle.PeriodStart.Unix(), cycle.Attempt)
+	t, err := su.transactions.GetByMerchantReference(ctx, s.MerchantID, reference)
+	if err == domain.ErrNotFound {
+		t = domain.Transaction{
+			MerchantID:        s.MerchantID,
+			ParentMerchantID:  s.MerchantID,
+			SettingID:         s.SettingID,
+			Amount:            cycle.Amount,
+			Currency:          plan.Currency,
+			CustomerID:        s.CustomerID,
+			PaymentMethodID:   s.PaymentMethodID,
+			MerchantReference: reference,
+		}
+		err = su.transactions.Store(ctx, &t)
+		if err != nil {
+			log.Printf("subscription %d: %v", s.ID, err)
+		}
+		if err == domain.ErrReferenceTaken {
+			// Another run is charging the same cycle.
+			return cycle.TransactionID, domain.CycleProcessing
+		}
+		if t.ID == 0 {
+			return 0, domain.CycleFailed
+		}
+	} else if err != nil {
+		log.Printf("subscription %d: %v", s.ID, err)
+		return cycle.TransactionID, domain.CycleProcessing
+	}
+
+	return t.ID, su.outcome(ctx, s, t)
+}
+
+// outcome says what a cycle's transaction came to, capturing it if it is
+// only authorized. An authorization the processor will not capture is
+// voided before the cycle fails, so that the retry does not leave a second
+// hold on the card; one that could not be captured or voided for now is
+// tried again by a later run.
+func (su *subscriptionUsecase) outcome(ctx context.Context, s *domain.Subscription, t domain.Transaction) string {
+	switch t.State {
+	case domain.TransactionCaptured:
+		return domain.CyclePaid
+	case domain.TransactionPending, domain.TransactionReview, domain.TransactionProcessing:
+		return domain.CycleProcessing
+	case domain.TransactionAuthorized:
+	default:
+		return domain.CycleFailed
+	}
+
+	captured, err := su.transactions.Capture(ctx, t.ID)
+	if err != nil {
+		log.Printf("subscription %d: capture transaction %d: %v", s.ID, t.ID, err)
+		return domain.CycleProcessing
+	}
+	if captured.State == domain.TransactionCaptured {
+		return domain.CyclePaid
+	}
+
+	_, err = su.transactions.Void(ctx, t.ID)
+	if err != nil {
+		log.Printf("subscription %d: void transaction %d: %v", s.ID, t.ID, err)
+		return domain.CycleProcessing
+	}
+
+	return domain.CycleFailed
+}
+
+// settle applies a finished cycle to the subscription. A merchant's change
+// that lands meanwhile is kept: the cycle is applied again on top of it, and
+// a plan change's proration stays on the next bill.
+func (su *subscriptionUsecase) settle(ctx context.Context, s *domain.Subscription, plan domain.Plan, cycle domain.BillingCycle, carry int64, now time.Time) error {
+	billed := s.Adjustment
+
+	return su.update(ctx, s, func(s *domain.Subscription) error {
+		if cycle.Status == domain.CyclePaid {
+			if s.Status != domain.SubscriptionCanceled {
+				s.Status = domain.SubscriptionActive
+			}
+			s.CurrentPeriodStart = cycle.PeriodStart
+			s.CurrentPeriodEnd = cycle.PeriodStart.AddDate(0, plan.IntervalMonths, 0)
+			s.Adjustment = carry + s.Adjustment - billed
+			s.FailedAttempts = 0
+			s.NextRetryAt = time.Time{}
+			return nil
+		}
+		if s.Status == domain.SubscriptionCanceled {
+			return nil
+		}
+
+		s.FailedAttempts++
+		if s.FailedAttempts > len(su.retries) {
+			s.Status = domain.SubscriptionCanceled
+		} else {
+			s.Status = domain.SubscriptionPastDue
+			s.NextRetryAt = now.Add(su.retries[s.FailedAttempts-1])
+		}
+		return nil
+	})
+}
+
+// update makes a change to the subscription and writes it. If something
+// else updated the subscription since it was read, it is read again and the
+// change made over, a few times before giving up with
+// domain.ErrStaleVersion.
+func (su *subscriptionUsecase) update(ctx context.Context, s *domain.Subscription, change func(s *domain.Subscription) error) error {
+	for attempt := 1; ; attempt++ {
+		err := change(s)
+		if err != nil {
+			return err
+		}
+		err = su.subscriptionRepo.Update(ctx, s)
+		if err != domain.ErrStaleVersion || attempt == updateAttempts {
+			return err
+		}
+
+		*s, err = su.subscriptionRepo.GetByID(ctx, s.ID)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (su *subscriptionUsecase) getPlan(ctx context.Context, merchantID int64, id int64) (domain.Plan, error) {
+	p, err := su.subscriptionRepo.GetPlan(ctx, id)
+	if err != nil {
+		return domain.Plan{}, err
+	}
+	if p.MerchantID != merchantID {
+		return domain.Plan{}, domain.ErrNotFound
+	}
+
+	return p, nil
+}
+
+// prorate scales diff by the share of the current period still to run.
+func prorate(diff int64, s domain.Subscription, now time.Time) int64 {
+	total := s.CurrentPeriodEnd.Sub(s.CurrentPeriodStart)
+	left := s.CurrentPeriodEnd.Sub(now)
+	if total <= 0 || left <= 0 {
+		return 0
+	}
+	if left > total {
+		left = total
+	}
+
+	return int64(math.Round(float64(diff) * float64(left) / float64(total)))
+}
+
+func normalize(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
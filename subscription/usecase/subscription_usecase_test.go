@@ -0,0 +1,387 @@
+package usecase_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	subscriptionUsecase "github.com/hezbymuhammad/payment-gateway/subscription/usecase"
+)
+
+var (
+	now     = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	retries = []time.Duration{24 * time.Hour, 72 * time.Hour}
+	plan    = domain.Plan{ID: 2, MerchantID: 1, Amount: 50000, Currency: "IDR", IntervalMonths: 1}
+	pm      = domain.PaymentMethod{ID: 5, CustomerID: 3, MerchantID: 1, CardToken: "tok_1"}
+)
+
+func dueSubscription() domain.Subscription {
+	return domain.Subscription{
+		ID:                 1,
+		MerchantID:         1,
+		CustomerID:         3,
+		PaymentMethodID:    5,
+		SettingID:          1,
+		PlanID:             2,
+		Status:             domain.SubscriptionActive,
+		CurrentPeriodStart: now.AddDate(0, -1, 0),
+		CurrentPeriodEnd:   now,
+	}
+}
+
+func approve(tu *mocks.TransactionUsecase) {
+	tu.On("GetByMerchantReference", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, domain.ErrNotFound).Once()
+	tu.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	tu.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+}
+
+func decline(tu *mocks.TransactionUsecase) {
+	tu.On("GetByMerchantReference", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, domain.ErrNotFound).Once()
+	tu.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionDeclined
+	}).Return(nil).Once()
+}
+
+func TestSubscribeWithTrial(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	trialPlan := plan
+	trialPlan.TrialDays = 14
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(trialPlan, nil).Once()
+	mockCustomers.On("GetPaymentMethod", mock.Anything, int64(1), int64(5)).Return(pm, nil).Once()
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	data := &domain.Subscription{MerchantID: 1, CustomerID: 3, PaymentMethodID: 5, SettingID: 1, PlanID: 2}
+	err := u.Subscribe(context.TODO(), data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionTrialing, data.Status)
+	assert.Equal(t, data.CurrentPeriodStart.AddDate(0, 0, 14), data.CurrentPeriodEnd)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestSubscribeChargesFirstPeriod(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockCustomers.On("GetPaymentMethod", mock.Anything, int64(1), int64(5)).Return(pm, nil).Once()
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Status == domain.CyclePaid && c.TransactionID == 4 && c.Amount == 50000
+	})).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	approve(mockTransactions)
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	data := &domain.Subscription{MerchantID: 1, CustomerID: 3, PaymentMethodID: 5, SettingID: 1, PlanID: 2}
+	err := u.Subscribe(context.TODO(), data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionActive, data.Status)
+	assert.Equal(t, data.CurrentPeriodStart.AddDate(0, 1, 0), data.CurrentPeriodEnd)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeWithAnotherCustomersCard(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockCustomers.On("GetPaymentMethod", mock.Anything, int64(1), int64(5)).Return(pm, nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.Subscribe(context.TODO(), &domain.Subscription{MerchantID: 1, CustomerID: 9, PaymentMethodID: 5, PlanID: 2})
+
+	assert.Equal(t, domain.ErrInvalidCustomer, err)
+}
+
+func TestRunBillingRenews(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := dueSubscription()
+	s.Adjustment = -10000
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{s}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.PeriodStart == now && c.Attempt == 1 && c.Amount == 40000
+	})).Return(true, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionActive && s.CurrentPeriodStart == now && s.CurrentPeriodEnd == now.AddDate(0, 1, 0) && s.Adjustment == 0
+	})).Return(nil).Once()
+	approve(mockTransactions)
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBillingDunning(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Status == domain.CycleFailed
+	})).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionPastDue && s.FailedAttempts == 1 && s.NextRetryAt == now.Add(24*time.Hour) && s.CurrentPeriodEnd == now
+	})).Return(nil).Once()
+	decline(mockTransactions)
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBillingDunningExhausted(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := dueSubscription()
+	s.Status = domain.SubscriptionPastDue
+	s.FailedAttempts = 2
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{s}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Attempt == 3
+	})).Return(true, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionCanceled
+	})).Return(nil).Once()
+	decline(mockTransactions)
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBillingReconcilesInterruptedCycle(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("GetCycle", mock.Anything, int64(1), now, 1).Return(domain.BillingCycle{ID: 9, SubscriptionID: 1, PeriodStart: now, Attempt: 1, Amount: 50000, Status: domain.CycleProcessing}, nil).Once()
+	mockTransactions.On("GetByMerchantReference", mock.Anything, int64(1), "subscription:1:"+strconv.FormatInt(now.Unix(), 10)+":1").Return(domain.Transaction{ID: 4, State: domain.TransactionAuthorized}, nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.ID == 9 && c.Status == domain.CyclePaid && c.TransactionID == 4
+	})).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionActive && s.CurrentPeriodEnd == now.AddDate(0, 1, 0)
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBillingAwaitsReview(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockTransactions.On("GetByMerchantReference", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, domain.ErrNotFound).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionReview
+	}).Return(nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Status == domain.CycleProcessing && c.TransactionID == 4
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRunBillingCaptureError(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockTransactions.On("GetByMerchantReference", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, domain.ErrNotFound).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{}, domain.ErrProcessorTimeout).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Status == domain.CycleProcessing && c.TransactionID == 4
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRunBillingVoidsUncapturedAuthorization(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockTransactions.On("GetByMerchantReference", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, domain.ErrNotFound).Once()
+	mockTransactions.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		t := args.Get(1).(*domain.Transaction)
+		t.ID = 4
+		t.State = domain.TransactionAuthorized
+	}).Return(nil).Once()
+	mockTransactions.On("Capture", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionAuthorized}, nil).Once()
+	mockTransactions.On("Void", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionVoided}, nil).Once()
+	mockRepo.On("UpdateCycle", mock.Anything, mock.MatchedBy(func(c *domain.BillingCycle) bool {
+		return c.Status == domain.CycleFailed && c.TransactionID == 4
+	})).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionPastDue
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestRunBillingAppliesCycleFromEarlierRun(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{dueSubscription()}, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("ClaimCycle", mock.Anything, mock.Anything).Return(false, nil).Once()
+	mockRepo.On("GetCycle", mock.Anything, int64(1), now, 1).Return(domain.BillingCycle{ID: 9, PeriodStart: now, Status: domain.CyclePaid, TransactionID: 4}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionActive && s.CurrentPeriodEnd == now.AddDate(0, 1, 0)
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBillingCancelsAtPeriodEnd(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := dueSubscription()
+	s.CancelAtPeriodEnd = true
+	mockRepo.On("FetchDue", mock.Anything, now).Return([]domain.Subscription{s}, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Status == domain.SubscriptionCanceled
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	err := u.RunBilling(context.TODO(), now)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "ClaimCycle", mock.Anything, mock.Anything)
+}
+
+func TestChangePlanProrates(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	s := dueSubscription()
+	s.CurrentPeriodStart = time.Now().UTC().Add(-15 * 24 * time.Hour)
+	s.CurrentPeriodEnd = time.Now().UTC().Add(15 * 24 * time.Hour)
+	bigger := domain.Plan{ID: 6, MerchantID: 1, Amount: 110000, Currency: "IDR", IntervalMonths: 1}
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(s, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(2)).Return(plan, nil).Once()
+	mockRepo.On("GetPlan", mock.Anything, int64(6)).Return(bigger, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	res, err := u.ChangePlan(context.TODO(), 1, 1, 6)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), res.PlanID)
+	assert.InDelta(t, 30000, res.Adjustment, 10)
+}
+
+func TestCancel(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(dueSubscription(), nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	res, err := u.Cancel(context.TODO(), 1, 1, true)
+
+	assert.NoError(t, err)
+	assert.True(t, res.CancelAtPeriodEnd)
+	assert.Equal(t, domain.SubscriptionActive, res.Status)
+}
+
+func TestCancelRetriesStaleUpdate(t *testing.T) {
+	mockRepo := new(mocks.SubscriptionRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockTransactions := new(mocks.TransactionUsecase)
+	billed := dueSubscription()
+	billed.Status = domain.SubscriptionPastDue
+	billed.Version = 2
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(dueSubscription(), nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Version == 0
+	})).Return(domain.ErrStaleVersion).Once()
+	mockRepo.On("GetByID", mock.Anything, int64(1)).Return(billed, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *domain.Subscription) bool {
+		return s.Version == 2
+	})).Return(nil).Once()
+	u := subscriptionUsecase.NewSubscriptionUsecase(mockRepo, mockCustomers, mockTransactions, retries)
+
+	res, err := u.Cancel(context.TODO(), 1, 1, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SubscriptionCanceled, res.Status)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,20 +1,40 @@
 package http
 
 import (
+        "context"
+        "encoding/json"
         "fmt"
+        "mime"
 	"net/http"
+        "reflect"
+        "sort"
         "strconv"
+        "strings"
         "log"
 
 	"github.com/labstack/echo"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/etag"
 )
 
 type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// LimitResponse tells the caller which limit a transaction would have gone
+// over and how much of it is left.
+type LimitResponse struct {
+	Message string `json:"message"`
+	domain.LimitStatus
+}
+
+// FieldResponse names the field a request was refused for.
+type FieldResponse struct {
+	Message string `json:"message"`
+	Field   string `json:"field"`
+}
+
 type TransactionHandler struct {
         Usecase domain.TransactionUsecase
 }
@@ -25,8 +45,16 @@ func NewTransactionHandler(e *echo.Echo, u domain.TransactionUsecase) *Transacti
         }
 
         e.POST("/transactions", handler.Store)
-        e.PUT("/transactions/:id", handler.Update)
+        e.PATCH("/transactions/:id", handler.Patch)
         e.GET("/transactions/:id", handler.GetByID)
+        e.GET("/transactions/:id/changes", handler.FetchChanges)
+        e.POST("/transactions/:id/capture", handler.Capture)
+        e.POST("/transactions/:id/refund", handler.Refund)
+        e.POST("/transactions/:id/void", handler.Void)
+        e.GET("/transactions/reviews", handler.FetchReviews)
+        e.GET("/transactions/lookup", handler.GetByMerchantReference)
+        e.POST("/transactions/:id/approve", handler.Approve)
+        e.POST("/transactions/:id/reject", handler.Reject)
 
         return handler
 }
@@ -42,33 +70,143 @@ func (h *TransactionHandler) Store(c echo.Context) error {
 	if err != nil && fmt.Sprint(err) == "Unauthorized" {
 		return c.JSON(http.StatusUnauthorized, ResponseError{Message: "Unauthorized"})
 	}
+	if err == domain.ErrMerchantInactive {
+		return c.JSON(http.StatusForbidden, ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrInvalidCard || err == domain.ErrInvalidCustomer || err == domain.ErrPaymentMethod || err == domain.ErrInstallmentPlan || err == domain.ErrPromotion || err == domain.ErrCurrency || err == domain.ErrFXQuote || err == domain.ErrReference || err == domain.ErrMetadataLimit {
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	}
+	if le, ok := err.(*domain.LimitError); ok {
+		c.Response().Header().Set("X-Limit", le.Limit)
+		c.Response().Header().Set("X-Limit-Remaining", strconv.FormatInt(le.Remaining, 10))
+		return c.JSON(http.StatusUnprocessableEntity, LimitResponse{Message: domain.ErrLimitExceeded.Error(), LimitStatus: le.LimitStatus})
+	}
+	if err == domain.ErrPromotionQuota || err == domain.ErrReferenceTaken {
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrProvider {
+		return c.JSON(http.StatusBadGateway, ResponseError{Message: err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
 	}
 
-        return c.NoContent(http.StatusCreated)
+        c.Response().Header().Set("ETag", etag.Format(data.Version))
+        return c.JSON(http.StatusCreated, data)
 }
 
-func (h *TransactionHandler) Update(c echo.Context) error {
-        idP, err := strconv.Atoi(c.Param("id"))
+// patchable are the fields a merge patch may set on a transaction. The
+// version only says which version the patch was made against.
+var patchable = map[string]bool{
+        "description": true,
+        "metadata": true,
+        "state": true,
+        "version": true,
+}
+
+// transactionFields are the JSON names of a transaction's fields, to tell
+// a field that cannot be changed from one that does not exist.
+var transactionFields = jsonFields(reflect.TypeOf(domain.Transaction{}))
+
+// Patch applies a JSON Merge Patch (RFC 7396) to a transaction. Only the
+// description, metadata and state can be changed, and the state only along
+// the moves its own endpoints make. Any other field is refused with 422.
+// Like other updates it must say which version it patches, in If-Match as
+// the ETag from GET or as the version in the body; a stale If-Match fails
+// with 412 and a stale body version with 409.
+func (h *TransactionHandler) Patch(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
         if err != nil {
 		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
 	}
-        id := int64(idP)
+        if !mergePatchType(c.Request().Header.Get(echo.HeaderContentType)) {
+		return c.JSON(http.StatusUnsupportedMediaType, ResponseError{Message: "Content-Type must be application/merge-patch+json"})
+	}
+
+        var fields map[string]json.RawMessage
+        err = json.NewDecoder(c.Request().Body).Decode(&fields)
+        if err != nil || fields == nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        data, err := decodePatch(fields)
+        if fe, ok := err.(*domain.FieldError); ok {
+		return c.JSON(http.StatusUnprocessableEntity, FieldResponse{Message: fe.Err.Error(), Field: fe.Field})
+	}
+        if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+        ifMatch, status := etag.Expect(c.Request(), &data.Version)
+	if status == http.StatusPreconditionRequired {
+		return c.JSON(status, ResponseError{Message: "If-Match or version is required, fetch the transaction first"})
+	}
+	if status != 0 {
+		return c.JSON(status, ResponseError{Message: domain.ErrStaleVersion.Error()})
+	}
 
 	ctx := c.Request().Context()
-        var data domain.Transaction
-        c.Bind(&data)
-        data.ID = id
-	if data.MerchantID == 0 || data.SettingID == 0 || data.ID == 0 {
+        res, err := h.Usecase.Patch(ctx, id, &data)
+	if err == domain.ErrStaleVersion {
+		return c.JSON(etag.StaleStatus(ifMatch), ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err == domain.ErrInvalidState {
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrMetadataLimit {
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrProcessorTimeout {
+		return c.JSON(http.StatusGatewayTimeout, ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+// GetByMerchantReference finds a merchant's transaction by the reference
+// the merchant gave it, such as their order ID.
+func (h *TransactionHandler) GetByMerchantReference(c echo.Context) error {
+        merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+        reference := c.QueryParam("merchantReference")
+        if err != nil || merchantID == 0 || reference == "" {
 		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
 	}
-        err = h.Usecase.Update(ctx, &data)
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.GetByMerchantReference(ctx, merchantID, reference)
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
 	}
 
-        return c.NoContent(http.StatusOK)
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+// FetchChanges lists the changes patches made to a transaction.
+func (h *TransactionHandler) FetchChanges(c echo.Context) error {
+        id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.FetchChanges(ctx, id)
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        return c.JSON(http.StatusOK, res)
 }
 
 func (h *TransactionHandler) GetByID(c echo.Context) error {
@@ -81,9 +219,154 @@ func (h *TransactionHandler) GetByID(c echo.Context) error {
 
 	ctx := c.Request().Context()
         res, err := h.Usecase.GetByID(ctx, id)
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+func (h *TransactionHandler) Capture(c echo.Context) error {
+        return h.followUp(c, h.Usecase.Capture)
+}
+
+func (h *TransactionHandler) Refund(c echo.Context) error {
+        return h.followUp(c, h.Usecase.Refund)
+}
+
+func (h *TransactionHandler) Void(c echo.Context) error {
+        return h.followUp(c, h.Usecase.Void)
+}
+
+func (h *TransactionHandler) Approve(c echo.Context) error {
+        return h.followUp(c, h.Usecase.Approve)
+}
+
+func (h *TransactionHandler) Reject(c echo.Context) error {
+        return h.followUp(c, h.Usecase.Reject)
+}
+
+// FetchReviews lists a merchant's transactions held by the risk rules for
+// manual review.
+func (h *TransactionHandler) FetchReviews(c echo.Context) error {
+        merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+        if err != nil || merchantID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+        res, err := h.Usecase.FetchReviews(ctx, merchantID)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
 	}
 
         return c.JSON(http.StatusOK, res)
 }
+
+func (h *TransactionHandler) followUp(c echo.Context, call func(ctx context.Context, id int64) (domain.Transaction, error)) error {
+        idP, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+        id := int64(idP)
+
+	ctx := c.Request().Context()
+        res, err := call(ctx, id)
+	if err == domain.ErrInvalidState || err == domain.ErrStaleVersion {
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	}
+	if err == domain.ErrProcessorTimeout {
+		return c.JSON(http.StatusGatewayTimeout, ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+        c.Response().Header().Set("ETag", etag.Format(res.Version))
+        return c.JSON(http.StatusOK, res)
+}
+
+// mergePatchType reports whether a request body of the content type can be
+// read as a merge patch. Plain JSON is taken too.
+func mergePatchType(contentType string) bool {
+        if contentType == "" {
+                return true
+        }
+        mediaType, _, err := mime.ParseMediaType(contentType)
+        if err != nil {
+                return false
+        }
+
+        return mediaType == "application/merge-patch+json" || mediaType == echo.MIMEApplicationJSON
+}
+
+// decodePatch reads a merge patch's fields. A field outside patchable is a
+// domain.FieldError, reported in name order so the same patch always fails
+// the same way.
+func decodePatch(fields map[string]json.RawMessage) (domain.TransactionPatch, error) {
+        names := make([]string, 0, len(fields))
+        for name := range fields {
+                names = append(names, name)
+        }
+        sort.Strings(names)
+        for _, name := range names {
+                if patchable[name] {
+                        continue
+                }
+                if transactionFields[name] {
+                        return domain.TransactionPatch{}, &domain.FieldError{Field: name, Err: domain.ErrImmutableField}
+                }
+                return domain.TransactionPatch{}, &domain.FieldError{Field: name, Err: domain.ErrUnknownField}
+        }
+
+        p := domain.TransactionPatch{}
+        if raw, ok := fields["description"]; ok {
+                var description *string
+                err := json.Unmarshal(raw, &description)
+                if err != nil {
+                        return domain.TransactionPatch{}, err
+                }
+                if description == nil {
+                        description = new(string)
+                }
+                p.Description = description
+        }
+        if raw, ok := fields["metadata"]; ok {
+                err := json.Unmarshal(raw, &p.Metadata)
+                if err != nil {
+                        return domain.TransactionPatch{}, err
+                }
+                p.ClearMetadata = p.Metadata == nil
+        }
+        if raw, ok := fields["state"]; ok {
+                err := json.Unmarshal(raw, &p.State)
+                if err != nil || p.State == nil {
+                        return domain.TransactionPatch{}, fmt.Errorf("state must be a string")
+                }
+        }
+        if raw, ok := fields["version"]; ok {
+                err := json.Unmarshal(raw, &p.Version)
+                if err != nil {
+                        return domain.TransactionPatch{}, err
+                }
+        }
+
+        return p, nil
+}
+
+// jsonFields gives the JSON names of a struct's fields.
+func jsonFields(t reflect.Type) map[string]bool {
+        fields := map[string]bool{}
+        for i := 0; i < t.NumField(); i++ {
+                name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+                if name != "" && name != "-" {
+                        fields[name] = true
+                }
+        }
+
+        return fields
+}
@@ -140,98 +140,183 @@ func TestFailedStore(t *testing.T) {
         assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
 
-func TestUpdate(t *testing.T) {
+func TestPatch(t *testing.T) {
         mockUsecase := new(mocks.TransactionUsecase)
-        mockUsecase.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockUsecase.On("Patch", mock.Anything, int64(1), mock.MatchedBy(func(p *domain.TransactionPatch) bool {
+                note, ok := p.Metadata["note"]
+                return *p.Description == "Order A1" && ok && note == nil && *p.Metadata["channel"] == "web" && !p.ClearMetadata && p.State == nil && p.Version == 3
+        })).Return(domain.Transaction{ID: 1, Description: "Order A1", Metadata: map[string]string{"channel": "web"}, Version: 4}, nil).Once()
 
-        data := &domain.Transaction{
-                MerchantID: 1,
-                ParentMerchantID: 1,
-                SettingID: 1,
-                Status: true,
-        }
-        j, err := json.Marshal(data)
-        assert.NoError(t, err)
-
-	e := echo.New()
+        ctx, rec := patchContext(`{"description":"Order A1","metadata":{"note":null,"channel":"web"}}`, `"3"`)
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err := handler.Patch(ctx)
 
-	req, err := http.NewRequest(echo.PUT, "/transactions/1", strings.NewReader(string(j)))
         assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+        mockUsecase.AssertExpectations(t)
+}
 
-        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	rec := httptest.NewRecorder()
-	ctx := e.NewContext(req, rec)
-        ctx.SetPath("transactions/:id")
-	ctx.SetParamNames("id")
-	ctx.SetParamValues("1")
+func TestPatchClearsDescriptionAndMetadata(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("Patch", mock.Anything, int64(1), mock.MatchedBy(func(p *domain.TransactionPatch) bool {
+                return *p.Description == "" && p.ClearMetadata && *p.State == domain.TransactionVoided && p.Version == 2
+        })).Return(domain.Transaction{ID: 1, Version: 4}, nil).Once()
 
+        ctx, rec := patchContext(`{"description":null,"metadata":null,"state":"voided","version":2}`, "")
         handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
-        err = handler.Update(ctx)
+        err := handler.Patch(ctx)
 
         assert.NoError(t, err)
         assert.Equal(t, http.StatusOK, rec.Code)
+        mockUsecase.AssertExpectations(t)
 }
 
-func TestUpdateBadRequest(t *testing.T) {
-        mockUsecase := new(mocks.TransactionUsecase)
-        mockUsecase.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+func TestPatchRefusedFields(t *testing.T) {
+        tests := []struct {
+                body    string
+                field   string
+                message string
+        }{
+                {body: `{"merchantId":2}`, field: "merchantId", message: domain.ErrImmutableField.Error()},
+                {body: `{"description":"Order A1","parentMerchantId":2}`, field: "parentMerchantId", message: domain.ErrImmutableField.Error()},
+                {body: `{"settingId":3,"amount":1}`, field: "amount", message: domain.ErrImmutableField.Error()},
+                {body: `{"id":2}`, field: "id", message: domain.ErrImmutableField.Error()},
+                {body: `{"color":"RED"}`, field: "color", message: domain.ErrUnknownField.Error()},
+        }
 
-        data := &domain.Transaction{
-                ParentMerchantID: 1,
-                Status: true,
+        for _, tt := range tests {
+                mockUsecase := new(mocks.TransactionUsecase)
+
+                ctx, rec := patchContext(tt.body, `"1"`)
+                handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+                err := handler.Patch(ctx)
+
+                assert.NoError(t, err)
+                assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, tt.body)
+                var res transactionHttp.FieldResponse
+                assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+                assert.Equal(t, transactionHttp.FieldResponse{Message: tt.message, Field: tt.field}, res)
+                mockUsecase.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
         }
-        j, err := json.Marshal(data)
-        assert.NoError(t, err)
+}
 
-	e := echo.New()
+func TestPatchBadRequest(t *testing.T) {
+        for _, body := range []string{`[]`, `null`, `{"state":null}`, `{"state":1}`, `{"description":5}`, `{"metadata":{"order":1}}`, `{"metadata":"A1"}`} {
+                mockUsecase := new(mocks.TransactionUsecase)
 
-	req, err := http.NewRequest(echo.PUT, "/transactions/1", strings.NewReader(string(j)))
-        assert.NoError(t, err)
+                ctx, rec := patchContext(body, `"1"`)
+                handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+                err := handler.Patch(ctx)
 
-        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	rec := httptest.NewRecorder()
-	ctx := e.NewContext(req, rec)
-        ctx.SetPath("transactions/:id")
-	ctx.SetParamNames("id")
-	ctx.SetParamValues("1")
+                assert.NoError(t, err)
+                assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+                mockUsecase.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+        }
+}
 
+func TestPatchUnsupportedMediaType(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+
+        ctx, rec := patchContext(`{"description":"Order A1"}`, `"1"`)
+        ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMETextPlain)
         handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
-        err = handler.Update(ctx)
+        err := handler.Patch(ctx)
 
         assert.NoError(t, err)
-        assert.Equal(t, http.StatusBadRequest, rec.Code)
+        assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
 }
 
-func TestUpdateMissingParams(t *testing.T) {
-        mockUsecase := new(mocks.TransactionUsecase)
-        mockUsecase.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+func TestPatchPreconditions(t *testing.T) {
+        tests := []struct {
+                name    string
+                body    string
+                ifMatch string
+                err     error
+                code    int
+        }{
+                {name: "no version", body: `{"description":"Order A1"}`, code: http.StatusPreconditionRequired},
+                {name: "weak tag", body: `{"description":"Order A1"}`, ifMatch: `W/"3"`, code: http.StatusPreconditionFailed},
+                {name: "stale if-match", body: `{"description":"Order A1"}`, ifMatch: `"3"`, err: domain.ErrStaleVersion, code: http.StatusPreconditionFailed},
+                {name: "stale body version", body: `{"description":"Order A1","version":3}`, err: domain.ErrStaleVersion, code: http.StatusConflict},
+                {name: "refused move", body: `{"state":"captured","version":3}`, err: domain.ErrInvalidState, code: http.StatusConflict},
+                {name: "not found", body: `{"description":"Order A1","version":3}`, err: domain.ErrNotFound, code: http.StatusNotFound},
+        }
 
-        data := &domain.Transaction{
-                MerchantID: 1,
-                ParentMerchantID: 1,
-                SettingID: 1,
-                Status: true,
+        for _, tt := range tests {
+                mockUsecase := new(mocks.TransactionUsecase)
+                mockUsecase.On("Patch", mock.Anything, int64(1), mock.Anything).Return(domain.Transaction{}, tt.err).Once()
+
+                ctx, rec := patchContext(tt.body, tt.ifMatch)
+                handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+                err := handler.Patch(ctx)
+
+                assert.NoError(t, err)
+                assert.Equal(t, tt.code, rec.Code, tt.name)
+                if tt.err == nil {
+                        mockUsecase.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+                }
         }
-        j, err := json.Marshal(data)
-        assert.NoError(t, err)
+}
 
-	e := echo.New()
+func TestPatchMissingParams(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
 
-	req, err := http.NewRequest(echo.PUT, "/transactions/", strings.NewReader(string(j)))
+	req, err := http.NewRequest(echo.PATCH, "/transactions/", strings.NewReader(`{"description":"Order A1"}`))
         assert.NoError(t, err)
 
-        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+        req.Header.Set(echo.HeaderContentType, "application/merge-patch+json")
 	rec := httptest.NewRecorder()
-	ctx := e.NewContext(req, rec)
+	ctx := echo.New().NewContext(req, rec)
         ctx.SetPath("transactions/")
 
         handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
-        err = handler.Update(ctx)
+        err = handler.Patch(ctx)
 
         assert.NoError(t, err)
         assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
+func TestFetchChanges(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        changes := []domain.TransactionChange{{ID: 1, TransactionID: 1, Version: 2, Field: "description", Before: `""`, After: `"Order A1"`}}
+        mockUsecase.On("FetchChanges", mock.Anything, int64(1)).Return(changes, nil).Once()
+
+	req, err := http.NewRequest(echo.GET, "/transactions/1/changes", nil)
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(req, rec)
+        ctx.SetPath("transactions/:id/changes")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.FetchChanges(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        var res []domain.TransactionChange
+        assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+        assert.Equal(t, changes, res)
+}
+
+// patchContext gives the context of a merge patch to transaction 1.
+func patchContext(body string, ifMatch string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(echo.PATCH, "/transactions/1", strings.NewReader(body))
+        req.Header.Set(echo.HeaderContentType, "application/merge-patch+json")
+        if ifMatch != "" {
+                req.Header.Set("If-Match", ifMatch)
+        }
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(req, rec)
+        ctx.SetPath("transactions/:id")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+        return ctx, rec
+}
+
 func TestGetByID(t *testing.T) {
         data := domain.Transaction{
                 ID: 1,
@@ -294,3 +379,188 @@ func TestGetByIDInvalidParam(t *testing.T) {
         assert.NoError(t, err)
         assert.Equal(t, http.StatusNotFound, rec.Code)
 }
+
+func TestCapture(t *testing.T) {
+        data := domain.Transaction{
+                ID: 1,
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Status: true,
+                State: domain.TransactionCaptured,
+        }
+	json_data, err := json.Marshal(data)
+        assert.NoError(t, err)
+
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("Capture", mock.Anything, int64(1)).Return(data, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/transactions/1/capture", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("transactions/:id/capture")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.Capture(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Equal(t, string(json_data)+"\n", rec.Body.String())
+}
+
+func TestRefundInvalidState(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("Refund", mock.Anything, int64(1)).Return(domain.Transaction{}, domain.ErrInvalidState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/transactions/1/refund", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("transactions/:id/refund")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.Refund(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestFetchReviews(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("FetchReviews", mock.Anything, int64(6)).Return([]domain.Transaction{
+                {ID: 4, MerchantID: 6, State: domain.TransactionReview, RiskDecision: domain.RiskReview, RiskRules: []string{"big ticket"}},
+        }, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/transactions/reviews?merchantId=6", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.FetchReviews(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, rec.Code)
+        assert.Contains(t, rec.Body.String(), `"riskRules":["big ticket"]`)
+}
+
+func TestGetByMerchantReference(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("GetByMerchantReference", mock.Anything, int64(6), "order-1").Return(domain.Transaction{
+                ID: 4, MerchantID: 6, MerchantReference: "order-1", Metadata: map[string]string{"channel": "web"}, Version: 2,
+        }, nil).Once()
+        mockUsecase.On("GetByMerchantReference", mock.Anything, int64(6), "order-2").Return(domain.Transaction{}, domain.ErrNotFound).Once()
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+
+        tests := []struct {
+                query string
+                code  int
+        }{
+                {query: "merchantId=6&merchantReference=order-1", code: http.StatusOK},
+                {query: "merchantId=6&merchantReference=order-2", code: http.StatusNotFound},
+                {query: "merchantId=6", code: http.StatusBadRequest},
+                {query: "merchantReference=order-1", code: http.StatusBadRequest},
+        }
+        for _, tt := range tests {
+	        req := httptest.NewRequest(echo.GET, "/transactions/lookup?"+tt.query, nil)
+	        rec := httptest.NewRecorder()
+	        ctx := echo.New().NewContext(req, rec)
+
+                err := handler.GetByMerchantReference(ctx)
+
+                assert.NoError(t, err)
+                assert.Equal(t, tt.code, rec.Code, tt.query)
+                if tt.code == http.StatusOK {
+                        assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+                        assert.Contains(t, rec.Body.String(), `"merchantReference":"order-1","metadata":{"channel":"web"}`)
+                }
+        }
+        mockUsecase.AssertExpectations(t)
+}
+
+func TestStoreMerchantReferenceAndMetadata(t *testing.T) {
+        tests := []struct {
+                err  error
+                code int
+        }{
+                {err: domain.ErrReferenceTaken, code: http.StatusConflict},
+                {err: domain.ErrReference, code: http.StatusUnprocessableEntity},
+                {err: domain.ErrMetadataLimit, code: http.StatusUnprocessableEntity},
+        }
+
+        for _, tt := range tests {
+                mockUsecase := new(mocks.TransactionUsecase)
+                mockUsecase.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+                        return t.MerchantReference == "order-1" && t.Metadata["channel"] == "web"
+                })).Return(tt.err).Once()
+
+	        req := httptest.NewRequest(echo.POST, "/transactions", strings.NewReader(`{"merchantId":1,"parentMerchantId":1,"settingId":1,"merchantReference":"order-1","metadata":{"channel":"web"}}`))
+                req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	        rec := httptest.NewRecorder()
+	        ctx := echo.New().NewContext(req, rec)
+
+                handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+                err := handler.Store(ctx)
+
+                assert.NoError(t, err)
+                assert.Equal(t, tt.code, rec.Code, tt.err.Error())
+                assert.Contains(t, rec.Body.String(), tt.err.Error())
+        }
+}
+
+func TestApproveInvalidState(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("Approve", mock.Anything, int64(1)).Return(domain.Transaction{}, domain.ErrInvalidState).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/transactions/1/approve", strings.NewReader(""))
+        assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("transactions/:id/approve")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.Approve(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestStoreOverLimit(t *testing.T) {
+        mockUsecase := new(mocks.TransactionUsecase)
+        mockUsecase.On("Store", mock.Anything, mock.Anything).Return(&domain.LimitError{
+                LimitStatus: domain.LimitStatus{Limit: domain.LimitDailyVolume, PaymentType: "CARD", Cap: 500000, Used: 450000, Remaining: 50000},
+        }).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/transactions", strings.NewReader(`{"merchantId":1,"parentMerchantId":1,"settingId":1,"amount":100000}`))
+        assert.NoError(t, err)
+
+        req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+        ctx.SetPath("/transactions")
+
+        handler := transactionHttp.NewTransactionHandler(echo.New(), mockUsecase)
+        err = handler.Store(ctx)
+
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+        assert.Equal(t, domain.LimitDailyVolume, rec.Header().Get("X-Limit"))
+        assert.Equal(t, "50000", rec.Header().Get("X-Limit-Remaining"))
+        assert.Contains(t, rec.Body.String(), `"remaining":50000`)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/memory"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.TransactionRepository(t, func(t *testing.T) domain.TransactionRepository {
+		return transactionRepo.NewTransactionRepository()
+	})
+}
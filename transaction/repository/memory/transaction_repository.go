@@ -0,0 +1,191 @@
+// Package memory keeps transactions in process memory, for tests and demos
+// that should not need a database. It behaves like the SQL repositories.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// TransactionRepository is a domain.TransactionRepository safe for
+// concurrent use.
+type TransactionRepository struct {
+	mu           sync.RWMutex
+	transactions map[int64]domain.Transaction
+	changes      []domain.TransactionChange
+	lastID       int64
+}
+
+var _ domain.TransactionRepository = (*TransactionRepository)(nil)
+
+func NewTransactionRepository() *TransactionRepository {
+	return &TransactionRepository{
+		transactions: map[int64]domain.Transaction{},
+	}
+}
+
+func (tr *TransactionRepository) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	t, ok := tr.transactions[id]
+	if !ok {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+
+	return copyTransaction(t), nil
+}
+
+// Store adds a transaction. Like the unique index in the SQL
+// repositories, it fails with domain.ErrReferenceTaken if the merchant
+// already has a transaction with the same non-empty MerchantReference.
+func (tr *TransactionRepository) Store(ctx context.Context, t *domain.Transaction) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	if tr.referenceTaken(*t) {
+		return domain.ErrReferenceTaken
+	}
+	tr.lastID++
+	t.ID = tr.lastID
+	t.Version = 1
+	tr.transactions[t.ID] = copyTransaction(*t)
+
+	return nil
+}
+
+// Update writes the transaction back over the version it was read at and
+// bumps its version. It fails with domain.ErrStaleVersion if the
+// transaction was changed since, or domain.ErrNotFound if there is no such
+// transaction. CreatedAt keeps its stored value, as in the SQL
+// repositories.
+func (tr *TransactionRepository) Update(ctx context.Context, t *domain.Transaction) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	stored, ok := tr.transactions[t.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Version != t.Version {
+		return domain.ErrStaleVersion
+	}
+	if tr.referenceTaken(*t) {
+		return domain.ErrReferenceTaken
+	}
+	t.Version++
+	updated := copyTransaction(*t)
+	updated.CreatedAt = stored.CreatedAt
+	tr.transactions[t.ID] = updated
+
+	return nil
+}
+
+// FetchByState lists a merchant's transactions in the given state, oldest
+// first.
+func (tr *TransactionRepository) FetchByState(ctx context.Context, merchantID int64, state string) ([]domain.Transaction, error) {
+	result := []domain.Transaction{}
+	for _, t := range tr.All() {
+		if t.MerchantID == merchantID && t.State == state {
+			result = append(result, t)
+		}
+	}
+
+	return result, nil
+}
+
+// GetByMerchantReference finds the merchant's transaction with the
+// reference the merchant gave it.
+func (tr *TransactionRepository) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+	for _, t := range tr.All() {
+		if t.MerchantID == merchantID && t.MerchantReference == reference {
+			return t, nil
+		}
+	}
+
+	return domain.Transaction{}, domain.ErrNotFound
+}
+
+// referenceTaken reports whether another of the merchant's transactions
+// has t's MerchantReference. tr.mu must be held.
+func (tr *TransactionRepository) referenceTaken(t domain.Transaction) bool {
+	if t.MerchantReference == "" {
+		return false
+	}
+	for _, other := range tr.transactions {
+		if other.ID != t.ID && other.MerchantID == t.MerchantID && other.MerchantReference == t.MerchantReference {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StoreChanges appends to the audit trail of patched transactions.
+func (tr *TransactionRepository) StoreChanges(ctx context.Context, changes []domain.TransactionChange) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	for i := range changes {
+		changes[i].ID = int64(len(tr.changes) + 1)
+		c := changes[i]
+		c.CreatedAt = c.CreatedAt.UTC()
+		tr.changes = append(tr.changes, c)
+	}
+
+	return nil
+}
+
+// FetchChanges lists a transaction's audit trail, oldest first.
+func (tr *TransactionRepository) FetchChanges(ctx context.Context, transactionID int64) ([]domain.TransactionChange, error) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	result := []domain.TransactionChange{}
+	for _, c := range tr.changes {
+		if c.TransactionID == transactionID {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+// All lists every transaction by ID, for the in-memory risk repository to
+// count.
+func (tr *TransactionRepository) All() []domain.Transaction {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	result := make([]domain.Transaction, 0, len(tr.transactions))
+	for _, t := range tr.transactions {
+		result = append(result, copyTransaction(t))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result
+}
+
+// copyTransaction detaches the risk rules and metadata. None read back as
+// nil and times as UTC, as from the SQL repositories.
+func copyTransaction(t domain.Transaction) domain.Transaction {
+	if len(t.RiskRules) == 0 {
+		t.RiskRules = nil
+	} else {
+		t.RiskRules = append([]string{}, t.RiskRules...)
+	}
+	metadata := t.Metadata
+	t.Metadata = nil
+	for k, v := range metadata {
+		if t.Metadata == nil {
+			t.Metadata = map[string]string{}
+		}
+		t.Metadata[k] = v
+	}
+	t.CreatedAt = t.CreatedAt.UTC()
+
+	return t
+}
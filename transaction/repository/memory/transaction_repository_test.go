@@ -0,0 +1,33 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/memory"
+)
+
+func TestConcurrentStoreAndUpdate(t *testing.T) {
+	tr := transactionRepo.NewTransactionRepository()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := domain.Transaction{MerchantID: 1, State: domain.TransactionPending}
+			assert.NoError(t, tr.Store(context.TODO(), &data))
+			data.State = domain.TransactionCaptured
+			assert.NoError(t, tr.Update(context.TODO(), &data))
+		}()
+	}
+	wg.Wait()
+
+	res, err := tr.FetchByState(context.TODO(), 1, domain.TransactionCaptured)
+	assert.NoError(t, err)
+	assert.Len(t, res, 50)
+}
@@ -0,0 +1,396 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const transactionColumns = "id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version"
+
+type postgresTransactionRepo struct {
+	DB *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) domain.TransactionRepository {
+	return &postgresTransactionRepo{
+		DB: db,
+	}
+}
+
+func (tr *postgresTransactionRepo) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE id=$1"
+
+	data, err := scanTransaction(tr.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Transaction{}, err
+	}
+
+	return data, nil
+}
+
+func (tr *postgresTransactionRepo) Store(ctx context.Context, t *domain.Transaction) error {
+	query := "INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41) RETURNING id, version"
+
+	metadata, err := encodeMetadata(t.Metadata)
+	if err != nil {
+		return err
+	}
+
+	err = tr.DB.QueryRowContext(
+		ctx,
+		query,
+		t.MerchantID,
+		t.ParentMerchantID,
+		t.SettingID,
+		t.Status,
+		t.Amount,
+		t.Currency,
+		t.PaymentType,
+		t.State,
+		t.Processor,
+		t.ProcessorReference,
+		t.ResponseCode,
+		t.ResponseMessage,
+		t.RoutingDecision,
+		t.CardToken,
+		t.CardLast4,
+		t.CardBrand,
+		t.CardFingerprint,
+		t.CustomerID,
+		t.PaymentMethodID,
+		t.PaymentCode,
+		t.InstallmentPlanID,
+		t.InstallmentTenor,
+		t.Fee,
+		t.SettlementAmount,
+		t.PromoCode,
+		t.PromotionID,
+		t.OriginalAmount,
+		t.Discount,
+		t.SettlementCurrency,
+		t.FXQuoteID,
+		t.FXRate,
+		t.FXMarkup,
+		t.IPAddress,
+		t.IPCountry,
+		t.BillingCountry,
+		t.RiskDecision,
+		riskRules(t.RiskRules),
+		t.MerchantReference,
+		t.Description,
+		metadata,
+		t.CreatedAt,
+	).Scan(&t.ID, &t.Version)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return referenceError(err)
+	}
+
+	return nil
+}
+
+// Update writes the transaction back over the version it was read at and
+// bumps its version. It fails with domain.ErrStaleVersion if the
+// transaction was changed since, or domain.ErrNotFound if there is no such
+// transaction.
+func (tr *postgresTransactionRepo) Update(ctx context.Context, t *domain.Transaction) error {
+	query := "UPDATE transactions SET merchant_id=$1, parent_merchant_id=$2, setting_id=$3, status=$4, amount=$5, currency=$6, payment_type=$7, state=$8, processor=$9, processor_reference=$10, response_code=$11, response_message=$12, routing_decision=$13, card_token=$14, card_last4=$15, card_brand=$16, card_fingerprint=$17, customer_id=$18, payment_method_id=$19, payment_code=$20, installment_plan_id=$21, installment_tenor=$22, fee=$23, settlement_amount=$24, promo_code=$25, promotion_id=$26, original_amount=$27, discount=$28, settlement_currency=$29, fx_quote_id=$30, fx_rate=$31, fx_markup=$32, ip_address=$33, ip_country=$34, billing_country=$35, risk_decision=$36, risk_rules=$37, merchant_reference=$38, description=$39, metadata=$40, version=version+1 WHERE id=$41 AND version=$42"
+
+	metadata, err := encodeMetadata(t.Metadata)
+	if err != nil {
+		return err
+	}
+
+	res, err := tr.DB.ExecContext(
+		ctx,
+		query,
+		t.MerchantID,
+		t.ParentMerchantID,
+		t.SettingID,
+		t.Status,
+		t.Amount,
+		t.Currency,
+		t.PaymentType,
+		t.State,
+		t.Processor,
+		t.ProcessorReference,
+		t.ResponseCode,
+		t.ResponseMessage,
+		t.RoutingDecision,
+		t.CardToken,
+		t.CardLast4,
+		t.CardBrand,
+		t.CardFingerprint,
+		t.CustomerID,
+		t.PaymentMethodID,
+		t.PaymentCode,
+		t.InstallmentPlanID,
+		t.InstallmentTenor,
+		t.Fee,
+		t.SettlementAmount,
+		t.PromoCode,
+		t.PromotionID,
+		t.OriginalAmount,
+		t.Discount,
+		t.SettlementCurrency,
+		t.FXQuoteID,
+		t.FXRate,
+		t.FXMarkup,
+		t.IPAddress,
+		t.IPCountry,
+		t.BillingCountry,
+		t.RiskDecision,
+		riskRules(t.RiskRules),
+		t.MerchantReference,
+		t.Description,
+		metadata,
+		t.ID,
+		t.Version,
+	)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return referenceError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return tr.missingOrStale(ctx, t.ID)
+	}
+
+	t.Version++
+	return nil
+}
+
+// missingOrStale tells why an update matched no row.
+func (tr *postgresTransactionRepo) missingOrStale(ctx context.Context, id int64) error {
+	query := "SELECT EXISTS (SELECT 1 FROM transactions WHERE id=$1)"
+
+	var exists bool
+	err := tr.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if exists {
+		return domain.ErrStaleVersion
+	}
+
+	return domain.ErrNotFound
+}
+
+// FetchByState lists a merchant's transactions in the given state, oldest
+// first.
+func (tr *postgresTransactionRepo) FetchByState(ctx context.Context, merchantID int64, state string) ([]domain.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE merchant_id=$1 AND state=$2 ORDER BY id"
+
+	rows, err := tr.DB.QueryContext(ctx, query, merchantID, state)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Transaction{}
+	for rows.Next() {
+		data, err := scanTransaction(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// StoreChanges appends to the audit trail of patched transactions.
+func (tr *postgresTransactionRepo) StoreChanges(ctx context.Context, changes []domain.TransactionChange) error {
+	query := "INSERT INTO transaction_changes (transaction_id, version, field, before, after, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	for i := range changes {
+		c := &changes[i]
+		err := tr.DB.QueryRowContext(ctx, query, c.TransactionID, c.Version, c.Field, c.Before, c.After, c.CreatedAt).Scan(&c.ID)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchChanges lists a transaction's audit trail, oldest first.
+func (tr *postgresTransactionRepo) FetchChanges(ctx context.Context, transactionID int64) ([]domain.TransactionChange, error) {
+	query := "SELECT id, transaction_id, version, field, before, after, created_at FROM transaction_changes WHERE transaction_id=$1 ORDER BY id"
+
+	rows, err := tr.DB.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.TransactionChange{}
+	for rows.Next() {
+		c := domain.TransactionChange{}
+		err = rows.Scan(&c.ID, &c.TransactionID, &c.Version, &c.Field, &c.Before, &c.After, &c.CreatedAt)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		c.CreatedAt = c.CreatedAt.UTC()
+		result = append(result, c)
+	}
+
+	return result, rows.Err()
+}
+
+// GetByMerchantReference finds the merchant's transaction with the
+// reference the merchant gave it.
+func (tr *postgresTransactionRepo) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE merchant_id=$1 AND merchant_reference=$2"
+
+	data, err := scanTransaction(tr.DB.QueryRowContext(ctx, query, merchantID, reference))
+	if err == sql.ErrNoRows {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.Transaction{}, err
+	}
+
+	return data, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction reads a transaction row. Times come back in UTC, as the
+// rest of the gateway keeps them, and empty metadata as nil.
+func scanTransaction(row scanner) (domain.Transaction, error) {
+	var rules pq.StringArray
+	var metadata []byte
+	var parentMerchantID sql.NullInt64
+	data := domain.Transaction{}
+
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&parentMerchantID,
+		&data.SettingID,
+		&data.Status,
+		&data.Amount,
+		&data.Currency,
+		&data.PaymentType,
+		&data.State,
+		&data.Processor,
+		&data.ProcessorReference,
+		&data.ResponseCode,
+		&data.ResponseMessage,
+		&data.RoutingDecision,
+		&data.CardToken,
+		&data.CardLast4,
+		&data.CardBrand,
+		&data.CardFingerprint,
+		&data.CustomerID,
+		&data.PaymentMethodID,
+		&data.PaymentCode,
+		&data.InstallmentPlanID,
+		&data.InstallmentTenor,
+		&data.Fee,
+		&data.SettlementAmount,
+		&data.PromoCode,
+		&data.PromotionID,
+		&data.OriginalAmount,
+		&data.Discount,
+		&data.SettlementCurrency,
+		&data.FXQuoteID,
+		&data.FXRate,
+		&data.FXMarkup,
+		&data.IPAddress,
+		&data.IPCountry,
+		&data.BillingCountry,
+		&data.RiskDecision,
+		&rules,
+		&data.MerchantReference,
+		&data.Description,
+		&metadata,
+		&data.CreatedAt,
+		&data.Version,
+	)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	data.ParentMerchantID = parentMerchantID.Int64
+	data.CreatedAt = data.CreatedAt.UTC()
+	if len(rules) > 0 {
+		data.RiskRules = []string(rules)
+	}
+	err = json.Unmarshal(metadata, &data.Metadata)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if len(data.Metadata) == 0 {
+		data.Metadata = nil
+	}
+
+	return data, nil
+}
+
+// encodeMetadata gives the JSON object stored for the metadata, empty
+// rather than NULL when there is none.
+func encodeMetadata(m map[string]string) (string, error) {
+	if len(m) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+// riskRules stores the rules as a text array, empty rather than NULL when
+// none fired.
+func riskRules(rules []string) pq.StringArray {
+	if rules == nil {
+		return pq.StringArray{}
+	}
+
+	return pq.StringArray(rules)
+}
+
+// referenceError turns a violation of the unique (merchant_id,
+// merchant_reference) index into domain.ErrReferenceTaken, for a request
+// that got past the usecase's GetByMerchantReference check concurrently
+// with another using the same reference.
+func referenceError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "transactions_merchant_reference" {
+		return domain.ErrReferenceTaken
+	}
+	return err
+}
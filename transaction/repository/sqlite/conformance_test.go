@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.TransactionRepository(t, func(t *testing.T) domain.TransactionRepository {
+		return transactionRepo.NewTransactionRepository(migrationtest.NewDB(t))
+	})
+}
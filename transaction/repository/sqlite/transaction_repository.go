@@ -3,11 +3,18 @@ package sqlite
 import (
 	"context"
         "database/sql"
+        "encoding/json"
+        "errors"
         "log"
+        "strings"
+
+	"github.com/mattn/go-sqlite3"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
 )
 
+const transactionColumns = "id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version"
+
 type sqliteTransactionRepo struct {
 	DB *sql.DB
 }
@@ -19,7 +26,7 @@ func NewTransactionRepository(db *sql.DB) domain.TransactionRepository {
 }
 
 func (tr *sqliteTransactionRepo) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
-        query := "SELECT id, merchant_id, parent_merchant_id, setting_id, status FROM transactions WHERE id=? LIMIT 1"
+        query := "SELECT " + transactionColumns + " FROM transactions WHERE id=? LIMIT 1"
 
         rows, err := tr.DB.Query(query, id)
         if err != nil {
@@ -29,29 +36,24 @@ func (tr *sqliteTransactionRepo) GetByID(ctx context.Context, id int64) (domain.
         }
         defer rows.Close()
 
-        var rawStatus int
-        data := domain.Transaction{}
-
-        rows.Next()
-        err = rows.Scan(
-                &data.ID,
-                &data.MerchantID,
-                &data.ParentMerchantID,
-                &data.SettingID,
-                &rawStatus,
-        )
+        if !rows.Next() {
+                err = rows.Err()
+                if err == nil {
+                        err = domain.ErrNotFound
+                }
+                return domain.Transaction{}, err
+        }
+        data, err := scanTransaction(rows)
         if err != nil {
                 log.Println(query)
                 log.Println(err)
                 return domain.Transaction{}, err
         }
 
-        data.Status = rawStatus != 0
-
         return data, nil
 }
 func (tr *sqliteTransactionRepo) Store(ctx context.Context, t *domain.Transaction) error {
-        query := "INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status) values (?, ?, ?, ?)"
+        query := "INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
         stmt, err := tr.DB.PrepareContext(ctx, query)
         if err != nil {
@@ -60,12 +62,60 @@ func (tr *sqliteTransactionRepo) Store(ctx context.Context, t *domain.Transactio
                 return err
         }
 
+        metadata, err := encodeMetadata(t.Metadata)
+        if err != nil {
+                return err
+        }
+
         status := btoi(t.Status)
-        res, err := stmt.ExecContext(ctx, t.MerchantID, t.ParentMerchantID, t.SettingID, status)
+        res, err := stmt.ExecContext(
+                ctx,
+                t.MerchantID,
+                t.ParentMerchantID,
+                t.SettingID,
+                status,
+                t.Amount,
+                t.Currency,
+                t.PaymentType,
+                t.State,
+                t.Processor,
+                t.ProcessorReference,
+                t.ResponseCode,
+                t.ResponseMessage,
+                t.RoutingDecision,
+                t.CardToken,
+                t.CardLast4,
+                t.CardBrand,
+                t.CardFingerprint,
+                t.CustomerID,
+                t.PaymentMethodID,
+                t.PaymentCode,
+                t.InstallmentPlanID,
+                t.InstallmentTenor,
+                t.Fee,
+                t.SettlementAmount,
+                t.PromoCode,
+                t.PromotionID,
+                t.OriginalAmount,
+                t.Discount,
+                t.SettlementCurrency,
+                t.FXQuoteID,
+                t.FXRate,
+                t.FXMarkup,
+                t.IPAddress,
+                t.IPCountry,
+                t.BillingCountry,
+                t.RiskDecision,
+                strings.Join(t.RiskRules, ","),
+                t.MerchantReference,
+                t.Description,
+                metadata,
+                t.CreatedAt,
+        )
         if err != nil {
                 log.Println(query)
                 log.Println(err)
-                return err
+                return referenceError(err)
         }
 
         lastID, err := res.LastInsertId()
@@ -76,12 +126,22 @@ func (tr *sqliteTransactionRepo) Store(ctx context.Context, t *domain.Transactio
         }
 
         t.ID = lastID
+        t.Version = 1
 
         return nil
 
 }
+// Update writes the transaction back over the version it was read at and
+// bumps its version. It fails with domain.ErrStaleVersion if the
+// transaction was changed since, or domain.ErrNotFound if there is no such
+// transaction.
 func (tr *sqliteTransactionRepo) Update(ctx context.Context, t *domain.Transaction) error {
-        query := "UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=? WHERE id=?"
+        query := "UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=?, amount=?, currency=?, payment_type=?, state=?, processor=?, processor_reference=?, response_code=?, response_message=?, routing_decision=?, card_token=?, card_last4=?, card_brand=?, card_fingerprint=?, customer_id=?, payment_method_id=?, payment_code=?, installment_plan_id=?, installment_tenor=?, fee=?, settlement_amount=?, promo_code=?, promotion_id=?, original_amount=?, discount=?, settlement_currency=?, fx_quote_id=?, fx_rate=?, fx_markup=?, ip_address=?, ip_country=?, billing_country=?, risk_decision=?, risk_rules=?, merchant_reference=?, description=?, metadata=?, version=version+1 WHERE id=? AND version=?"
+
+        metadata, err := encodeMetadata(t.Metadata)
+        if err != nil {
+                return err
+        }
 
         stmt, err := tr.DB.PrepareContext(ctx, query)
         if err != nil {
@@ -90,19 +150,292 @@ func (tr *sqliteTransactionRepo) Update(ctx context.Context, t *domain.Transacti
                 return err
         }
 
-        _, err = stmt.ExecContext(ctx, t.MerchantID, t.ParentMerchantID, t.SettingID, t.Status, t.ID)
+        res, err := stmt.ExecContext(
+                ctx,
+                t.MerchantID,
+                t.ParentMerchantID,
+                t.SettingID,
+                t.Status,
+                t.Amount,
+                t.Currency,
+                t.PaymentType,
+                t.State,
+                t.Processor,
+                t.ProcessorReference,
+                t.ResponseCode,
+                t.ResponseMessage,
+                t.RoutingDecision,
+                t.CardToken,
+                t.CardLast4,
+                t.CardBrand,
+                t.CardFingerprint,
+                t.CustomerID,
+                t.PaymentMethodID,
+                t.PaymentCode,
+                t.InstallmentPlanID,
+                t.InstallmentTenor,
+                t.Fee,
+                t.SettlementAmount,
+                t.PromoCode,
+                t.PromotionID,
+                t.OriginalAmount,
+                t.Discount,
+                t.SettlementCurrency,
+                t.FXQuoteID,
+                t.FXRate,
+                t.FXMarkup,
+                t.IPAddress,
+                t.IPCountry,
+                t.BillingCountry,
+                t.RiskDecision,
+                strings.Join(t.RiskRules, ","),
+                t.MerchantReference,
+                t.Description,
+                metadata,
+                t.ID,
+                t.Version,
+        )
         if err != nil {
                 log.Println(query)
                 log.Println(err)
+                return referenceError(err)
+        }
+
+        n, err := res.RowsAffected()
+        if err != nil {
                 return err
         }
+        if n == 0 {
+                return tr.missingOrStale(ctx, t.ID)
+        }
+
+        t.Version++
+        return nil
+}
+
+// missingOrStale tells why an update matched no row.
+func (tr *sqliteTransactionRepo) missingOrStale(ctx context.Context, id int64) error {
+        query := "SELECT EXISTS (SELECT 1 FROM transactions WHERE id=?)"
+
+        var exists bool
+        err := tr.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return err
+        }
+        if exists {
+                return domain.ErrStaleVersion
+        }
+
+        return domain.ErrNotFound
+}
+
+// FetchByState lists a merchant's transactions in the given state, oldest
+// first.
+func (tr *sqliteTransactionRepo) FetchByState(ctx context.Context, merchantID int64, state string) ([]domain.Transaction, error) {
+        query := "SELECT " + transactionColumns + " FROM transactions WHERE merchant_id=? AND state=? ORDER BY id"
+
+        rows, err := tr.DB.QueryContext(ctx, query, merchantID, state)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.Transaction{}
+        for rows.Next() {
+                data, err := scanTransaction(rows)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, data)
+        }
+
+        return result, rows.Err()
+}
+
+// StoreChanges appends to the audit trail of patched transactions.
+func (tr *sqliteTransactionRepo) StoreChanges(ctx context.Context, changes []domain.TransactionChange) error {
+        query := "INSERT INTO transaction_changes(transaction_id, version, field, before, after, created_at) VALUES(?, ?, ?, ?, ?, ?)"
+
+        for i := range changes {
+                c := &changes[i]
+                res, err := tr.DB.ExecContext(ctx, query, c.TransactionID, c.Version, c.Field, c.Before, c.After, c.CreatedAt)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return err
+                }
+                c.ID, err = res.LastInsertId()
+                if err != nil {
+                        return err
+                }
+        }
 
         return nil
 }
 
+// FetchChanges lists a transaction's audit trail, oldest first.
+func (tr *sqliteTransactionRepo) FetchChanges(ctx context.Context, transactionID int64) ([]domain.TransactionChange, error) {
+        query := "SELECT id, transaction_id, version, field, before, after, created_at FROM transaction_changes WHERE transaction_id=? ORDER BY id"
+
+        rows, err := tr.DB.QueryContext(ctx, query, transactionID)
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return nil, err
+        }
+        defer rows.Close()
+
+        result := []domain.TransactionChange{}
+        for rows.Next() {
+                c := domain.TransactionChange{}
+                err = rows.Scan(&c.ID, &c.TransactionID, &c.Version, &c.Field, &c.Before, &c.After, &c.CreatedAt)
+                if err != nil {
+                        log.Println(query)
+                        log.Println(err)
+                        return nil, err
+                }
+                result = append(result, c)
+        }
+
+        return result, rows.Err()
+}
+
+// GetByMerchantReference finds the merchant's transaction with the
+// reference the merchant gave it.
+func (tr *sqliteTransactionRepo) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+        query := "SELECT " + transactionColumns + " FROM transactions WHERE merchant_id=? AND merchant_reference=? LIMIT 1"
+
+        data, err := scanTransaction(tr.DB.QueryRowContext(ctx, query, merchantID, reference))
+        if err == sql.ErrNoRows {
+                return domain.Transaction{}, domain.ErrNotFound
+        }
+        if err != nil {
+                log.Println(query)
+                log.Println(err)
+                return domain.Transaction{}, err
+        }
+
+        return data, nil
+}
+
+type scanner interface {
+        Scan(dest ...interface{}) error
+}
+
+// scanTransaction reads a transaction row. Risk rules are stored comma
+// separated and metadata as a JSON object.
+func scanTransaction(row scanner) (domain.Transaction, error) {
+        var rawStatus int
+        var riskRules string
+        var metadata string
+        data := domain.Transaction{}
+
+        err := row.Scan(
+                &data.ID,
+                &data.MerchantID,
+                &data.ParentMerchantID,
+                &data.SettingID,
+                &rawStatus,
+                &data.Amount,
+                &data.Currency,
+                &data.PaymentType,
+                &data.State,
+                &data.Processor,
+                &data.ProcessorReference,
+                &data.ResponseCode,
+                &data.ResponseMessage,
+                &data.RoutingDecision,
+                &data.CardToken,
+                &data.CardLast4,
+                &data.CardBrand,
+                &data.CardFingerprint,
+                &data.CustomerID,
+                &data.PaymentMethodID,
+                &data.PaymentCode,
+                &data.InstallmentPlanID,
+                &data.InstallmentTenor,
+                &data.Fee,
+                &data.SettlementAmount,
+                &data.PromoCode,
+                &data.PromotionID,
+                &data.OriginalAmount,
+                &data.Discount,
+                &data.SettlementCurrency,
+                &data.FXQuoteID,
+                &data.FXRate,
+                &data.FXMarkup,
+                &data.IPAddress,
+                &data.IPCountry,
+                &data.BillingCountry,
+                &data.RiskDecision,
+                &riskRules,
+                &data.MerchantReference,
+                &data.Description,
+                &metadata,
+                &data.CreatedAt,
+                &data.Version,
+        )
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        data.Status = rawStatus != 0
+        if riskRules != "" {
+                data.RiskRules = strings.Split(riskRules, ",")
+        }
+        data.Metadata, err = decodeMetadata(metadata)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        return data, nil
+}
+
+func encodeMetadata(m map[string]string) (string, error) {
+        if len(m) == 0 {
+                return "{}", nil
+        }
+        b, err := json.Marshal(m)
+        return string(b), err
+}
+
+// decodeMetadata reads back a metadata object, leaving an empty one nil.
+func decodeMetadata(raw string) (map[string]string, error) {
+        m := map[string]string{}
+        if raw != "" {
+                err := json.Unmarshal([]byte(raw), &m)
+                if err != nil {
+                        return nil, err
+                }
+        }
+        if len(m) == 0 {
+                return nil, nil
+        }
+
+        return m, nil
+}
+
 func btoi(b bool) int {
     if b {
         return 1
     }
     return 0
  }
+
+// referenceError turns a violation of the unique (merchant_id,
+// merchant_reference) index into domain.ErrReferenceTaken, for a request
+// that got past the usecase's GetByMerchantReference check concurrently
+// with another using the same reference.
+func referenceError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), "merchant_reference") {
+		return domain.ErrReferenceTaken
+	}
+	return err
+}
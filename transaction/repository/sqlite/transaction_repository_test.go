@@ -5,11 +5,13 @@ import (
         "fmt"
 	"testing"
         "regexp"
+        "time"
 
         "github.com/stretchr/testify/assert"
         sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
 	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
 )
 
@@ -25,10 +27,25 @@ func TestGetByIDSuccess(t *testing.T) {
                 ParentMerchantID: 2,
                 SettingID: 1,
                 Status: true,
+                Amount: 10000,
+                Currency: "IDR",
+                PaymentType: "CARD",
+                State: domain.TransactionAuthorized,
+                Processor: "simulator",
+                ProcessorReference: "simulator-1",
+                ResponseCode: domain.ResponseApproved,
+                ResponseMessage: "Approved",
+                RoutingDecision: "rule=default attempts=simulator:00",
+                CardToken: "tok_1",
+                CardLast4: "1111",
+                CardBrand: "VISA",
+                CustomerID: 3,
+                PaymentMethodID: 5,
+                Version: 4,
         }
 
-        rows := sqlmock.NewRows([]string{"id", "merchant_id", "parent_merchant_id", "setting_id", "status"}).AddRow(data.ID, data.MerchantID, data.ParentMerchantID, data.SettingID, 1)
-        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status FROM transactions WHERE id=? LIMIT 1")
+        rows := sqlmock.NewRows([]string{"id", "merchant_id", "parent_merchant_id", "setting_id", "status", "amount", "currency", "payment_type", "state", "processor", "processor_reference", "response_code", "response_message", "routing_decision", "card_token", "card_last4", "card_brand", "card_fingerprint", "customer_id", "payment_method_id", "payment_code", "installment_plan_id", "installment_tenor", "fee", "settlement_amount", "promo_code", "promotion_id", "original_amount", "discount", "settlement_currency", "fx_quote_id", "fx_rate", "fx_markup", "ip_address", "ip_country", "billing_country", "risk_decision", "risk_rules", "merchant_reference", "description", "metadata", "created_at", "version"}).AddRow(data.ID, data.MerchantID, data.ParentMerchantID, data.SettingID, 1, data.Amount, data.Currency, data.PaymentType, data.State, data.Processor, data.ProcessorReference, data.ResponseCode, data.ResponseMessage, data.RoutingDecision, data.CardToken, data.CardLast4, data.CardBrand, data.CardFingerprint, data.CustomerID, data.PaymentMethodID, data.PaymentCode, data.InstallmentPlanID, data.InstallmentTenor, data.Fee, data.SettlementAmount, data.PromoCode, data.PromotionID, data.OriginalAmount, data.Discount, data.SettlementCurrency, data.FXQuoteID, data.FXRate, data.FXMarkup, data.IPAddress, data.IPCountry, data.BillingCountry, data.RiskDecision, "", data.MerchantReference, data.Description, "{}", data.CreatedAt, data.Version)
+        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version FROM transactions WHERE id=? LIMIT 1")
 
         mock.ExpectQuery(query).WillReturnRows(rows)
         tr := transactionRepo.NewTransactionRepository(db)
@@ -44,7 +61,7 @@ func TestGetByIDError(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status FROM transactions WHERE id=? LIMIT 1")
+        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version FROM transactions WHERE id=? LIMIT 1")
 
         mock.ExpectQuery(query).WillReturnError(fmt.Errorf("some error"))
         tr := transactionRepo.NewTransactionRepository(db)
@@ -59,13 +76,14 @@ func TestGetByIDNoRows(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 
-        rows := sqlmock.NewRows([]string{"id", "merchant_id", "parent_merchant_id", "setting_id", "status"})
-        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status FROM transactions WHERE id=? LIMIT 1")
+        rows := sqlmock.NewRows([]string{"id", "merchant_id", "parent_merchant_id", "setting_id", "status", "amount", "currency", "payment_type", "state", "processor", "processor_reference", "response_code", "response_message", "routing_decision", "card_token", "card_last4", "card_brand", "card_fingerprint", "customer_id", "payment_method_id", "payment_code", "installment_plan_id", "installment_tenor", "fee", "settlement_amount", "promo_code", "promotion_id", "original_amount", "discount", "settlement_currency", "fx_quote_id", "fx_rate", "fx_markup", "ip_address", "ip_country", "billing_country", "risk_decision", "risk_rules", "merchant_reference", "description", "metadata", "created_at", "version"})
+        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version FROM transactions WHERE id=? LIMIT 1")
 
         mock.ExpectQuery(query).WillReturnRows(rows)
         tr := transactionRepo.NewTransactionRepository(db)
 
         res, err := tr.GetByID(context.TODO(), 1)
+        assert.Equal(t, domain.ErrNotFound, err)
         assert.Equal(t, res, domain.Transaction{})
 }
 
@@ -81,10 +99,10 @@ func TestStoreSuccess(t *testing.T) {
                 SettingID: 1,
                 Status: false,
         }
-        query := regexp.QuoteMeta("INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status) values (?, ?, ?, ?)")
+        query := regexp.QuoteMeta("INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, 0).WillReturnResult(sqlmock.NewResult(12, 1))
+        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, 0, data.Amount, data.Currency, data.PaymentType, data.State, data.Processor, data.ProcessorReference, data.ResponseCode, data.ResponseMessage, data.RoutingDecision, data.CardToken, data.CardLast4, data.CardBrand, data.CardFingerprint, data.CustomerID, data.PaymentMethodID, data.PaymentCode, data.InstallmentPlanID, data.InstallmentTenor, data.Fee, data.SettlementAmount, data.PromoCode, data.PromotionID, data.OriginalAmount, data.Discount, data.SettlementCurrency, data.FXQuoteID, data.FXRate, data.FXMarkup, data.IPAddress, data.IPCountry, data.BillingCountry, data.RiskDecision, "", data.MerchantReference, data.Description, "{}", data.CreatedAt).WillReturnResult(sqlmock.NewResult(12, 1))
         tr := transactionRepo.NewTransactionRepository(db)
 
         err = tr.Store(context.TODO(), data)
@@ -104,10 +122,10 @@ func TestStoreError(t *testing.T) {
                 SettingID: 1,
                 Status: false,
         }
-        query := regexp.QuoteMeta("INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status) values (?, ?, ?, ?)")
+        query := regexp.QuoteMeta("INSERT INTO transactions (merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, 0).WillReturnError(fmt.Errorf("some error"))
+        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, 0, data.Amount, data.Currency, data.PaymentType, data.State, data.Processor, data.ProcessorReference, data.ResponseCode, data.ResponseMessage, data.RoutingDecision, data.CardToken, data.CardLast4, data.CardBrand, data.CardFingerprint, data.CustomerID, data.PaymentMethodID, data.PaymentCode, data.InstallmentPlanID, data.InstallmentTenor, data.Fee, data.SettlementAmount, data.PromoCode, data.PromotionID, data.OriginalAmount, data.Discount, data.SettlementCurrency, data.FXQuoteID, data.FXRate, data.FXMarkup, data.IPAddress, data.IPCountry, data.BillingCountry, data.RiskDecision, "", data.MerchantReference, data.Description, "{}", data.CreatedAt).WillReturnError(fmt.Errorf("some error"))
         tr := transactionRepo.NewTransactionRepository(db)
 
         err = tr.Store(context.TODO(), data)
@@ -126,11 +144,12 @@ func TestUpdateSuccess(t *testing.T) {
                 ParentMerchantID: 2,
                 SettingID: 1,
                 Status: true,
+                Version: 2,
         }
-        query := regexp.QuoteMeta("UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=? WHERE id=?")
+        query := regexp.QuoteMeta("UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=?, amount=?, currency=?, payment_type=?, state=?, processor=?, processor_reference=?, response_code=?, response_message=?, routing_decision=?, card_token=?, card_last4=?, card_brand=?, card_fingerprint=?, customer_id=?, payment_method_id=?, payment_code=?, installment_plan_id=?, installment_tenor=?, fee=?, settlement_amount=?, promo_code=?, promotion_id=?, original_amount=?, discount=?, settlement_currency=?, fx_quote_id=?, fx_rate=?, fx_markup=?, ip_address=?, ip_country=?, billing_country=?, risk_decision=?, risk_rules=?, merchant_reference=?, description=?, metadata=?, version=version+1 WHERE id=? AND version=?")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, data.Status, data.ID).WillReturnResult(sqlmock.NewResult(12, 1))
+        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, data.Status, data.Amount, data.Currency, data.PaymentType, data.State, data.Processor, data.ProcessorReference, data.ResponseCode, data.ResponseMessage, data.RoutingDecision, data.CardToken, data.CardLast4, data.CardBrand, data.CardFingerprint, data.CustomerID, data.PaymentMethodID, data.PaymentCode, data.InstallmentPlanID, data.InstallmentTenor, data.Fee, data.SettlementAmount, data.PromoCode, data.PromotionID, data.OriginalAmount, data.Discount, data.SettlementCurrency, data.FXQuoteID, data.FXRate, data.FXMarkup, data.IPAddress, data.IPCountry, data.BillingCountry, data.RiskDecision, "", data.MerchantReference, data.Description, "{}", data.ID, data.Version).WillReturnResult(sqlmock.NewResult(12, 1))
         tr := transactionRepo.NewTransactionRepository(db)
 
         err = tr.Update(context.TODO(), data)
@@ -149,13 +168,156 @@ func TestUpdateError(t *testing.T) {
                 ParentMerchantID: 2,
                 SettingID: 1,
                 Status: true,
+                Version: 2,
         }
-        query := regexp.QuoteMeta("UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=? WHERE id=?")
+        query := regexp.QuoteMeta("UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=?, amount=?, currency=?, payment_type=?, state=?, processor=?, processor_reference=?, response_code=?, response_message=?, routing_decision=?, card_token=?, card_last4=?, card_brand=?, card_fingerprint=?, customer_id=?, payment_method_id=?, payment_code=?, installment_plan_id=?, installment_tenor=?, fee=?, settlement_amount=?, promo_code=?, promotion_id=?, original_amount=?, discount=?, settlement_currency=?, fx_quote_id=?, fx_rate=?, fx_markup=?, ip_address=?, ip_country=?, billing_country=?, risk_decision=?, risk_rules=?, merchant_reference=?, description=?, metadata=?, version=version+1 WHERE id=? AND version=?")
 
         prep := mock.ExpectPrepare(query)
-        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, data.Status, data.ID).WillReturnError(fmt.Errorf("some error"))
+        prep.ExpectExec().WithArgs(data.MerchantID, data.ParentMerchantID, data.SettingID, data.Status, data.Amount, data.Currency, data.PaymentType, data.State, data.Processor, data.ProcessorReference, data.ResponseCode, data.ResponseMessage, data.RoutingDecision, data.CardToken, data.CardLast4, data.CardBrand, data.CardFingerprint, data.CustomerID, data.PaymentMethodID, data.PaymentCode, data.InstallmentPlanID, data.InstallmentTenor, data.Fee, data.SettlementAmount, data.PromoCode, data.PromotionID, data.OriginalAmount, data.Discount, data.SettlementCurrency, data.FXQuoteID, data.FXRate, data.FXMarkup, data.IPAddress, data.IPCountry, data.BillingCountry, data.RiskDecision, "", data.MerchantReference, data.Description, "{}", data.ID, data.Version).WillReturnError(fmt.Errorf("some error"))
         tr := transactionRepo.NewTransactionRepository(db)
 
         err = tr.Update(context.TODO(), data)
         assert.Error(t, err)
 }
+
+func TestUpdateStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        data := &domain.Transaction{
+                ID: 1,
+                MerchantID: 1,
+                ParentMerchantID: 2,
+                SettingID: 1,
+                Version: 2,
+        }
+        query := regexp.QuoteMeta("UPDATE transactions SET merchant_id=?, parent_merchant_id=?, setting_id=?, status=?, amount=?, currency=?, payment_type=?, state=?, processor=?, processor_reference=?, response_code=?, response_message=?, routing_decision=?, card_token=?, card_last4=?, card_brand=?, card_fingerprint=?, customer_id=?, payment_method_id=?, payment_code=?, installment_plan_id=?, installment_tenor=?, fee=?, settlement_amount=?, promo_code=?, promotion_id=?, original_amount=?, discount=?, settlement_currency=?, fx_quote_id=?, fx_rate=?, fx_markup=?, ip_address=?, ip_country=?, billing_country=?, risk_decision=?, risk_rules=?, merchant_reference=?, description=?, metadata=?, version=version+1 WHERE id=? AND version=?")
+
+        prep := mock.ExpectPrepare(query)
+        prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+        mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM transactions WHERE id=?)")).WithArgs(data.ID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+        tr := transactionRepo.NewTransactionRepository(db)
+
+        err = tr.Update(context.TODO(), data)
+        assert.Equal(t, domain.ErrStaleVersion, err)
+        assert.Equal(t, int64(2), data.Version)
+}
+
+func TestFetchByState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+        rows := sqlmock.NewRows([]string{"id", "merchant_id", "parent_merchant_id", "setting_id", "status", "amount", "currency", "payment_type", "state", "processor", "processor_reference", "response_code", "response_message", "routing_decision", "card_token", "card_last4", "card_brand", "card_fingerprint", "customer_id", "payment_method_id", "payment_code", "installment_plan_id", "installment_tenor", "fee", "settlement_amount", "promo_code", "promotion_id", "original_amount", "discount", "settlement_currency", "fx_quote_id", "fx_rate", "fx_markup", "ip_address", "ip_country", "billing_country", "risk_decision", "risk_rules", "merchant_reference", "description", "metadata", "created_at", "version"}).
+                AddRow(4, 6, 6, 1, 0, 9000000, "IDR", "CARD", domain.TransactionReview, "", "", "", "Held for review", "", "tok_1", "1111", "VISA", "", 0, 0, "", 0, 0, 0, 9000000, "", 0, 9000000, 0, "IDR", 0, "", 0, "203.0.113.7", "SG", "ID", domain.RiskReview, "big ticket,country mismatch", "order-1", "Order A1", `{"channel":"web"}`, createdAt, 3)
+        query := regexp.QuoteMeta("SELECT id, merchant_id, parent_merchant_id, setting_id, status, amount, currency, payment_type, state, processor, processor_reference, response_code, response_message, routing_decision, card_token, card_last4, card_brand, card_fingerprint, customer_id, payment_method_id, payment_code, installment_plan_id, installment_tenor, fee, settlement_amount, promo_code, promotion_id, original_amount, discount, settlement_currency, fx_quote_id, fx_rate, fx_markup, ip_address, ip_country, billing_country, risk_decision, risk_rules, merchant_reference, description, metadata, created_at, version FROM transactions WHERE merchant_id=? AND state=? ORDER BY id")
+
+        mock.ExpectQuery(query).WithArgs(6, domain.TransactionReview).WillReturnRows(rows)
+        tr := transactionRepo.NewTransactionRepository(db)
+
+        res, err := tr.FetchByState(context.TODO(), 6, domain.TransactionReview)
+        assert.NoError(t, err)
+        assert.Len(t, res, 1)
+        assert.Equal(t, []string{"big ticket", "country mismatch"}, res[0].RiskRules)
+        assert.Equal(t, "SG", res[0].IPCountry)
+        assert.Equal(t, "order-1", res[0].MerchantReference)
+        assert.Equal(t, "Order A1", res[0].Description)
+        assert.Equal(t, map[string]string{"channel": "web"}, res[0].Metadata)
+        assert.Equal(t, createdAt, res[0].CreatedAt)
+        assert.Equal(t, int64(3), res[0].Version)
+}
+
+func TestStoreAndUpdateMigrated(t *testing.T) {
+        db := migrationtest.NewDB(t)
+        tr := transactionRepo.NewTransactionRepository(db)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                Currency: "IDR",
+                PaymentType: "CARD",
+                State: domain.TransactionPending,
+                RiskRules: []string{"velocity", "country"},
+                Metadata: map[string]string{"order": "A1"},
+                CreatedAt: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+        }
+
+        err := tr.Store(context.TODO(), &data)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(1), data.ID)
+
+        data.State = domain.TransactionAuthorized
+        data.Status = true
+        data.Description = "Order A1"
+        err = tr.Update(context.TODO(), &data)
+        assert.NoError(t, err)
+
+        res, err := tr.GetByID(context.TODO(), data.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, data, res)
+
+        authorized, err := tr.FetchByState(context.TODO(), 1, domain.TransactionAuthorized)
+        assert.NoError(t, err)
+        assert.Len(t, authorized, 1)
+}
+
+func TestStoreChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+        changes := []domain.TransactionChange{
+                {TransactionID: 1, Version: 3, Field: "description", Before: `""`, After: `"Order A1"`, CreatedAt: createdAt},
+                {TransactionID: 1, Version: 3, Field: "metadata", Before: `{}`, After: `{"order":"A1"}`, CreatedAt: createdAt},
+        }
+        query := regexp.QuoteMeta("INSERT INTO transaction_changes(transaction_id, version, field, before, after, created_at) VALUES(?, ?, ?, ?, ?, ?)")
+
+        mock.ExpectExec(query).WithArgs(1, 3, "description", `""`, `"Order A1"`, createdAt).WillReturnResult(sqlmock.NewResult(7, 1))
+        mock.ExpectExec(query).WithArgs(1, 3, "metadata", `{}`, `{"order":"A1"}`, createdAt).WillReturnResult(sqlmock.NewResult(8, 1))
+        tr := transactionRepo.NewTransactionRepository(db)
+
+        err = tr.StoreChanges(context.TODO(), changes)
+        assert.NoError(t, err)
+        assert.Equal(t, int64(7), changes[0].ID)
+        assert.Equal(t, int64(8), changes[1].ID)
+}
+
+func TestFetchChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+        if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+        createdAt := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+        rows := sqlmock.NewRows([]string{"id", "transaction_id", "version", "field", "before", "after", "created_at"}).
+                AddRow(7, 1, 3, "state", `"authorized"`, `"captured"`, createdAt)
+        query := regexp.QuoteMeta("SELECT id, transaction_id, version, field, before, after, created_at FROM transaction_changes WHERE transaction_id=? ORDER BY id")
+
+        mock.ExpectQuery(query).WithArgs(1).WillReturnRows(rows)
+        tr := transactionRepo.NewTransactionRepository(db)
+
+        res, err := tr.FetchChanges(context.TODO(), 1)
+        assert.NoError(t, err)
+        assert.Equal(t, []domain.TransactionChange{{ID: 7, TransactionID: 1, Version: 3, Field: "state", Before: `"authorized"`, After: `"captured"`, CreatedAt: createdAt}}, res)
+}
+
+func TestStoreDuplicateMerchantReferenceMigrated(t *testing.T) {
+        db := migrationtest.NewDB(t)
+        tr := transactionRepo.NewTransactionRepository(db)
+        first := domain.Transaction{MerchantID: 1, ParentMerchantID: 1, SettingID: 1, MerchantReference: "order-1"}
+        again := first
+        unreferenced := domain.Transaction{MerchantID: 1, ParentMerchantID: 1, SettingID: 1}
+
+        assert.NoError(t, tr.Store(context.TODO(), &first))
+        assert.Error(t, tr.Store(context.TODO(), &again))
+        assert.NoError(t, tr.Store(context.TODO(), &unreferenced))
+        unreferenced.ID = 0
+        assert.NoError(t, tr.Store(context.TODO(), &unreferenced))
+}
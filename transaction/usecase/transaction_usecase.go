@@ -2,39 +2,517 @@ package usecase
 
 import (
         "context"
-        "errors"
+        "encoding/json"
+        "log"
+        "reflect"
+        "strings"
+        "time"
+        "unicode/utf8"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
 )
 
+// recordAttempts is how many times the outcome of a call is written over
+// patches that keep landing before giving up.
+const recordAttempts = 3
+
 type transactionUsecase struct {
         merchantRepo domain.MerchantRepository
-        transactionRepo domain.TransactionUsecase
+        transactionRepo domain.TransactionRepository
+        customers domain.CustomerUsecase
+        cardVault domain.CardVaultUsecase
+        processor domain.PaymentProcessor
+        channels map[string]domain.PaymentChannel
+        installments domain.InstallmentUsecase
+        promotions domain.PromotionUsecase
+        fx domain.FXUsecase
+        risk domain.RiskUsecase
+        limits domain.LimitUsecase
+}
+
+// Option configures the optional parts of the transaction usecase.
+type Option func(*transactionUsecase)
+
+// WithChannel routes transactions of the channel's payment type through it
+// instead of the card processor.
+func WithChannel(c domain.PaymentChannel) Option {
+        return func(tu *transactionUsecase) {
+                tu.channels[c.PaymentType()] = c
+        }
+}
+
+// WithInstallments lets card transactions pick an installment plan.
+func WithInstallments(iu domain.InstallmentUsecase) Option {
+        return func(tu *transactionUsecase) {
+                tu.installments = iu
+        }
+}
+
+// WithPromotions lets transactions carry a promo code.
+func WithPromotions(pu domain.PromotionUsecase) Option {
+        return func(tu *transactionUsecase) {
+                tu.promotions = pu
+        }
+}
+
+// WithFX lets transactions be paid in currencies other than the one the
+// merchant settles in.
+func WithFX(fu domain.FXUsecase) Option {
+        return func(tu *transactionUsecase) {
+                tu.fx = fu
+        }
+}
+
+// WithRisk screens transactions with the merchant's risk rules before they
+// are sent on. Blocked transactions are declined and ones that need review
+// are held until approved or rejected.
+func WithRisk(ru domain.RiskUsecase) Option {
+        return func(tu *transactionUsecase) {
+                tu.risk = ru
+        }
+}
+
+// WithLimits holds transactions to the merchant's amount and volume caps.
+func WithLimits(lu domain.LimitUsecase) Option {
+        return func(tu *transactionUsecase) {
+                tu.limits = lu
+        }
 }
 
-func NewTransactionUsecase(mr domain.MerchantRepository, tr domain.TransactionRepository) domain.TransactionUsecase {
-        return &transactionUsecase{
+func NewTransactionUsecase(mr domain.MerchantRepository, tr domain.TransactionRepository, cu domain.CustomerUsecase, cv domain.CardVaultUsecase, p domain.PaymentProcessor, opts ...Option) domain.TransactionUsecase {
+        tu := &transactionUsecase{
                 merchantRepo: mr,
                 transactionRepo: tr,
+                customers: cu,
+                cardVault: cv,
+                processor: p,
+                channels: map[string]domain.PaymentChannel{},
+        }
+        for _, opt := range opts {
+                opt(tu)
         }
+
+        return tu
 }
 
 func (tu *transactionUsecase) GetByID(ctx context.Context, id int64) (domain.Transaction, error) {
         return tu.transactionRepo.GetByID(ctx, id)
 }
+
+// Store takes a payment for the merchant. Only an approved merchant can take
+// payments. A merchant reference, if given, has to be new for the merchant.
 func (tu *transactionUsecase) Store(ctx context.Context, t *domain.Transaction) error {
+        merchant, err := tu.merchantRepo.GetByID(ctx, t.MerchantID)
+        if err != nil {
+                return err
+        }
+        if merchant.Status != domain.MerchantApproved {
+                return domain.ErrMerchantInactive
+        }
+        err = checkMetadata(t.Metadata)
+        if err != nil {
+                return err
+        }
+        if t.MerchantReference != "" {
+                if utf8.RuneCountInString(t.MerchantReference) > domain.MaxMerchantReferenceLength {
+                        return domain.ErrReference
+                }
+                _, err = tu.transactionRepo.GetByMerchantReference(ctx, t.MerchantID, t.MerchantReference)
+                if err == nil {
+                        return domain.ErrReferenceTaken
+                }
+                if err != domain.ErrNotFound {
+                        return err
+                }
+        }
+
         if t.MerchantID != t.ParentMerchantID {
                 return tu.storeForChild(ctx, t)
         } else {
                 return tu.store(ctx, t)
         }
 }
-func (tu *transactionUsecase) Update(ctx context.Context, t *domain.Transaction) error {
-        return tu.transactionRepo.Update(ctx, t)
+// Patch applies a merge patch to the transaction as of the version the
+// caller read. A state change goes through the same follow-up as its own
+// endpoint and is made first, so a move that is refused changes nothing.
+// Every field changed is recorded in the transaction's audit trail.
+func (tu *transactionUsecase) Patch(ctx context.Context, id int64, p *domain.TransactionPatch) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if p.Version != t.Version {
+                return domain.Transaction{}, domain.ErrStaleVersion
+        }
+        if len(p.Metadata) > 0 {
+                err = checkMetadata(mergeMetadata(t.Metadata, p))
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+        }
+        before := t
+
+        if p.State != nil && *p.State != t.State {
+                move := tu.transition(*p.State)
+                if move == nil {
+                        return domain.Transaction{}, domain.ErrInvalidState
+                }
+                t, err = move(ctx, id)
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+        }
+
+        description, metadata := t.Description, t.Metadata
+        if p.Description != nil {
+                t.Description = *p.Description
+        }
+        t.Metadata = mergeMetadata(t.Metadata, p)
+        if t.Description != description || !reflect.DeepEqual(t.Metadata, metadata) {
+                err = tu.transactionRepo.Update(ctx, &t)
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+        }
+
+        changes, err := diff(before, t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if len(changes) > 0 {
+                err = tu.transactionRepo.StoreChanges(ctx, changes)
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+        }
+
+        return t, nil
+}
+
+// FetchChanges lists the changes patches made to a transaction, oldest
+// first.
+func (tu *transactionUsecase) FetchChanges(ctx context.Context, id int64) ([]domain.TransactionChange, error) {
+        _, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return nil, err
+        }
+
+        return tu.transactionRepo.FetchChanges(ctx, id)
+}
+
+// GetByMerchantReference finds a merchant's transaction by the reference
+// the merchant gave it.
+func (tu *transactionUsecase) GetByMerchantReference(ctx context.Context, merchantID int64, reference string) (domain.Transaction, error) {
+        if reference == "" {
+                return domain.Transaction{}, domain.ErrNotFound
+        }
+
+        return tu.transactionRepo.GetByMerchantReference(ctx, merchantID, reference)
+}
+
+// transition gives the follow-up that moves a transaction to the state, or
+// nil if a patch cannot ask for it.
+func (tu *transactionUsecase) transition(state string) func(ctx context.Context, id int64) (domain.Transaction, error) {
+        switch state {
+        case domain.TransactionCaptured:
+                return tu.Capture
+        case domain.TransactionRefunded:
+                return tu.Refund
+        case domain.TransactionVoided:
+                return tu.Void
+        case domain.TransactionDeclined:
+                return tu.Reject
+        }
+
+        return nil
+}
+
+func (tu *transactionUsecase) Capture(ctx context.Context, id int64) (domain.Transaction, error) {
+        return tu.followUp(ctx, id, domain.TransactionAuthorized, domain.TransactionCaptured, tu.processor.Capture)
+}
+func (tu *transactionUsecase) Refund(ctx context.Context, id int64) (domain.Transaction, error) {
+        return tu.followUp(ctx, id, domain.TransactionCaptured, domain.TransactionRefunded, tu.processor.Refund)
+}
+func (tu *transactionUsecase) Void(ctx context.Context, id int64) (domain.Transaction, error) {
+        t, err := tu.followUp(ctx, id, domain.TransactionAuthorized, domain.TransactionVoided, tu.processor.Void)
+        if err == nil && t.State == domain.TransactionVoided {
+                tu.unreserve(ctx, &t)
+        }
+        return t, err
+}
+
+// Complete settles a pending channel transaction once the customer has paid
+// the expected amount outside the gateway. The reference is whatever the
+// paying side uses to identify the payment.
+func (tu *transactionUsecase) Complete(ctx context.Context, id int64, amount int64, reference string) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if _, ok := tu.channels[t.PaymentType]; !ok || t.State != domain.TransactionPending {
+                return domain.Transaction{}, domain.ErrInvalidState
+        }
+        if amount != t.Amount {
+                return domain.Transaction{}, domain.ErrAmountMismatch
+        }
+
+        t.State = domain.TransactionCaptured
+        t.Status = true
+        t.ResponseCode = "00"
+        t.ResponseMessage = "Paid"
+        if reference != "" {
+                t.ProcessorReference = reference
+        }
+
+        err = tu.transactionRepo.Update(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        return t, nil
+}
+
+// Fail marks a pending channel transaction that can no longer be paid as
+// failed and gives back what was reserved for it. A transaction that is no
+// longer pending is returned as it is.
+func (tu *transactionUsecase) Fail(ctx context.Context, id int64, message string) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if t.State != domain.TransactionPending {
+                return t, nil
+        }
+
+        t.State = domain.TransactionFailed
+        t.ResponseMessage = message
+        err = tu.transactionRepo.Update(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        tu.unreserve(ctx, &t)
+        tu.release(ctx, &t)
+
+        return t, nil
+}
+
+func (tu *transactionUsecase) FetchReviews(ctx context.Context, merchantID int64) ([]domain.Transaction, error) {
+        return tu.transactionRepo.FetchByState(ctx, merchantID, domain.TransactionReview)
+}
+
+// Approve sends a transaction held for review on as if it had just been
+// submitted: a card is authorized and a channel payment is initiated.
+func (tu *transactionUsecase) Approve(ctx context.Context, id int64) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if t.State != domain.TransactionReview {
+                return domain.Transaction{}, domain.ErrInvalidState
+        }
+
+        c, ok := tu.channels[t.PaymentType]
+        var setting domain.Setting
+        if ok {
+                setting, err = tu.channelSetting(ctx, &t)
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+        }
+
+        // Taking it out of review first means a second approval made at
+        // the same time fails here rather than authorizing the card or
+        // initiating the payment again.
+        t.State = domain.TransactionPending
+        t.ResponseMessage = ""
+        err = tu.transactionRepo.Update(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        if ok {
+                err = c.Initiate(ctx, &t, setting)
+                if err != nil {
+                        tu.abandon(ctx, &t, err)
+                        tu.unreserve(ctx, &t)
+                        tu.release(ctx, &t)
+                        return domain.Transaction{}, err
+                }
+                err = tu.record(ctx, &t)
+                if err != nil {
+                        return domain.Transaction{}, err
+                }
+                return t, nil
+        }
+
+        err = tu.authorize(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if t.State != domain.TransactionAuthorized {
+                tu.unreserve(ctx, &t)
+                tu.release(ctx, &t)
+        }
+
+        return t, nil
+}
+
+// Reject declines a transaction held for review and gives back what was
+// reserved for it.
+func (tu *transactionUsecase) Reject(ctx context.Context, id int64) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if t.State != domain.TransactionReview {
+                return domain.Transaction{}, domain.ErrInvalidState
+        }
+
+        t.State = domain.TransactionDeclined
+        t.ResponseMessage = "Rejected in review"
+        err = tu.transactionRepo.Update(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        tu.unreserve(ctx, &t)
+        tu.release(ctx, &t)
+
+        return t, nil
 }
 
 func (tu *transactionUsecase) store(ctx context.Context, t *domain.Transaction) error {
-        return tu.transactionRepo.Store(ctx, t)
+        if t.Currency == "" {
+                t.Currency = "IDR"
+        }
+        if t.PaymentType == "" {
+                t.PaymentType = "CARD"
+        }
+        // Callers bind the transaction from a request, so anything the
+        // gateway fills in itself starts out empty whatever they sent.
+        t.State = domain.TransactionPending
+        t.Status = false
+        t.Processor = ""
+        t.ProcessorReference = ""
+        t.ResponseCode = ""
+        t.ResponseMessage = ""
+        t.RoutingDecision = ""
+        t.CardLast4 = ""
+        t.CardBrand = ""
+        t.CardFingerprint = ""
+        t.InstallmentTenor = 0
+        t.Fee = 0
+        t.SettlementAmount = t.Amount
+        t.PromotionID = 0
+        t.OriginalAmount = t.Amount
+        t.Discount = 0
+        t.IPCountry = strings.ToUpper(t.IPCountry)
+        t.BillingCountry = strings.ToUpper(t.BillingCountry)
+        t.SettlementCurrency = ""
+        t.FXRate = ""
+        t.FXMarkup = 0
+        t.RiskDecision = ""
+        t.RiskRules = nil
+        t.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+        if c, ok := tu.channels[t.PaymentType]; ok {
+                if t.InstallmentPlanID != 0 {
+                        return domain.ErrInstallmentPlan
+                }
+                t.PaymentMethodID = 0
+                t.CardToken = ""
+                err := tu.redeem(ctx, t, "")
+                if err != nil {
+                        return err
+                }
+                err = tu.lockRate(ctx, t)
+                if err != nil {
+                        tu.release(ctx, t)
+                        return err
+                }
+                err = tu.assess(ctx, t, "")
+                if err != nil {
+                        tu.release(ctx, t)
+                        return err
+                }
+                err = tu.reserve(ctx, t)
+                if err != nil {
+                        tu.release(ctx, t)
+                        return err
+                }
+                err = tu.initiate(ctx, t, c)
+                if err != nil {
+                        tu.unreserve(ctx, t)
+                }
+                if err != nil || t.State == domain.TransactionDeclined {
+                        tu.release(ctx, t)
+                }
+                return err
+        }
+
+        err := tu.resolveCard(ctx, t)
+        if err != nil {
+                return err
+        }
+
+        var bin string
+        if t.PromoCode != "" || t.InstallmentPlanID != 0 || tu.risk != nil {
+                bin, err = tu.cardBIN(ctx, t)
+                if err != nil {
+                        return err
+                }
+        }
+
+        err = tu.redeem(ctx, t, bin)
+        if err != nil {
+                return err
+        }
+
+        if t.InstallmentPlanID != 0 {
+                err = tu.applyInstallment(ctx, t, bin)
+                if err != nil {
+                        tu.release(ctx, t)
+                        return err
+                }
+        }
+
+        err = tu.lockRate(ctx, t)
+        if err != nil {
+                tu.release(ctx, t)
+                return err
+        }
+
+        err = tu.assess(ctx, t, bin)
+        if err != nil {
+                tu.release(ctx, t)
+                return err
+        }
+
+        err = tu.reserve(ctx, t)
+        if err != nil {
+                tu.release(ctx, t)
+                return err
+        }
+
+        err = tu.transactionRepo.Store(ctx, t)
+        if err != nil {
+                tu.unreserve(ctx, t)
+                tu.release(ctx, t)
+                return err
+        }
+        if t.State == domain.TransactionDeclined {
+                tu.release(ctx, t)
+                return nil
+        }
+        if t.State == domain.TransactionReview {
+                return nil
+        }
+
+        err = tu.authorize(ctx, t)
+        if t.State != domain.TransactionAuthorized {
+                tu.unreserve(ctx, t)
+                tu.release(ctx, t)
+        }
+        return err
 }
 func (tu *transactionUsecase) storeForChild(ctx context.Context, t *domain.Transaction) error {
         authorized, err := tu.merchantRepo.IsAuthorizedParent(
@@ -48,8 +526,406 @@ func (tu *transactionUsecase) storeForChild(ctx context.Context, t *domain.Trans
                 return err
         }
         if authorized == false {
-                return errors.New("Unauthorized")
+                return domain.ErrUnauthorized
+        }
+
+        return tu.store(ctx, t)
+}
+
+// initiate stores a transaction for a payment channel. The setting has to be
+// one of the merchant's and offer the requested payment type. A transaction
+// the channel refuses is stored as failed.
+func (tu *transactionUsecase) initiate(ctx context.Context, t *domain.Transaction, c domain.PaymentChannel) error {
+        setting, err := tu.channelSetting(ctx, t)
+        if err != nil {
+                return err
+        }
+
+        err = tu.transactionRepo.Store(ctx, t)
+        if err != nil {
+                return err
+        }
+        if t.State != domain.TransactionPending {
+                return nil
+        }
+
+        err = c.Initiate(ctx, t, setting)
+        if err != nil {
+                tu.abandon(ctx, t, err)
+                return err
+        }
+
+        return tu.transactionRepo.Update(ctx, t)
+}
+
+// abandon marks a channel transaction the channel could not start as failed,
+// so nothing waits on it. The caller gives back what was reserved for it.
+func (tu *transactionUsecase) abandon(ctx context.Context, t *domain.Transaction, cause error) {
+        t.State = domain.TransactionFailed
+        t.ResponseMessage = cause.Error()
+        err := tu.transactionRepo.Update(ctx, t)
+        if err != nil {
+                log.Printf("transaction %d: mark failed: %v", t.ID, err)
+        }
+}
+
+func (tu *transactionUsecase) channelSetting(ctx context.Context, t *domain.Transaction) (domain.Setting, error) {
+        setting, err := tu.merchantRepo.GetSetting(ctx, t.SettingID)
+        if err == domain.ErrNotFound {
+                return domain.Setting{}, domain.ErrPaymentMethod
+        }
+        if err != nil {
+                return domain.Setting{}, err
+        }
+        if setting.MerchantID != t.MerchantID || setting.PaymentType != t.PaymentType {
+                return domain.Setting{}, domain.ErrPaymentMethod
+        }
+
+        return setting, nil
+}
+
+// resolveCard works out which vaulted card to charge. A saved payment method
+// wins over a raw card token and also links the transaction to its customer.
+func (tu *transactionUsecase) resolveCard(ctx context.Context, t *domain.Transaction) error {
+        if t.PaymentMethodID != 0 {
+                pm, err := tu.customers.GetPaymentMethod(ctx, t.MerchantID, t.PaymentMethodID)
+                if err == domain.ErrNotFound {
+                        return domain.ErrInvalidCard
+                }
+                if err != nil {
+                        return err
+                }
+                if t.CustomerID != 0 && t.CustomerID != pm.CustomerID {
+                        return domain.ErrInvalidCustomer
+                }
+
+                t.CustomerID = pm.CustomerID
+                t.CardToken = pm.CardToken
+        } else if t.CustomerID != 0 {
+                _, err := tu.customers.GetByID(ctx, t.MerchantID, t.CustomerID)
+                if err == domain.ErrNotFound {
+                        return domain.ErrInvalidCustomer
+                }
+                if err != nil {
+                        return err
+                }
+        }
+
+        // Only cards vaulted by the same merchant can be charged.
+        card, err := tu.cardVault.GetByToken(ctx, t.MerchantID, t.CardToken)
+        if err == domain.ErrNotFound {
+                return domain.ErrInvalidCard
+        }
+        if err != nil {
+                return err
+        }
+        t.CardLast4 = card.Last4
+        t.CardBrand = card.Brand
+        t.CardFingerprint = card.Fingerprint
+
+        return nil
+}
+
+// cardBIN looks up the issuer prefix of the card being charged, which
+// installment plans and promotions can be limited to.
+func (tu *transactionUsecase) cardBIN(ctx context.Context, t *domain.Transaction) (string, error) {
+        card, err := tu.cardVault.GetByToken(ctx, t.MerchantID, t.CardToken)
+        if err == domain.ErrNotFound {
+                return "", domain.ErrInvalidCard
+        }
+        if err != nil {
+                return "", err
+        }
+
+        return card.BIN, nil
+}
+
+// applyInstallment checks the card qualifies for the chosen plan by its BIN.
+func (tu *transactionUsecase) applyInstallment(ctx context.Context, t *domain.Transaction, bin string) error {
+        if tu.installments == nil {
+                return domain.ErrInstallmentPlan
         }
 
-        return tu.transactionRepo.Store(ctx, t)
+        return tu.installments.Apply(ctx, t, bin)
+}
+
+// redeem applies the transaction's promo code, if it has one, before
+// anything is charged.
+func (tu *transactionUsecase) redeem(ctx context.Context, t *domain.Transaction, bin string) error {
+        if t.PromoCode == "" {
+                return nil
+        }
+        if tu.promotions == nil {
+                return domain.ErrPromotion
+        }
+
+        return tu.promotions.Redeem(ctx, t, bin)
+}
+
+// lockRate records the rate a transaction settles at. Without FX support
+// only the settlement currency can be charged.
+func (tu *transactionUsecase) lockRate(ctx context.Context, t *domain.Transaction) error {
+        if tu.fx == nil {
+                t.SettlementCurrency = domain.SettlementCurrency
+                if t.Currency != domain.SettlementCurrency {
+                        return domain.ErrCurrency
+                }
+                return nil
+        }
+
+        return tu.fx.Lock(ctx, t)
+}
+
+// assess runs the risk rules on a transaction about to be stored. A blocked
+// transaction is stored declined and one that needs review is stored held;
+// neither goes any further.
+func (tu *transactionUsecase) assess(ctx context.Context, t *domain.Transaction, bin string) error {
+        if tu.risk == nil {
+                return nil
+        }
+
+        err := tu.risk.Evaluate(ctx, t, bin)
+        if err != nil {
+                return err
+        }
+
+        switch t.RiskDecision {
+        case domain.RiskBlock:
+                t.State = domain.TransactionDeclined
+                t.ResponseMessage = "Blocked by risk rules"
+        case domain.RiskReview:
+                t.State = domain.TransactionReview
+                t.ResponseMessage = "Held for review"
+        }
+
+        return nil
+}
+
+// reserve takes the transaction off the merchant's limits. A transaction
+// the risk rules blocked is not going anywhere and reserves nothing.
+func (tu *transactionUsecase) reserve(ctx context.Context, t *domain.Transaction) error {
+        if tu.limits == nil || t.State == domain.TransactionDeclined {
+                return nil
+        }
+
+        return tu.limits.Reserve(ctx, t)
+}
+
+// unreserve gives back what reserve took. Like release, a failure is only
+// logged.
+func (tu *transactionUsecase) unreserve(ctx context.Context, t *domain.Transaction) {
+        if tu.limits == nil || t.RiskDecision == domain.RiskBlock {
+                return
+        }
+
+        err := tu.limits.Release(ctx, t)
+        if err != nil {
+                log.Printf("transaction %d: release limits: %v", t.ID, err)
+        }
+}
+
+// release gives back the promotion redemption of a transaction that was not
+// paid. Failing to do so only costs quota, so it is logged, not returned.
+func (tu *transactionUsecase) release(ctx context.Context, t *domain.Transaction) {
+        if t.PromotionID == 0 {
+                return
+        }
+
+        err := tu.promotions.Release(ctx, t)
+        if err != nil {
+                log.Printf("transaction %d: release promotion %d: %v", t.ID, t.PromotionID, err)
+        }
+}
+
+// authorize sends a freshly stored transaction to the processor and records
+// whatever came back, including timeouts, before returning.
+func (tu *transactionUsecase) authorize(ctx context.Context, t *domain.Transaction) error {
+        res, err := tu.processor.Authorize(ctx, paymentRequest(t))
+        applyResponse(t, res)
+
+        switch {
+        case err == domain.ErrProcessorTimeout:
+                t.State = domain.TransactionFailed
+        case err != nil:
+                return err
+        case res.Approved:
+                t.State = domain.TransactionAuthorized
+                t.Status = true
+        default:
+                t.State = domain.TransactionDeclined
+        }
+
+        return tu.record(ctx, t)
+}
+
+type processorCall func(ctx context.Context, req *domain.PaymentRequest) (domain.ProcessorResponse, error)
+
+func (tu *transactionUsecase) followUp(ctx context.Context, id int64, from string, to string, call processorCall) (domain.Transaction, error) {
+        t, err := tu.transactionRepo.GetByID(ctx, id)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+        if t.State != from {
+                return domain.Transaction{}, domain.ErrInvalidState
+        }
+
+        // Marking it processing first means a second call made at the same
+        // time fails here rather than reaching the processor too.
+        t.State = domain.TransactionProcessing
+        err = tu.transactionRepo.Update(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        res, err := call(ctx, paymentRequest(&t))
+        t.State = from
+        if err != nil {
+                rerr := tu.record(ctx, &t)
+                if rerr != nil {
+                        log.Printf("transaction %d: put back to %s: %v", t.ID, from, rerr)
+                }
+                return domain.Transaction{}, err
+        }
+        applyResponse(&t, res)
+        if res.Approved {
+                t.State = to
+                t.Status = to == domain.TransactionCaptured
+        }
+
+        err = tu.record(ctx, &t)
+        if err != nil {
+                return domain.Transaction{}, err
+        }
+
+        return t, nil
+}
+
+// record saves what a processor or channel call made of a transaction the
+// caller has claimed. Nothing else can move it meanwhile, but a patch can
+// still change its description or metadata; those are kept and the call's
+// outcome written over them rather than lost.
+func (tu *transactionUsecase) record(ctx context.Context, t *domain.Transaction) error {
+        for attempt := 1; ; attempt++ {
+                err := tu.transactionRepo.Update(ctx, t)
+                if err != domain.ErrStaleVersion || attempt == recordAttempts {
+                        return err
+                }
+
+                fresh, err := tu.transactionRepo.GetByID(ctx, t.ID)
+                if err != nil {
+                        return err
+                }
+                t.Description, t.Metadata, t.Version = fresh.Description, fresh.Metadata, fresh.Version
+        }
+}
+
+func paymentRequest(t *domain.Transaction) *domain.PaymentRequest {
+        return &domain.PaymentRequest{
+                TransactionID: t.ID,
+                MerchantID: t.MerchantID,
+                Amount: t.Amount,
+                Currency: t.Currency,
+                PaymentType: t.PaymentType,
+                CardToken: t.CardToken,
+                Processor: t.Processor,
+                Reference: t.ProcessorReference,
+                Installments: t.InstallmentTenor,
+        }
+}
+
+func applyResponse(t *domain.Transaction, res domain.ProcessorResponse) {
+        t.Processor = res.Processor
+        if res.Reference != "" {
+                t.ProcessorReference = res.Reference
+        }
+        t.ResponseCode = res.Code
+        t.ResponseMessage = res.Message
+        if res.Route != "" {
+                t.RoutingDecision = res.Route
+        }
+}
+
+// mergeMetadata applies the patch's metadata to m as RFC 7396 merges an
+// object, leaving an empty result nil.
+func mergeMetadata(m map[string]string, p *domain.TransactionPatch) map[string]string {
+        merged := map[string]string{}
+        if !p.ClearMetadata {
+                for k, v := range m {
+                        merged[k] = v
+                }
+        }
+        for k, v := range p.Metadata {
+                if v == nil {
+                        delete(merged, k)
+                } else {
+                        merged[k] = *v
+                }
+        }
+        if len(merged) == 0 {
+                return nil
+        }
+
+        return merged
+}
+
+// checkMetadata keeps metadata within the number of keys and the key and
+// value lengths a transaction may carry.
+func checkMetadata(m map[string]string) error {
+        if len(m) > domain.MaxMetadataKeys {
+                return domain.ErrMetadataLimit
+        }
+        for k, v := range m {
+                if k == "" || utf8.RuneCountInString(k) > domain.MaxMetadataKeyLength || utf8.RuneCountInString(v) > domain.MaxMetadataValueLength {
+                        return domain.ErrMetadataLimit
+                }
+        }
+
+        return nil
+}
+
+// diff lists the patchable fields that differ between two versions of a
+// transaction, with their JSON values.
+func diff(before, after domain.Transaction) ([]domain.TransactionChange, error) {
+        fields := []struct {
+                name          string
+                before, after interface{}
+        }{
+                {"state", before.State, after.State},
+                {"description", before.Description, after.Description},
+                {"metadata", metadataValue(before.Metadata), metadataValue(after.Metadata)},
+        }
+
+        now := time.Now().UTC().Truncate(time.Second)
+        changes := []domain.TransactionChange{}
+        for _, f := range fields {
+                b, err := json.Marshal(f.before)
+                if err != nil {
+                        return nil, err
+                }
+                a, err := json.Marshal(f.after)
+                if err != nil {
+                        return nil, err
+                }
+                if string(b) == string(a) {
+                        continue
+                }
+                changes = append(changes, domain.TransactionChange{
+                        TransactionID: after.ID,
+                        Version: after.Version,
+                        Field: f.name,
+                        Before: string(b),
+                        After: string(a),
+                        CreatedAt: now,
+                })
+        }
+
+        return changes, nil
+}
+
+// metadataValue shows missing metadata as an empty object.
+func metadataValue(m map[string]string) map[string]string {
+        if m == nil {
+                return map[string]string{}
+        }
+        return m
 }
@@ -2,57 +2,155 @@ package usecase_test
 
 import (
 	"context"
+        "fmt"
+        "strings"
+        "sync"
         "testing"
+        "time"
 
         "github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/hezbymuhammad/payment-gateway/domain"
 	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
 	transactionUsecase "github.com/hezbymuhammad/payment-gateway/transaction/usecase"
 )
 
+var card = domain.Card{
+        Token: "tok_1",
+        MerchantID: 1,
+        Last4: "1111",
+        Brand: "VISA",
+}
+
+var active = domain.Merchant{
+        ID: 1,
+        Name: "lorem",
+        Status: domain.MerchantApproved,
+}
+
+var approved = domain.ProcessorResponse{
+        Processor: "simulator",
+        Reference: "simulator-1",
+        Code: domain.ResponseApproved,
+        Message: "Approved",
+        Approved: true,
+}
+
 func TestStore(t *testing.T) {
         mockMerchantRepo := new(mocks.MerchantRepository)
         mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
         data := domain.Transaction{
                 MerchantID: 1,
                 ParentMerchantID: 1,
                 SettingID: 1,
                 Status: false,
+                Amount: 10000,
+                CardToken: "tok_1",
         }
 
         mockMerchantRepo.On("IsAuthorizedParent", mock.Anything, mock.Anything).Return(true, nil).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
         mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
-        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo)
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionAuthorized, data.State)
+        assert.Equal(t, "simulator-1", data.ProcessorReference)
+        assert.Equal(t, "1111", data.CardLast4)
+        assert.Equal(t, "VISA", data.CardBrand)
+        assert.True(t, data.Status)
+}
+
+func TestStoreIgnoresServerFields(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CardToken: "tok_1",
+                Processor: "planted",
+                ProcessorReference: "planted-1",
+                ResponseCode: "00",
+                ResponseMessage: "Approved",
+                RoutingDecision: "planted",
+                CardLast4: "9999",
+                CardBrand: "PLANTED",
+                SettlementCurrency: "USD",
+                FXRate: "1",
+                FXMarkup: 5,
+        }
+
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+                return t.Processor == "" && t.ProcessorReference == "" && t.ResponseCode == "" && t.ResponseMessage == "" && t.RoutingDecision == "" &&
+                        t.CardLast4 == "1111" && t.CardBrand == "VISA" && t.SettlementCurrency == "IDR" && t.FXRate == "" && t.FXMarkup == 0
+        })).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor)
 
         err := u.Store(context.TODO(), &data)
 
         assert.NoError(t, err)
+        assert.Equal(t, "simulator-1", data.ProcessorReference)
+        mockTransactionRepo.AssertExpectations(t)
 }
 
 func TestStoreForChild(t *testing.T) {
         mockMerchantRepo := new(mocks.MerchantRepository)
         mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
         data := domain.Transaction{
                 MerchantID: 1,
                 ParentMerchantID: 2,
                 SettingID: 1,
                 Status: false,
+                Amount: 10000,
+                CardToken: "tok_1",
         }
 
         mockMerchantRepo.On("IsAuthorizedParent", mock.Anything, mock.Anything).Return(true, nil).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
         mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
-        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo)
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
 
         err := u.Store(context.TODO(), &data)
 
         assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionAuthorized, data.State)
+        assert.Equal(t, "simulator-1", data.ProcessorReference)
+        assert.Equal(t, "1111", data.CardLast4)
+        assert.Equal(t, "VISA", data.CardBrand)
+        assert.True(t, data.Status)
 }
 
 func TestStoreForChildUnauthorized(t *testing.T) {
         mockMerchantRepo := new(mocks.MerchantRepository)
         mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
         data := domain.Transaction{
                 MerchantID: 1,
                 ParentMerchantID: 2,
@@ -61,8 +159,10 @@ func TestStoreForChildUnauthorized(t *testing.T) {
         }
 
         mockMerchantRepo.On("IsAuthorizedParent", mock.Anything, mock.Anything).Return(false, nil).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
         mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
-        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo)
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
 
         err := u.Store(context.TODO(), &data)
 
@@ -72,6 +172,9 @@ func TestStoreForChildUnauthorized(t *testing.T) {
 func TestGetByID(t *testing.T) {
         mockMerchantRepo := new(mocks.MerchantRepository)
         mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
         data := domain.Transaction{
                 ID: 1,
                 MerchantID: 1,
@@ -80,7 +183,7 @@ func TestGetByID(t *testing.T) {
                 Status: false,
         }
         mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(data, nil).Once()
-        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo)
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
 
         res, err := u.GetByID(context.TODO(), int64(1))
 
@@ -88,20 +191,1197 @@ func TestGetByID(t *testing.T) {
         assert.Equal(t, res, data)
 }
 
-func TestUpdate(t *testing.T) {
+func TestPatch(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        stored := domain.Transaction{ID: 1, MerchantID: 1, State: domain.TransactionAuthorized, Description: "Order A1", Metadata: map[string]string{"order": "A1", "note": "gift"}, Version: 2}
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+                return t.Description == "Order A1, 2 items" && len(t.Metadata) == 2 && t.Metadata["order"] == "A1" && t.Metadata["channel"] == "web" && t.MerchantID == 1
+        })).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).Version++
+        }).Return(nil).Once()
+        mockTransactionRepo.On("StoreChanges", mock.Anything, mock.MatchedBy(func(changes []domain.TransactionChange) bool {
+                return len(changes) == 2 &&
+                        changes[0].Field == "description" && changes[0].Before == `"Order A1"` && changes[0].After == `"Order A1, 2 items"` && changes[0].Version == 3 &&
+                        changes[1].Field == "metadata" && changes[1].Before == `{"note":"gift","order":"A1"}` && changes[1].After == `{"channel":"web","order":"A1"}`
+        })).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+        description, web := "Order A1, 2 items", "web"
+
+        res, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{
+                Description: &description,
+                Metadata: map[string]*string{"note": nil, "channel": &web},
+                Version: 2,
+        })
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(3), res.Version)
+        mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestPatchState(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockProcessor := new(mocks.PaymentProcessor)
+        stored := domain.Transaction{ID: 1, MerchantID: 1, State: domain.TransactionAuthorized, ProcessorReference: "simulator-1", Version: 2}
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Twice()
+        mockProcessor.On("Capture", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).Version++
+        }).Return(nil).Twice()
+        mockTransactionRepo.On("StoreChanges", mock.Anything, mock.MatchedBy(func(changes []domain.TransactionChange) bool {
+                return len(changes) == 1 && changes[0].Field == "state" && changes[0].Before == `"authorized"` && changes[0].After == `"captured"` && changes[0].Version == 4
+        })).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), mockProcessor)
+        captured := domain.TransactionCaptured
+
+        res, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{State: &captured, Version: 2})
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionCaptured, res.State)
+        mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestPatchStateNotAllowed(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        stored := domain.Transaction{ID: 1, State: domain.TransactionPending, Version: 1}
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+        authorized, description := domain.TransactionAuthorized, "Order A1"
+
+        _, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{State: &authorized, Description: &description, Version: 1})
+
+        assert.Equal(t, domain.ErrInvalidState, err)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+        mockTransactionRepo.AssertNotCalled(t, "StoreChanges", mock.Anything, mock.Anything)
+}
+
+func TestPatchStale(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(domain.Transaction{ID: 1, Version: 3}, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+        description := "Order A1"
+
+        _, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{Description: &description, Version: 2})
+
+        assert.Equal(t, domain.ErrStaleVersion, err)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPatchUnchanged(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        stored := domain.Transaction{ID: 1, Description: "Order A1", Metadata: map[string]string{"order": "A1"}, Version: 2}
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+        description, order := "Order A1", "A1"
+
+        res, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{Description: &description, Metadata: map[string]*string{"order": &order}, Version: 2})
+
+        assert.NoError(t, err)
+        assert.Equal(t, stored, res)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+        mockTransactionRepo.AssertNotCalled(t, "StoreChanges", mock.Anything, mock.Anything)
+}
+
+func TestPatchMetadataLimit(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        metadata := map[string]string{}
+        for i := 0; i < domain.MaxMetadataKeys; i++ {
+                metadata[fmt.Sprint("key", i)] = "value"
+        }
+        stored := domain.Transaction{ID: 1, State: domain.TransactionAuthorized, Metadata: metadata, Version: 2}
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(stored, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+        value, captured := "value", domain.TransactionCaptured
+
+        _, err := u.Patch(context.TODO(), 1, &domain.TransactionPatch{Metadata: map[string]*string{"one_more": &value}, State: &captured, Version: 2})
+
+        assert.Equal(t, domain.ErrMetadataLimit, err)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestStoreMerchantReferenceTaken(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CardToken: "tok_1",
+                MerchantReference: "order-1",
+        }
+
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        mockTransactionRepo.On("GetByMerchantReference", mock.Anything, int64(1), "order-1").Return(domain.Transaction{ID: 4, MerchantID: 1, MerchantReference: "order-1"}, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrReferenceTaken, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreMerchantReferenceTakenConcurrently(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CardToken: "tok_1",
+                MerchantReference: "order-1",
+        }
+
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        mockTransactionRepo.On("GetByMerchantReference", mock.Anything, int64(1), "order-1").Return(domain.Transaction{}, domain.ErrNotFound).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(domain.ErrReferenceTaken).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrReferenceTaken, err)
+        mockTransactionRepo.AssertExpectations(t)
+        mockProcessor.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestStoreOutOfBounds(t *testing.T) {
+        tests := []struct {
+                name      string
+                reference string
+                metadata  map[string]string
+                err       error
+        }{
+                {name: "long reference", reference: strings.Repeat("a", domain.MaxMerchantReferenceLength+1), err: domain.ErrReference},
+                {name: "long key", metadata: map[string]string{strings.Repeat("k", domain.MaxMetadataKeyLength+1): "v"}, err: domain.ErrMetadataLimit},
+                {name: "long value", metadata: map[string]string{"order": strings.Repeat("v", domain.MaxMetadataValueLength+1)}, err: domain.ErrMetadataLimit},
+                {name: "empty key", metadata: map[string]string{"": "v"}, err: domain.ErrMetadataLimit},
+        }
+
+        for _, tt := range tests {
+                mockMerchantRepo := new(mocks.MerchantRepository)
+                mockTransactionRepo := new(mocks.TransactionRepository)
+                data := domain.Transaction{MerchantID: 1, ParentMerchantID: 1, SettingID: 1, Amount: 10000, CardToken: "tok_1", MerchantReference: tt.reference, Metadata: tt.metadata}
+
+                mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+                u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+                err := u.Store(context.TODO(), &data)
+
+                assert.Equal(t, tt.err, err, tt.name)
+                mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+        }
+}
+
+func TestGetByMerchantReference(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        data := domain.Transaction{ID: 4, MerchantID: 1, MerchantReference: "order-1"}
+        mockTransactionRepo.On("GetByMerchantReference", mock.Anything, int64(1), "order-1").Return(data, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+        res, err := u.GetByMerchantReference(context.TODO(), 1, "order-1")
+        assert.NoError(t, err)
+        assert.Equal(t, data, res)
+
+        _, err = u.GetByMerchantReference(context.TODO(), 1, "")
+        assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestStoreDeclined(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10005,
+                CardToken: "tok_1",
+        }
+        declined := domain.ProcessorResponse{Processor: "simulator", Reference: "simulator-1", Code: domain.ResponseDeclined, Message: "Do not honor"}
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(declined, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionDeclined, data.State)
+        assert.Equal(t, domain.ResponseDeclined, data.ResponseCode)
+        assert.False(t, data.Status)
+}
+
+func TestStoreProcessorTimeout(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10068,
+                CardToken: "tok_1",
+        }
+        timeout := domain.ProcessorResponse{Processor: "simulator", Reference: "simulator-1", Code: domain.ResponseTimeout}
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(timeout, domain.ErrProcessorTimeout).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionFailed, data.State)
+        assert.Equal(t, domain.ResponseTimeout, data.ResponseCode)
+        mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestCapture(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                ID: 1,
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                State: domain.TransactionAuthorized,
+                Processor: "simulator",
+                ProcessorReference: "simulator-1",
+        }
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(data, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionProcessing
+        })).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionCaptured
+        })).Return(nil).Once()
+        mockProcessor.On("Capture", mock.Anything, mock.MatchedBy(func(req *domain.PaymentRequest) bool {
+                return req.Reference == "simulator-1"
+        })).Return(approved, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        res, err := u.Capture(context.TODO(), int64(1))
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionCaptured, res.State)
+        assert.True(t, res.Status)
+}
+
+func TestCaptureConcurrently(t *testing.T) {
+        tr := transactionRepo.NewTransactionRepository(migrationtest.NewDB(t))
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{MerchantID: 1, ParentMerchantID: 1, SettingID: 1, Amount: 10000, Currency: "IDR", PaymentType: "CARD", State: domain.TransactionAuthorized, ProcessorReference: "simulator-1"}
+        assert.NoError(t, tr.Store(context.TODO(), &data))
+        mockProcessor.On("Capture", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+                time.Sleep(10 * time.Millisecond)
+        }).Return(approved, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), tr, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), mockProcessor)
+
+        errs := make(chan error, 5)
+        var wg sync.WaitGroup
+        for n := 0; n < 5; n++ {
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        _, err := u.Capture(context.TODO(), data.ID)
+                        errs <- err
+                }()
+        }
+        wg.Wait()
+        close(errs)
+
+        captured := 0
+        for err := range errs {
+                if err == nil {
+                        captured++
+                }
+        }
+        assert.Equal(t, 1, captured)
+        mockProcessor.AssertNumberOfCalls(t, "Capture", 1)
+        res, err := tr.GetByID(context.TODO(), data.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionCaptured, res.State)
+}
+
+func TestCaptureProcessorError(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{ID: 1, State: domain.TransactionAuthorized, ProcessorReference: "simulator-1"}
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(data, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionProcessing
+        })).Return(nil).Once()
+        mockProcessor.On("Capture", mock.Anything, mock.Anything).Return(domain.ProcessorResponse{}, domain.ErrProcessorTimeout).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionAuthorized
+        })).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), mockProcessor)
+
+        _, err := u.Capture(context.TODO(), int64(1))
+
+        assert.Equal(t, domain.ErrProcessorTimeout, err)
+        mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestRefundInvalidState(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                ID: 1,
+                State: domain.TransactionAuthorized,
+        }
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(data, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        _, err := u.Refund(context.TODO(), int64(1))
+
+        assert.Equal(t, domain.ErrInvalidState, err)
+        mockProcessor.AssertNotCalled(t, "Refund", mock.Anything, mock.Anything)
+}
+
+func TestVoid(t *testing.T) {
         mockMerchantRepo := new(mocks.MerchantRepository)
         mockTransactionRepo := new(mocks.TransactionRepository)
-        data := &domain.Transaction{
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
                 ID: 1,
+                State: domain.TransactionAuthorized,
+                ProcessorReference: "simulator-1",
+        }
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(1)).Return(data, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionProcessing
+        })).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionVoided
+        })).Return(nil).Once()
+        mockProcessor.On("Void", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        res, err := u.Void(context.TODO(), int64(1))
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionVoided, res.State)
+        assert.False(t, res.Status)
+}
+
+func TestStoreUnknownCardToken(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
                 MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CardToken: "tok_other",
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_other").Return(domain.Card{}, domain.ErrNotFound).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrInvalidCard, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreWithPaymentMethod(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 2,
                 ParentMerchantID: 2,
                 SettingID: 1,
-                Status: false,
+                Amount: 10000,
+                PaymentMethodID: 5,
+        }
+        pm := domain.PaymentMethod{ID: 5, CustomerID: 3, MerchantID: 1, CardToken: "tok_1", Last4: "1111", Brand: "VISA"}
+
+        mockCustomers.On("GetPaymentMethod", mock.Anything, int64(2), int64(5)).Return(pm, nil).Once()
+        mockCardVault.On("GetByToken", mock.Anything, int64(2), "tok_1").Return(domain.Card{Token: "tok_1", MerchantID: 2, Last4: "1111", Brand: "VISA", Fingerprint: "fp_1"}, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.MatchedBy(func(req *domain.PaymentRequest) bool {
+                return req.CardToken == "tok_1"
+        })).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(2)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(3), data.CustomerID)
+        assert.Equal(t, "1111", data.CardLast4)
+        assert.Equal(t, "fp_1", data.CardFingerprint)
+}
+
+func TestStoreWithPaymentMethodOfAnotherCustomer(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CustomerID: 4,
+                PaymentMethodID: 5,
+        }
+        pm := domain.PaymentMethod{ID: 5, CustomerID: 3, MerchantID: 1, CardToken: "tok_1"}
+
+        mockCustomers.On("GetPaymentMethod", mock.Anything, int64(1), int64(5)).Return(pm, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrInvalidCustomer, err)
+}
+
+func TestStoreWithUnknownCustomer(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCustomers := new(mocks.CustomerUsecase)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                CustomerID: 4,
+                CardToken: "tok_1",
+        }
+
+        mockCustomers.On("GetByID", mock.Anything, int64(1), int64(4)).Return(domain.Customer{}, domain.ErrNotFound).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, mockCustomers, mockCardVault, mockProcessor)
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrInvalidCustomer, err)
+}
+
+func TestStoreThroughChannel(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        setting := domain.Setting{ID: 7, MerchantID: 1, PaymentType: "QR"}
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 7,
+                Amount: 10000,
+                PaymentType: "QR",
+        }
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockChannel.On("Initiate", mock.Anything, mock.Anything, setting).Return(nil).Once()
+        mockMerchantRepo.On("GetSetting", mock.Anything, int64(7)).Return(setting, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionPending, data.State)
+        mockChannel.AssertExpectations(t)
+}
+
+func TestStoreThroughChannelIgnoresCardFields(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        setting := domain.Setting{ID: 7, MerchantID: 1, PaymentType: "QR"}
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 7,
+                Amount: 10000,
+                PaymentType: "QR",
+                CardToken: "tok_1",
+                CardLast4: "1111",
+                CardBrand: "VISA",
+                PaymentMethodID: 3,
+                ProcessorReference: "planted-1",
         }
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockChannel.On("Initiate", mock.Anything, mock.Anything, setting).Return(nil).Once()
+        mockMerchantRepo.On("GetSetting", mock.Anything, int64(7)).Return(setting, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+                return t.CardToken == "" && t.CardLast4 == "" && t.CardBrand == "" && t.PaymentMethodID == 0 && t.ProcessorReference == ""
+        })).Return(nil).Once()
         mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
-        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo)
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel))
 
-        err := u.Update(context.TODO(), data)
+        err := u.Store(context.TODO(), &data)
 
         assert.NoError(t, err)
+        mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestStoreThroughFailingChannel(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        mockLimits := new(mocks.LimitUsecase)
+        mockPromotions := new(mocks.PromotionUsecase)
+        setting := domain.Setting{ID: 7, MerchantID: 1, PaymentType: "QR"}
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 7,
+                Amount: 10000,
+                PaymentType: "QR",
+                PromoCode: "HEMAT",
+        }
+        unavailable := fmt.Errorf("channel unavailable")
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockPromotions.On("Redeem", mock.Anything, mock.Anything, "").Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).PromotionID = 3
+        }).Return(nil).Once()
+        mockLimits.On("Reserve", mock.Anything, mock.Anything).Return(nil).Once()
+        mockMerchantRepo.On("GetSetting", mock.Anything, int64(7)).Return(setting, nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).ID = 4
+        }).Return(nil).Once()
+        mockChannel.On("Initiate", mock.Anything, mock.Anything, setting).Return(unavailable).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.ID == 4 && tx.State == domain.TransactionFailed && tx.ResponseMessage == "channel unavailable"
+        })).Return(nil).Once()
+        mockLimits.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        mockPromotions.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel), transactionUsecase.WithLimits(mockLimits), transactionUsecase.WithPromotions(mockPromotions))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, unavailable, err)
+        assert.Equal(t, domain.TransactionFailed, data.State)
+        mockTransactionRepo.AssertExpectations(t)
+        mockLimits.AssertExpectations(t)
+        mockPromotions.AssertExpectations(t)
+}
+
+func TestStoreThroughChannelWithCardSetting(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 10000,
+                PaymentType: "QR",
+        }
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockMerchantRepo.On("GetSetting", mock.Anything, int64(1)).Return(domain.Setting{ID: 1, MerchantID: 1, PaymentType: "CARD"}, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrPaymentMethod, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestComplete(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        pending := domain.Transaction{ID: 3, Amount: 10000, PaymentType: "QR", State: domain.TransactionPending}
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(3)).Return(pending, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel))
+
+        res, err := u.Complete(context.TODO(), 3, 10000, "issuer-1")
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionCaptured, res.State)
+        assert.Equal(t, "issuer-1", res.ProcessorReference)
+        assert.True(t, res.Status)
+}
+
+func TestCompleteAmountMismatch(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        pending := domain.Transaction{ID: 3, Amount: 10000, PaymentType: "QR", State: domain.TransactionPending}
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(3)).Return(pending, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel))
+
+        _, err := u.Complete(context.TODO(), 3, 9000, "")
+
+        assert.Equal(t, domain.ErrAmountMismatch, err)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestCompleteCardTransaction(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        pending := domain.Transaction{ID: 3, Amount: 10000, PaymentType: "CARD", State: domain.TransactionPending}
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(3)).Return(pending, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+        _, err := u.Complete(context.TODO(), 3, 10000, "")
+
+        assert.Equal(t, domain.ErrInvalidState, err)
+}
+
+func TestStoreWithInstallments(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockInstallments := new(mocks.InstallmentUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 1000000,
+                CardToken: "tok_1",
+                InstallmentPlanID: 2,
+        }
+        bin := card
+        bin.BIN = "411111"
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(bin, nil).Twice()
+        mockInstallments.On("Apply", mock.Anything, mock.Anything, "411111").Run(func(args mock.Arguments) {
+                tx := args.Get(1).(*domain.Transaction)
+                tx.InstallmentTenor = 6
+                tx.Fee = 10000
+                tx.SettlementAmount = 990000
+        }).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.MatchedBy(func(req *domain.PaymentRequest) bool {
+                return req.Installments == 6
+        })).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithInstallments(mockInstallments))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(990000), data.SettlementAmount)
+        mockProcessor.AssertExpectations(t)
+}
+
+func TestStoreWithInstallmentsNotOffered(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 1000000,
+                CardToken: "tok_1",
+                InstallmentPlanID: 2,
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Twice()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, new(mocks.PaymentProcessor))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrInstallmentPlan, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreWithPromotion(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockPromotions := new(mocks.PromotionUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 100000,
+                CardToken: "tok_1",
+                PromoCode: "HEMAT",
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Twice()
+        mockPromotions.On("Redeem", mock.Anything, mock.Anything, card.BIN).Run(func(args mock.Arguments) {
+                tx := args.Get(1).(*domain.Transaction)
+                tx.PromotionID = 3
+                tx.Discount = 10000
+                tx.Amount = 90000
+                tx.SettlementAmount = 90000
+        }).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.MatchedBy(func(req *domain.PaymentRequest) bool {
+                return req.Amount == 90000
+        })).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithPromotions(mockPromotions))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(100000), data.OriginalAmount)
+        assert.Equal(t, int64(90000), data.Amount)
+        mockProcessor.AssertExpectations(t)
+        mockPromotions.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+}
+
+func TestStoreWithPromotionDeclined(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockPromotions := new(mocks.PromotionUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 100000,
+                CardToken: "tok_1",
+                PromoCode: "HEMAT",
+        }
+        declined := domain.ProcessorResponse{Processor: "simulator", Reference: "simulator-1", Code: domain.ResponseDeclined, Message: "Do not honor"}
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Twice()
+        mockPromotions.On("Redeem", mock.Anything, mock.Anything, card.BIN).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).PromotionID = 3
+        }).Return(nil).Once()
+        mockPromotions.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(declined, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithPromotions(mockPromotions))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionDeclined, data.State)
+        mockPromotions.AssertExpectations(t)
+}
+
+func TestStoreWithPromotionQuotaExhausted(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockPromotions := new(mocks.PromotionUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 100000,
+                CardToken: "tok_1",
+                PromoCode: "HEMAT",
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Twice()
+        mockPromotions.On("Redeem", mock.Anything, mock.Anything, card.BIN).Return(domain.ErrPromotionQuota).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, new(mocks.PaymentProcessor), transactionUsecase.WithPromotions(mockPromotions))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrPromotionQuota, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreInForeignCurrency(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockFX := new(mocks.FXUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 1000,
+                Currency: "USD",
+                CardToken: "tok_1",
+                FXQuoteID: 4,
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockFX.On("Lock", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+                tx := args.Get(1).(*domain.Transaction)
+                tx.SettlementCurrency = "IDR"
+                tx.FXRate = "16250.00"
+                tx.FXMarkup = 200
+                tx.SettlementAmount = 159250
+        }).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.MatchedBy(func(req *domain.PaymentRequest) bool {
+                return req.Amount == 1000 && req.Currency == "USD"
+        })).Return(approved, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithFX(mockFX))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, int64(159250), data.SettlementAmount)
+        mockProcessor.AssertExpectations(t)
+}
+
+func TestStoreInForeignCurrencyWithoutFX(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 1000,
+                Currency: "USD",
+                CardToken: "tok_1",
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, new(mocks.PaymentProcessor))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrCurrency, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestStoreBlockedByRisk(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockPromotions := new(mocks.PromotionUsecase)
+        mockRisk := new(mocks.RiskUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 100000,
+                CardToken: "tok_1",
+                PromoCode: "HEMAT",
+                IPCountry: "sg",
+        }
+        bin := card
+        bin.BIN = "411111"
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(bin, nil).Twice()
+        mockPromotions.On("Redeem", mock.Anything, mock.Anything, "411111").Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).PromotionID = 3
+        }).Return(nil).Once()
+        mockPromotions.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        mockRisk.On("Evaluate", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.IPCountry == "SG"
+        }), "411111").Run(func(args mock.Arguments) {
+                tx := args.Get(1).(*domain.Transaction)
+                tx.RiskDecision = domain.RiskBlock
+                tx.RiskRules = []string{"blocked countries"}
+        }).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionDeclined && tx.RiskDecision == domain.RiskBlock
+        })).Return(nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithPromotions(mockPromotions), transactionUsecase.WithRisk(mockRisk))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionDeclined, data.State)
+        assert.Equal(t, "Blocked by risk rules", data.ResponseMessage)
+        mockProcessor.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+        mockPromotions.AssertExpectations(t)
+}
+
+func TestStoreHeldForReview(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockRisk := new(mocks.RiskUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 9000000,
+                CardToken: "tok_1",
+        }
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Twice()
+        mockRisk.On("Evaluate", mock.Anything, mock.Anything, card.BIN).Run(func(args mock.Arguments) {
+                args.Get(1).(*domain.Transaction).RiskDecision = domain.RiskReview
+        }).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithRisk(mockRisk))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionReview, data.State)
+        mockProcessor.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestApprove(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockProcessor := new(mocks.PaymentProcessor)
+        held := domain.Transaction{ID: 4, MerchantID: 1, Amount: 9000000, PaymentType: "CARD", CardToken: "tok_1", State: domain.TransactionReview, RiskDecision: domain.RiskReview}
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(held, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionPending
+        })).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(approved, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionAuthorized
+        })).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), mockProcessor)
+
+        res, err := u.Approve(context.TODO(), 4)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionAuthorized, res.State)
+        assert.Equal(t, domain.RiskReview, res.RiskDecision)
+}
+
+func TestApproveConcurrently(t *testing.T) {
+        tr := transactionRepo.NewTransactionRepository(migrationtest.NewDB(t))
+        mockProcessor := new(mocks.PaymentProcessor)
+        held := domain.Transaction{MerchantID: 1, ParentMerchantID: 1, SettingID: 1, Amount: 9000000, Currency: "IDR", PaymentType: "CARD", CardToken: "tok_1", State: domain.TransactionReview, RiskDecision: domain.RiskReview}
+        assert.NoError(t, tr.Store(context.TODO(), &held))
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+                time.Sleep(10 * time.Millisecond)
+        }).Return(approved, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), tr, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), mockProcessor)
+
+        var wg sync.WaitGroup
+        for n := 0; n < 5; n++ {
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        u.Approve(context.TODO(), held.ID)
+                }()
+        }
+        wg.Wait()
+
+        mockProcessor.AssertNumberOfCalls(t, "Authorize", 1)
+        res, err := tr.GetByID(context.TODO(), held.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionAuthorized, res.State)
+}
+
+func TestApproveThroughFailingChannel(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockChannel := new(mocks.PaymentChannel)
+        mockLimits := new(mocks.LimitUsecase)
+        setting := domain.Setting{ID: 7, MerchantID: 1, PaymentType: "QR"}
+        held := domain.Transaction{ID: 4, MerchantID: 1, SettingID: 7, Amount: 9000000, PaymentType: "QR", State: domain.TransactionReview, RiskDecision: domain.RiskReview}
+        unavailable := fmt.Errorf("channel unavailable")
+
+        mockChannel.On("PaymentType").Return("QR")
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(held, nil).Once()
+        mockMerchantRepo.On("GetSetting", mock.Anything, int64(7)).Return(setting, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionPending
+        })).Return(nil).Once()
+        mockChannel.On("Initiate", mock.Anything, mock.Anything, setting).Return(unavailable).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionFailed
+        })).Return(nil).Once()
+        mockLimits.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(mockChannel), transactionUsecase.WithLimits(mockLimits))
+
+        _, err := u.Approve(context.TODO(), 4)
+
+        assert.Equal(t, unavailable, err)
+        mockTransactionRepo.AssertExpectations(t)
+        mockLimits.AssertExpectations(t)
+}
+
+func TestRejectNotHeld(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionAuthorized}, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+        _, err := u.Reject(context.TODO(), 4)
+
+        assert.Equal(t, domain.ErrInvalidState, err)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestReject(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockPromotions := new(mocks.PromotionUsecase)
+        held := domain.Transaction{ID: 4, MerchantID: 1, PromotionID: 3, State: domain.TransactionReview}
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(held, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockPromotions.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithPromotions(mockPromotions))
+
+        res, err := u.Reject(context.TODO(), 4)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionDeclined, res.State)
+        mockPromotions.AssertExpectations(t)
+}
+
+func TestStoreOverLimit(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockLimits := new(mocks.LimitUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 600000,
+                CardToken: "tok_1",
+        }
+        exceeded := &domain.LimitError{LimitStatus: domain.LimitStatus{Limit: domain.LimitDailyVolume, Cap: 500000, Remaining: 500000}}
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockLimits.On("Reserve", mock.Anything, mock.Anything).Return(exceeded).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithLimits(mockLimits))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, exceeded, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+        mockProcessor.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestStoreDeclinedReleasesLimits(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockCardVault := new(mocks.CardVaultUsecase)
+        mockProcessor := new(mocks.PaymentProcessor)
+        mockLimits := new(mocks.LimitUsecase)
+        data := domain.Transaction{
+                MerchantID: 1,
+                ParentMerchantID: 1,
+                SettingID: 1,
+                Amount: 100000,
+                CardToken: "tok_1",
+        }
+        declined := domain.ProcessorResponse{Processor: "simulator", Code: "05", Message: "Do not honor"}
+
+        mockCardVault.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(card, nil).Once()
+        mockLimits.On("Reserve", mock.Anything, mock.Anything).Return(nil).Once()
+        mockTransactionRepo.On("Store", mock.Anything, mock.Anything).Return(nil).Once()
+        mockProcessor.On("Authorize", mock.Anything, mock.Anything).Return(declined, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+        mockLimits.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(1)).Return(active, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), mockCardVault, mockProcessor, transactionUsecase.WithLimits(mockLimits))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionDeclined, data.State)
+        mockLimits.AssertExpectations(t)
+}
+
+func TestFail(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockLimits := new(mocks.LimitUsecase)
+        pending := domain.Transaction{ID: 4, MerchantID: 1, PaymentType: "VA_BCA", Amount: 50000, State: domain.TransactionPending}
+
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(pending, nil).Once()
+        mockTransactionRepo.On("Update", mock.Anything, mock.MatchedBy(func(tx *domain.Transaction) bool {
+                return tx.State == domain.TransactionFailed && tx.ResponseMessage == "Virtual account expired"
+        })).Return(nil).Once()
+        mockLimits.On("Release", mock.Anything, mock.Anything).Return(nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithLimits(mockLimits))
+
+        res, err := u.Fail(context.TODO(), 4, "Virtual account expired")
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionFailed, res.State)
+        mockLimits.AssertExpectations(t)
+}
+
+func TestFailNotPending(t *testing.T) {
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        mockLimits := new(mocks.LimitUsecase)
+        mockTransactionRepo.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(new(mocks.MerchantRepository), mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithLimits(mockLimits))
+
+        res, err := u.Fail(context.TODO(), 4, "Virtual account expired")
+
+        assert.NoError(t, err)
+        assert.Equal(t, domain.TransactionCaptured, res.State)
+        mockTransactionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+        mockLimits.AssertNotCalled(t, "Release", mock.Anything, mock.Anything)
+}
+
+func TestStoreMerchantHeldForReview(t *testing.T) {
+        mockMerchantRepo := new(mocks.MerchantRepository)
+        mockTransactionRepo := new(mocks.TransactionRepository)
+        data := domain.Transaction{
+                MerchantID: 11,
+                ParentMerchantID: 11,
+                SettingID: 1,
+                Amount: 10000,
+                CardToken: "tok_1",
+        }
+
+        mockMerchantRepo.On("GetByID", mock.Anything, int64(11)).Return(domain.Merchant{ID: 11, Status: domain.MerchantPendingReview}, nil).Once()
+        u := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, mockTransactionRepo, new(mocks.CustomerUsecase), new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor))
+
+        err := u.Store(context.TODO(), &data)
+
+        assert.Equal(t, domain.ErrMerchantInactive, err)
+        mockTransactionRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
 }
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type VaultHandler struct {
+	Usecase domain.CardVaultUsecase
+}
+
+func NewVaultHandler(e *echo.Echo, u domain.CardVaultUsecase) *VaultHandler {
+	handler := &VaultHandler{
+		Usecase: u,
+	}
+
+	e.POST("/vault/cards", handler.Tokenize)
+	e.GET("/vault/cards/:token", handler.GetByToken)
+	e.DELETE("/vault/cards/:token", handler.Delete)
+
+	return handler
+}
+
+func (h *VaultHandler) Tokenize(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.Card
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.Number == "" {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Tokenize(ctx, &data)
+	if err == domain.ErrInvalidCard || err == domain.ErrCardExpired || err == domain.ErrUnknownBrand {
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *VaultHandler) GetByToken(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByToken(ctx, merchantID, c.Param("token"))
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *VaultHandler) Delete(c echo.Context) error {
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	err = h.Usecase.Delete(ctx, merchantID, c.Param("token"))
+	if err == domain.ErrNotFound {
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	vaultHttp "github.com/hezbymuhammad/payment-gateway/vault/delivery/http"
+)
+
+func TestTokenize(t *testing.T) {
+	mockUsecase := new(mocks.CardVaultUsecase)
+	mockUsecase.On("Tokenize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		c := args.Get(1).(*domain.Card)
+		c.Token = "tok_1"
+		c.Number = ""
+		c.Last4 = "1111"
+	}).Return(nil).Once()
+
+	j, err := json.Marshal(domain.Card{MerchantID: 1, Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2030})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/vault/cards", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/vault/cards")
+
+	handler := vaultHttp.NewVaultHandler(echo.New(), mockUsecase)
+	err = handler.Tokenize(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"token":"tok_1"`)
+	assert.NotContains(t, rec.Body.String(), "4111111111111111")
+}
+
+func TestTokenizeInvalidCard(t *testing.T) {
+	mockUsecase := new(mocks.CardVaultUsecase)
+	mockUsecase.On("Tokenize", mock.Anything, mock.Anything).Return(domain.ErrInvalidCard).Once()
+
+	j, err := json.Marshal(domain.Card{MerchantID: 1, Number: "4111111111111112"})
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/vault/cards", strings.NewReader(string(j)))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/vault/cards")
+
+	handler := vaultHttp.NewVaultHandler(echo.New(), mockUsecase)
+	err = handler.Tokenize(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestGetByToken(t *testing.T) {
+	mockUsecase := new(mocks.CardVaultUsecase)
+	mockUsecase.On("GetByToken", mock.Anything, int64(1), "tok_1").Return(domain.Card{Token: "tok_1", Last4: "1111", Brand: "VISA"}, nil).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.GET, "/vault/cards/tok_1?merchantId=1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/vault/cards/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("tok_1")
+
+	handler := vaultHttp.NewVaultHandler(echo.New(), mockUsecase)
+	err = handler.GetByToken(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"brand":"VISA"`)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	mockUsecase := new(mocks.CardVaultUsecase)
+	mockUsecase.On("Delete", mock.Anything, int64(1), "tok_1").Return(domain.ErrNotFound).Once()
+
+	e := echo.New()
+	req, err := http.NewRequest(echo.DELETE, "/vault/cards/tok_1?merchantId=1", strings.NewReader(""))
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/vault/cards/:token")
+	ctx.SetParamNames("token")
+	ctx.SetParamValues("tok_1")
+
+	handler := vaultHttp.NewVaultHandler(echo.New(), mockUsecase)
+	err = handler.Delete(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
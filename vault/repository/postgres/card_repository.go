@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const cardColumns = "token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext"
+
+type postgresCardRepo struct {
+	DB *sql.DB
+}
+
+func NewCardRepository(db *sql.DB) domain.CardVaultRepository {
+	return &postgresCardRepo{
+		DB: db,
+	}
+}
+
+func (cr *postgresCardRepo) Store(ctx context.Context, c *domain.VaultedCard) error {
+	query := "INSERT INTO cards (" + cardColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+
+	_, err := cr.exec(ctx, query, c.Token, c.MerchantID, c.BIN, c.Last4, c.Brand, c.Fingerprint, c.ExpiryMonth, c.ExpiryYear, c.EncryptedKey, c.Ciphertext)
+	return err
+}
+
+func (cr *postgresCardRepo) GetByToken(ctx context.Context, token string) (domain.VaultedCard, error) {
+	query := "SELECT " + cardColumns + " FROM cards WHERE token=$1"
+
+	data, err := scanCard(cr.DB.QueryRowContext(ctx, query, token))
+	if err == sql.ErrNoRows {
+		return domain.VaultedCard{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.VaultedCard{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *postgresCardRepo) Delete(ctx context.Context, token string) error {
+	query := "DELETE FROM cards WHERE token=$1"
+
+	res, err := cr.exec(ctx, query, token)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// FetchWithoutFingerprint lists the cards vaulted before fingerprints were
+// kept.
+func (cr *postgresCardRepo) FetchWithoutFingerprint(ctx context.Context) ([]domain.VaultedCard, error) {
+	query := "SELECT " + cardColumns + " FROM cards WHERE fingerprint='' ORDER BY token"
+
+	rows, err := cr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.VaultedCard{}
+	for rows.Next() {
+		data, err := scanCard(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// SetFingerprint sets the fingerprint of a card that has none, and of the
+// transactions charged to it, in one database transaction.
+func (cr *postgresCardRepo) SetFingerprint(ctx context.Context, token string, fingerprint string) error {
+	tx, err := cr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE cards SET fingerprint=$1 WHERE token=$2 AND fingerprint=''"
+	_, err = tx.ExecContext(ctx, query, fingerprint, token)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "UPDATE transactions SET card_fingerprint=$1 WHERE card_token=$2 AND card_fingerprint=''"
+	_, err = tx.ExecContext(ctx, query, fingerprint, token)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cr *postgresCardRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := cr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCard(row scanner) (domain.VaultedCard, error) {
+	data := domain.VaultedCard{}
+	err := row.Scan(
+		&data.Token,
+		&data.MerchantID,
+		&data.BIN,
+		&data.Last4,
+		&data.Brand,
+		&data.Fingerprint,
+		&data.ExpiryMonth,
+		&data.ExpiryYear,
+		&data.EncryptedKey,
+		&data.Ciphertext,
+	)
+
+	return data, err
+}
@@ -0,0 +1,21 @@
+//go:build postgres
+// +build postgres
+
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/postgres"
+	vaultRepo "github.com/hezbymuhammad/payment-gateway/vault/repository/postgres"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.CardVaultRepository(t, func(t *testing.T) (domain.CardVaultRepository, domain.TransactionRepository) {
+		db := migrationtest.NewPostgresDB(t)
+		return vaultRepo.NewCardRepository(db), transactionRepo.NewTransactionRepository(db)
+	})
+}
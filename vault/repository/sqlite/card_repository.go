@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type sqliteCardRepo struct {
+	DB *sql.DB
+}
+
+func NewCardRepository(db *sql.DB) domain.CardVaultRepository {
+	return &sqliteCardRepo{
+		DB: db,
+	}
+}
+
+func (cr *sqliteCardRepo) Store(ctx context.Context, c *domain.VaultedCard) error {
+	query := "INSERT INTO cards (token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, c.Token, c.MerchantID, c.BIN, c.Last4, c.Brand, c.Fingerprint, c.ExpiryMonth, c.ExpiryYear, c.EncryptedKey, c.Ciphertext)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (cr *sqliteCardRepo) GetByToken(ctx context.Context, token string) (domain.VaultedCard, error) {
+	query := "SELECT token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext FROM cards WHERE token=? LIMIT 1"
+
+	data := domain.VaultedCard{}
+	err := cr.DB.QueryRowContext(ctx, query, token).Scan(
+		&data.Token,
+		&data.MerchantID,
+		&data.BIN,
+		&data.Last4,
+		&data.Brand,
+		&data.Fingerprint,
+		&data.ExpiryMonth,
+		&data.ExpiryYear,
+		&data.EncryptedKey,
+		&data.Ciphertext,
+	)
+	if err == sql.ErrNoRows {
+		return domain.VaultedCard{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.VaultedCard{}, err
+	}
+
+	return data, nil
+}
+
+func (cr *sqliteCardRepo) Delete(ctx context.Context, token string) error {
+	query := "DELETE FROM cards WHERE token=?"
+
+	stmt, err := cr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, token)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// FetchWithoutFingerprint lists the cards vaulted before fingerprints were
+// kept.
+func (cr *sqliteCardRepo) FetchWithoutFingerprint(ctx context.Context) ([]domain.VaultedCard, error) {
+	query := "SELECT token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext FROM cards WHERE fingerprint='' ORDER BY token"
+
+	rows, err := cr.DB.QueryContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.VaultedCard{}
+	for rows.Next() {
+		data := domain.VaultedCard{}
+		err = rows.Scan(
+			&data.Token,
+			&data.MerchantID,
+			&data.BIN,
+			&data.Last4,
+			&data.Brand,
+			&data.Fingerprint,
+			&data.ExpiryMonth,
+			&data.ExpiryYear,
+			&data.EncryptedKey,
+			&data.Ciphertext,
+		)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// SetFingerprint sets the fingerprint of a card that has none, and of the
+// transactions charged to it, in one database transaction.
+func (cr *sqliteCardRepo) SetFingerprint(ctx context.Context, token string, fingerprint string) error {
+	tx, err := cr.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE cards SET fingerprint=? WHERE token=? AND fingerprint=''"
+	_, err = tx.ExecContext(ctx, query, fingerprint, token)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	query = "UPDATE transactions SET card_fingerprint=? WHERE card_token=? AND card_fingerprint=''"
+	_, err = tx.ExecContext(ctx, query, fingerprint, token)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return tx.Commit()
+}
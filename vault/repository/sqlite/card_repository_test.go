@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	cardRepo "github.com/hezbymuhammad/payment-gateway/vault/repository/sqlite"
+)
+
+var card = domain.VaultedCard{
+	Card: domain.Card{
+		Token:       "tok_1",
+		MerchantID:  1,
+		BIN:         "411111",
+		Last4:       "1111",
+		Brand:       "VISA",
+		Fingerprint: "fp_1",
+		ExpiryMonth: 12,
+		ExpiryYear:  2030,
+	},
+	EncryptedKey: []byte("key"),
+	Ciphertext:   []byte("pan"),
+}
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("INSERT INTO cards (token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(card.Token, card.MerchantID, card.BIN, card.Last4, card.Brand, card.Fingerprint, card.ExpiryMonth, card.ExpiryYear, card.EncryptedKey, card.Ciphertext).WillReturnResult(sqlmock.NewResult(1, 1))
+	cr := cardRepo.NewCardRepository(db)
+
+	data := card
+	err = cr.Store(context.TODO(), &data)
+	assert.NoError(t, err)
+}
+
+func TestGetByToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"token", "merchant_id", "bin", "last4", "brand", "fingerprint", "expiry_month", "expiry_year", "encrypted_key", "ciphertext"}).
+		AddRow(card.Token, card.MerchantID, card.BIN, card.Last4, card.Brand, card.Fingerprint, card.ExpiryMonth, card.ExpiryYear, card.EncryptedKey, card.Ciphertext)
+	query := regexp.QuoteMeta("SELECT token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext FROM cards WHERE token=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs("tok_1").WillReturnRows(rows)
+	cr := cardRepo.NewCardRepository(db)
+
+	res, err := cr.GetByToken(context.TODO(), "tok_1")
+	assert.NoError(t, err)
+	assert.Equal(t, card, res)
+}
+
+func TestGetByTokenNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"token", "merchant_id", "bin", "last4", "brand", "fingerprint", "expiry_month", "expiry_year", "encrypted_key", "ciphertext"})
+	query := regexp.QuoteMeta("SELECT token, merchant_id, bin, last4, brand, fingerprint, expiry_month, expiry_year, encrypted_key, ciphertext FROM cards WHERE token=? LIMIT 1")
+	mock.ExpectQuery(query).WillReturnRows(rows)
+	cr := cardRepo.NewCardRepository(db)
+
+	_, err = cr.GetByToken(context.TODO(), "tok_1")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("DELETE FROM cards WHERE token=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs("tok_1").WillReturnResult(sqlmock.NewResult(0, 1))
+	cr := cardRepo.NewCardRepository(db)
+
+	err = cr.Delete(context.TODO(), "tok_1")
+	assert.NoError(t, err)
+}
+
+func TestDeleteError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("DELETE FROM cards WHERE token=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs("tok_1").WillReturnError(fmt.Errorf("some error"))
+	cr := cardRepo.NewCardRepository(db)
+
+	err = cr.Delete(context.TODO(), "tok_1")
+	assert.Error(t, err)
+}
+
+func TestSetFingerprint(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	cr := cardRepo.NewCardRepository(db)
+	unfingerprinted, fingerprinted := card, card
+	unfingerprinted.Fingerprint = ""
+	fingerprinted.Token = "tok_2"
+	assert.NoError(t, cr.Store(context.TODO(), &unfingerprinted))
+	assert.NoError(t, cr.Store(context.TODO(), &fingerprinted))
+	_, err := db.Exec("INSERT INTO transactions (merchant_id, setting_id, status, card_token) VALUES (1, 1, 0, 'tok_1'), (1, 1, 0, 'tok_2')")
+	assert.NoError(t, err)
+
+	res, err := cr.FetchWithoutFingerprint(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.VaultedCard{unfingerprinted}, res)
+
+	assert.NoError(t, cr.SetFingerprint(context.TODO(), "tok_1", "fp_2"))
+
+	res, err = cr.FetchWithoutFingerprint(context.TODO())
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+	var fingerprints []string
+	rows, err := db.Query("SELECT card_fingerprint FROM transactions ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var fp string
+		assert.NoError(t, rows.Scan(&fp))
+		fingerprints = append(fingerprints, fp)
+	}
+	assert.Equal(t, []string{"fp_2", ""}, fingerprints)
+}
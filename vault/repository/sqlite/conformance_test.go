@@ -0,0 +1,18 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
+	vaultRepo "github.com/hezbymuhammad/payment-gateway/vault/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.CardVaultRepository(t, func(t *testing.T) (domain.CardVaultRepository, domain.TransactionRepository) {
+		db := migrationtest.NewDB(t)
+		return vaultRepo.NewCardRepository(db), transactionRepo.NewTransactionRepository(db)
+	})
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// seal encrypts plaintext under a fresh data key and returns the data key
+// wrapped with the vault key alongside the ciphertext.
+func seal(vaultKey []byte, plaintext []byte) ([]byte, []byte, error) {
+	dataKey := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := encrypt(vaultKey, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err := encrypt(dataKey, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wrapped, ciphertext, nil
+}
+
+func open(vaultKey []byte, wrapped []byte, ciphertext []byte) ([]byte, error) {
+	dataKey, err := decrypt(vaultKey, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(dataKey, ciphertext)
+}
+
+// encrypt uses AES-GCM and prefixes the output with its nonce.
+func encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key []byte, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// fingerprint identifies a card number without revealing it. The HMAC key is
+// derived from the vault key rather than being the vault key itself.
+func fingerprint(vaultKey []byte, number string) string {
+	k := hmac.New(sha256.New, vaultKey)
+	k.Write([]byte("card fingerprint"))
+
+	mac := hmac.New(sha256.New, k.Sum(nil))
+	mac.Write([]byte(number))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+// KeyPlaceholder is the vault.key shipped in config.json. It only says
+// where the real key goes, and LoadKey refuses it.
+const KeyPlaceholder = "set VAULT_KEY or vault.keyFile"
+
+// LoadKey finds the 32 byte vault key, base64 encoded, in env (the
+// VAULT_KEY environment variable), else in the file at keyFile, else in
+// configured (vault.key).
+func LoadKey(env string, keyFile string, configured string) ([]byte, error) {
+	encoded := env
+	if encoded == "" && keyFile != "" {
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(b)
+	}
+	if encoded == "" {
+		encoded = configured
+	}
+	encoded = strings.TrimSpace(encoded)
+
+	if encoded == "" || encoded == KeyPlaceholder {
+		return nil, errors.New("no vault key: " + KeyPlaceholder)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("the vault key must be 32 bytes of base64")
+	}
+
+	return key, nil
+}
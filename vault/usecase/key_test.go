@@ -0,0 +1,53 @@
+package usecase_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vaultUsecase "github.com/hezbymuhammad/payment-gateway/vault/usecase"
+)
+
+func TestLoadKey(t *testing.T) {
+	envKey := bytes.Repeat([]byte{1}, 32)
+	fileKey := bytes.Repeat([]byte{2}, 32)
+	dir, err := ioutil.TempDir("", "vault")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "vault.key")
+	assert.NoError(t, ioutil.WriteFile(keyFile, []byte("AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=\n"), 0600))
+
+	res, err := vaultUsecase.LoadKey("AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=", keyFile, vaultUsecase.KeyPlaceholder)
+	assert.NoError(t, err)
+	assert.Equal(t, envKey, res)
+
+	res, err = vaultUsecase.LoadKey("", keyFile, vaultUsecase.KeyPlaceholder)
+	assert.NoError(t, err)
+	assert.Equal(t, fileKey, res)
+
+	res, err = vaultUsecase.LoadKey("", "", "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=")
+	assert.NoError(t, err)
+	assert.Equal(t, envKey, res)
+}
+
+func TestLoadKeyRefused(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		env        string
+		keyFile    string
+		configured string
+	}{
+		{name: "placeholder", configured: vaultUsecase.KeyPlaceholder},
+		{name: "missing", configured: ""},
+		{name: "no file", keyFile: "/nonexistent/vault.key"},
+		{name: "short", env: "c2hvcnQ="},
+		{name: "not base64", env: "lorem ipsum"},
+	} {
+		_, err := vaultUsecase.LoadKey(tt.env, tt.keyFile, tt.configured)
+		assert.Error(t, err, tt.name)
+	}
+}
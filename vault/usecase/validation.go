@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type binRange struct {
+	brand   string
+	low     int
+	high    int
+	digits  int
+	lengths []int
+}
+
+// Issuer ranges we accept, matched on the leading digits of the PAN.
+var binRanges = []binRange{
+	{"VISA", 4, 4, 1, []int{13, 16, 19}},
+	{"MASTERCARD", 51, 55, 2, []int{16}},
+	{"MASTERCARD", 2221, 2720, 4, []int{16}},
+	{"AMEX", 34, 34, 2, []int{15}},
+	{"AMEX", 37, 37, 2, []int{15}},
+	{"JCB", 3528, 3589, 4, []int{16, 17, 18, 19}},
+}
+
+func luhn(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+func brandOf(number string) (string, error) {
+	for _, r := range binRanges {
+		prefix, err := strconv.Atoi(number[:r.digits])
+		if err != nil || prefix < r.low || prefix > r.high {
+			continue
+		}
+		for _, l := range r.lengths {
+			if len(number) == l {
+				return r.brand, nil
+			}
+		}
+		return "", domain.ErrInvalidCard
+	}
+
+	return "", domain.ErrUnknownBrand
+}
+
+// validate normalises the card number and fills in BIN, last4 and brand.
+func validate(c *domain.Card, now time.Time) error {
+	number := strings.NewReplacer(" ", "", "-", "").Replace(c.Number)
+	if len(number) < 12 || len(number) > 19 {
+		return domain.ErrInvalidCard
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return domain.ErrInvalidCard
+		}
+	}
+	if !luhn(number) {
+		return domain.ErrInvalidCard
+	}
+
+	brand, err := brandOf(number)
+	if err != nil {
+		return err
+	}
+
+	if c.ExpiryMonth < 1 || c.ExpiryMonth > 12 {
+		return domain.ErrInvalidCard
+	}
+	if c.ExpiryYear < 100 {
+		c.ExpiryYear += 2000
+	}
+	// A card is good until the last moment of its expiry month.
+	expiry := time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	if !now.Before(expiry) {
+		return domain.ErrCardExpired
+	}
+
+	c.Number = number
+	c.BIN = number[:6]
+	c.Last4 = number[len(number)-4:]
+	c.Brand = brand
+	return nil
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type vaultUsecase struct {
+	cardRepo domain.CardVaultRepository
+	key      []byte
+}
+
+// NewCardVaultUsecase returns the merchant-facing side of the vault. key is the
+// 32 byte envelope key used to wrap every per-card data key.
+func NewCardVaultUsecase(cr domain.CardVaultRepository, key []byte) domain.CardVaultUsecase {
+	return &vaultUsecase{
+		cardRepo: cr,
+		key:      key,
+	}
+}
+
+func (vu *vaultUsecase) Tokenize(ctx context.Context, c *domain.Card) error {
+	err := validate(c, time.Now())
+	if err != nil {
+		return err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	wrapped, ciphertext, err := seal(vu.key, []byte(c.Number))
+	if err != nil {
+		return err
+	}
+
+	c.Token = token
+	c.Fingerprint = fingerprint(vu.key, c.Number)
+	c.Number = ""
+	err = vu.cardRepo.Store(ctx, &domain.VaultedCard{
+		Card:         *c,
+		EncryptedKey: wrapped,
+		Ciphertext:   ciphertext,
+	})
+	if err != nil {
+		c.Token = ""
+		return err
+	}
+
+	return nil
+}
+
+func (vu *vaultUsecase) GetByToken(ctx context.Context, merchantID int64, token string) (domain.Card, error) {
+	vc, err := vu.cardRepo.GetByToken(ctx, token)
+	if err != nil {
+		return domain.Card{}, err
+	}
+	// Tokens belonging to another merchant are reported as missing.
+	if vc.MerchantID != merchantID {
+		return domain.Card{}, domain.ErrNotFound
+	}
+
+	return vc.Card, nil
+}
+
+func (vu *vaultUsecase) Delete(ctx context.Context, merchantID int64, token string) error {
+	_, err := vu.GetByToken(ctx, merchantID, token)
+	if err != nil {
+		return err
+	}
+
+	return vu.cardRepo.Delete(ctx, token)
+}
+
+type detokenizer struct {
+	cardRepo domain.CardVaultRepository
+	key      []byte
+}
+
+// NewCardDetokenizer returns the only way to get a PAN back out of the vault.
+// It must only be wired into processor adapters.
+func NewCardDetokenizer(cr domain.CardVaultRepository, key []byte) domain.CardDetokenizer {
+	return &detokenizer{
+		cardRepo: cr,
+		key:      key,
+	}
+}
+
+func (d *detokenizer) Detokenize(ctx context.Context, token string) (domain.Card, error) {
+	vc, err := d.cardRepo.GetByToken(ctx, token)
+	if err != nil {
+		return domain.Card{}, err
+	}
+
+	number, err := open(d.key, vc.EncryptedKey, vc.Ciphertext)
+	if err != nil {
+		return domain.Card{}, err
+	}
+
+	c := vc.Card
+	c.Number = string(number)
+	return c, nil
+}
+
+// FingerprintCards gives the cards vaulted before fingerprints were kept
+// theirs, and their transactions too, and returns how many it did. It is
+// run once, by the fingerprint-cards command, rather than on the way out of
+// the vault.
+func FingerprintCards(ctx context.Context, cr domain.CardVaultRepository, key []byte) (int, error) {
+	cards, err := cr.FetchWithoutFingerprint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, vc := range cards {
+		number, err := open(key, vc.EncryptedKey, vc.Ciphertext)
+		if err != nil {
+			return i, err
+		}
+		err = cr.SetFingerprint(ctx, vc.Token, fingerprint(key, string(number)))
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(cards), nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return "tok_" + hex.EncodeToString(b), nil
+}
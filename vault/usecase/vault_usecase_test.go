@@ -0,0 +1,177 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	vaultUsecase "github.com/hezbymuhammad/payment-gateway/vault/usecase"
+)
+
+var key = bytes.Repeat([]byte{7}, 32)
+
+func validCard() domain.Card {
+	return domain.Card{
+		MerchantID:  1,
+		Number:      "4111 1111 1111 1111",
+		ExpiryMonth: 12,
+		ExpiryYear:  time.Now().Year() + 1,
+	}
+}
+
+func TestTokenizeAndDetokenize(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	var stored domain.VaultedCard
+	mockRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = *args.Get(1).(*domain.VaultedCard)
+	}).Return(nil).Once()
+	u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+
+	data := validCard()
+	err := u.Tokenize(context.TODO(), &data)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, "^tok_[0-9a-f]{32}$", data.Token)
+	assert.Empty(t, data.Number)
+	assert.Equal(t, "1111", data.Last4)
+	assert.Equal(t, "411111", data.BIN)
+	assert.Equal(t, "VISA", data.Brand)
+	assert.NotContains(t, string(stored.Ciphertext), "4111111111111111")
+	assert.Empty(t, stored.Number)
+
+	mockRepo.On("GetByToken", mock.Anything, data.Token).Return(stored, nil).Once()
+	d := vaultUsecase.NewCardDetokenizer(mockRepo, key)
+
+	res, err := d.Detokenize(context.TODO(), data.Token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "4111111111111111", res.Number)
+}
+
+func TestDetokenizeWithWrongKey(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	var stored domain.VaultedCard
+	mockRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = *args.Get(1).(*domain.VaultedCard)
+	}).Return(nil).Once()
+	data := validCard()
+	err := vaultUsecase.NewCardVaultUsecase(mockRepo, key).Tokenize(context.TODO(), &data)
+	assert.NoError(t, err)
+
+	mockRepo.On("GetByToken", mock.Anything, data.Token).Return(stored, nil).Once()
+	d := vaultUsecase.NewCardDetokenizer(mockRepo, bytes.Repeat([]byte{8}, 32))
+
+	_, err = d.Detokenize(context.TODO(), data.Token)
+
+	assert.Error(t, err)
+}
+
+func TestTokenizeRejectsInvalidCards(t *testing.T) {
+	cases := []struct {
+		name   string
+		number string
+		month  int
+		year   int
+		err    error
+	}{
+		{"luhn", "4111111111111112", 12, time.Now().Year() + 1, domain.ErrInvalidCard},
+		{"letters", "4111abcd11111111", 12, time.Now().Year() + 1, domain.ErrInvalidCard},
+		{"short", "4111", 12, time.Now().Year() + 1, domain.ErrInvalidCard},
+		{"expired", "4111111111111111", 1, time.Now().Year() - 1, domain.ErrCardExpired},
+		{"month", "4111111111111111", 13, time.Now().Year() + 1, domain.ErrInvalidCard},
+		{"brand", "6011111111111117", 12, time.Now().Year() + 1, domain.ErrUnknownBrand},
+		{"length", "378282246310005", 12, time.Now().Year() + 1, nil},
+		{"mastercard", "5555555555554444", 12, time.Now().Year() + 1, nil},
+	}
+
+	for _, c := range cases {
+		mockRepo := new(mocks.CardVaultRepository)
+		mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+		u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+
+		data := domain.Card{MerchantID: 1, Number: c.number, ExpiryMonth: c.month, ExpiryYear: c.year}
+		err := u.Tokenize(context.TODO(), &data)
+
+		assert.Equal(t, c.err, err, c.name)
+	}
+}
+
+func TestTokenizeSameCardTwice(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	mockRepo.On("Store", mock.Anything, mock.Anything).Return(nil)
+	u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+
+	first, second, other := validCard(), validCard(), validCard()
+	second.Number = "4111111111111111"
+	other.Number = "5555555555554444"
+	for _, data := range []*domain.Card{&first, &second, &other} {
+		assert.NoError(t, u.Tokenize(context.TODO(), data))
+	}
+
+	assert.NotEqual(t, first.Token, second.Token)
+	assert.Regexp(t, "^[0-9a-f]{64}$", first.Fingerprint)
+	assert.Equal(t, first.Fingerprint, second.Fingerprint)
+	assert.NotEqual(t, first.Fingerprint, other.Fingerprint)
+	assert.NotContains(t, first.Fingerprint, "4111111111111111")
+}
+
+func TestFingerprintCards(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	var stored domain.VaultedCard
+	mockRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = *args.Get(1).(*domain.VaultedCard)
+	}).Return(nil).Once()
+	u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+	data := validCard()
+	assert.NoError(t, u.Tokenize(context.TODO(), &data))
+
+	// As vaulted before fingerprints were kept.
+	stored.Fingerprint = ""
+	mockRepo.On("FetchWithoutFingerprint", mock.Anything).Return([]domain.VaultedCard{stored}, nil).Once()
+	mockRepo.On("SetFingerprint", mock.Anything, data.Token, data.Fingerprint).Return(nil).Once()
+
+	n, err := vaultUsecase.FingerprintCards(context.TODO(), mockRepo, key)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCardJSON(t *testing.T) {
+	b, err := json.Marshal(domain.Card{Token: "tok_1", Fingerprint: "fp_1"})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "fp_1")
+}
+
+func TestGetByTokenOtherMerchant(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	stored := domain.VaultedCard{Card: domain.Card{Token: "tok_1", MerchantID: 2, Last4: "1111", Fingerprint: "fp_1"}}
+	mockRepo.On("GetByToken", mock.Anything, "tok_1").Return(stored, nil)
+	u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+
+	_, err := u.GetByToken(context.TODO(), 1, "tok_1")
+	assert.Equal(t, domain.ErrNotFound, err)
+
+	res, err := u.GetByToken(context.TODO(), 2, "tok_1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1111", res.Last4)
+}
+
+func TestDelete(t *testing.T) {
+	mockRepo := new(mocks.CardVaultRepository)
+	stored := domain.VaultedCard{Card: domain.Card{Token: "tok_1", MerchantID: 1, Fingerprint: "fp_1"}}
+	mockRepo.On("GetByToken", mock.Anything, "tok_1").Return(stored, nil).Once()
+	mockRepo.On("Delete", mock.Anything, "tok_1").Return(nil).Once()
+	u := vaultUsecase.NewCardVaultUsecase(mockRepo, key)
+
+	err := u.Delete(context.TODO(), 1, "tok_1")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
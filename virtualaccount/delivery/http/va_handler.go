@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+type VirtualAccountHandler struct {
+	Usecase domain.VirtualAccountUsecase
+}
+
+func NewVirtualAccountHandler(e *echo.Echo, u domain.VirtualAccountUsecase) *VirtualAccountHandler {
+	handler := &VirtualAccountHandler{
+		Usecase: u,
+	}
+
+	e.POST("/virtual_accounts", handler.Store)
+	e.GET("/virtual_accounts/:id", handler.GetByID)
+	e.POST("/virtual_accounts/notifications", handler.Notify)
+
+	return handler
+}
+
+func (h *VirtualAccountHandler) Store(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.VirtualAccount
+	c.Bind(&data)
+	if data.MerchantID == 0 || data.SettingID == 0 || data.CustomerID == 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	if data.Mode == "" {
+		data.Mode = domain.VirtualAccountClosed
+	}
+	if data.Mode != domain.VirtualAccountOpen && (data.Mode != domain.VirtualAccountClosed || data.Amount <= 0) {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	err := h.Usecase.Store(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, data)
+}
+
+func (h *VirtualAccountHandler) GetByID(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+	merchantID, err := strconv.ParseInt(c.QueryParam("merchantId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	ctx := c.Request().Context()
+	res, err := h.Usecase.GetByID(ctx, merchantID, id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Notify simulates the bank telling us a transfer has arrived.
+func (h *VirtualAccountHandler) Notify(c echo.Context) error {
+	ctx := c.Request().Context()
+	var data domain.VANotification
+	c.Bind(&data)
+	if data.Bank == "" || data.Number == "" || data.Reference == "" || data.Amount <= 0 {
+		return c.JSON(http.StatusBadRequest, ResponseError{Message: "Bad request param"})
+	}
+
+	res, err := h.Usecase.Notify(ctx, &data)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func respondError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.JSON(http.StatusNotFound, ResponseError{Message: "Not found"})
+	case domain.ErrPaymentMethod, domain.ErrInvalidCustomer, domain.ErrUnderpaid:
+		return c.JSON(http.StatusUnprocessableEntity, ResponseError{Message: err.Error()})
+	case domain.ErrInvalidState, domain.ErrDuplicatePayment:
+		return c.JSON(http.StatusConflict, ResponseError{Message: err.Error()})
+	case domain.ErrVAExpired:
+		return c.JSON(http.StatusGone, ResponseError{Message: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, ResponseError{Message: "Failed to proceed"})
+	}
+}
@@ -0,0 +1,109 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	vaHttp "github.com/hezbymuhammad/payment-gateway/virtualaccount/delivery/http"
+)
+
+func TestStore(t *testing.T) {
+	mockUsecase := new(mocks.VirtualAccountUsecase)
+	mockUsecase.On("Store", mock.Anything, mock.MatchedBy(func(va *domain.VirtualAccount) bool {
+		return va.Mode == domain.VirtualAccountClosed
+	})).Return(nil).Once()
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/virtual_accounts", strings.NewReader(`{"merchantId":6,"settingId":6,"customerId":1,"amount":1000}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := vaHttp.NewVirtualAccountHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestStoreClosedWithoutAmount(t *testing.T) {
+	mockUsecase := new(mocks.VirtualAccountUsecase)
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/virtual_accounts", strings.NewReader(`{"merchantId":6,"settingId":6,"customerId":1,"mode":"closed"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := vaHttp.NewVirtualAccountHandler(echo.New(), mockUsecase)
+	err = handler.Store(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockUsecase.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestNotify(t *testing.T) {
+	mockUsecase := new(mocks.VirtualAccountUsecase)
+	mockUsecase.On("Notify", mock.Anything, mock.Anything).Return(domain.VirtualAccountPayment{ID: 1, TransactionID: 4, Overpaid: 5000}, nil).Once()
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/virtual_accounts/notifications", strings.NewReader(`{"bank":"BCA","number":"7001200000000001","amount":55000,"reference":"b1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := vaHttp.NewVirtualAccountHandler(echo.New(), mockUsecase)
+	err = handler.Notify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"overpaid":5000`)
+}
+
+func TestNotifyExpired(t *testing.T) {
+	mockUsecase := new(mocks.VirtualAccountUsecase)
+	mockUsecase.On("Notify", mock.Anything, mock.Anything).Return(domain.VirtualAccountPayment{}, domain.ErrVAExpired).Once()
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/virtual_accounts/notifications", strings.NewReader(`{"bank":"BCA","number":"7001200000000001","amount":50000,"reference":"b1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := vaHttp.NewVirtualAccountHandler(echo.New(), mockUsecase)
+	err = handler.Notify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusGone, rec.Code)
+}
+
+func TestNotifyUnderpaid(t *testing.T) {
+	mockUsecase := new(mocks.VirtualAccountUsecase)
+	mockUsecase.On("Notify", mock.Anything, mock.Anything).Return(domain.VirtualAccountPayment{}, domain.ErrUnderpaid).Once()
+	e := echo.New()
+	req, err := http.NewRequest(echo.POST, "/virtual_accounts/notifications", strings.NewReader(`{"bank":"BCA","number":"7001200000000001","amount":40000,"reference":"b1"}`))
+	assert.NoError(t, err)
+
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	handler := vaHttp.NewVirtualAccountHandler(echo.New(), mockUsecase)
+	err = handler.Notify(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
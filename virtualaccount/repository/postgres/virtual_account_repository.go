@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const virtualAccountColumns = "id, merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing"
+
+type postgresVirtualAccountRepo struct {
+	DB *sql.DB
+}
+
+func NewVirtualAccountRepository(db *sql.DB) domain.VirtualAccountRepository {
+	return &postgresVirtualAccountRepo{
+		DB: db,
+	}
+}
+
+func (vr *postgresVirtualAccountRepo) Store(ctx context.Context, va *domain.VirtualAccount) error {
+	query := "INSERT INTO virtual_accounts (merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id"
+
+	err := vr.DB.QueryRowContext(
+		ctx,
+		query,
+		va.MerchantID,
+		va.SettingID,
+		va.CustomerID,
+		va.TransactionID,
+		va.Bank,
+		nullString(va.Number),
+		va.Mode,
+		va.Amount,
+		va.Status,
+		va.ExpiresAt,
+		va.Standing,
+	).Scan(&va.ID)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (vr *postgresVirtualAccountRepo) GetByID(ctx context.Context, id int64) (domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE id=$1"
+
+	return vr.get(ctx, query, id)
+}
+
+func (vr *postgresVirtualAccountRepo) GetByNumber(ctx context.Context, bank string, number string) (domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE bank=$1 AND number=$2"
+
+	return vr.get(ctx, query, bank, number)
+}
+
+func (vr *postgresVirtualAccountRepo) Update(ctx context.Context, va *domain.VirtualAccount) error {
+	query := "UPDATE virtual_accounts SET number=$1, status=$2, transaction_id=$3 WHERE id=$4"
+
+	_, err := vr.exec(ctx, query, nullString(va.Number), va.Status, va.TransactionID, va.ID)
+	return err
+}
+
+// Link ties an active account that is not waiting on a transaction to the
+// transaction, and reports whether this caller won.
+func (vr *postgresVirtualAccountRepo) Link(ctx context.Context, id int64, transactionID int64) (bool, error) {
+	query := "UPDATE virtual_accounts SET transaction_id=$1 WHERE id=$2 AND transaction_id=0 AND status='active'"
+
+	res, err := vr.exec(ctx, query, transactionID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Unlink frees an account that is still tied to the transaction. An account
+// since tied to another transaction is left alone.
+func (vr *postgresVirtualAccountRepo) Unlink(ctx context.Context, id int64, transactionID int64) error {
+	query := "UPDATE virtual_accounts SET transaction_id=0 WHERE id=$1 AND transaction_id=$2"
+
+	_, err := vr.exec(ctx, query, id, transactionID)
+	return err
+}
+
+// FetchExpired lists the active accounts whose expiry has passed. Accounts
+// that do not expire have a zero expiry and are never listed.
+func (vr *postgresVirtualAccountRepo) FetchExpired(ctx context.Context, now time.Time) ([]domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE status='active' AND expires_at > $1 AND expires_at <= $2 ORDER BY id"
+
+	rows, err := vr.DB.QueryContext(ctx, query, time.Time{}, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.VirtualAccount{}
+	for rows.Next() {
+		data, err := scanVirtualAccount(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// ClaimPayment records a transfer unless the bank already reported the same
+// reference for the account, and reports whether this caller won.
+func (vr *postgresVirtualAccountRepo) ClaimPayment(ctx context.Context, p *domain.VirtualAccountPayment) (bool, error) {
+	query := "INSERT INTO virtual_account_payments (virtual_account_id, transaction_id, reference, amount, overpaid, paid_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (virtual_account_id, reference) DO NOTHING RETURNING id"
+
+	err := vr.DB.QueryRowContext(ctx, query, p.VirtualAccountID, p.TransactionID, p.Reference, p.Amount, p.Overpaid, p.PaidAt).Scan(&p.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (vr *postgresVirtualAccountRepo) UpdatePayment(ctx context.Context, p *domain.VirtualAccountPayment) error {
+	query := "UPDATE virtual_account_payments SET transaction_id=$1 WHERE id=$2"
+
+	_, err := vr.exec(ctx, query, p.TransactionID, p.ID)
+	return err
+}
+
+// ReleasePayment drops a claimed transfer that could not be applied so that
+// the bank can retry it.
+func (vr *postgresVirtualAccountRepo) ReleasePayment(ctx context.Context, id int64) error {
+	query := "DELETE FROM virtual_account_payments WHERE id=$1"
+
+	_, err := vr.exec(ctx, query, id)
+	return err
+}
+
+func (vr *postgresVirtualAccountRepo) get(ctx context.Context, query string, args ...interface{}) (domain.VirtualAccount, error) {
+	data, err := scanVirtualAccount(vr.DB.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return domain.VirtualAccount{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.VirtualAccount{}, err
+	}
+
+	return data, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVirtualAccount(row scanner) (domain.VirtualAccount, error) {
+	data := domain.VirtualAccount{}
+	var number sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.CustomerID,
+		&data.TransactionID,
+		&data.Bank,
+		&number,
+		&data.Mode,
+		&data.Amount,
+		&data.Status,
+		&data.ExpiresAt,
+		&data.Standing,
+	)
+	if err != nil {
+		return domain.VirtualAccount{}, err
+	}
+
+	data.Number = number.String
+	data.ExpiresAt = data.ExpiresAt.UTC()
+	return data, nil
+}
+
+func (vr *postgresVirtualAccountRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := vr.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// nullString stores an account that has not been numbered yet as NULL so it
+// stays out of the unique index on bank and number.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,16 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/hezbymuhammad/payment-gateway/conformance"
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	vaRepo "github.com/hezbymuhammad/payment-gateway/virtualaccount/repository/sqlite"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.VirtualAccountRepository(t, func(t *testing.T) domain.VirtualAccountRepository {
+		return vaRepo.NewVirtualAccountRepository(migrationtest.NewDB(t))
+	})
+}
@@ -0,0 +1,241 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+const virtualAccountColumns = "id, merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing"
+
+type sqliteVirtualAccountRepo struct {
+	DB *sql.DB
+}
+
+func NewVirtualAccountRepository(db *sql.DB) domain.VirtualAccountRepository {
+	return &sqliteVirtualAccountRepo{
+		DB: db,
+	}
+}
+
+func (vr *sqliteVirtualAccountRepo) Store(ctx context.Context, va *domain.VirtualAccount) error {
+	query := "INSERT INTO virtual_accounts (merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	res, err := vr.exec(
+		ctx,
+		query,
+		va.MerchantID,
+		va.SettingID,
+		va.CustomerID,
+		va.TransactionID,
+		va.Bank,
+		nullString(va.Number),
+		va.Mode,
+		va.Amount,
+		va.Status,
+		va.ExpiresAt,
+		va.Standing,
+	)
+	if err != nil {
+		return err
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return err
+	}
+
+	va.ID = lastID
+	return nil
+}
+
+func (vr *sqliteVirtualAccountRepo) GetByID(ctx context.Context, id int64) (domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE id=? LIMIT 1"
+
+	return vr.get(ctx, query, id)
+}
+
+func (vr *sqliteVirtualAccountRepo) GetByNumber(ctx context.Context, bank string, number string) (domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE bank=? AND number=? LIMIT 1"
+
+	return vr.get(ctx, query, bank, number)
+}
+
+func (vr *sqliteVirtualAccountRepo) Update(ctx context.Context, va *domain.VirtualAccount) error {
+	query := "UPDATE virtual_accounts SET number=?, status=?, transaction_id=? WHERE id=?"
+
+	_, err := vr.exec(ctx, query, nullString(va.Number), va.Status, va.TransactionID, va.ID)
+	return err
+}
+
+// Link ties an active account that is not waiting on a transaction to the
+// transaction, and reports whether this caller won.
+func (vr *sqliteVirtualAccountRepo) Link(ctx context.Context, id int64, transactionID int64) (bool, error) {
+	query := "UPDATE virtual_accounts SET transaction_id=? WHERE id=? AND transaction_id=0 AND status='active'"
+
+	res, err := vr.exec(ctx, query, transactionID, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Unlink frees an account that is still tied to the transaction. An account
+// since tied to another transaction is left alone.
+func (vr *sqliteVirtualAccountRepo) Unlink(ctx context.Context, id int64, transactionID int64) error {
+	query := "UPDATE virtual_accounts SET transaction_id=0 WHERE id=? AND transaction_id=?"
+
+	_, err := vr.exec(ctx, query, id, transactionID)
+	return err
+}
+
+// FetchExpired lists the active accounts whose expiry has passed. Accounts
+// that do not expire have a zero expiry and are never listed.
+func (vr *sqliteVirtualAccountRepo) FetchExpired(ctx context.Context, now time.Time) ([]domain.VirtualAccount, error) {
+	query := "SELECT " + virtualAccountColumns + " FROM virtual_accounts WHERE status='active' AND expires_at > ? AND expires_at <= ? ORDER BY id"
+
+	rows, err := vr.DB.QueryContext(ctx, query, time.Time{}, now)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.VirtualAccount{}
+	for rows.Next() {
+		data, err := scanVirtualAccount(rows)
+		if err != nil {
+			log.Println(query)
+			log.Println(err)
+			return nil, err
+		}
+		result = append(result, data)
+	}
+
+	return result, rows.Err()
+}
+
+// ClaimPayment records a transfer unless the bank already reported the same
+// reference for the account, and reports whether this caller won.
+func (vr *sqliteVirtualAccountRepo) ClaimPayment(ctx context.Context, p *domain.VirtualAccountPayment) (bool, error) {
+	query := "INSERT OR IGNORE INTO virtual_account_payments (virtual_account_id, transaction_id, reference, amount, overpaid, paid_at) VALUES (?, ?, ?, ?, ?, ?)"
+
+	res, err := vr.exec(ctx, query, p.VirtualAccountID, p.TransactionID, p.Reference, p.Amount, p.Overpaid, p.PaidAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return false, err
+	}
+
+	p.ID = lastID
+	return true, nil
+}
+
+func (vr *sqliteVirtualAccountRepo) UpdatePayment(ctx context.Context, p *domain.VirtualAccountPayment) error {
+	query := "UPDATE virtual_account_payments SET transaction_id=? WHERE id=?"
+
+	_, err := vr.exec(ctx, query, p.TransactionID, p.ID)
+	return err
+}
+
+// ReleasePayment drops a claimed transfer that could not be applied so that
+// the bank can retry it.
+func (vr *sqliteVirtualAccountRepo) ReleasePayment(ctx context.Context, id int64) error {
+	query := "DELETE FROM virtual_account_payments WHERE id=?"
+
+	_, err := vr.exec(ctx, query, id)
+	return err
+}
+
+func (vr *sqliteVirtualAccountRepo) get(ctx context.Context, query string, args ...interface{}) (domain.VirtualAccount, error) {
+	data, err := scanVirtualAccount(vr.DB.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return domain.VirtualAccount{}, domain.ErrNotFound
+	}
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return domain.VirtualAccount{}, err
+	}
+
+	return data, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVirtualAccount(row scanner) (domain.VirtualAccount, error) {
+	data := domain.VirtualAccount{}
+	var number sql.NullString
+	err := row.Scan(
+		&data.ID,
+		&data.MerchantID,
+		&data.SettingID,
+		&data.CustomerID,
+		&data.TransactionID,
+		&data.Bank,
+		&number,
+		&data.Mode,
+		&data.Amount,
+		&data.Status,
+		&data.ExpiresAt,
+		&data.Standing,
+	)
+	data.Number = number.String
+
+	return data, err
+}
+
+func (vr *sqliteVirtualAccountRepo) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := vr.DB.PrepareContext(ctx, query)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		log.Println(query)
+		log.Println(err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// nullString stores an account that has not been numbered yet as NULL so it
+// stays out of the unique index on bank and number.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
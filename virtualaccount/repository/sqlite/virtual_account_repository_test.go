@@ -0,0 +1,157 @@
+package sqlite_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	vaRepo "github.com/hezbymuhammad/payment-gateway/virtualaccount/repository/sqlite"
+)
+
+var now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+var virtualAccountColumns = []string{"id", "merchant_id", "setting_id", "customer_id", "transaction_id", "bank", "number", "mode", "amount", "status", "expires_at", "standing"}
+
+func TestStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.VirtualAccount{MerchantID: 6, SettingID: 6, TransactionID: 4, Bank: "BCA", Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive, ExpiresAt: now}
+	query := regexp.QuoteMeta("INSERT INTO virtual_accounts (merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(6, 6, 0, 4, "BCA", nil, domain.VirtualAccountClosed, 50000, domain.VirtualAccountActive, now, false).WillReturnResult(sqlmock.NewResult(3, 1))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	err = vr.Store(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), data.ID)
+}
+
+func TestGetByNumber(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(virtualAccountColumns).
+		AddRow(3, 6, 6, 1, 0, "BCA", "7001200000000003", domain.VirtualAccountOpen, 0, domain.VirtualAccountActive, time.Time{}, true)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing FROM virtual_accounts WHERE bank=? AND number=? LIMIT 1")
+	mock.ExpectQuery(query).WithArgs("BCA", "7001200000000003").WillReturnRows(rows)
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	res, err := vr.GetByNumber(context.TODO(), "BCA", "7001200000000003")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.ID)
+	assert.Equal(t, domain.VirtualAccountOpen, res.Mode)
+	assert.True(t, res.Standing)
+}
+
+func TestGetByNumberNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(virtualAccountColumns))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	_, err = vr.GetByNumber(context.TODO(), "BCA", "7001200000000099")
+	assert.Equal(t, domain.ErrNotFound, err)
+}
+
+func TestLink(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE virtual_accounts SET transaction_id=? WHERE id=? AND transaction_id=0 AND status='active'")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(4, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep = mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(5, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	linked, err := vr.Link(context.TODO(), 3, 4)
+	assert.NoError(t, err)
+	assert.True(t, linked)
+
+	linked, err = vr.Link(context.TODO(), 3, 5)
+	assert.NoError(t, err)
+	assert.False(t, linked)
+}
+
+func TestUnlink(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := regexp.QuoteMeta("UPDATE virtual_accounts SET transaction_id=0 WHERE id=? AND transaction_id=?")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(3, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	err = vr.Unlink(context.TODO(), 3, 4)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFetchExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows(virtualAccountColumns).
+		AddRow(1, 6, 6, 0, 4, "BCA", "7001200000000001", domain.VirtualAccountClosed, 50000, domain.VirtualAccountActive, now.Add(-time.Hour), false)
+	query := regexp.QuoteMeta("SELECT id, merchant_id, setting_id, customer_id, transaction_id, bank, number, mode, amount, status, expires_at, standing FROM virtual_accounts WHERE status='active' AND expires_at > ? AND expires_at <= ? ORDER BY id")
+	mock.ExpectQuery(query).WithArgs(time.Time{}, now).WillReturnRows(rows)
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	res, err := vr.FetchExpired(context.TODO(), now)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, int64(4), res[0].TransactionID)
+}
+
+func TestClaimPayment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.VirtualAccountPayment{VirtualAccountID: 3, Reference: "b1", Amount: 55000, Overpaid: 5000, PaidAt: now}
+	query := regexp.QuoteMeta("INSERT OR IGNORE INTO virtual_account_payments (virtual_account_id, transaction_id, reference, amount, overpaid, paid_at) VALUES (?, ?, ?, ?, ?, ?)")
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(3, 0, "b1", 55000, 5000, now).WillReturnResult(sqlmock.NewResult(8, 1))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	claimed, err := vr.ClaimPayment(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, int64(8), data.ID)
+}
+
+func TestClaimPaymentAlreadyClaimed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	data := &domain.VirtualAccountPayment{VirtualAccountID: 3, Reference: "b1", Amount: 55000, PaidAt: now}
+	prep := mock.ExpectPrepare(regexp.QuoteMeta("INSERT OR IGNORE INTO virtual_account_payments"))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	vr := vaRepo.NewVirtualAccountRepository(db)
+
+	claimed, err := vr.ClaimPayment(context.TODO(), data)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+// PaymentType is the setting and transaction payment type paid by bank
+// transfer. A VA setting names its bank in PaymentName.
+const PaymentType = "VA"
+
+// numberLength is how many digits a virtual account number has, bank prefix
+// included.
+const numberLength = 16
+
+// Config holds the virtual account settings that come from config.json. Banks
+// maps a bank code to the prefix its account numbers start with, and TTL is
+// how long an account issued for a transaction can be paid.
+type Config struct {
+	Banks map[string]string
+	TTL   time.Duration
+}
+
+type vaChannel struct {
+	vaRepo domain.VirtualAccountRepository
+	cfg    Config
+}
+
+// NewVAChannel returns the payment channel that answers VA transactions with
+// a closed-amount virtual account. A transaction that already carries the
+// number of one of the customer's accounts is paid into that account instead.
+func NewVAChannel(vr domain.VirtualAccountRepository, cfg Config) domain.PaymentChannel {
+	return &vaChannel{
+		vaRepo: vr,
+		cfg:    cfg,
+	}
+}
+
+func (c *vaChannel) PaymentType() string {
+	return PaymentType
+}
+
+// Initiate only takes rupiah transactions, since virtual accounts are paid
+// by domestic transfer.
+func (c *vaChannel) Initiate(ctx context.Context, t *domain.Transaction, s domain.Setting) error {
+	if t.Currency != "IDR" {
+		return domain.ErrPaymentMethod
+	}
+	if t.PaymentCode != "" {
+		va, err := c.vaRepo.GetByNumber(ctx, s.PaymentName, t.PaymentCode)
+		if err == domain.ErrNotFound {
+			return domain.ErrPaymentMethod
+		}
+		if err != nil {
+			return err
+		}
+		if va.MerchantID != t.MerchantID || !va.Standing || va.TransactionID != 0 || va.Status != domain.VirtualAccountActive {
+			return domain.ErrPaymentMethod
+		}
+
+		// The next transfer to the account pays this transaction rather
+		// than opening a new one.
+		linked, err := c.vaRepo.Link(ctx, va.ID, t.ID)
+		if err != nil {
+			return err
+		}
+		if !linked {
+			return domain.ErrPaymentMethod
+		}
+
+		t.Processor = "va"
+		return nil
+	}
+
+	va := domain.VirtualAccount{
+		MerchantID:    t.MerchantID,
+		SettingID:     s.ID,
+		CustomerID:    t.CustomerID,
+		TransactionID: t.ID,
+		Bank:          s.PaymentName,
+		Mode:          domain.VirtualAccountClosed,
+		Amount:        t.Amount,
+		Status:        domain.VirtualAccountActive,
+		ExpiresAt:     time.Now().UTC().Add(c.cfg.TTL).Truncate(time.Second),
+	}
+	err := issue(ctx, c.vaRepo, c.cfg, &va)
+	if err != nil {
+		return err
+	}
+
+	t.PaymentCode = va.Number
+	t.Processor = "va"
+	return nil
+}
+
+// issue stores a virtual account and numbers it after its row id, so numbers
+// never collide within a bank.
+func issue(ctx context.Context, vr domain.VirtualAccountRepository, cfg Config, va *domain.VirtualAccount) error {
+	prefix, ok := cfg.Banks[va.Bank]
+	if !ok {
+		return domain.ErrPaymentMethod
+	}
+
+	err := vr.Store(ctx, va)
+	if err != nil {
+		return err
+	}
+
+	va.Number = fmt.Sprintf("%s%0*d", prefix, numberLength-len(prefix), va.ID)
+	return vr.Update(ctx, va)
+}
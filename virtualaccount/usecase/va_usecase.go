@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+)
+
+type vaUsecase struct {
+	vaRepo       domain.VirtualAccountRepository
+	merchantRepo domain.MerchantRepository
+	customers    domain.CustomerUsecase
+	transactions domain.TransactionUsecase
+	cfg          Config
+}
+
+func NewVirtualAccountUsecase(vr domain.VirtualAccountRepository, mr domain.MerchantRepository, cu domain.CustomerUsecase, tu domain.TransactionUsecase, cfg Config) domain.VirtualAccountUsecase {
+	return &vaUsecase{
+		vaRepo:       vr,
+		merchantRepo: mr,
+		customers:    cu,
+		transactions: tu,
+		cfg:          cfg,
+	}
+}
+
+// Store issues a standing virtual account for one of the merchant's
+// customers. Open-amount accounts take whatever the customer sends.
+func (vu *vaUsecase) Store(ctx context.Context, va *domain.VirtualAccount) error {
+	s, err := vu.merchantRepo.GetSetting(ctx, va.SettingID)
+	if err == domain.ErrNotFound {
+		return domain.ErrPaymentMethod
+	}
+	if err != nil {
+		return err
+	}
+	if s.MerchantID != va.MerchantID || s.PaymentType != PaymentType {
+		return domain.ErrPaymentMethod
+	}
+
+	_, err = vu.customers.GetByID(ctx, va.MerchantID, va.CustomerID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidCustomer
+	}
+	if err != nil {
+		return err
+	}
+
+	if va.Mode == domain.VirtualAccountOpen {
+		va.Amount = 0
+	}
+	va.Bank = s.PaymentName
+	va.TransactionID = 0
+	va.Status = domain.VirtualAccountActive
+	va.Standing = true
+	va.ExpiresAt = va.ExpiresAt.UTC().Truncate(time.Second)
+
+	return issue(ctx, vu.vaRepo, vu.cfg, va)
+}
+
+func (vu *vaUsecase) GetByID(ctx context.Context, merchantID int64, id int64) (domain.VirtualAccount, error) {
+	va, err := vu.vaRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.VirtualAccount{}, err
+	}
+	if va.MerchantID != merchantID {
+		return domain.VirtualAccount{}, domain.ErrNotFound
+	}
+
+	return va, nil
+}
+
+// Notify applies a transfer the bank reported. Underpaying a closed-amount
+// account is rejected so the bank returns the money; overpaying it settles
+// the amount due and records the excess on the payment to be refunded. The
+// same bank reference is only ever applied once.
+func (vu *vaUsecase) Notify(ctx context.Context, n *domain.VANotification) (domain.VirtualAccountPayment, error) {
+	va, err := vu.vaRepo.GetByNumber(ctx, n.Bank, n.Number)
+	if err != nil {
+		return domain.VirtualAccountPayment{}, err
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case va.Status == domain.VirtualAccountExpired:
+		return domain.VirtualAccountPayment{}, domain.ErrVAExpired
+	case va.Status != domain.VirtualAccountActive:
+		return domain.VirtualAccountPayment{}, domain.ErrInvalidState
+	case !va.ExpiresAt.IsZero() && !now.Before(va.ExpiresAt):
+		err = vu.expire(ctx, &va)
+		if err != nil {
+			return domain.VirtualAccountPayment{}, err
+		}
+		return domain.VirtualAccountPayment{}, domain.ErrVAExpired
+	}
+
+	due := va.Amount
+	if va.Mode == domain.VirtualAccountOpen {
+		due = n.Amount
+	}
+	if va.Standing && va.TransactionID != 0 {
+		t, err := vu.transactions.GetByID(ctx, va.TransactionID)
+		if err != nil {
+			return domain.VirtualAccountPayment{}, err
+		}
+		if t.State == domain.TransactionPending {
+			due = t.Amount
+		} else {
+			// The linked transaction ended some other way, so this
+			// transfer gets one of its own.
+			err = vu.vaRepo.Unlink(ctx, va.ID, va.TransactionID)
+			if err != nil {
+				return domain.VirtualAccountPayment{}, err
+			}
+			va.TransactionID = 0
+		}
+	}
+	if n.Amount < due {
+		return domain.VirtualAccountPayment{}, domain.ErrUnderpaid
+	}
+
+	p := domain.VirtualAccountPayment{
+		VirtualAccountID: va.ID,
+		Reference:        n.Reference,
+		Amount:           n.Amount,
+		Overpaid:         n.Amount - due,
+		PaidAt:           now.Truncate(time.Second),
+	}
+	claimed, err := vu.vaRepo.ClaimPayment(ctx, &p)
+	if err != nil {
+		return domain.VirtualAccountPayment{}, err
+	}
+	if !claimed {
+		return domain.VirtualAccountPayment{}, domain.ErrDuplicatePayment
+	}
+
+	p.TransactionID, err = vu.settle(ctx, va, due, n.Reference)
+	if err != nil {
+		vu.release(ctx, p.ID)
+		return domain.VirtualAccountPayment{}, err
+	}
+
+	err = vu.vaRepo.UpdatePayment(ctx, &p)
+	if err != nil {
+		return domain.VirtualAccountPayment{}, err
+	}
+	if va.Standing {
+		// Whether the transfer paid the transaction the account was
+		// waiting on or one of its own, which the channel linked when
+		// it was stored, the account is free for the next one.
+		err = vu.vaRepo.Unlink(ctx, va.ID, p.TransactionID)
+	} else {
+		va.Status = domain.VirtualAccountPaid
+		err = vu.vaRepo.Update(ctx, &va)
+	}
+	if err != nil {
+		return domain.VirtualAccountPayment{}, err
+	}
+
+	return p, nil
+}
+
+// RunExpiry closes every active account past its expiry, failing the
+// transactions they were waiting on. Accounts that fail are logged and
+// retried on the next run.
+func (vu *vaUsecase) RunExpiry(ctx context.Context, now time.Time) error {
+	vas, err := vu.vaRepo.FetchExpired(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for i := range vas {
+		err = vu.expire(ctx, &vas[i])
+		if err != nil {
+			log.Printf("virtual account %d: %v", vas[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// settle completes the transaction a transfer pays for. A standing account
+// that is not waiting on a transaction gets a new one per transfer.
+func (vu *vaUsecase) settle(ctx context.Context, va domain.VirtualAccount, amount int64, reference string) (int64, error) {
+	id := va.TransactionID
+	if id == 0 {
+		t := domain.Transaction{
+			MerchantID:       va.MerchantID,
+			ParentMerchantID: va.MerchantID,
+			SettingID:        va.SettingID,
+			CustomerID:       va.CustomerID,
+			Amount:           amount,
+			Currency:         "IDR",
+			PaymentType:      PaymentType,
+			PaymentCode:      va.Number,
+		}
+		err := vu.transactions.Store(ctx, &t)
+		if err != nil {
+			return 0, err
+		}
+		id = t.ID
+	}
+
+	t, err := vu.transactions.Complete(ctx, id, amount, reference)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.ID, nil
+}
+
+// expire closes an account that is past its expiry. The transaction it was
+// waiting on can no longer be paid and is marked failed.
+func (vu *vaUsecase) expire(ctx context.Context, va *domain.VirtualAccount) error {
+	va.Status = domain.VirtualAccountExpired
+	err := vu.vaRepo.Update(ctx, va)
+	if err != nil {
+		return err
+	}
+
+	if va.TransactionID != 0 {
+		_, err = vu.transactions.Fail(ctx, va.TransactionID, domain.ErrVAExpired.Error())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// release drops a transfer that could not be applied so the bank can retry.
+func (vu *vaUsecase) release(ctx context.Context, id int64) {
+	err := vu.vaRepo.ReleasePayment(ctx, id)
+	if err != nil {
+		log.Printf("virtual account payment %d: %v", id, err)
+	}
+}
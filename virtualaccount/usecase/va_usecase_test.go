@@ -0,0 +1,301 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/hezbymuhammad/payment-gateway/domain"
+	"github.com/hezbymuhammad/payment-gateway/domain/mocks"
+	"github.com/hezbymuhammad/payment-gateway/migration/migrationtest"
+	transactionRepo "github.com/hezbymuhammad/payment-gateway/transaction/repository/sqlite"
+	transactionUsecase "github.com/hezbymuhammad/payment-gateway/transaction/usecase"
+	vaRepo "github.com/hezbymuhammad/payment-gateway/virtualaccount/repository/sqlite"
+	vaUsecase "github.com/hezbymuhammad/payment-gateway/virtualaccount/usecase"
+)
+
+var cfg = vaUsecase.Config{
+	Banks: map[string]string{"BCA": "70012"},
+	TTL:   24 * time.Hour,
+}
+
+var setting = domain.Setting{ID: 6, MerchantID: 6, PaymentType: "VA", PaymentName: "BCA"}
+
+func numbered(id int64) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		args.Get(1).(*domain.VirtualAccount).ID = id
+	}
+}
+
+func TestInitiate(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockRepo.On("Store", mock.Anything, mock.MatchedBy(func(va *domain.VirtualAccount) bool {
+		return va.TransactionID == 4 && va.Amount == 50000 && va.Mode == domain.VirtualAccountClosed && !va.ExpiresAt.IsZero()
+	})).Run(numbered(1)).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 6, Amount: 50000, Currency: "IDR"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.NoError(t, err)
+	assert.Equal(t, "7001200000000001", data.PaymentCode)
+}
+
+func TestInitiateUnknownBank(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 7, Amount: 50000, Currency: "IDR"}
+
+	err := c.Initiate(context.TODO(), &data, domain.Setting{ID: 7, MerchantID: 6, PaymentType: "VA", PaymentName: "XYZ"})
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestInitiateForeignCurrency(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 6, Amount: 5000, Currency: "USD"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestInitiateWithAnotherMerchantsAccount(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(domain.VirtualAccount{ID: 2, MerchantID: 1, Status: domain.VirtualAccountActive, Standing: true}, nil).Once()
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 6, Amount: 50000, Currency: "IDR", PaymentCode: "7001200000000002"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+}
+
+func TestInitiateWithCustomerAccount(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(domain.VirtualAccount{ID: 2, MerchantID: 6, Status: domain.VirtualAccountActive, Standing: true}, nil).Once()
+	mockRepo.On("Link", mock.Anything, int64(2), int64(4)).Return(true, nil).Once()
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 6, Amount: 50000, Currency: "IDR", PaymentCode: "7001200000000002"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.NoError(t, err)
+	assert.Equal(t, "va", data.Processor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInitiateWithLinkedCustomerAccount(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(domain.VirtualAccount{ID: 2, MerchantID: 6, Status: domain.VirtualAccountActive, Standing: true}, nil).Once()
+	mockRepo.On("Link", mock.Anything, int64(2), int64(4)).Return(false, nil).Once()
+	c := vaUsecase.NewVAChannel(mockRepo, cfg)
+	data := domain.Transaction{ID: 4, MerchantID: 6, SettingID: 6, Amount: 50000, Currency: "IDR", PaymentCode: "7001200000000002"}
+
+	err := c.Initiate(context.TODO(), &data, setting)
+	assert.Equal(t, domain.ErrPaymentMethod, err)
+}
+
+func TestStoreForCustomer(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(6)).Return(setting, nil).Once()
+	mockCustomers.On("GetByID", mock.Anything, int64(6), int64(1)).Return(domain.Customer{ID: 1, MerchantID: 6}, nil).Once()
+	mockRepo.On("Store", mock.Anything, mock.Anything).Run(numbered(2)).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, mockMerchantRepo, mockCustomers, new(mocks.TransactionUsecase), cfg)
+	data := domain.VirtualAccount{MerchantID: 6, SettingID: 6, CustomerID: 1, Mode: domain.VirtualAccountOpen, Amount: 999}
+
+	err := u.Store(context.TODO(), &data)
+	assert.NoError(t, err)
+	assert.Equal(t, "7001200000000002", data.Number)
+	assert.Equal(t, "BCA", data.Bank)
+	assert.Equal(t, int64(0), data.Amount)
+	assert.Equal(t, domain.VirtualAccountActive, data.Status)
+	assert.True(t, data.Standing)
+}
+
+func TestStoreForUnknownCustomer(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(6)).Return(setting, nil).Once()
+	mockCustomers.On("GetByID", mock.Anything, int64(6), int64(9)).Return(domain.Customer{}, domain.ErrNotFound).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, mockMerchantRepo, mockCustomers, new(mocks.TransactionUsecase), cfg)
+	data := domain.VirtualAccount{MerchantID: 6, SettingID: 6, CustomerID: 9, Mode: domain.VirtualAccountClosed, Amount: 1000}
+
+	err := u.Store(context.TODO(), &data)
+	assert.Equal(t, domain.ErrInvalidCustomer, err)
+	mockRepo.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+}
+
+func TestNotifyOverpaid(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	va := domain.VirtualAccount{ID: 1, MerchantID: 6, TransactionID: 4, Bank: "BCA", Number: "7001200000000001", Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000001").Return(va, nil).Once()
+	mockRepo.On("ClaimPayment", mock.Anything, mock.MatchedBy(func(p *domain.VirtualAccountPayment) bool {
+		return p.Amount == 55000 && p.Overpaid == 5000
+	})).Return(true, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(4), int64(50000), "b1").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockRepo.On("UpdatePayment", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(va *domain.VirtualAccount) bool {
+		return va.Status == domain.VirtualAccountPaid
+	})).Return(nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	res, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000001", Amount: 55000, Reference: "b1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), res.TransactionID)
+	assert.Equal(t, int64(5000), res.Overpaid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNotifyUnderpaid(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	va := domain.VirtualAccount{ID: 1, MerchantID: 6, TransactionID: 4, Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000001").Return(va, nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), new(mocks.TransactionUsecase), cfg)
+
+	_, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000001", Amount: 40000, Reference: "b1"})
+	assert.Equal(t, domain.ErrUnderpaid, err)
+	mockRepo.AssertNotCalled(t, "ClaimPayment", mock.Anything, mock.Anything)
+}
+
+func TestNotifyOpenAmount(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	va := domain.VirtualAccount{ID: 2, MerchantID: 6, SettingID: 6, CustomerID: 1, Number: "7001200000000002", Mode: domain.VirtualAccountOpen, Status: domain.VirtualAccountActive, Standing: true}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(va, nil).Once()
+	mockRepo.On("ClaimPayment", mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockTransactions.On("Store", mock.Anything, mock.MatchedBy(func(t *domain.Transaction) bool {
+		return t.Amount == 12345 && t.CustomerID == 1 && t.PaymentCode == "7001200000000002"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Transaction).ID = 7
+	}).Return(nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(7), int64(12345), "c1").Return(domain.Transaction{ID: 7}, nil).Once()
+	mockRepo.On("UpdatePayment", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("Unlink", mock.Anything, int64(2), int64(7)).Return(nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	res, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000002", Amount: 12345, Reference: "c1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), res.TransactionID)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestNotifyStandingAccountTwice(t *testing.T) {
+	db := migrationtest.NewDB(t)
+	vr := vaRepo.NewVirtualAccountRepository(db)
+	tr := transactionRepo.NewTransactionRepository(db)
+	mockMerchantRepo := new(mocks.MerchantRepository)
+	mockCustomers := new(mocks.CustomerUsecase)
+	mockMerchantRepo.On("GetSetting", mock.Anything, int64(6)).Return(setting, nil)
+	mockMerchantRepo.On("GetByID", mock.Anything, int64(6)).Return(domain.Merchant{ID: 6, Status: domain.MerchantApproved}, nil)
+	mockCustomers.On("GetByID", mock.Anything, int64(6), int64(1)).Return(domain.Customer{ID: 1, MerchantID: 6}, nil).Once()
+	tu := transactionUsecase.NewTransactionUsecase(mockMerchantRepo, tr, mockCustomers, new(mocks.CardVaultUsecase), new(mocks.PaymentProcessor), transactionUsecase.WithChannel(vaUsecase.NewVAChannel(vr, cfg)))
+	u := vaUsecase.NewVirtualAccountUsecase(vr, mockMerchantRepo, mockCustomers, tu, cfg)
+	va := domain.VirtualAccount{MerchantID: 6, SettingID: 6, CustomerID: 1, Mode: domain.VirtualAccountOpen}
+	assert.NoError(t, u.Store(context.TODO(), &va))
+
+	for _, reference := range []string{"c1", "c2"} {
+		res, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: va.Number, Amount: 12345, Reference: reference})
+		assert.NoError(t, err)
+		paid, err := tr.GetByID(context.TODO(), res.TransactionID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransactionCaptured, paid.State)
+	}
+	res, err := vr.GetByID(context.TODO(), va.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), res.TransactionID)
+}
+
+func TestNotifyLinkedAccount(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	va := domain.VirtualAccount{ID: 2, MerchantID: 6, SettingID: 6, CustomerID: 1, TransactionID: 4, Number: "7001200000000002", Mode: domain.VirtualAccountOpen, Status: domain.VirtualAccountActive, Standing: true}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(va, nil).Once()
+	mockTransactions.On("GetByID", mock.Anything, int64(4)).Return(domain.Transaction{ID: 4, Amount: 50000, State: domain.TransactionPending}, nil).Once()
+	mockRepo.On("ClaimPayment", mock.Anything, mock.MatchedBy(func(p *domain.VirtualAccountPayment) bool {
+		return p.Amount == 52000 && p.Overpaid == 2000
+	})).Return(true, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(4), int64(50000), "c1").Return(domain.Transaction{ID: 4, State: domain.TransactionCaptured}, nil).Once()
+	mockRepo.On("UpdatePayment", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("Unlink", mock.Anything, int64(2), int64(4)).Return(nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	res, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000002", Amount: 52000, Reference: "c1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), res.TransactionID)
+	mockTransactions.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNotifyDuplicate(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	va := domain.VirtualAccount{ID: 2, MerchantID: 6, Mode: domain.VirtualAccountOpen, Status: domain.VirtualAccountActive, Standing: true}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000002").Return(va, nil).Once()
+	mockRepo.On("ClaimPayment", mock.Anything, mock.Anything).Return(false, nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), new(mocks.TransactionUsecase), cfg)
+
+	_, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000002", Amount: 12345, Reference: "c1"})
+	assert.Equal(t, domain.ErrDuplicatePayment, err)
+}
+
+func TestNotifyReleasesFailedPayment(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	va := domain.VirtualAccount{ID: 1, MerchantID: 6, TransactionID: 4, Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000001").Return(va, nil).Once()
+	mockRepo.On("ClaimPayment", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.VirtualAccountPayment).ID = 8
+	}).Return(true, nil).Once()
+	mockTransactions.On("Complete", mock.Anything, int64(4), int64(50000), "b1").Return(domain.Transaction{}, domain.ErrInvalidState).Once()
+	mockRepo.On("ReleasePayment", mock.Anything, int64(8)).Return(nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	_, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000001", Amount: 50000, Reference: "b1"})
+	assert.Equal(t, domain.ErrInvalidState, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNotifyExpired(t *testing.T) {
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	va := domain.VirtualAccount{ID: 1, MerchantID: 6, TransactionID: 4, Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive, ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("GetByNumber", mock.Anything, "BCA", "7001200000000001").Return(va, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(va *domain.VirtualAccount) bool {
+		return va.Status == domain.VirtualAccountExpired
+	})).Return(nil).Once()
+	mockTransactions.On("Fail", mock.Anything, int64(4), domain.ErrVAExpired.Error()).Return(domain.Transaction{ID: 4, State: domain.TransactionFailed}, nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	_, err := u.Notify(context.TODO(), &domain.VANotification{Bank: "BCA", Number: "7001200000000001", Amount: 50000, Reference: "b1"})
+	assert.Equal(t, domain.ErrVAExpired, err)
+	mockTransactions.AssertExpectations(t)
+}
+
+func TestRunExpiry(t *testing.T) {
+	now := time.Now().UTC()
+	mockRepo := new(mocks.VirtualAccountRepository)
+	mockTransactions := new(mocks.TransactionUsecase)
+	vas := []domain.VirtualAccount{
+		{ID: 1, MerchantID: 6, TransactionID: 4, Mode: domain.VirtualAccountClosed, Amount: 50000, Status: domain.VirtualAccountActive, ExpiresAt: now.Add(-time.Minute)},
+		{ID: 2, MerchantID: 6, Mode: domain.VirtualAccountOpen, Status: domain.VirtualAccountActive, ExpiresAt: now.Add(-time.Minute), Standing: true},
+	}
+	mockRepo.On("FetchExpired", mock.Anything, now).Return(vas, nil).Once()
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(va *domain.VirtualAccount) bool {
+		return va.Status == domain.VirtualAccountExpired
+	})).Return(nil).Twice()
+	mockTransactions.On("Fail", mock.Anything, int64(4), domain.ErrVAExpired.Error()).Return(domain.Transaction{ID: 4, State: domain.TransactionFailed}, nil).Once()
+	u := vaUsecase.NewVirtualAccountUsecase(mockRepo, new(mocks.MerchantRepository), new(mocks.CustomerUsecase), mockTransactions, cfg)
+
+	err := u.RunExpiry(context.TODO(), now)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockTransactions.AssertExpectations(t)
+}